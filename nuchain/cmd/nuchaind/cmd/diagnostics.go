@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/server"
+
+	"shared/diagnostics"
+)
+
+// DiagnosticsConfig controls the diagnostics server
+type DiagnosticsConfig struct {
+	Address string `mapstructure:"address"`
+}
+
+// startDiagnostics serves the diagnostics of the node alongside it when
+// diagnostics.address is set in app.toml, until the node stops
+func startDiagnostics(svrCtx *server.Context, _ client.Context, ctx context.Context, g *errgroup.Group) error {
+	addr := svrCtx.Viper.GetString("diagnostics.address")
+	if addr == "" {
+		return nil
+	}
+
+	svrCtx.Logger.Info("serving diagnostics", "address", addr)
+	g.Go(func() error {
+		if err := diagnostics.Serve(ctx, addr, diagnostics.Default); err != nil {
+			return fmt.Errorf("diagnostics server: %w", err)
+		}
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	tmcfg "github.com/cometbft/cometbft/config"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutilcli "github.com/cosmos/cosmos-sdk/x/genutil/client/cli"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+
+	miningtypes "nuchain/x/mining/types"
+)
+
+// FlagProfile selects the network profile used by init
+const FlagProfile = "profile"
+
+// InitCmd wraps the SDK init command so config.toml, app.toml and genesis are
+// generated from the selected network profile.
+func InitCmd(mbm module.BasicManager, defaultNodeHome string) *cobra.Command {
+	cmd := genutilcli.InitCmd(mbm, defaultNodeHome)
+	cmd.Flags().String(FlagProfile, DefaultProfile, fmt.Sprintf("Network profile (%s)", strings.Join(ProfileNames(), ", ")))
+
+	runInit := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString(FlagProfile)
+		profile, err := GetProfile(name)
+		if err != nil {
+			return err
+		}
+
+		// Default the chain ID to the profile's so every node on a network agrees
+		if chainID, _ := cmd.Flags().GetString(flags.FlagChainID); chainID == "" {
+			if err := cmd.Flags().Set(flags.FlagChainID, profile.ChainID); err != nil {
+				return err
+			}
+		}
+
+		if err := runInit(cmd, args); err != nil {
+			return err
+		}
+
+		return applyProfile(cmd, profile)
+	}
+
+	return cmd
+}
+
+// applyProfile rewrites the files generated by init with the profile settings
+func applyProfile(cmd *cobra.Command, profile Profile) error {
+	config := server.GetServerContextFromCmd(cmd).Config
+	configDir := filepath.Join(config.RootDir, "config")
+
+	profile.ApplyTendermintConfig(config)
+	tmcfg.WriteConfigFile(filepath.Join(configDir, "config.toml"), config)
+
+	appTemplate, appConfig := profileAppConfig(profile)
+	serverconfig.SetConfigTemplate(appTemplate)
+	serverconfig.WriteConfigFile(filepath.Join(configDir, "app.toml"), appConfig)
+
+	genFile := config.GenesisFile()
+	appState, genDoc, err := genutiltypes.GenesisStateFromGenFile(genFile)
+	if err != nil {
+		return fmt.Errorf("failed to read genesis: %w", err)
+	}
+
+	miningGenesis := miningtypes.DefaultGenesis()
+	if raw, ok := appState[miningtypes.ModuleName]; ok {
+		if err := json.Unmarshal(raw, miningGenesis); err != nil {
+			return fmt.Errorf("failed to parse %s genesis: %w", miningtypes.ModuleName, err)
+		}
+	}
+	profile.ApplyGenesis(miningGenesis)
+	if err := miningGenesis.Validate(); err != nil {
+		return fmt.Errorf("profile %s produced invalid genesis: %w", profile.Name, err)
+	}
+
+	if appState[miningtypes.ModuleName], err = json.Marshal(miningGenesis); err != nil {
+		return err
+	}
+	if genDoc.AppState, err = json.MarshalIndent(appState, "", " "); err != nil {
+		return err
+	}
+
+	if err := genutil.ExportGenesisFile(genDoc, genFile); err != nil {
+		return err
+	}
+
+	cmd.PrintErrf("Applied %s profile (chain-id %s)\n", profile.Name, genDoc.ChainID)
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tmcfg "github.com/cometbft/cometbft/config"
+
+	miningtypes "nuchain/x/mining/types"
+)
+
+// DefaultProfile is used when init is run without --profile
+const DefaultProfile = "mainnet"
+
+// Profile is a named network environment. Everything that differs between
+// mainnet, testnet and devnet is derived from here instead of edited by hand.
+type Profile struct {
+	Name              string
+	ChainID           string
+	BlockTime         time.Duration
+	HalvingInterval   int64
+	SupportedChains   []string // External chains mining rigs may be bridged from
+	LayerZeroEndpoint string
+	FaucetEnabled     bool
+	MinGasPrices      string
+}
+
+// Profiles are the supported nuChain environments
+var Profiles = map[string]Profile{
+	"mainnet": {
+		Name:            "mainnet",
+		ChainID:         "nuchain-1",
+		BlockTime:       200 * time.Millisecond,
+		HalvingInterval: 210000000,
+		SupportedChains: []string{"altcoinchain-2330", "polygon-137"},
+		FaucetEnabled:   false,
+		MinGasPrices:    "0.025nu",
+	},
+	"testnet": {
+		Name:            "testnet",
+		ChainID:         "nuchain-testnet-1",
+		BlockTime:       200 * time.Millisecond,
+		HalvingInterval: 2100000,
+		SupportedChains: []string{"altcoinchain-2330", "polygon-amoy-80002"},
+		FaucetEnabled:   true,
+		MinGasPrices:    "0nu",
+	},
+	"devnet": {
+		Name:            "devnet",
+		ChainID:         "nuchain-devnet",
+		BlockTime:       time.Second,
+		HalvingInterval: 21000,
+		SupportedChains: []string{"altcoinchain-2330", "polygon-amoy-80002", "localhost-1337"},
+		FaucetEnabled:   true,
+		MinGasPrices:    "0nu",
+	},
+}
+
+// GetProfile looks up a profile by name
+func GetProfile(name string) (Profile, error) {
+	profile, ok := Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, expected one of %v", name, ProfileNames())
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the profile names in a stable order
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyTendermintConfig sets consensus timing for the profile
+func (p Profile) ApplyTendermintConfig(cfg *tmcfg.Config) {
+	cfg.Consensus.TimeoutCommit = p.BlockTime
+	cfg.Consensus.CreateEmptyBlocks = true
+	cfg.Consensus.CreateEmptyBlocksInterval = p.BlockTime
+}
+
+// ApplyGenesis sets the mining module genesis for the profile
+func (p Profile) ApplyGenesis(gs *miningtypes.GenesisState) {
+	gs.Params.HalvingInterval = p.HalvingInterval
+	gs.Params.SupportedChains = p.SupportedChains
+	gs.Params.LayerZeroEndpoint = p.LayerZeroEndpoint
+}
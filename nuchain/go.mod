@@ -10,6 +10,7 @@ require (
 	github.com/spf13/cobra v1.7.0
 	github.com/stretchr/testify v1.8.4
 	github.com/tendermint/tendermint v0.37.2
+	golang.org/x/sync v0.3.0
 	google.golang.org/genproto/googleapis/api v0.0.0-20230726155614-23370e0ffb3e
 	google.golang.org/grpc v1.57.0
 	gopkg.in/yaml.v2 v2.4.0
@@ -20,4 +21,8 @@ require (
 	github.com/ethereum/go-ethereum v1.12.0
 	github.com/layerzerolabs/lz-sdk-go v0.2.0 // LayerZero SDK
 	github.com/altcoinchain/sdk v0.1.0 // Altcoinchain SDK
-)
\ No newline at end of file
+)
+
+require shared v0.0.0
+
+replace shared => ../shared
@@ -1,24 +1,51 @@
 package mining
 
 import (
+	"cosmossdk.io/core/comet"
+	
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	
 	"nuchain/x/mining/keeper"
 	"nuchain/x/mining/types"
+	"shared/diagnostics"
 )
 
 // BeginBlocker is called at the beginning of every block
 func BeginBlocker(ctx sdk.Context, k keeper.Keeper) {
+	defer trackPhase(ctx, "begin_block")()
+	
+	// Slash and tombstone staking nodes caught double signing
+	evidences := ctx.CometInfo().GetEvidence()
+	for i := 0; i < evidences.Len(); i++ {
+		evidence := evidences.Get(i)
+		switch evidence.Type() {
+		case comet.DuplicateVote, comet.LightClientAttack:
+			if err := k.HandleEquivocationEvidence(ctx, evidence); err != nil {
+				k.Logger(ctx).Error("Failed to handle equivocation evidence", "error", err)
+			}
+		}
+	}
+	
 	// Update staking node status based on block signing
-	k.UpdateStakingNodeStatus(ctx)
+	k.TrackLiveness(ctx)
 }
 
 // EndBlocker is called at the end of every block
 func EndBlocker(ctx sdk.Context, k keeper.Keeper) {
+	defer trackPhase(ctx, "end_block")()
+	
+	// Rotate reward ownership for recoveries whose delay has elapsed
+	k.ExecuteMatureRecoveries(ctx)
+	
+	// Pay pools the escrowed fees of epochs that can no longer be disputed
+	k.ReleasePoolFees(ctx)
+	
 	// Distribute block rewards to miners and stakers
+	done := trackPhase(ctx, "distribute_block_rewards")
 	if err := k.DistributeBlockRewards(ctx, ctx.BlockHeight()); err != nil {
 		k.Logger(ctx).Error("Failed to distribute block rewards", "error", err)
 	}
+	done()
 	
 	// Emit block reward distribution event
 	ctx.EventManager().EmitEvent(
@@ -29,11 +56,8 @@ func EndBlocker(ctx sdk.Context, k keeper.Keeper) {
 	)
 }
 
-// UpdateStakingNodeStatus updates the online status of staking nodes
-func (k Keeper) UpdateStakingNodeStatus(ctx sdk.Context) {
-	// Implementation would check which validators signed the current block
-	// and update their online status accordingly
-	
-	// For now, this is a placeholder that would integrate with Tendermint consensus
-	k.Logger(ctx).Info("Updated staking node status", "block_height", ctx.BlockHeight())
-}
\ No newline at end of file
+// trackPhase times a phase of the module in the block of ctx for the
+// diagnostics server; the returned function ends it
+func trackPhase(ctx sdk.Context, phase string) func() {
+	return diagnostics.Default.Track(ctx.BlockHeight(), types.ModuleName, phase)
+}
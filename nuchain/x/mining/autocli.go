@@ -0,0 +1,257 @@
+package mining
+
+import (
+	autocliv1 "cosmossdk.io/api/autocli/v1"
+)
+
+// AutoCLIOptions exposes the mining Msg and Query services to the autocli generated commands
+func (am AppModule) AutoCLIOptions() *autocliv1.ModuleOptions {
+	return &autocliv1.ModuleOptions{
+		Query: &autocliv1.ServiceCommandDescriptor{
+			Service: "nuchain.mining.v1.Query",
+			RpcCommandOptions: []*autocliv1.RpcCommandOptions{
+				{
+					RpcMethod: "Params",
+					Use:       "params",
+					Short:     "Show the mining module parameters",
+				},
+				{
+					RpcMethod: "MiningRigs",
+					Use:       "mining-rigs",
+					Short:     "List the mining rig NFTs registered from Altcoinchain and Polygon",
+				},
+				{
+					RpcMethod: "MiningRig",
+					Use:       "mining-rig [chain-id] [token-id]",
+					Short:     "Show a mining rig NFT",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "chain_id"},
+						{ProtoField: "token_id"},
+					},
+				},
+				{
+					RpcMethod: "PoolOperators",
+					Use:       "pool-operators",
+					Short:     "List the registered mining pools",
+				},
+				{
+					RpcMethod: "PoolOperator",
+					Use:       "pool-operator [chain-id] [address]",
+					Short:     "Show a mining pool, its members and fee schedule",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "chain_id"},
+						{ProtoField: "address"},
+					},
+				},
+				{
+					RpcMethod: "StakingNodes",
+					Use:       "staking-nodes",
+					Short:     "List the staking nodes",
+				},
+				{
+					RpcMethod: "StakingNode",
+					Use:       "staking-node [operator]",
+					Short:     "Show the staking node of an operator",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "operator"},
+					},
+				},
+				{
+					RpcMethod: "TotalHashPower",
+					Use:       "total-hash-power",
+					Short:     "Show the hash power of the active mining rigs",
+				},
+				{
+					RpcMethod: "TombstonedOperators",
+					Use:       "tombstoned-operators",
+					Short:     "List staking node operators tombstoned for double signing",
+				},
+				{
+					RpcMethod: "TombstonedOperator",
+					Use:       "tombstoned-operator [operator]",
+					Short:     "Show the tombstone record of a staking node operator",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "operator"},
+					},
+				},
+				{
+					RpcMethod: "PoolFeeDisputes",
+					Use:       "pool-fee-disputes",
+					Short:     "List pool fee disputes",
+				},
+				{
+					RpcMethod: "PoolFeeDispute",
+					Use:       "pool-fee-dispute [id]",
+					Short:     "Show a pool fee dispute and its evidence",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "id"},
+					},
+				},
+				{
+					RpcMethod: "RewardWithholding",
+					Use:       "reward-withholding [account]",
+					Short:     "Show the share of an account's mining rewards withheld and where it goes",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "account"},
+					},
+				},
+				{
+					RpcMethod: "WithholdingReports",
+					Use:       "withholding-reports [account]",
+					Short:     "List an account's rewards and withheld amounts per reporting period",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "account"},
+					},
+				},
+				{
+					RpcMethod: "Treasury",
+					Use:       "treasury",
+					Short:     "Show the funds of the community treasury and the tax that accrued them",
+				},
+			},
+		},
+		Tx: &autocliv1.ServiceCommandDescriptor{
+			Service: "nuchain.mining.v1.Msg",
+			RpcCommandOptions: []*autocliv1.RpcCommandOptions{
+				{
+					RpcMethod: "CreateStakingNode",
+					Use:       "create-staking-node [moniker] [consensus-address] [supported-chains]",
+					Short:     "Register a nuChain staking node and escrow its NU stake",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "moniker"},
+						{ProtoField: "consensus_address"},
+						{ProtoField: "supported_chains", Varargs: true},
+					},
+				},
+				{
+					RpcMethod: "ProcessCrossChainMessage",
+					Use:       "process-cross-chain-message [source-chain] [message-type] [payload] [nonce]",
+					Short:     "Relay a message from Altcoinchain or Polygon",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "source_chain"},
+						{ProtoField: "message_type"},
+						{ProtoField: "payload"},
+						{ProtoField: "nonce"},
+					},
+				},
+				{
+					RpcMethod: "UpdateMiningRig",
+					Use:       "update-mining-rig [token-id] [chain-id] [contract-address] [hash-power] [watt-consumption] [is-active]",
+					Short:     "Update a mining rig NFT configuration",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "token_id"},
+						{ProtoField: "chain_id"},
+						{ProtoField: "contract_address"},
+						{ProtoField: "hash_power"},
+						{ProtoField: "watt_consumption"},
+						{ProtoField: "is_active"},
+					},
+				},
+				{
+					RpcMethod: "SetRecoveryGuardians",
+					Use:       "set-recovery-guardians [threshold] [delay-blocks] [guardians]",
+					Short:     "Designate the guardians allowed to recover your account",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "threshold"},
+						{ProtoField: "delay_blocks"},
+						{ProtoField: "guardians", Varargs: true},
+					},
+				},
+				{
+					RpcMethod: "ApproveRecovery",
+					Use:       "approve-recovery [account] [new-owner]",
+					Short:     "Approve rotating a guarded account to a new owner",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "account"},
+						{ProtoField: "new_owner"},
+					},
+				},
+				{
+					RpcMethod: "CancelRecovery",
+					Use:       "cancel-recovery",
+					Short:     "Abort a pending recovery of your account",
+				},
+				{
+					RpcMethod: "AnnounceMaintenance",
+					Use:       "announce-maintenance [start-height] [end-height]",
+					Short:     "Pre-announce staking node downtime so missed blocks are not penalized",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "start_height"},
+						{ProtoField: "end_height"},
+					},
+				},
+				{
+					RpcMethod: "CancelMaintenance",
+					Use:       "cancel-maintenance",
+					Short:     "Withdraw your announced maintenance window",
+				},
+				{
+					RpcMethod: "SetPoolFeeSchedule",
+					Use:       "set-pool-fee-schedule [chain-id] [fee-bps] [fee-recipient]",
+					Short:     "Change the fee your pool keeps from member rewards",
+					Long:      "Publishes a new fee schedule version. Members only pay the new fee after rejoining with consent to its hash.",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "chain_id"},
+						{ProtoField: "fee_bps"},
+						{ProtoField: "fee_recipient"},
+					},
+				},
+				{
+					RpcMethod: "JoinPool",
+					Use:       "join-pool [miner] [operator] [chain-id] [fee-schedule-hash]",
+					Short:     "Consent to a pool's fee schedule and join it",
+					Long:      "Must be signed by both the miner and the pool operator: build it with --generate-only and have each party sign.",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "miner"},
+						{ProtoField: "operator"},
+						{ProtoField: "chain_id"},
+						{ProtoField: "fee_schedule_hash"},
+					},
+				},
+				{
+					RpcMethod: "LeavePool",
+					Use:       "leave-pool",
+					Short:     "Leave your current mining pool",
+				},
+				{
+					RpcMethod: "OpenPoolDispute",
+					Use:       "open-pool-dispute [pool] [chain-id] [epoch] [reason]",
+					Short:     "Contest the pool fee you paid in an epoch",
+					Long:      "Keeps the epoch's fee escrowed until governance or the arbitrator resolves the dispute.",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "pool"},
+						{ProtoField: "chain_id"},
+						{ProtoField: "epoch"},
+						{ProtoField: "reason"},
+					},
+				},
+				{
+					RpcMethod: "SubmitDisputeEvidence",
+					Use:       "submit-dispute-evidence [dispute-id]",
+					Short:     "Attach a Merkle-proven share from the pool's share log to a dispute",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "dispute_id"},
+					},
+				},
+				{
+					RpcMethod: "ResolvePoolDispute",
+					Use:       "resolve-pool-dispute [dispute-id] [miner-refund]",
+					Short:     "Settle a pool fee dispute (governance or arbitrator only)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "dispute_id"},
+						{ProtoField: "miner_refund"},
+					},
+				},
+				{
+					RpcMethod: "SetRewardWithholding",
+					Use:       "set-reward-withholding [recipient] [bps]",
+					Short:     "Withhold a share of your mining rewards into a savings or tax account; 0 bps clears it",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "recipient"},
+						{ProtoField: "bps"},
+					},
+				},
+			},
+		},
+	}
+}
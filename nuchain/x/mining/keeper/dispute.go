@@ -0,0 +1,274 @@
+package keeper
+
+import (
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// PoolEpoch returns the pool fee epoch of the current block
+func (k Keeper) PoolEpoch(ctx sdk.Context) uint64 {
+	return uint64(ctx.BlockHeight() / k.GetParams(ctx).PoolEpochBlocks)
+}
+
+// poolFeePayoutKey orders payouts by epoch so mature ones can be released with
+// a single range scan
+func poolFeePayoutKey(epoch uint64, pool, chainId, miner string) []byte {
+	return []byte(fmt.Sprintf("%020d/%s/%s/%s", epoch, pool, chainId, miner))
+}
+
+// GetPoolFeePayout returns the escrowed fee a miner paid its pool in an epoch
+func (k Keeper) GetPoolFeePayout(ctx sdk.Context, epoch uint64, pool, chainId, miner string) (types.PoolFeePayout, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolFeePayoutKey))
+	bz := store.Get(poolFeePayoutKey(epoch, pool, chainId, miner))
+	if bz == nil {
+		return types.PoolFeePayout{}, false
+	}
+
+	var payout types.PoolFeePayout
+	k.cdc.MustUnmarshal(bz, &payout)
+	return payout, true
+}
+
+func (k Keeper) setPoolFeePayout(ctx sdk.Context, payout types.PoolFeePayout) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolFeePayoutKey))
+	bz := k.cdc.MustMarshal(&payout)
+	store.Set(poolFeePayoutKey(payout.Epoch, payout.Pool, payout.ChainId, payout.Miner), bz)
+}
+
+func (k Keeper) deletePoolFeePayout(ctx sdk.Context, payout types.PoolFeePayout) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolFeePayoutKey))
+	store.Delete(poolFeePayoutKey(payout.Epoch, payout.Pool, payout.ChainId, payout.Miner))
+}
+
+// escrowPoolFee adds a fee the module kept from a member reward to the member's
+// payout for the current epoch
+func (k Keeper) escrowPoolFee(ctx sdk.Context, payout types.PoolFeePayout) {
+	payout.Epoch = k.PoolEpoch(ctx)
+	amount, _ := sdk.NewIntFromString(payout.Amount)
+
+	if existing, found := k.GetPoolFeePayout(ctx, payout.Epoch, payout.Pool, payout.ChainId, payout.Miner); found {
+		accrued, _ := sdk.NewIntFromString(existing.Amount)
+		amount = amount.Add(accrued)
+		payout.DisputeId = existing.DisputeId
+	}
+
+	payout.Amount = amount.String()
+	k.setPoolFeePayout(ctx, payout)
+}
+
+// ReleasePoolFees pays pools the fees of epochs whose dispute window has closed.
+// It runs on epoch boundaries; contested payouts stay escrowed until resolved.
+func (k Keeper) ReleasePoolFees(ctx sdk.Context) {
+	params := k.GetParams(ctx)
+	if ctx.BlockHeight()%params.PoolEpochBlocks != 0 {
+		return
+	}
+
+	current := k.PoolEpoch(ctx)
+	window := uint64(params.DisputeWindowEpochs)
+	if current <= window {
+		return
+	}
+	cutoff := current - window
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolFeePayoutKey))
+	iterator := store.Iterator(nil, []byte(fmt.Sprintf("%020d/", cutoff)))
+
+	var mature []types.PoolFeePayout
+	for ; iterator.Valid(); iterator.Next() {
+		var payout types.PoolFeePayout
+		k.cdc.MustUnmarshal(iterator.Value(), &payout)
+		if payout.DisputeId == 0 {
+			mature = append(mature, payout)
+		}
+	}
+	iterator.Close()
+
+	for _, payout := range mature {
+		if err := k.payFromEscrow(ctx, payout.FeeRecipient, payout.Amount); err != nil {
+			k.Logger(ctx).Error("Failed to release pool fee",
+				"pool", payout.Pool,
+				"miner", payout.Miner,
+				"epoch", payout.Epoch,
+				"error", err)
+			continue
+		}
+		k.deletePoolFeePayout(ctx, payout)
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeReleasePoolFee,
+				sdk.NewAttribute(types.AttributeKeyPool, payout.Pool),
+				sdk.NewAttribute(types.AttributeKeyMiner, payout.Miner),
+				sdk.NewAttribute(types.AttributeKeyEpoch, fmt.Sprintf("%d", payout.Epoch)),
+				sdk.NewAttribute(types.AttributeKeyAmount, payout.Amount),
+			),
+		)
+	}
+}
+
+// payFromEscrow sends escrowed NU from the module account
+func (k Keeper) payFromEscrow(ctx sdk.Context, recipient string, amount string) error {
+	addr, err := sdk.AccAddressFromBech32(recipient)
+	if err != nil {
+		return err
+	}
+
+	value, ok := sdk.NewIntFromString(amount)
+	if !ok {
+		return fmt.Errorf("invalid amount: %s", amount)
+	}
+	if !value.IsPositive() {
+		return nil
+	}
+
+	coins := sdk.NewCoins(sdk.NewCoin("nu", value))
+	return k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, addr, coins)
+}
+
+// GetPoolFeeDispute returns a dispute by ID
+func (k Keeper) GetPoolFeeDispute(ctx sdk.Context, id uint64) (types.PoolFeeDispute, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolDisputeKey))
+	bz := store.Get(sdk.Uint64ToBigEndian(id))
+	if bz == nil {
+		return types.PoolFeeDispute{}, false
+	}
+
+	var dispute types.PoolFeeDispute
+	k.cdc.MustUnmarshal(bz, &dispute)
+	return dispute, true
+}
+
+func (k Keeper) setPoolFeeDispute(ctx sdk.Context, dispute types.PoolFeeDispute) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolDisputeKey))
+	bz := k.cdc.MustMarshal(&dispute)
+	store.Set(sdk.Uint64ToBigEndian(dispute.Id), bz)
+}
+
+func (k Keeper) nextPoolDisputeId(ctx sdk.Context) uint64 {
+	store := ctx.KVStore(k.storeKey)
+	key := types.KeyPrefix(types.PoolDisputeCountKey)
+
+	var id uint64
+	if bz := store.Get(key); bz != nil {
+		id = sdk.BigEndianToUint64(bz)
+	}
+	id++
+
+	store.Set(key, sdk.Uint64ToBigEndian(id))
+	return id
+}
+
+// OpenPoolDispute contests the fee a miner paid its pool in an epoch. The fee
+// stays escrowed in the module until the dispute is resolved.
+func (k Keeper) OpenPoolDispute(ctx sdk.Context, miner, pool, chainId string, epoch uint64, reason string) (uint64, error) {
+	payout, found := k.GetPoolFeePayout(ctx, epoch, pool, chainId, miner)
+	if !found {
+		return 0, fmt.Errorf("no escrowed fee paid by %s to pool %s in epoch %d", miner, pool, epoch)
+	}
+	if payout.DisputeId != 0 {
+		return 0, fmt.Errorf("payout for epoch %d is already disputed in dispute %d", epoch, payout.DisputeId)
+	}
+
+	id := k.nextPoolDisputeId(ctx)
+	payout.DisputeId = id
+	k.setPoolFeePayout(ctx, payout)
+
+	k.setPoolFeeDispute(ctx, types.PoolFeeDispute{
+		Id:             id,
+		Miner:          miner,
+		Pool:           pool,
+		ChainId:        chainId,
+		Epoch:          epoch,
+		DisputedAmount: payout.Amount,
+		Reason:         reason,
+		Status:         types.DisputeStatus_DISPUTE_STATUS_OPEN,
+		OpenedHeight:   ctx.BlockHeight(),
+	})
+
+	return id, nil
+}
+
+// SubmitDisputeEvidence attaches share log evidence from either party. The
+// Merkle proof is checked when the message is validated.
+func (k Keeper) SubmitDisputeEvidence(ctx sdk.Context, id uint64, evidence types.DisputeEvidence) error {
+	dispute, found := k.GetPoolFeeDispute(ctx, id)
+	if !found {
+		return fmt.Errorf("dispute %d not found", id)
+	}
+	if dispute.Status != types.DisputeStatus_DISPUTE_STATUS_OPEN {
+		return fmt.Errorf("dispute %d is not open", id)
+	}
+	if evidence.Submitter != dispute.Miner && evidence.Submitter != dispute.Pool {
+		return fmt.Errorf("only the miner or the pool operator may submit evidence")
+	}
+	if len(dispute.Evidence) >= types.MaxDisputeEvidence {
+		return fmt.Errorf("dispute %d already holds the maximum of %d evidence items", id, types.MaxDisputeEvidence)
+	}
+
+	evidence.SubmittedHeight = ctx.BlockHeight()
+	dispute.Evidence = append(dispute.Evidence, evidence)
+	k.setPoolFeeDispute(ctx, dispute)
+
+	return nil
+}
+
+// IsDisputeResolver reports whether an account may settle pool fee disputes
+func (k Keeper) IsDisputeResolver(ctx sdk.Context, account string) bool {
+	if account == k.authority {
+		return true
+	}
+
+	arbitrator := k.GetParams(ctx).Arbitrator
+	return arbitrator != "" && account == arbitrator
+}
+
+// ResolvePoolDispute settles a dispute: minerRefund goes back to the miner's
+// reward recipient and the rest of the escrowed fee is paid to the pool
+func (k Keeper) ResolvePoolDispute(ctx sdk.Context, resolver string, id uint64, minerRefund sdk.Int) (types.PoolFeeDispute, error) {
+	dispute, found := k.GetPoolFeeDispute(ctx, id)
+	if !found {
+		return types.PoolFeeDispute{}, fmt.Errorf("dispute %d not found", id)
+	}
+	if dispute.Status != types.DisputeStatus_DISPUTE_STATUS_OPEN {
+		return types.PoolFeeDispute{}, fmt.Errorf("dispute %d is not open", id)
+	}
+
+	payout, found := k.GetPoolFeePayout(ctx, dispute.Epoch, dispute.Pool, dispute.ChainId, dispute.Miner)
+	if !found {
+		return types.PoolFeeDispute{}, fmt.Errorf("escrowed payout of dispute %d not found", id)
+	}
+
+	// Fees kept after the dispute was opened are part of the escrow as well
+	escrowed, _ := sdk.NewIntFromString(payout.Amount)
+	if minerRefund.GT(escrowed) {
+		return types.PoolFeeDispute{}, fmt.Errorf("refund %s exceeds escrowed fee %s", minerRefund, escrowed)
+	}
+
+	miner, err := sdk.AccAddressFromBech32(dispute.Miner)
+	if err != nil {
+		return types.PoolFeeDispute{}, err
+	}
+
+	if err := k.payFromEscrow(ctx, k.GetRewardRecipient(ctx, miner).String(), minerRefund.String()); err != nil {
+		return types.PoolFeeDispute{}, fmt.Errorf("failed to refund miner: %w", err)
+	}
+	if err := k.payFromEscrow(ctx, payout.FeeRecipient, escrowed.Sub(minerRefund).String()); err != nil {
+		return types.PoolFeeDispute{}, fmt.Errorf("failed to pay pool: %w", err)
+	}
+	k.deletePoolFeePayout(ctx, payout)
+
+	dispute.DisputedAmount = escrowed.String()
+	dispute.Status = types.DisputeStatus_DISPUTE_STATUS_RESOLVED
+	dispute.MinerRefund = minerRefund.String()
+	dispute.Resolver = resolver
+	dispute.ResolvedHeight = ctx.BlockHeight()
+	k.setPoolFeeDispute(ctx, dispute)
+
+	return dispute, nil
+}
@@ -0,0 +1,150 @@
+package keeper
+
+import (
+	"fmt"
+
+	"cosmossdk.io/core/comet"
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// GetStakingNode returns the staking node registered by an operator
+func (k Keeper) GetStakingNode(ctx sdk.Context, operator string) (types.StakingNode, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.StakingNodeKey))
+	bz := store.Get([]byte(types.StakingNodeKey + operator))
+	if bz == nil {
+		return types.StakingNode{}, false
+	}
+
+	var node types.StakingNode
+	k.cdc.MustUnmarshal(bz, &node)
+	return node, true
+}
+
+// SetStakingNode stores a staking node and indexes it by consensus address
+func (k Keeper) SetStakingNode(ctx sdk.Context, node types.StakingNode) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.StakingNodeKey))
+	bz := k.cdc.MustMarshal(&node)
+	store.Set([]byte(types.StakingNodeKey+node.Operator), bz)
+
+	if node.ConsensusAddress != "" {
+		consStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.StakingNodeConsAddressKey))
+		consStore.Set([]byte(node.ConsensusAddress), []byte(node.Operator))
+	}
+}
+
+// GetStakingNodeByConsAddress returns the staking node signing with a consensus address
+func (k Keeper) GetStakingNodeByConsAddress(ctx sdk.Context, consAddr sdk.ConsAddress) (types.StakingNode, bool) {
+	consStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.StakingNodeConsAddressKey))
+	operator := consStore.Get([]byte(consAddr.String()))
+	if operator == nil {
+		return types.StakingNode{}, false
+	}
+
+	return k.GetStakingNode(ctx, string(operator))
+}
+
+// GetTombstonedOperator returns the tombstone record of an operator
+func (k Keeper) GetTombstonedOperator(ctx sdk.Context, operator string) (types.TombstonedOperator, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.TombstoneKey))
+	bz := store.Get([]byte(operator))
+	if bz == nil {
+		return types.TombstonedOperator{}, false
+	}
+
+	var record types.TombstonedOperator
+	k.cdc.MustUnmarshal(bz, &record)
+	return record, true
+}
+
+func (k Keeper) setTombstonedOperator(ctx sdk.Context, record types.TombstonedOperator) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.TombstoneKey))
+	bz := k.cdc.MustMarshal(&record)
+	store.Set([]byte(record.Operator), bz)
+}
+
+// SlashFractionDoubleSign returns the share of escrowed NU burned for equivocation
+func (k Keeper) SlashFractionDoubleSign(ctx sdk.Context) sdk.Dec {
+	var fraction string
+	k.paramstore.Get(ctx, types.KeySlashFractionDoubleSign, &fraction)
+	return sdk.MustNewDecFromStr(fraction)
+}
+
+// HandleEquivocationEvidence slashes and tombstones the staking node that signed
+// conflicting blocks. It receives the same CometBFT evidence as x/evidence, which
+// keeps handling SDK validators; consensus keys not registered as staking nodes
+// are ignored here. Expired evidence is dropped using the consensus evidence params.
+func (k Keeper) HandleEquivocationEvidence(ctx sdk.Context, evidence comet.Evidence) error {
+	consAddr := sdk.ConsAddress(evidence.Validator().Address())
+
+	node, found := k.GetStakingNodeByConsAddress(ctx, consAddr)
+	if !found {
+		return nil
+	}
+
+	// A node is only punished once, however much evidence is submitted
+	if node.Tombstoned {
+		k.Logger(ctx).Info("Ignoring equivocation evidence for tombstoned staking node",
+			"operator", node.Operator,
+			"infraction_height", evidence.Height())
+		return nil
+	}
+
+	if params := ctx.ConsensusParams().Evidence; params != nil {
+		ageDuration := ctx.BlockHeader().Time.Sub(evidence.Time())
+		ageBlocks := ctx.BlockHeight() - evidence.Height()
+		if ageDuration > params.MaxAgeDuration && ageBlocks > params.MaxAgeNumBlocks {
+			k.Logger(ctx).Info("Ignoring expired equivocation evidence",
+				"operator", node.Operator,
+				"infraction_height", evidence.Height(),
+				"age_blocks", ageBlocks)
+			return nil
+		}
+	}
+
+	escrowed := node.Stake()
+	slashAmount := k.SlashFractionDoubleSign(ctx).MulInt(escrowed).TruncateInt()
+
+	// Burn the slashed share of the escrow held by the module account
+	if slashAmount.IsPositive() {
+		coins := sdk.NewCoins(sdk.NewCoin("nu", slashAmount))
+		if err := k.bankKeeper.BurnCoins(ctx, types.ModuleName, coins); err != nil {
+			return fmt.Errorf("failed to burn slashed stake: %w", err)
+		}
+	}
+
+	node.StakedNu = escrowed.Sub(slashAmount).String()
+	node.IsOnline = false
+	node.VotingPower = 0
+	node.Tombstoned = true
+	k.SetStakingNode(ctx, node)
+
+	k.setTombstonedOperator(ctx, types.TombstonedOperator{
+		Operator:         node.Operator,
+		ConsensusAddress: node.ConsensusAddress,
+		InfractionHeight: evidence.Height(),
+		SlashedNu:        slashAmount.String(),
+		TombstonedHeight: ctx.BlockHeight(),
+	})
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeTombstoneStakingNode,
+			sdk.NewAttribute(types.AttributeKeyOperator, node.Operator),
+			sdk.NewAttribute(types.AttributeKeyConsensusAddress, node.ConsensusAddress),
+			sdk.NewAttribute(types.AttributeKeyInfractionHeight, fmt.Sprintf("%d", evidence.Height())),
+			sdk.NewAttribute(types.AttributeKeyAmount, slashAmount.String()),
+		),
+	)
+
+	k.Logger(ctx).Info("Slashed and tombstoned staking node for double signing",
+		"operator", node.Operator,
+		"consensus_address", node.ConsensusAddress,
+		"infraction_height", evidence.Height(),
+		"slashed_nu", slashAmount.String())
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package keeper
+
+import (
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// InitGenesis imports the params, mining rigs, pools and staking nodes of a
+// genesis state. Rigs are written to the store directly and the hash power
+// aggregates rebuilt from them once, rather than updated rig by rig.
+func (k Keeper) InitGenesis(ctx sdk.Context, gs types.GenesisState) {
+	k.SetParams(ctx, gs.Params)
+
+	rigs := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
+	for _, rig := range gs.MiningRigs {
+		rigs.Set(miningRigKey(rig.TokenId, rig.ChainId), k.cdc.MustMarshal(&rig))
+	}
+	k.RebuildHashPower(ctx)
+
+	for _, pool := range gs.PoolOperators {
+		k.setPoolOperator(ctx, pool)
+	}
+	for _, node := range gs.StakingNodes {
+		k.SetStakingNode(ctx, node)
+	}
+}
+
+// ExportGenesis exports the state InitGenesis imports
+func (k Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	gs := types.DefaultGenesis()
+	gs.Params = k.GetParams(ctx)
+	gs.LastBlockHeight = ctx.BlockHeight()
+
+	k.iterate(ctx, types.MiningRigKey, func(value []byte) {
+		var rig types.MiningRigNFT
+		k.cdc.MustUnmarshal(value, &rig)
+		gs.MiningRigs = append(gs.MiningRigs, rig)
+	})
+	k.iterate(ctx, types.PoolOperatorKey, func(value []byte) {
+		var pool types.PoolOperator
+		k.cdc.MustUnmarshal(value, &pool)
+		gs.PoolOperators = append(gs.PoolOperators, pool)
+	})
+	k.iterate(ctx, types.StakingNodeKey, func(value []byte) {
+		var node types.StakingNode
+		k.cdc.MustUnmarshal(value, &node)
+		gs.StakingNodes = append(gs.StakingNodes, node)
+	})
+	return gs
+}
+
+// iterate calls fn with each value stored under a key prefix
+func (k Keeper) iterate(ctx sdk.Context, keyPrefix string, fn func(value []byte)) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(keyPrefix))
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		fn(iterator.Value())
+	}
+}
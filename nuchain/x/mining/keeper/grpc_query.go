@@ -0,0 +1,291 @@
+package keeper
+
+import (
+	"context"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"nuchain/x/mining/types"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// Params returns the module parameters
+func (k Keeper) Params(goCtx context.Context, req *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
+
+// MiningRigs lists the mining rig NFTs registered from the source chains
+func (k Keeper) MiningRigs(goCtx context.Context, req *types.QueryMiningRigsRequest) (*types.QueryMiningRigsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
+
+	var rigs []types.MiningRigNFT
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var rig types.MiningRigNFT
+		if err := k.cdc.Unmarshal(value, &rig); err != nil {
+			return err
+		}
+		rigs = append(rigs, rig)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryMiningRigsResponse{Rigs: rigs, Pagination: pageRes}, nil
+}
+
+// MiningRig returns a mining rig NFT by its chain and token ID
+func (k Keeper) MiningRig(goCtx context.Context, req *types.QueryMiningRigRequest) (*types.QueryMiningRigResponse, error) {
+	if req == nil || req.ChainId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	rig, found := k.GetMiningRig(ctx, req.TokenId, req.ChainId)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "mining rig %d on %s not found", req.TokenId, req.ChainId)
+	}
+
+	return &types.QueryMiningRigResponse{Rig: rig}, nil
+}
+
+// PoolOperators lists the mining pools registered from the source chains
+func (k Keeper) PoolOperators(goCtx context.Context, req *types.QueryPoolOperatorsRequest) (*types.QueryPoolOperatorsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolOperatorKey))
+
+	var pools []types.PoolOperator
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var pool types.PoolOperator
+		if err := k.cdc.Unmarshal(value, &pool); err != nil {
+			return err
+		}
+		pools = append(pools, pool)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryPoolOperatorsResponse{Pools: pools, Pagination: pageRes}, nil
+}
+
+// PoolOperator returns the pool of an operator on a source chain
+func (k Keeper) PoolOperator(goCtx context.Context, req *types.QueryPoolOperatorRequest) (*types.QueryPoolOperatorResponse, error) {
+	if req == nil || req.ChainId == "" || req.Address == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	pool, found := k.GetPoolOperator(ctx, req.Address, req.ChainId)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "pool of %s on %s not found", req.Address, req.ChainId)
+	}
+
+	return &types.QueryPoolOperatorResponse{Pool: pool}, nil
+}
+
+// StakingNodes lists the staking nodes, tombstoned ones included
+func (k Keeper) StakingNodes(goCtx context.Context, req *types.QueryStakingNodesRequest) (*types.QueryStakingNodesResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.StakingNodeKey))
+
+	var nodes []types.StakingNode
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var node types.StakingNode
+		if err := k.cdc.Unmarshal(value, &node); err != nil {
+			return err
+		}
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryStakingNodesResponse{Nodes: nodes, Pagination: pageRes}, nil
+}
+
+// StakingNode returns the staking node of an operator
+func (k Keeper) StakingNode(goCtx context.Context, req *types.QueryStakingNodeRequest) (*types.QueryStakingNodeResponse, error) {
+	if req == nil || req.Operator == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	node, found := k.GetStakingNode(ctx, req.Operator)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no staking node for %s", req.Operator)
+	}
+
+	return &types.QueryStakingNodeResponse{Node: node}, nil
+}
+
+// TotalHashPower returns the hash power of the active mining rigs
+func (k Keeper) TotalHashPower(goCtx context.Context, req *types.QueryTotalHashPowerRequest) (*types.QueryTotalHashPowerResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryTotalHashPowerResponse{TotalHashPower: k.GetTotalHashPower(ctx)}, nil
+}
+
+// TombstonedOperators lists staking node operators removed for double signing
+func (k Keeper) TombstonedOperators(goCtx context.Context, req *types.QueryTombstonedOperatorsRequest) (*types.QueryTombstonedOperatorsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.TombstoneKey))
+
+	var operators []types.TombstonedOperator
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var record types.TombstonedOperator
+		if err := k.cdc.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		operators = append(operators, record)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryTombstonedOperatorsResponse{Operators: operators, Pagination: pageRes}, nil
+}
+
+// TombstonedOperator returns the tombstone record of a single operator
+func (k Keeper) TombstonedOperator(goCtx context.Context, req *types.QueryTombstonedOperatorRequest) (*types.QueryTombstonedOperatorResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	record, found := k.GetTombstonedOperator(ctx, req.Operator)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "operator %s is not tombstoned", req.Operator)
+	}
+
+	return &types.QueryTombstonedOperatorResponse{Operator: record}, nil
+}
+
+// PoolFeeDisputes lists pool fee disputes
+func (k Keeper) PoolFeeDisputes(goCtx context.Context, req *types.QueryPoolFeeDisputesRequest) (*types.QueryPoolFeeDisputesResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolDisputeKey))
+
+	var disputes []types.PoolFeeDispute
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var dispute types.PoolFeeDispute
+		if err := k.cdc.Unmarshal(value, &dispute); err != nil {
+			return err
+		}
+		disputes = append(disputes, dispute)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryPoolFeeDisputesResponse{Disputes: disputes, Pagination: pageRes}, nil
+}
+
+// PoolFeeDispute returns a single pool fee dispute
+func (k Keeper) PoolFeeDispute(goCtx context.Context, req *types.QueryPoolFeeDisputeRequest) (*types.QueryPoolFeeDisputeResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	dispute, found := k.GetPoolFeeDispute(ctx, req.Id)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "dispute %d not found", req.Id)
+	}
+
+	return &types.QueryPoolFeeDisputeResponse{Dispute: dispute}, nil
+}
+
+// RewardWithholding returns the withholding configured by an account
+func (k Keeper) RewardWithholding(goCtx context.Context, req *types.QueryRewardWithholdingRequest) (*types.QueryRewardWithholdingResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	withholding, found := k.GetRewardWithholding(ctx, req.Account)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no reward withholding for %s", req.Account)
+	}
+
+	return &types.QueryRewardWithholdingResponse{Withholding: withholding}, nil
+}
+
+// WithholdingReports lists an account's per-period withholding totals, oldest
+// period first
+func (k Keeper) WithholdingReports(goCtx context.Context, req *types.QueryWithholdingReportsRequest) (*types.QueryWithholdingReportsResponse, error) {
+	if req == nil || req.Account == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.WithholdingReportKey+req.Account+"/"))
+
+	var reports []types.WithholdingReport
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var report types.WithholdingReport
+		if err := k.cdc.Unmarshal(value, &report); err != nil {
+			return err
+		}
+		reports = append(reports, report)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryWithholdingReportsResponse{Reports: reports, Pagination: pageRes}, nil
+}
+
+// Treasury returns the funds of the community treasury and the tax that accrued them
+func (k Keeper) Treasury(goCtx context.Context, req *types.QueryTreasuryRequest) (*types.QueryTreasuryResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryTreasuryResponse{
+		Balance:     k.TreasuryBalance(ctx).String(),
+		Accrued:     k.GetTreasuryAccrued(ctx).String(),
+		TreasuryTax: k.GetParams(ctx).TreasuryTax,
+	}, nil
+}
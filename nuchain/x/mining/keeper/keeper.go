@@ -3,7 +3,6 @@ package keeper
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
 	
 	"cosmossdk.io/log"
 	"cosmossdk.io/store/prefix"
@@ -18,6 +17,8 @@ import (
 	// Cross-chain integrations
 	layerzero "github.com/layerzerolabs/lz-sdk-go"
 	altcoin "github.com/altcoinchain/sdk"
+
+	"shared/pow"
 )
 
 type Keeper struct {
@@ -28,6 +29,10 @@ type Keeper struct {
 	bankKeeper types.BankKeeper
 	logger     log.Logger
 	
+	// authority is the governance account allowed to settle pool fee disputes
+	// and spend the treasury
+	authority string
+	
 	// Cross-chain clients
 	layerZeroClient *layerzero.Client
 	altcoinClient   *altcoin.Client
@@ -42,6 +47,7 @@ func NewKeeper(
 	ps paramtypes.Subspace,
 	bankKeeper types.BankKeeper,
 	logger log.Logger,
+	authority string,
 	layerZeroEndpoint string,
 	altcoinRPC string,
 	polygonRPC string,
@@ -69,6 +75,7 @@ func NewKeeper(
 		paramstore:      ps,
 		bankKeeper:      bankKeeper,
 		logger:          logger,
+		authority:       authority,
 		layerZeroClient: layerZeroClient,
 		altcoinClient:   altcoinClient,
 		polygonRPC:      polygonRPC,
@@ -77,7 +84,10 @@ func NewKeeper(
 }
 
 // ProcessCrossChainMessage handles incoming messages from Altcoinchain/Polygon
-func (k Keeper) ProcessCrossChainMessage(ctx sdk.Context, msg types.CrossChainMessage) error {
+func (k Keeper) ProcessCrossChainMessage(ctx sdk.Context, msg types.CrossChainMessage) (err error) {
+	messageType := msg.MessageType
+	defer func() { countCrossChainMessage(messageType, err) }()
+	
 	switch msg.MessageType {
 	case "mining_rig_update":
 		return k.processMiningRigUpdate(ctx, msg)
@@ -86,6 +96,7 @@ func (k Keeper) ProcessCrossChainMessage(ctx sdk.Context, msg types.CrossChainMe
 	case "reward_distribution":
 		return k.processRewardDistribution(ctx, msg)
 	default:
+		messageType = "unknown"
 		return fmt.Errorf("unknown message type: %s", msg.MessageType)
 	}
 }
@@ -102,12 +113,8 @@ func (k Keeper) processMiningRigUpdate(ctx sdk.Context, msg types.CrossChainMess
 		return fmt.Errorf("invalid hash power: %d", rigData.HashPower)
 	}
 	
-	// Store the mining rig data
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
-	key := types.MiningRigKey + strconv.FormatUint(rigData.TokenId, 10) + "-" + rigData.ChainId
-	
-	bz := k.cdc.MustMarshal(&rigData)
-	store.Set([]byte(key), bz)
+	// Store the mining rig data, moving its hash power in the aggregates
+	k.SetMiningRig(ctx, rigData)
 	
 	k.logger.Info("Updated mining rig NFT", 
 		"token_id", rigData.TokenId,
@@ -130,12 +137,17 @@ func (k Keeper) processPoolOperatorStake(ctx sdk.Context, msg types.CrossChainMe
 		return fmt.Errorf("pool operator has not staked required WATT tokens")
 	}
 	
-	// Store pool operator data
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolOperatorKey))
-	key := types.PoolOperatorKey + poolData.Address + "-" + poolData.ChainId
+	// Membership and the fee schedule are managed on nuChain, not by the source chain
+	if existing, found := k.GetPoolOperator(ctx, poolData.Address, poolData.ChainId); found {
+		poolData.Miners = existing.Miners
+		poolData.FeeSchedule = existing.FeeSchedule
+	} else {
+		poolData.Miners = nil
+		poolData.FeeSchedule = nil
+	}
 	
-	bz := k.cdc.MustMarshal(&poolData)
-	store.Set([]byte(key), bz)
+	// Store pool operator data
+	k.setPoolOperator(ctx, poolData)
 	
 	k.logger.Info("Registered pool operator",
 		"address", poolData.Address,
@@ -145,36 +157,46 @@ func (k Keeper) processPoolOperatorStake(ctx sdk.Context, msg types.CrossChainMe
 	return nil
 }
 
-// CreateStakingNode creates a new staking node for nuChain validation
-func (k Keeper) CreateStakingNode(ctx sdk.Context, operator sdk.AccAddress, moniker string, supportedChains []string) error {
-	// Check if operator has staked 21 NU tokens
-	stakedAmount := k.GetStakedAmount(ctx, operator)
-	requiredStake := sdk.NewInt(21 * 1e18) // 21 NU tokens
-	
-	if stakedAmount.LT(requiredStake) {
-		return fmt.Errorf("insufficient stake: required %s, got %s", requiredStake, stakedAmount)
+// CreateStakingNode creates a new staking node for nuChain validation. The
+// required stake is escrowed in the module account so it can be slashed if the
+// node's consensus key double signs.
+func (k Keeper) CreateStakingNode(ctx sdk.Context, operator sdk.AccAddress, moniker string, supportedChains []string, consAddr sdk.ConsAddress) error {
+	if existing, found := k.GetStakingNode(ctx, operator.String()); found {
+		if existing.Tombstoned {
+			return fmt.Errorf("operator %s is tombstoned", operator)
+		}
+		return fmt.Errorf("operator %s already runs a staking node", operator)
+	}
+	
+	if _, found := k.GetStakingNodeByConsAddress(ctx, consAddr); found {
+		return fmt.Errorf("consensus address %s is already registered", consAddr)
+	}
+	
+	// Escrow 21 NU tokens
+	requiredStake := types.NodeStake
+	coins := sdk.NewCoins(sdk.NewCoin("nu", requiredStake))
+	if err := k.bankKeeper.SendCoinsFromAccountToModule(ctx, operator, types.ModuleName, coins); err != nil {
+		return fmt.Errorf("insufficient stake: required %s: %w", requiredStake, err)
 	}
 	
 	stakingNode := types.StakingNode{
-		Operator:        operator.String(),
-		Moniker:         moniker,
-		StakedNu:        stakedAmount.Uint64(),
-		IsOnline:        true,
-		LastBlockSigned: ctx.BlockHeight(),
-		VotingPower:     k.CalculateVotingPower(stakedAmount),
-		SupportedChains: supportedChains,
+		Operator:         operator.String(),
+		Moniker:          moniker,
+		StakedNu:         requiredStake.String(),
+		IsOnline:         true,
+		LastBlockSigned:  ctx.BlockHeight(),
+		VotingPower:      k.CalculateVotingPower(requiredStake),
+		SupportedChains:  supportedChains,
+		ConsensusAddress: consAddr.String(),
 	}
 	
 	// Store staking node
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.StakingNodeKey))
-	key := types.StakingNodeKey + operator.String()
-	
-	bz := k.cdc.MustMarshal(&stakingNode)
-	store.Set([]byte(key), bz)
+	k.SetStakingNode(ctx, stakingNode)
 	
 	k.logger.Info("Created staking node",
 		"operator", operator.String(),
 		"moniker", moniker,
+		"consensus_address", consAddr.String(),
 		"voting_power", stakingNode.VotingPower)
 	
 	return nil
@@ -188,21 +210,21 @@ func (k Keeper) DistributeBlockRewards(ctx sdk.Context, blockHeight int64) error
 		return fmt.Errorf("no active mining rigs found")
 	}
 	
-	// Calculate base reward (0.05 NU per block)
-	baseReward := sdk.NewInt(50000000000000000) // 0.05 NU * 10^18
+	// Base reward of 0.05 NU per block, halving every 210M blocks
+	baseReward := sdk.NewIntFromBigInt(pow.DefaultHalvingSchedule().Reward(blockHeight))
 	
-	// Apply halving mechanism
-	halvingInterval := int64(210000000)
-	halvings := blockHeight / halvingInterval
-	if halvings > 0 {
-		divisor := sdk.NewInt(1 << uint(halvings))
-		baseReward = baseReward.Quo(divisor)
+	// The treasury tax is paid before the miners share the rest
+	minersReward, err := k.payTreasuryTax(ctx, baseReward)
+	if err != nil {
+		return fmt.Errorf("failed to pay the treasury tax: %w", err)
 	}
 	
 	// Distribute rewards to miners based on hash power contribution
-	if err := k.distributeMiningRewards(ctx, baseReward, totalHashPower); err != nil {
+	minted, err := k.distributeMiningRewards(ctx, minersReward, totalHashPower)
+	if err != nil {
 		return fmt.Errorf("failed to distribute mining rewards: %w", err)
 	}
+	setBlockRewardGauges(minted.Add(baseReward.Sub(minersReward)), totalHashPower)
 	
 	// Distribute WATT rewards to online staking nodes
 	if err := k.distributeStakingRewards(ctx, blockHeight); err != nil {
@@ -212,48 +234,75 @@ func (k Keeper) DistributeBlockRewards(ctx sdk.Context, blockHeight int64) error
 	return nil
 }
 
-// distributeMiningRewards distributes NU rewards to miners
-func (k Keeper) distributeMiningRewards(ctx sdk.Context, totalReward sdk.Int, totalHashPower uint64) error {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
+// distributeMiningRewards distributes NU rewards to the owners of active rigs
+// in proportion to their hash power and returns the total minted for them
+func (k Keeper) distributeMiningRewards(ctx sdk.Context, totalReward sdk.Int, totalHashPower uint64) (sdk.Int, error) {
+	minted := sdk.ZeroInt()
+	
+	// Owners are collected first: paying them writes to the store
+	type ownerShare struct {
+		owner     string
+		hashPower uint64
+	}
+	var shares []ownerShare
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.OwnerHashPowerKey))
 	iterator := store.Iterator(nil, nil)
-	defer iterator.Close()
-	
 	for ; iterator.Valid(); iterator.Next() {
-		var rig types.MiningRigNFT
-		k.cdc.MustUnmarshal(iterator.Value(), &rig)
-		
-		if !rig.IsActive {
-			continue
-		}
-		
+		shares = append(shares, ownerShare{owner: string(iterator.Key()), hashPower: sdk.BigEndianToUint64(iterator.Value())})
+	}
+	iterator.Close()
+	
+	for _, share := range shares {
 		// Calculate reward based on hash power contribution
-		contribution := sdk.NewDec(int64(rig.HashPower)).Quo(sdk.NewDec(int64(totalHashPower)))
+		contribution := sdk.NewDec(int64(share.hashPower)).Quo(sdk.NewDec(int64(totalHashPower)))
 		reward := contribution.MulInt(totalReward).TruncateInt()
 		
 		if reward.IsPositive() {
+			// Rigs are owned by the nuChain account that registered them; an
+			// EVM owner address would name no account
+			owner, err := sdk.AccAddressFromBech32(share.owner)
+			if err != nil {
+				k.logger.Error("Skipping reward for rig owner with invalid address",
+					"owner", share.owner,
+					"error", err)
+				continue
+			}
+			recipient := k.GetRewardRecipient(ctx, owner)
+			
 			// Mint and send NU tokens
 			coins := sdk.NewCoins(sdk.NewCoin("nu", reward))
 			if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
-				return err
+				return minted, err
+			}
+			minted = minted.Add(reward)
+			
+			// Pool members pay the fee they consented to. It stays escrowed in the
+			// module until the epoch can no longer be disputed.
+			if payout, found := k.poolFeeShare(ctx, owner, reward); found {
+				k.escrowPoolFee(ctx, payout)
+				fee, _ := sdk.NewIntFromString(payout.Amount)
+				coins = coins.Sub(sdk.NewCoin("nu", fee))
 			}
 			
-			recipient, err := sdk.AccAddressFromBech32(rig.Owner)
+			// The recipient may route a share into a savings or tax account
+			remaining, err := k.withholdReward(ctx, recipient, coins.AmountOf("nu"))
 			if err != nil {
-				continue
+				return minted, err
 			}
+			coins = sdk.NewCoins(sdk.NewCoin("nu", remaining))
 			
 			if err := k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, recipient, coins); err != nil {
-				return err
+				return minted, err
 			}
 			
 			k.logger.Info("Distributed mining reward",
-				"recipient", rig.Owner,
+				"recipient", share.owner,
 				"amount", reward.String(),
-				"hash_power", rig.HashPower)
+				"hash_power", share.hashPower)
 		}
 	}
 	
-	return nil
+	return minted, nil
 }
 
 // distributeStakingRewards distributes WATT rewards to staking nodes
@@ -264,18 +313,22 @@ func (k Keeper) distributeStakingRewards(ctx sdk.Context, blockHeight int64) err
 	
 	// Base WATT reward per online staking node per block
 	wattReward := sdk.NewInt(1000000000000000) // 0.001 WATT * 10^18
+	var sent, failed int
 	
 	for ; iterator.Valid(); iterator.Next() {
 		var node types.StakingNode
 		k.cdc.MustUnmarshal(iterator.Value(), &node)
 		
-		if !node.IsOnline {
+		// Offline, jailed and suspended nodes earn nothing
+		if !node.IsOnline || node.RewardsSuspended || node.Tombstoned {
 			continue
 		}
 		
 		// Send cross-chain message to distribute WATT rewards
 		for _, chainId := range node.SupportedChains {
+			sent++
 			if err := k.sendWattReward(ctx, node.Operator, chainId, wattReward); err != nil {
+				failed++
 				k.logger.Error("Failed to send WATT reward",
 					"operator", node.Operator,
 					"chain_id", chainId,
@@ -284,6 +337,7 @@ func (k Keeper) distributeStakingRewards(ctx sdk.Context, blockHeight int64) err
 		}
 	}
 	
+	setCrossChainGauges(sent, failed)
 	return nil
 }
 
@@ -305,30 +359,13 @@ func (k Keeper) sendWattReward(ctx sdk.Context, operator string, chainId string,
 	return k.layerZeroClient.SendMessage(chainId, payloadBytes)
 }
 
-// GetTotalHashPower calculates total hash power from all active mining rigs
-func (k Keeper) GetTotalHashPower(ctx sdk.Context) uint64 {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
-	iterator := store.Iterator(nil, nil)
-	defer iterator.Close()
-	
-	var totalHashPower uint64
-	for ; iterator.Valid(); iterator.Next() {
-		var rig types.MiningRigNFT
-		k.cdc.MustUnmarshal(iterator.Value(), &rig)
-		
-		if rig.IsActive {
-			totalHashPower += rig.HashPower
-		}
-	}
-	
-	return totalHashPower
-}
-
-// GetStakedAmount returns the amount of NU tokens staked by an operator
+// GetStakedAmount returns the amount of NU tokens escrowed by an operator
 func (k Keeper) GetStakedAmount(ctx sdk.Context, operator sdk.AccAddress) sdk.Int {
-	// Implementation would check staking contract or module
-	// For now, return a placeholder
-	return sdk.NewInt(21 * 1e18) // 21 NU tokens
+	node, found := k.GetStakingNode(ctx, operator.String())
+	if !found {
+		return sdk.ZeroInt()
+	}
+	return node.Stake()
 }
 
 // CalculateVotingPower calculates voting power based on staked amount
@@ -0,0 +1,252 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"cosmossdk.io/core/comet"
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// GetNodeLiveness returns the signing info of a staking node
+func (k Keeper) GetNodeLiveness(ctx sdk.Context, operator string) types.NodeLiveness {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.NodeLivenessKey))
+	bz := store.Get([]byte(operator))
+	if bz == nil {
+		return types.NodeLiveness{Operator: operator}
+	}
+
+	var liveness types.NodeLiveness
+	k.cdc.MustUnmarshal(bz, &liveness)
+	return liveness
+}
+
+func (k Keeper) setNodeLiveness(ctx sdk.Context, liveness types.NodeLiveness) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.NodeLivenessKey))
+	bz := k.cdc.MustMarshal(&liveness)
+	store.Set([]byte(liveness.Operator), bz)
+}
+
+func missedBlockKey(operator string, index int64) []byte {
+	key := make([]byte, 0, len(operator)+9)
+	key = append(key, operator...)
+	key = append(key, '/')
+	return binary.BigEndian.AppendUint64(key, uint64(index))
+}
+
+func (k Keeper) getMissedBlock(ctx sdk.Context, operator string, index int64) bool {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MissedBlockKey))
+	return store.Has(missedBlockKey(operator, index))
+}
+
+func (k Keeper) setMissedBlock(ctx sdk.Context, operator string, index int64, missed bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MissedBlockKey))
+	if missed {
+		store.Set(missedBlockKey(operator, index), []byte{1})
+	} else {
+		store.Delete(missedBlockKey(operator, index))
+	}
+}
+
+func (k Keeper) clearMissedBlocks(ctx sdk.Context, operator string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MissedBlockKey+operator+"/"))
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// GetMaintenanceWindow returns the maintenance announced by an operator
+func (k Keeper) GetMaintenanceWindow(ctx sdk.Context, operator string) (types.MaintenanceWindow, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MaintenanceWindowKey))
+	bz := store.Get([]byte(operator))
+	if bz == nil {
+		return types.MaintenanceWindow{}, false
+	}
+
+	var window types.MaintenanceWindow
+	k.cdc.MustUnmarshal(bz, &window)
+	return window, true
+}
+
+func (k Keeper) deleteMaintenanceWindow(ctx sdk.Context, operator string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MaintenanceWindowKey))
+	store.Delete([]byte(operator))
+}
+
+// AnnounceMaintenance records downtime planned by a staking node operator. The
+// window must start at least MaintenanceNoticeBlocks ahead so it cannot be used
+// to excuse an outage that is already under way, and replaces any earlier one.
+func (k Keeper) AnnounceMaintenance(ctx sdk.Context, window types.MaintenanceWindow) error {
+	node, found := k.GetStakingNode(ctx, window.Operator)
+	if !found {
+		return fmt.Errorf("operator %s has no staking node", window.Operator)
+	}
+	if node.Tombstoned {
+		return fmt.Errorf("operator %s is tombstoned", window.Operator)
+	}
+
+	params := k.GetParams(ctx).LivenessParams
+	if earliest := ctx.BlockHeight() + params.MaintenanceNoticeBlocks; window.StartHeight < earliest {
+		return fmt.Errorf("maintenance must start at or after height %d", earliest)
+	}
+	if length := window.EndHeight - window.StartHeight; length > params.MaxMaintenanceBlocks {
+		return fmt.Errorf("maintenance of %d blocks exceeds the maximum of %d", length, params.MaxMaintenanceBlocks)
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MaintenanceWindowKey))
+	bz := k.cdc.MustMarshal(&window)
+	store.Set([]byte(window.Operator), bz)
+	return nil
+}
+
+// CancelMaintenance withdraws an operator's announced maintenance window
+func (k Keeper) CancelMaintenance(ctx sdk.Context, operator string) error {
+	if _, found := k.GetMaintenanceWindow(ctx, operator); !found {
+		return fmt.Errorf("operator %s has no announced maintenance", operator)
+	}
+
+	k.deleteMaintenanceWindow(ctx, operator)
+	return nil
+}
+
+// inMaintenance reports whether height falls inside the operator's announced
+// maintenance, dropping the window once it has passed
+func (k Keeper) inMaintenance(ctx sdk.Context, operator string, height int64) bool {
+	window, found := k.GetMaintenanceWindow(ctx, operator)
+	if !found {
+		return false
+	}
+	if height > window.EndHeight {
+		k.deleteMaintenanceWindow(ctx, operator)
+		return false
+	}
+	return height >= window.StartHeight
+}
+
+// TrackLiveness updates staking node signing info from the last commit. Missed
+// blocks count over a sliding window as in x/slashing. Once an outage has lasted
+// longer than the grace period, WATT rewards are suspended at SuspendMissedBlocks
+// and the node is jailed at JailMissedBlocks. Blocks inside an announced
+// maintenance window are never counted as missed.
+func (k Keeper) TrackLiveness(ctx sdk.Context) {
+	params := k.GetParams(ctx).LivenessParams
+	height := ctx.BlockHeight()
+
+	votes := ctx.CometInfo().GetLastCommit().Votes()
+	for i := 0; i < votes.Len(); i++ {
+		vote := votes.Get(i)
+
+		node, found := k.GetStakingNodeByConsAddress(ctx, sdk.ConsAddress(vote.Validator().Address()))
+		if !found || node.Tombstoned {
+			continue
+		}
+
+		signed := vote.GetBlockIDFlag() != comet.BlockIDFlagAbsent
+		k.handleNodeSignature(ctx, params, node, height, signed)
+	}
+}
+
+func (k Keeper) handleNodeSignature(ctx sdk.Context, params types.LivenessParams, node types.StakingNode, height int64, signed bool) {
+	liveness := k.GetNodeLiveness(ctx, node.Operator)
+	maintenance := k.inMaintenance(ctx, node.Operator, height)
+
+	index := liveness.IndexOffset % params.LivenessWindow
+	liveness.IndexOffset++
+
+	// Update the sliding window counter
+	previous := k.getMissedBlock(ctx, node.Operator, index)
+	missed := !signed && !maintenance
+	switch {
+	case !previous && missed:
+		k.setMissedBlock(ctx, node.Operator, index, true)
+		liveness.MissedBlocksCounter++
+	case previous && !missed:
+		k.setMissedBlock(ctx, node.Operator, index, false)
+		liveness.MissedBlocksCounter--
+	}
+
+	if signed {
+		node.IsOnline = true
+		node.LastBlockSigned = height
+		liveness.DowntimeStartHeight = 0
+
+		if node.JailedUntil > 0 && height >= node.JailedUntil {
+			k.unjailNode(ctx, &node)
+		}
+		if node.JailedUntil == 0 && node.RewardsSuspended && liveness.MissedBlocksCounter < params.SuspendMissedBlocks {
+			node.RewardsSuspended = false
+			k.emitLivenessEvent(ctx, types.EventTypeResumeStakingRewards, node, liveness)
+		}
+	} else {
+		node.IsOnline = false
+		if liveness.DowntimeStartHeight == 0 && !maintenance {
+			liveness.DowntimeStartHeight = height
+		}
+	}
+
+	// Penalties only apply once the outage has outlasted the grace period
+	outage := liveness.DowntimeStartHeight > 0 && height-liveness.DowntimeStartHeight >= params.DowntimeGraceBlocks
+	if missed && outage && node.JailedUntil == 0 {
+		switch {
+		case liveness.MissedBlocksCounter >= params.JailMissedBlocks:
+			node.JailedUntil = height + params.JailDurationBlocks
+			node.RewardsSuspended = true
+			node.VotingPower = 0
+			k.emitLivenessEvent(ctx, types.EventTypeJailStakingNode, node, liveness)
+
+			// Start over with a clean window once the jail period is served
+			k.clearMissedBlocks(ctx, node.Operator)
+			liveness.MissedBlocksCounter = 0
+			liveness.IndexOffset = 0
+		case liveness.MissedBlocksCounter >= params.SuspendMissedBlocks && !node.RewardsSuspended:
+			node.RewardsSuspended = true
+			k.emitLivenessEvent(ctx, types.EventTypeSuspendStakingRewards, node, liveness)
+		}
+	}
+
+	k.setNodeLiveness(ctx, liveness)
+	k.SetStakingNode(ctx, node)
+}
+
+func (k Keeper) unjailNode(ctx sdk.Context, node *types.StakingNode) {
+	node.JailedUntil = 0
+	node.VotingPower = k.CalculateVotingPower(node.Stake())
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeUnjailStakingNode,
+			sdk.NewAttribute(types.AttributeKeyOperator, node.Operator),
+			sdk.NewAttribute(types.AttributeKeyBlockHeight, strconv.FormatInt(ctx.BlockHeight(), 10)),
+		),
+	)
+}
+
+func (k Keeper) emitLivenessEvent(ctx sdk.Context, eventType string, node types.StakingNode, liveness types.NodeLiveness) {
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			eventType,
+			sdk.NewAttribute(types.AttributeKeyOperator, node.Operator),
+			sdk.NewAttribute(types.AttributeKeyMissedBlocks, strconv.FormatInt(liveness.MissedBlocksCounter, 10)),
+			sdk.NewAttribute(types.AttributeKeyJailedUntil, strconv.FormatInt(node.JailedUntil, 10)),
+			sdk.NewAttribute(types.AttributeKeyBlockHeight, strconv.FormatInt(ctx.BlockHeight(), 10)),
+		),
+	)
+
+	k.Logger(ctx).Info("Staking node liveness changed",
+		"event", eventType,
+		"operator", node.Operator,
+		"missed_blocks", liveness.MissedBlocksCounter,
+		"jailed_until", node.JailedUntil)
+}
@@ -0,0 +1,41 @@
+package keeper
+
+import (
+	"math/big"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// Metrics are exported through the SDK telemetry, which serves them to
+// Prometheus when telemetry is enabled in app.toml. They are observations of
+// the node alone and never feed back into state: the reward minted and the
+// cross-chain messages of each block, the total hash power, and counts of
+// the cross-chain messages handled.
+
+// setBlockRewardGauges records the reward a block minted, its treasury tax
+// included, and the hash power that shared it
+func setBlockRewardGauges(minted sdk.Int, totalHashPower uint64) {
+	f, _ := new(big.Float).SetInt(minted.BigInt()).Float32()
+	telemetry.ModuleSetGauge(types.ModuleName, f, "block_reward_minted")
+	telemetry.ModuleSetGauge(types.ModuleName, float32(totalHashPower), "total_hash_power")
+}
+
+// setCrossChainGauges records the messages a block sent to other chains and
+// how many of them failed
+func setCrossChainGauges(sent, failed int) {
+	telemetry.ModuleSetGauge(types.ModuleName, float32(sent), "block_cross_chain_messages", "sent")
+	telemetry.ModuleSetGauge(types.ModuleName, float32(failed), "block_cross_chain_messages", "failed")
+}
+
+// countCrossChainMessage counts a message received from another chain, by
+// type and whether it was handled
+func countCrossChainMessage(messageType string, err error) {
+	status := "ok"
+	if err != nil {
+		status = "failed"
+	}
+	telemetry.IncrCounter(1, types.ModuleName, "cross_chain_messages", "in", messageType, status)
+}
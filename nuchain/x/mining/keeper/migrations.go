@@ -0,0 +1,22 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Migrator is a struct for handling in-place store migrations
+type Migrator struct {
+	keeper Keeper
+}
+
+// NewMigrator returns a new Migrator
+func NewMigrator(keeper Keeper) Migrator {
+	return Migrator{keeper: keeper}
+}
+
+// Migrate1to2 migrates the store from consensus version 1 to 2: the total
+// and per-owner hash power aggregates, computed from the stored rigs
+func (m Migrator) Migrate1to2(ctx sdk.Context) error {
+	m.keeper.RebuildHashPower(ctx)
+	return nil
+}
@@ -2,6 +2,7 @@ package keeper
 
 import (
 	"context"
+	"encoding/hex"
 	"strconv"
 	"strings"
 
@@ -46,8 +47,13 @@ func (k msgServer) CreateStakingNode(goCtx context.Context, msg *types.MsgCreate
 		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
 	}
 
+	consAddr, err := sdk.ConsAddressFromBech32(msg.ConsensusAddress)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid consensus address (%s)", err)
+	}
+
 	// Create the staking node
-	if err := k.Keeper.CreateStakingNode(ctx, creator, msg.Moniker, msg.SupportedChains); err != nil {
+	if err := k.Keeper.CreateStakingNode(ctx, creator, msg.Moniker, msg.SupportedChains, consAddr); err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
 
@@ -58,6 +64,7 @@ func (k msgServer) CreateStakingNode(goCtx context.Context, msg *types.MsgCreate
 			sdk.NewAttribute(types.AttributeKeyCreator, msg.Creator),
 			sdk.NewAttribute(types.AttributeKeyMoniker, msg.Moniker),
 			sdk.NewAttribute(types.AttributeKeySupportedChains, strings.Join(msg.SupportedChains, ",")),
+			sdk.NewAttribute(types.AttributeKeyConsensusAddress, msg.ConsensusAddress),
 		),
 	)
 
@@ -165,4 +172,333 @@ func (k msgServer) UpdateMiningRig(goCtx context.Context, msg *types.MsgUpdateMi
 	)
 
 	return &types.MsgUpdateMiningRigResponse{}, nil
-}
\ No newline at end of file
+}
+
+// SetRecoveryGuardians designates the guardians allowed to recover the sender's account
+func (k msgServer) SetRecoveryGuardians(goCtx context.Context, msg *types.MsgSetRecoveryGuardians) (*types.MsgSetRecoveryGuardiansResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	config := types.RecoveryConfig{
+		Account:     msg.Creator,
+		Guardians:   msg.Guardians,
+		Threshold:   msg.Threshold,
+		DelayBlocks: msg.DelayBlocks,
+	}
+
+	if err := k.Keeper.SetRecoveryGuardians(ctx, config); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSetRecoveryGuardians,
+			sdk.NewAttribute(types.AttributeKeyAccount, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyGuardian, strings.Join(msg.Guardians, ",")),
+			sdk.NewAttribute(types.AttributeKeyThreshold, strconv.FormatUint(uint64(msg.Threshold), 10)),
+		),
+	)
+
+	return &types.MsgSetRecoveryGuardiansResponse{}, nil
+}
+
+// ApproveRecovery records a guardian's approval to rotate an account to a new owner
+func (k msgServer) ApproveRecovery(goCtx context.Context, msg *types.MsgApproveRecovery) (*types.MsgApproveRecoveryResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	pending, err := k.Keeper.ApproveRecovery(ctx, msg.Creator, msg.Account, msg.NewOwner)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeApproveRecovery,
+			sdk.NewAttribute(types.AttributeKeyGuardian, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyAccount, msg.Account),
+			sdk.NewAttribute(types.AttributeKeyNewOwner, msg.NewOwner),
+			sdk.NewAttribute(types.AttributeKeyExecuteHeight, strconv.FormatInt(pending.ExecuteHeight, 10)),
+		),
+	)
+
+	return &types.MsgApproveRecoveryResponse{
+		Approvals:     uint32(len(pending.Approvals)),
+		ExecuteHeight: pending.ExecuteHeight,
+	}, nil
+}
+
+// CancelRecovery aborts a pending recovery of the sender's account
+func (k msgServer) CancelRecovery(goCtx context.Context, msg *types.MsgCancelRecovery) (*types.MsgCancelRecoveryResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if err := k.Keeper.CancelRecovery(ctx, msg.Creator); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrNotFound, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeCancelRecovery,
+			sdk.NewAttribute(types.AttributeKeyAccount, msg.Creator),
+		),
+	)
+
+	return &types.MsgCancelRecoveryResponse{}, nil
+}
+
+// AnnounceMaintenance pre-announces downtime of the sender's staking node
+func (k msgServer) AnnounceMaintenance(goCtx context.Context, msg *types.MsgAnnounceMaintenance) (*types.MsgAnnounceMaintenanceResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	window := types.MaintenanceWindow{
+		Operator:    msg.Creator,
+		StartHeight: msg.StartHeight,
+		EndHeight:   msg.EndHeight,
+	}
+
+	if err := k.Keeper.AnnounceMaintenance(ctx, window); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeAnnounceMaintenance,
+			sdk.NewAttribute(types.AttributeKeyOperator, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyStartHeight, strconv.FormatInt(msg.StartHeight, 10)),
+			sdk.NewAttribute(types.AttributeKeyEndHeight, strconv.FormatInt(msg.EndHeight, 10)),
+		),
+	)
+
+	return &types.MsgAnnounceMaintenanceResponse{}, nil
+}
+
+// CancelMaintenance withdraws the sender's announced maintenance window
+func (k msgServer) CancelMaintenance(goCtx context.Context, msg *types.MsgCancelMaintenance) (*types.MsgCancelMaintenanceResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if err := k.Keeper.CancelMaintenance(ctx, msg.Creator); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrNotFound, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeCancelMaintenance,
+			sdk.NewAttribute(types.AttributeKeyOperator, msg.Creator),
+		),
+	)
+
+	return &types.MsgCancelMaintenanceResponse{}, nil
+}
+
+// SetPoolFeeSchedule changes the fee the sender's pool keeps from member rewards
+func (k msgServer) SetPoolFeeSchedule(goCtx context.Context, msg *types.MsgSetPoolFeeSchedule) (*types.MsgSetPoolFeeScheduleResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	pool, err := k.Keeper.SetPoolFeeSchedule(ctx, msg.Creator, msg.ChainId, msg.FeeBps, msg.FeeRecipient)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	hash := types.FeeScheduleHash(pool)
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSetPoolFeeSchedule,
+			sdk.NewAttribute(types.AttributeKeyPool, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyChainId, msg.ChainId),
+			sdk.NewAttribute(types.AttributeKeyFeeBps, strconv.FormatUint(uint64(msg.FeeBps), 10)),
+			sdk.NewAttribute(types.AttributeKeyFeeVersion, strconv.FormatUint(pool.FeeSchedule.Version, 10)),
+			sdk.NewAttribute(types.AttributeKeyFeeScheduleHash, hex.EncodeToString(hash)),
+		),
+	)
+
+	return &types.MsgSetPoolFeeScheduleResponse{
+		Version:         pool.FeeSchedule.Version,
+		FeeScheduleHash: hash,
+	}, nil
+}
+
+// JoinPool records a miner's consent to a pool's fee schedule, co-signed by the operator
+func (k msgServer) JoinPool(goCtx context.Context, msg *types.MsgJoinPool) (*types.MsgJoinPoolResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if err := k.Keeper.JoinPool(ctx, msg.Miner, msg.Operator, msg.ChainId, msg.FeeScheduleHash); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeJoinPool,
+			sdk.NewAttribute(types.AttributeKeyMiner, msg.Miner),
+			sdk.NewAttribute(types.AttributeKeyPool, msg.Operator),
+			sdk.NewAttribute(types.AttributeKeyChainId, msg.ChainId),
+			sdk.NewAttribute(types.AttributeKeyFeeScheduleHash, hex.EncodeToString(msg.FeeScheduleHash)),
+		),
+	)
+
+	return &types.MsgJoinPoolResponse{}, nil
+}
+
+// LeavePool removes the sender from its pool
+func (k msgServer) LeavePool(goCtx context.Context, msg *types.MsgLeavePool) (*types.MsgLeavePoolResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	membership, err := k.Keeper.LeavePool(ctx, msg.Miner)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrNotFound, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeLeavePool,
+			sdk.NewAttribute(types.AttributeKeyMiner, msg.Miner),
+			sdk.NewAttribute(types.AttributeKeyPool, membership.Pool),
+			sdk.NewAttribute(types.AttributeKeyChainId, membership.ChainId),
+		),
+	)
+
+	return &types.MsgLeavePoolResponse{}, nil
+}
+
+// OpenPoolDispute contests the pool fee the sender paid in an epoch
+func (k msgServer) OpenPoolDispute(goCtx context.Context, msg *types.MsgOpenPoolDispute) (*types.MsgOpenPoolDisputeResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	id, err := k.Keeper.OpenPoolDispute(ctx, msg.Creator, msg.Pool, msg.ChainId, msg.Epoch, msg.Reason)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeOpenPoolDispute,
+			sdk.NewAttribute(types.AttributeKeyDisputeId, strconv.FormatUint(id, 10)),
+			sdk.NewAttribute(types.AttributeKeyMiner, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyPool, msg.Pool),
+			sdk.NewAttribute(types.AttributeKeyEpoch, strconv.FormatUint(msg.Epoch, 10)),
+		),
+	)
+
+	return &types.MsgOpenPoolDisputeResponse{DisputeId: id}, nil
+}
+
+// SubmitDisputeEvidence attaches share log evidence to an open dispute
+func (k msgServer) SubmitDisputeEvidence(goCtx context.Context, msg *types.MsgSubmitDisputeEvidence) (*types.MsgSubmitDisputeEvidenceResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	evidence := types.DisputeEvidence{
+		Submitter:    msg.Creator,
+		ShareLogUri:  msg.ShareLogUri,
+		ShareLogHash: msg.ShareLogHash,
+		MerkleRoot:   msg.MerkleRoot,
+		Leaf:         msg.Leaf,
+		LeafIndex:    msg.LeafIndex,
+		MerkleProof:  msg.MerkleProof,
+	}
+
+	if err := k.Keeper.SubmitDisputeEvidence(ctx, msg.DisputeId, evidence); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSubmitDisputeEvidence,
+			sdk.NewAttribute(types.AttributeKeyDisputeId, strconv.FormatUint(msg.DisputeId, 10)),
+			sdk.NewAttribute(types.AttributeKeyCreator, msg.Creator),
+		),
+	)
+
+	return &types.MsgSubmitDisputeEvidenceResponse{}, nil
+}
+
+// ResolvePoolDispute settles a dispute; only governance or the arbitrator may call it
+func (k msgServer) ResolvePoolDispute(goCtx context.Context, msg *types.MsgResolvePoolDispute) (*types.MsgResolvePoolDisputeResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if !k.Keeper.IsDisputeResolver(ctx, msg.Authority) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "%s may not resolve pool disputes", msg.Authority)
+	}
+
+	refund, ok := sdk.NewIntFromString(msg.MinerRefund)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid miner refund: %s", msg.MinerRefund)
+	}
+
+	dispute, err := k.Keeper.ResolvePoolDispute(ctx, msg.Authority, msg.DisputeId, refund)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeResolvePoolDispute,
+			sdk.NewAttribute(types.AttributeKeyDisputeId, strconv.FormatUint(msg.DisputeId, 10)),
+			sdk.NewAttribute(types.AttributeKeyResolver, msg.Authority),
+			sdk.NewAttribute(types.AttributeKeyMinerRefund, dispute.MinerRefund),
+			sdk.NewAttribute(types.AttributeKeyAmount, dispute.DisputedAmount),
+		),
+	)
+
+	return &types.MsgResolvePoolDisputeResponse{}, nil
+}
+
+func (k msgServer) SetRewardWithholding(goCtx context.Context, msg *types.MsgSetRewardWithholding) (*types.MsgSetRewardWithholdingResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if err := k.Keeper.SetRewardWithholding(ctx, msg.Account, msg.Recipient, msg.Bps); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSetRewardWithholding,
+			sdk.NewAttribute(types.AttributeKeyAccount, msg.Account),
+			sdk.NewAttribute(types.AttributeKeyRecipient, msg.Recipient),
+			sdk.NewAttribute(types.AttributeKeyWithholdingBps, strconv.FormatUint(uint64(msg.Bps), 10)),
+		),
+	)
+
+	return &types.MsgSetRewardWithholdingResponse{}, nil
+}
+
+// SpendTreasury pays funds of the community treasury; only governance may call it
+func (k msgServer) SpendTreasury(goCtx context.Context, msg *types.MsgSpendTreasury) (*types.MsgSpendTreasuryResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if msg.Authority != k.authority {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "expected %s to spend the treasury, got %s", k.authority, msg.Authority)
+	}
+
+	recipient, err := sdk.AccAddressFromBech32(msg.Recipient)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid recipient address (%s)", err)
+	}
+	amount, ok := sdk.NewIntFromString(msg.Amount)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid amount: %s", msg.Amount)
+	}
+
+	if err := k.Keeper.SpendTreasury(ctx, recipient, amount); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Emit event
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSpendTreasury,
+			sdk.NewAttribute(types.AttributeKeyRecipient, msg.Recipient),
+			sdk.NewAttribute(types.AttributeKeyAmount, msg.Amount),
+		),
+	)
+
+	return &types.MsgSpendTreasuryResponse{}, nil
+}
@@ -0,0 +1,179 @@
+package keeper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// GetPoolOperator returns a pool registered on a source chain
+func (k Keeper) GetPoolOperator(ctx sdk.Context, operator string, chainId string) (types.PoolOperator, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolOperatorKey))
+	bz := store.Get([]byte(types.PoolOperatorKey + operator + "-" + chainId))
+	if bz == nil {
+		return types.PoolOperator{}, false
+	}
+
+	var pool types.PoolOperator
+	k.cdc.MustUnmarshal(bz, &pool)
+	return pool, true
+}
+
+func (k Keeper) setPoolOperator(ctx sdk.Context, pool types.PoolOperator) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolOperatorKey))
+	bz := k.cdc.MustMarshal(&pool)
+	store.Set([]byte(types.PoolOperatorKey+pool.Address+"-"+pool.ChainId), bz)
+}
+
+// GetPoolMembership returns the pool consent recorded for a miner
+func (k Keeper) GetPoolMembership(ctx sdk.Context, miner string) (types.PoolMembership, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolMembershipKey))
+	bz := store.Get([]byte(miner))
+	if bz == nil {
+		return types.PoolMembership{}, false
+	}
+
+	var membership types.PoolMembership
+	k.cdc.MustUnmarshal(bz, &membership)
+	return membership, true
+}
+
+func (k Keeper) setPoolMembership(ctx sdk.Context, membership types.PoolMembership) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolMembershipKey))
+	bz := k.cdc.MustMarshal(&membership)
+	store.Set([]byte(membership.Miner), bz)
+}
+
+func (k Keeper) deletePoolMembership(ctx sdk.Context, miner string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PoolMembershipKey))
+	store.Delete([]byte(miner))
+}
+
+// SetPoolFeeSchedule replaces a pool's fee schedule and bumps its version.
+// Members keep their consent to the previous schedule, so no fee is taken from
+// them until they rejoin under the new one.
+func (k Keeper) SetPoolFeeSchedule(ctx sdk.Context, operator string, chainId string, feeBps uint32, feeRecipient string) (types.PoolOperator, error) {
+	pool, found := k.GetPoolOperator(ctx, operator, chainId)
+	if !found {
+		return types.PoolOperator{}, fmt.Errorf("no pool registered for %s on %s", operator, chainId)
+	}
+
+	schedule := types.FeeSchedule{FeeBps: feeBps, FeeRecipient: feeRecipient}
+	if err := schedule.Validate(); err != nil {
+		return types.PoolOperator{}, err
+	}
+	if pool.FeeSchedule != nil {
+		schedule.Version = pool.FeeSchedule.Version
+	}
+	schedule.Version++
+
+	pool.FeeSchedule = &schedule
+	k.setPoolOperator(ctx, pool)
+
+	return pool, nil
+}
+
+// JoinPool records a miner's consent to the pool's current fee schedule. The
+// message carrying it is signed by both the miner and the operator, so the
+// stored hash is the schedule both parties agreed to.
+func (k Keeper) JoinPool(ctx sdk.Context, miner string, operator string, chainId string, feeScheduleHash []byte) error {
+	pool, found := k.GetPoolOperator(ctx, operator, chainId)
+	if !found {
+		return fmt.Errorf("no pool registered for %s on %s", operator, chainId)
+	}
+	if pool.FeeSchedule == nil {
+		return fmt.Errorf("pool %s has not published a fee schedule", operator)
+	}
+	if !bytes.Equal(feeScheduleHash, types.FeeScheduleHash(pool)) {
+		return fmt.Errorf("consent does not match the current fee schedule of pool %s (version %d)",
+			operator, pool.FeeSchedule.Version)
+	}
+
+	// Rejoining the same pool only refreshes the consent
+	if current, found := k.GetPoolMembership(ctx, miner); found {
+		if current.Pool != operator || current.ChainId != chainId {
+			return fmt.Errorf("miner %s already belongs to pool %s", miner, current.Pool)
+		}
+	} else {
+		pool.Miners = append(pool.Miners, miner)
+		k.setPoolOperator(ctx, pool)
+	}
+
+	k.setPoolMembership(ctx, types.PoolMembership{
+		Miner:           miner,
+		Pool:            operator,
+		ChainId:         chainId,
+		FeeScheduleHash: feeScheduleHash,
+		JoinedHeight:    ctx.BlockHeight(),
+	})
+
+	return nil
+}
+
+// LeavePool removes a miner from its pool. Leaving needs no operator approval.
+func (k Keeper) LeavePool(ctx sdk.Context, miner string) (types.PoolMembership, error) {
+	membership, found := k.GetPoolMembership(ctx, miner)
+	if !found {
+		return types.PoolMembership{}, fmt.Errorf("miner %s is not in a pool", miner)
+	}
+
+	if pool, found := k.GetPoolOperator(ctx, membership.Pool, membership.ChainId); found {
+		miners := pool.Miners[:0]
+		for _, m := range pool.Miners {
+			if m != miner {
+				miners = append(miners, m)
+			}
+		}
+		pool.Miners = miners
+		k.setPoolOperator(ctx, pool)
+	}
+
+	k.deletePoolMembership(ctx, miner)
+	return membership, nil
+}
+
+// poolFeeShare returns the part of a miner's reward owed to its pool. A fee is
+// only taken when the miner's recorded consent matches the pool's current fee
+// schedule, so an operator cannot raise fees without members agreeing again.
+func (k Keeper) poolFeeShare(ctx sdk.Context, miner sdk.AccAddress, reward sdk.Int) (types.PoolFeePayout, bool) {
+	membership, found := k.GetPoolMembership(ctx, miner.String())
+	if !found {
+		return types.PoolFeePayout{}, false
+	}
+
+	pool, found := k.GetPoolOperator(ctx, membership.Pool, membership.ChainId)
+	if !found || pool.FeeSchedule == nil || pool.FeeSchedule.FeeBps == 0 {
+		return types.PoolFeePayout{}, false
+	}
+
+	if !bytes.Equal(membership.FeeScheduleHash, types.FeeScheduleHash(pool)) {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypePoolConsentMismatch,
+				sdk.NewAttribute(types.AttributeKeyMiner, membership.Miner),
+				sdk.NewAttribute(types.AttributeKeyPool, membership.Pool),
+				sdk.NewAttribute(types.AttributeKeyFeeScheduleHash, hex.EncodeToString(membership.FeeScheduleHash)),
+			),
+		)
+		return types.PoolFeePayout{}, false
+	}
+
+	fee := reward.MulRaw(int64(pool.FeeSchedule.FeeBps)).QuoRaw(10000)
+	if !fee.IsPositive() {
+		return types.PoolFeePayout{}, false
+	}
+
+	return types.PoolFeePayout{
+		Pool:         membership.Pool,
+		ChainId:      membership.ChainId,
+		Miner:        membership.Miner,
+		Amount:       fee.String(),
+		FeeRecipient: pool.FeeSchedule.FeeRecipient,
+	}, true
+}
@@ -0,0 +1,208 @@
+package keeper
+
+import (
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// maxRewardOwnerHops bounds how many chained rotations are followed when paying rewards
+const maxRewardOwnerHops = 8
+
+// SetRecoveryConfig stores the guardian set for an account
+func (k Keeper) SetRecoveryConfig(ctx sdk.Context, config types.RecoveryConfig) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RecoveryConfigKey))
+	bz := k.cdc.MustMarshal(&config)
+	store.Set([]byte(config.Account), bz)
+}
+
+// GetRecoveryConfig returns the guardian set for an account
+func (k Keeper) GetRecoveryConfig(ctx sdk.Context, account string) (types.RecoveryConfig, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RecoveryConfigKey))
+	bz := store.Get([]byte(account))
+	if bz == nil {
+		return types.RecoveryConfig{}, false
+	}
+
+	var config types.RecoveryConfig
+	k.cdc.MustUnmarshal(bz, &config)
+	return config, true
+}
+
+// GetPendingRecovery returns the in-flight recovery for an account
+func (k Keeper) GetPendingRecovery(ctx sdk.Context, account string) (types.PendingRecovery, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PendingRecoveryKey))
+	bz := store.Get([]byte(account))
+	if bz == nil {
+		return types.PendingRecovery{}, false
+	}
+
+	var pending types.PendingRecovery
+	k.cdc.MustUnmarshal(bz, &pending)
+	return pending, true
+}
+
+func (k Keeper) setPendingRecovery(ctx sdk.Context, pending types.PendingRecovery) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PendingRecoveryKey))
+	bz := k.cdc.MustMarshal(&pending)
+	store.Set([]byte(pending.Account), bz)
+}
+
+func (k Keeper) deletePendingRecovery(ctx sdk.Context, account string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PendingRecoveryKey))
+	store.Delete([]byte(account))
+}
+
+// SetRecoveryGuardians replaces an account's guardian set and drops any pending
+// recovery collected under the previous set. An account already recovered is
+// refused: its guardians moved to the new owner, and the old key, possibly
+// the one lost or stolen, must not point its rewards anywhere else.
+func (k Keeper) SetRecoveryGuardians(ctx sdk.Context, config types.RecoveryConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if owner, found := k.GetRewardOwner(ctx, config.Account); found {
+		return fmt.Errorf("account %s was recovered to %s, whose guardians protect it", config.Account, owner)
+	}
+
+	k.SetRecoveryConfig(ctx, config)
+	k.deletePendingRecovery(ctx, config.Account)
+	return nil
+}
+
+// ApproveRecovery records a guardian approval. When the threshold is reached the
+// recovery is scheduled DelayBlocks in the future. Guardians must agree on a
+// single key: approving another new owner is refused while a recovery is
+// pending, so one guardian cannot wipe the others' approvals. Only the owner
+// cancelling it, or the recovery expiring unscheduled, makes way for another.
+func (k Keeper) ApproveRecovery(ctx sdk.Context, guardian, account, newOwner string) (types.PendingRecovery, error) {
+	config, found := k.GetRecoveryConfig(ctx, account)
+	if !found {
+		return types.PendingRecovery{}, fmt.Errorf("account %s has no guardians", account)
+	}
+	if !config.IsGuardian(guardian) {
+		return types.PendingRecovery{}, fmt.Errorf("%s is not a guardian of %s", guardian, account)
+	}
+
+	pending, found := k.GetPendingRecovery(ctx, account)
+	if found && pending.Expired(ctx.BlockHeight(), config.DelayBlocks) {
+		found = false
+	}
+	if !found {
+		pending = types.PendingRecovery{
+			Account:     account,
+			NewOwner:    newOwner,
+			StartHeight: ctx.BlockHeight(),
+		}
+	} else if pending.NewOwner != newOwner {
+		return pending, fmt.Errorf("a recovery of %s to %s is pending", account, pending.NewOwner)
+	}
+
+	if pending.HasApproved(guardian) {
+		return pending, fmt.Errorf("guardian %s already approved", guardian)
+	}
+	pending.Approvals = append(pending.Approvals, guardian)
+
+	if pending.ExecuteHeight == 0 && uint32(len(pending.Approvals)) >= config.Threshold {
+		pending.ExecuteHeight = ctx.BlockHeight() + config.DelayBlocks
+	}
+
+	k.setPendingRecovery(ctx, pending)
+	return pending, nil
+}
+
+// CancelRecovery lets the current owner abort a pending recovery during the delay
+func (k Keeper) CancelRecovery(ctx sdk.Context, account string) error {
+	if _, found := k.GetPendingRecovery(ctx, account); !found {
+		return fmt.Errorf("no pending recovery for %s", account)
+	}
+
+	k.deletePendingRecovery(ctx, account)
+	return nil
+}
+
+// ExecuteMatureRecoveries rotates reward ownership for every recovery whose delay elapsed
+func (k Keeper) ExecuteMatureRecoveries(ctx sdk.Context) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.PendingRecoveryKey))
+	iterator := store.Iterator(nil, nil)
+
+	var matured []types.PendingRecovery
+	for ; iterator.Valid(); iterator.Next() {
+		var pending types.PendingRecovery
+		k.cdc.MustUnmarshal(iterator.Value(), &pending)
+
+		if pending.ExecuteHeight > 0 && pending.ExecuteHeight <= ctx.BlockHeight() {
+			matured = append(matured, pending)
+		}
+	}
+	iterator.Close()
+
+	for _, pending := range matured {
+		k.SetRewardOwner(ctx, pending.Account, pending.NewOwner)
+		k.deletePendingRecovery(ctx, pending.Account)
+
+		// The new owner inherits the guardian set so it stays recoverable
+		if config, found := k.GetRecoveryConfig(ctx, pending.Account); found {
+			store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RecoveryConfigKey))
+			store.Delete([]byte(pending.Account))
+			config.Account = pending.NewOwner
+			k.SetRecoveryConfig(ctx, config)
+		}
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeExecuteRecovery,
+				sdk.NewAttribute(types.AttributeKeyAccount, pending.Account),
+				sdk.NewAttribute(types.AttributeKeyNewOwner, pending.NewOwner),
+				sdk.NewAttribute(types.AttributeKeyBlockHeight, sdk.NewInt(ctx.BlockHeight()).String()),
+			),
+		)
+
+		k.logger.Info("Executed account recovery",
+			"account", pending.Account,
+			"new_owner", pending.NewOwner,
+			"approvals", len(pending.Approvals))
+	}
+}
+
+// SetRewardOwner redirects all future rewards earned by account to owner
+func (k Keeper) SetRewardOwner(ctx sdk.Context, account, owner string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RewardOwnerKey))
+	store.Set([]byte(account), []byte(owner))
+}
+
+// GetRewardOwner returns the owner an account's rewards were rotated to by a
+// recovery, if any
+func (k Keeper) GetRewardOwner(ctx sdk.Context, account string) (string, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RewardOwnerKey))
+	bz := store.Get([]byte(account))
+	if bz == nil {
+		return "", false
+	}
+	return string(bz), true
+}
+
+// GetRewardRecipient resolves where rewards earned by account are paid, following
+// rotations so a recovered account that is itself later recovered still resolves
+func (k Keeper) GetRewardRecipient(ctx sdk.Context, account sdk.AccAddress) sdk.AccAddress {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RewardOwnerKey))
+
+	current := account.String()
+	for i := 0; i < maxRewardOwnerHops; i++ {
+		bz := store.Get([]byte(current))
+		if bz == nil {
+			break
+		}
+		current = string(bz)
+	}
+
+	recipient, err := sdk.AccAddressFromBech32(current)
+	if err != nil {
+		return account
+	}
+	return recipient
+}
@@ -0,0 +1,144 @@
+package keeper
+
+import (
+	"strconv"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// Block rewards are shared by the hash power of the active rigs. Rather than
+// summing every rig each block, the keeper keeps the total and each owner's
+// subtotal up to date as rigs are set: a rig's old contribution is taken out
+// and its new one added, so registering, updating or deactivating a rig only
+// updates a few counters, and rewards are shared by iterating the owners with
+// active rigs.
+
+// miningRigKey is the key of a rig NFT within the rig store
+func miningRigKey(tokenId uint64, chainId string) []byte {
+	return []byte(types.MiningRigKey + strconv.FormatUint(tokenId, 10) + "-" + chainId)
+}
+
+// GetMiningRig returns a rig NFT registered from a source chain
+func (k Keeper) GetMiningRig(ctx sdk.Context, tokenId uint64, chainId string) (types.MiningRigNFT, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
+	bz := store.Get(miningRigKey(tokenId, chainId))
+	if bz == nil {
+		return types.MiningRigNFT{}, false
+	}
+
+	var rig types.MiningRigNFT
+	k.cdc.MustUnmarshal(bz, &rig)
+	return rig, true
+}
+
+// SetMiningRig stores a rig NFT and moves the hash power it had to the hash
+// power it has now in the total and the subtotals of its owners
+func (k Keeper) SetMiningRig(ctx sdk.Context, rig types.MiningRigNFT) {
+	if old, found := k.GetMiningRig(ctx, rig.TokenId, rig.ChainId); found && old.IsActive {
+		k.removeHashPower(ctx, old.Owner, old.HashPower)
+	}
+	if rig.IsActive {
+		k.addHashPower(ctx, rig.Owner, rig.HashPower)
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
+	store.Set(miningRigKey(rig.TokenId, rig.ChainId), k.cdc.MustMarshal(&rig))
+}
+
+// GetTotalHashPower returns the hash power of all active mining rigs
+func (k Keeper) GetTotalHashPower(ctx sdk.Context) uint64 {
+	bz := ctx.KVStore(k.storeKey).Get(types.KeyPrefix(types.TotalHashPowerKey))
+	if bz == nil {
+		return 0
+	}
+	return sdk.BigEndianToUint64(bz)
+}
+
+// GetOwnerHashPower returns the hash power of the active rigs of an owner
+func (k Keeper) GetOwnerHashPower(ctx sdk.Context, owner string) uint64 {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.OwnerHashPowerKey))
+	bz := store.Get([]byte(owner))
+	if bz == nil {
+		return 0
+	}
+	return sdk.BigEndianToUint64(bz)
+}
+
+func (k Keeper) addHashPower(ctx sdk.Context, owner string, hashPower uint64) {
+	k.setTotalHashPower(ctx, k.GetTotalHashPower(ctx)+hashPower)
+	k.setOwnerHashPower(ctx, owner, k.GetOwnerHashPower(ctx, owner)+hashPower)
+}
+
+func (k Keeper) removeHashPower(ctx sdk.Context, owner string, hashPower uint64) {
+	k.setTotalHashPower(ctx, subHashPower(k.GetTotalHashPower(ctx), hashPower))
+	k.setOwnerHashPower(ctx, owner, subHashPower(k.GetOwnerHashPower(ctx, owner), hashPower))
+}
+
+func (k Keeper) setTotalHashPower(ctx sdk.Context, hashPower uint64) {
+	ctx.KVStore(k.storeKey).Set(types.KeyPrefix(types.TotalHashPowerKey), sdk.Uint64ToBigEndian(hashPower))
+}
+
+// setOwnerHashPower stores the subtotal of an owner, dropping owners left
+// with no active hash power so reward distribution skips them
+func (k Keeper) setOwnerHashPower(ctx sdk.Context, owner string, hashPower uint64) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.OwnerHashPowerKey))
+	if hashPower == 0 {
+		store.Delete([]byte(owner))
+		return
+	}
+	store.Set([]byte(owner), sdk.Uint64ToBigEndian(hashPower))
+}
+
+func subHashPower(total, hashPower uint64) uint64 {
+	if hashPower > total {
+		return 0
+	}
+	return total - hashPower
+}
+
+// RebuildHashPower recomputes the total and owner subtotals from the stored
+// rigs. Migrate1to2 runs it to introduce the aggregates, and InitGenesis
+// after writing the rigs of the genesis to the store directly.
+func (k Keeper) RebuildHashPower(ctx sdk.Context) {
+	var total uint64
+	subtotals := make(map[string]uint64)
+	var owners []string
+
+	rigs := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.MiningRigKey))
+	iterator := rigs.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		var rig types.MiningRigNFT
+		k.cdc.MustUnmarshal(iterator.Value(), &rig)
+		if !rig.IsActive {
+			continue
+		}
+		if _, seen := subtotals[rig.Owner]; !seen {
+			owners = append(owners, rig.Owner)
+		}
+		total += rig.HashPower
+		subtotals[rig.Owner] += rig.HashPower
+	}
+	iterator.Close()
+
+	// Stores are not written while iterated, so stale subtotals are deleted
+	// once their keys are collected
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.OwnerHashPowerKey))
+	var stale [][]byte
+	ownerIterator := store.Iterator(nil, nil)
+	for ; ownerIterator.Valid(); ownerIterator.Next() {
+		stale = append(stale, ownerIterator.Key())
+	}
+	ownerIterator.Close()
+	for _, key := range stale {
+		store.Delete(key)
+	}
+
+	k.setTotalHashPower(ctx, total)
+	for _, owner := range owners {
+		k.setOwnerHashPower(ctx, owner, subtotals[owner])
+	}
+}
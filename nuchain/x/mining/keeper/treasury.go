@@ -0,0 +1,79 @@
+package keeper
+
+import (
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"nuchain/x/mining/types"
+)
+
+// TreasuryAddress returns the module account of the community treasury
+func TreasuryAddress() sdk.AccAddress {
+	return authtypes.NewModuleAddress(types.TreasuryModuleName)
+}
+
+// TreasuryBalance returns the funds of the treasury not spent yet
+func (k Keeper) TreasuryBalance(ctx sdk.Context) sdk.Int {
+	return k.bankKeeper.GetBalance(ctx, TreasuryAddress(), "nu").Amount
+}
+
+// GetTreasuryAccrued returns the total of the treasury tax paid so far
+func (k Keeper) GetTreasuryAccrued(ctx sdk.Context) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(types.KeyPrefix(types.TreasuryAccruedKey))
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	accrued, ok := sdk.NewIntFromString(string(bz))
+	if !ok {
+		return sdk.ZeroInt()
+	}
+	return accrued
+}
+
+// payTreasuryTax mints the TreasuryTax share of a block reward to the
+// treasury and returns what is left for the miners
+func (k Keeper) payTreasuryTax(ctx sdk.Context, reward sdk.Int) (sdk.Int, error) {
+	rate, err := sdk.NewDecFromStr(k.GetParams(ctx).TreasuryTax)
+	if err != nil {
+		return reward, nil
+	}
+	tax := rate.MulInt(reward).TruncateInt()
+	if !tax.IsPositive() {
+		return reward, nil
+	}
+
+	coins := sdk.NewCoins(sdk.NewCoin("nu", tax))
+	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
+		return reward, err
+	}
+	if err := k.bankKeeper.SendCoinsFromModuleToModule(ctx, types.ModuleName, types.TreasuryModuleName, coins); err != nil {
+		return reward, err
+	}
+	accrued := k.GetTreasuryAccrued(ctx).Add(tax)
+	ctx.KVStore(k.storeKey).Set(types.KeyPrefix(types.TreasuryAccruedKey), []byte(accrued.String()))
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeTreasuryTax,
+			sdk.NewAttribute(types.AttributeKeyAmount, tax.String()),
+			sdk.NewAttribute(types.AttributeKeyBlockHeight, strconv.FormatInt(ctx.BlockHeight(), 10)),
+		),
+	)
+	return reward.Sub(tax), nil
+}
+
+// SpendTreasury pays amount of the treasury's funds to recipient
+func (k Keeper) SpendTreasury(ctx sdk.Context, recipient sdk.AccAddress, amount sdk.Int) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("treasury spend must be positive: %s", amount)
+	}
+	if balance := k.TreasuryBalance(ctx); balance.LT(amount) {
+		return fmt.Errorf("treasury holds %s, cannot spend %s", balance, amount)
+	}
+
+	coins := sdk.NewCoins(sdk.NewCoin("nu", amount))
+	return k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.TreasuryModuleName, recipient, coins)
+}
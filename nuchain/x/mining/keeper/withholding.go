@@ -0,0 +1,117 @@
+package keeper
+
+import (
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"nuchain/x/mining/types"
+)
+
+// WithholdingPeriod returns the reporting period of the current block
+func (k Keeper) WithholdingPeriod(ctx sdk.Context) uint64 {
+	return uint64(ctx.BlockHeight() / k.GetParams(ctx).WithholdingPeriodBlocks)
+}
+
+// GetRewardWithholding returns the withholding configured by an account
+func (k Keeper) GetRewardWithholding(ctx sdk.Context, account string) (types.RewardWithholding, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RewardWithholdingKey))
+	bz := store.Get([]byte(account))
+	if bz == nil {
+		return types.RewardWithholding{}, false
+	}
+
+	var withholding types.RewardWithholding
+	k.cdc.MustUnmarshal(bz, &withholding)
+	return withholding, true
+}
+
+// SetRewardWithholding withholds bps of the account's mining rewards into
+// recipient. Zero bps removes the withholding.
+func (k Keeper) SetRewardWithholding(ctx sdk.Context, account, recipient string, bps uint32) error {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.RewardWithholdingKey))
+
+	if bps == 0 {
+		store.Delete([]byte(account))
+		return nil
+	}
+
+	withholding := types.RewardWithholding{
+		Account:       account,
+		Recipient:     recipient,
+		Bps:           bps,
+		UpdatedHeight: ctx.BlockHeight(),
+	}
+	if err := withholding.Validate(); err != nil {
+		return err
+	}
+
+	store.Set([]byte(account), k.cdc.MustMarshal(&withholding))
+	return nil
+}
+
+// withholdingReportKey groups reports by account so one account's periods can
+// be listed with a prefix scan
+func withholdingReportKey(account string, period uint64) []byte {
+	return []byte(fmt.Sprintf("%s/%020d", account, period))
+}
+
+// GetWithholdingReport returns an account's totals for a period
+func (k Keeper) GetWithholdingReport(ctx sdk.Context, account string, period uint64) (types.WithholdingReport, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.WithholdingReportKey))
+	bz := store.Get(withholdingReportKey(account, period))
+	if bz == nil {
+		return types.WithholdingReport{}, false
+	}
+
+	var report types.WithholdingReport
+	k.cdc.MustUnmarshal(bz, &report)
+	return report, true
+}
+
+func (k Keeper) setWithholdingReport(ctx sdk.Context, report types.WithholdingReport) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.WithholdingReportKey))
+	bz := k.cdc.MustMarshal(&report)
+	store.Set(withholdingReportKey(report.Account, report.Period), bz)
+}
+
+// withholdReward sends the withheld share of a reward to the account's
+// withholding recipient and returns what is left for the account. Rewards of
+// accounts without withholding are returned unchanged and not reported.
+func (k Keeper) withholdReward(ctx sdk.Context, account sdk.AccAddress, reward sdk.Int) (sdk.Int, error) {
+	withholding, found := k.GetRewardWithholding(ctx, account.String())
+	if !found {
+		return reward, nil
+	}
+
+	withheld := reward.MulRaw(int64(withholding.Bps)).QuoRaw(types.MaxWithholdingBps)
+	if withheld.IsPositive() {
+		if err := k.payFromEscrow(ctx, withholding.Recipient, withheld.String()); err != nil {
+			return reward, fmt.Errorf("failed to withhold reward: %w", err)
+		}
+	}
+
+	period := k.WithholdingPeriod(ctx)
+	report, found := k.GetWithholdingReport(ctx, account.String(), period)
+	if !found {
+		report = types.WithholdingReport{
+			Account:     account.String(),
+			Period:      period,
+			StartHeight: int64(period) * k.GetParams(ctx).WithholdingPeriodBlocks,
+			Gross:       sdk.ZeroInt().String(),
+			Withheld:    sdk.ZeroInt().String(),
+		}
+	}
+
+	gross, _ := sdk.NewIntFromString(report.Gross)
+	total, _ := sdk.NewIntFromString(report.Withheld)
+	report.Gross = gross.Add(reward).String()
+	report.Withheld = total.Add(withheld).String()
+	report.Payouts++
+	report.Recipient = withholding.Recipient
+	k.setWithholdingReport(ctx, report)
+
+	return reward.Sub(withheld), nil
+}
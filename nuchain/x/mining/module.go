@@ -0,0 +1,139 @@
+package mining
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/core/appmodule"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"nuchain/x/mining/keeper"
+	"nuchain/x/mining/types"
+)
+
+var (
+	_ module.AppModuleBasic      = AppModuleBasic{}
+	_ module.HasGenesis          = AppModule{}
+	_ module.HasServices         = AppModule{}
+	_ module.HasConsensusVersion = AppModule{}
+	_ appmodule.AppModule        = AppModule{}
+	_ appmodule.HasBeginBlocker  = AppModule{}
+	_ appmodule.HasEndBlocker    = AppModule{}
+)
+
+// AppModuleBasic implements the stateless parts of the mining module: its
+// codecs, default genesis and gateway routes
+type AppModuleBasic struct{}
+
+// Name returns the module's name
+func (AppModuleBasic) Name() string {
+	return types.ModuleName
+}
+
+// RegisterLegacyAminoCodec registers the amino names of the module's messages
+func (AppModuleBasic) RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
+	types.RegisterCodec(cdc)
+}
+
+// RegisterInterfaces registers the module's messages and Msg service
+func (AppModuleBasic) RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
+	types.RegisterInterfaces(registry)
+}
+
+// DefaultGenesis returns the module's default genesis state. GenesisState
+// is not a proto message, so it is encoded with encoding/json.
+func (AppModuleBasic) DefaultGenesis(codec.JSONCodec) json.RawMessage {
+	bz, err := json.Marshal(types.DefaultGenesis())
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// ValidateGenesis checks the module's genesis state
+func (AppModuleBasic) ValidateGenesis(_ codec.JSONCodec, _ client.TxEncodingConfig, bz json.RawMessage) error {
+	var genState types.GenesisState
+	if err := json.Unmarshal(bz, &genState); err != nil {
+		return fmt.Errorf("failed to unmarshal %s genesis state: %w", types.ModuleName, err)
+	}
+	return genState.Validate()
+}
+
+// RegisterGRPCGatewayRoutes serves the Query service over REST
+func (AppModuleBasic) RegisterGRPCGatewayRoutes(clientCtx client.Context, mux *runtime.ServeMux) {
+	if err := types.RegisterQueryHandlerClient(context.Background(), mux, types.NewQueryClient(clientCtx)); err != nil {
+		panic(err)
+	}
+}
+
+// AppModule is the mining module of the module manager. The app sets the
+// upgrade handler on its own, see CreateUpgradeHandler.
+type AppModule struct {
+	AppModuleBasic
+
+	keeper keeper.Keeper
+}
+
+// NewAppModule returns the mining module of k
+func NewAppModule(k keeper.Keeper) AppModule {
+	return AppModule{keeper: k}
+}
+
+// IsOnePerModuleType implements the depinject.OnePerModuleType interface
+func (AppModule) IsOnePerModuleType() {}
+
+// IsAppModule implements the appmodule.AppModule interface
+func (AppModule) IsAppModule() {}
+
+// ConsensusVersion returns the version of the module's state machine
+func (AppModule) ConsensusVersion() uint64 {
+	return ConsensusVersion
+}
+
+// RegisterServices registers the Msg and Query services and the store
+// migrations up to ConsensusVersion
+func (am AppModule) RegisterServices(cfg module.Configurator) {
+	types.RegisterMsgServer(cfg.MsgServer(), keeper.NewMsgServerImpl(am.keeper))
+	types.RegisterQueryServer(cfg.QueryServer(), am.keeper)
+
+	if err := RegisterMigrations(cfg, am.keeper); err != nil {
+		panic(fmt.Sprintf("failed to register %s migrations: %s", types.ModuleName, err))
+	}
+}
+
+// InitGenesis initializes the module's state from its genesis
+func (am AppModule) InitGenesis(ctx sdk.Context, _ codec.JSONCodec, bz json.RawMessage) {
+	var genState types.GenesisState
+	if err := json.Unmarshal(bz, &genState); err != nil {
+		panic(fmt.Errorf("failed to unmarshal %s genesis state: %w", types.ModuleName, err))
+	}
+	InitGenesis(ctx, am.keeper, genState)
+}
+
+// ExportGenesis returns the module's state as genesis
+func (am AppModule) ExportGenesis(ctx sdk.Context, _ codec.JSONCodec) json.RawMessage {
+	bz, err := json.Marshal(ExportGenesis(ctx, am.keeper))
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// BeginBlock runs BeginBlocker
+func (am AppModule) BeginBlock(ctx context.Context) error {
+	BeginBlocker(sdk.UnwrapSDKContext(ctx), am.keeper)
+	return nil
+}
+
+// EndBlock runs EndBlocker
+func (am AppModule) EndBlock(ctx context.Context) error {
+	EndBlocker(sdk.UnwrapSDKContext(ctx), am.keeper)
+	return nil
+}
@@ -2,22 +2,52 @@ package types
 
 import (
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
 	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/msgservice"
 )
 
+// RegisterCodec registers the amino names used for legacy JSON signing (ledger).
+// Names must match the amino.name options in tx.proto.
 func RegisterCodec(cdc *codec.LegacyAmino) {
-	cdc.RegisterConcrete(&MsgCreateStakingNode{}, "mining/CreateStakingNode", nil)
-	cdc.RegisterConcrete(&MsgProcessCrossChainMessage{}, "mining/ProcessCrossChainMessage", nil)
-	cdc.RegisterConcrete(&MsgUpdateMiningRig{}, "mining/UpdateMiningRig", nil)
+	legacy.RegisterAminoMsg(cdc, &MsgCreateStakingNode{}, "mining/CreateStakingNode")
+	legacy.RegisterAminoMsg(cdc, &MsgProcessCrossChainMessage{}, "mining/ProcessCrossChainMessage")
+	legacy.RegisterAminoMsg(cdc, &MsgUpdateMiningRig{}, "mining/UpdateMiningRig")
+	legacy.RegisterAminoMsg(cdc, &MsgSetRecoveryGuardians{}, "mining/SetRecoveryGuardians")
+	legacy.RegisterAminoMsg(cdc, &MsgApproveRecovery{}, "mining/ApproveRecovery")
+	legacy.RegisterAminoMsg(cdc, &MsgCancelRecovery{}, "mining/CancelRecovery")
+	legacy.RegisterAminoMsg(cdc, &MsgAnnounceMaintenance{}, "mining/AnnounceMaintenance")
+	legacy.RegisterAminoMsg(cdc, &MsgCancelMaintenance{}, "mining/CancelMaintenance")
+	legacy.RegisterAminoMsg(cdc, &MsgSetPoolFeeSchedule{}, "mining/SetPoolFeeSchedule")
+	legacy.RegisterAminoMsg(cdc, &MsgJoinPool{}, "mining/JoinPool")
+	legacy.RegisterAminoMsg(cdc, &MsgLeavePool{}, "mining/LeavePool")
+	legacy.RegisterAminoMsg(cdc, &MsgOpenPoolDispute{}, "mining/OpenPoolDispute")
+	legacy.RegisterAminoMsg(cdc, &MsgSubmitDisputeEvidence{}, "mining/SubmitDisputeEvidence")
+	legacy.RegisterAminoMsg(cdc, &MsgResolvePoolDispute{}, "mining/ResolvePoolDispute")
+	legacy.RegisterAminoMsg(cdc, &MsgSetRewardWithholding{}, "mining/SetRewardWithholding")
+	legacy.RegisterAminoMsg(cdc, &MsgSpendTreasury{}, "mining/SpendTreasury")
 }
 
+// RegisterInterfaces registers the Msg implementations and the generated Msg service
 func RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
 	registry.RegisterImplementations((*sdk.Msg)(nil),
 		&MsgCreateStakingNode{},
 		&MsgProcessCrossChainMessage{},
 		&MsgUpdateMiningRig{},
+		&MsgSetRecoveryGuardians{},
+		&MsgApproveRecovery{},
+		&MsgCancelRecovery{},
+		&MsgAnnounceMaintenance{},
+		&MsgCancelMaintenance{},
+		&MsgSetPoolFeeSchedule{},
+		&MsgJoinPool{},
+		&MsgLeavePool{},
+		&MsgOpenPoolDispute{},
+		&MsgSubmitDisputeEvidence{},
+		&MsgResolvePoolDispute{},
+		&MsgSetRewardWithholding{},
+		&MsgSpendTreasury{},
 	)
 
 	msgservice.RegisterMsgServiceDesc(registry, &_Msg_serviceDesc)
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+const (
+	// MaxDisputeEvidence bounds the evidence stored per dispute
+	MaxDisputeEvidence = 32
+
+	// MaxDisputeReasonLength bounds the free-form reason of a dispute
+	MaxDisputeReasonLength = 512
+
+	// MaxMerkleProofDepth bounds proofs to share logs of up to 2^32 shares
+	MaxMerkleProofDepth = 32
+)
+
+// Share log trees hash leaves and inner nodes with distinct prefixes so a leaf
+// can never be passed off as an inner node
+const (
+	merkleLeafPrefix  = 0x00
+	merkleInnerPrefix = 0x01
+)
+
+// VerifyMerkleProof checks that leaf sits at index in the share log tree with
+// the given root. The proof lists sibling hashes from the leaf upwards.
+func VerifyMerkleProof(root, leaf []byte, index uint64, proof [][]byte) error {
+	if len(proof) > MaxMerkleProofDepth {
+		return fmt.Errorf("merkle proof too deep: %d > %d", len(proof), MaxMerkleProofDepth)
+	}
+	if index>>uint(len(proof)) != 0 {
+		return fmt.Errorf("leaf index %d out of range for proof of depth %d", index, len(proof))
+	}
+
+	hash := sha256.Sum256(append([]byte{merkleLeafPrefix}, leaf...))
+	node := hash[:]
+
+	for _, sibling := range proof {
+		if len(sibling) != sha256.Size {
+			return fmt.Errorf("merkle proof node must be %d bytes", sha256.Size)
+		}
+
+		data := []byte{merkleInnerPrefix}
+		if index&1 == 0 {
+			data = append(append(data, node...), sibling...)
+		} else {
+			data = append(append(data, sibling...), node...)
+		}
+		hash = sha256.Sum256(data)
+		node = hash[:]
+		index >>= 1
+	}
+
+	if !bytes.Equal(node, root) {
+		return fmt.Errorf("merkle proof does not match root")
+	}
+	return nil
+}
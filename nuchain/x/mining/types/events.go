@@ -8,6 +8,28 @@ const (
 	EventTypeDistributeRewards         = "distribute_rewards"
 	EventTypeStakingNodeOnline         = "staking_node_online"
 	EventTypeStakingNodeOffline        = "staking_node_offline"
+	EventTypeSetRecoveryGuardians      = "set_recovery_guardians"
+	EventTypeApproveRecovery           = "approve_recovery"
+	EventTypeCancelRecovery            = "cancel_recovery"
+	EventTypeExecuteRecovery           = "execute_recovery"
+	EventTypeTombstoneStakingNode      = "tombstone_staking_node"
+	EventTypeSuspendStakingRewards     = "suspend_staking_rewards"
+	EventTypeResumeStakingRewards      = "resume_staking_rewards"
+	EventTypeJailStakingNode           = "jail_staking_node"
+	EventTypeUnjailStakingNode         = "unjail_staking_node"
+	EventTypeAnnounceMaintenance       = "announce_maintenance"
+	EventTypeCancelMaintenance         = "cancel_maintenance"
+	EventTypeSetPoolFeeSchedule        = "set_pool_fee_schedule"
+	EventTypeJoinPool                  = "join_pool"
+	EventTypeLeavePool                 = "leave_pool"
+	EventTypePoolConsentMismatch       = "pool_consent_mismatch"
+	EventTypeReleasePoolFee            = "release_pool_fee"
+	EventTypeOpenPoolDispute           = "open_pool_dispute"
+	EventTypeSubmitDisputeEvidence     = "submit_dispute_evidence"
+	EventTypeResolvePoolDispute        = "resolve_pool_dispute"
+	EventTypeSetRewardWithholding      = "set_reward_withholding"
+	EventTypeTreasuryTax               = "treasury_tax"
+	EventTypeSpendTreasury             = "spend_treasury"
 )
 
 // Mining module attribute keys
@@ -28,4 +50,25 @@ const (
 	AttributeKeyBlockHeight       = "block_height"
 	AttributeKeyOperator          = "operator"
 	AttributeKeyVotingPower       = "voting_power"
+	AttributeKeyAccount           = "account"
+	AttributeKeyGuardian          = "guardian"
+	AttributeKeyNewOwner          = "new_owner"
+	AttributeKeyThreshold         = "threshold"
+	AttributeKeyExecuteHeight     = "execute_height"
+	AttributeKeyConsensusAddress  = "consensus_address"
+	AttributeKeyInfractionHeight  = "infraction_height"
+	AttributeKeyMissedBlocks      = "missed_blocks"
+	AttributeKeyJailedUntil       = "jailed_until"
+	AttributeKeyStartHeight       = "start_height"
+	AttributeKeyEndHeight         = "end_height"
+	AttributeKeyMiner             = "miner"
+	AttributeKeyPool              = "pool"
+	AttributeKeyFeeBps            = "fee_bps"
+	AttributeKeyFeeScheduleHash   = "fee_schedule_hash"
+	AttributeKeyFeeVersion        = "fee_version"
+	AttributeKeyEpoch             = "epoch"
+	AttributeKeyDisputeId         = "dispute_id"
+	AttributeKeyMinerRefund       = "miner_refund"
+	AttributeKeyResolver          = "resolver"
+	AttributeKeyWithholdingBps    = "withholding_bps"
 )
\ No newline at end of file
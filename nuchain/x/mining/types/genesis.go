@@ -1,6 +1,10 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
 
 // DefaultIndex is the default global index
 const DefaultIndex uint64 = 1
@@ -46,8 +50,13 @@ func (gs GenesisState) Validate() error {
 		if node.Operator == "" {
 			return fmt.Errorf("staking node operator cannot be empty")
 		}
-		if node.StakedNu < 21*1e18 {
-			return fmt.Errorf("insufficient stake for node %s: %d", node.Operator, node.StakedNu)
+		stake, ok := sdk.NewIntFromString(node.StakedNu)
+		if !ok || stake.IsNegative() {
+			return fmt.Errorf("invalid stake for node %s: %s", node.Operator, node.StakedNu)
+		}
+		// Tombstoned nodes keep their slashed remainder, which may be below the minimum
+		if !node.Tombstoned && stake.LT(NodeStake) {
+			return fmt.Errorf("insufficient stake for node %s: %s", node.Operator, node.StakedNu)
 		}
 	}
 
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestGenesisValidatesNodeStakeAboveUint64(t *testing.T) {
+	if NodeStake.IsUint64() {
+		t.Fatalf("node stake %s fits a uint64", NodeStake)
+	}
+
+	cases := []struct {
+		name  string
+		node  StakingNode
+		valid bool
+	}{
+		{"required stake", StakingNode{Operator: "op", StakedNu: NodeStake.String()}, true},
+		{"below the stake", StakingNode{Operator: "op", StakedNu: NodeStake.Sub(sdk.OneInt()).String()}, false},
+		{"slashed tombstoned node", StakingNode{Operator: "op", StakedNu: "19950000000000000000", Tombstoned: true}, true},
+		{"not an amount", StakingNode{Operator: "op", StakedNu: "21 NU"}, false},
+		{"negative", StakingNode{Operator: "op", StakedNu: "-1", Tombstoned: true}, false},
+	}
+	for _, c := range cases {
+		gs := DefaultGenesis()
+		gs.StakingNodes = []StakingNode{c.node}
+		err := gs.Validate()
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+}
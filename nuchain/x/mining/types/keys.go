@@ -12,6 +12,10 @@ const (
 
 	// MemStoreKey defines the in-memory store key
 	MemStoreKey = "mem_mining"
+
+	// TreasuryModuleName is the module account of the community treasury,
+	// which only governance spends from
+	TreasuryModuleName = "treasury"
 )
 
 var (
@@ -29,6 +33,57 @@ var (
 	
 	// BlockRewardKey is the key prefix for storing block reward data
 	BlockRewardKey = "block_reward/"
+	
+	// RecoveryConfigKey is the key prefix for storing account guardian sets
+	RecoveryConfigKey = "recovery_config/"
+	
+	// PendingRecoveryKey is the key prefix for storing in-flight recoveries
+	PendingRecoveryKey = "pending_recovery/"
+	
+	// RewardOwnerKey is the key prefix for storing rotated reward recipients
+	RewardOwnerKey = "reward_owner/"
+	
+	// StakingNodeConsAddressKey is the key prefix indexing staking nodes by consensus address
+	StakingNodeConsAddressKey = "staking_node_cons/"
+	
+	// TombstoneKey is the key prefix for storing tombstoned staking node operators
+	TombstoneKey = "tombstone/"
+	
+	// NodeLivenessKey is the key prefix for storing staking node signing info
+	NodeLivenessKey = "node_liveness/"
+	
+	// MissedBlockKey is the key prefix for the per-node missed block bitmap
+	MissedBlockKey = "missed_block/"
+	
+	// MaintenanceWindowKey is the key prefix for storing announced maintenance windows
+	MaintenanceWindowKey = "maintenance_window/"
+	
+	// PoolMembershipKey is the key prefix for storing miner pool consents
+	PoolMembershipKey = "pool_membership/"
+	
+	// PoolFeePayoutKey is the key prefix for pool fees escrowed per epoch
+	PoolFeePayoutKey = "pool_fee_payout/"
+	
+	// PoolDisputeKey is the key prefix for storing pool fee disputes
+	PoolDisputeKey = "pool_dispute/"
+	
+	// PoolDisputeCountKey is the key of the last assigned dispute ID
+	PoolDisputeCountKey = "pool_dispute_count"
+	
+	// RewardWithholdingKey is the key prefix for storing reward withholding settings
+	RewardWithholdingKey = "reward_withholding/"
+	
+	// WithholdingReportKey is the key prefix for withholding totals per account and period
+	WithholdingReportKey = "withholding_report/"
+	
+	// TreasuryAccruedKey is the key of the total of the treasury tax paid
+	TreasuryAccruedKey = "treasury_accrued"
+	
+	// TotalHashPowerKey is the key of the hash power of all active mining rigs
+	TotalHashPowerKey = "total_hash_power"
+	
+	// OwnerHashPowerKey is the key prefix for the hash power of the active rigs of each owner
+	OwnerHashPowerKey = "owner_hash_power/"
 )
 
 func KeyPrefix(p string) []byte {
@@ -5,30 +5,17 @@ import (
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
-const (
-	TypeMsgCreateStakingNode         = "create_staking_node"
-	TypeMsgProcessCrossChainMessage  = "process_cross_chain_message"
-	TypeMsgUpdateMiningRig           = "update_mining_rig"
-)
-
 var _ sdk.Msg = &MsgCreateStakingNode{}
 
-func NewMsgCreateStakingNode(creator string, moniker string, supportedChains []string) *MsgCreateStakingNode {
+func NewMsgCreateStakingNode(creator string, moniker string, supportedChains []string, consensusAddress string) *MsgCreateStakingNode {
 	return &MsgCreateStakingNode{
-		Creator:         creator,
-		Moniker:         moniker,
-		SupportedChains: supportedChains,
+		Creator:          creator,
+		Moniker:          moniker,
+		SupportedChains:  supportedChains,
+		ConsensusAddress: consensusAddress,
 	}
 }
 
-func (msg *MsgCreateStakingNode) Route() string {
-	return RouterKey
-}
-
-func (msg *MsgCreateStakingNode) Type() string {
-	return TypeMsgCreateStakingNode
-}
-
 func (msg *MsgCreateStakingNode) GetSigners() []sdk.AccAddress {
 	creator, err := sdk.AccAddressFromBech32(msg.Creator)
 	if err != nil {
@@ -56,6 +43,10 @@ func (msg *MsgCreateStakingNode) ValidateBasic() error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "supported chains cannot be empty")
 	}
 	
+	if _, err := sdk.ConsAddressFromBech32(msg.ConsensusAddress); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid consensus address (%s)", err)
+	}
+	
 	return nil
 }
 
@@ -71,14 +62,6 @@ func NewMsgProcessCrossChainMessage(creator string, sourceChain string, messageT
 	}
 }
 
-func (msg *MsgProcessCrossChainMessage) Route() string {
-	return RouterKey
-}
-
-func (msg *MsgProcessCrossChainMessage) Type() string {
-	return TypeMsgProcessCrossChainMessage
-}
-
 func (msg *MsgProcessCrossChainMessage) GetSigners() []sdk.AccAddress {
 	creator, err := sdk.AccAddressFromBech32(msg.Creator)
 	if err != nil {
@@ -123,14 +106,6 @@ func NewMsgUpdateMiningRig(creator string, tokenId uint64, chainId string, contr
 	}
 }
 
-func (msg *MsgUpdateMiningRig) Route() string {
-	return RouterKey
-}
-
-func (msg *MsgUpdateMiningRig) Type() string {
-	return TypeMsgUpdateMiningRig
-}
-
 func (msg *MsgUpdateMiningRig) GetSigners() []sdk.AccAddress {
 	creator, err := sdk.AccAddressFromBech32(msg.Creator)
 	if err != nil {
@@ -161,33 +136,524 @@ func (msg *MsgUpdateMiningRig) ValidateBasic() error {
 	return nil
 }
 
-// Message types for the mining module
-type MsgCreateStakingNode struct {
-	Creator         string   `json:"creator"`
-	Moniker         string   `json:"moniker"`
-	SupportedChains []string `json:"supported_chains"`
+var _ sdk.Msg = &MsgSetRecoveryGuardians{}
+
+func NewMsgSetRecoveryGuardians(creator string, guardians []string, threshold uint32, delayBlocks int64) *MsgSetRecoveryGuardians {
+	return &MsgSetRecoveryGuardians{
+		Creator:     creator,
+		Guardians:   guardians,
+		Threshold:   threshold,
+		DelayBlocks: delayBlocks,
+	}
+}
+
+func (msg *MsgSetRecoveryGuardians) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgSetRecoveryGuardians) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSetRecoveryGuardians) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	config := RecoveryConfig{
+		Account:     msg.Creator,
+		Guardians:   msg.Guardians,
+		Threshold:   msg.Threshold,
+		DelayBlocks: msg.DelayBlocks,
+	}
+	if err := config.Validate(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgApproveRecovery{}
+
+func NewMsgApproveRecovery(creator string, account string, newOwner string) *MsgApproveRecovery {
+	return &MsgApproveRecovery{
+		Creator:  creator,
+		Account:  account,
+		NewOwner: newOwner,
+	}
+}
+
+func (msg *MsgApproveRecovery) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgApproveRecovery) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgApproveRecovery) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid guardian address (%s)", err)
+	}
+	
+	if _, err := sdk.AccAddressFromBech32(msg.Account); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid account address (%s)", err)
+	}
+	
+	if _, err := sdk.AccAddressFromBech32(msg.NewOwner); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid new owner address (%s)", err)
+	}
+	
+	if msg.Account == msg.NewOwner {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "new owner must differ from the account")
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgCancelRecovery{}
+
+func NewMsgCancelRecovery(creator string) *MsgCancelRecovery {
+	return &MsgCancelRecovery{
+		Creator: creator,
+	}
+}
+
+func (msg *MsgCancelRecovery) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgCancelRecovery) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgCancelRecovery) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgAnnounceMaintenance{}
+
+func NewMsgAnnounceMaintenance(creator string, startHeight int64, endHeight int64) *MsgAnnounceMaintenance {
+	return &MsgAnnounceMaintenance{
+		Creator:     creator,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+}
+
+func (msg *MsgAnnounceMaintenance) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgAnnounceMaintenance) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgAnnounceMaintenance) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	if msg.StartHeight <= 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "start height must be positive")
+	}
+	
+	if msg.EndHeight <= msg.StartHeight {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "end height must be after start height")
+	}
+	
+	return nil
 }
 
-type MsgCreateStakingNodeResponse struct{}
+var _ sdk.Msg = &MsgCancelMaintenance{}
 
-type MsgProcessCrossChainMessage struct {
-	Creator     string `json:"creator"`
-	SourceChain string `json:"source_chain"`
-	MessageType string `json:"message_type"`
-	Payload     []byte `json:"payload"`
-	Nonce       uint64 `json:"nonce"`
+func NewMsgCancelMaintenance(creator string) *MsgCancelMaintenance {
+	return &MsgCancelMaintenance{
+		Creator: creator,
+	}
 }
 
-type MsgProcessCrossChainMessageResponse struct{}
+func (msg *MsgCancelMaintenance) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
 
-type MsgUpdateMiningRig struct {
-	Creator         string `json:"creator"`
-	TokenId         uint64 `json:"token_id"`
-	ChainId         string `json:"chain_id"`
-	ContractAddress string `json:"contract_address"`
-	HashPower       uint64 `json:"hash_power"`
-	WattConsumption uint64 `json:"watt_consumption"`
-	IsActive        bool   `json:"is_active"`
+func (msg *MsgCancelMaintenance) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
 }
 
-type MsgUpdateMiningRigResponse struct{}
\ No newline at end of file
+func (msg *MsgCancelMaintenance) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgSetPoolFeeSchedule{}
+
+func NewMsgSetPoolFeeSchedule(creator string, chainId string, feeBps uint32, feeRecipient string) *MsgSetPoolFeeSchedule {
+	return &MsgSetPoolFeeSchedule{
+		Creator:      creator,
+		ChainId:      chainId,
+		FeeBps:       feeBps,
+		FeeRecipient: feeRecipient,
+	}
+}
+
+func (msg *MsgSetPoolFeeSchedule) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgSetPoolFeeSchedule) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSetPoolFeeSchedule) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	if msg.ChainId == "" {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "chain ID cannot be empty")
+	}
+	
+	schedule := FeeSchedule{FeeBps: msg.FeeBps, FeeRecipient: msg.FeeRecipient}
+	if err := schedule.Validate(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgJoinPool{}
+
+func NewMsgJoinPool(miner string, operator string, chainId string, feeScheduleHash []byte) *MsgJoinPool {
+	return &MsgJoinPool{
+		Miner:           miner,
+		Operator:        operator,
+		ChainId:         chainId,
+		FeeScheduleHash: feeScheduleHash,
+	}
+}
+
+// GetSigners requires the miner's consent and the operator's co-signature
+func (msg *MsgJoinPool) GetSigners() []sdk.AccAddress {
+	miner, err := sdk.AccAddressFromBech32(msg.Miner)
+	if err != nil {
+		panic(err)
+	}
+	operator, err := sdk.AccAddressFromBech32(msg.Operator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{miner, operator}
+}
+
+func (msg *MsgJoinPool) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgJoinPool) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Miner)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid miner address (%s)", err)
+	}
+	
+	_, err = sdk.AccAddressFromBech32(msg.Operator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid operator address (%s)", err)
+	}
+	
+	if msg.Miner == msg.Operator {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "operator cannot join its own pool")
+	}
+	
+	if msg.ChainId == "" {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "chain ID cannot be empty")
+	}
+	
+	if len(msg.FeeScheduleHash) != 32 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "fee schedule hash must be 32 bytes")
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgLeavePool{}
+
+func NewMsgLeavePool(miner string) *MsgLeavePool {
+	return &MsgLeavePool{
+		Miner: miner,
+	}
+}
+
+func (msg *MsgLeavePool) GetSigners() []sdk.AccAddress {
+	miner, err := sdk.AccAddressFromBech32(msg.Miner)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{miner}
+}
+
+func (msg *MsgLeavePool) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgLeavePool) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Miner)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid miner address (%s)", err)
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgOpenPoolDispute{}
+
+func NewMsgOpenPoolDispute(creator string, pool string, chainId string, epoch uint64, reason string) *MsgOpenPoolDispute {
+	return &MsgOpenPoolDispute{
+		Creator: creator,
+		Pool:    pool,
+		ChainId: chainId,
+		Epoch:   epoch,
+		Reason:  reason,
+	}
+}
+
+func (msg *MsgOpenPoolDispute) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgOpenPoolDispute) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgOpenPoolDispute) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	_, err = sdk.AccAddressFromBech32(msg.Pool)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid pool address (%s)", err)
+	}
+	
+	if msg.ChainId == "" {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "chain ID cannot be empty")
+	}
+	
+	if len(msg.Reason) > MaxDisputeReasonLength {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "reason exceeds %d characters", MaxDisputeReasonLength)
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgSubmitDisputeEvidence{}
+
+func (msg *MsgSubmitDisputeEvidence) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgSubmitDisputeEvidence) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSubmitDisputeEvidence) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	if len(msg.ShareLogHash) != 32 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "share log hash must be 32 bytes")
+	}
+	
+	if len(msg.MerkleRoot) != 32 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "merkle root must be 32 bytes")
+	}
+	
+	if len(msg.Leaf) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "leaf cannot be empty")
+	}
+	
+	if err := VerifyMerkleProof(msg.MerkleRoot, msg.Leaf, msg.LeafIndex, msg.MerkleProof); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgResolvePoolDispute{}
+
+func NewMsgResolvePoolDispute(authority string, disputeId uint64, minerRefund string) *MsgResolvePoolDispute {
+	return &MsgResolvePoolDispute{
+		Authority:   authority,
+		DisputeId:   disputeId,
+		MinerRefund: minerRefund,
+	}
+}
+
+func (msg *MsgResolvePoolDispute) GetSigners() []sdk.AccAddress {
+	authority, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{authority}
+}
+
+func (msg *MsgResolvePoolDispute) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgResolvePoolDispute) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid authority address (%s)", err)
+	}
+	
+	refund, ok := sdk.NewIntFromString(msg.MinerRefund)
+	if !ok || refund.IsNegative() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid miner refund: %s", msg.MinerRefund)
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgSetRewardWithholding{}
+
+func NewMsgSetRewardWithholding(account string, recipient string, bps uint32) *MsgSetRewardWithholding {
+	return &MsgSetRewardWithholding{
+		Account:   account,
+		Recipient: recipient,
+		Bps:       bps,
+	}
+}
+
+func (msg *MsgSetRewardWithholding) GetSigners() []sdk.AccAddress {
+	account, err := sdk.AccAddressFromBech32(msg.Account)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{account}
+}
+
+func (msg *MsgSetRewardWithholding) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSetRewardWithholding) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Account)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid account address (%s)", err)
+	}
+
+	// A zero share clears the withholding and needs no recipient
+	if msg.Bps == 0 {
+		return nil
+	}
+
+	withholding := RewardWithholding{Account: msg.Account, Recipient: msg.Recipient, Bps: msg.Bps}
+	if err := withholding.Validate(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	return nil
+}
+
+var _ sdk.Msg = &MsgSpendTreasury{}
+
+func NewMsgSpendTreasury(authority string, recipient string, amount string) *MsgSpendTreasury {
+	return &MsgSpendTreasury{
+		Authority: authority,
+		Recipient: recipient,
+		Amount:    amount,
+	}
+}
+
+func (msg *MsgSpendTreasury) GetSigners() []sdk.AccAddress {
+	authority, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{authority}
+}
+
+func (msg *MsgSpendTreasury) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSpendTreasury) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid authority address (%s)", err)
+	}
+	
+	_, err = sdk.AccAddressFromBech32(msg.Recipient)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid recipient address (%s)", err)
+	}
+	
+	amount, ok := sdk.NewIntFromString(msg.Amount)
+	if !ok || !amount.IsPositive() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid amount: %s", msg.Amount)
+	}
+	
+	return nil
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"fmt"
 	
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	"gopkg.in/yaml.v2"
 )
@@ -15,6 +16,19 @@ var (
 	KeyHalvingInterval      = []byte("HalvingInterval")
 	KeySupportedChains      = []byte("SupportedChains")
 	KeyLayerZeroEndpoint    = []byte("LayerZeroEndpoint")
+	KeySlashFractionDoubleSign = []byte("SlashFractionDoubleSign")
+	KeyLivenessWindow          = []byte("LivenessWindow")
+	KeySuspendMissedBlocks     = []byte("SuspendMissedBlocks")
+	KeyJailMissedBlocks        = []byte("JailMissedBlocks")
+	KeyDowntimeGraceBlocks     = []byte("DowntimeGraceBlocks")
+	KeyJailDurationBlocks      = []byte("JailDurationBlocks")
+	KeyMaintenanceNoticeBlocks = []byte("MaintenanceNoticeBlocks")
+	KeyMaxMaintenanceBlocks    = []byte("MaxMaintenanceBlocks")
+	KeyPoolEpochBlocks         = []byte("PoolEpochBlocks")
+	KeyDisputeWindowEpochs     = []byte("DisputeWindowEpochs")
+	KeyArbitrator              = []byte("Arbitrator")
+	KeyWithholdingPeriodBlocks = []byte("WithholdingPeriodBlocks")
+	KeyTreasuryTax             = []byte("TreasuryTax")
 )
 
 // ParamKeyTable the param key table for launch module
@@ -29,13 +43,23 @@ func NewParams(
 	halvingInterval int64,
 	supportedChains []string,
 	layerZeroEndpoint string,
+	slashFractionDoubleSign string,
+	liveness LivenessParams,
+	disputes PoolDisputeParams,
+	withholdingPeriodBlocks int64,
+	treasuryTax string,
 ) Params {
 	return Params{
-		MinStakeAmount:    minStakeAmount,
-		BlockReward:       blockReward,
-		HalvingInterval:   halvingInterval,
-		SupportedChains:   supportedChains,
-		LayerZeroEndpoint: layerZeroEndpoint,
+		MinStakeAmount:          minStakeAmount,
+		BlockReward:             blockReward,
+		HalvingInterval:         halvingInterval,
+		SupportedChains:         supportedChains,
+		LayerZeroEndpoint:       layerZeroEndpoint,
+		SlashFractionDoubleSign: slashFractionDoubleSign,
+		LivenessParams:          liveness,
+		PoolDisputeParams:       disputes,
+		WithholdingPeriodBlocks: withholdingPeriodBlocks,
+		TreasuryTax:             treasuryTax,
 	}
 }
 
@@ -47,9 +71,38 @@ func DefaultParams() Params {
 		210000000,              // 210M blocks
 		[]string{"altcoinchain-2330", "polygon-137"},
 		"",
+		"0.050000000000000000", // 5% of escrowed NU
+		DefaultLivenessParams(),
+		DefaultPoolDisputeParams(),
+		172800, // Daily withholding reports
+		"0.050000000000000000", // 5% of block rewards fund the community treasury
 	)
 }
 
+// DefaultPoolDisputeParams holds pool fees for a week of daily epochs. With no
+// arbitrator set only governance can resolve disputes.
+func DefaultPoolDisputeParams() PoolDisputeParams {
+	return PoolDisputeParams{
+		PoolEpochBlocks:     172800, // 24 hours
+		DisputeWindowEpochs: 7,
+		Arbitrator:          "",
+	}
+}
+
+// DefaultLivenessParams returns downtime limits at 0.5 second blocks. Nodes get
+// ten minutes of grace per outage to ride out routing problems between regions.
+func DefaultLivenessParams() LivenessParams {
+	return LivenessParams{
+		LivenessWindow:          7200,   // 1 hour
+		SuspendMissedBlocks:     3600,   // Half the window
+		JailMissedBlocks:        6480,   // 90% of the window
+		DowntimeGraceBlocks:     1200,   // 10 minutes
+		JailDurationBlocks:      7200,   // 1 hour
+		MaintenanceNoticeBlocks: 600,    // 5 minutes
+		MaxMaintenanceBlocks:    172800, // 24 hours
+	}
+}
+
 // ParamSetPairs get the params.ParamSet
 func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
 	return paramtypes.ParamSetPairs{
@@ -58,6 +111,19 @@ func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
 		paramtypes.NewParamSetPair(KeyHalvingInterval, &p.HalvingInterval, validateHalvingInterval),
 		paramtypes.NewParamSetPair(KeySupportedChains, &p.SupportedChains, validateSupportedChains),
 		paramtypes.NewParamSetPair(KeyLayerZeroEndpoint, &p.LayerZeroEndpoint, validateLayerZeroEndpoint),
+		paramtypes.NewParamSetPair(KeySlashFractionDoubleSign, &p.SlashFractionDoubleSign, validateSlashFractionDoubleSign),
+		paramtypes.NewParamSetPair(KeyLivenessWindow, &p.LivenessWindow, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeySuspendMissedBlocks, &p.SuspendMissedBlocks, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyJailMissedBlocks, &p.JailMissedBlocks, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyDowntimeGraceBlocks, &p.DowntimeGraceBlocks, validateNonNegativeBlocks),
+		paramtypes.NewParamSetPair(KeyJailDurationBlocks, &p.JailDurationBlocks, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyMaintenanceNoticeBlocks, &p.MaintenanceNoticeBlocks, validateNonNegativeBlocks),
+		paramtypes.NewParamSetPair(KeyMaxMaintenanceBlocks, &p.MaxMaintenanceBlocks, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyPoolEpochBlocks, &p.PoolEpochBlocks, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyDisputeWindowEpochs, &p.DisputeWindowEpochs, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyArbitrator, &p.Arbitrator, validateArbitrator),
+		paramtypes.NewParamSetPair(KeyWithholdingPeriodBlocks, &p.WithholdingPeriodBlocks, validatePositiveBlocks),
+		paramtypes.NewParamSetPair(KeyTreasuryTax, &p.TreasuryTax, validateTreasuryTax),
 	}
 }
 
@@ -78,6 +144,21 @@ func (p Params) Validate() error {
 	if err := validateLayerZeroEndpoint(p.LayerZeroEndpoint); err != nil {
 		return err
 	}
+	if err := validateSlashFractionDoubleSign(p.SlashFractionDoubleSign); err != nil {
+		return err
+	}
+	if err := p.LivenessParams.Validate(); err != nil {
+		return err
+	}
+	if err := p.PoolDisputeParams.Validate(); err != nil {
+		return err
+	}
+	if err := validatePositiveBlocks(p.WithholdingPeriodBlocks); err != nil {
+		return err
+	}
+	if err := validateTreasuryTax(p.TreasuryTax); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -149,11 +230,116 @@ func validateLayerZeroEndpoint(i interface{}) error {
 	return nil
 }
 
-// Params defines the parameters for the mining module
-type Params struct {
-	MinStakeAmount    string   `json:"min_stake_amount" yaml:"min_stake_amount"`
-	BlockReward       string   `json:"block_reward" yaml:"block_reward"`
-	HalvingInterval   int64    `json:"halving_interval" yaml:"halving_interval"`
-	SupportedChains   []string `json:"supported_chains" yaml:"supported_chains"`
-	LayerZeroEndpoint string   `json:"layer_zero_endpoint" yaml:"layer_zero_endpoint"`
-}
\ No newline at end of file
+func validateSlashFractionDoubleSign(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	fraction, err := sdk.NewDecFromStr(v)
+	if err != nil {
+		return fmt.Errorf("invalid slash fraction: %w", err)
+	}
+	
+	if fraction.IsNegative() || fraction.GT(sdk.OneDec()) {
+		return fmt.Errorf("slash fraction must be between 0 and 1: %s", v)
+	}
+	
+	return nil
+}
+
+func validateTreasuryTax(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	tax, err := sdk.NewDecFromStr(v)
+	if err != nil {
+		return fmt.Errorf("invalid treasury tax: %w", err)
+	}
+	
+	if tax.IsNegative() || tax.GT(sdk.OneDec()) {
+		return fmt.Errorf("treasury tax must be between 0 and 1: %s", v)
+	}
+	
+	return nil
+}
+
+func validatePositiveBlocks(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v <= 0 {
+		return fmt.Errorf("block count must be positive: %d", v)
+	}
+	
+	return nil
+}
+
+func validateNonNegativeBlocks(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v < 0 {
+		return fmt.Errorf("block count cannot be negative: %d", v)
+	}
+	
+	return nil
+}
+
+// Validate checks each limit and that the thresholds fit inside the window
+func (p LivenessParams) Validate() error {
+	for _, v := range []int64{p.LivenessWindow, p.SuspendMissedBlocks, p.JailMissedBlocks, p.JailDurationBlocks, p.MaxMaintenanceBlocks} {
+		if err := validatePositiveBlocks(v); err != nil {
+			return err
+		}
+	}
+	for _, v := range []int64{p.DowntimeGraceBlocks, p.MaintenanceNoticeBlocks} {
+		if err := validateNonNegativeBlocks(v); err != nil {
+			return err
+		}
+	}
+	
+	if p.JailMissedBlocks > p.LivenessWindow {
+		return fmt.Errorf("jail threshold %d exceeds liveness window %d", p.JailMissedBlocks, p.LivenessWindow)
+	}
+	if p.SuspendMissedBlocks > p.JailMissedBlocks {
+		return fmt.Errorf("suspend threshold %d exceeds jail threshold %d", p.SuspendMissedBlocks, p.JailMissedBlocks)
+	}
+	
+	return nil
+}
+
+func validateArbitrator(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	// Without an arbitrator disputes are left to governance
+	if v == "" {
+		return nil
+	}
+	
+	if _, err := sdk.AccAddressFromBech32(v); err != nil {
+		return fmt.Errorf("invalid arbitrator address: %w", err)
+	}
+	
+	return nil
+}
+
+// Validate checks the epoch length, dispute window and arbitrator
+func (p PoolDisputeParams) Validate() error {
+	if err := validatePositiveBlocks(p.PoolEpochBlocks); err != nil {
+		return err
+	}
+	if p.DisputeWindowEpochs <= 0 {
+		return fmt.Errorf("dispute window must be positive: %d", p.DisputeWindowEpochs)
+	}
+	return validateArbitrator(p.Arbitrator)
+}
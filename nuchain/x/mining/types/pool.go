@@ -0,0 +1,40 @@
+package types
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// PoolConsentDomain separates pool fee consents from any other hashed data
+const PoolConsentDomain = "nuchain-pool-fee-v1"
+
+// MaxPoolFeeBps caps the share of member rewards a pool may keep (50%)
+const MaxPoolFeeBps = 5000
+
+// Validate checks the fee rate and recipient
+func (f FeeSchedule) Validate() error {
+	if f.FeeBps > MaxPoolFeeBps {
+		return fmt.Errorf("pool fee %d bps exceeds maximum of %d", f.FeeBps, MaxPoolFeeBps)
+	}
+	if _, err := sdk.AccAddressFromBech32(f.FeeRecipient); err != nil {
+		return fmt.Errorf("invalid fee recipient: %w", err)
+	}
+	return nil
+}
+
+// FeeScheduleHash is the digest miners consent to when joining a pool. It binds
+// the pool, its chain and the schedule version so a consent cannot be replayed
+// against another pool or a later schedule.
+func FeeScheduleHash(pool PoolOperator) []byte {
+	var schedule FeeSchedule
+	if pool.FeeSchedule != nil {
+		schedule = *pool.FeeSchedule
+	}
+
+	data := fmt.Sprintf("%s:%s:%s:%d:%s:%d", PoolConsentDomain, pool.Address, pool.ChainId,
+		schedule.FeeBps, schedule.FeeRecipient, schedule.Version)
+	hash := sha256.Sum256([]byte(data))
+	return hash[:]
+}
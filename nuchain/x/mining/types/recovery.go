@@ -0,0 +1,74 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// MinRecoveryDelayBlocks keeps the owner a window to cancel a hostile recovery
+	// (about 12 hours at 0.5 second blocks)
+	MinRecoveryDelayBlocks int64 = 86400
+
+	// MaxGuardians bounds the guardian set stored per account
+	MaxGuardians = 15
+)
+
+// Validate checks guardian addresses, threshold and delay
+func (c RecoveryConfig) Validate() error {
+	if len(c.Guardians) == 0 {
+		return fmt.Errorf("guardians cannot be empty")
+	}
+	if len(c.Guardians) > MaxGuardians {
+		return fmt.Errorf("too many guardians: %d > %d", len(c.Guardians), MaxGuardians)
+	}
+	if c.Threshold == 0 || int(c.Threshold) > len(c.Guardians) {
+		return fmt.Errorf("threshold must be between 1 and %d", len(c.Guardians))
+	}
+	if c.DelayBlocks < MinRecoveryDelayBlocks {
+		return fmt.Errorf("recovery delay must be at least %d blocks", MinRecoveryDelayBlocks)
+	}
+
+	seen := make(map[string]bool)
+	for _, guardian := range c.Guardians {
+		if _, err := sdk.AccAddressFromBech32(guardian); err != nil {
+			return fmt.Errorf("invalid guardian address %s: %w", guardian, err)
+		}
+		if guardian == c.Account {
+			return fmt.Errorf("account cannot be its own guardian")
+		}
+		if seen[guardian] {
+			return fmt.Errorf("duplicate guardian %s", guardian)
+		}
+		seen[guardian] = true
+	}
+
+	return nil
+}
+
+// IsGuardian reports whether addr is part of the guardian set
+func (c RecoveryConfig) IsGuardian(addr string) bool {
+	for _, guardian := range c.Guardians {
+		if guardian == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether the recovery failed to reach its threshold within
+// delayBlocks of its first approval, by height
+func (p PendingRecovery) Expired(height, delayBlocks int64) bool {
+	return p.ExecuteHeight == 0 && height >= p.StartHeight+delayBlocks
+}
+
+// HasApproved reports whether the guardian already approved this recovery
+func (p PendingRecovery) HasApproved(guardian string) bool {
+	for _, approval := range p.Approvals {
+		if approval == guardian {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package types
+
+import "testing"
+
+func TestPendingRecoveryExpiresOnlyUnscheduled(t *testing.T) {
+	const delay = MinRecoveryDelayBlocks
+	pending := PendingRecovery{StartHeight: 100}
+
+	if pending.Expired(100+delay-1, delay) {
+		t.Fatal("expired before the delay elapsed")
+	}
+	if !pending.Expired(100+delay, delay) {
+		t.Fatal("unscheduled recovery did not expire after the delay")
+	}
+
+	// A scheduled recovery executes or is cancelled, it never expires
+	pending.ExecuteHeight = 100 + delay
+	if pending.Expired(100+10*delay, delay) {
+		t.Fatal("scheduled recovery expired")
+	}
+}
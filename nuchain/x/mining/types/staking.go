@@ -0,0 +1,19 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NodeStake is the NU a staking node escrows: 21 NU at 18 decimals, more
+// than an int64 or a uint64 holds
+var NodeStake = sdk.NewIntWithDecimal(21, 18)
+
+// Stake returns the NU the node has escrowed, zero if it is not a valid
+// amount
+func (n StakingNode) Stake() sdk.Int {
+	stake, ok := sdk.NewIntFromString(n.StakedNu)
+	if !ok {
+		return sdk.ZeroInt()
+	}
+	return stake
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MaxWithholdingBps lets an account withhold up to all of its rewards
+const MaxWithholdingBps = 10000
+
+// Validate checks the share and that rewards are routed to another account
+func (w RewardWithholding) Validate() error {
+	if w.Bps == 0 || w.Bps > MaxWithholdingBps {
+		return fmt.Errorf("withholding must be between 1 and %d bps: %d", MaxWithholdingBps, w.Bps)
+	}
+	if _, err := sdk.AccAddressFromBech32(w.Recipient); err != nil {
+		return fmt.Errorf("invalid withholding recipient: %w", err)
+	}
+	if w.Recipient == w.Account {
+		return fmt.Errorf("withholding recipient must differ from the account")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package mining
+
+import (
+	"context"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"nuchain/x/mining/keeper"
+	"nuchain/x/mining/types"
+)
+
+// ConsensusVersion is the version of the mining module's state machine. It is
+// bumped whenever the store layout or state transitions change, with a
+// migration from the previous version.
+const ConsensusVersion = 2
+
+// UpgradeName is the software upgrade that moves the mining store to version 2
+const UpgradeName = "v2-hash-power-aggregates"
+
+// RegisterMigrations registers the store migrations of the module with the
+// configurator, for the module manager to run on upgrade
+func RegisterMigrations(cfg module.Configurator, k keeper.Keeper) error {
+	m := keeper.NewMigrator(k)
+	return cfg.RegisterMigration(types.ModuleName, 1, m.Migrate1to2)
+}
+
+// CreateUpgradeHandler returns the handler of UpgradeName. It runs the
+// registered migrations of every module whose version changed, so the hash
+// power aggregates are built in place at the upgrade height.
+func CreateUpgradeHandler(mm *module.Manager, configurator module.Configurator) upgradetypes.UpgradeHandler {
+	return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		return mm.RunMigrations(ctx, configurator, fromVM)
+	}
+}
@@ -2,18 +2,22 @@ package keeper
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"time"
 	
 	"cosmossdk.io/log"
 	"cosmossdk.io/store/prefix"
 	storetypes "cosmossdk.io/store/types"
 	
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	
 	"nuchain/x/pow/types"
+	"shared/pow"
 	
 	// External integrations
 	cysic "github.com/cysic-labs/zk-sdk-go"
@@ -82,11 +86,14 @@ func (k Keeper) VerifyZkProof(ctx sdk.Context, proof []byte, publicInputs []byte
 // MineBlock processes a mining attempt with zk-proof
 func (k Keeper) MineBlock(ctx sdk.Context, miner sdk.AccAddress, proof []byte) error {
 	difficulty := k.GetDifficulty(ctx)
-	blockHeader := ctx.BlockHeader()
 	
-	publicInputs := k.PreparePublicInputs(blockHeader, difficulty, miner)
+	publicInputs := k.PreparePublicInputs(ctx, difficulty, miner)
+	telemetry.ModuleSetGauge(types.ModuleName, float32(difficulty), "difficulty")
 	
-	if !k.VerifyZkProof(ctx, proof, publicInputs) {
+	start := time.Now()
+	valid := k.VerifyZkProof(ctx, proof, publicInputs)
+	measureProof(start, valid)
+	if !valid {
 		return fmt.Errorf("invalid zk-proof")
 	}
 	
@@ -95,51 +102,57 @@ func (k Keeper) MineBlock(ctx sdk.Context, miner sdk.AccAddress, proof []byte) e
 		return err
 	}
 	
-	return k.SubmitToL1(ctx, blockHeader, proof)
+	return k.SubmitToL1(ctx, proof)
 }
 
-// DistributeReward calculates and distributes NU tokens
+// DistributeReward mints the block reward of the halving schedule to the
+// miner in NU
 func (k Keeper) DistributeReward(ctx sdk.Context, miner sdk.AccAddress) error {
-	reward := k.CalculateReward(ctx.BlockHeight())
-	
-	// Mint NU tokens
-	coins := sdk.NewCoins(sdk.NewCoin("nu", reward))
-	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
+	minter := bankMinter{ctx: ctx, bankKeeper: k.bankKeeper, denom: "nu"}
+	reward, err := pow.DistributeReward(minter, pow.DefaultHalvingSchedule(), ctx.BlockHeight(), miner)
+	if err != nil {
 		return err
 	}
-	
-	return k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, miner, coins)
+	setRewardGauge(reward)
+	return nil
 }
 
-// CalculateReward implements halving mechanism for NU tokens
+// CalculateReward returns the block reward at height, see pow.HalvingSchedule
 func (k Keeper) CalculateReward(height int64) sdk.Int {
-	halvingInterval := int64(210000000) // 210M blocks
-	halvings := height / halvingInterval
-	
-	// Initial reward: 0.05 NU * 10^18 wei
-	initialReward := sdk.NewInt(50000000000000000)
-	
-	if halvings >= 64 {
-		return sdk.ZeroInt()
+	return sdk.NewIntFromBigInt(pow.DefaultHalvingSchedule().Reward(height))
+}
+
+// bankMinter mints block rewards through the bank keeper, to the module
+// account and on to the miner
+type bankMinter struct {
+	ctx        sdk.Context
+	bankKeeper types.BankKeeper
+	denom      string
+}
+
+func (m bankMinter) MintReward(miner []byte, amount *big.Int) error {
+	coins := sdk.NewCoins(sdk.NewCoin(m.denom, sdk.NewIntFromBigInt(amount)))
+	if err := m.bankKeeper.MintCoins(m.ctx, types.ModuleName, coins); err != nil {
+		return err
 	}
-	
-	divisor := sdk.NewInt(1 << uint(halvings))
-	return initialReward.Quo(divisor)
+	return m.bankKeeper.SendCoinsFromModuleToAccount(m.ctx, types.ModuleName, miner, coins)
 }
 
 // SubmitToL1 submits zk-rollup batch to Altcoinchain L1
-func (k Keeper) SubmitToL1(ctx sdk.Context, blockHeader *storetypes.Context, proof []byte) error {
+func (k Keeper) SubmitToL1(ctx sdk.Context, proof []byte) error {
 	// Create zk-rollup batch
 	batch := &altcoin.RollupBatch{
 		Height:      ctx.BlockHeight(),
-		BlockHash:   blockHeader.BlockHeader().Hash().String(),
+		BlockHash:   fmt.Sprintf("%X", ctx.HeaderHash()),
 		Timestamp:   ctx.BlockTime().Unix(),
 		ZkProof:     proof,
 		TxCount:     len(ctx.TxBytes()),
 	}
 	
 	// Submit to Altcoinchain L1
-	return k.altcoinClient.SubmitRollupBatch(batch)
+	err := k.altcoinClient.SubmitRollupBatch(batch)
+	countCrossChainMessage("out", "altcoinchain", err)
+	return err
 }
 
 // BridgeToZChain handles cross-chain messaging to Z Blockchain
@@ -153,11 +166,15 @@ func (k Keeper) BridgeToZChain(ctx sdk.Context, recipient string, amount sdk.Int
 	}
 	
 	// Send via LayerZero
-	return k.layerZeroClient.SendMessage("z-blockchain-1", payload)
+	err := k.layerZeroClient.SendMessage("z-blockchain-1", payload)
+	countCrossChainMessage("out", "zchain", err)
+	return err
 }
 
 // ProcessZChainMessage handles messages from zChain
-func (k Keeper) ProcessZChainMessage(ctx sdk.Context, messageType string, payload []byte) error {
+func (k Keeper) ProcessZChainMessage(ctx sdk.Context, messageType string, payload []byte) (err error) {
+	defer func() { countCrossChainMessage("in", "zchain", err) }()
+	
 	switch messageType {
 	case "zchain_mining_reward":
 		return k.processZChainMiningReward(ctx, payload)
@@ -225,16 +242,14 @@ func (k Keeper) updateCrossChainMiningStats(ctx sdk.Context, miner string, rewar
 }
 
 // PreparePublicInputs creates public inputs for zk-proof verification
-func (k Keeper) PreparePublicInputs(header *storetypes.Context, difficulty uint64, miner sdk.AccAddress) []byte {
+func (k Keeper) PreparePublicInputs(ctx sdk.Context, difficulty uint64, miner sdk.AccAddress) []byte {
 	data := make([]byte, 0, 64+8+20)
 	
 	// Block hash
-	blockHash := header.BlockHeader().Hash()
-	data = append(data, blockHash[:]...)
+	data = append(data, ctx.HeaderHash()...)
 	
 	// Previous block hash
-	prevHash := header.BlockHeader().LastBlockId.Hash
-	data = append(data, prevHash[:]...)
+	data = append(data, ctx.BlockHeader().LastBlockId.Hash...)
 	
 	// Difficulty
 	diffBytes := make([]byte, 8)
@@ -249,20 +264,14 @@ func (k Keeper) PreparePublicInputs(header *storetypes.Context, difficulty uint6
 
 // GetDifficulty retrieves current mining difficulty
 func (k Keeper) GetDifficulty(ctx sdk.Context) uint64 {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.DifficultyKey))
-	bz := store.Get(types.DifficultyKey)
-	if bz == nil {
-		return 1000000
-	}
-	return binary.BigEndian.Uint64(bz)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyKey)
+	return pow.GetDifficulty(store, types.DifficultyKey)
 }
 
 // SetDifficulty sets mining difficulty
 func (k Keeper) SetDifficulty(ctx sdk.Context, difficulty uint64) {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.DifficultyKey))
-	bz := make([]byte, 8)
-	binary.BigEndian.PutUint64(bz, difficulty)
-	store.Set(types.DifficultyKey, bz)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyKey)
+	pow.SetDifficulty(store, types.DifficultyKey, difficulty)
 }
 
 // Logger returns the keeper's logger
@@ -0,0 +1,40 @@
+package keeper
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+
+	"nuchain/x/pow/types"
+)
+
+// Metrics are exported through the SDK telemetry, which serves them to
+// Prometheus when telemetry is enabled in app.toml. They are observations of
+// the node alone and never feed back into state.
+
+// measureProof records the time verifying a mining proof took since start
+// and counts it as valid or not
+func measureProof(start time.Time, valid bool) {
+	telemetry.ModuleMeasureSince(types.ModuleName, start, "proof_verification", "mining")
+	telemetry.IncrCounter(1, types.ModuleName, "proofs", status(valid))
+}
+
+// countCrossChainMessage counts a message sent to or received from chain,
+// by whether it was handled
+func countCrossChainMessage(direction, chain string, err error) {
+	telemetry.IncrCounter(1, types.ModuleName, "cross_chain_messages", direction, chain, status(err == nil))
+}
+
+// setRewardGauge records the reward minted for the block
+func setRewardGauge(reward *big.Int) {
+	f, _ := new(big.Float).SetInt(reward).Float32()
+	telemetry.ModuleSetGauge(types.ModuleName, f, "block_reward_minted")
+}
+
+func status(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "failed"
+}
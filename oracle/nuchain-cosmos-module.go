@@ -11,6 +11,9 @@ import (
 	
 	// Cysic integration
 	cysic "github.com/cysic-labs/zk-sdk-go"
+
+	"shared/address"
+	"shared/pow"
 )
 
 // OracleKeeper handles cross-chain mining data and block rewards
@@ -171,16 +174,8 @@ func (k *OracleKeeper) verifyCysicProof(proof []byte, publicInputs []byte) bool
 
 // calculateMinerReward calculates NU token reward based on hash power contribution
 func (k *OracleKeeper) calculateMinerReward(ctx sdk.Context, miner *MinerState, blockHeight int64) sdk.Int {
-	// Base reward: 0.05 NU per block
-	baseReward := sdk.NewInt(50000000000000000) // 0.05 NU * 10^18
-	
-	// Apply halving mechanism
-	halvingInterval := int64(210000000)
-	halvings := blockHeight / halvingInterval
-	if halvings > 0 {
-		divisor := sdk.NewInt(1 << uint(halvings))
-		baseReward = baseReward.Quo(divisor)
-	}
+	// Base reward of the emission schedule both chains share
+	baseReward := sdk.NewIntFromBigInt(pow.DefaultEmission().Schedule.Reward(blockHeight))
 	
 	// Calculate miner's share based on hash power
 	if k.totalHashPower == 0 {
@@ -195,11 +190,13 @@ func (k *OracleKeeper) calculateMinerReward(ctx sdk.Context, miner *MinerState,
 
 // distributeNuTokens mints and distributes NU tokens to miner
 func (k *OracleKeeper) distributeNuTokens(ctx sdk.Context, nuChainAddress string, amount sdk.Int) error {
-	// Convert nuChain address to Cosmos address
-	recipient, err := sdk.AccAddressFromBech32(nuChainAddress)
+	// Accept nu1..., z1... or base58 forms, which all map to the same account
+	// bytes. An EVM 0x address is refused: it is not the hash accounts are.
+	addr, _, _, err := address.Parse(nuChainAddress)
 	if err != nil {
 		return fmt.Errorf("invalid nuChain address: %w", err)
 	}
+	recipient := sdk.AccAddress(addr.Bytes())
 	
 	// Mint NU tokens
 	coins := sdk.NewCoins(sdk.NewCoin("nu", amount))
@@ -6,7 +6,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"math/big"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -21,6 +20,11 @@ import (
 	
 	// LayerZero for cross-chain
 	layerzero "github.com/layerzerolabs/lz-sdk-go"
+
+	"shared/address"
+	"shared/pow"
+
+	utxotypes "z-blockchain/x/utxo/types"
 )
 
 // UTXOSidechainBridge manages the UTXO sidechain integration with nuChain
@@ -36,6 +40,7 @@ type UTXOSidechainBridge struct {
 	// Hardware mining
 	hardwareMiners  map[string]*HardwareMiner
 	miningPools     map[string]*MiningPool
+	hardwareBonuses utxotypes.HardwareBonusSchedule
 	
 	// Cross-chain coordination
 	nuChainBlocks   chan *NuChainBlock
@@ -138,6 +143,7 @@ func NewUTXOSidechainBridge(
 		pendingTxs:      make(map[string]*UTXOTransaction),
 		hardwareMiners:  make(map[string]*HardwareMiner),
 		miningPools:     make(map[string]*MiningPool),
+		hardwareBonuses: utxotypes.DefaultHardwareBonusSchedule(),
 		nuChainBlocks:   make(chan *NuChainBlock, 100),
 		zChainBlocks:    make(chan *ZChainBlock, 100),
 	}
@@ -207,46 +213,36 @@ func (b *UTXOSidechainBridge) prepareMiningInputs(ctx sdk.Context, miner *Hardwa
 	return hash[:]
 }
 
-// calculateBaseReward calculates base mining reward with halving
+// calculateBaseReward returns the base mining reward of the emission
+// schedule zChain pays, see pow.Emission
 func (b *UTXOSidechainBridge) calculateBaseReward(blockHeight int64) sdk.Int {
-	halvingInterval := int64(210000000) // 210M blocks
-	halvings := blockHeight / halvingInterval
-	
-	// Initial reward: 0.05 Z * 10^18 wei
-	initialReward := sdk.NewInt(50000000000000000)
-	
-	if halvings >= 64 {
-		return sdk.ZeroInt()
+	return sdk.NewIntFromBigInt(pow.DefaultEmission().Schedule.Reward(blockHeight))
+}
+
+// SetHardwareBonusSchedule replaces the hardware bonus schedule with the one
+// zChain governance set, read from the utxo module params
+func (b *UTXOSidechainBridge) SetHardwareBonusSchedule(schedule utxotypes.HardwareBonusSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid hardware bonus schedule: %w", err)
 	}
-	
-	divisor := sdk.NewInt(1 << uint(halvings))
-	return initialReward.Quo(divisor)
+	b.hardwareBonuses = schedule
+	return nil
 }
 
 // getHardwareBonus returns bonus for hardware acceleration
 func (b *UTXOSidechainBridge) getHardwareBonus(hardwareID string) sdk.Int {
-	bonuses := map[string]int64{
-		"nvidia-rtx-4090": 5000000000000000,  // 0.005 Z
-		"nvidia-a100":     5000000000000000,  // 0.005 Z
-		"nvidia-h100":     10000000000000000, // 0.01 Z
-		"xilinx-fpga":     15000000000000000, // 0.015 Z
-		"amd-rx-7900-xtx": 5500000000000000,  // 0.0055 Z
-	}
-	
-	if bonus, exists := bonuses[hardwareID]; exists {
-		return sdk.NewInt(bonus)
-	}
-	
-	return sdk.ZeroInt()
+	return b.hardwareBonuses.Bonus(hardwareID)
 }
 
 // distributeZTokens mints and distributes Z tokens on UTXO sidechain
 func (b *UTXOSidechainBridge) distributeZTokens(ctx sdk.Context, zChainAddress string, amount sdk.Int) error {
-	// Convert to Cosmos address
-	recipient, err := sdk.AccAddressFromBech32(zChainAddress)
+	// Accept z1..., nu1... or base58 forms, which all map to the same account
+	// bytes. An EVM 0x address is refused: it is not the hash accounts are.
+	addr, _, _, err := address.Parse(zChainAddress)
 	if err != nil {
 		return fmt.Errorf("invalid zChain address: %w", err)
 	}
+	recipient := sdk.AccAddress(addr.Bytes())
 	
 	// Mint Z tokens
 	coins := sdk.NewCoins(sdk.NewCoin("z", amount))
@@ -342,15 +338,25 @@ func (b *UTXOSidechainBridge) ProcessUTXOTransaction(ctx sdk.Context, tx *UTXOTr
 // RegisterHardwareMiner registers a new hardware miner
 func (b *UTXOSidechainBridge) RegisterHardwareMiner(
 	ctx sdk.Context,
-	address string,
+	minerAddress string,
 	hardwareID string,
 	hashPower uint64,
 	wattConsumption uint64,
 	nuChainAddress string,
 	zChainAddress string,
 ) error {
+	// Store canonical bech32 forms so lookups do not depend on the submitted encoding
+	nuChainAddress, err := address.ToNuChain(nuChainAddress)
+	if err != nil {
+		return fmt.Errorf("invalid nuChain address: %w", err)
+	}
+	zChainAddress, err = address.ToZChain(zChainAddress)
+	if err != nil {
+		return fmt.Errorf("invalid zChain address: %w", err)
+	}
+	
 	miner := &HardwareMiner{
-		Address:         address,
+		Address:         minerAddress,
 		HardwareID:      hardwareID,
 		HashPower:       hashPower,
 		WattConsumption: wattConsumption,
@@ -361,10 +367,10 @@ func (b *UTXOSidechainBridge) RegisterHardwareMiner(
 		TotalRewards:    sdk.ZeroInt(),
 	}
 	
-	b.hardwareMiners[address] = miner
+	b.hardwareMiners[minerAddress] = miner
 	
 	ctx.Logger().Info("Registered hardware miner",
-		"address", address,
+		"address", minerAddress,
 		"hardware_id", hardwareID,
 		"hash_power", hashPower,
 		"nuchain_address", nuChainAddress,
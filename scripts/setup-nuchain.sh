@@ -33,7 +33,14 @@ print_header() {
 }
 
 # Configuration
-CHAIN_ID="nuchain-1"
+PROFILE="${PROFILE:-mainnet}"
+# The chain ID follows the profile, as nuchaind init sets it
+case "$PROFILE" in
+    mainnet) CHAIN_ID="nuchain-1" ;;
+    testnet) CHAIN_ID="nuchain-testnet-1" ;;
+    devnet) CHAIN_ID="nuchain-devnet" ;;
+    *) echo "Unknown profile $PROFILE, expected mainnet, testnet or devnet" >&2; exit 1 ;;
+esac
 MONIKER="nuchain-validator"
 KEYRING_BACKEND="test"
 HOME_DIR="$HOME/.nuchain"
@@ -62,7 +69,7 @@ init_nuchain() {
     cd nuchain
     
     # Initialize the chain
-    nuchaind init $MONIKER --chain-id $CHAIN_ID --home $HOME_DIR
+    nuchaind init $MONIKER --profile $PROFILE --home $HOME_DIR
     
     # Add validator key
     nuchaind keys add validator --keyring-backend $KEYRING_BACKEND --home $HOME_DIR
@@ -87,15 +94,9 @@ configure_l2() {
     CONFIG_FILE="$HOME_DIR/config/config.toml"
     
     # Set consensus parameters for fast block times
-    sed -i 's/timeout_commit = "5s"/timeout_commit = "200ms"/' $CONFIG_FILE
     sed -i 's/timeout_propose = "3s"/timeout_propose = "100ms"/' $CONFIG_FILE
     sed -i 's/timeout_prevote = "1s"/timeout_prevote = "100ms"/' $CONFIG_FILE
     sed -i 's/timeout_precommit = "1s"/timeout_precommit = "100ms"/' $CONFIG_FILE
-    sed -i 's/create_empty_blocks_interval = "0s"/create_empty_blocks_interval = "200ms"/' $CONFIG_FILE
-    
-    # Update app.toml
-    APP_CONFIG_FILE="$HOME_DIR/config/app.toml"
-    sed -i 's/minimum-gas-prices = ""/minimum-gas-prices = "0.025nu"/' $APP_CONFIG_FILE
     
     print_status "L2 configuration completed"
 }
@@ -202,9 +203,11 @@ test_cross_chain() {
 create_staking_node() {
     print_header "Creating staking node"
     
-    # Create staking node with 21 NU minimum stake
+    # Create staking node with 21 NU minimum stake, bound to this node's
+    # consensus key so double signing slashes the escrow
     nuchaind tx mining create-staking-node \
         --moniker "nuchain-staker-1" \
+        --consensus-address "$(nuchaind tendermint show-address --home $HOME_DIR)" \
         --supported-chains "altcoinchain-2330,polygon-137" \
         --from validator \
         --keyring-backend $KEYRING_BACKEND \
@@ -33,7 +33,14 @@ print_header() {
 }
 
 # Configuration
-CHAIN_ID="z-blockchain-1"
+PROFILE="${PROFILE:-mainnet}"
+# The chain ID follows the profile, as z-blockchaind init sets it
+case "$PROFILE" in
+    mainnet) CHAIN_ID="z-blockchain-1" ;;
+    testnet) CHAIN_ID="z-blockchain-testnet-1" ;;
+    devnet) CHAIN_ID="z-blockchain-devnet" ;;
+    *) echo "Unknown profile $PROFILE, expected mainnet, testnet or devnet" >&2; exit 1 ;;
+esac
 MONIKER="zchain-miner"
 KEYRING_BACKEND="test"
 HOME_DIR="$HOME/.z-blockchain"
@@ -69,7 +76,7 @@ init_zchain() {
     cd z-blockchain
     
     # Initialize the chain
-    z-blockchaind init $MONIKER --chain-id $CHAIN_ID --home $HOME_DIR
+    z-blockchaind init $MONIKER --profile $PROFILE --home $HOME_DIR
     
     # Add miner key
     z-blockchaind keys add miner --keyring-backend $KEYRING_BACKEND --home $HOME_DIR
@@ -94,21 +101,15 @@ configure_utxo() {
     CONFIG_FILE="$HOME_DIR/config/config.toml"
     
     # Set consensus parameters for fast block times
-    sed -i 's/timeout_commit = "5s"/timeout_commit = "200ms"/' $CONFIG_FILE
     sed -i 's/timeout_propose = "3s"/timeout_propose = "100ms"/' $CONFIG_FILE
     sed -i 's/timeout_prevote = "1s"/timeout_prevote = "100ms"/' $CONFIG_FILE
     sed -i 's/timeout_precommit = "1s"/timeout_precommit = "100ms"/' $CONFIG_FILE
-    sed -i 's/create_empty_blocks_interval = "0s"/create_empty_blocks_interval = "200ms"/' $CONFIG_FILE
     
     # Optimize mempool for UTXO transactions
     sed -i 's/size = 5000/size = 10000/' $CONFIG_FILE
     sed -i 's/max_txs_bytes = 1073741824/max_txs_bytes = 2147483648/' $CONFIG_FILE
     sed -i 's/cache_size = 10000/cache_size = 20000/' $CONFIG_FILE
     
-    # Update app.toml
-    APP_CONFIG_FILE="$HOME_DIR/config/app.toml"
-    sed -i 's/minimum-gas-prices = ""/minimum-gas-prices = "0.001z"/' $APP_CONFIG_FILE
-    
     print_status "UTXO configuration completed"
 }
 
@@ -0,0 +1,281 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Human readable parts used by the two chains
+const (
+	// ZChainHRP is the bech32 prefix for Z Blockchain accounts
+	ZChainHRP = "z"
+
+	// ZChainValoperHRP is the bech32 prefix for Z Blockchain validator operators
+	ZChainValoperHRP = "zvaloper"
+
+	// NuChainHRP is the bech32 prefix for nuChain accounts
+	NuChainHRP = "nu"
+
+	// NuChainValoperHRP is the bech32 prefix for nuChain validator operators
+	NuChainValoperHRP = "nuvaloper"
+
+	// NuChainValconsHRP is the bech32 prefix for nuChain consensus addresses
+	NuChainValconsHRP = "nuvalcons"
+)
+
+const (
+	// Length is the byte length of every account address
+	Length = 20
+
+	// ZBase58Version is the version byte of base58check encoded Z wallet addresses
+	ZBase58Version byte = 0x50
+
+	// HexPrefix is the prefix of hex addresses: EVM addresses (Altcoinchain,
+	// Polygon) and the hex form of accounts
+	HexPrefix = "0x"
+)
+
+// Format identifies a textual address encoding
+type Format int
+
+// FormatHex is an EVM address. Parse recognizes it but refuses it: an EVM
+// address is not the account of its key, see EVMAddress.
+const (
+	FormatUnknown Format = iota
+	FormatBech32
+	FormatZBase58
+	FormatHex
+	FormatZcash
+)
+
+// String implements the Stringer interface.
+func (f Format) String() string {
+	switch f {
+	case FormatBech32:
+		return "bech32"
+	case FormatZBase58:
+		return "base58"
+	case FormatHex:
+		return "hex"
+	case FormatZcash:
+		return "zcash"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	ErrInvalidLength = errors.New("address must be 20 bytes")
+	ErrInvalidPubKey = errors.New("public key must be a 33 byte compressed secp256k1 key")
+	ErrUnknownFormat = errors.New("unrecognized address format")
+	ErrWrongHRP      = errors.New("unexpected bech32 prefix")
+)
+
+// Address is the 20 byte account identifier shared by all encodings
+type Address [Length]byte
+
+// FromPubKey derives the account address for a compressed secp256k1 public key.
+// The derivation is RIPEMD160(SHA256(pubkey)), identical to Cosmos SDK secp256k1
+// accounts, so a key produces the same account on zChain, nuChain and in the wallet.
+func FromPubKey(pubKey []byte) (Address, error) {
+	if len(pubKey) != 33 || (pubKey[0] != 0x02 && pubKey[0] != 0x03) {
+		return Address{}, ErrInvalidPubKey
+	}
+
+	sha := sha256.Sum256(pubKey)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+
+	var addr Address
+	copy(addr[:], hasher.Sum(nil))
+	return addr, nil
+}
+
+// FromBytes wraps a raw 20 byte slice
+func FromBytes(bz []byte) (Address, error) {
+	if len(bz) != Length {
+		return Address{}, ErrInvalidLength
+	}
+
+	var addr Address
+	copy(addr[:], bz)
+	return addr, nil
+}
+
+// Bytes returns a copy of the raw address bytes
+func (a Address) Bytes() []byte {
+	return append([]byte(nil), a[:]...)
+}
+
+// Equal reports whether both addresses identify the same account
+func (a Address) Equal(other Address) bool {
+	return bytes.Equal(a[:], other[:])
+}
+
+// Bech32 encodes the address with the given human readable part
+func (a Address) Bech32(hrp string) (string, error) {
+	converted, err := bech32.ConvertBits(a[:], 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(hrp, converted)
+}
+
+// ZChain returns the z1... bech32 form
+func (a Address) ZChain() string {
+	s, _ := a.Bech32(ZChainHRP)
+	return s
+}
+
+// NuChain returns the nu1... bech32 form
+func (a Address) NuChain() string {
+	s, _ := a.Bech32(NuChainHRP)
+	return s
+}
+
+// ZBase58 returns the base58check form displayed by the Z Core wallet
+func (a Address) ZBase58() string {
+	return base58.CheckEncode(a[:], ZBase58Version)
+}
+
+// Hex returns the account bytes in 0x-prefixed hex, the form the wallet keys
+// its records by. It is not the EVM address of the account's key, which
+// EVMFromPubKey derives.
+func (a Address) Hex() string {
+	return HexPrefix + hex.EncodeToString(a[:])
+}
+
+// String returns the canonical zChain bech32 form
+func (a Address) String() string {
+	return a.ZChain()
+}
+
+// Parse decodes an address in any supported format and reports which format it was in.
+// For bech32 input the human readable part is returned as well. An EVM address is
+// refused with ErrEVMAddress: it names no account.
+func Parse(s string) (Address, Format, string, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, HexPrefix) || strings.HasPrefix(s, "0X") {
+		return Address{}, FormatHex, "", fmt.Errorf("%w: %q", ErrEVMAddress, s)
+	}
+
+	if hrp, addr, err := parseBech32(s); err == nil {
+		return addr, FormatBech32, hrp, nil
+	}
+
+	if addr, err := ParseZBase58(s); err == nil {
+		return addr, FormatZBase58, "", nil
+	}
+
+	if addr, err := ParseZcash(s); err == nil {
+		return addr, FormatZcash, "", nil
+	}
+
+	return Address{}, FormatUnknown, "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// ParseBech32 decodes a bech32 address and checks its human readable part
+func ParseBech32(s string, expectedHRP string) (Address, error) {
+	hrp, addr, err := parseBech32(s)
+	if err != nil {
+		return Address{}, err
+	}
+	if hrp != expectedHRP {
+		return Address{}, fmt.Errorf("%w: got %s, want %s", ErrWrongHRP, hrp, expectedHRP)
+	}
+	return addr, nil
+}
+
+// ParseZChain decodes a z1... address
+func ParseZChain(s string) (Address, error) {
+	return ParseBech32(s, ZChainHRP)
+}
+
+// ParseNuChain decodes a nu1... address
+func ParseNuChain(s string) (Address, error) {
+	return ParseBech32(s, NuChainHRP)
+}
+
+// ParseZBase58 decodes a base58check Z wallet address
+func ParseZBase58(s string) (Address, error) {
+	payload, version, err := base58.CheckDecode(s)
+	if err != nil {
+		return Address{}, err
+	}
+	if version != ZBase58Version {
+		return Address{}, fmt.Errorf("unexpected base58 version byte 0x%02x", version)
+	}
+	return FromBytes(payload)
+}
+
+// ParseHex decodes the hex form of an account written by Hex. An EVM address
+// decodes too, to an unrelated account, so ParseHex is only for strings known
+// to come from Hex.
+func ParseHex(s string) (Address, error) {
+	if len(s) < 2 || !strings.EqualFold(s[:2], HexPrefix) {
+		return Address{}, fmt.Errorf("hex address must start with %s", HexPrefix)
+	}
+
+	bz, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return Address{}, err
+	}
+	return FromBytes(bz)
+}
+
+// Convert re-encodes an address given in any supported format into the target format.
+// hrp is only used when the target format is bech32. Accounts do not convert to EVM
+// addresses, nor back.
+func Convert(s string, target Format, hrp string) (string, error) {
+	addr, _, _, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	switch target {
+	case FormatBech32:
+		return addr.Bech32(hrp)
+	case FormatZBase58:
+		return addr.ZBase58(), nil
+	case FormatHex:
+		return "", ErrEVMAddress
+	case FormatZcash:
+		return addr.Zcash(), nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ToZChain converts an address in any format into its z1... form
+func ToZChain(s string) (string, error) {
+	return Convert(s, FormatBech32, ZChainHRP)
+}
+
+// ToNuChain converts an address in any format into its nu1... form
+func ToNuChain(s string) (string, error) {
+	return Convert(s, FormatBech32, NuChainHRP)
+}
+
+func parseBech32(s string) (string, Address, error) {
+	hrp, data, err := bech32.Decode(s)
+	if err != nil {
+		return "", Address{}, err
+	}
+
+	converted, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", Address{}, err
+	}
+
+	addr, err := FromBytes(converted)
+	return hrp, addr, err
+}
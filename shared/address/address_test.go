@@ -0,0 +1,166 @@
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// vector is a reference derivation shared with the frontend and contract tooling.
+// Every encoding of a vector must decode to the same 20 bytes.
+type vector struct {
+	PubKey  string
+	Hex     string
+	ZChain  string
+	NuChain string
+	ZBase58 string
+	Zcash   string
+	EVM     string
+}
+
+// vectors are derived from the secp256k1 keys 1, 2, 3 and an arbitrary odd-y key.
+// The first entry matches the BIP-173 P2WPKH witness program for the generator point;
+// the EVM addresses of keys 1, 2 and 3 are the well known Ethereum ones.
+var vectors = []vector{
+	{
+		PubKey:  "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		Hex:     "0x751e76e8199196d454941c45d1b3a323f1433bd6",
+		ZChain:  "z1w508d6qejxtdg4y5r3zarvary0c5xw7keklfzv",
+		NuChain: "nu1w508d6qejxtdg4y5r3zarvary0c5xw7k0mvhxa",
+		ZBase58: "ZNiXKqjfAVvrZzLvBLkpctBvUU41vLSxBk",
+		Zcash:   "t1UYsZVJkLPeMjxEtACvSxfWuNmddpWfxzs",
+		EVM:     "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+	},
+	{
+		PubKey:  "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+		Hex:     "0x06afd46bcdfd22ef94ac122aa11f241244a37ecc",
+		ZChain:  "z1q6hag67dl53wl99vzg42z8eyzfz2xlkvngw0mc",
+		NuChain: "nu1q6hag67dl53wl99vzg42z8eyzfz2xlkv99a3lf",
+		ZBase58: "ZCecThsB5tGpN7HVq9EF59w5JMFUCri9w5",
+		Zcash:   "t1JUxhMSGFmzKY5BTp1PsQwG4Ceq642SmnB",
+		EVM:     "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+	},
+	{
+		PubKey:  "02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		Hex:     "0x7dd65592d0ab2fe0d0257d571abf032cd9db93dc",
+		ZChain:  "z10ht9tyks4vh7p5p904t340cr9nvahy7uyj0xt3",
+		NuChain: "nu10ht9tyks4vh7p5p904t340cr9nvahy7ujluc0q",
+		ZBase58: "ZPWczsabedr8PgbZg31JVc6R4RZHFjH33Q",
+		Zcash:   "t1VLyEX9gpXZdZeVXeuAvqPRPxj8u8qiVHL",
+		EVM:     "0x6813Eb9362372EEF6200f3b1dbC3f819671cBA69",
+	},
+	{
+		PubKey:  "03e60fce93b59e9ec53011aabc21c23e97b2a31369b87a5ae9c44ee89e2a6dec0a",
+		Hex:     "0x60aa32549d990a09863b8fd4ce611ebd70bb310b",
+		ZChain:  "z1vz4ry4yany9qnp3m3l2vucg7h4ctkvgth5hsz8",
+		NuChain: "nu1vz4ry4yany9qnp3m3l2vucg7h4ctkvgtpeywxk",
+		ZBase58: "ZLrNXa4eRjrrujhG9gdtq9CotybpNkZh2L",
+		Zcash:   "t1SgimDdjbdaN5hbE8YoXAvXnoHBSLjCPqy",
+		EVM:     "0xfaE394561e33e242c551d15D4625309EA4c0B97f",
+	},
+}
+
+// invalidVectors must be rejected by Parse
+var invalidVectors = []string{
+	"",
+	"z1w508d6qejxtdg4y5r3zarvary0c5xw7keklfzw",   // bad bech32 checksum
+	"z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsdg628", // 21 byte payload
+	"ZNiXKqjfAVvrZzLvBLkpctBvUU41vLSxBj",         // bad base58 checksum
+	"0x751e76e8199196d454941c45d1b3a323f1433b",   // 19 bytes
+	"0x751e76e8199196d454941c45d1b3a323f1433bzz", // not hex
+	"t3VEtV2oBtHxjq7wKHJb3PHsqXHvMRgUmVw",        // zcash P2SH
+}
+
+// TestVectors checks derivation, encoding and parsing against the reference
+// vectors, so a mismatched dependency cannot silently change addresses
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		pubKey, err := hex.DecodeString(v.PubKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		addr, err := FromPubKey(pubKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := addr.Hex(); got != v.Hex {
+			t.Errorf("%s: got %s, want %s", v.PubKey, got, v.Hex)
+		}
+
+		encoded := map[string]string{
+			v.ZChain:  addr.ZChain(),
+			v.NuChain: addr.NuChain(),
+			v.ZBase58: addr.ZBase58(),
+			v.Zcash:   addr.Zcash(),
+		}
+		for want, got := range encoded {
+			if want != got {
+				t.Errorf("%s: got %s, want %s", v.PubKey, got, want)
+			}
+
+			parsed, _, _, err := Parse(want)
+			if err != nil {
+				t.Errorf("%s: %v", want, err)
+				continue
+			}
+			if !parsed.Equal(addr) {
+				t.Errorf("%s decoded to a different account", want)
+			}
+		}
+	}
+}
+
+// TestEVMVectors checks EVM addresses are derived from the uncompressed key,
+// not converted from the account
+func TestEVMVectors(t *testing.T) {
+	for _, v := range vectors {
+		pubKey, err := hex.DecodeString(v.PubKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		addr, err := EVMFromPubKey(pubKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := addr.Hex(); got != v.EVM {
+			t.Errorf("%s: got %s, want %s", v.PubKey, got, v.EVM)
+		}
+
+		for _, s := range []string{v.EVM, strings.ToLower(v.EVM)} {
+			parsed, err := ParseEVM(s)
+			if err != nil {
+				t.Errorf("%s: %v", s, err)
+			} else if parsed != addr {
+				t.Errorf("%s decoded to a different address", s)
+			}
+
+			// An EVM address is no account, in any chain's encoding
+			if _, format, _, err := Parse(s); !errors.Is(err, ErrEVMAddress) || format != FormatHex {
+				t.Errorf("Parse(%s): got %v, want ErrEVMAddress", s, err)
+			}
+		}
+		if _, err := ToZChain(v.EVM); !errors.Is(err, ErrEVMAddress) {
+			t.Errorf("ToZChain(%s): got %v, want ErrEVMAddress", v.EVM, err)
+		}
+		if _, err := Convert(v.ZChain, FormatHex, ""); !errors.Is(err, ErrEVMAddress) {
+			t.Errorf("Convert(%s, FormatHex): got %v, want ErrEVMAddress", v.ZChain, err)
+		}
+	}
+
+	// A wrong case letter breaks the EIP-55 checksum
+	if _, err := ParseEVM("0x7e5F4552091A69125d5DfCb7b8C2659029395Bdf"); err == nil {
+		t.Error("an address with a bad checksum was accepted")
+	}
+}
+
+func TestInvalidVectors(t *testing.T) {
+	for _, s := range invalidVectors {
+		if _, _, _, err := Parse(s); err == nil {
+			t.Errorf("%q was accepted", s)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrEVMAddress is returned when an EVM address is given where an account is
+// expected. It is a hash of the uncompressed key, not of the compressed one
+// accounts hash, so no account can be recovered from it.
+var ErrEVMAddress = errors.New("EVM address does not identify an account")
+
+// EVMAddress is the 20 byte address a secp256k1 key controls on Altcoinchain
+// and Polygon. It is derived differently from the Address of the same key and
+// never converts to or from one; only the key links the two.
+type EVMAddress [Length]byte
+
+// EVMFromPubKey derives the EVM address of a compressed or uncompressed
+// secp256k1 public key: the last 20 bytes of the Keccak-256 hash of the
+// uncompressed key without its 0x04 prefix
+func EVMFromPubKey(pubKey []byte) (EVMAddress, error) {
+	key, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return EVMAddress{}, fmt.Errorf("invalid secp256k1 public key: %w", err)
+	}
+
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(key.SerializeUncompressed()[1:])
+
+	var addr EVMAddress
+	copy(addr[:], hasher.Sum(nil)[32-Length:])
+	return addr, nil
+}
+
+// ParseEVM decodes a 0x-prefixed EVM address. A mixed case address must carry
+// a valid EIP-55 checksum; all lower or upper case ones carry none.
+func ParseEVM(s string) (EVMAddress, error) {
+	if len(s) < 2 || !strings.EqualFold(s[:2], HexPrefix) {
+		return EVMAddress{}, fmt.Errorf("EVM address must start with %s", HexPrefix)
+	}
+
+	bz, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return EVMAddress{}, err
+	}
+	if len(bz) != Length {
+		return EVMAddress{}, ErrInvalidLength
+	}
+
+	var addr EVMAddress
+	copy(addr[:], bz)
+
+	digits := s[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && addr.Hex() != HexPrefix+digits {
+		return EVMAddress{}, fmt.Errorf("EVM address %s has an invalid EIP-55 checksum", s)
+	}
+	return addr, nil
+}
+
+// Bytes returns a copy of the raw address bytes
+func (a EVMAddress) Bytes() []byte {
+	return append([]byte(nil), a[:]...)
+}
+
+// Hex returns the 0x-prefixed EIP-55 checksummed form
+func (a EVMAddress) Hex() string {
+	digits := []byte(hex.EncodeToString(a[:]))
+
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(digits)
+	hash := hasher.Sum(nil)
+
+	// A letter is upper case where the matching nibble of the hash of the
+	// lower case address is 8 or more
+	for i, c := range digits {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			digits[i] = c - 'a' + 'A'
+		}
+	}
+	return HexPrefix + string(digits)
+}
+
+// String returns the checksummed hex form
+func (a EVMAddress) String() string {
+	return a.Hex()
+}
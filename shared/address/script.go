@@ -0,0 +1,35 @@
+package address
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+// ScriptHashHRP is the bech32 prefix for pay to script hash addresses. Any
+// script, a multisig, a hash time lock or an escrow, is paid to through the
+// same zsh1... form of its hash; the script is only revealed when spent.
+const ScriptHashHRP = "zsh"
+
+// FromScript derives the script hash address of a redeem script,
+// RIPEMD160(SHA256(script)) as for a public key
+func FromScript(script []byte) Address {
+	sha := sha256.Sum256(script)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+
+	var addr Address
+	copy(addr[:], hasher.Sum(nil))
+	return addr
+}
+
+// ScriptHash returns the zsh1... form of a script hash
+func (a Address) ScriptHash() string {
+	s, _ := a.Bech32(ScriptHashHRP)
+	return s
+}
+
+// ParseScriptHash decodes a zsh1... address
+func ParseScriptHash(s string) (Address, error) {
+	return ParseBech32(s, ScriptHashHRP)
+}
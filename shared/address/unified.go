@@ -0,0 +1,266 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+const (
+	// ShieldedHRP is the bech32 prefix for shielded payment addresses
+	ShieldedHRP = "zs"
+
+	// UnifiedHRP is the bech32m prefix for unified addresses
+	UnifiedHRP = "zu"
+
+	// DiversifierLength is the byte length of a shielded address diversifier
+	DiversifierLength = 11
+
+	// ShieldedReceiverLength is a diversifier followed by a 32 byte transmission key
+	ShieldedReceiverLength = DiversifierLength + 32
+)
+
+// Receiver type codes inside a unified address
+const (
+	typecodeTransparent byte = 0x00
+	typecodeShielded    byte = 0x02
+)
+
+var (
+	ErrNoReceivers     = errors.New("unified address must contain at least one receiver")
+	ErrUnknownReceiver = errors.New("unknown unified address receiver")
+)
+
+// ReceiverKind identifies which pool a receiver pays into
+type ReceiverKind int
+
+const (
+	ReceiverTransparent ReceiverKind = iota
+	ReceiverShielded
+	ReceiverScriptHash
+)
+
+// String implements the Stringer interface.
+func (k ReceiverKind) String() string {
+	switch k {
+	case ReceiverTransparent:
+		return "transparent"
+	case ReceiverShielded:
+		return "shielded"
+	case ReceiverScriptHash:
+		return "scripthash"
+	default:
+		return "unknown"
+	}
+}
+
+// ShieldedReceiver is a shielded payment address. Many receivers with different
+// diversifiers can belong to the same spend key without being linkable.
+type ShieldedReceiver [ShieldedReceiverLength]byte
+
+// ShieldedReceiverFromBytes wraps a raw 43 byte receiver
+func ShieldedReceiverFromBytes(bz []byte) (ShieldedReceiver, error) {
+	if len(bz) != ShieldedReceiverLength {
+		return ShieldedReceiver{}, fmt.Errorf("shielded receiver must be %d bytes", ShieldedReceiverLength)
+	}
+
+	var r ShieldedReceiver
+	copy(r[:], bz)
+	return r, nil
+}
+
+// Diversifier returns the diversifier part of the receiver
+func (r ShieldedReceiver) Diversifier() []byte {
+	return append([]byte(nil), r[:DiversifierLength]...)
+}
+
+// TransmissionKey returns the diversified transmission key part of the receiver
+func (r ShieldedReceiver) TransmissionKey() []byte {
+	return append([]byte(nil), r[DiversifierLength:]...)
+}
+
+// String returns the zs1... bech32 form
+func (r ShieldedReceiver) String() string {
+	converted, _ := bech32.ConvertBits(r[:], 8, 5, true)
+	s, _ := bech32.Encode(ShieldedHRP, converted)
+	return s
+}
+
+// ParseShielded decodes a zs1... shielded address
+func ParseShielded(s string) (ShieldedReceiver, error) {
+	hrp, data, err := bech32.Decode(strings.TrimSpace(s))
+	if err != nil {
+		return ShieldedReceiver{}, err
+	}
+	if hrp != ShieldedHRP {
+		return ShieldedReceiver{}, fmt.Errorf("%w: got %s, want %s", ErrWrongHRP, hrp, ShieldedHRP)
+	}
+
+	converted, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return ShieldedReceiver{}, err
+	}
+	return ShieldedReceiverFromBytes(converted)
+}
+
+// Unified bundles a transparent and a shielded receiver in one address. A
+// sender picks the receiver it supports, preferring the shielded one.
+type Unified struct {
+	Transparent *Address
+	Shielded    *ShieldedReceiver
+}
+
+// Preferred returns the receiver kind a sender should pay to
+func (u Unified) Preferred() ReceiverKind {
+	if u.Shielded != nil {
+		return ReceiverShielded
+	}
+	return ReceiverTransparent
+}
+
+// Encode returns the zu1... bech32m form. Receivers are written as
+// typecode, length, value in ascending typecode order.
+func (u Unified) Encode() (string, error) {
+	var payload []byte
+	if u.Transparent != nil {
+		payload = append(payload, typecodeTransparent, Length)
+		payload = append(payload, u.Transparent[:]...)
+	}
+	if u.Shielded != nil {
+		payload = append(payload, typecodeShielded, ShieldedReceiverLength)
+		payload = append(payload, u.Shielded[:]...)
+	}
+	if len(payload) == 0 {
+		return "", ErrNoReceivers
+	}
+
+	converted, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeM(UnifiedHRP, converted)
+}
+
+// String returns the zu1... form, or an empty string for an empty address
+func (u Unified) String() string {
+	s, _ := u.Encode()
+	return s
+}
+
+// ParseUnified decodes a zu1... unified address. Unified addresses exceed the
+// 90 character bech32 limit, so the length is not checked.
+func ParseUnified(s string) (Unified, error) {
+	hrp, data, err := bech32.DecodeNoLimit(strings.TrimSpace(s))
+	if err != nil {
+		return Unified{}, err
+	}
+	if hrp != UnifiedHRP {
+		return Unified{}, fmt.Errorf("%w: got %s, want %s", ErrWrongHRP, hrp, UnifiedHRP)
+	}
+
+	payload, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return Unified{}, err
+	}
+
+	var u Unified
+	last := -1
+	for len(payload) > 0 {
+		if len(payload) < 2 || len(payload) < 2+int(payload[1]) {
+			return Unified{}, fmt.Errorf("truncated unified address receiver")
+		}
+		typecode, value := payload[0], payload[2:2+int(payload[1])]
+		payload = payload[2+int(payload[1]):]
+
+		if int(typecode) <= last {
+			return Unified{}, fmt.Errorf("unified address receivers out of order or duplicated")
+		}
+		last = int(typecode)
+
+		switch typecode {
+		case typecodeTransparent:
+			addr, err := FromBytes(value)
+			if err != nil {
+				return Unified{}, err
+			}
+			u.Transparent = &addr
+		case typecodeShielded:
+			r, err := ShieldedReceiverFromBytes(value)
+			if err != nil {
+				return Unified{}, err
+			}
+			u.Shielded = &r
+		default:
+			return Unified{}, fmt.Errorf("%w: typecode 0x%02x", ErrUnknownReceiver, typecode)
+		}
+	}
+
+	if u.Transparent == nil && u.Shielded == nil {
+		return Unified{}, ErrNoReceivers
+	}
+	return u, nil
+}
+
+// Recipient is a payment destination resolved to the receiver a sender should use
+type Recipient struct {
+	Kind        ReceiverKind
+	Transparent Address // The script hash for ReceiverScriptHash
+	Shielded    ShieldedReceiver
+	Unified     bool // The destination was given as a unified address
+}
+
+// String returns the encoding of the chosen receiver
+func (r Recipient) String() string {
+	switch r.Kind {
+	case ReceiverShielded:
+		return r.Shielded.String()
+	case ReceiverScriptHash:
+		return r.Transparent.ScriptHash()
+	}
+	return r.Transparent.ZChain()
+}
+
+// ParseRecipient resolves a transparent, script hash, shielded or unified
+// address. For a unified address the shielded receiver is chosen when present.
+func ParseRecipient(s string) (Recipient, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case strings.HasPrefix(strings.ToLower(s), UnifiedHRP+"1"):
+		u, err := ParseUnified(s)
+		if err != nil {
+			return Recipient{}, err
+		}
+		r := Recipient{Kind: u.Preferred(), Unified: true}
+		if u.Shielded != nil {
+			r.Shielded = *u.Shielded
+		}
+		if u.Transparent != nil {
+			r.Transparent = *u.Transparent
+		}
+		return r, nil
+
+	case strings.HasPrefix(strings.ToLower(s), ScriptHashHRP+"1"):
+		hash, err := ParseScriptHash(s)
+		if err != nil {
+			return Recipient{}, err
+		}
+		return Recipient{Kind: ReceiverScriptHash, Transparent: hash}, nil
+
+	case strings.HasPrefix(strings.ToLower(s), ShieldedHRP+"1"):
+		shielded, err := ParseShielded(s)
+		if err != nil {
+			return Recipient{}, err
+		}
+		return Recipient{Kind: ReceiverShielded, Shielded: shielded}, nil
+
+	default:
+		addr, _, _, err := Parse(s)
+		if err != nil {
+			return Recipient{}, err
+		}
+		return Recipient{Kind: ReceiverTransparent, Transparent: addr}, nil
+	}
+}
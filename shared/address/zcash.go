@@ -0,0 +1,58 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+)
+
+// Zcash transparent addresses are base58check with a two byte version. Zcash
+// hashes keys as RIPEMD160(SHA256(compressed pubkey)) too, so the t-addr of a
+// key holds the same 20 bytes as its Z account.
+var (
+	ZcashP2PKHVersion        = [2]byte{0x1c, 0xb8} // t1 on mainnet
+	ZcashP2PKHTestnetVersion = [2]byte{0x1d, 0x25} // tm on testnet
+
+	zcashP2SHVersion        = [2]byte{0x1c, 0xbd} // t3
+	zcashP2SHTestnetVersion = [2]byte{0x1c, 0xba} // t2
+)
+
+// ErrZcashScript is returned for P2SH t-addrs, which name a script rather
+// than a key and have no Z account
+var ErrZcashScript = errors.New("zcash P2SH addresses have no Z account")
+
+// Zcash returns the Zcash mainnet t1... form
+func (a Address) Zcash() string {
+	return encodeZcash(ZcashP2PKHVersion, a)
+}
+
+// ZcashTestnet returns the Zcash testnet tm... form
+func (a Address) ZcashTestnet() string {
+	return encodeZcash(ZcashP2PKHTestnetVersion, a)
+}
+
+func encodeZcash(version [2]byte, a Address) string {
+	payload := append([]byte{version[1]}, a[:]...)
+	return base58.CheckEncode(payload, version[0])
+}
+
+// ParseZcash decodes a Zcash P2PKH t-addr from mainnet or testnet
+func ParseZcash(s string) (Address, error) {
+	payload, first, err := base58.CheckDecode(s)
+	if err != nil {
+		return Address{}, err
+	}
+	if len(payload) < 1 {
+		return Address{}, ErrInvalidLength
+	}
+
+	switch version := [2]byte{first, payload[0]}; version {
+	case ZcashP2PKHVersion, ZcashP2PKHTestnetVersion:
+		return FromBytes(payload[1:])
+	case zcashP2SHVersion, zcashP2SHTestnetVersion:
+		return Address{}, ErrZcashScript
+	default:
+		return Address{}, fmt.Errorf("unexpected zcash version 0x%02x%02x", version[0], version[1])
+	}
+}
@@ -0,0 +1,227 @@
+// Package dashboard renders a live terminal view of a local zChain or nuChain
+// node from its CometBFT RPC and Prometheus metrics.
+package dashboard
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Config describes the node to watch and what counts as a mining proof and a
+// reward on its chain
+type Config struct {
+	Title           string
+	Node            string // CometBFT RPC address, tcp:// or http://
+	MetricsURL      string // Prometheus endpoint; empty to skip metrics
+	TargetBlockTime time.Duration
+	Window          int // Blocks sampled for block times, proofs and rewards
+	Refresh         time.Duration
+
+	RewardDenom    string
+	RewardDecimals int
+
+	// Raw store location of the big endian difficulty; empty store to skip
+	DifficultyStore string
+	DifficultyKey   []byte
+
+	// IsProofTx reports whether a raw transaction submits a mining proof
+	IsProofTx func(tx []byte) bool
+}
+
+// blockStats are what the dashboard keeps of a block; blocks never change, so
+// they are fetched once
+type blockStats struct {
+	time     time.Time
+	proofs   int
+	accepted int
+	reward   *big.Int
+}
+
+// Dashboard polls a node and redraws its state
+type Dashboard struct {
+	cfg    Config
+	rpc    *rpcClient
+	blocks map[int64]blockStats
+}
+
+// New creates a dashboard for cfg
+func New(cfg Config) *Dashboard {
+	if cfg.Window < 2 {
+		cfg.Window = 2
+	}
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = 2 * time.Second
+	}
+
+	return &Dashboard{
+		cfg:    cfg,
+		rpc:    newRPCClient(cfg.Node),
+		blocks: make(map[int64]blockStats),
+	}
+}
+
+// snapshot is one poll of the node
+type snapshot struct {
+	status     nodeStatus
+	sampled    int
+	intervals  []time.Duration
+	proofs     int
+	accepted   int
+	rewards    *big.Int
+	difficulty *uint64
+	metrics    metrics
+	metricsErr error
+	takenAt    time.Time
+}
+
+// Run redraws the dashboard every refresh interval until ctx is cancelled
+func (d *Dashboard) Run(ctx context.Context, out io.Writer) error {
+	ticker := time.NewTicker(d.cfg.Refresh)
+	defer ticker.Stop()
+
+	for {
+		snap, err := d.collect()
+
+		// Clear the screen and home the cursor before each frame
+		fmt.Fprint(out, "\033[H\033[2J")
+		if err != nil {
+			fmt.Fprintf(out, "%s status: node unreachable at %s: %v\n", d.cfg.Title, d.cfg.Node, err)
+		} else {
+			d.render(out, snap)
+		}
+		fmt.Fprintf(out, "\nRefreshing every %s, Ctrl-C to quit\n", d.cfg.Refresh)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Print renders a single frame, for scripts and non-interactive terminals
+func (d *Dashboard) Print(out io.Writer) error {
+	snap, err := d.collect()
+	if err != nil {
+		return err
+	}
+	d.render(out, snap)
+	return nil
+}
+
+func (d *Dashboard) collect() (snapshot, error) {
+	status, err := d.rpc.status()
+	if err != nil {
+		return snapshot{}, err
+	}
+
+	snap := snapshot{status: status, rewards: new(big.Int), takenAt: time.Now()}
+
+	from := status.Height - int64(d.cfg.Window) + 1
+	if from < 1 {
+		from = 1
+	}
+
+	var prev *blockStats
+	for height := from; height <= status.Height; height++ {
+		stats, err := d.blockStats(height)
+		if err != nil {
+			// Pruned or not yet indexed; sample what is available
+			prev = nil
+			continue
+		}
+
+		snap.sampled++
+		snap.proofs += stats.proofs
+		snap.accepted += stats.accepted
+		snap.rewards.Add(snap.rewards, stats.reward)
+		if prev != nil {
+			snap.intervals = append(snap.intervals, stats.time.Sub(prev.time))
+		}
+		prev = &stats
+	}
+
+	// Forget blocks that slid out of the window
+	for height := range d.blocks {
+		if height < from {
+			delete(d.blocks, height)
+		}
+	}
+
+	if d.cfg.DifficultyStore != "" {
+		if bz, err := d.rpc.storeValue(d.cfg.DifficultyStore, d.cfg.DifficultyKey); err == nil && len(bz) == 8 {
+			difficulty := binary.BigEndian.Uint64(bz)
+			snap.difficulty = &difficulty
+		}
+	}
+
+	if d.cfg.MetricsURL != "" {
+		snap.metrics, snap.metricsErr = scrapeMetrics(d.cfg.MetricsURL)
+	}
+
+	return snap, nil
+}
+
+func (d *Dashboard) blockStats(height int64) (blockStats, error) {
+	if stats, ok := d.blocks[height]; ok {
+		return stats, nil
+	}
+
+	b, err := d.rpc.block(height)
+	if err != nil {
+		return blockStats{}, err
+	}
+	results, err := d.rpc.blockResults(height)
+	if err != nil {
+		return blockStats{}, err
+	}
+
+	stats := blockStats{time: b.Time, reward: new(big.Int)}
+	for i, tx := range b.Txs {
+		if d.cfg.IsProofTx == nil || !d.cfg.IsProofTx(tx) {
+			continue
+		}
+		stats.proofs++
+		if i < len(results.TxCodes) && results.TxCodes[i] == 0 {
+			stats.accepted++
+		}
+	}
+
+	// Minted coins carry the block rewards on both chains
+	for _, event := range results.Events {
+		if event.Type != "coinbase" {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == "amount" {
+				stats.reward.Add(stats.reward, coinAmount(attr.Value, d.cfg.RewardDenom))
+			}
+		}
+	}
+
+	d.blocks[height] = stats
+	return stats, nil
+}
+
+var coinPattern = regexp.MustCompile(`^([0-9]+)([a-zA-Z][a-zA-Z0-9/:._-]*)$`)
+
+// coinAmount sums the amount of denom in an SDK coins string such as "5z,3nu"
+func coinAmount(coins, denom string) *big.Int {
+	total := new(big.Int)
+	for _, coin := range strings.Split(coins, ",") {
+		m := coinPattern.FindStringSubmatch(strings.TrimSpace(coin))
+		if m == nil || m[2] != denom {
+			continue
+		}
+		if amount, ok := new(big.Int).SetString(m[1], 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	return total
+}
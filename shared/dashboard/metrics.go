@@ -0,0 +1,63 @@
+package dashboard
+
+import (
+	"bufio"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metrics are Prometheus samples summed over their labels
+type metrics map[string]float64
+
+// scrapeMetrics reads the node's Prometheus endpoint. Only the text exposition
+// format is understood; comments and timestamps are skipped.
+func scrapeMetrics(endpoint string) (metrics, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	samples := metrics{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		name := fields[0]
+		if i := strings.IndexByte(name, '{'); i >= 0 {
+			name = name[:i]
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		samples[name] += value
+	}
+
+	return samples, scanner.Err()
+}
+
+// lookup returns the first metric present, tolerating the tendermint_ and
+// cometbft_ namespaces used by different node versions
+func (m metrics) lookup(names ...string) (float64, bool) {
+	for _, name := range names {
+		for _, namespace := range []string{"cometbft_", "tendermint_"} {
+			if v, ok := m[namespace+name]; ok {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
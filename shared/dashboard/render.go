@@ -0,0 +1,167 @@
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// onTargetTolerance is how much slower than the target a block may be and
+// still count as on target
+const onTargetTolerance = 1.25
+
+// sparkWidth is the number of recent block intervals drawn
+const sparkWidth = 60
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+func (d *Dashboard) render(out io.Writer, s snapshot) {
+	st := s.status
+
+	sync := "synced"
+	if st.CatchingUp {
+		sync = "catching up"
+	}
+	age := s.takenAt.Sub(st.BlockTime).Round(100 * time.Millisecond)
+	fmt.Fprintf(out, "%s  %s  %s  height %d  %s  last block %s ago\n\n",
+		d.cfg.Title, st.Network, st.Moniker, st.Height, sync, age)
+
+	d.renderBlockTimes(out, s.intervals)
+
+	if s.difficulty != nil {
+		row(out, "Difficulty", fmt.Sprintf("%d", *s.difficulty))
+	} else if d.cfg.DifficultyStore != "" {
+		row(out, "Difficulty", "unavailable")
+	}
+
+	row(out, "Mempool", fmt.Sprintf("%d txs, %s", st.MempoolTxs, formatBytes(st.MempoolSize)))
+	row(out, "Peers", fmt.Sprintf("%d", st.Peers))
+
+	if s.proofs == 0 {
+		row(out, "Proofs", fmt.Sprintf("none submitted in the last %d blocks", s.sampled))
+	} else {
+		rate := 100 * float64(s.accepted) / float64(s.proofs)
+		row(out, "Proofs", fmt.Sprintf("%d submitted, %d accepted (%.1f%%) in the last %d blocks",
+			s.proofs, s.accepted, rate, s.sampled))
+	}
+
+	symbol := strings.ToUpper(d.cfg.RewardDenom)
+	perBlock := new(big.Int)
+	if s.sampled > 0 {
+		perBlock.Quo(s.rewards, big.NewInt(int64(s.sampled)))
+	}
+	row(out, "Rewards", fmt.Sprintf("%s %s minted in the last %d blocks, %s %s per block",
+		formatAmount(s.rewards, d.cfg.RewardDecimals), symbol, s.sampled,
+		formatAmount(perBlock, d.cfg.RewardDecimals), symbol))
+
+	d.renderMetrics(out, s)
+}
+
+func (d *Dashboard) renderBlockTimes(out io.Writer, intervals []time.Duration) {
+	target := d.cfg.TargetBlockTime
+	if len(intervals) == 0 {
+		row(out, "Block time", fmt.Sprintf("target %s, not enough blocks sampled", target))
+		return
+	}
+
+	var total, slowest time.Duration
+	onTarget := 0
+	for _, interval := range intervals {
+		total += interval
+		if interval > slowest {
+			slowest = interval
+		}
+		if float64(interval) <= float64(target)*onTargetTolerance {
+			onTarget++
+		}
+	}
+	avg := total / time.Duration(len(intervals))
+
+	row(out, "Block time", fmt.Sprintf("avg %s  target %s  on target %.0f%%  slowest %s  (%d intervals)",
+		seconds(avg), seconds(target), 100*float64(onTarget)/float64(len(intervals)), seconds(slowest), len(intervals)))
+
+	// Bars span zero to twice the target; anything slower is drawn full height
+	recent := intervals
+	if len(recent) > sparkWidth {
+		recent = recent[len(recent)-sparkWidth:]
+	}
+	var spark strings.Builder
+	for _, interval := range recent {
+		level := int(float64(interval) / float64(2*target) * float64(len(sparkLevels)-1))
+		if level >= len(sparkLevels) {
+			level = len(sparkLevels) - 1
+		}
+		if level < 0 {
+			level = 0
+		}
+		spark.WriteRune(sparkLevels[level])
+	}
+	row(out, "", spark.String())
+}
+
+func (d *Dashboard) renderMetrics(out io.Writer, s snapshot) {
+	if d.cfg.MetricsURL == "" {
+		return
+	}
+	if s.metricsErr != nil {
+		row(out, "Metrics", fmt.Sprintf("unavailable (%v); enable instrumentation.prometheus in config.toml", s.metricsErr))
+		return
+	}
+
+	var parts []string
+	sum, okSum := s.metrics.lookup("consensus_block_interval_seconds_sum")
+	count, okCount := s.metrics.lookup("consensus_block_interval_seconds_count")
+	if okSum && okCount && count > 0 {
+		parts = append(parts, fmt.Sprintf("avg block time since start %s", seconds(time.Duration(sum/count*float64(time.Second)))))
+	}
+	if rounds, ok := s.metrics.lookup("consensus_rounds"); ok {
+		parts = append(parts, fmt.Sprintf("consensus rounds %.0f", rounds))
+	}
+	if failed, ok := s.metrics.lookup("mempool_failed_txs"); ok {
+		parts = append(parts, fmt.Sprintf("mempool rejected %.0f txs", failed))
+	}
+
+	if len(parts) == 0 {
+		row(out, "Metrics", "no consensus or mempool metrics exported")
+		return
+	}
+	row(out, "Metrics", strings.Join(parts, ", "))
+}
+
+func row(out io.Writer, label, value string) {
+	fmt.Fprintf(out, "%-12s  %s\n", label, value)
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// formatAmount renders base units as a decimal token amount
+func formatAmount(amount *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return amount.String()
+	}
+
+	digits := amount.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
@@ -0,0 +1,211 @@
+package dashboard
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rpcClient reads the CometBFT JSON-RPC endpoints of the local node
+type rpcClient struct {
+	base string
+	http *http.Client
+}
+
+func newRPCClient(node string) *rpcClient {
+	// The SDK --node flag uses tcp://; the RPC server speaks HTTP on the same port
+	base := strings.TrimSuffix(node, "/")
+	if strings.HasPrefix(base, "tcp://") {
+		base = "http://" + strings.TrimPrefix(base, "tcp://")
+	}
+
+	return &rpcClient{
+		base: base,
+		http: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (c *rpcClient) get(method string, params url.Values, out interface{}) error {
+	endpoint := c.base + "/" + method
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	resp, err := c.http.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf("%s: %s %s", method, envelope.Error.Message, envelope.Error.Data)
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
+
+type nodeStatus struct {
+	Network     string
+	Moniker     string
+	Height      int64
+	BlockTime   time.Time
+	CatchingUp  bool
+	Peers       int
+	MempoolTxs  int
+	MempoolSize int64
+}
+
+func (c *rpcClient) status() (nodeStatus, error) {
+	var status struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Moniker string `json:"moniker"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string    `json:"latest_block_height"`
+			LatestBlockTime   time.Time `json:"latest_block_time"`
+			CatchingUp        bool      `json:"catching_up"`
+		} `json:"sync_info"`
+	}
+	if err := c.get("status", nil, &status); err != nil {
+		return nodeStatus{}, err
+	}
+
+	var netInfo struct {
+		NPeers string `json:"n_peers"`
+	}
+	if err := c.get("net_info", nil, &netInfo); err != nil {
+		return nodeStatus{}, err
+	}
+
+	var mempool struct {
+		NTxs       string `json:"n_txs"`
+		TotalBytes string `json:"total_bytes"`
+	}
+	if err := c.get("num_unconfirmed_txs", nil, &mempool); err != nil {
+		return nodeStatus{}, err
+	}
+
+	height, _ := strconv.ParseInt(status.SyncInfo.LatestBlockHeight, 10, 64)
+	peers, _ := strconv.Atoi(netInfo.NPeers)
+	mempoolTxs, _ := strconv.Atoi(mempool.NTxs)
+	mempoolSize, _ := strconv.ParseInt(mempool.TotalBytes, 10, 64)
+
+	return nodeStatus{
+		Network:     status.NodeInfo.Network,
+		Moniker:     status.NodeInfo.Moniker,
+		Height:      height,
+		BlockTime:   status.SyncInfo.LatestBlockTime,
+		CatchingUp:  status.SyncInfo.CatchingUp,
+		Peers:       peers,
+		MempoolTxs:  mempoolTxs,
+		MempoolSize: mempoolSize,
+	}, nil
+}
+
+type rpcEvent struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+type block struct {
+	Time time.Time
+	Txs  [][]byte
+}
+
+func (c *rpcClient) block(height int64) (block, error) {
+	var result struct {
+		Block struct {
+			Header struct {
+				Time time.Time `json:"time"`
+			} `json:"header"`
+			Data struct {
+				Txs []string `json:"txs"`
+			} `json:"data"`
+		} `json:"block"`
+	}
+	if err := c.get("block", url.Values{"height": {strconv.FormatInt(height, 10)}}, &result); err != nil {
+		return block{}, err
+	}
+
+	b := block{Time: result.Block.Header.Time}
+	for _, tx := range result.Block.Data.Txs {
+		bz, err := base64.StdEncoding.DecodeString(tx)
+		if err != nil {
+			return block{}, fmt.Errorf("block %d: %w", height, err)
+		}
+		b.Txs = append(b.Txs, bz)
+	}
+	return b, nil
+}
+
+type blockResults struct {
+	TxCodes []uint32
+	Events  []rpcEvent // Transaction and block events together
+}
+
+func (c *rpcClient) blockResults(height int64) (blockResults, error) {
+	var result struct {
+		TxsResults []struct {
+			Code   uint32     `json:"code"`
+			Events []rpcEvent `json:"events"`
+		} `json:"txs_results"`
+		FinalizeBlockEvents []rpcEvent `json:"finalize_block_events"`
+		BeginBlockEvents    []rpcEvent `json:"begin_block_events"`
+		EndBlockEvents      []rpcEvent `json:"end_block_events"`
+	}
+	if err := c.get("block_results", url.Values{"height": {strconv.FormatInt(height, 10)}}, &result); err != nil {
+		return blockResults{}, err
+	}
+
+	var r blockResults
+	for _, tx := range result.TxsResults {
+		r.TxCodes = append(r.TxCodes, tx.Code)
+		r.Events = append(r.Events, tx.Events...)
+	}
+	r.Events = append(r.Events, result.FinalizeBlockEvents...)
+	r.Events = append(r.Events, result.BeginBlockEvents...)
+	r.Events = append(r.Events, result.EndBlockEvents...)
+	return r, nil
+}
+
+// storeValue reads a raw key from a module store through ABCI
+func (c *rpcClient) storeValue(storeKey string, key []byte) ([]byte, error) {
+	params := url.Values{
+		"path": {fmt.Sprintf("%q", "/store/"+storeKey+"/key")},
+		"data": {"0x" + hex.EncodeToString(key)},
+	}
+
+	var result struct {
+		Response struct {
+			Code  uint32 `json:"code"`
+			Log   string `json:"log"`
+			Value string `json:"value"`
+		} `json:"response"`
+	}
+	if err := c.get("abci_query", params, &result); err != nil {
+		return nil, err
+	}
+	if result.Response.Code != 0 {
+		return nil, fmt.Errorf("abci query: %s", result.Response.Log)
+	}
+	return base64.StdEncoding.DecodeString(result.Response.Value)
+}
@@ -0,0 +1,121 @@
+// Package diagnostics serves the runtime diagnostics of a chain daemon over
+// HTTP, to debug block time overruns on production nodes: Go's pprof
+// profiles, a dump of every goroutine, a summary of the runtime, and the time
+// each module spent in each phase of the recent blocks. The server is opt-in
+// and should listen on a loopback or otherwise private address only, as the
+// profiles expose the node's internals and cost CPU to take.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strconv"
+	"time"
+)
+
+// defaultRecentBlocks is the number of blocks /debug/timings returns unless
+// asked for more
+const defaultRecentBlocks = 20
+
+// Handler serves the diagnostics, with the module timings of timings:
+//
+//	/debug/pprof/          pprof index and profiles
+//	/debug/goroutines      stacks of every goroutine, as text
+//	/debug/runtime         goroutines, memory and GC summary, as JSON
+//	/debug/timings?blocks= module timings of the recent blocks, as JSON
+func Handler(timings *Timings) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, readRuntime())
+	})
+
+	mux.HandleFunc("/debug/timings", func(w http.ResponseWriter, r *http.Request) {
+		n := defaultRecentBlocks
+		if s := r.URL.Query().Get("blocks"); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil || v <= 0 {
+				http.Error(w, "blocks must be a positive number", http.StatusBadRequest)
+				return
+			}
+			n = v
+		}
+		writeJSON(w, timings.Recent(n))
+	})
+	return mux
+}
+
+// Serve serves Handler of timings on addr until ctx is done
+func Serve(ctx context.Context, addr string, timings *Timings) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           Handler(timings),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Runtime summarizes the state of the Go runtime
+type Runtime struct {
+	Goroutines int    `json:"goroutines"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	NumCPU     int    `json:"num_cpu"`
+	HeapAlloc  uint64 `json:"heap_alloc_bytes"`
+	HeapInuse  uint64 `json:"heap_inuse_bytes"`
+	Sys        uint64 `json:"sys_bytes"`
+	NumGC      uint32 `json:"num_gc"`
+	PauseTotal uint64 `json:"gc_pause_total_ns"`
+	LastPause  uint64 `json:"gc_last_pause_ns"`
+}
+
+func readRuntime() Runtime {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return Runtime{
+		Goroutines: runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		HeapAlloc:  m.HeapAlloc,
+		HeapInuse:  m.HeapInuse,
+		Sys:        m.Sys,
+		NumGC:      m.NumGC,
+		PauseTotal: m.PauseTotalNs,
+		LastPause:  m.PauseNs[(m.NumGC+255)%256],
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
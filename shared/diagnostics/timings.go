@@ -0,0 +1,117 @@
+package diagnostics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimingBlocks is the number of recent blocks Default keeps timings of
+const DefaultTimingBlocks = 1000
+
+// Default collects the timings of the modules of the node. Modules record
+// into it whether or not the diagnostics server runs; recording a phase
+// costs a map update under a lock.
+var Default = NewTimings(DefaultTimingBlocks)
+
+// Timings holds how long each module spent in each phase of the recent
+// blocks, such as its BeginBlocker, EndBlocker or message handlers. It is
+// safe for concurrent use.
+type Timings struct {
+	mu     sync.Mutex
+	limit  int
+	blocks map[int64]*BlockTimings
+}
+
+// BlockTimings are the timings of one block: the time spent by module and
+// phase, summed when a phase runs several times, and the number of runs
+type BlockTimings struct {
+	Height  int64                              `json:"height"`
+	Total   time.Duration                      `json:"total_ns"`
+	Modules map[string]map[string]PhaseTimings `json:"modules"`
+}
+
+// PhaseTimings are the runs of a phase of a module in a block
+type PhaseTimings struct {
+	Elapsed time.Duration `json:"elapsed_ns"`
+	Runs    int           `json:"runs"`
+}
+
+// NewTimings returns timings keeping the last limit blocks
+func NewTimings(limit int) *Timings {
+	return &Timings{limit: limit, blocks: make(map[int64]*BlockTimings)}
+}
+
+// Track starts timing a phase of module at height and returns the function
+// ending it, to defer
+func (t *Timings) Track(height int64, module, phase string) func() {
+	start := time.Now()
+	return func() {
+		t.Record(height, module, phase, time.Since(start))
+	}
+}
+
+// Record adds the time a run of a phase of module at height took
+func (t *Timings) Record(height int64, module, phase string, elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	block, ok := t.blocks[height]
+	if !ok {
+		block = &BlockTimings{Height: height, Modules: make(map[string]map[string]PhaseTimings)}
+		t.blocks[height] = block
+		t.pruneLocked(height)
+	}
+	phases, ok := block.Modules[module]
+	if !ok {
+		phases = make(map[string]PhaseTimings)
+		block.Modules[module] = phases
+	}
+	p := phases[phase]
+	p.Elapsed += elapsed
+	p.Runs++
+	phases[phase] = p
+	block.Total += elapsed
+}
+
+// pruneLocked drops the blocks more than limit below height
+func (t *Timings) pruneLocked(height int64) {
+	if len(t.blocks) <= t.limit {
+		return
+	}
+	for h := range t.blocks {
+		if h <= height-int64(t.limit) {
+			delete(t.blocks, h)
+		}
+	}
+}
+
+// Recent returns copies of the timings of the last n blocks recorded, the
+// newest first
+func (t *Timings) Recent(n int) []BlockTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	heights := make([]int64, 0, len(t.blocks))
+	for h := range t.blocks {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	if n < len(heights) {
+		heights = heights[:n]
+	}
+
+	recent := make([]BlockTimings, len(heights))
+	for i, h := range heights {
+		block := t.blocks[h]
+		recent[i] = BlockTimings{Height: h, Total: block.Total, Modules: make(map[string]map[string]PhaseTimings, len(block.Modules))}
+		for module, phases := range block.Modules {
+			copied := make(map[string]PhaseTimings, len(phases))
+			for phase, p := range phases {
+				copied[phase] = p
+			}
+			recent[i].Modules[module] = copied
+		}
+	}
+	return recent
+}
@@ -0,0 +1,11 @@
+module shared
+
+go 1.21
+
+require (
+	github.com/btcsuite/btcd/btcec/v2 v2.1.3
+	github.com/btcsuite/btcd/btcutil v1.1.3
+	golang.org/x/crypto v0.12.0
+)
+
+require github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
@@ -0,0 +1,356 @@
+// Package htlc defines the hash time locked contract zChain atomic swaps lock
+// Z in. It is shared by the wallet, which builds and spends contracts, and the
+// utxo module, which reports their spends, so the two agree on the template.
+//
+// A contract is a redeem script paid to through its zsh1... script hash:
+//
+//	OP_IF
+//	    OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <hash> OP_EQUALVERIFY
+//	    OP_DUP OP_HASH160 <recipient>
+//	OP_ELSE
+//	    <lock time> OP_CHECKLOCKTIMEVERIFY OP_DROP
+//	    OP_DUP OP_HASH160 <refund>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+//
+// The recipient redeems it with the 32 byte preimage of hash, revealing the
+// preimage on chain; the refund address takes the value back once the lock
+// time has passed. The hash is SHA256, as in the hash time locks of EVM
+// chains, so the same secret unlocks both legs of a swap against NU, or an
+// asset on Altcoinchain or Polygon, without trusting the bridge.
+package htlc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"shared/address"
+)
+
+// Opcodes of the template
+const (
+	op0             = 0x00
+	opPushData1     = 0x4c
+	op1             = 0x51
+	op16            = 0x60
+	opIf            = 0x63
+	opElse          = 0x67
+	opEndIf         = 0x68
+	opDrop          = 0x75
+	opDup           = 0x76
+	opSize          = 0x82
+	opEqualVerify   = 0x88
+	opSHA256        = 0xa8
+	opHash160       = 0xa9
+	opCheckSig      = 0xac
+	opCheckLockTime = 0xb1
+)
+
+// PreimageSize is the size of the secret a contract is redeemed with
+const PreimageSize = 32
+
+// Lock times below LockTimeThreshold are block heights, above it Unix times.
+// Lock times are encoded in at most 5 bytes.
+const (
+	LockTimeThreshold = 500000000
+	MaxLockTime       = 1<<39 - 1
+)
+
+var (
+	ErrNotContract  = errors.New("not a hash time locked contract")
+	ErrPreimage     = errors.New("preimage does not match the contract hash")
+	ErrLockTime     = errors.New("invalid contract lock time")
+	ErrNotHTLCSpend = errors.New("not a spend of a hash time locked contract")
+)
+
+// Contract is the content of a hash time locked contract
+type Contract struct {
+	Hash      [32]byte        // SHA256 of the secret
+	Recipient address.Address // Redeems with the secret
+	Refund    address.Address // Takes the value back after LockTime
+	LockTime  uint64          // Block height or Unix time, as a transaction lock time
+}
+
+// New creates a contract paying recipient against the preimage of hash,
+// refundable to refund from lockTime on
+func New(hash [32]byte, recipient, refund address.Address, lockTime uint64) (Contract, error) {
+	if lockTime == 0 || lockTime > MaxLockTime {
+		return Contract{}, fmt.Errorf("%w: %d", ErrLockTime, lockTime)
+	}
+	return Contract{Hash: hash, Recipient: recipient, Refund: refund, LockTime: lockTime}, nil
+}
+
+// Script returns the redeem script of c
+func (c Contract) Script() []byte {
+	script := make([]byte, 0, 100)
+	script = append(script, opIf, opSize)
+	script = appendPush(script, []byte{PreimageSize})
+	script = append(script, opEqualVerify, opSHA256)
+	script = appendPush(script, c.Hash[:])
+	script = append(script, opEqualVerify, opDup, opHash160)
+	script = appendPush(script, c.Recipient.Bytes())
+	script = append(script, opElse)
+	script = appendPush(script, encodeNum(int64(c.LockTime)))
+	script = append(script, opCheckLockTime, opDrop, opDup, opHash160)
+	script = appendPush(script, c.Refund.Bytes())
+	return append(script, opEndIf, opEqualVerify, opCheckSig)
+}
+
+// Address returns the script hash the contract is paid to
+func (c Contract) Address() address.Address {
+	return address.FromScript(c.Script())
+}
+
+// Refundable reports whether the refund path is open at a block of height
+// and Unix time
+func (c Contract) Refundable(height, time int64) bool {
+	if c.LockTime < LockTimeThreshold {
+		return uint64(height) >= c.LockTime
+	}
+	return uint64(time) >= c.LockTime
+}
+
+// Verify checks that preimage unlocks c
+func (c Contract) Verify(preimage []byte) error {
+	if len(preimage) != PreimageSize || sha256.Sum256(preimage) != c.Hash {
+		return ErrPreimage
+	}
+	return nil
+}
+
+// Parse decodes a redeem script following the template, so a swap party can
+// audit the contract its counterparty funded before locking its own leg
+func Parse(script []byte) (Contract, error) {
+	pushes, err := parse(script)
+	if err != nil {
+		return Contract{}, err
+	}
+
+	var c Contract
+	template := []struct {
+		op   byte
+		data *[]byte
+	}{
+		{op: opIf}, {op: opSize}, {data: new([]byte)}, {op: opEqualVerify}, {op: opSHA256},
+		{data: new([]byte)}, {op: opEqualVerify}, {op: opDup}, {op: opHash160}, {data: new([]byte)},
+		{op: opElse}, {data: new([]byte)}, {op: opCheckLockTime}, {op: opDrop}, {op: opDup},
+		{op: opHash160}, {data: new([]byte)}, {op: opEndIf}, {op: opEqualVerify}, {op: opCheckSig},
+	}
+	if len(pushes) != len(template) {
+		return Contract{}, ErrNotContract
+	}
+	for i, want := range template {
+		got := pushes[i]
+		if want.data == nil {
+			if got.push || got.op != want.op {
+				return Contract{}, ErrNotContract
+			}
+			continue
+		}
+		if !got.push {
+			return Contract{}, ErrNotContract
+		}
+		*want.data = got.data
+	}
+
+	size, hash, recipient, lockTime, refund := *template[2].data, *template[5].data, *template[9].data, *template[11].data, *template[16].data
+	if !bytes.Equal(size, []byte{PreimageSize}) || len(hash) != 32 || len(recipient) != 20 || len(refund) != 20 {
+		return Contract{}, ErrNotContract
+	}
+	n, err := decodeNum(lockTime)
+	if err != nil || n <= 0 || n > MaxLockTime {
+		return Contract{}, fmt.Errorf("%w: %x", ErrLockTime, lockTime)
+	}
+
+	copy(c.Hash[:], hash)
+	copy(c.Recipient[:], recipient)
+	copy(c.Refund[:], refund)
+	c.LockTime = uint64(n)
+	if !bytes.Equal(c.Script(), script) {
+		return Contract{}, fmt.Errorf("%w: pushes are not minimal", ErrNotContract)
+	}
+	return c, nil
+}
+
+// RedeemScriptSig spends c with preimage: <signature> <pubkey> <preimage> 1
+// <redeem script>. signature is the 64 byte signature of the input's sighash
+// over the redeem script.
+func RedeemScriptSig(c Contract, signature []byte, hashType byte, pubKey, preimage []byte) ([]byte, error) {
+	if err := c.Verify(preimage); err != nil {
+		return nil, err
+	}
+	scriptSig, err := signaturePushes(signature, hashType, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	scriptSig = appendPush(scriptSig, preimage)
+	scriptSig = append(scriptSig, op1)
+	return appendPush(scriptSig, c.Script()), nil
+}
+
+// RefundScriptSig spends c through its refund path: <signature> <pubkey> 0
+// <redeem script>. The spending transaction's lock time must be at least the
+// contract's.
+func RefundScriptSig(c Contract, signature []byte, hashType byte, pubKey []byte) ([]byte, error) {
+	scriptSig, err := signaturePushes(signature, hashType, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	scriptSig = append(scriptSig, op0)
+	return appendPush(scriptSig, c.Script()), nil
+}
+
+// Spend is a decoded scriptSig spending a contract
+type Spend struct {
+	Contract Contract
+	Preimage []byte // Nil for a refund
+}
+
+// Refund reports whether the spend took the refund path
+func (s Spend) Refund() bool {
+	return s.Preimage == nil
+}
+
+// ParseSpend decodes the scriptSig of an input spending a contract. The
+// preimage of a redeem is returned whether or not it matches: the chain has
+// already checked it for an included spend.
+func ParseSpend(scriptSig []byte) (Spend, error) {
+	pushes, err := parse(scriptSig)
+	if err != nil {
+		return Spend{}, ErrNotHTLCSpend
+	}
+	for _, p := range pushes {
+		if !p.push {
+			return Spend{}, ErrNotHTLCSpend
+		}
+	}
+
+	var spend Spend
+	switch {
+	case len(pushes) == 5 && pushes[3].op == op1:
+		if len(pushes[2].data) != PreimageSize {
+			return Spend{}, ErrNotHTLCSpend
+		}
+		spend.Preimage = append([]byte(nil), pushes[2].data...)
+	case len(pushes) == 4 && pushes[2].op == op0:
+	default:
+		return Spend{}, ErrNotHTLCSpend
+	}
+
+	if spend.Contract, err = Parse(pushes[len(pushes)-1].data); err != nil {
+		return Spend{}, ErrNotHTLCSpend
+	}
+	return spend, nil
+}
+
+// ExtractPreimage returns the secret revealed by a scriptSig redeeming a
+// contract locked to hash, so the other leg of the swap can be claimed
+func ExtractPreimage(scriptSig []byte, hash [32]byte) ([]byte, bool) {
+	spend, err := ParseSpend(scriptSig)
+	if err != nil || spend.Refund() || spend.Contract.Hash != hash || spend.Contract.Verify(spend.Preimage) != nil {
+		return nil, false
+	}
+	return spend.Preimage, true
+}
+
+func signaturePushes(signature []byte, hashType byte, pubKey []byte) ([]byte, error) {
+	if len(signature) < 64 {
+		return nil, fmt.Errorf("signature must be at least 64 bytes, got %d", len(signature))
+	}
+	if len(pubKey) != 33 {
+		return nil, address.ErrInvalidPubKey
+	}
+	scriptSig := appendPush(nil, append(append([]byte{}, signature[:64]...), hashType))
+	return appendPush(scriptSig, pubKey), nil
+}
+
+// push is an opcode of a script, with the data it pushes
+type push struct {
+	op   byte
+	data []byte
+	push bool
+}
+
+// parse splits a script into opcodes; the template needs no pushes of more
+// than 255 bytes
+func parse(script []byte) ([]push, error) {
+	var ops []push
+	for i := 0; i < len(script); {
+		op := push{op: script[i]}
+		i++
+
+		n := 0
+		switch {
+		case op.op == op0:
+			op.push = true
+		case op.op >= 0x01 && op.op <= 0x4b:
+			n, op.push = int(op.op), true
+		case op.op == opPushData1:
+			if i >= len(script) {
+				return nil, ErrNotContract
+			}
+			n, op.push = int(script[i]), true
+			i++
+		case op.op >= op1 && op.op <= op16:
+			op.data, op.push = []byte{op.op - op1 + 1}, true
+		}
+		if n > 0 {
+			if i+n > len(script) {
+				return nil, ErrNotContract
+			}
+			op.data = script[i : i+n]
+			i += n
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// appendPush appends the shortest push of data
+func appendPush(script, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n == 0:
+		return append(script, op0)
+	case n == 1 && data[0] >= 1 && data[0] <= 16:
+		return append(script, op1+data[0]-1)
+	case n <= 0x4b:
+		script = append(script, byte(n))
+	default:
+		script = append(script, opPushData1, byte(n))
+	}
+	return append(script, data...)
+}
+
+// encodeNum encodes a positive script number, little endian with the sign in
+// the top bit of the last byte
+func encodeNum(n int64) []byte {
+	var out []byte
+	for ; n > 0; n >>= 8 {
+		out = append(out, byte(n))
+	}
+	if len(out) > 0 && out[len(out)-1]&0x80 != 0 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// decodeNum decodes a minimally encoded script number of at most 5 bytes
+func decodeNum(v []byte) (int64, error) {
+	if len(v) == 0 || len(v) > 5 {
+		return 0, ErrLockTime
+	}
+	if v[len(v)-1]&0x7f == 0 && (len(v) == 1 || v[len(v)-2]&0x80 == 0) {
+		return 0, ErrLockTime
+	}
+	if v[len(v)-1]&0x80 != 0 {
+		return 0, ErrLockTime
+	}
+	var n int64
+	for i, b := range v {
+		n |= int64(b) << uint(8*i)
+	}
+	return n, nil
+}
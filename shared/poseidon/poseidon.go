@@ -0,0 +1,197 @@
+// Package poseidon is the Poseidon hash over the BN254 scalar field, the
+// field of the shielded pool's circuits. It hashes two field elements with
+// the parameters circomlib uses for two inputs: width 3, 8 full and 57
+// partial rounds and the x^5 S-box, so a Merkle path verified in a circuit
+// matches the tree kept on chain.
+//
+// The round constants and MDS matrix are derived as in the reference
+// implementation, from the Grain LFSR seeded with the parameters, rather than
+// listed. The tests check the result against circomlib.
+package poseidon
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Modulus is the order of the BN254 scalar field
+var Modulus, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+// Size is the length of an encoded field element
+const Size = 32
+
+const (
+	width         = 3
+	fullRounds    = 8
+	partialRounds = 57
+	fieldBits     = 254
+)
+
+var ErrNotInField = errors.New("value is not a field element")
+
+var (
+	once           sync.Once
+	roundConstants []*big.Int
+	mds            [width][width]*big.Int
+)
+
+// Hash returns the Poseidon hash of two field elements
+func Hash(left, right *big.Int) (*big.Int, error) {
+	for _, v := range []*big.Int{left, right} {
+		if v.Sign() < 0 || v.Cmp(Modulus) >= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotInField, v)
+		}
+	}
+	once.Do(generateParams)
+
+	state := [width]*big.Int{new(big.Int), new(big.Int).Set(left), new(big.Int).Set(right)}
+	for r := 0; r < fullRounds+partialRounds; r++ {
+		for i := range state {
+			state[i].Add(state[i], roundConstants[r*width+i])
+			state[i].Mod(state[i], Modulus)
+		}
+
+		full := r < fullRounds/2 || r >= fullRounds/2+partialRounds
+		for i := range state {
+			if full || i == 0 {
+				state[i] = sbox(state[i])
+			}
+		}
+
+		var mixed [width]*big.Int
+		for i := range mixed {
+			mixed[i] = new(big.Int)
+			for j := range state {
+				mixed[i].Add(mixed[i], new(big.Int).Mul(mds[i][j], state[j]))
+			}
+			mixed[i].Mod(mixed[i], Modulus)
+		}
+		state = mixed
+	}
+	return state[0], nil
+}
+
+// HashBytes hashes two field elements encoded as 32 bytes big endian
+func HashBytes(left, right []byte) ([]byte, error) {
+	l, err := Element(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Element(right)
+	if err != nil {
+		return nil, err
+	}
+	h, err := Hash(l, r)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(h), nil
+}
+
+// Element decodes a field element from 32 bytes big endian
+func Element(bz []byte) (*big.Int, error) {
+	if len(bz) != Size {
+		return nil, fmt.Errorf("%w: %d bytes", ErrNotInField, len(bz))
+	}
+	v := new(big.Int).SetBytes(bz)
+	if v.Cmp(Modulus) >= 0 {
+		return nil, fmt.Errorf("%w: %x", ErrNotInField, bz)
+	}
+	return v, nil
+}
+
+// Encode is the 32 byte big endian encoding of a field element
+func Encode(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, Size))
+}
+
+// Reduce maps any byte string to a field element, as big endian mod Modulus
+func Reduce(bz []byte) []byte {
+	return Encode(new(big.Int).Mod(new(big.Int).SetBytes(bz), Modulus))
+}
+
+func sbox(x *big.Int) *big.Int {
+	x2 := new(big.Int).Mul(x, x)
+	x4 := x2.Mul(x2, x2)
+	x4.Mod(x4, Modulus)
+	return x4.Mod(x4.Mul(x4, x), Modulus)
+}
+
+// generateParams derives the round constants and the Cauchy MDS matrix from
+// the Grain LFSR. Constants not below Modulus are skipped; matrix seeds are
+// reduced.
+func generateParams() {
+	g := newGrain()
+	for len(roundConstants) < (fullRounds+partialRounds)*width {
+		if c := g.element(); c.Cmp(Modulus) < 0 {
+			roundConstants = append(roundConstants, c)
+		}
+	}
+
+	var xs, ys [width]*big.Int
+	for i := range xs {
+		xs[i] = new(big.Int).Mod(g.element(), Modulus)
+	}
+	for i := range ys {
+		ys[i] = new(big.Int).Mod(g.element(), Modulus)
+	}
+	for i := range mds {
+		for j := range mds[i] {
+			sum := new(big.Int).Add(xs[i], ys[j])
+			mds[i][j] = sum.ModInverse(sum.Mod(sum, Modulus), Modulus)
+		}
+	}
+}
+
+// grain is the 80 bit Grain LFSR of the Poseidon reference implementation
+type grain struct {
+	state []byte
+}
+
+func newGrain() *grain {
+	var state []byte
+	push := func(v, bits int) {
+		for i := bits - 1; i >= 0; i-- {
+			state = append(state, byte(v>>i&1))
+		}
+	}
+	push(1, 2) // Prime field
+	push(0, 4) // x^alpha S-box
+	push(fieldBits, 12)
+	push(width, 12)
+	push(fullRounds, 10)
+	push(partialRounds, 10)
+	push(1<<30-1, 30)
+
+	g := &grain{state: state}
+	for i := 0; i < 160; i++ {
+		g.step()
+	}
+	return g
+}
+
+func (g *grain) step() byte {
+	s := g.state
+	bit := s[62] ^ s[51] ^ s[38] ^ s[23] ^ s[13] ^ s[0]
+	g.state = append(s[1:], bit)
+	return bit
+}
+
+// bit outputs the second of each pair of steps whose first is 1
+func (g *grain) bit() byte {
+	for g.step() == 0 {
+		g.step()
+	}
+	return g.step()
+}
+
+func (g *grain) element() *big.Int {
+	v := new(big.Int)
+	for i := 0; i < fieldBits; i++ {
+		v.Lsh(v, 1)
+		v.SetBit(v, 0, uint(g.bit()))
+	}
+	return v
+}
@@ -0,0 +1,18 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestCircomlibVector checks Hash against circomlib's poseidon([1, 2])
+func TestCircomlibVector(t *testing.T) {
+	want, _ := new(big.Int).SetString("115cc0f5e7d690413df64c6b9662e9cf2a3617f2743245519e19607a4417189a", 16)
+	got, err := Hash(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(want) != 0 {
+		t.Fatalf("poseidon(1, 2) = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package pow
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// DefaultDifficulty is the difficulty of a chain that never stored one
+const DefaultDifficulty = 1000000
+
+// MaxRetargetFactor bounds a retarget to 4x the previous difficulty either
+// way, as in Bitcoin
+const MaxRetargetFactor = 4
+
+// KVStore is the part of a key-value store the difficulty is kept in; the
+// stores of the Cosmos SDK satisfy it
+type KVStore interface {
+	Get(key []byte) []byte
+	Set(key, value []byte)
+}
+
+// GetDifficulty returns the difficulty stored under key as 8 big endian
+// bytes, DefaultDifficulty when none is
+func GetDifficulty(store KVStore, key []byte) uint64 {
+	bz := store.Get(key)
+	if len(bz) != 8 {
+		return DefaultDifficulty
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+// SetDifficulty stores difficulty under key as 8 big endian bytes
+func SetDifficulty(store KVStore, key []byte, difficulty uint64) {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, difficulty)
+	store.Set(key, bz)
+}
+
+// Retarget returns the difficulty that would have made blocks taking
+// actualMillis take targetMillis, within MaxRetargetFactor of current. It is
+// computed without overflow and is at least 1.
+func Retarget(current uint64, actualMillis, targetMillis int64) uint64 {
+	if actualMillis < 1 {
+		actualMillis = 1
+	}
+	if targetMillis < 1 {
+		targetMillis = 1
+	}
+
+	next := new(big.Int).SetUint64(current)
+	next.Mul(next, big.NewInt(targetMillis))
+	next.Quo(next, big.NewInt(actualMillis))
+
+	upper := new(big.Int).Mul(new(big.Int).SetUint64(current), big.NewInt(MaxRetargetFactor))
+	lower := new(big.Int).SetUint64(current / MaxRetargetFactor)
+	if next.Cmp(upper) > 0 {
+		next = upper
+	} else if next.Cmp(lower) < 0 {
+		next = lower
+	}
+
+	if !next.IsUint64() {
+		return ^uint64(0)
+	}
+	if next.Sign() == 0 {
+		return 1
+	}
+	return next.Uint64()
+}
+
+// ClampDifficulty returns difficulty within the governance bounds min and
+// max; a zero bound is not enforced
+func ClampDifficulty(difficulty, min, max uint64) uint64 {
+	if min > 0 && difficulty < min {
+		return min
+	}
+	if max > 0 && difficulty > max {
+		return max
+	}
+	return difficulty
+}
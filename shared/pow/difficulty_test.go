@@ -0,0 +1,69 @@
+package pow
+
+import (
+	"math"
+	"testing"
+)
+
+type memStore map[string][]byte
+
+func (s memStore) Get(key []byte) []byte { return s[string(key)] }
+func (s memStore) Set(key, value []byte) { s[string(key)] = value }
+
+func TestDifficultyStoreRoundTrip(t *testing.T) {
+	store := memStore{}
+	key := []byte("difficulty")
+	if got := GetDifficulty(store, key); got != DefaultDifficulty {
+		t.Fatalf("unset difficulty = %d, want %d", got, DefaultDifficulty)
+	}
+
+	SetDifficulty(store, key, math.MaxUint64)
+	if got := GetDifficulty(store, key); got != math.MaxUint64 {
+		t.Fatalf("got %d", got)
+	}
+
+	store[string(key)] = []byte{1, 2, 3}
+	if got := GetDifficulty(store, key); got != DefaultDifficulty {
+		t.Fatalf("malformed difficulty = %d, want %d", got, DefaultDifficulty)
+	}
+}
+
+func TestRetarget(t *testing.T) {
+	cases := []struct {
+		name           string
+		current        uint64
+		actual, target int64
+		next           uint64
+	}{
+		{"on target", 1000, 500, 500, 1000},
+		{"twice as fast", 1000, 250, 500, 2000},
+		{"twice as slow", 1000, 1000, 500, 500},
+		{"clamped up", 1000, 1, 500, 4000},
+		{"clamped down", 1000, 1000000, 500, 250},
+		{"zero duration", 1000, 0, 500, 4000},
+		{"negative target", 1000, 500, -1, 250},
+		{"never below 1", 1, 1000000, 1, 1},
+		{"no overflow", math.MaxUint64, 1, math.MaxInt64, math.MaxUint64},
+		{"no overflow of the clamp", math.MaxUint64 / 2, 1, 4, math.MaxUint64},
+	}
+	for _, c := range cases {
+		if got := Retarget(c.current, c.actual, c.target); got != c.next {
+			t.Errorf("%s: got %d, want %d", c.name, got, c.next)
+		}
+	}
+}
+
+func TestClampDifficulty(t *testing.T) {
+	cases := []struct{ difficulty, min, max, want uint64 }{
+		{50, 100, 200, 100},
+		{150, 100, 200, 150},
+		{250, 100, 200, 200},
+		{5, 0, 0, 5},
+		{math.MaxUint64, 100, 0, math.MaxUint64},
+	}
+	for _, c := range cases {
+		if got := ClampDifficulty(c.difficulty, c.min, c.max); got != c.want {
+			t.Errorf("clamp %d to [%d, %d] = %d, want %d", c.difficulty, c.min, c.max, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package pow
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DefaultMaxSupply is the hard cap of both chains' supply in whole tokens:
+// what the default halving schedule pays over its life, 0.05 per block for
+// 210M blocks doubled. Hardware bonuses are minted under the same cap, so it
+// binds before the schedule runs out.
+const DefaultMaxSupply = 21000000
+
+// Emission is the block reward schedule of a chain and the hard cap of the
+// supply it mints
+type Emission struct {
+	Schedule  HalvingSchedule
+	MaxSupply *big.Int // Base units
+}
+
+// DefaultEmission returns the emission of both chains
+func DefaultEmission() Emission {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	return Emission{
+		Schedule:  DefaultHalvingSchedule(),
+		MaxSupply: new(big.Int).Mul(big.NewInt(DefaultMaxSupply), unit),
+	}
+}
+
+// Validate checks the schedule and that the cap is not negative
+func (e Emission) Validate() error {
+	if err := e.Schedule.Validate(); err != nil {
+		return err
+	}
+	if e.MaxSupply == nil || e.MaxSupply.Sign() < 0 {
+		return fmt.Errorf("max supply must not be negative: %v", e.MaxSupply)
+	}
+	return nil
+}
+
+// Mintable returns how much of amount can be minted once minted has been:
+// all of it below the cap, the rest of the cap at it, nothing past it
+func (e Emission) Mintable(minted, amount *big.Int) *big.Int {
+	left := new(big.Int).Sub(e.MaxSupply, minted)
+	if left.Sign() <= 0 {
+		return new(big.Int)
+	}
+	if amount.Cmp(left) > 0 {
+		return left
+	}
+	return new(big.Int).Set(amount)
+}
+
+// NextHalving returns the first height after height the reward halves at, 0
+// once it has halved to zero
+func (s HalvingSchedule) NextHalving(height int64) int64 {
+	if s.Interval <= 0 || s.Reward(height).Sign() == 0 {
+		return 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	return (s.Halvings(height) + 1) * s.Interval
+}
@@ -0,0 +1,41 @@
+package pow
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMintableStopsAtTheCap(t *testing.T) {
+	e := Emission{Schedule: DefaultHalvingSchedule(), MaxSupply: big.NewInt(100)}
+	cases := []struct{ minted, amount, mintable int64 }{
+		{0, 10, 10},
+		{90, 10, 10},
+		{95, 10, 5},
+		{100, 10, 0},
+		{150, 10, 0},
+	}
+	for _, c := range cases {
+		if got := e.Mintable(big.NewInt(c.minted), big.NewInt(c.amount)); got.Cmp(big.NewInt(c.mintable)) != 0 {
+			t.Errorf("mintable of %d after %d = %s, want %d", c.amount, c.minted, got, c.mintable)
+		}
+	}
+}
+
+func TestDefaultEmission(t *testing.T) {
+	e := DefaultEmission()
+	if err := e.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The schedule pays at most the cap: interval * reward * 2
+	paid := new(big.Int).Mul(big.NewInt(DefaultHalvingInterval), big.NewInt(DefaultInitialReward))
+	paid.Lsh(paid, 1)
+	if paid.Cmp(e.MaxSupply) > 0 {
+		t.Fatalf("schedule pays %s over a cap of %s", paid, e.MaxSupply)
+	}
+
+	e.MaxSupply = big.NewInt(-1)
+	if err := e.Validate(); err == nil {
+		t.Fatal("negative cap accepted")
+	}
+}
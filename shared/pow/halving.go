@@ -0,0 +1,65 @@
+// Package pow holds the proof-of-work rules zChain and nuChain share: the
+// halving schedule of the block reward, the difficulty store and its
+// retarget, and the interface a chain mints rewards through. It has no
+// Cosmos SDK dependency, so the x/pow and utxo keepers of both chains use it
+// over their own stores and bank keepers and cannot drift apart.
+package pow
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Both chains start at 0.05 of their token per block, halving every 210M
+// blocks: about 3.3 years at the 0.5s block time
+const (
+	DefaultInitialReward   = 50000000000000000 // 0.05 * 10^18 base units
+	DefaultHalvingInterval = 210000000
+)
+
+// HalvingSchedule is a block reward halving every Interval blocks
+type HalvingSchedule struct {
+	InitialReward *big.Int
+	Interval      int64
+}
+
+// DefaultHalvingSchedule returns the schedule of both chains
+func DefaultHalvingSchedule() HalvingSchedule {
+	return HalvingSchedule{
+		InitialReward: big.NewInt(DefaultInitialReward),
+		Interval:      DefaultHalvingInterval,
+	}
+}
+
+// Validate checks the initial reward is not negative and the interval is
+// positive
+func (s HalvingSchedule) Validate() error {
+	if s.InitialReward == nil || s.InitialReward.Sign() < 0 {
+		return fmt.Errorf("initial reward must not be negative: %v", s.InitialReward)
+	}
+	if s.Interval <= 0 {
+		return fmt.Errorf("halving interval must be positive: %d", s.Interval)
+	}
+	return nil
+}
+
+// Halvings returns the number of halvings before height
+func (s HalvingSchedule) Halvings(height int64) int64 {
+	if height <= 0 || s.Interval <= 0 {
+		return 0
+	}
+	return height / s.Interval
+}
+
+// Reward returns the block reward at height: the initial reward halved once
+// per interval, rounding down, until it reaches zero
+func (s HalvingSchedule) Reward(height int64) *big.Int {
+	if s.InitialReward == nil {
+		return new(big.Int)
+	}
+	halvings := s.Halvings(height)
+	if halvings >= int64(s.InitialReward.BitLen()) {
+		return new(big.Int)
+	}
+	return new(big.Int).Rsh(s.InitialReward, uint(halvings))
+}
@@ -0,0 +1,115 @@
+package pow
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRewardHalvesAtIntervalBoundaries(t *testing.T) {
+	s := HalvingSchedule{InitialReward: big.NewInt(1000), Interval: 10}
+	cases := []struct {
+		height int64
+		reward int64
+	}{
+		{-1, 1000},
+		{0, 1000},
+		{9, 1000},
+		{10, 500},
+		{19, 500},
+		{20, 250},
+		{90, 1}, // 1000 >> 9
+		{100, 0},
+		{1 << 62, 0},
+	}
+	for _, c := range cases {
+		if got := s.Reward(c.height); got.Cmp(big.NewInt(c.reward)) != 0 {
+			t.Errorf("reward at %d = %s, want %d", c.height, got, c.reward)
+		}
+	}
+}
+
+func TestDefaultScheduleReachesZero(t *testing.T) {
+	s := DefaultHalvingSchedule()
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Reward(DefaultHalvingInterval - 1); got.Cmp(big.NewInt(DefaultInitialReward)) != 0 {
+		t.Errorf("reward before the first halving = %s", got)
+	}
+
+	// 0.05 * 10^18 has 56 bits, so it is gone after 56 halvings and
+	// shifting further must not wrap around
+	last := int64(big.NewInt(DefaultInitialReward).BitLen())
+	if got := s.Reward((last - 1) * DefaultHalvingInterval); got.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("reward of the last halving = %s, want 1", got)
+	}
+	for _, halvings := range []int64{last, 64, 1000} {
+		if got := s.Reward(halvings * DefaultHalvingInterval); got.Sign() != 0 {
+			t.Errorf("reward after %d halvings = %s, want 0", halvings, got)
+		}
+	}
+}
+
+func TestNextHalving(t *testing.T) {
+	s := HalvingSchedule{InitialReward: big.NewInt(4), Interval: 10}
+	cases := []struct{ height, next int64 }{
+		{-5, 10},
+		{0, 10},
+		{9, 10},
+		{10, 20},
+		{29, 30},
+		{30, 0}, // 4 >> 3 is zero
+	}
+	for _, c := range cases {
+		if got := s.NextHalving(c.height); got != c.next {
+			t.Errorf("next halving after %d = %d, want %d", c.height, got, c.next)
+		}
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	invalid := []HalvingSchedule{
+		{InitialReward: nil, Interval: 10},
+		{InitialReward: big.NewInt(-1), Interval: 10},
+		{InitialReward: big.NewInt(1), Interval: 0},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v accepted", s)
+		}
+	}
+}
+
+type recordingMinter struct {
+	minted *big.Int
+	err    error
+}
+
+func (m *recordingMinter) MintReward(_ []byte, amount *big.Int) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.minted = amount
+	return nil
+}
+
+func TestDistributeReward(t *testing.T) {
+	s := HalvingSchedule{InitialReward: big.NewInt(2), Interval: 10}
+
+	m := &recordingMinter{}
+	reward, err := DistributeReward(m, s, 10, []byte("miner"))
+	if err != nil || reward.Cmp(big.NewInt(1)) != 0 || m.minted.Cmp(reward) != 0 {
+		t.Fatalf("got reward %v, minted %v, err %v", reward, m.minted, err)
+	}
+
+	// Nothing is minted once the reward is zero
+	m = &recordingMinter{err: errors.New("minted")}
+	if reward, err := DistributeReward(m, s, 20, []byte("miner")); err != nil || reward.Sign() != 0 {
+		t.Fatalf("got reward %v, err %v", reward, err)
+	}
+
+	if _, err := DistributeReward(m, s, 0, []byte("miner")); err == nil {
+		t.Fatal("mint error dropped")
+	}
+}
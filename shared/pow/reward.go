@@ -0,0 +1,22 @@
+package pow
+
+import "math/big"
+
+// Minter mints a block reward to a miner. A chain implements it over its
+// bank keeper and the context of the block rewarded, minting its own denom.
+type Minter interface {
+	MintReward(miner []byte, amount *big.Int) error
+}
+
+// DistributeReward mints the reward the schedule pays at height to miner and
+// returns it. Nothing is minted once the reward has halved to zero.
+func DistributeReward(m Minter, s HalvingSchedule, height int64, miner []byte) (*big.Int, error) {
+	reward := s.Reward(height)
+	if reward.Sign() == 0 {
+		return reward, nil
+	}
+	if err := m.MintReward(miner, reward); err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
@@ -0,0 +1,178 @@
+// Package sighash defines the digest a transparent zChain input signs. It is
+// shared by the utxo module, which verifies signatures, and the wallet, which
+// makes them, so the two cannot drift apart.
+//
+// The digest commits to the chain ID, the spent outputs and the relative lock
+// times of the inputs, the amount of the
+// output being spent and, depending on the hash type, the outputs of the
+// transaction. A signature therefore cannot be moved to another chain and
+// outputs or amounts cannot be changed under it. The transaction hash is not
+// signed: it leaves out output scripts and the amounts of spent outputs.
+package sighash
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"shared/address"
+)
+
+// HashType selects the parts of a transaction a signature commits to. It is
+// appended to the signature.
+type HashType byte
+
+const (
+	All          HashType = 0x01 // Every input and output
+	None         HashType = 0x02 // Every input, no outputs
+	Single       HashType = 0x03 // Every input, the output of the same index
+	AnyoneCanPay HashType = 0x80 // Combined with the above: only the input signed
+)
+
+// Valid reports whether t is one of the defined hash types
+func (t HashType) Valid() bool {
+	switch t &^ AnyoneCanPay {
+	case All, None, Single:
+		return true
+	}
+	return false
+}
+
+var ErrHashType = errors.New("invalid signature hash type")
+
+// Input names the output an input spends and its relative lock time, see
+// the utxo script package for the sequence encoding
+type Input struct {
+	TxHash   string
+	Index    uint32
+	Sequence uint32
+}
+
+// Output is a transaction output
+type Output struct {
+	Amount       string
+	Address      string
+	ScriptPubkey []byte
+}
+
+// Tx is the part of a UTXO transaction signatures commit to
+type Tx struct {
+	ChainID  string
+	Inputs   []Input
+	Outputs  []Output
+	Fee      string
+	LockTime uint64
+}
+
+var domain = []byte("z-sighash/v2")
+
+// Digest is the hash signed by input index. subscript is the script being
+// executed from its last OP_CODESEPARATOR on, AddressScript for an output
+// locked to an address, and amount the amount of the output spent.
+//
+// The serialization is sha256d over the domain, the hash type and then, each
+// integer as 8 bytes big endian and each string or script prefixed by its
+// length: the chain ID, the signed inputs with their sequences, the input index, amount and
+// subscript, the signed outputs, the fee and the lock time.
+func Digest(tx *Tx, index int, subscript []byte, amount string, hashType HashType) ([]byte, error) {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input %d out of range", index)
+	}
+	if !hashType.Valid() {
+		return nil, fmt.Errorf("%w: 0x%02x", ErrHashType, byte(hashType))
+	}
+
+	h := sha256.New()
+	h.Write(domain)
+	h.Write([]byte{byte(hashType)})
+	writeBytes(h, []byte(tx.ChainID))
+
+	inputs := tx.Inputs
+	if hashType&AnyoneCanPay != 0 {
+		inputs = tx.Inputs[index : index+1]
+	}
+	writeUint64(h, uint64(len(inputs)))
+	for _, input := range inputs {
+		writeBytes(h, []byte(input.TxHash))
+		writeUint64(h, uint64(input.Index))
+		writeUint64(h, uint64(input.Sequence))
+	}
+
+	writeUint64(h, uint64(index))
+	writeBytes(h, []byte(amount))
+	writeBytes(h, subscript)
+
+	var outputs []Output
+	switch hashType &^ AnyoneCanPay {
+	case All:
+		outputs = tx.Outputs
+	case Single:
+		if index >= len(tx.Outputs) {
+			return nil, fmt.Errorf("%w: no output %d for SIGHASH_SINGLE", ErrHashType, index)
+		}
+		outputs = tx.Outputs[index : index+1]
+	}
+	writeUint64(h, uint64(len(outputs)))
+	for _, output := range outputs {
+		writeBytes(h, []byte(output.Amount))
+		writeBytes(h, []byte(output.Address))
+		writeBytes(h, output.ScriptPubkey)
+	}
+
+	writeBytes(h, []byte(tx.Fee))
+	writeUint64(h, tx.LockTime)
+
+	first := h.Sum(nil)
+	second := sha256.Sum256(first)
+	return second[:], nil
+}
+
+// AddressScript is the locking script of an output without a script of its
+// own: OP_DUP OP_HASH160 <addr> OP_EQUALVERIFY OP_CHECKSIG, paying to the
+// public key hash the address is
+func AddressScript(addr address.Address) []byte {
+	script := make([]byte, 0, 25)
+	script = append(script, 0x76, 0xa9, 0x14)
+	script = append(script, addr.Bytes()...)
+	return append(script, 0x88, 0xac)
+}
+
+// ScriptHashScript is the locking script paying to a script hash address:
+// OP_HASH160 <hash> OP_EQUAL. The output is spent by pushing the redeem script
+// hashing to hash after the data it needs.
+func ScriptHashScript(hash address.Address) []byte {
+	script := make([]byte, 0, 23)
+	script = append(script, 0xa9, 0x14)
+	script = append(script, hash.Bytes()...)
+	return append(script, 0x87)
+}
+
+// ScriptSig spends an AddressScript output: a push of the 64 byte signature
+// followed by its hash type, then a push of the 33 byte compressed public key
+func ScriptSig(signature []byte, hashType HashType, pubKey []byte) ([]byte, error) {
+	if len(signature) < 64 {
+		return nil, fmt.Errorf("signature must be at least 64 bytes, got %d", len(signature))
+	}
+	if len(pubKey) != 33 {
+		return nil, address.ErrInvalidPubKey
+	}
+
+	scriptSig := make([]byte, 0, 1+65+1+33)
+	scriptSig = append(scriptSig, 65)
+	scriptSig = append(scriptSig, signature[:64]...)
+	scriptSig = append(scriptSig, byte(hashType), 33)
+	return append(scriptSig, pubKey...), nil
+}
+
+func writeUint64(w io.Writer, v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	w.Write(buf[:])
+}
+
+func writeBytes(w io.Writer, b []byte) {
+	writeUint64(w, uint64(len(b)))
+	w.Write(b)
+}
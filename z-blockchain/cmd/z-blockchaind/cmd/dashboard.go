@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+
+	"shared/dashboard"
+	utxotypes "z-blockchain/x/utxo/types"
+)
+
+const (
+	flagMetrics = "metrics"
+	flagWindow  = "window"
+	flagRefresh = "refresh"
+	flagTarget  = "target"
+	flagOnce    = "once"
+)
+
+// StatusDashboardCmd shows a live terminal view of the local node
+func StatusDashboardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status-dashboard",
+		Short: "Live view of block times, difficulty, mempool, proofs, peers and rewards",
+		Long: `Poll the local node's RPC and Prometheus metrics and redraw a status view.
+
+Block times, proof acceptance and minted rewards are computed over the last
+--window blocks. Mining proofs are MsgSubmitMiningProof transactions; a proof is
+accepted when its transaction succeeded.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			metricsURL, _ := cmd.Flags().GetString(flagMetrics)
+			window, _ := cmd.Flags().GetInt(flagWindow)
+			refresh, _ := cmd.Flags().GetDuration(flagRefresh)
+			target, _ := cmd.Flags().GetDuration(flagTarget)
+			once, _ := cmd.Flags().GetBool(flagOnce)
+
+			decodeTx := clientCtx.TxConfig.TxDecoder()
+			d := dashboard.New(dashboard.Config{
+				Title:           "zChain",
+				Node:            clientCtx.NodeURI,
+				MetricsURL:      metricsURL,
+				TargetBlockTime: target,
+				Window:          window,
+				Refresh:         refresh,
+				RewardDenom:     "z",
+				RewardDecimals:  18,
+				// The keeper stores the difficulty under its key inside a store
+				// prefixed with the same key
+				DifficultyStore: utxotypes.StoreKey,
+				DifficultyKey:   append(append([]byte{}, utxotypes.DifficultyKey...), utxotypes.DifficultyKey...),
+				IsProofTx: func(bz []byte) bool {
+					tx, err := decodeTx(bz)
+					if err != nil {
+						return false
+					}
+					for _, msg := range tx.GetMsgs() {
+						if _, ok := msg.(*utxotypes.MsgSubmitMiningProof); ok {
+							return true
+						}
+					}
+					return false
+				},
+			})
+
+			if once {
+				return d.Print(cmd.OutOrStdout())
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return d.Run(ctx, cmd.OutOrStdout())
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+	cmd.Flags().String(flagMetrics, "http://localhost:26660/metrics", "Prometheus endpoint of the node; empty to skip metrics")
+	cmd.Flags().Int(flagWindow, 120, "Number of recent blocks to sample")
+	cmd.Flags().Duration(flagRefresh, 2*time.Second, "Redraw interval")
+	cmd.Flags().Duration(flagTarget, 500*time.Millisecond, "Target block time")
+	cmd.Flags().Bool(flagOnce, false, "Print a single snapshot and exit")
+
+	return cmd
+}
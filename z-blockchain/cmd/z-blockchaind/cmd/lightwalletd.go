@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+
+	"z-blockchain/x/utxo/client/lightwallet"
+	utxotypes "z-blockchain/x/utxo/types"
+)
+
+const flagListen = "listen"
+
+// LightwalletdCmd serves compact blocks of the node to shielded light wallets
+func LightwalletdCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lightwalletd",
+		Short: "Serve compact blocks to shielded light wallets over gRPC",
+		Long: `Serve the CompactTxStreamer gRPC service on --listen. Blocks are read from
+--node and reduced to their nullifiers, note commitments, memo ciphertexts and
+anchors, which is all a shielded wallet needs to find and spend its notes.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			addr, _ := cmd.Flags().GetString(flagListen)
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+
+			server := grpc.NewServer()
+			utxotypes.RegisterCompactTxStreamerServer(server, lightwallet.NewServer(clientCtx))
+			go func() {
+				<-cmd.Context().Done()
+				server.GracefulStop()
+			}()
+
+			cmd.Printf("Serving compact blocks of %s on %s\n", clientCtx.NodeURI, listener.Addr())
+			return server.Serve(listener)
+		},
+	}
+
+	cmd.Flags().String(flagListen, "localhost:9067", "Address to serve the CompactTxStreamer service on")
+	flags.AddQueryFlagsToCmd(cmd)
+	return cmd
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tmcfg "github.com/cometbft/cometbft/config"
+
+	utxotypes "z-blockchain/x/utxo/types"
+)
+
+// DefaultProfile is used when init is run without --profile
+const DefaultProfile = "mainnet"
+
+// Profile is a named network environment. Everything that differs between
+// mainnet, testnet and devnet is derived from here instead of edited by hand.
+type Profile struct {
+	Name              string
+	ChainID           string
+	BlockTime         time.Duration
+	HalvingInterval   int64
+	InitialDifficulty uint64
+	MinDifficulty     uint64
+	SupportedDevices  []string // Device classes eligible in the hardware registry
+	FaucetEnabled     bool
+	MinGasPrices      string
+}
+
+// consumerGPUs are the ASIC resistant devices allowed on every network
+var consumerGPUs = []string{
+	"nvidia-rtx-3080", "nvidia-rtx-3090", "nvidia-rtx-4080", "nvidia-rtx-4090",
+	"amd-rx-6800-xt", "amd-rx-6900-xt", "amd-rx-7800-xt", "amd-rx-7900-xtx",
+}
+
+// Profiles are the supported zChain environments
+var Profiles = map[string]Profile{
+	"mainnet": {
+		Name:              "mainnet",
+		ChainID:           "z-blockchain-1",
+		BlockTime:         200 * time.Millisecond,
+		HalvingInterval:   210000000,
+		InitialDifficulty: 1000000,
+		MinDifficulty:     1000000,
+		SupportedDevices:  append(append([]string{}, consumerGPUs...), "nvidia-a100", "nvidia-h100"),
+		FaucetEnabled:     false,
+		MinGasPrices:      "0.001z",
+	},
+	"testnet": {
+		Name:              "testnet",
+		ChainID:           "z-blockchain-testnet-1",
+		BlockTime:         200 * time.Millisecond,
+		HalvingInterval:   2100000,
+		InitialDifficulty: 10000,
+		MinDifficulty:     10000,
+		SupportedDevices:  append(append([]string{}, consumerGPUs...), "nvidia-a100", "nvidia-h100", "xilinx-fpga"),
+		FaucetEnabled:     true,
+		MinGasPrices:      "0z",
+	},
+	"devnet": {
+		Name:              "devnet",
+		ChainID:           "z-blockchain-devnet",
+		BlockTime:         time.Second,
+		HalvingInterval:   21000,
+		InitialDifficulty: 1,
+		MinDifficulty:     1,
+		SupportedDevices:  append(append([]string{}, consumerGPUs...), "nvidia-a100", "nvidia-h100", "xilinx-fpga", "cpu"),
+		FaucetEnabled:     true,
+		MinGasPrices:      "0z",
+	},
+}
+
+// GetProfile looks up a profile by name
+func GetProfile(name string) (Profile, error) {
+	profile, ok := Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, expected one of %v", name, ProfileNames())
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the profile names in a stable order
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyTendermintConfig sets consensus timing and mempool limits for the profile
+func (p Profile) ApplyTendermintConfig(cfg *tmcfg.Config) {
+	cfg.Consensus.TimeoutCommit = p.BlockTime
+	cfg.Consensus.CreateEmptyBlocks = true
+	cfg.Consensus.CreateEmptyBlocksInterval = p.BlockTime
+
+	// Optimize for hardware mining
+	cfg.Mempool.Size = 10000
+	cfg.Mempool.MaxTxsBytes = 1073741824 // 1GB
+	cfg.Mempool.CacheSize = 20000
+}
+
+// ApplyGenesis sets the utxo module genesis for the profile
+func (p Profile) ApplyGenesis(gs *utxotypes.GenesisState) {
+	gs.Difficulty = p.InitialDifficulty
+	gs.HalvingInterval = p.HalvingInterval
+	gs.HardwareAcceleration = len(p.SupportedDevices) > 0
+
+	gs.Params.HalvingInterval = p.HalvingInterval
+	gs.Params.MinDifficulty = p.MinDifficulty
+	gs.Params.HardwareAcceleration = gs.HardwareAcceleration
+	gs.Hardware = utxotypes.HardwareRegistry(p.SupportedDevices)
+}
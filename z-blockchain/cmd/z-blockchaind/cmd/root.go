@@ -4,7 +4,6 @@ import (
 	"errors"
 	"io"
 	"os"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -93,26 +92,55 @@ func NewRootCmd() (*cobra.Command, types.EncodingConfig) {
 func initTendermintConfig() *tmcfg.Config {
 	cfg := tmcfg.DefaultConfig()
 
-	// Set 0.5 second block time for zChain
-	cfg.Consensus.TimeoutCommit = 200 * time.Millisecond
-	cfg.Consensus.CreateEmptyBlocks = true
-	cfg.Consensus.CreateEmptyBlocksInterval = 200 * time.Millisecond
-
-	// Optimize for hardware mining
-	cfg.Mempool.Size = 10000
-	cfg.Mempool.MaxTxsBytes = 1073741824 // 1GB
-	cfg.Mempool.CacheSize = 20000
+	// Set 0.5 second block time for zChain; init --profile rewrites this per network
+	Profiles[DefaultProfile].ApplyTendermintConfig(cfg)
 
 	return cfg
 }
 
+// FaucetConfig controls the development token faucet
+type FaucetConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// CustomAppConfig extends the SDK app.toml with the network profile
+type CustomAppConfig struct {
+	serverconfig.Config `mapstructure:",squash"`
+
+	Profile     string            `mapstructure:"profile"`
+	Faucet      FaucetConfig      `mapstructure:"faucet"`
+	Diagnostics DiagnosticsConfig `mapstructure:"diagnostics"`
+}
+
+const profileConfigTemplate = `
+###############################################################################
+###                           Network Profile                               ###
+###############################################################################
+
+# Profile this node was initialized with (mainnet, testnet or devnet)
+profile = "{{ .Profile }}"
+
+[faucet]
+
+# Serve the token faucet. Never enable on mainnet.
+enabled = {{ .Faucet.Enabled }}
+
+[diagnostics]
+
+# Address of the diagnostics server, serving pprof profiles, goroutine dumps
+# and per-module block timings, e.g. "localhost:6061". Empty disables it. Keep
+# it private: the profiles expose the node's internals.
+address = "{{ .Diagnostics.Address }}"
+`
+
 // initAppConfig helps to override default appConfig template and configs.
 // return "", nil if no custom configuration is required for the application.
 func initAppConfig() (string, interface{}) {
-	type CustomAppConfig struct {
-		serverconfig.Config
-	}
+	return profileAppConfig(Profiles[DefaultProfile])
+}
 
+// profileAppConfig builds app.toml contents for a network profile
+func profileAppConfig(profile Profile) (string, CustomAppConfig) {
 	// Optionally allow the chain developer to overwrite the SDK's default
 	// server config.
 	srvCfg := serverconfig.DefaultConfig()
@@ -127,14 +155,16 @@ func initAppConfig() (string, interface{}) {
 	// - if you set srvCfg.MinGasPrices non-empty, validators CAN tweak their
 	//   own app.toml to override, or use this default value.
 	//
-	// In z-blockchain, we set the min gas prices to 0.
-	srvCfg.MinGasPrices = "0z"
+	// In z-blockchain, the min gas price comes from the network profile.
+	srvCfg.MinGasPrices = profile.MinGasPrices
 
 	customAppConfig := CustomAppConfig{
-		Config: *srvCfg,
+		Config:  *srvCfg,
+		Profile: profile.Name,
+		Faucet:  FaucetConfig{Enabled: profile.FaucetEnabled},
 	}
 
-	customAppTemplate := serverconfig.DefaultConfigTemplate
+	customAppTemplate := serverconfig.DefaultConfigTemplate + profileConfigTemplate
 
 	return customAppTemplate, customAppConfig
 }
@@ -144,7 +174,7 @@ func initRootCmd(rootCmd *cobra.Command, encodingConfig types.EncodingConfig) {
 	cfg.Seal()
 
 	rootCmd.AddCommand(
-		genutilcli.InitCmd(app.ModuleBasics, app.DefaultNodeHome),
+		InitCmd(app.ModuleBasics, app.DefaultNodeHome),
 		genutilcli.CollectGenTxsCmd(banktypes.GenesisBalancesIterator{}, app.DefaultNodeHome),
 		genutilcli.MigrateGenesisCmd(),
 		genutilcli.GenTxCmd(app.ModuleBasics, encodingConfig.TxConfig, banktypes.GenesisBalancesIterator{}, app.DefaultNodeHome),
@@ -157,11 +187,17 @@ func initRootCmd(rootCmd *cobra.Command, encodingConfig types.EncodingConfig) {
 		snapshot.Cmd(newApp),
 	)
 
-	server.AddCommands(rootCmd, app.DefaultNodeHome, newApp, appExport, addModuleInitFlags)
+	server.AddCommandsWithStartCmdOptions(rootCmd, app.DefaultNodeHome, newApp, appExport, server.StartCmdOptions{
+		AddFlags:  addModuleInitFlags,
+		PostSetup: startDiagnostics,
+	})
 
 	// add keybase, auxiliary RPC, query, and tx child commands
 	rootCmd.AddCommand(
 		rpc.StatusCommand(),
+		StatusDashboardCmd(),
+		LightwalletdCmd(),
+		StratumCmd(),
 		queryCommand(),
 		txCommand(),
 		keys.Commands(app.DefaultNodeHome),
@@ -188,6 +224,7 @@ func queryCommand() *cobra.Command {
 		rpc.BlockCommand(),
 		authcmd.QueryTxsByEventsCmd(),
 		authcmd.QueryTxCmd(),
+		SPVProofCmd(),
 	)
 
 	app.ModuleBasics.AddQueryCommands(cmd)
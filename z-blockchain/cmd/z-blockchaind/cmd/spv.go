@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+
+	"z-blockchain/x/utxo/client/spv"
+)
+
+const flagUTXOSetRoot = "utxo-set-root"
+
+// SPVProofCmd fetches the payment proof of a UTXO transaction and checks it
+// against the header of its block and a UTXO set root
+func SPVProofCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spv-proof [tx-hash]",
+		Short: "Fetch and verify the inclusion proof of a UTXO transaction",
+		Long: `Fetch the Merkle path of a UTXO transaction to its block's data hash and the
+accumulator proofs of its outputs, and verify them. The UTXO set root is taken
+from --utxo-set-root when given, as a light client would, and from the node
+otherwise.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			proof, err := spv.Fetch(cmd.Context(), clientCtx, args[0])
+			if err != nil {
+				return err
+			}
+
+			node, err := clientCtx.GetNode()
+			if err != nil {
+				return err
+			}
+			height := proof.Height()
+			block, err := node.Block(cmd.Context(), &height)
+			if err != nil {
+				return err
+			}
+
+			root := proof.Utxo.UtxoSetRoot
+			if rootHex, _ := cmd.Flags().GetString(flagUTXOSetRoot); rootHex != "" {
+				if root, err = hex.DecodeString(rootHex); err != nil {
+					return fmt.Errorf("invalid UTXO set root: %w", err)
+				}
+			}
+
+			if err := proof.Verify(block.Block.DataHash, root, clientCtx.TxConfig.TxDecoder()); err != nil {
+				return err
+			}
+			return clientCtx.PrintProto(&proof.Utxo)
+		},
+	}
+
+	cmd.Flags().String(flagUTXOSetRoot, "", "Hex UTXO set root committed at the inclusion height to verify against")
+	flags.AddQueryFlagsToCmd(cmd)
+	return cmd
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"time"
+
+	"cosmossdk.io/log"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+
+	"z-blockchain/x/utxo/client/stratum"
+	utxotypes "z-blockchain/x/utxo/types"
+)
+
+const (
+	flagPoolDifficulty = "pool-difficulty"
+	flagShareInterval  = "share-interval"
+	flagHardwareID     = "hardware-id"
+	flagListenV2       = "listen-v2"
+	flagAuthorityKey   = "authority-key"
+	flagJobDeclaration = "job-declaration"
+)
+
+// StratumCmd serves mining work of the node to external miners over stratum
+func StratumCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stratum",
+		Short: "Serve mining work to external miners over Stratum v1 and V2",
+		Long: `Serve stratum v1 on --listen. Every new block of --node becomes a job for the
+header committing to the --from address; shares are checked against the
+connection's difficulty, and those meeting the chain's difficulty are
+submitted as MsgSubmitMiningProof signed by --from, which receives the
+reward. Paying the workers is left to the pool operator.
+
+Every connection starts at --pool-difficulty and is retargeted toward one
+share per --share-interval, so a single GPU and a farm behind one proxy both
+submit at a steady rate; 0 keeps --pool-difficulty.
+
+--listen-v2 also serves Stratum V2, encrypted under a key certified by
+--authority-key, whose public key miners pin. With --job-declaration, V2
+miners may declare their own jobs, paying their own address; they submit the
+blocks of those jobs themselves.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+			txf, err := tx.NewFactoryCLI(clientCtx, cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			hardwareID, _ := cmd.Flags().GetString(flagHardwareID)
+			poolDifficulty, _ := cmd.Flags().GetUint64(flagPoolDifficulty)
+			shareInterval, _ := cmd.Flags().GetDuration(flagShareInterval)
+			jobDeclaration, _ := cmd.Flags().GetBool(flagJobDeclaration)
+			config := stratum.Config{
+				Pool:           clientCtx.GetFromAddress().String(),
+				HardwareID:     hardwareID,
+				PoolDifficulty: poolDifficulty,
+				ShareInterval:  shareInterval,
+				JobDeclaration: jobDeclaration,
+			}
+
+			addrV2, _ := cmd.Flags().GetString(flagListenV2)
+			if addrV2 != "" {
+				keyHex, _ := cmd.Flags().GetString(flagAuthorityKey)
+				key, err := hex.DecodeString(keyHex)
+				if err != nil || len(key) != 32 {
+					return errors.New("--listen-v2 needs a 32 byte hex --authority-key")
+				}
+				config.AuthorityKey, _ = btcec.PrivKeyFromBytes(key)
+			}
+			submit := func(_ context.Context, msg *utxotypes.MsgSubmitMiningProof) error {
+				return tx.BroadcastTx(clientCtx, txf, msg)
+			}
+			server, err := stratum.NewServer(clientCtx, config, submit, log.NewLogger(cmd.ErrOrStderr()))
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			errs := make(chan error, 2)
+			if addrV2 != "" {
+				listenerV2, err := net.Listen("tcp", addrV2)
+				if err != nil {
+					return err
+				}
+				cmd.Printf("Serving stratum v2 for %s on %s, authority key %x\n",
+					config.Pool, listenerV2.Addr(), schnorr.SerializePubKey(config.AuthorityKey.PubKey()))
+				go func() { errs <- server.ServeV2(ctx, listenerV2) }()
+			}
+
+			addr, _ := cmd.Flags().GetString(flagListen)
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			cmd.Printf("Serving stratum for %s on %s\n", config.Pool, listener.Addr())
+			go func() { errs <- server.Serve(ctx, listener) }()
+			return <-errs
+		},
+	}
+
+	cmd.Flags().String(flagListen, "0.0.0.0:3333", "Address to serve stratum on")
+	cmd.Flags().Uint64(flagPoolDifficulty, 1, "Starting difficulty of a share")
+	cmd.Flags().Duration(flagShareInterval, 10*time.Second, "Time between shares vardiff aims each connection at, 0 to disable")
+	cmd.Flags().String(flagHardwareID, "", "Hardware identifier submitted with the proofs, a device class of the hardware registry")
+	cmd.Flags().String(flagListenV2, "", "Address to serve Stratum V2 on, none by default")
+	cmd.Flags().String(flagAuthorityKey, "", "Hex secp256k1 key certifying the Stratum V2 server")
+	cmd.Flags().Bool(flagJobDeclaration, false, "Let Stratum V2 miners declare their own jobs")
+	flags.AddTxFlagsToCmd(cmd)
+	_ = cmd.MarkFlagRequired(flagHardwareID)
+	return cmd
+}
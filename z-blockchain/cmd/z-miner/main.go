@@ -0,0 +1,170 @@
+// Command z-miner mines Z on the CPU with the reference Equihash solver. It
+// fetches work from a node, solves nonces until a solution meets the
+// difficulty or a new block makes the work stale, and submits the
+// MsgSubmitMiningProof signed by --from, which receives the reward.
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/config"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"z-blockchain/app"
+	"z-blockchain/x/utxo/client/miner"
+	"z-blockchain/x/utxo/types"
+)
+
+const (
+	flagHardwareID = "hardware-id"
+	flagBlocks     = "blocks"
+	flagDriver     = "driver"
+	flagNonces     = "nonces"
+)
+
+func main() {
+	if err := newMineCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newMineCmd() *cobra.Command {
+	app.SetConfig()
+
+	encodingConfig := app.MakeEncodingConfig()
+	initClientCtx := client.Context{}.
+		WithCodec(encodingConfig.Codec).
+		WithInterfaceRegistry(encodingConfig.InterfaceRegistry).
+		WithTxConfig(encodingConfig.TxConfig).
+		WithLegacyAmino(encodingConfig.Amino).
+		WithInput(os.Stdin).
+		WithAccountRetriever(authtypes.AccountRetriever{}).
+		WithBroadcastMode(flags.BroadcastSync).
+		WithHomeDir(app.DefaultNodeHome).
+		WithViper("")
+
+	cmd := &cobra.Command{
+		Use:   "z-miner",
+		Short: "Mine Z with the reference CPU Equihash solver",
+		Long: `Fetch the latest block, difficulty and Equihash parameters from --node, solve
+nonces for the header committing to the --from address, and submit the first
+solution meeting the difficulty. Work is refetched after every nonce, so a new
+block restarts the search. --blocks limits the blocks mined; 0 mines until
+interrupted.
+
+The solver is plain Go on one core. At 144_5 a nonce takes a minute or more,
+longer than a mainnet block; it is meant for devnets and testnets running
+smaller parameters, such as 48_5.`,
+		Args: cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.ReadPersistentCommandFlags(initClientCtx, cmd.Flags())
+			if err != nil {
+				return err
+			}
+			if clientCtx, err = config.ReadFromClientConfig(clientCtx); err != nil {
+				return err
+			}
+			return client.SetCmdClientContextHandler(clientCtx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+			hardwareID, _ := cmd.Flags().GetString(flagHardwareID)
+			blocks, _ := cmd.Flags().GetUint64(flagBlocks)
+			creator := clientCtx.GetFromAddress().String()
+
+			var mined uint64
+			var nonce uint64
+			for blocks == 0 || mined < blocks {
+				work, err := miner.FetchWork(cmd.Context(), clientCtx)
+				if err != nil {
+					return err
+				}
+
+				start := time.Now()
+				msg, err := work.Mine(creator, hardwareID, work.Timestamp(start.UnixMilli()), nonce)
+				if err != nil {
+					return err
+				}
+				nonce++
+				if msg == nil {
+					continue
+				}
+
+				// A block committed while solving leaves the solution for an
+				// earlier height, still paid as an uncle within MaxUncleDepth
+				latest, err := miner.FetchWork(cmd.Context(), clientCtx)
+				if err != nil {
+					return err
+				}
+				if depth := latest.Height - work.Height; depth > types.MaxUncleDepth {
+					cmd.PrintErrf("solution at height %d stale after %s\n", work.Height+1, time.Since(start))
+					continue
+				} else if depth > 0 {
+					cmd.PrintErrf("solution at height %d is an uncle %d blocks deep\n", work.Height+1, depth)
+				}
+
+				cmd.PrintErrf("solved height %d nonce %d in %s\n", work.Height+1, msg.Nonce, time.Since(start))
+				if err := tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg); err != nil {
+					return err
+				}
+				mined++
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagHardwareID, "", "Hardware identifier submitted with the proof, a device class of the hardware registry")
+	cmd.Flags().Uint64(flagBlocks, 0, "Blocks to mine before exiting, 0 for no limit")
+	cmd.PersistentFlags().String(flags.FlagHome, app.DefaultNodeHome, "directory for config and data")
+	flags.AddTxFlagsToCmd(cmd)
+	_ = cmd.MarkFlagRequired(flagHardwareID)
+	cmd.AddCommand(newAttestCmd())
+	return cmd
+}
+
+func newAttestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attest [device-class]",
+		Short: "Benchmark this machine for a hardware registration",
+		Long: `Solve the benchmark challenge of the device class and --driver at the
+chain's Equihash parameters for --nonces nonces, and print the attestation a
+registrar submits with register-hardware: the driver fingerprint, the first
+solution as the benchmark proof, and the solutions per second measured.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+			driver, _ := cmd.Flags().GetString(flagDriver)
+			nonces, _ := cmd.Flags().GetUint64(flagNonces)
+
+			params, err := types.NewQueryClient(clientCtx).Params(cmd.Context(), &types.QueryParamsRequest{})
+			if err != nil {
+				return fmt.Errorf("query params: %w", err)
+			}
+
+			attestation, err := miner.Attest(params.Params.Equihash(), args[0], driver, nonces)
+			if err != nil {
+				return err
+			}
+			return clientCtx.PrintProto(attestation)
+		},
+	}
+
+	cmd.Flags().String(flagDriver, "", "Driver and firmware versions of the device, fingerprinted in the attestation")
+	cmd.Flags().Uint64(flagNonces, 4, "Nonces to solve for the measured rate")
+	flags.AddQueryFlagsToCmd(cmd)
+	_ = cmd.MarkFlagRequired(flagDriver)
+	return cmd
+}
@@ -10,6 +10,8 @@ require (
 	github.com/spf13/cobra v1.7.0
 	github.com/stretchr/testify v1.8.4
 	github.com/tendermint/tendermint v0.37.2
+	golang.org/x/crypto v0.12.0
+	golang.org/x/sync v0.3.0
 	google.golang.org/genproto/googleapis/api v0.0.0-20230726155614-23370e0ffb3e
 	google.golang.org/grpc v1.57.0
 	gopkg.in/yaml.v2 v2.4.0
@@ -20,4 +22,10 @@ require (
 	github.com/ethereum/go-ethereum v1.12.0
 	github.com/btcsuite/btcd/btcec/v2 v2.3.2
 	github.com/wealdtech/go-ec-codec v1.1.2
-)
\ No newline at end of file
+	github.com/consensys/gnark v0.9.1
+	github.com/consensys/gnark-crypto v0.12.1
+)
+
+require shared v0.0.0
+
+replace shared => ../shared
@@ -2,6 +2,7 @@ package keeper
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	
@@ -13,6 +14,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	
+	"shared/pow"
 	"z-blockchain/x/pow/types"
 	
 	// Hypothetical zk-SNARK library
@@ -73,12 +75,11 @@ func (k Keeper) VerifyZkProof(ctx sdk.Context, proof []byte, publicInputs []byte
 
 // MineBlock processes a mining attempt with zk-proof
 func (k Keeper) MineBlock(ctx sdk.Context, miner sdk.AccAddress, proof []byte) error {
-	// Get current difficulty and block header
+	// Get current difficulty
 	difficulty := k.GetDifficulty(ctx)
-	blockHeader := ctx.BlockHeader()
 	
 	// Prepare public inputs for zk-proof verification
-	publicInputs := k.PreparePublicInputs(blockHeader, difficulty, miner)
+	publicInputs := k.PreparePublicInputs(ctx, difficulty, miner)
 	
 	// Verify zk-SNARK proof
 	if !k.VerifyZkProof(ctx, proof, publicInputs) {
@@ -126,55 +127,55 @@ func (k Keeper) SynchronizeWithNuChain(ctx sdk.Context) error {
 	return k.layerZeroClient.SendMessage(k.nuChainEndpoint, payloadBytes)
 }
 
-// DistributeReward calculates and distributes mining rewards
+// DistributeReward mints the block reward of the halving schedule to the
+// miner in Z and notifies nuChain
 func (k Keeper) DistributeReward(ctx sdk.Context, miner sdk.AccAddress) error {
-	reward := k.CalculateReward(ctx.BlockHeight())
-	
-	// Mint new coins
-	coins := sdk.NewCoins(sdk.NewCoin("z", reward))
-	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
+	minter := bankMinter{ctx: ctx, bankKeeper: k.bankKeeper, denom: "z"}
+	reward, err := pow.DistributeReward(minter, pow.DefaultHalvingSchedule(), ctx.BlockHeight(), miner)
+	if err != nil {
 		return err
 	}
 	
-	// Send to miner
-	return k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, miner, coins)
-}
-
-	
 	// Notify nuChain of mining reward
-	if err := k.NotifyNuChain(ctx, miner, reward); err != nil {
+	if err := k.NotifyNuChain(ctx, miner, sdk.NewIntFromBigInt(reward), ""); err != nil {
 		k.logger.Error("Failed to notify nuChain of mining reward", "error", err)
 		// Don't fail the transaction, just log the error
 	}
-// CalculateReward implements halving mechanism
-func (k Keeper) CalculateReward(height int64) sdk.Int {
-	halvingInterval := int64(210000000) // 210M blocks
-	halvings := height / halvingInterval
-	
-	// Initial reward: 0.05 Z * 10^18 wei
-	initialReward := sdk.NewInt(50000000000000000)
 	
-	// Apply halving: reward = initial / (2^halvings)
-	if halvings >= 64 { // Prevent overflow
-		return sdk.ZeroInt()
+	return nil
+}
+
+// CalculateReward returns the block reward at height, see pow.HalvingSchedule
+func (k Keeper) CalculateReward(height int64) sdk.Int {
+	return sdk.NewIntFromBigInt(pow.DefaultHalvingSchedule().Reward(height))
+}
+
+// bankMinter mints block rewards through the bank keeper, to the module
+// account and on to the miner
+type bankMinter struct {
+	ctx        sdk.Context
+	bankKeeper types.BankKeeper
+	denom      string
+}
+
+func (m bankMinter) MintReward(miner []byte, amount *big.Int) error {
+	coins := sdk.NewCoins(sdk.NewCoin(m.denom, sdk.NewIntFromBigInt(amount)))
+	if err := m.bankKeeper.MintCoins(m.ctx, types.ModuleName, coins); err != nil {
+		return err
 	}
-	
-	divisor := sdk.NewInt(1 << uint(halvings))
-	return initialReward.Quo(divisor)
+	return m.bankKeeper.SendCoinsFromModuleToAccount(m.ctx, types.ModuleName, miner, coins)
 }
 
 // PreparePublicInputs creates public inputs for zk-proof verification
-func (k Keeper) PreparePublicInputs(header *storetypes.Context, difficulty uint64, miner sdk.AccAddress) []byte {
+func (k Keeper) PreparePublicInputs(ctx sdk.Context, difficulty uint64, miner sdk.AccAddress) []byte {
 	// Combine block hash, difficulty, and miner address
 	data := make([]byte, 0, 64+8+20)
 	
 	// Block hash (32 bytes)
-	blockHash := header.BlockHeader().Hash()
-	data = append(data, blockHash[:]...)
+	data = append(data, ctx.HeaderHash()...)
 	
 	// Previous block hash (32 bytes)
-	prevHash := header.BlockHeader().LastBlockId.Hash
-	data = append(data, prevHash[:]...)
+	data = append(data, ctx.BlockHeader().LastBlockId.Hash...)
 	
 	// Difficulty (8 bytes)
 	diffBytes := make([]byte, 8)
@@ -189,55 +190,52 @@ func (k Keeper) PreparePublicInputs(header *storetypes.Context, difficulty uint6
 
 // GetDifficulty retrieves current mining difficulty
 func (k Keeper) GetDifficulty(ctx sdk.Context) uint64 {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.DifficultyKey))
-	bz := store.Get(types.DifficultyKey)
-	if bz == nil {
-		return 1000000 // Default difficulty
-	}
-	return binary.BigEndian.Uint64(bz)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyKey)
+	return pow.GetDifficulty(store, types.DifficultyKey)
 }
 
 // SetDifficulty sets mining difficulty
 func (k Keeper) SetDifficulty(ctx sdk.Context, difficulty uint64) {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.DifficultyKey))
-	bz := make([]byte, 8)
-	binary.BigEndian.PutUint64(bz, difficulty)
-	store.Set(types.DifficultyKey, bz)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyKey)
+	pow.SetDifficulty(store, types.DifficultyKey, difficulty)
 }
 
-// AdjustDifficulty implements difficulty adjustment algorithm
+// AdjustDifficulty retargets the difficulty every RetargetInterval blocks
+// from the time the interval took, see pow.Retarget. The first call only
+// starts the interval.
 func (k Keeper) AdjustDifficulty(ctx sdk.Context) {
 	currentHeight := ctx.BlockHeight()
 	
 	// Adjust difficulty every 2016 blocks (similar to Bitcoin)
-	if currentHeight%2016 != 0 {
+	if currentHeight%types.RetargetInterval != 0 {
 		return
 	}
 	
-	// Target: 0.5 seconds per block
-	targetTime := int64(500) // milliseconds
-	actualTime := k.GetBlockTime(ctx, currentHeight-2016, currentHeight)
-	
-	currentDifficulty := k.GetDifficulty(ctx)
+	now := ctx.BlockTime().UnixMilli()
+	start, found := k.getRetargetStart(ctx)
+	k.setRetargetStart(ctx, now)
+	if !found {
+		return
+	}
 	
-	// Calculate new difficulty
-	newDifficulty := currentDifficulty * uint64(targetTime) / uint64(actualTime)
+	// Target: 0.5 seconds per block
+	targetTime := int64(500) * types.RetargetInterval // milliseconds
 	
-	// Limit adjustment to 4x increase or 1/4 decrease
-	if newDifficulty > currentDifficulty*4 {
-		newDifficulty = currentDifficulty * 4
-	} else if newDifficulty < currentDifficulty/4 {
-		newDifficulty = currentDifficulty / 4
+	k.SetDifficulty(ctx, pow.Retarget(k.GetDifficulty(ctx), now-start, targetTime))
+}
+
+// getRetargetStart returns the time, in Unix milliseconds, of the last
+// retarget
+func (k Keeper) getRetargetStart(ctx sdk.Context) (int64, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.RetargetStartKey)
+	if len(bz) != 8 {
+		return 0, false
 	}
-	
-	k.SetDifficulty(ctx, newDifficulty)
+	return int64(binary.BigEndian.Uint64(bz)), true
 }
 
-// GetBlockTime calculates average block time between two heights
-func (k Keeper) GetBlockTime(ctx sdk.Context, startHeight, endHeight int64) int64 {
-	// Implementation would query historical block times
-	// For now, return current block time
-	return ctx.BlockTime().Unix()
+func (k Keeper) setRetargetStart(ctx sdk.Context, millis int64) {
+	ctx.KVStore(k.storeKey).Set(types.RetargetStartKey, sdk.Uint64ToBigEndian(uint64(millis)))
 }
 
 // Logger returns the keeper's logger
@@ -0,0 +1,10 @@
+package types
+
+import sdk "github.com/cosmos/cosmos-sdk/types"
+
+// BankKeeper defines the expected bank keeper: block rewards are minted to
+// the module account and sent on to the miner
+type BankKeeper interface {
+	MintCoins(ctx sdk.Context, moduleName string, amt sdk.Coins) error
+	SendCoinsFromModuleToAccount(ctx sdk.Context, senderModule string, recipientAddr sdk.AccAddress, amt sdk.Coins) error
+}
@@ -26,8 +26,15 @@ var (
 	
 	// HalvingIntervalKey is the key for storing halving interval
 	HalvingIntervalKey = []byte("halving_interval")
+	
+	// RetargetStartKey is the key for the time of the last difficulty
+	// retarget, which the next one measures from
+	RetargetStartKey = []byte("retarget_start")
 )
 
+// RetargetInterval is the number of blocks between difficulty retargets
+const RetargetInterval = 2016
+
 func KeyPrefix(p string) []byte {
 	return []byte(p)
 }
\ No newline at end of file
@@ -3,14 +3,29 @@ package utxo
 import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	
+	"shared/diagnostics"
 	"z-blockchain/x/utxo/keeper"
 	"z-blockchain/x/utxo/types"
 )
 
 // BeginBlocker is called at the beginning of every block
 func BeginBlocker(ctx sdk.Context, k keeper.Keeper) {
-	// Adjust Equihash difficulty every 2016 blocks (similar to Zcash)
-	if ctx.BlockHeight()%2016 == 0 && ctx.BlockHeight() > 0 {
+	defer trackPhase(ctx, "begin_block")()
+	
+	// Conflicts met checking proposals are not the block's
+	k.ResetConflicts()
+	
+	// Metrics are of the block's own delivery
+	k.BeginBlockMetrics(ctx)
+	
+	// Retargeting measures the time of the blocks before
+	k.RecordBlockTime(ctx)
+	
+	// Follow hash power every block with LWMA, or adjust the Equihash
+	// difficulty every 2016 blocks (similar to Zcash)
+	if k.GetParams(ctx).DifficultyAlgorithm == types.DifficultyAlgorithmLWMA {
+		k.AdjustDifficultyLWMA(ctx)
+	} else if ctx.BlockHeight()%types.RetargetInterval == 0 && ctx.BlockHeight() > 0 {
 		k.equihashMining.AdjustEquihashDifficulty(ctx)
 	}
 	
@@ -20,12 +35,51 @@ func BeginBlocker(ctx sdk.Context, k keeper.Keeper) {
 
 // EndBlocker is called at the end of every block
 func EndBlocker(ctx sdk.Context, k keeper.Keeper) {
+	defer trackPhase(ctx, "end_block")()
+	
 	// Process any pending UTXO operations
 	k.ProcessPendingUTXOs(ctx)
 	
 	// Update UTXO set statistics
 	k.UpdateUTXOSetStats(ctx)
 	
+	// Penalise unanswered memory audits, then issue the next round
+	done := trackPhase(ctx, "memory_challenges")
+	k.ExpireMemoryChallenges(ctx)
+	k.IssueMemoryChallenges(ctx)
+	done()
+	
+	// Hold the 0.5s block time SLA by lowering the difficulty floor
+	k.WatchBlockTimes(ctx)
+	
+	// Reward the best mining proof of the block, whose miner wins its fees
+	done = trackPhase(ctx, "reward_block_proof")
+	k.RewardBlockProof(ctx)
+	done()
+	
+	// Pay the block's fees to its miner and burn the rest
+	k.SettleBlockFees(ctx)
+	
+	// Deposits not shielded by their transaction stay in the pool
+	k.ClearShieldedDeposits(ctx)
+	
+	// Commit to the UTXO set left by the block
+	done = trackPhase(ctx, "commit_utxo_set")
+	k.CommitUTXOSet(ctx)
+	done()
+	
+	// The note commitment tree root becomes an anchor for later spends
+	k.RecordAnchor(ctx)
+	
+	// Tell wallets which of the block's transactions lost a double spend
+	k.EmitConflicts(ctx)
+	
+	// The next block's mining proofs build on this one
+	k.RecordBlockHash(ctx)
+	
+	// Export the block's metrics to telemetry
+	k.EmitBlockMetrics(ctx)
+	
 	// Emit block processing event
 	ctx.EventManager().EmitEvent(
 		sdk.NewEvent(
@@ -43,8 +97,11 @@ func (k Keeper) AdjustDifficulty(ctx sdk.Context) {
 	// Target: 0.5 seconds per block
 	targetTime := int64(500) // milliseconds
 	
-	// Calculate actual time for last 2016 blocks
-	actualTime := k.GetBlockTimeRange(ctx, currentHeight-2016, currentHeight)
+	// Calculate the average block time of the last 2016 blocks
+	actualTime := k.GetBlockTimeRange(ctx, currentHeight-types.RetargetInterval, currentHeight) / types.RetargetInterval
+	if actualTime < 1 {
+		actualTime = 1
+	}
 	
 	currentDifficulty := k.GetDifficulty(ctx)
 	
@@ -102,16 +159,16 @@ func (k Keeper) UpdateUTXOSetStats(ctx sdk.Context) {
 	k.Logger(ctx).Debug("Updated UTXO set statistics", "block_height", ctx.BlockHeight())
 }
 
-// GetBlockTimeRange calculates average block time between two heights
-func (k Keeper) GetBlockTimeRange(ctx sdk.Context, startHeight, endHeight int64) int64 {
-	// Implementation would query historical block times
-	// For now, return target block time (500ms)
-	return 500
-}
-
 // GetParams returns the module parameters
 func (k Keeper) GetParams(ctx sdk.Context) types.Params {
 	var params types.Params
 	k.paramstore.GetParamSet(ctx, &params)
 	return params
-}
\ No newline at end of file
+}
+
+// trackPhase times a phase of the module in the block of ctx for the
+// diagnostics server; the returned function ends it. The heaviest steps of
+// EndBlocker are timed on their own as well, so an overrun points at one.
+func trackPhase(ctx sdk.Context, phase string) func() {
+	return diagnostics.Default.Track(ctx.BlockHeight(), types.ModuleName, phase)
+}
@@ -0,0 +1,208 @@
+// Package accumulator commits to the live UTXO set with a sparse Merkle tree.
+// Every possible key, the sha256 of an outpoint, has a leaf at depth 256, so
+// the same proof format shows both that an output is unspent and that it is
+// not: a leaf holding the output's hash, or the empty leaf.
+//
+// Only nodes differing from the empty subtree of their depth are stored, so
+// the tree takes space proportional to the set, and an update rewrites the 256
+// nodes on the path of its key.
+package accumulator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Depth is the number of levels below the root, one per key bit
+const Depth = 256
+
+// Domain separation between leaves and inner nodes
+const (
+	leafPrefix  = 0x00
+	innerPrefix = 0x01
+)
+
+var ErrInvalidProof = errors.New("invalid accumulator proof")
+
+// Store persists tree nodes. A prefix store of the module satisfies it.
+type Store interface {
+	Get(key []byte) []byte
+	Set(key, value []byte)
+	Delete(key []byte)
+}
+
+// empty[d] is the root of an empty subtree whose root is at depth d
+var empty = func() [Depth + 1][]byte {
+	var e [Depth + 1][]byte
+	e[Depth] = make([]byte, sha256.Size)
+	for d := Depth - 1; d >= 0; d-- {
+		e[d] = hashInner(e[d+1], e[d+1])
+	}
+	return e
+}()
+
+// EmptyRoot is the root of the tree of the empty set
+func EmptyRoot() []byte {
+	return append([]byte(nil), empty[0]...)
+}
+
+// Key is the tree key of an outpoint
+func Key(txHash string, outputIndex uint32) [32]byte {
+	return sha256.Sum256([]byte(fmt.Sprintf("%s:%d", txHash, outputIndex)))
+}
+
+// ValueHash is the hash a leaf commits to for an output with encoding bz, its
+// protobuf encoding
+func ValueHash(bz []byte) []byte {
+	hash := sha256.Sum256(bz)
+	return hash[:]
+}
+
+// LeafHash is the leaf of key holding an output whose canonical encoding
+// hashes to valueHash
+func LeafHash(key [32]byte, valueHash []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(key[:])
+	h.Write(valueHash)
+	return h.Sum(nil)
+}
+
+func hashInner(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{innerPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Tree is the accumulator over a store
+type Tree struct {
+	store Store
+}
+
+// New opens the tree kept in store
+func New(store Store) *Tree {
+	return &Tree{store: store}
+}
+
+// Root is the commitment to the set
+func (t *Tree) Root() []byte {
+	return t.node(0, [32]byte{})
+}
+
+// Update sets the leaf of key to hold valueHash, or empties it when
+// valueHash is nil
+func (t *Tree) Update(key [32]byte, valueHash []byte) {
+	hash := empty[Depth]
+	if valueHash != nil {
+		hash = LeafHash(key, valueHash)
+	}
+	t.setNode(Depth, key, hash)
+
+	for d := Depth; d > 0; d-- {
+		sibling := t.node(d, flip(key, d-1))
+		if bit(key, d-1) == 0 {
+			hash = hashInner(hash, sibling)
+		} else {
+			hash = hashInner(sibling, hash)
+		}
+		t.setNode(d-1, key, hash)
+	}
+}
+
+// Proof shows the leaf of a key under a root. Siblings lists the siblings on
+// the path from the leaf up that are not empty subtrees; bit i of Bitmap,
+// counted from the first byte's high bit, is set when the sibling at depth
+// 256-i is listed.
+type Proof struct {
+	Bitmap   [Depth / 8]byte
+	Siblings [][]byte
+}
+
+// Prove returns the proof of the leaf of key
+func (t *Tree) Prove(key [32]byte) Proof {
+	var proof Proof
+	for d, i := Depth, 0; d > 0; d, i = d-1, i+1 {
+		sibling := t.node(d, flip(key, d-1))
+		if !bytes.Equal(sibling, empty[d]) {
+			proof.Bitmap[i/8] |= 0x80 >> (i % 8)
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+	}
+	return proof
+}
+
+// Verify checks that under root the leaf of key holds valueHash, or is empty
+// when valueHash is nil, proving the output is not in the set
+func Verify(root []byte, key [32]byte, valueHash []byte, proof Proof) error {
+	hash := empty[Depth]
+	if valueHash != nil {
+		hash = LeafHash(key, valueHash)
+	}
+
+	next := 0
+	for d, i := Depth, 0; d > 0; d, i = d-1, i+1 {
+		sibling := empty[d]
+		if proof.Bitmap[i/8]&(0x80>>(i%8)) != 0 {
+			if next == len(proof.Siblings) {
+				return fmt.Errorf("%w: too few siblings", ErrInvalidProof)
+			}
+			sibling = proof.Siblings[next]
+			next++
+		}
+		if bit(key, d-1) == 0 {
+			hash = hashInner(hash, sibling)
+		} else {
+			hash = hashInner(sibling, hash)
+		}
+	}
+
+	if next != len(proof.Siblings) {
+		return fmt.Errorf("%w: too many siblings", ErrInvalidProof)
+	}
+	if !bytes.Equal(hash, root) {
+		return fmt.Errorf("%w: root mismatch", ErrInvalidProof)
+	}
+	return nil
+}
+
+// node returns the node at depth d on the path of key
+func (t *Tree) node(d int, key [32]byte) []byte {
+	if bz := t.store.Get(nodeKey(d, key)); bz != nil {
+		return bz
+	}
+	return empty[d]
+}
+
+func (t *Tree) setNode(d int, key [32]byte, hash []byte) {
+	if bytes.Equal(hash, empty[d]) {
+		t.store.Delete(nodeKey(d, key))
+		return
+	}
+	t.store.Set(nodeKey(d, key), hash)
+}
+
+// nodeKey is the depth followed by the first d bits of key, the rest zeroed
+func nodeKey(d int, key [32]byte) []byte {
+	nk := make([]byte, 2+len(key))
+	nk[0], nk[1] = byte(d>>8), byte(d)
+	for i := 0; i < d; i++ {
+		if bit(key, i) == 1 {
+			nk[2+i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return nk
+}
+
+// bit returns bit i of key, counted from the first byte's high bit
+func bit(key [32]byte, i int) byte {
+	return (key[i/8] >> (7 - i%8)) & 1
+}
+
+func flip(key [32]byte, i int) [32]byte {
+	key[i/8] ^= 0x80 >> (i % 8)
+	return key
+}
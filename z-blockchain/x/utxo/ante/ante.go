@@ -0,0 +1,120 @@
+// Package ante checks UTXO spends before they enter the mempool. The
+// decorator validates every MsgSendUTXO and MsgShield against the current
+// state at CheckTx, so a missing or spent UTXO, a bad signature or a wrong fee
+// is rejected there instead of in DeliverTx, enforces the fee rate floor of
+// the module params and prioritizes transactions by fee rate. TxLimitDecorator
+// bounds the size, inputs, outputs and proofs of a transaction and charges gas
+// for their verification. MiningBanDecorator verifies mining solutions at
+// CheckTx and bans miners sending invalid ones for a while; the app must
+// place it after the SDK's SigVerificationDecorator so only the signer of a
+// proof can be charged for it. The mempool
+// wrapper rejects a transaction spending an outpoint another pending
+// transaction already spends.
+package ante
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+)
+
+// DefaultMinFeeRate is the minimum fee, in base units per encoded byte of the
+// UTXO messages, a node relays on top of the MinFeeRate param
+var DefaultMinFeeRate = sdk.NewInt(10)
+
+// UTXODecorator validates the UTXO spends of a transaction
+type UTXODecorator struct {
+	keeper     keeper.Keeper
+	minFeeRate sdk.Int
+}
+
+// NewUTXODecorator returns a decorator enforcing minFeeRate at CheckTx. Like
+// minimum gas prices it is node policy; blocks are only checked against the
+// MinFeeRate param.
+func NewUTXODecorator(k keeper.Keeper, minFeeRate sdk.Int) UTXODecorator {
+	return UTXODecorator{keeper: k, minFeeRate: minFeeRate}
+}
+
+func (d UTXODecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	fee := sdk.ZeroInt()
+	size := 0
+	spent := make(map[string]bool)
+	for i, msg := range tx.GetMsgs() {
+		var utxoTx types.UTXOTransaction
+		switch msg := msg.(type) {
+		case *types.MsgSendUTXO:
+			utxoTx = d.keeper.NewUTXOTransaction(ctx, msg)
+			size += msg.Size()
+		case *types.MsgShield:
+			utxoTx = d.keeper.NewShieldTransaction(ctx, msg)
+			size += msg.Size()
+		default:
+			continue
+		}
+
+		// Messages are checked against the state before the transaction, so
+		// spends shared between them are caught here
+		for _, outpoint := range inputOutpoints(utxoTx.Inputs) {
+			if spent[outpoint] {
+				return ctx, sdkerrors.Wrapf(sdkerrors.ErrConflict, "UTXO %s spent by two messages", outpoint)
+			}
+			spent[outpoint] = true
+		}
+
+		msgFee, err := d.keeper.CheckUTXOTransaction(ctx, utxoTx)
+		if err != nil {
+			var conflict *types.ConflictError
+			if errors.As(err, &conflict) && !simulate {
+				d.keeper.RecordConflict(ctx, utxoTx.TxHash, conflict)
+			}
+			return ctx, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "message %d: %s", i, err)
+		}
+		fee = fee.Add(msgFee)
+	}
+	if size == 0 {
+		return next(ctx, tx, simulate)
+	}
+
+	rate := fee.QuoRaw(int64(size))
+	if floor := d.keeper.MinFeeRate(ctx); !simulate && rate.LT(floor) {
+		return ctx, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFee, "fee rate %s per byte is below the consensus minimum of %s", rate, floor)
+	}
+	if ctx.IsCheckTx() && !simulate && rate.LT(d.minFeeRate) {
+		return ctx, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFee, "fee rate %s per byte is below the minimum of %s", rate, d.minFeeRate)
+	}
+
+	priority := int64(math.MaxInt64)
+	if rate.IsInt64() {
+		priority = rate.Int64()
+	}
+	return next(ctx.WithPriority(priority), tx, simulate)
+}
+
+// Outpoints lists the outpoints the UTXO messages of tx spend, as
+// tx_hash:output_index
+func Outpoints(tx sdk.Tx) []string {
+	var outpoints []string
+	for _, msg := range tx.GetMsgs() {
+		switch msg := msg.(type) {
+		case *types.MsgSendUTXO:
+			outpoints = append(outpoints, inputOutpoints(msg.Inputs)...)
+		case *types.MsgShield:
+			outpoints = append(outpoints, inputOutpoints(msg.Inputs)...)
+		}
+	}
+	return outpoints
+}
+
+func inputOutpoints(inputs []*types.TxInput) []string {
+	outpoints := make([]string, len(inputs))
+	for i, input := range inputs {
+		outpoints[i] = fmt.Sprintf("%s:%d", input.PrevTxHash, input.PrevOutputIndex)
+	}
+	return outpoints
+}
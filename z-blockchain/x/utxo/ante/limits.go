@@ -0,0 +1,38 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"z-blockchain/x/utxo/keeper"
+)
+
+// TxLimitDecorator enforces the transaction limits of the module params and
+// charges the verification gas of the utxo messages. Place it ahead of
+// UTXODecorator so an oversized transaction is refused before any of its
+// signatures are checked.
+type TxLimitDecorator struct {
+	keeper keeper.Keeper
+}
+
+// NewTxLimitDecorator returns a decorator enforcing the limits of k's params
+func NewTxLimitDecorator(k keeper.Keeper) TxLimitDecorator {
+	return TxLimitDecorator{keeper: k}
+}
+
+func (d TxLimitDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	var weight keeper.TxWeight
+	for _, msg := range tx.GetMsgs() {
+		weight = weight.Add(keeper.MsgWeight(msg))
+	}
+	if weight == (keeper.TxWeight{}) {
+		return next(ctx, tx, simulate)
+	}
+
+	size := uint64(len(ctx.TxBytes()))
+	if err := d.keeper.CheckTxLimits(ctx, weight, size); err != nil {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxTooLarge, err.Error())
+	}
+	d.keeper.ConsumeVerificationGas(ctx, weight, size)
+	return next(ctx, tx, simulate)
+}
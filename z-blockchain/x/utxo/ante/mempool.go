@@ -0,0 +1,114 @@
+package ante
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+)
+
+var (
+	_ mempool.Mempool    = (*UTXOMempool)(nil)
+	_ keeper.MempoolView = (*UTXOMempool)(nil)
+)
+
+// UTXOMempool wraps the app mempool and refuses a transaction spending an
+// outpoint a transaction it holds already spends; the first spend seen wins.
+// Wrap a priority mempool so transactions are ordered by the fee rate
+// UTXODecorator sets as their priority. It also keeps the pending UTXO
+// transactions for the keeper's balance queries, see keeper.SetMempoolView.
+type UTXOMempool struct {
+	mempool.Mempool
+
+	mu      sync.Mutex
+	spends  map[string]string // Outpoint -> spending transaction, see spender
+	pending map[string]sdk.Tx // Spender -> transaction
+}
+
+// NewUTXOMempool wraps inner
+func NewUTXOMempool(inner mempool.Mempool) *UTXOMempool {
+	return &UTXOMempool{
+		Mempool: inner,
+		spends:  make(map[string]string),
+		pending: make(map[string]sdk.Tx),
+	}
+}
+
+// Insert adds tx unless it conflicts with a pending spend
+func (m *UTXOMempool) Insert(ctx context.Context, tx sdk.Tx) error {
+	outpoints := Outpoints(tx)
+	id := spender(tx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, outpoint := range outpoints {
+		if other, ok := m.spends[outpoint]; ok && other != id {
+			return sdkerrors.Wrapf(sdkerrors.ErrConflict, "UTXO %s is already spent by pending transaction %s", outpoint, other)
+		}
+	}
+	if err := m.Mempool.Insert(ctx, tx); err != nil {
+		return err
+	}
+	for _, outpoint := range outpoints {
+		m.spends[outpoint] = id
+	}
+	if id != "" {
+		m.pending[id] = tx
+	}
+	return nil
+}
+
+// Remove drops tx and releases the outpoints it spends
+func (m *UTXOMempool) Remove(tx sdk.Tx) error {
+	outpoints := Outpoints(tx)
+	id := spender(tx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, outpoint := range outpoints {
+		if m.spends[outpoint] == id {
+			delete(m.spends, outpoint)
+		}
+	}
+	delete(m.pending, id)
+	return m.Mempool.Remove(tx)
+}
+
+// PendingTxs returns the pending transactions with UTXO messages
+func (m *UTXOMempool) PendingTxs() []sdk.Tx {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txs := make([]sdk.Tx, 0, len(m.pending))
+	for _, tx := range m.pending {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// spender identifies a transaction by the hashes of its UTXO messages.
+// Transactions are decoded afresh on removal, so instances cannot be compared.
+func spender(tx sdk.Tx) string {
+	var hashes []string
+	for _, msg := range tx.GetMsgs() {
+		switch msg := msg.(type) {
+		case *types.MsgSendUTXO:
+			hashes = append(hashes, msg.Hash())
+		case *types.MsgShield:
+			hashes = append(hashes, msg.Hash())
+		case *types.MsgSendShielded:
+			hashes = append(hashes, msg.Hash())
+		case *types.MsgDeshield:
+			hashes = append(hashes, msg.Hash())
+		}
+	}
+	return strings.Join(hashes, ",")
+}
@@ -0,0 +1,72 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+)
+
+// MiningBanDecorator keeps proof verification spam out of the mempool. At
+// CheckTx it refuses the mining proofs of banned miners before verifying
+// anything, then verifies the solution of the others, native or merged mined;
+// an invalid one is refused and charged to the miner's ban score. Whether
+// the proof is for the coming block is left to the proposal handlers, as it
+// may change before the proof is included. Blocks are checked as before.
+//
+// The ban score is keyed by the proof's miner address, so the decorator must
+// run after the SDK's SigVerificationDecorator: ahead of it, an unsigned
+// transaction naming a victim as miner would get the victim banned. Only a
+// proof whose miner signed its message is scored.
+type MiningBanDecorator struct {
+	keeper keeper.Keeper
+}
+
+// NewMiningBanDecorator returns a decorator enforcing the ban scores of k
+func NewMiningBanDecorator(k keeper.Keeper) MiningBanDecorator {
+	return MiningBanDecorator{keeper: k}
+}
+
+func (d MiningBanDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	if !ctx.IsCheckTx() {
+		return next(ctx, tx, simulate)
+	}
+
+	for i, msg := range tx.GetMsgs() {
+		msg, ok := msg.(types.MiningProofMsg)
+		if !ok {
+			continue
+		}
+		proof := msg.MiningProof()
+
+		if until, banned := d.keeper.MinerBanned(ctx, proof.MinerAddress); banned {
+			return ctx, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "message %d: miner %s is banned for invalid mining proofs until height %d", i, proof.MinerAddress, until)
+		}
+
+		if err := d.keeper.CheckMiningSolution(ctx, proof); err != nil {
+			// A proof admitted earlier may fail a recheck after a retarget,
+			// which is no fault of the miner. Simulations skip signature
+			// verification, so their miner is not proven.
+			if !simulate && !ctx.IsReCheckTx() && signedBy(msg, proof.MinerAddress) {
+				d.keeper.AddBanScore(ctx, proof.MinerAddress, keeper.InvalidSolutionBanScore)
+			}
+			return ctx, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "message %d: %s", i, err)
+		}
+	}
+	return next(ctx, tx, simulate)
+}
+
+// signedBy reports whether miner is among the signers of msg
+func signedBy(msg sdk.Msg, miner string) bool {
+	addr, err := sdk.AccAddressFromBech32(miner)
+	if err != nil {
+		return false
+	}
+	for _, signer := range msg.GetSigners() {
+		if signer.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package utxo
+
+import (
+	autocliv1 "cosmossdk.io/api/autocli/v1"
+)
+
+// AutoCLIOptions exposes the utxo Query and Msg services to the autocli
+// generated commands
+func (am AppModule) AutoCLIOptions() *autocliv1.ModuleOptions {
+	return &autocliv1.ModuleOptions{
+		Query: &autocliv1.ServiceCommandDescriptor{
+			Service: "zblockchain.utxo.v1.Query",
+			RpcCommandOptions: []*autocliv1.RpcCommandOptions{
+				{
+					RpcMethod: "BlockTimeWatchdog",
+					Use:       "block-time-watchdog",
+					Short:     "Show the current block time window and consecutive SLA breaches",
+				},
+				{
+					RpcMethod: "WatchdogEvents",
+					Use:       "watchdog-events",
+					Short:     "List block time breaches, recoveries and difficulty floor changes",
+				},
+				{
+					RpcMethod: "UTXO",
+					Use:       "utxo [tx-hash] [output-index]",
+					Short:     "Show an output, spent or not",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "tx_hash"},
+						{ProtoField: "output_index"},
+					},
+				},
+				{
+					RpcMethod: "UTXOsByAddress",
+					Use:       "utxos [address]",
+					Short:     "List the unspent outputs of an address",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "address"},
+					},
+				},
+				{
+					RpcMethod: "AddressBalance",
+					Use:       "balance [address]",
+					Short:     "Show the confirmed, immature and pending balance of an address",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "address"},
+					},
+				},
+				{
+					RpcMethod: "Transaction",
+					Use:       "transaction [tx-hash]",
+					Short:     "Show a transparent transaction",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "tx_hash"},
+					},
+				},
+				{
+					RpcMethod: "ShieldedTx",
+					Use:       "shielded-tx [tx-hash]",
+					Short:     "Show a shielded transaction",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "tx_hash"},
+					},
+				},
+				{
+					RpcMethod: "Difficulty",
+					Use:       "difficulty",
+					Short:     "Show the current mining difficulty and its bounds",
+				},
+				{
+					RpcMethod: "DifficultyHistory",
+					Use:       "difficulty-history",
+					Short:     "List the recent difficulty retargets, oldest first",
+				},
+				{
+					RpcMethod: "MergedMiningCommitment",
+					Use:       "merged-mining-commitment [miner] [timestamp-ms]",
+					Short:     "Show the extra data an Altcoinchain header carries to merge mine the next block",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "miner"},
+						{ProtoField: "timestamp"},
+					},
+				},
+				{
+					RpcMethod: "NoteWitness",
+					Use:       "note-witness [commitment]",
+					Short:     "Show the Merkle path of a note commitment to the current anchor",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "commitment"},
+					},
+				},
+				{
+					RpcMethod: "NoteTreeRoot",
+					Use:       "note-tree-root",
+					Short:     "Show the note commitment tree root and its number of leaves",
+				},
+				{
+					RpcMethod: "ShieldedOutputs",
+					Use:       "shielded-outputs [start-height] [end-height]",
+					Short:     "List the shielded outputs and their ciphertexts created in a range of heights (end 0 for the latest)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "start_height"},
+						{ProtoField: "end_height"},
+					},
+				},
+				{
+					RpcMethod: "DataOutputs",
+					Use:       "data-outputs",
+					Short:     "List the data outputs whose data starts with the hex --prefix",
+				},
+				{
+					RpcMethod: "FeeParams",
+					Use:       "fee-params",
+					Short:     "Show the minimum fee rate and dust limit transactions must meet",
+				},
+				{
+					RpcMethod: "Emission",
+					Use:       "emission",
+					Short:     "Show the next block reward, the next halving and the minted supply under the cap",
+				},
+				{
+					RpcMethod: "Treasury",
+					Use:       "treasury",
+					Short:     "Show the funds of the community treasury and the tax that accrued them",
+				},
+				{
+					RpcMethod: "ShieldedPool",
+					Use:       "shielded-pool",
+					Short:     "Show the total value held in the shielded pool",
+				},
+				{
+					RpcMethod: "UTXOSetRoot",
+					Use:       "utxo-set-root [height]",
+					Short:     "Show the UTXO set accumulator root committed at a height (0 for the latest)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "height"},
+					},
+				},
+				{
+					RpcMethod: "UTXOProof",
+					Use:       "utxo-proof [tx-hash] [output-index]",
+					Short:     "Prove an output is or is not in the UTXO set",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "tx_hash"},
+						{ProtoField: "output_index"},
+					},
+				},
+				{
+					RpcMethod: "TransactionProof",
+					Use:       "transaction-proof [tx-hash]",
+					Short:     "Show where a transaction was included and accumulator proofs of its outputs (query at the inclusion height)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "tx_hash"},
+					},
+				},
+				{
+					RpcMethod: "HardwareRegistry",
+					Use:       "hardware-registry",
+					Short:     "List the device classes of the hardware registry",
+				},
+				{
+					RpcMethod: "HardwareClass",
+					Use:       "hardware-class [device-class]",
+					Short:     "Show a device class of the hardware registry",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "device_class"},
+					},
+				},
+				{
+					RpcMethod: "PayoutSplit",
+					Use:       "payout-split [miner]",
+					Short:     "Show the payout addresses a miner's rewards and fees are divided between",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "miner"},
+					},
+				},
+				{
+					RpcMethod: "MinerStats",
+					Use:       "miner-stats [miner]",
+					Short:     "Show the rewards, blocks won and hardware of a miner",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "miner"},
+					},
+				},
+				{
+					RpcMethod: "TopMiners",
+					Use:       "top-miners",
+					Short:     "List miners by total rewards, highest first",
+				},
+				{
+					RpcMethod: "HardwareDistribution",
+					Use:       "hardware-distribution",
+					Short:     "List the miners, blocks won and rewards of each device class",
+				},
+				{
+					RpcMethod: "HardwareFlags",
+					Use:       "hardware-flags",
+					Short:     "List the miners whose memory audits matched an ASIC, pending or upheld by governance",
+				},
+				{
+					RpcMethod: "Params",
+					Use:       "params",
+					Short:     "Show the module parameters",
+				},
+			},
+		},
+		Tx: &autocliv1.ServiceCommandDescriptor{
+			Service: "zblockchain.utxo.v1.Msg",
+			RpcCommandOptions: []*autocliv1.RpcCommandOptions{
+				{
+					RpcMethod: "SendUTXO",
+					Use:       "send-utxo [fee] [lock-time]",
+					Short:     "Spend UTXOs into new outputs (inputs and outputs via --inputs/--outputs JSON)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "fee"},
+						{ProtoField: "lock_time"},
+					},
+				},
+				{
+					RpcMethod: "SendShielded",
+					Use:       "send-shielded [zk-proof] [fee]",
+					Short:     "Submit a shielded transaction",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "zk_proof"},
+						{ProtoField: "fee"},
+					},
+				},
+				{
+					RpcMethod: "Shield",
+					Use:       "shield [value] [fee] [zk-proof]",
+					Short:     "Spend UTXOs into shielded notes (inputs, change outputs and commitments via --inputs/--outputs/--commitments)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "value"},
+						{ProtoField: "fee"},
+						{ProtoField: "zk_proof"},
+					},
+				},
+				{
+					RpcMethod: "Deshield",
+					Use:       "deshield [anchor] [fee] [zk-proof]",
+					Short:     "Spend shielded notes into UTXOs (nullifiers, change commitments and outputs via --nullifiers/--commitments/--outputs)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "anchor"},
+						{ProtoField: "fee"},
+						{ProtoField: "zk_proof"},
+					},
+				},
+				{
+					RpcMethod: "SubmitMiningProof",
+					Use:       "submit-mining-proof [zk-proof] [nonce] [difficulty] [hardware-id] [timestamp-ms] [height] [prev-block-hash]",
+					Short:     "Submit a hardware-accelerated zk-SNARK mining proof",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "zk_proof"},
+						{ProtoField: "nonce"},
+						{ProtoField: "difficulty"},
+						{ProtoField: "hardware_id"},
+						{ProtoField: "timestamp"},
+						{ProtoField: "height"},
+						{ProtoField: "prev_block_hash"},
+					},
+				},
+				{
+					RpcMethod: "SubmitAuxPow",
+					Use:       "submit-aux-pow [aux-pow] [timestamp-ms] [height] [prev-block-hash]",
+					Short:     "Submit an Altcoinchain header merge mining a block (RLP encoded, see merged-mining-commitment)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "aux_pow"},
+						{ProtoField: "timestamp"},
+						{ProtoField: "height"},
+						{ProtoField: "prev_block_hash"},
+					},
+				},
+				{
+					RpcMethod: "RespondMemoryChallenge",
+					Use:       "respond-memory-challenge [root]",
+					Short:     "Answer the open memory audit challenge (sampled blocks via --openings JSON)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "root"},
+					},
+				},
+				{
+					RpcMethod: "RegisterHardware",
+					Use:       "register-hardware [device-class] [eligible]",
+					Short:     "Register or update a device class of the hardware registry (benchmark via --attestation JSON, see z-miner attest)",
+					PositionalArgs: []*autocliv1.PositionalArgDescriptor{
+						{ProtoField: "device_class"},
+						{ProtoField: "eligible"},
+					},
+				},
+				{
+					RpcMethod: "SetPayoutSplit",
+					Use:       "set-payout-split",
+					Short:     "Divide your mining payouts between payout addresses (each --shares '{\"address\":...,\"bps\":...}', summing to 10000 bps; none clears it)",
+				},
+			},
+		},
+	}
+}
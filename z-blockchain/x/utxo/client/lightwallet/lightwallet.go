@@ -0,0 +1,184 @@
+// Package lightwallet serves compact blocks to shielded wallets, in the manner
+// of Zcash's lightwalletd. It reads full blocks from a node over its RPC and
+// keeps only the shielded part of each, so a mobile wallet syncs a fraction
+// of the chain's data and never reveals which notes are its own.
+package lightwallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// PollInterval is how often a following stream checks for a new block
+var PollInterval = 250 * time.Millisecond
+
+var _ types.CompactTxStreamerServer = (*Server)(nil)
+
+// Server implements the CompactTxStreamer service against the node behind a
+// client context
+type Server struct {
+	clientCtx client.Context
+	decode    sdk.TxDecoder
+}
+
+// NewServer returns a server reading blocks from the node of clientCtx
+func NewServer(clientCtx client.Context) *Server {
+	return &Server{clientCtx: clientCtx, decode: clientCtx.TxConfig.TxDecoder()}
+}
+
+// GetLatestBlock returns the height and hash of the latest block
+func (s *Server) GetLatestBlock(ctx context.Context, _ *types.ChainSpec) (*types.BlockID, error) {
+	height, hash, err := s.latest(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &types.BlockID{Height: height, Hash: hash}, nil
+}
+
+// GetBlock returns the compact block at the requested height
+func (s *Server) GetBlock(ctx context.Context, req *types.BlockID) (*types.CompactBlock, error) {
+	if req == nil || req.Height <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	return s.compactBlock(ctx, req.Height)
+}
+
+// GetBlockRange streams the compact blocks of a range, following the chain
+// when the range is open
+func (s *Server) GetBlockRange(req *types.BlockRange, stream types.CompactTxStreamer_GetBlockRangeServer) error {
+	if req == nil || req.Start <= 0 || (req.End != 0 && req.End < req.Start) {
+		return status.Error(codes.InvalidArgument, "invalid range")
+	}
+
+	ctx := stream.Context()
+	for height := req.Start; req.End == 0 || height <= req.End; height++ {
+		if err := s.waitFor(ctx, height, req.End == 0); err != nil {
+			return err
+		}
+		block, err := s.compactBlock(ctx, height)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitFor returns once height is committed. Unless follow is set a height
+// beyond the latest is an error.
+func (s *Server) waitFor(ctx context.Context, height int64, follow bool) error {
+	for {
+		latest, _, err := s.latest(ctx)
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if height <= latest {
+			return nil
+		}
+		if !follow {
+			return status.Errorf(codes.OutOfRange, "height %d is beyond the latest block %d", height, latest)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+func (s *Server) latest(ctx context.Context) (int64, []byte, error) {
+	node, err := s.clientCtx.GetNode()
+	if err != nil {
+		return 0, nil, err
+	}
+	res, err := node.Status(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.SyncInfo.LatestBlockHeight, res.SyncInfo.LatestBlockHash, nil
+}
+
+// compactBlock reads the block at height and reduces it to its shielded
+// transactions
+func (s *Server) compactBlock(ctx context.Context, height int64) (*types.CompactBlock, error) {
+	node, err := s.clientCtx.GetNode()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	block, err := node.Block(ctx, &height)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "block %d: %s", height, err)
+	}
+	results, err := node.BlockResults(ctx, &height)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "block %d results: %s", height, err)
+	}
+
+	// The anchor is the root left by the block, queried at its height
+	queryClient := types.NewQueryClient(s.clientCtx.WithHeight(height))
+	root, err := queryClient.NoteTreeRoot(ctx, &types.QueryNoteTreeRootRequest{})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "note tree root at %d: %s", height, err)
+	}
+
+	compact := &types.CompactBlock{
+		Height:   height,
+		Hash:     block.BlockID.Hash,
+		PrevHash: block.Block.Header.LastBlockID.Hash,
+		Time:     block.Block.Header.Time.Unix(),
+		Anchor:   root.Root,
+	}
+	for i, bz := range block.Block.Data.Txs {
+		if i < len(results.TxsResults) && results.TxsResults[i].Code != 0 {
+			continue
+		}
+		tx, err := s.decode(bz)
+		if err != nil {
+			continue
+		}
+		for _, msg := range tx.GetMsgs() {
+			if c, ok := compactTx(msg); ok {
+				c.Index = uint32(i)
+				compact.Txs = append(compact.Txs, c)
+			}
+		}
+	}
+	return compact, nil
+}
+
+// compactTx keeps the shielded part of a message
+func compactTx(msg sdk.Msg) (*types.CompactTx, bool) {
+	switch msg := msg.(type) {
+	case *types.MsgSendShielded:
+		return &types.CompactTx{
+			TxHash:      msg.Hash(),
+			Nullifiers:  msg.Nullifiers,
+			Commitments: msg.Commitments,
+			Ciphertext:  msg.EncryptedMemo,
+		}, true
+	case *types.MsgShield:
+		return &types.CompactTx{
+			TxHash:      msg.Hash(),
+			Commitments: msg.Commitments,
+			Ciphertext:  msg.EncryptedMemo,
+		}, true
+	case *types.MsgDeshield:
+		return &types.CompactTx{
+			TxHash:      msg.Hash(),
+			Nullifiers:  msg.Nullifiers,
+			Commitments: msg.Commitments,
+			Ciphertext:  msg.EncryptedMemo,
+		}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,42 @@
+package miner
+
+import (
+	"crypto/sha256"
+	"math"
+	"time"
+
+	"z-blockchain/x/utxo/equihash"
+	"z-blockchain/x/utxo/types"
+)
+
+// Attest runs the benchmark a device class is registered with: it solves the
+// benchmark challenge of the class and driver for nonces from 0, keeping the
+// first solution as the proof, and measures the solve rate over them. It
+// goes on past nonces until a solution is found. driver describes the driver
+// and firmware versions; the attestation carries its SHA-256.
+func Attest(p equihash.Params, deviceClass, driver string, nonces uint64) (*types.HardwareAttestation, error) {
+	fingerprint := sha256.Sum256([]byte(driver))
+	attestation := &types.HardwareAttestation{DriverFingerprint: fingerprint[:]}
+
+	start := time.Now()
+	found := 0
+	for nonce := uint64(0); nonce < nonces || attestation.BenchmarkProof == nil; nonce++ {
+		header := types.HardwareBenchmarkHeader(deviceClass, attestation.DriverFingerprint, nonce)
+		solutions, err := equihash.Solve(p, types.GenerateEquihashChallenge(header), solutionsPerNonce)
+		if err != nil {
+			return nil, err
+		}
+		found += len(solutions)
+		if attestation.BenchmarkProof != nil || len(solutions) == 0 {
+			continue
+		}
+
+		minimal, err := equihash.MinimalFromIndices(p, solutions[0])
+		if err != nil {
+			return nil, err
+		}
+		attestation.BenchmarkProof = types.EncodeEquihashProof(nonce, minimal)
+	}
+	attestation.SolutionsPerSecond = uint64(math.Ceil(float64(found) / time.Since(start).Seconds()))
+	return attestation, nil
+}
@@ -0,0 +1,104 @@
+// Package miner mines Z with the reference Equihash solver: it fetches work
+// from a node, solves it on the CPU and builds the MsgSubmitMiningProof that
+// claims the block reward. It serves integration tests and small miners; at
+// the mainnet 144_5 a CPU solve outlasts the 0.5s block, so only devnets and
+// testnets running smaller parameters see its solutions land.
+package miner
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/cosmos/cosmos-sdk/client"
+
+	"z-blockchain/x/utxo/equihash"
+	"z-blockchain/x/utxo/types"
+)
+
+// solutionsPerNonce is the most solutions taken from one nonce; two are
+// expected
+const solutionsPerNonce = 8
+
+// Work is what a solution is found for: the latest block, which the block
+// including the solution follows, and the chain's difficulty, Equihash
+// parameters and bound on solution times
+type Work struct {
+	Height         int64
+	BlockHash      []byte
+	BlockTime      int64 // Unix milliseconds
+	Difficulty     uint64
+	Params         equihash.Params
+	MaxFutureDrift int64 // Milliseconds a solution time may run ahead of the block time
+}
+
+// FetchWork gets the work for the next block from the node behind clientCtx
+func FetchWork(ctx context.Context, clientCtx client.Context) (*Work, error) {
+	node, err := clientCtx.GetNode()
+	if err != nil {
+		return nil, err
+	}
+	block, err := node.Block(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	queryClient := types.NewQueryClient(clientCtx)
+	difficulty, err := queryClient.Difficulty(ctx, &types.QueryDifficultyRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("query difficulty: %w", err)
+	}
+	params, err := queryClient.Params(ctx, &types.QueryParamsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("query params: %w", err)
+	}
+
+	return &Work{
+		Height:         block.Block.Height,
+		BlockHash:      block.BlockID.Hash,
+		BlockTime:      block.Block.Time.UnixMilli(),
+		Difficulty:     difficulty.Difficulty,
+		Params:         params.Params.Equihash(),
+		MaxFutureDrift: params.Params.MaxFutureDriftMillis,
+	}, nil
+}
+
+// Timestamp is the solution time to use at now, in Unix milliseconds: the
+// chain rejects times not after the median of recent blocks, so a clock
+// behind the latest block is moved past it
+func (w *Work) Timestamp(now int64) int64 {
+	if now <= w.BlockTime {
+		return w.BlockTime + 1
+	}
+	return now
+}
+
+// Mine solves the header of miner at nonce and returns the proof of the first
+// solution meeting the difficulty, or nil when the nonce has none. The proof
+// claims the block after the work's, and only counts when included in it.
+func (w *Work) Mine(miner, hardwareID string, timestamp int64, nonce uint64) (*types.MsgSubmitMiningProof, error) {
+	header := types.NewEquihashHeader(w.BlockHash, miner, timestamp, w.Difficulty, nonce)
+	solutions, err := equihash.Solve(w.Params, types.GenerateEquihashChallenge(header), solutionsPerNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	target := types.GetEquihashTarget(header.Bits)
+	for _, indices := range solutions {
+		hash, err := types.EquihashSolutionHash(w.Params, header, indices)
+		if err != nil {
+			return nil, err
+		}
+		if new(big.Int).SetBytes(hash).Cmp(target) > 0 {
+			continue
+		}
+
+		minimal, err := equihash.MinimalFromIndices(w.Params, indices)
+		if err != nil {
+			return nil, err
+		}
+		proof := types.EncodeEquihashProof(nonce, minimal)
+		return types.NewMsgSubmitMiningProof(miner, proof, nil, nonce, w.Difficulty, hardwareID, timestamp, w.Height+1, w.BlockHash), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,142 @@
+// Package spv fetches and checks proofs that a UTXO payment was included in a
+// block, for clients that follow headers only. A payment proof links the
+// header's data hash to the CometBFT transaction carrying the payment, and the
+// UTXO set root committed at that height to each of the payment's outputs.
+package spv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/accumulator"
+	"z-blockchain/x/utxo/types"
+)
+
+// PaymentProof is everything needed to check a payment against a header
+type PaymentProof struct {
+	TxProof cmttypes.TxProof
+	Utxo    types.QueryTransactionProofResponse
+}
+
+// Fetch gets the proof of the UTXO transaction txHash from the node behind
+// clientCtx
+func Fetch(ctx context.Context, clientCtx client.Context, txHash string) (*PaymentProof, error) {
+	queryClient := types.NewQueryClient(clientCtx)
+	res, err := queryClient.TransactionProof(ctx, &types.QueryTransactionProofRequest{TxHash: txHash})
+	if err != nil {
+		return nil, err
+	}
+
+	// The outputs are proved against the root of the inclusion block
+	height := res.Location.Height
+	atHeight := types.NewQueryClient(clientCtx.WithHeight(height))
+	res, err = atHeight.TransactionProof(ctx, &types.QueryTransactionProofRequest{TxHash: txHash})
+	if err != nil {
+		return nil, fmt.Errorf("query at height %d: %w", height, err)
+	}
+
+	node, err := clientCtx.GetNode()
+	if err != nil {
+		return nil, err
+	}
+	resTx, err := node.Tx(ctx, res.Location.BlockTxHash, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentProof{TxProof: resTx.Proof, Utxo: *res}, nil
+}
+
+// Height is the block the payment was included in; Verify needs its data hash
+// and the UTXO set root committed at its end
+func (p *PaymentProof) Height() int64 {
+	return p.Utxo.Location.Height
+}
+
+// Verify checks the proof against dataHash, the data hash of the header at
+// Height, and utxoSetRoot, the UTXO set root committed at Height. The root is
+// in the application state, so it has to come from a source the client trusts
+// such as the bridge oracle, not from the node serving the proof. decode
+// decodes the enclosing CometBFT transaction.
+func (p *PaymentProof) Verify(dataHash, utxoSetRoot []byte, decode sdk.TxDecoder) error {
+	if err := p.TxProof.Validate(dataHash); err != nil {
+		return fmt.Errorf("transaction not in block: %w", err)
+	}
+	if !bytes.Equal(p.TxProof.Leaf(), p.Utxo.Location.BlockTxHash) {
+		return fmt.Errorf("proof is of another transaction")
+	}
+
+	// The UTXO transaction must be one the proved transaction carries
+	tx, err := decode(p.TxProof.Data)
+	if err != nil {
+		return fmt.Errorf("decode transaction: %w", err)
+	}
+	send := carried(tx, p.Utxo.Transaction.TxHash)
+	if send == nil {
+		return fmt.Errorf("transaction does not carry UTXO transaction %s", p.Utxo.Transaction.TxHash)
+	}
+
+	if p.Utxo.Height != p.Height() {
+		return fmt.Errorf("outputs proved at height %d, not the inclusion height %d", p.Utxo.Height, p.Height())
+	}
+	if len(p.Utxo.Outputs) != len(send.Outputs) {
+		return fmt.Errorf("%d output proofs for %d outputs", len(p.Utxo.Outputs), len(send.Outputs))
+	}
+	for i, output := range p.Utxo.Outputs {
+		if output.OutputIndex != uint32(i) {
+			return fmt.Errorf("proof %d is of output %d", i, output.OutputIndex)
+		}
+		if err := verifyOutput(utxoSetRoot, p.Utxo.Transaction.TxHash, output); err != nil {
+			return fmt.Errorf("output %d: %w", output.OutputIndex, err)
+		}
+	}
+	return nil
+}
+
+// OutputValue is the value of an output the proof shows unspent at Height, or
+// false when it shows the output absent
+func (p *PaymentProof) OutputValue(outputIndex uint32) (types.UTXO, bool) {
+	for _, output := range p.Utxo.Outputs {
+		if output.OutputIndex == outputIndex && output.Utxo != nil {
+			return *output.Utxo, true
+		}
+	}
+	return types.UTXO{}, false
+}
+
+// carried returns the UTXO message of tx with hash txHash
+func carried(tx sdk.Tx, txHash string) *types.MsgSendUTXO {
+	for _, msg := range tx.GetMsgs() {
+		if send, ok := msg.(*types.MsgSendUTXO); ok && send.Hash() == txHash {
+			return send
+		}
+	}
+	return nil
+}
+
+func verifyOutput(root []byte, txHash string, output types.OutputProof) error {
+	var proof accumulator.Proof
+	if len(output.Bitmap) != len(proof.Bitmap) {
+		return fmt.Errorf("%w: bitmap of %d bytes", accumulator.ErrInvalidProof, len(output.Bitmap))
+	}
+	copy(proof.Bitmap[:], output.Bitmap)
+	proof.Siblings = output.Siblings
+
+	var valueHash []byte
+	if output.Utxo != nil {
+		if output.Utxo.TxHash != txHash || output.Utxo.OutputIndex != output.OutputIndex {
+			return fmt.Errorf("%w: proves UTXO %s:%d", accumulator.ErrInvalidProof, output.Utxo.TxHash, output.Utxo.OutputIndex)
+		}
+		bz, err := output.Utxo.Marshal()
+		if err != nil {
+			return err
+		}
+		valueHash = accumulator.ValueHash(bz)
+	}
+	return accumulator.Verify(root, accumulator.Key(txHash, output.OutputIndex), valueHash, proof)
+}
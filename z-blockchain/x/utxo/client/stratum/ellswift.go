@@ -0,0 +1,208 @@
+package stratum
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// ElligatorSwift encoding of secp256k1 public keys (BIP 324), the key format
+// of the Stratum V2 Noise handshake: 64 bytes indistinguishable from random
+// that decode to an x coordinate. Only handshakes use it, so the field
+// arithmetic is plain big.Int.
+
+// ellswiftSize is the size of an encoded public key
+const ellswiftSize = 64
+
+var (
+	fieldP = mustBig("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	// minus3Sqrt is the square root of -3 that fieldSqrt returns, as in the
+	// BIP 324 reference
+	minus3Sqrt = fieldSqrt(new(big.Int).Sub(fieldP, big.NewInt(3)))
+	curveB     = big.NewInt(7)
+)
+
+func mustBig(h string) *big.Int {
+	v, ok := new(big.Int).SetString(h, 16)
+	if !ok {
+		panic("bad field constant " + h)
+	}
+	return v
+}
+
+func fieldMod(v *big.Int) *big.Int {
+	return v.Mod(v, fieldP)
+}
+
+func fieldAdd(a, b *big.Int) *big.Int { return fieldMod(new(big.Int).Add(a, b)) }
+func fieldSub(a, b *big.Int) *big.Int { return fieldMod(new(big.Int).Sub(a, b)) }
+func fieldMul(a, b *big.Int) *big.Int { return fieldMod(new(big.Int).Mul(a, b)) }
+func fieldNeg(a *big.Int) *big.Int    { return fieldMod(new(big.Int).Neg(a)) }
+
+func fieldDiv(a, b *big.Int) *big.Int {
+	return fieldMul(a, new(big.Int).ModInverse(b, fieldP))
+}
+
+// fieldSqrt is a^((p+1)/4), or nil when a is not a square
+func fieldSqrt(a *big.Int) *big.Int {
+	e := new(big.Int).Add(fieldP, big.NewInt(1))
+	e.Rsh(e, 2)
+	r := new(big.Int).Exp(a, e, fieldP)
+	if fieldMul(r, r).Cmp(fieldMod(new(big.Int).Set(a))) != 0 {
+		return nil
+	}
+	return r
+}
+
+// curveRHS is x^3 + 7
+func curveRHS(x *big.Int) *big.Int {
+	return fieldAdd(fieldMul(fieldMul(x, x), x), curveB)
+}
+
+func isCurveX(x *big.Int) bool {
+	return fieldSqrt(curveRHS(x)) != nil
+}
+
+// xswiftec maps any field elements u, t to an x coordinate on the curve
+func xswiftec(u, t *big.Int) *big.Int {
+	if u.Sign() == 0 {
+		u = big.NewInt(1)
+	}
+	if t.Sign() == 0 {
+		t = big.NewInt(1)
+	}
+	if fieldAdd(curveRHS(u), fieldMul(t, t)).Sign() == 0 {
+		t = fieldAdd(t, t)
+	}
+	x := fieldDiv(fieldSub(curveRHS(u), fieldMul(t, t)), fieldAdd(t, t))
+	y := fieldDiv(fieldAdd(x, t), fieldMul(minus3Sqrt, u))
+	two := big.NewInt(2)
+	for _, c := range []*big.Int{
+		fieldAdd(u, fieldMul(big.NewInt(4), fieldMul(y, y))),
+		fieldDiv(fieldSub(fieldNeg(fieldDiv(x, y)), u), two),
+		fieldDiv(fieldSub(fieldDiv(x, y), u), two),
+	} {
+		if isCurveX(c) {
+			return c
+		}
+	}
+	// Unreachable: one of the three is on the curve for any u, t
+	panic("xswiftec: no candidate on the curve")
+}
+
+// xswiftecInv finds t with xswiftec(u, t) = x for one of the 8 cases of the
+// inverse, or nil when that case has none
+func xswiftecInv(x, u *big.Int, c int) *big.Int {
+	var v, s *big.Int
+	two := big.NewInt(2)
+	if c&2 == 0 {
+		if isCurveX(fieldSub(fieldNeg(x), u)) {
+			return nil
+		}
+		v = x
+		s = fieldNeg(fieldDiv(curveRHS(u), fieldAdd(fieldAdd(fieldMul(u, u), fieldMul(u, v)), fieldMul(v, v))))
+	} else {
+		s = fieldSub(x, u)
+		if s.Sign() == 0 {
+			return nil
+		}
+		uu := fieldMul(u, u)
+		r := fieldSqrt(fieldMul(fieldNeg(s), fieldAdd(fieldMul(big.NewInt(4), curveRHS(u)), fieldMul(big.NewInt(3), fieldMul(s, uu)))))
+		if r == nil {
+			return nil
+		}
+		if c&1 != 0 {
+			if r.Sign() == 0 {
+				return nil
+			}
+			r = fieldNeg(r)
+		}
+		v = fieldDiv(fieldSub(fieldDiv(r, s), u), two)
+	}
+	w := fieldSqrt(s)
+	if w == nil {
+		return nil
+	}
+
+	one := big.NewInt(1)
+	var m *big.Int
+	if c&1 == 0 {
+		m = fieldSub(one, minus3Sqrt)
+	} else {
+		m = fieldAdd(one, minus3Sqrt)
+	}
+	t := fieldMul(w, fieldAdd(fieldDiv(fieldMul(u, m), two), v))
+	if (c>>2)&1 == c&1 {
+		t = fieldNeg(t)
+	}
+	return t
+}
+
+// ellswiftEncode encodes the public key of priv as random looking u || t
+func ellswiftEncode(priv *btcec.PrivateKey) [ellswiftSize]byte {
+	x := new(big.Int).SetBytes(priv.PubKey().SerializeCompressed()[1:])
+	var enc [ellswiftSize]byte
+	for {
+		if _, err := rand.Read(enc[:33]); err != nil {
+			panic(err)
+		}
+		// xswiftec reads u = 0 as 1, so it has no inverse
+		u := fieldMod(new(big.Int).SetBytes(enc[:32]))
+		if u.Sign() == 0 {
+			continue
+		}
+		if t := xswiftecInv(x, u, int(enc[32]&7)); t != nil {
+			t.FillBytes(enc[32:])
+			return enc
+		}
+	}
+}
+
+// ellswiftDecode returns the x coordinate an encoding maps to
+func ellswiftDecode(enc [ellswiftSize]byte) *big.Int {
+	u := fieldMod(new(big.Int).SetBytes(enc[:32]))
+	t := fieldMod(new(big.Int).SetBytes(enc[32:]))
+	return xswiftec(u, t)
+}
+
+// ellswiftECDH is the BIP 324 shared secret of priv, whose encoding is ours,
+// and the peer's encoded key, ordered initiator first
+func ellswiftECDH(priv *btcec.PrivateKey, ours, theirs [ellswiftSize]byte, initiator bool) ([32]byte, error) {
+	var compressed [33]byte
+	compressed[0] = 0x02
+	ellswiftDecode(theirs).FillBytes(compressed[1:])
+	pub, err := btcec.ParsePubKey(compressed[:])
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	var point, shared btcec.JacobianPoint
+	pub.AsJacobian(&point)
+	// The curve has prime order, so a valid key never yields infinity
+	btcec.ScalarMultNonConst(&priv.Key, &point, &shared)
+	shared.ToAffine()
+	var x [32]byte
+	shared.X.PutBytes(&x)
+
+	a, b := ours, theirs
+	if !initiator {
+		a, b = theirs, ours
+	}
+	return taggedHash("bip324_ellswift_xonly_ecdh", a[:], b[:], x[:]), nil
+}
+
+// taggedHash is the BIP 340 tagged hash of the concatenated data
+func taggedHash(tag string, data ...[]byte) [32]byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, d := range data {
+		h.Write(d)
+	}
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
@@ -0,0 +1,173 @@
+package stratum
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"z-blockchain/x/utxo/client/miner"
+	"z-blockchain/x/utxo/equihash"
+	"z-blockchain/x/utxo/types"
+)
+
+// Errors of share submission, with their stratum codes
+var (
+	ErrJobNotFound    = &Error{Code: 21, Message: "job not found"}
+	ErrDuplicateShare = &Error{Code: 22, Message: "duplicate share"}
+	ErrLowDifficulty  = &Error{Code: 23, Message: "low difficulty share"}
+	ErrUnauthorized   = &Error{Code: 24, Message: "unauthorized worker"}
+	ErrNotSubscribed  = &Error{Code: 25, Message: "not subscribed"}
+	ErrInvalidShare   = &Error{Code: 20, Message: "invalid share"}
+)
+
+// Error is a stratum error, sent as [code, message, null]
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// invalid is ErrInvalidShare with a reason
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidShare, fmt.Sprintf(format, args...))
+}
+
+// job is the work of one block template: the Equihash header committing to
+// miner, less the nonce miners fill in. Jobs follow each new block for the
+// pool's address and, for Stratum V2, the templates miners declare.
+type job struct {
+	id        uint64
+	work      *miner.Work
+	miner     string
+	timestamp int64 // Unix milliseconds, the solution time submitted
+	header    *types.EquihashHeader
+	seen      map[string]struct{} // Shares accepted, by nonce and solution
+}
+
+func newJob(id uint64, work *miner.Work, miner string, timestamp int64) *job {
+	return &job{
+		id:        id,
+		work:      work,
+		miner:     miner,
+		timestamp: timestamp,
+		header:    types.NewEquihashHeader(work.BlockHash, miner, timestamp, work.Difficulty, 0),
+		seen:      make(map[string]struct{}),
+	}
+}
+
+// notifyParams are the mining.notify parameters, in the order of ZIP 301:
+// job id, version, previous block hash, merkle root, reserved, time, bits and
+// clean jobs, the integers little endian hex as the header serializes them.
+// zChain headers have no reserved field, sent empty, and an 8 byte nonce:
+// the 4 byte extranonce of the connection followed by the miner's 4 bytes.
+func (j *job) notifyParams(clean bool) []interface{} {
+	le32 := func(v uint32) string {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], v)
+		return hex.EncodeToString(b[:])
+	}
+	return []interface{}{
+		strconv.FormatUint(j.id, 16),
+		le32(j.header.Version),
+		hex.EncodeToString(j.header.PrevBlockHash),
+		hex.EncodeToString(j.header.MerkleRoot),
+		"",
+		le32(j.header.Timestamp),
+		le32(j.header.Bits),
+		clean,
+	}
+}
+
+// share is a checked solution
+type share struct {
+	nonce   uint64
+	indices []uint32
+	minimal []byte
+	hash    *big.Int
+	block   bool // Meets the network target as well as the share target
+}
+
+// parseSubmit decodes the hex fields of a mining.submit for the connection's
+// nonce1
+func parseSubmit(nonce1 []byte, ntimeHex, nonce2Hex, solutionHex string) (uint64, uint32, []byte, error) {
+	ntime, err := hex.DecodeString(ntimeHex)
+	if err != nil || len(ntime) != 4 {
+		return 0, 0, nil, invalid("ntime must be 4 bytes")
+	}
+	nonce2, err := hex.DecodeString(nonce2Hex)
+	if err != nil || len(nonce1)+len(nonce2) != 8 {
+		return 0, 0, nil, invalid("nonce2 must be %d bytes", 8-len(nonce1))
+	}
+	solution, err := hex.DecodeString(solutionHex)
+	if err != nil {
+		return 0, 0, nil, invalid("solution is not hex")
+	}
+	return headerNonce(nonce1, nonce2), binary.LittleEndian.Uint32(ntime), solution, nil
+}
+
+// headerNonce is the header nonce of an extranonce and a miner's nonce
+func headerNonce(nonce1, nonce2 []byte) uint64 {
+	return binary.LittleEndian.Uint64(append(append([]byte(nil), nonce1...), nonce2...))
+}
+
+// check verifies a solution at nonce with time ntime against the job and the
+// share target
+func (j *job) check(nonce uint64, ntime uint32, solution []byte, shareTarget *big.Int) (*share, error) {
+	if ntime != j.header.Timestamp {
+		return nil, invalid("ntime must be the job's")
+	}
+
+	p := j.work.Params
+	minimal, err := decodeSolution(p, solution)
+	if err != nil {
+		return nil, err
+	}
+	indices, err := equihash.IndicesFromMinimal(p, minimal)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	header := *j.header
+	header.Nonce = nonce
+	if err := equihash.VerifyIndices(p, types.GenerateEquihashChallenge(&header), indices); err != nil {
+		return nil, invalid("%v", err)
+	}
+	hash, err := types.EquihashSolutionHash(p, &header, indices)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	s := &share{nonce: nonce, indices: indices, minimal: minimal, hash: new(big.Int).SetBytes(hash)}
+	if s.hash.Cmp(shareTarget) > 0 {
+		return nil, ErrLowDifficulty
+	}
+	s.block = s.hash.Cmp(types.GetEquihashTarget(header.Bits)) <= 0
+	return s, nil
+}
+
+// decodeSolution takes a minimally encoded solution, with or without the
+// compact size prefix Zcash miners send
+func decodeSolution(p equihash.Params, solution []byte) ([]byte, error) {
+	size := p.SolutionSize()
+	switch {
+	case len(solution) == size:
+		return solution, nil
+	case size < 0xfd && len(solution) == size+1 && int(solution[0]) == size:
+		return solution[1:], nil
+	case len(solution) == size+3 && solution[0] == 0xfd && int(binary.LittleEndian.Uint16(solution[1:])) == size:
+		return solution[3:], nil
+	}
+	return nil, invalid("solution must be %d bytes", size)
+}
+
+// proof is the MsgSubmitMiningProof claiming the block of a share, which the
+// job's miner must sign
+func (j *job) proof(hardwareID string, s *share) *types.MsgSubmitMiningProof {
+	zkProof := types.EncodeEquihashProof(s.nonce, s.minimal)
+	return types.NewMsgSubmitMiningProof(j.miner, zkProof, nil, s.nonce, j.work.Difficulty, hardwareID, j.timestamp, j.work.Height+1, j.work.BlockHash)
+}
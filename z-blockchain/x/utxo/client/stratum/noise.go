@@ -0,0 +1,226 @@
+package stratum
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// The Noise NX handshake of Stratum V2: the miner, which has no static key,
+// learns the server's static key and a certificate for it signed by the
+// pool's authority key, which miners pin. Afterwards every frame is
+// encrypted with ChaCha20-Poly1305, one key each way.
+
+const noiseProtocolName = "Noise_NX_Secp256k1+EllSwift_ChaChaPoly_SHA256"
+
+const (
+	macSize         = chacha20poly1305.Overhead
+	certificateSize = 2 + 4 + 4 + schnorr.SignatureSize
+	// handshakeReplySize is the responder's e, encrypted s and encrypted
+	// certificate
+	handshakeReplySize = ellswiftSize + ellswiftSize + macSize + certificateSize + macSize
+	// certificateValidity is how long the certificate given to a miner is
+	// valid
+	certificateValidity = time.Hour
+)
+
+// cipherState encrypts one direction of a connection
+type cipherState struct {
+	aead cipher.AEAD
+	n    uint64
+}
+
+func newCipherState(k [32]byte) *cipherState {
+	aead, err := chacha20poly1305.New(k[:])
+	if err != nil {
+		panic(err)
+	}
+	return &cipherState{aead: aead}
+}
+
+// nonce is 32 zero bits followed by the little endian counter
+func (c *cipherState) nonce() []byte {
+	var n [chacha20poly1305.NonceSize]byte
+	binary.LittleEndian.PutUint64(n[4:], c.n)
+	c.n++
+	return n[:]
+}
+
+func (c *cipherState) encrypt(ad, plaintext []byte) []byte {
+	return c.aead.Seal(nil, c.nonce(), plaintext, ad)
+}
+
+func (c *cipherState) decrypt(ad, ciphertext []byte) ([]byte, error) {
+	return c.aead.Open(nil, c.nonce(), ciphertext, ad)
+}
+
+// symmetricState is the chaining key, handshake hash and key of a handshake
+type symmetricState struct {
+	ck, h [32]byte
+	cs    *cipherState
+}
+
+func newSymmetricState() *symmetricState {
+	s := &symmetricState{ck: sha256.Sum256([]byte(noiseProtocolName))}
+	// The empty prologue
+	s.h = sha256.Sum256(s.ck[:])
+	return s
+}
+
+func (s *symmetricState) mixHash(data []byte) {
+	s.h = sha256.Sum256(append(s.h[:], data...))
+}
+
+func (s *symmetricState) mixKey(ikm [32]byte) {
+	var k [32]byte
+	s.ck, k = hkdf2(s.ck, ikm[:])
+	s.cs = newCipherState(k)
+}
+
+func (s *symmetricState) encryptAndHash(plaintext []byte) []byte {
+	if s.cs == nil {
+		s.mixHash(plaintext)
+		return plaintext
+	}
+	ciphertext := s.cs.encrypt(s.h[:], plaintext)
+	s.mixHash(ciphertext)
+	return ciphertext
+}
+
+func (s *symmetricState) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	if s.cs == nil {
+		s.mixHash(ciphertext)
+		return ciphertext, nil
+	}
+	plaintext, err := s.cs.decrypt(s.h[:], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	s.mixHash(ciphertext)
+	return plaintext, nil
+}
+
+// split returns the initiator's sending and the responder's sending ciphers
+func (s *symmetricState) split() (*cipherState, *cipherState) {
+	k1, k2 := hkdf2(s.ck, nil)
+	return newCipherState(k1), newCipherState(k2)
+}
+
+// hkdf2 is the two output HKDF of Noise over HMAC-SHA256
+func hkdf2(ck [32]byte, ikm []byte) (out1, out2 [32]byte) {
+	mac := func(key, data []byte) (out [32]byte) {
+		h := hmac.New(sha256.New, key)
+		h.Write(data)
+		h.Sum(out[:0])
+		return out
+	}
+	temp := mac(ck[:], ikm)
+	out1 = mac(temp[:], []byte{0x01})
+	out2 = mac(temp[:], append(out1[:], 0x02))
+	return out1, out2
+}
+
+// certificate is the authority's signature of the server's static key for a
+// validity period, Unix seconds
+type certificate struct {
+	version       uint16
+	validFrom     uint32
+	notValidAfter uint32
+	signature     [schnorr.SignatureSize]byte
+}
+
+func (c *certificate) digest(static *big.Int) []byte {
+	var msg [2 + 4 + 4 + 32]byte
+	binary.LittleEndian.PutUint16(msg[0:], c.version)
+	binary.LittleEndian.PutUint32(msg[2:], c.validFrom)
+	binary.LittleEndian.PutUint32(msg[6:], c.notValidAfter)
+	static.FillBytes(msg[10:])
+	h := sha256.Sum256(msg[:])
+	return h[:]
+}
+
+func signCertificate(authority *btcec.PrivateKey, static *big.Int, now time.Time) (*certificate, error) {
+	c := &certificate{
+		validFrom:     uint32(now.Unix()),
+		notValidAfter: uint32(now.Add(certificateValidity).Unix()),
+	}
+	sig, err := schnorr.Sign(authority, c.digest(static))
+	if err != nil {
+		return nil, err
+	}
+	copy(c.signature[:], sig.Serialize())
+	return c, nil
+}
+
+func (c *certificate) bytes() []byte {
+	b := make([]byte, 0, certificateSize)
+	b = binary.LittleEndian.AppendUint16(b, c.version)
+	b = binary.LittleEndian.AppendUint32(b, c.validFrom)
+	b = binary.LittleEndian.AppendUint32(b, c.notValidAfter)
+	return append(b, c.signature[:]...)
+}
+
+// noiseConn is a connection after the handshake
+type noiseConn struct {
+	rw         io.ReadWriter
+	send, recv *cipherState
+}
+
+// noiseRespond runs the server side of the handshake with static key s,
+// certified by authority
+func noiseRespond(rw io.ReadWriter, s, authority *btcec.PrivateKey, now time.Time) (*noiseConn, error) {
+	ss := newSymmetricState()
+
+	// -> e
+	var re [ellswiftSize]byte
+	if _, err := io.ReadFull(rw, re[:]); err != nil {
+		return nil, err
+	}
+	ss.mixHash(re[:])
+	ss.encryptAndHash(nil)
+
+	// <- e, ee, s, es, certificate
+	e, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	eEnc := ellswiftEncode(e)
+	reply := make([]byte, 0, handshakeReplySize)
+	reply = append(reply, eEnc[:]...)
+	ss.mixHash(eEnc[:])
+
+	ee, err := ellswiftECDH(e, eEnc, re, false)
+	if err != nil {
+		return nil, err
+	}
+	ss.mixKey(ee)
+
+	sEnc := ellswiftEncode(s)
+	reply = append(reply, ss.encryptAndHash(sEnc[:])...)
+
+	es, err := ellswiftECDH(s, sEnc, re, false)
+	if err != nil {
+		return nil, err
+	}
+	ss.mixKey(es)
+
+	cert, err := signCertificate(authority, ellswiftDecode(sEnc), now)
+	if err != nil {
+		return nil, err
+	}
+	reply = append(reply, ss.encryptAndHash(cert.bytes())...)
+	if _, err := rw.Write(reply); err != nil {
+		return nil, err
+	}
+
+	c1, c2 := ss.split()
+	return &noiseConn{rw: rw, send: c2, recv: c1}, nil
+}
@@ -0,0 +1,433 @@
+// Package stratum bridges external GPU miners to the chain over stratum v1,
+// in the Equihash dialect of ZIP 301. The server turns each new block into a
+// job, checks the shares miners submit against the pool difficulty, and
+// submits the shares that meet the network difficulty as MsgSubmitMiningProof
+// from the pool's address, which the header commits to and which receives the
+// reward.
+//
+// The protocol is line delimited JSON-RPC over TCP:
+//
+//	mining.subscribe  -> [null, extranonce1]
+//	mining.authorize  [worker, password] -> true
+//	mining.submit     [worker, job id, ntime, nonce2, solution] -> true
+//
+// with mining.set_target and mining.notify pushed to subscribed connections.
+//
+// ServeV2 serves the same jobs over Stratum V2, encrypted, and lets miners
+// declare their own templates; see sv2.go for the dialect.
+package stratum
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"cosmossdk.io/log"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/cosmos/cosmos-sdk/client"
+
+	"z-blockchain/x/utxo/client/miner"
+	"z-blockchain/x/utxo/types"
+)
+
+// PollInterval is how often the server checks for a new block
+var PollInterval = 250 * time.Millisecond
+
+// maxLineBytes bounds a request line; a 200_9 solution is 2.7 KB of hex
+const maxLineBytes = 16 * 1024
+
+// staleJobs is how many jobs back a share is still checked, so shares in
+// flight when a block lands are not all rejected; only the latest job's can
+// claim a block, the others' are submitted as uncles
+const staleJobs = 4
+
+// Submitter sends the proof of a block found by the pool
+type Submitter func(ctx context.Context, msg *types.MsgSubmitMiningProof) error
+
+// Config configures a server
+type Config struct {
+	Pool           string // Address the proofs are submitted from and the header commits to
+	HardwareID     string // Submitted with every proof; must be an eligible device class
+	PoolDifficulty uint64 // Difficulty of a share, the starting one under vardiff
+
+	// ShareInterval is the time between shares vardiff aims every
+	// connection at; zero keeps PoolDifficulty
+	ShareInterval time.Duration
+
+	// AuthorityKey certifies the server's Stratum V2 key; miners pin its
+	// public key. ServeV2 requires it.
+	AuthorityKey *btcec.PrivateKey
+	// JobDeclaration lets Stratum V2 miners mine templates they declare,
+	// paying their own address, instead of the pool's
+	JobDeclaration bool
+}
+
+// Server is a stratum server for the node behind a client context
+type Server struct {
+	clientCtx client.Context
+	config    Config
+	submit    Submitter
+	logger    log.Logger
+
+	static    *btcec.PrivateKey // Stratum V2 static key
+	startOnce sync.Once
+
+	mu           sync.Mutex
+	jobs         []*job // Latest last
+	nextJob      uint64
+	nextNonce    uint32
+	conns        map[jobNotifier]struct{}
+	declarations map[string]*declaration // By job declaration token
+}
+
+// jobNotifier is a connection that is sent new jobs
+type jobNotifier interface {
+	notifyJob(j *job)
+}
+
+// NewServer returns a server submitting blocks through submit
+func NewServer(clientCtx client.Context, config Config, submit Submitter, logger log.Logger) (*Server, error) {
+	if config.Pool == "" || config.HardwareID == "" {
+		return nil, errors.New("pool address and hardware id are required")
+	}
+	if config.PoolDifficulty == 0 {
+		return nil, errors.New("pool difficulty must be positive")
+	}
+	static, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		clientCtx:    clientCtx,
+		config:       config,
+		submit:       submit,
+		logger:       logger,
+		static:       static,
+		conns:        make(map[jobNotifier]struct{}),
+		declarations: make(map[string]*declaration),
+	}, nil
+}
+
+// Serve accepts stratum v1 miners on listener and follows the chain until
+// ctx is done
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	return s.accept(ctx, listener, s.handle)
+}
+
+func (s *Server) accept(ctx context.Context, listener net.Listener, handle func(context.Context, net.Conn)) error {
+	s.startOnce.Do(func() { go s.follow(ctx) })
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go handle(ctx, netConn)
+	}
+}
+
+// follow makes a job of every new block and notifies the miners
+func (s *Server) follow(ctx context.Context) {
+	var height int64
+	for {
+		work, err := miner.FetchWork(ctx, s.clientCtx)
+		switch {
+		case err != nil:
+			s.logger.Error("fetch work", "err", err)
+		case work.Height != height:
+			height = work.Height
+			s.newJob(work)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+func (s *Server) newJob(work *miner.Work) {
+	s.mu.Lock()
+	s.nextJob++
+	j := newJob(s.nextJob, work, s.config.Pool, work.Timestamp(time.Now().UnixMilli()))
+	s.jobs = append(s.jobs, j)
+	if len(s.jobs) > staleJobs {
+		s.jobs = s.jobs[len(s.jobs)-staleJobs:]
+	}
+	for token, d := range s.declarations {
+		if d.height < work.Height-staleJobs {
+			delete(s.declarations, token)
+		}
+	}
+	conns := make([]jobNotifier, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.notifyJob(j)
+	}
+}
+
+func (s *Server) job(id uint64) (*job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, j := range s.jobs {
+		if j.id == id {
+			return j, i == len(s.jobs)-1
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) latestJob() *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return nil
+	}
+	return s.jobs[len(s.jobs)-1]
+}
+
+// conn is a miner's connection
+type conn struct {
+	netConn net.Conn
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	nonce1     []byte
+	subscribed bool
+	workers    map[string]bool
+	diff       *vardiff
+	retargeted bool // Difficulty changed by the last share
+}
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+type notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+func (c *conn) send(v interface{}) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.netConn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_ = c.enc.Encode(v)
+}
+
+func (c *conn) notify(method string, params []interface{}) {
+	c.send(notification{Method: method, Params: params})
+}
+
+func (c *conn) notifyJob(j *job) {
+	if c.diff.newJob(time.Now()) {
+		c.notifyTarget()
+	}
+	c.notify("mining.notify", j.notifyParams(true))
+}
+
+func (c *conn) notifyTarget() {
+	_, target := c.diff.current()
+	c.notify("mining.set_target", []interface{}{fmt.Sprintf("%064x", target)})
+}
+
+// allocNonce1 is the extranonce of a new connection or channel
+func (s *Server) allocNonce1() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextNonce++
+	return binary.BigEndian.AppendUint32(nil, s.nextNonce)
+}
+
+func (s *Server) register(c jobNotifier) {
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *Server) unregister(c jobNotifier) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+func (s *Server) handle(ctx context.Context, netConn net.Conn) {
+	defer netConn.Close()
+
+	c := &conn{
+		netConn: netConn,
+		enc:     json.NewEncoder(netConn),
+		nonce1:  s.allocNonce1(),
+		workers: make(map[string]bool),
+		diff:    newVardiff(s.config.PoolDifficulty, 1, s.config.ShareInterval, time.Now()),
+	}
+	defer s.unregister(c)
+
+	scanner := bufio.NewScanner(netConn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineBytes)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return
+		}
+		result, err := s.dispatch(ctx, c, req)
+		res := response{ID: req.ID, Result: result}
+		if err != nil {
+			res.Result = nil
+			var serr *Error
+			if !errors.As(err, &serr) {
+				serr = &Error{Code: 20, Message: err.Error()}
+			}
+			res.Error = []interface{}{serr.Code, err.Error(), nil}
+		}
+		c.send(res)
+
+		// Work follows the answer to the request that enables it
+		if req.Method == "mining.authorize" && err == nil {
+			c.notifyTarget()
+			if j := s.latestJob(); j != nil {
+				c.notifyJob(j)
+			}
+		}
+		// A new target takes effect with the next job, so the latest is
+		// sent again; shares at the old target count until a new block
+		if c.retargeted {
+			c.retargeted = false
+			c.notifyTarget()
+			if j := s.latestJob(); j != nil {
+				c.notify("mining.notify", j.notifyParams(false))
+			}
+		}
+	}
+}
+
+func (s *Server) dispatch(ctx context.Context, c *conn, req request) (interface{}, error) {
+	str := func(i int) string {
+		var v string
+		if i < len(req.Params) {
+			_ = json.Unmarshal(req.Params[i], &v)
+		}
+		return v
+	}
+
+	switch req.Method {
+	case "mining.subscribe":
+		c.subscribed = true
+		s.register(c)
+		return []interface{}{nil, hex.EncodeToString(c.nonce1)}, nil
+
+	case "mining.authorize":
+		if !c.subscribed {
+			return nil, ErrNotSubscribed
+		}
+		// Workers name themselves for the pool's accounting; the reward
+		// goes to the pool address
+		worker := str(0)
+		if worker == "" {
+			return nil, ErrUnauthorized
+		}
+		c.workers[worker] = true
+		return true, nil
+
+	case "mining.submit":
+		worker := str(0)
+		if !c.workers[worker] {
+			return nil, ErrUnauthorized
+		}
+		return s.submitShare(ctx, c, worker, str(1), str(2), str(3), str(4))
+
+	case "mining.extranonce.subscribe":
+		return false, nil
+	}
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
+
+// submitShare checks a mining.submit share
+func (s *Server) submitShare(ctx context.Context, c *conn, worker, jobID, ntimeHex, nonce2Hex, solutionHex string) (interface{}, error) {
+	id, err := strconv.ParseUint(jobID, 16, 64)
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+	j, latest := s.job(id)
+	if j == nil {
+		return nil, ErrJobNotFound
+	}
+	nonce, ntime, solution, err := parseSubmit(c.nonce1, ntimeHex, nonce2Hex, solutionHex)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := j.check(nonce, ntime, solution, c.diff.shareTarget())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.acceptShare(ctx, j, latest, worker, sh); err != nil {
+		return nil, err
+	}
+	c.retargeted = c.diff.share(time.Now())
+	return true, nil
+}
+
+// acceptShare records a checked share and submits the block it finds on the
+// latest job, or as an uncle on a job up to MaxUncleDepth blocks older.
+// Blocks of declared jobs pay the declaring miner, who submits them.
+func (s *Server) acceptShare(ctx context.Context, j *job, latest bool, worker string, sh *share) error {
+	key := fmt.Sprintf("%x/%x", sh.nonce, sh.minimal)
+	s.mu.Lock()
+	_, dup := j.seen[key]
+	j.seen[key] = struct{}{}
+	s.mu.Unlock()
+	if dup {
+		return ErrDuplicateShare
+	}
+
+	s.logger.Info("share accepted", "worker", worker, "job", j.id, "block", sh.block)
+	if !sh.block {
+		return nil
+	}
+	uncle := !latest
+	if uncle {
+		if l := s.latestJob(); l == nil || l.work.Height-j.work.Height > types.MaxUncleDepth {
+			return nil
+		}
+	}
+	if j.miner != s.config.Pool {
+		s.logger.Info("block found on declared job", "worker", worker, "miner", j.miner, "height", j.work.Height+1, "uncle", uncle)
+		return nil
+	}
+	msg := j.proof(s.config.HardwareID, sh)
+	if err := s.submit(ctx, msg); err != nil {
+		s.logger.Error("submit block", "worker", worker, "height", j.work.Height+1, "uncle", uncle, "err", err)
+	} else {
+		s.logger.Info("block submitted", "worker", worker, "height", j.work.Height+1, "uncle", uncle, "nonce", sh.nonce)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package stratum
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// Stratum V2 framing and messages. Frames are a 6 byte header, the extension
+// type, with its high bit marking channel messages, the message type and a
+// 24 bit payload length, followed by the payload; both are encrypted, the
+// payload in chunks of at most 64 KiB of ciphertext. Integers are little
+// endian.
+//
+// zChain speaks a dialect: an Equihash share needs an 8 byte nonce and the
+// solution, which SubmitSharesStandard has no room for, so shares go in
+// SubmitSharesEquihash under zChain's extension type. Templates have no
+// coinbase or transactions, only the parent block, the address the header
+// commits to and the time, so the job declaration messages carry those in
+// place of the Bitcoin fields.
+
+const (
+	sv2Version = 2
+
+	protocolMining         = 0
+	protocolJobDeclaration = 1
+
+	// extensionEquihash is zChain's extension type
+	extensionEquihash   = 0x5a43
+	extensionChannelMsg = 0x8000
+
+	frameHeaderSize = 6
+	maxChunk        = math.MaxUint16
+	// maxFramePayload bounds a received payload; the largest is a 200_9
+	// share, about 1.4 KB
+	maxFramePayload = 16 * 1024
+)
+
+// Message types
+const (
+	msgSetupConnection        = 0x00
+	msgSetupConnectionSuccess = 0x01
+	msgSetupConnectionError   = 0x02
+
+	msgOpenStandardMiningChannel        = 0x10
+	msgOpenStandardMiningChannelSuccess = 0x11
+	msgOpenMiningChannelError           = 0x12
+	msgNewMiningJob                     = 0x15
+	msgSubmitSharesEquihash             = 0x1a
+	msgSubmitSharesSuccess              = 0x1c
+	msgSubmitSharesError                = 0x1d
+	msgSetNewPrevHash                   = 0x20
+	msgSetTarget                        = 0x21
+	msgSetCustomMiningJob               = 0x22
+	msgSetCustomMiningJobSuccess        = 0x23
+	msgSetCustomMiningJobError          = 0x24
+
+	msgAllocateMiningJobToken        = 0x50
+	msgAllocateMiningJobTokenSuccess = 0x51
+	msgDeclareMiningJob              = 0x57
+	msgDeclareMiningJobSuccess       = 0x58
+	msgDeclareMiningJobError         = 0x59
+)
+
+var errFrame = errors.New("malformed stratum v2 frame")
+
+// frame is a decrypted frame
+type frame struct {
+	extension uint16
+	msgType   uint8
+	payload   []byte
+}
+
+// writeFrame encrypts and writes a message
+func (c *noiseConn) writeFrame(extension uint16, msgType uint8, payload []byte) error {
+	var header [frameHeaderSize]byte
+	binary.LittleEndian.PutUint16(header[0:], extension)
+	header[2] = msgType
+	header[3], header[4], header[5] = byte(len(payload)), byte(len(payload)>>8), byte(len(payload)>>16)
+
+	out := c.send.encrypt(nil, header[:])
+	for len(payload) > 0 {
+		n := len(payload)
+		if n > maxChunk-macSize {
+			n = maxChunk - macSize
+		}
+		out = append(out, c.send.encrypt(nil, payload[:n])...)
+		payload = payload[n:]
+	}
+	_, err := c.rw.Write(out)
+	return err
+}
+
+// readFrame reads and decrypts a message
+func (c *noiseConn) readFrame() (*frame, error) {
+	buf := make([]byte, frameHeaderSize+macSize)
+	if _, err := io.ReadFull(c.rw, buf); err != nil {
+		return nil, err
+	}
+	header, err := c.recv.decrypt(nil, buf)
+	if err != nil {
+		return nil, err
+	}
+	f := &frame{extension: binary.LittleEndian.Uint16(header[0:]), msgType: header[2]}
+	size := int(header[3]) | int(header[4])<<8 | int(header[5])<<16
+	if size > maxFramePayload {
+		return nil, fmt.Errorf("%w: %d byte payload", errFrame, size)
+	}
+
+	for size > 0 {
+		n := size
+		if n > maxChunk-macSize {
+			n = maxChunk - macSize
+		}
+		chunk := make([]byte, n+macSize)
+		if _, err := io.ReadFull(c.rw, chunk); err != nil {
+			return nil, err
+		}
+		plain, err := c.recv.decrypt(nil, chunk)
+		if err != nil {
+			return nil, err
+		}
+		f.payload = append(f.payload, plain...)
+		size -= n
+	}
+	return f, nil
+}
+
+// encoder appends the binary types of Stratum V2
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) u8(v uint8)   { e.b = append(e.b, v) }
+func (e *encoder) u16(v uint16) { e.b = binary.LittleEndian.AppendUint16(e.b, v) }
+func (e *encoder) u32(v uint32) { e.b = binary.LittleEndian.AppendUint32(e.b, v) }
+func (e *encoder) u64(v uint64) { e.b = binary.LittleEndian.AppendUint64(e.b, v) }
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.u8(1)
+	} else {
+		e.u8(0)
+	}
+}
+
+// u256 is a hash, as it is
+func (e *encoder) u256(v []byte) {
+	var b [32]byte
+	copy(b[:], v)
+	e.b = append(e.b, b[:]...)
+}
+
+// target is a 256 bit integer, little endian
+func (e *encoder) target(v *big.Int) {
+	var b [32]byte
+	v.FillBytes(b[:])
+	for i := 0; i < 16; i++ {
+		b[i], b[31-i] = b[31-i], b[i]
+	}
+	e.b = append(e.b, b[:]...)
+}
+
+// b0_255 is bytes with a one byte length, also STR0_255
+func (e *encoder) b0_255(v []byte) {
+	e.u8(uint8(len(v)))
+	e.b = append(e.b, v...)
+}
+
+// b0_64k is bytes with a two byte length
+func (e *encoder) b0_64k(v []byte) {
+	e.u16(uint16(len(v)))
+	e.b = append(e.b, v...)
+}
+
+// optionU32 is OPTION[U32], absent for nil
+func (e *encoder) optionU32(v *uint32) {
+	if v == nil {
+		e.u8(0)
+		return
+	}
+	e.u8(1)
+	e.u32(*v)
+}
+
+// decoder reads the binary types of Stratum V2, keeping the first error
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil || len(d.b) < n {
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: short payload", errFrame)
+		}
+		return make([]byte, n)
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) u8() uint8    { return d.take(1)[0] }
+func (d *decoder) u16() uint16  { return binary.LittleEndian.Uint16(d.take(2)) }
+func (d *decoder) u32() uint32  { return binary.LittleEndian.Uint32(d.take(4)) }
+func (d *decoder) u64() uint64  { return binary.LittleEndian.Uint64(d.take(8)) }
+func (d *decoder) u256() []byte { return append([]byte(nil), d.take(32)...) }
+
+func (d *decoder) target() *big.Int {
+	b := append([]byte(nil), d.take(32)...)
+	for i := 0; i < 16; i++ {
+		b[i], b[31-i] = b[31-i], b[i]
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func (d *decoder) b0_255() []byte {
+	return append([]byte(nil), d.take(int(d.u8()))...)
+}
+
+func (d *decoder) str() string {
+	return string(d.b0_255())
+}
+
+func (d *decoder) b0_64k() []byte {
+	return append([]byte(nil), d.take(int(d.u16()))...)
+}
+
+// done is the error of decoding, including trailing bytes
+func (d *decoder) done() error {
+	if d.err == nil && len(d.b) != 0 {
+		d.err = fmt.Errorf("%w: %d trailing bytes", errFrame, len(d.b))
+	}
+	return d.err
+}
+
+// Received messages
+
+type setupConnection struct {
+	protocol   uint8
+	minVersion uint16
+	maxVersion uint16
+	vendor     string
+	device     string
+}
+
+func (m *setupConnection) decode(d *decoder) {
+	m.protocol = d.u8()
+	m.minVersion = d.u16()
+	m.maxVersion = d.u16()
+	d.u32() // flags; none are supported
+	d.str() // endpoint host
+	d.u16() // endpoint port
+	m.vendor = d.str()
+	d.str() // hardware version
+	d.str() // firmware
+	m.device = d.str()
+}
+
+type openStandardMiningChannel struct {
+	requestID    uint32
+	userIdentity string
+	maxTarget    *big.Int
+}
+
+func (m *openStandardMiningChannel) decode(d *decoder) {
+	m.requestID = d.u32()
+	m.userIdentity = d.str()
+	d.u32() // nominal hash rate
+	m.maxTarget = d.target()
+}
+
+// submitSharesEquihash is SubmitSharesStandard with the solution; nonce is
+// the miner's half of the header nonce, after the channel's extranonce
+type submitSharesEquihash struct {
+	channelID      uint32
+	sequenceNumber uint32
+	jobID          uint32
+	nonce          uint32
+	ntime          uint32
+	version        uint32
+	solution       []byte
+}
+
+func (m *submitSharesEquihash) decode(d *decoder) {
+	m.channelID = d.u32()
+	m.sequenceNumber = d.u32()
+	m.jobID = d.u32()
+	m.nonce = d.u32()
+	m.ntime = d.u32()
+	m.version = d.u32()
+	m.solution = d.b0_64k()
+}
+
+type setCustomMiningJob struct {
+	channelID uint32
+	requestID uint32
+	token     []byte
+}
+
+func (m *setCustomMiningJob) decode(d *decoder) {
+	m.channelID = d.u32()
+	m.requestID = d.u32()
+	m.token = d.b0_255()
+}
+
+type allocateMiningJobToken struct {
+	userIdentifier string
+	requestID      uint32
+}
+
+func (m *allocateMiningJobToken) decode(d *decoder) {
+	m.userIdentifier = d.str()
+	m.requestID = d.u32()
+}
+
+// declareMiningJob declares the template of a job: the block it follows, the
+// address its header commits to, and its time in Unix milliseconds
+type declareMiningJob struct {
+	requestID uint32
+	token     []byte
+	version   uint32
+	prevHash  []byte
+	miner     string
+	timestamp uint64
+}
+
+func (m *declareMiningJob) decode(d *decoder) {
+	m.requestID = d.u32()
+	m.token = d.b0_255()
+	m.version = d.u32()
+	m.prevHash = d.u256()
+	m.miner = d.str()
+	m.timestamp = d.u64()
+}
@@ -0,0 +1,497 @@
+package stratum
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// handshakeTimeout bounds the Noise handshake and SetupConnection
+const handshakeTimeout = 10 * time.Second
+
+// declaration is a job declaration token: allocated, then bound to the
+// template the miner declares
+type declaration struct {
+	user     string
+	height   int64 // Latest block when allocated, for expiry
+	declared bool
+
+	prevHash  []byte
+	miner     string
+	timestamp int64 // Unix milliseconds
+}
+
+// ServeV2 accepts Stratum V2 miners on listener and follows the chain until
+// ctx is done. Mining connections open standard channels and are sent the
+// pool's jobs; with Config.JobDeclaration, job declaration connections
+// declare templates that mining connections then mine with
+// SetCustomMiningJob.
+func (s *Server) ServeV2(ctx context.Context, listener net.Listener) error {
+	if s.config.AuthorityKey == nil {
+		return errors.New("stratum v2 needs an authority key")
+	}
+	return s.accept(ctx, listener, s.handleV2)
+}
+
+// sv2Conn is a Stratum V2 connection
+type sv2Conn struct {
+	s        *Server
+	nc       *noiseConn
+	protocol uint8
+
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	channels    map[uint32]*channel
+	nextChannel uint32
+}
+
+// channel is a standard mining channel
+type channel struct {
+	id     uint32
+	user   string
+	nonce1 []byte
+	diff   *vardiff
+	custom map[uint32]*job // Declared jobs set on the channel, dropped on every block
+}
+
+func (s *Server) handleV2(ctx context.Context, netConn net.Conn) {
+	defer netConn.Close()
+
+	_ = netConn.SetDeadline(time.Now().Add(handshakeTimeout))
+	nc, err := noiseRespond(netConn, s.static, s.config.AuthorityKey, time.Now())
+	if err != nil {
+		s.logger.Debug("stratum v2 handshake", "remote", netConn.RemoteAddr(), "err", err)
+		return
+	}
+	c := &sv2Conn{s: s, nc: nc, channels: make(map[uint32]*channel)}
+	if !c.setup() {
+		return
+	}
+	_ = netConn.SetDeadline(time.Time{})
+
+	if c.protocol == protocolMining {
+		s.register(c)
+		defer s.unregister(c)
+	}
+	for {
+		f, err := nc.readFrame()
+		if err != nil {
+			return
+		}
+		if err := c.dispatch(ctx, f); err != nil {
+			s.logger.Debug("stratum v2 message", "remote", netConn.RemoteAddr(), "type", f.msgType, "err", err)
+			return
+		}
+	}
+}
+
+// send writes a message built by fill
+func (c *sv2Conn) send(extension uint16, msgType uint8, fill func(e *encoder)) {
+	var e encoder
+	fill(&e)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.nc.writeFrame(extension, msgType, e.b)
+}
+
+// setup answers the SetupConnection every connection opens with
+func (c *sv2Conn) setup() bool {
+	f, err := c.nc.readFrame()
+	if err != nil || f.msgType != msgSetupConnection {
+		return false
+	}
+	var m setupConnection
+	d := &decoder{b: f.payload}
+	m.decode(d)
+	if d.done() != nil {
+		return false
+	}
+
+	fail := func(code string) bool {
+		c.send(0, msgSetupConnectionError, func(e *encoder) {
+			e.u32(0)
+			e.b0_255([]byte(code))
+		})
+		return false
+	}
+	switch {
+	case m.minVersion > sv2Version || m.maxVersion < sv2Version:
+		return fail("protocol-version-mismatch")
+	case m.protocol == protocolMining:
+	case m.protocol == protocolJobDeclaration && c.s.config.JobDeclaration:
+	default:
+		return fail("unsupported-protocol")
+	}
+
+	c.protocol = m.protocol
+	c.s.logger.Debug("stratum v2 connection", "protocol", m.protocol, "vendor", m.vendor, "device", m.device)
+	c.send(0, msgSetupConnectionSuccess, func(e *encoder) {
+		e.u16(sv2Version)
+		e.u32(0)
+	})
+	return true
+}
+
+func (c *sv2Conn) dispatch(ctx context.Context, f *frame) error {
+	d := &decoder{b: f.payload}
+	extension := f.extension &^ extensionChannelMsg
+
+	switch {
+	case c.protocol == protocolMining && extension == 0 && f.msgType == msgOpenStandardMiningChannel:
+		var m openStandardMiningChannel
+		m.decode(d)
+		if err := d.done(); err != nil {
+			return err
+		}
+		c.openChannel(&m)
+
+	case c.protocol == protocolMining && extension == extensionEquihash && f.msgType == msgSubmitSharesEquihash:
+		var m submitSharesEquihash
+		m.decode(d)
+		if err := d.done(); err != nil {
+			return err
+		}
+		c.submitShares(ctx, &m)
+
+	case c.protocol == protocolMining && extension == 0 && f.msgType == msgSetCustomMiningJob:
+		var m setCustomMiningJob
+		m.decode(d)
+		if err := d.done(); err != nil {
+			return err
+		}
+		c.setCustomJob(&m)
+
+	case c.protocol == protocolJobDeclaration && extension == 0 && f.msgType == msgAllocateMiningJobToken:
+		var m allocateMiningJobToken
+		m.decode(d)
+		if err := d.done(); err != nil {
+			return err
+		}
+		c.allocateToken(&m)
+
+	case c.protocol == protocolJobDeclaration && extension == 0 && f.msgType == msgDeclareMiningJob:
+		var m declareMiningJob
+		m.decode(d)
+		if err := d.done(); err != nil {
+			return err
+		}
+		c.declareJob(&m)
+
+	default:
+		// Unknown messages are ignored, as the protocol allows
+	}
+	return nil
+}
+
+func (c *sv2Conn) openChannel(m *openStandardMiningChannel) {
+	// A device asking for harder shares gets them: the least difficulty
+	// whose target is within max_target
+	var minDifficulty *big.Int
+	if m.maxTarget.Sign() > 0 {
+		minDifficulty = new(big.Int).Add(types.EquihashTarget(1), m.maxTarget)
+		minDifficulty.Sub(minDifficulty, big.NewInt(1))
+		minDifficulty.Div(minDifficulty, m.maxTarget)
+	}
+	if minDifficulty == nil || !minDifficulty.IsUint64() {
+		c.send(0, msgOpenMiningChannelError, func(e *encoder) {
+			e.u32(m.requestID)
+			e.b0_255([]byte("max-target-out-of-range"))
+		})
+		return
+	}
+
+	c.mu.Lock()
+	c.nextChannel++
+	ch := &channel{
+		id:     c.nextChannel,
+		user:   m.userIdentity,
+		nonce1: c.s.allocNonce1(),
+		diff:   newVardiff(c.s.config.PoolDifficulty, minDifficulty.Uint64(), c.s.config.ShareInterval, time.Now()),
+		custom: make(map[uint32]*job),
+	}
+	_, target := ch.diff.current()
+	c.channels[ch.id] = ch
+	c.mu.Unlock()
+
+	c.send(0, msgOpenStandardMiningChannelSuccess, func(e *encoder) {
+		e.u32(m.requestID)
+		e.u32(ch.id)
+		e.target(target)
+		e.b0_255(ch.nonce1)
+		e.u32(0) // No group channel
+	})
+	if j := c.s.latestJob(); j != nil {
+		c.sendJob(ch, j)
+	}
+}
+
+// sendJob sends a job to a channel as a future job and the previous hash
+// that activates it
+func (c *sv2Conn) sendJob(ch *channel, j *job) {
+	c.send(extensionChannelMsg, msgNewMiningJob, func(e *encoder) {
+		e.u32(ch.id)
+		e.u32(uint32(j.id))
+		e.optionU32(nil)
+		e.u32(j.header.Version)
+		e.u256(j.header.MerkleRoot)
+	})
+	c.send(extensionChannelMsg, msgSetNewPrevHash, func(e *encoder) {
+		e.u32(ch.id)
+		e.u32(uint32(j.id))
+		e.u256(j.header.PrevBlockHash)
+		e.u32(j.header.Timestamp)
+		e.u32(j.header.Bits)
+	})
+}
+
+func (c *sv2Conn) notifyJob(j *job) {
+	c.mu.Lock()
+	channels := make([]*channel, 0, len(c.channels))
+	for _, ch := range c.channels {
+		ch.custom = make(map[uint32]*job)
+		channels = append(channels, ch)
+	}
+	c.mu.Unlock()
+
+	for _, ch := range channels {
+		if ch.diff.newJob(time.Now()) {
+			c.sendTarget(ch)
+		}
+		c.sendJob(ch, j)
+	}
+}
+
+// sendTarget sends a channel's retargeted share target, which applies at
+// once; shares at the old target count until a new block
+func (c *sv2Conn) sendTarget(ch *channel) {
+	_, target := ch.diff.current()
+	c.send(extensionChannelMsg, msgSetTarget, func(e *encoder) {
+		e.u32(ch.id)
+		e.target(target)
+	})
+}
+
+// job finds a job of a channel, declared or the pool's, and whether it is on
+// the latest block
+func (c *sv2Conn) job(ch *channel, id uint32) (*job, bool) {
+	c.mu.Lock()
+	j := ch.custom[id]
+	c.mu.Unlock()
+	if j == nil {
+		return c.s.job(uint64(id))
+	}
+	latest := c.s.latestJob()
+	return j, latest != nil && latest.work.Height == j.work.Height
+}
+
+func (c *sv2Conn) submitShares(ctx context.Context, m *submitSharesEquihash) {
+	fail := func(code string) {
+		c.send(extensionChannelMsg, msgSubmitSharesError, func(e *encoder) {
+			e.u32(m.channelID)
+			e.u32(m.sequenceNumber)
+			e.b0_255([]byte(code))
+		})
+	}
+
+	c.mu.Lock()
+	ch := c.channels[m.channelID]
+	c.mu.Unlock()
+	if ch == nil {
+		fail("invalid-channel-id")
+		return
+	}
+	j, latest := c.job(ch, m.jobID)
+	if j == nil {
+		fail("invalid-job-id")
+		return
+	}
+	if m.version != j.header.Version {
+		fail("invalid-version")
+		return
+	}
+
+	nonce := headerNonce(ch.nonce1, binary.LittleEndian.AppendUint32(nil, m.nonce))
+	target := ch.diff.shareTarget()
+	sh, err := j.check(nonce, m.ntime, m.solution, target)
+	if err == nil {
+		err = c.s.acceptShare(ctx, j, latest, ch.user, sh)
+	}
+	switch {
+	case errors.Is(err, ErrLowDifficulty):
+		fail("difficulty-too-low")
+		return
+	case errors.Is(err, ErrDuplicateShare):
+		fail("duplicate-share")
+		return
+	case err != nil:
+		fail("invalid-share")
+		return
+	}
+
+	difficulty := new(big.Int).Div(types.EquihashTarget(1), target)
+	c.send(extensionChannelMsg, msgSubmitSharesSuccess, func(e *encoder) {
+		e.u32(m.channelID)
+		e.u32(m.sequenceNumber)
+		e.u32(1)
+		if difficulty.IsUint64() {
+			e.u64(difficulty.Uint64())
+		} else {
+			e.u64(^uint64(0))
+		}
+	})
+	if ch.diff.share(time.Now()) {
+		c.sendTarget(ch)
+	}
+}
+
+func (c *sv2Conn) setCustomJob(m *setCustomMiningJob) {
+	fail := func(code string) {
+		c.send(extensionChannelMsg, msgSetCustomMiningJobError, func(e *encoder) {
+			e.u32(m.channelID)
+			e.u32(m.requestID)
+			e.b0_255([]byte(code))
+		})
+	}
+
+	c.mu.Lock()
+	ch := c.channels[m.channelID]
+	c.mu.Unlock()
+	if ch == nil {
+		fail("invalid-channel-id")
+		return
+	}
+	if !c.s.config.JobDeclaration {
+		fail("unsupported")
+		return
+	}
+	j, code := c.s.declaredJob(m.token)
+	if j == nil {
+		fail(code)
+		return
+	}
+
+	c.mu.Lock()
+	ch.custom[uint32(j.id)] = j
+	c.mu.Unlock()
+	c.send(extensionChannelMsg, msgSetCustomMiningJobSuccess, func(e *encoder) {
+		e.u32(m.channelID)
+		e.u32(m.requestID)
+		e.u32(uint32(j.id))
+	})
+	c.sendJob(ch, j)
+}
+
+func (c *sv2Conn) allocateToken(m *allocateMiningJobToken) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	var height int64
+	if j := c.s.latestJob(); j != nil {
+		height = j.work.Height
+	}
+
+	c.s.mu.Lock()
+	c.s.declarations[hex.EncodeToString(token)] = &declaration{user: m.userIdentifier, height: height}
+	c.s.mu.Unlock()
+
+	c.send(0, msgAllocateMiningJobTokenSuccess, func(e *encoder) {
+		e.u32(m.requestID)
+		e.b0_255(token)
+		e.bool(true) // Jobs may be mined before they are declared
+	})
+}
+
+// declareJob checks a declared template against the latest block and binds
+// it to a new token, which mining connections set on their channels
+func (c *sv2Conn) declareJob(m *declareMiningJob) {
+	fail := func(code, details string) {
+		c.send(0, msgDeclareMiningJobError, func(e *encoder) {
+			e.u32(m.requestID)
+			e.b0_255([]byte(code))
+			e.b0_64k([]byte(details))
+		})
+	}
+
+	key := hex.EncodeToString(m.token)
+	c.s.mu.Lock()
+	d := c.s.declarations[key]
+	if d != nil && !d.declared {
+		delete(c.s.declarations, key)
+	}
+	c.s.mu.Unlock()
+	if d == nil || d.declared {
+		fail("invalid-mining-job-token", "")
+		return
+	}
+
+	latest := c.s.latestJob()
+	switch {
+	case latest == nil || !bytes.Equal(m.prevHash, latest.work.BlockHash):
+		fail("invalid-job-param-value-prev-hash", "not the latest block")
+		return
+	case m.version != latest.header.Version:
+		fail("invalid-job-param-value-version", "")
+		return
+	}
+	if _, err := sdk.AccAddressFromBech32(m.miner); err != nil {
+		fail("invalid-job-param-value-miner", err.Error())
+		return
+	}
+	timestamp := int64(m.timestamp)
+	if err := types.CheckSolutionTime(timestamp, latest.work.BlockTime, time.Now().UnixMilli(), latest.work.MaxFutureDrift); err != nil {
+		fail("invalid-job-param-value-timestamp", err.Error())
+		return
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	c.s.mu.Lock()
+	c.s.declarations[hex.EncodeToString(token)] = &declaration{
+		user:      d.user,
+		height:    latest.work.Height,
+		declared:  true,
+		prevHash:  m.prevHash,
+		miner:     m.miner,
+		timestamp: timestamp,
+	}
+	c.s.mu.Unlock()
+	c.s.logger.Info("job declared", "user", d.user, "miner", m.miner, "height", latest.work.Height+1)
+
+	c.send(0, msgDeclareMiningJobSuccess, func(e *encoder) {
+		e.u32(m.requestID)
+		e.b0_255(token)
+	})
+}
+
+// declaredJob makes a job of the template declared under token, or returns
+// the error code why not
+func (s *Server) declaredJob(token []byte) (*job, string) {
+	latest := s.latestJob()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.declarations[hex.EncodeToString(token)]
+	if d == nil || !d.declared {
+		return nil, "invalid-mining-job-token"
+	}
+	if latest == nil || !bytes.Equal(d.prevHash, latest.work.BlockHash) {
+		return nil, "stale-prev-hash"
+	}
+	s.nextJob++
+	return newJob(s.nextJob, latest.work, d.miner, d.timestamp), ""
+}
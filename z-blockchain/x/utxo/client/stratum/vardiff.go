@@ -0,0 +1,119 @@
+package stratum
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"z-blockchain/x/utxo/types"
+)
+
+const (
+	// vardiffShares is how many shares a retarget measures
+	vardiffShares = 16
+	// vardiffMaxStep bounds the factor of one retarget
+	vardiffMaxStep = 4
+)
+
+// vardiff is the share difficulty of a connection or channel, retargeted
+// toward one share per interval so a single GPU and a farm behind one
+// connection both submit at a steady cadence
+type vardiff struct {
+	interval time.Duration // Zero keeps the difficulty fixed
+	min      uint64
+
+	mu         sync.Mutex
+	difficulty uint64
+	target     *big.Int
+	previous   *big.Int // Target before the last retarget, accepted until the next job
+	shares     int
+	since      time.Time
+}
+
+func newVardiff(difficulty, min uint64, interval time.Duration, now time.Time) *vardiff {
+	if difficulty < min {
+		difficulty = min
+	}
+	return &vardiff{
+		interval:   interval,
+		min:        min,
+		difficulty: difficulty,
+		target:     types.EquihashTarget(difficulty),
+		since:      now,
+	}
+}
+
+// current is the difficulty and target sent to the miner
+func (v *vardiff) current() (uint64, *big.Int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.difficulty, v.target
+}
+
+// shareTarget is the target shares are checked against: the current one, or
+// the one before a retarget while the miner may still be working to it
+func (v *vardiff) shareTarget() *big.Int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.previous != nil && v.previous.Cmp(v.target) > 0 {
+		return v.previous
+	}
+	return v.target
+}
+
+// share counts an accepted share and reports whether the difficulty changed
+func (v *vardiff) share(now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.shares++
+	return v.retarget(now)
+}
+
+// newJob retires the previous target and reports whether the difficulty
+// changed, which lowers it for miners that stopped finding shares
+func (v *vardiff) newJob(now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.previous = nil
+	return v.retarget(now)
+}
+
+// retarget scales the difficulty by the measured share rate once enough
+// shares arrived, or once the time they should take has passed
+func (v *vardiff) retarget(now time.Time) bool {
+	elapsed := now.Sub(v.since)
+	if v.interval == 0 || (v.shares < vardiffShares && elapsed < vardiffShares*v.interval) {
+		return false
+	}
+
+	// difficulty * shares * interval / elapsed, within vardiffMaxStep
+	next := new(big.Int).SetUint64(v.difficulty)
+	next.Mul(next, big.NewInt(int64(v.shares)))
+	next.Mul(next, big.NewInt(int64(v.interval)))
+	next.Div(next, big.NewInt(int64(elapsed)+1))
+	lo := v.difficulty / vardiffMaxStep
+	hi := new(big.Int).Mul(new(big.Int).SetUint64(v.difficulty), big.NewInt(vardiffMaxStep))
+	if hi.IsUint64() && next.Cmp(hi) > 0 {
+		next = hi
+	}
+	d := ^uint64(0)
+	if next.IsUint64() {
+		d = next.Uint64()
+	}
+	if d < lo {
+		d = lo
+	}
+	if d < v.min {
+		d = v.min
+	}
+
+	v.shares = 0
+	v.since = now
+	if d == v.difficulty {
+		return false
+	}
+	v.previous = v.target
+	v.difficulty = d
+	v.target = types.EquihashTarget(d)
+	return true
+}
@@ -0,0 +1,42 @@
+package equihash
+
+import (
+	"bytes"
+	"testing"
+
+	xblake2b "golang.org/x/crypto/blake2b"
+)
+
+// RFC 7693 appendix A: BLAKE2b-512 of "abc", unpersonalized
+func TestBlake2bVector(t *testing.T) {
+	d := newBlake2b(64, [16]byte{})
+	d.Write([]byte("abc"))
+	want := mustHex("ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d1" +
+		"7d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923")
+	if got := d.Sum(); !bytes.Equal(got, want) {
+		t.Fatalf("got %x, want %x", got, want)
+	}
+}
+
+// Unpersonalized, the state must agree with x/crypto at every output size
+// and across block boundaries
+func TestBlake2bMatchesXCrypto(t *testing.T) {
+	data := make([]byte, 3*blake2bBlockSize+1)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for size := 1; size <= 64; size++ {
+		for _, n := range []int{0, 1, blake2bBlockSize, blake2bBlockSize + 1, len(data)} {
+			ref, err := xblake2b.New(size, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref.Write(data[:n])
+			d := newBlake2b(size, [16]byte{})
+			d.Write(data[:n])
+			if !bytes.Equal(d.Sum(), ref.Sum(nil)) {
+				t.Errorf("blake2b-%d of %d bytes differs from x/crypto", 8*size, n)
+			}
+		}
+	}
+}
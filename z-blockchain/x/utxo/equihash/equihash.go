@@ -0,0 +1,310 @@
+// Package equihash verifies Equihash proofs of work as Zcash specifies them,
+// for any N and K; the chain mines 144_5 (zhash). A solution is 2^K indices
+// into the list of N bit BLAKE2b hashes of the header, personalized with
+// "ZcashPoW" and N and K, whose XOR is zero. Wagner's algorithm finds them in
+// K rounds of collisions on N/(K+1) bits, which takes memory for the whole
+// list; verifying walks the same tree over the 2^K hashes:
+//
+//   - at every level the two halves of each pair collide on the next
+//     N/(K+1) bits, which are then dropped;
+//   - the first index of the left half is below that of the right half, so
+//     each solution has one canonical order;
+//   - no index repeats;
+//   - the bits left after K rounds are zero.
+//
+// Solutions travel in the minimal encoding: the indices, N/(K+1)+1 bits each,
+// packed big endian.
+package equihash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrParams        = errors.New("invalid equihash parameters")
+	ErrSolutionSize  = errors.New("invalid equihash solution size")
+	ErrIndexRange    = errors.New("equihash index out of range")
+	ErrCollision     = errors.New("equihash hashes do not collide")
+	ErrOrdering      = errors.New("equihash index tree incorrectly ordered")
+	ErrDuplicate     = errors.New("equihash solution repeats an index")
+	ErrNonZeroResult = errors.New("equihash hashes do not XOR to zero")
+)
+
+// Params are the Equihash parameters N, the hash length in bits, and K, the
+// number of collision rounds
+type Params struct {
+	N, K int
+}
+
+// Zhash is Equihash 144_5, the chain's proof of work
+var Zhash = Params{N: 144, K: 5}
+
+// Validate checks that N splits into K+1 whole collision lengths, as Zcash
+// requires, and that the indices fit 32 bits
+func (p Params) Validate() error {
+	if p.K < 1 || p.N <= 0 || p.N%8 != 0 || p.N%(p.K+1) != 0 || p.N > 512 {
+		return fmt.Errorf("%w: N=%d K=%d", ErrParams, p.N, p.K)
+	}
+	if p.CollisionBitLength()+1 > 32 || p.K > 16 {
+		return fmt.Errorf("%w: N=%d K=%d", ErrParams, p.N, p.K)
+	}
+	return nil
+}
+
+// CollisionBitLength is the number of bits each round collides on
+func (p Params) CollisionBitLength() int {
+	return p.N / (p.K + 1)
+}
+
+// collisionByteLength is the bytes a collision chunk is expanded to
+func (p Params) collisionByteLength() int {
+	return (p.CollisionBitLength() + 7) / 8
+}
+
+// ListLength is the number of indices a solver hashes and collides, which
+// bounds its memory
+func (p Params) ListLength() uint64 {
+	return uint64(1) << (p.CollisionBitLength() + 1)
+}
+
+// SolutionIndices is the number of indices of a solution
+func (p Params) SolutionIndices() int {
+	return 1 << p.K
+}
+
+// SolutionSize is the size of a solution in the minimal encoding
+func (p Params) SolutionSize() int {
+	return p.SolutionIndices() * (p.CollisionBitLength() + 1) / 8
+}
+
+// indicesPerHash is the number of N bit hashes cut from one BLAKE2b output
+func (p Params) indicesPerHash() int {
+	return 512 / p.N
+}
+
+// Personalization is the BLAKE2b personalization of the parameters:
+// "ZcashPoW" followed by N and K as 32 bit little endian
+func (p Params) Personalization() [16]byte {
+	var personal [16]byte
+	copy(personal[:], "ZcashPoW")
+	binary.LittleEndian.PutUint32(personal[8:], uint32(p.N))
+	binary.LittleEndian.PutUint32(personal[12:], uint32(p.K))
+	return personal
+}
+
+// Verify checks a minimally encoded solution for input, the header the
+// solver hashed, nonce included
+func Verify(p Params, input, solution []byte) error {
+	indices, err := IndicesFromMinimal(p, solution)
+	if err != nil {
+		return err
+	}
+	return VerifyIndices(p, input, indices)
+}
+
+// VerifyIndices checks a solution given as its indices
+func VerifyIndices(p Params, input []byte, indices []uint32) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(indices) != p.SolutionIndices() {
+		return fmt.Errorf("%w: %d indices, expected %d", ErrSolutionSize, len(indices), p.SolutionIndices())
+	}
+	limit := p.ListLength()
+	for _, index := range indices {
+		if uint64(index) >= limit {
+			return fmt.Errorf("%w: %d", ErrIndexRange, index)
+		}
+	}
+
+	rows := make([]row, len(indices))
+	hasher := newHasher(p, input)
+	for i, index := range indices {
+		rows[i] = row{hash: hasher.expandedHash(index), indices: []uint32{index}}
+	}
+
+	cbl := p.collisionByteLength()
+	for len(rows) > 1 {
+		merged := make([]row, 0, len(rows)/2)
+		for i := 0; i < len(rows); i += 2 {
+			left, right := rows[i], rows[i+1]
+			if !bytes.Equal(left.hash[:cbl], right.hash[:cbl]) {
+				return fmt.Errorf("%w: indices %d and %d at round %d", ErrCollision, left.indices[0], right.indices[0], p.K-log2(len(rows))+1)
+			}
+			if right.indices[0] <= left.indices[0] {
+				return fmt.Errorf("%w: index %d before %d", ErrOrdering, right.indices[0], left.indices[0])
+			}
+			if !distinct(left.indices, right.indices) {
+				return ErrDuplicate
+			}
+			merged = append(merged, left.merge(right, cbl))
+		}
+		rows = merged
+	}
+
+	for _, b := range rows[0].hash {
+		if b != 0 {
+			return ErrNonZeroResult
+		}
+	}
+	return nil
+}
+
+// row is a node of the collision tree: the XOR of its leaves' expanded
+// hashes, less the chunks collided on, and its leaves' indices in order
+type row struct {
+	hash    []byte
+	indices []uint32
+}
+
+// merge XORs two colliding rows and drops the chunk they collide on
+func (r row) merge(other row, trim int) row {
+	hash := make([]byte, len(r.hash)-trim)
+	for i := range hash {
+		hash[i] = r.hash[trim+i] ^ other.hash[trim+i]
+	}
+	indices := make([]uint32, 0, len(r.indices)+len(other.indices))
+	indices = append(append(indices, r.indices...), other.indices...)
+	return row{hash: hash, indices: indices}
+}
+
+func distinct(a, b []uint32) bool {
+	seen := make(map[uint32]bool, len(a))
+	for _, index := range a {
+		seen[index] = true
+	}
+	for _, index := range b {
+		if seen[index] {
+			return false
+		}
+	}
+	return true
+}
+
+func log2(n int) int {
+	k := 0
+	for ; n > 1; n >>= 1 {
+		k++
+	}
+	return k
+}
+
+// hasher derives the hash of an index: BLAKE2b of the input and the index
+// divided by indicesPerHash, of which the N bits at the index's remainder are
+// taken
+type hasher struct {
+	p     Params
+	state *blake2b
+	input []byte
+}
+
+func newHasher(p Params, input []byte) *hasher {
+	size := p.indicesPerHash() * p.N / 8
+	return &hasher{p: p, state: newBlake2b(size, p.Personalization()), input: input}
+}
+
+// Hash returns the N bit hash of index
+func (h *hasher) Hash(index uint32) []byte {
+	var counter [4]byte
+	binary.LittleEndian.PutUint32(counter[:], index/uint32(h.p.indicesPerHash()))
+
+	h.state.Reset()
+	h.state.Write(h.input)
+	h.state.Write(counter[:])
+	out := h.state.Sum()
+
+	n := h.p.N / 8
+	start := int(index%uint32(h.p.indicesPerHash())) * n
+	return append([]byte(nil), out[start:start+n]...)
+}
+
+// expandedHash returns the hash of index split into its K+1 collision chunks,
+// each right aligned in whole bytes
+func (h *hasher) expandedHash(index uint32) []byte {
+	return expand(h.Hash(index), h.p.CollisionBitLength(), 0)
+}
+
+// expand splits a big endian bit string into chunks of bitLen bits, each
+// right aligned in (bitLen+7)/8 bytes after bytePad zero bytes
+func expand(in []byte, bitLen, bytePad int) []byte {
+	chunkBytes := (bitLen+7)/8 + bytePad
+	chunks := len(in) * 8 / bitLen
+	out := make([]byte, chunks*chunkBytes)
+
+	bit := 0
+	for c := 0; c < chunks; c++ {
+		var v uint64
+		for i := 0; i < bitLen; i++ {
+			v = v<<1 | uint64(in[bit/8]>>(7-bit%8)&1)
+			bit++
+		}
+		chunk := out[c*chunkBytes : (c+1)*chunkBytes]
+		for i := len(chunk) - 1; i >= 0 && v > 0; i-- {
+			chunk[i] = byte(v)
+			v >>= 8
+		}
+	}
+	return out
+}
+
+// compress packs chunks of bitLen bits, right aligned in (bitLen+7)/8 bytes
+// after bytePad bytes, into a big endian bit string; the inverse of expand
+func compress(in []byte, bitLen, bytePad int) []byte {
+	chunkBytes := (bitLen+7)/8 + bytePad
+	chunks := len(in) / chunkBytes
+	out := make([]byte, (chunks*bitLen+7)/8)
+
+	bit := 0
+	for c := 0; c < chunks; c++ {
+		var v uint64
+		for _, b := range in[c*chunkBytes : (c+1)*chunkBytes] {
+			v = v<<8 | uint64(b)
+		}
+		for i := bitLen - 1; i >= 0; i-- {
+			out[bit/8] |= byte(v>>uint(i)&1) << (7 - bit%8)
+			bit++
+		}
+	}
+	return out
+}
+
+// IndicesFromMinimal decodes the indices of a minimally encoded solution
+func IndicesFromMinimal(p Params, solution []byte) ([]uint32, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if len(solution) != p.SolutionSize() {
+		return nil, fmt.Errorf("%w: %d bytes, expected %d", ErrSolutionSize, len(solution), p.SolutionSize())
+	}
+
+	bitLen := p.CollisionBitLength() + 1
+	expanded := expand(solution, bitLen, 4-(bitLen+7)/8)
+	indices := make([]uint32, p.SolutionIndices())
+	for i := range indices {
+		indices[i] = binary.BigEndian.Uint32(expanded[4*i:])
+	}
+	return indices, nil
+}
+
+// MinimalFromIndices encodes a solution's indices minimally
+func MinimalFromIndices(p Params, indices []uint32) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if len(indices) != p.SolutionIndices() {
+		return nil, fmt.Errorf("%w: %d indices, expected %d", ErrSolutionSize, len(indices), p.SolutionIndices())
+	}
+
+	bitLen := p.CollisionBitLength() + 1
+	expanded := make([]byte, 4*len(indices))
+	for i, index := range indices {
+		if uint64(index) >= uint64(1)<<bitLen {
+			return nil, fmt.Errorf("%w: %d", ErrIndexRange, index)
+		}
+		binary.BigEndian.PutUint32(expanded[4*i:], index)
+	}
+	return compress(expanded, bitLen, 4-(bitLen+7)/8), nil
+}
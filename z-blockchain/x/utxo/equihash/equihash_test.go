@@ -0,0 +1,170 @@
+package equihash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// vector is a reference verification. Err is nil for a solution that must
+// verify, otherwise the error it must fail with.
+type vector struct {
+	Name     string
+	Params   Params
+	Input    []byte
+	Solution []byte
+	Err      error
+}
+
+// zcashInput is the input of Zcash's equihash_tests.cpp, which appends a 32
+// byte little endian nonce; zcash96_5 is its 96_5 solution for nonce 1
+var (
+	zcashInput = []byte("Equihash is an asymmetric PoW based on the Generalised Birthday problem.")
+	zcash96_5  = []uint32{
+		2261, 15185, 36112, 104243, 23779, 118390, 118332, 130041, 32642, 69878, 76925, 80080, 45858, 116805, 92842, 111026,
+		15972, 115059, 85191, 90330, 68190, 122819, 81830, 91132, 23460, 49807, 52426, 80391, 69567, 114474, 104973, 122568,
+	}
+)
+
+// Solutions for "Equihash N_K vector" followed by a nonce byte, as found by
+// Wagner's algorithm over the full index list
+var (
+	vector48_5 = mustHex("0164e4b4c3b29661ef0962cb59baf5ef07862a56cddbf7054651fd6f68fdbfc9df1e7bca")
+	vector96_5 = mustHex("0779b77a6c63bd9b13cabd95870579e61e2baa447713eb7cad53916554725605fb472869c08c5705189d52f3540adf4fa3bd44313ced3bf5037e7bd3bebbd2924039e7d5")
+
+	vector144_5 = mustHex("072bf4c98d4d5528153b9ffc8711f15ebbfdbee941e39742be1093ed7136769a3351fe358ad516deaa94550ecdfd73b9" +
+		"37fa0f33db6f9171d776755c7646b26174b5a0cc66a1c56d7c352742ef32d6e09453fe5671494796fa875f4fcc02edf3adc41d40")
+
+	vector200_9 = mustHex(
+		"000a28aead9e03917647b4851f4d4d6acd523e1ecd5469b70f449b69ace87fd5ce2bd0210aa735beacfe1a563be469ea" +
+			"50db84c562082624feaa8436d84f4a1cfad354f324dbe7f75524f32fefbe062f55796ee200f34538241532bbc9348a8f" +
+			"db7e239afa9519819702d7c156bb1e826d202196749eb94931b3fa93bc64018fc450cc70b573dddd80d481665ffdf590" +
+			"b269231117d53f831a5ddbd9df41d91d758e21ff3af002e4093b245584d25f76e8bc92d24be00304c69f0a062c1aa89c" +
+			"c0879363d6dcf082601bd1ee875052deb3801881dda1da11ce32cbb6f7703862f2d703457fa1021bc26482889eefed87" +
+			"c8e6d635597dcae5ab3bf71b0d853a0af44fe94ac540f410b2c0f04a89ec3df3461a90e73fd4944bfac0c5862e04e1ce" +
+			"51c008ddad181a83b3b9ec9f1d5fbd54c54b64d7bb72489cb9846621e0b557bcae579bf9ef953a9c4e4b9da78fd27130" +
+			"00b8c3e4bd036f16e2aa9286eca2c6e54982fa4f9f1155ccb32558b7077b28f1ae5e5b3d8c86f74f2a8612ed8deff0d3" +
+			"13cb454c144e97312a71a37c7b04d1153c9d0444c618e52dade25f516cb950eaeefac087015fd40e088e16449a4891f4" +
+			"edf0fbb4dfd5ce21d11301fd180656c7058bbdd85770ede45a65fd736c681b152392cd1ebbbda8bb32be19e9c4b1ea2e" +
+			"5c83863de1da600ee177edbb7b24d9d347101abb9518071905cbebb99e27a9fbc675a22d0d139870f1d99824a7082d13" +
+			"0a8d5d2d63777212eb0a6b1045952bfe27be1c24a18f8c9c517afd2855127255a4755aa0f593ad33b01b71b827e77d4d" +
+			"7989a43bf505a6de0f7caece06fee4983b8a7190a46d5127caa92b09a5ba94274f10d0e4c7e5c59472513dd14d1ea01b" +
+			"74d34db878260971f65481854428f7c3517eb73669707727adeb141417a6869ac6595cde93d62182521bc9a7f9172512" +
+			"002b2f5db70e1f7b5aab1220537046a398dd7d3d2f16d439318f69f89bb6f2631d4ab0ccf5faf89e830b1704db41af49" +
+			"f92b197af233eb42478c8fe647d7fb1fcceb50b9e336af2d5192a7be4670f4b0ace6a9e71e1eeb76a03336fd9e61f80f" +
+			"fbd5f15a8c01dacb922a4f7d0d89271be369ce8358991e74ad2611faee613eec979f139b406d4f40644a6cd82257ae49" +
+			"1d97446b86a69d237186d5bacee7aaeff4ac43c40f7f2eac00ca77aa146ab4fd99b0c3b5c7bbd7d9081b8e445e0bcc70" +
+			"dbe145b603af3c12ba18229d49bfd034125407476aaaca9c156ddd650290a9d2f15508524ba7441275fa11178cfb1fb9" +
+			"2911283c0e93b1a447f2e6ae07a714911f5c7729613d010c7c7907e4860a4597860a29c52a6e549bb53a67a7c1e4e3dc" +
+			"8e6378f3b720081bd87eba8c164f8cb542be866c3345f2f210b3ca20fb24f587c9bf0770b4fa6042fb8596c1e3f7ab0f" +
+			"01f406394e4f1eaef889b417416f2b1e91633ccfd4308555eb0650712bc13a6747984bd8020edd7bc5993c7aaa1012d4" +
+			"3552d601746fc24a2a0149b470a38b53fc67edea68bc89586ec81391dbb1a75f44df00c503beb9772994f3deca80b0f5" +
+			"fe4bd4fcca366844ef1bf27f42b54fc96967cbb30120bce8a7156d3eac6a4661b2e85aae697fea8e382dc7d1163295b9" +
+			"79db397839fc2aa26060e90685e7e05ce3869b880afc717a029c0d6acb6a8545f76e468eb65eaaff16913a6c5610e473" +
+			"6e4597f5dd35a5f1cb51ad49668f701ba8830aec0fb29127aae37f34f22fac4b7841b60834003f16c0345d976212c7c3" +
+			"345336e2b1a550e631d0aadd0ba574000a6b02c177a6f4bbf7e4fb0a031cb42ea920a3fd6422a0768b7fee263d42d899" +
+			"f1bc823f8f8b146efe5a1b460f20c14593d4de7d5181e1f018624b17378f83fe9b2b69f4ea31ce307fd828fa54a7f9d3")
+)
+
+// vectors covers Zcash's own test solution, the chain's 144_5, Zcash's 200_9,
+// whose 20 bit collisions are not byte aligned, and smaller parameters, with
+// each rule broken on a valid solution
+func vectors() []vector {
+	input := func(name string, nonce byte) []byte {
+		return append([]byte("Equihash "+name+" vector"), nonce)
+	}
+	p96 := Params{N: 96, K: 5}
+	in96 := input("96_5", 2)
+
+	zcashNonce := make([]byte, 32)
+	zcashNonce[0] = 1
+	zcash := append(append([]byte(nil), zcashInput...), zcashNonce...)
+
+	return []vector{
+		{"zcash 96_5", p96, zcash, mustMinimal(p96, zcash96_5), nil},
+		{"zcash 96_5 without nonce", p96, zcashInput, mustMinimal(p96, zcash96_5), ErrCollision},
+		{"144_5", Zhash, input("144_5", 0), vector144_5, nil},
+		{"200_9", Params{N: 200, K: 9}, input("200_9", 0), vector200_9, nil},
+		{"96_5", p96, in96, vector96_5, nil},
+		{"48_5", Params{N: 48, K: 5}, input("48_5", 2), vector48_5, nil},
+
+		{"144_5 other nonce", Zhash, input("144_5", 1), vector144_5, ErrCollision},
+		{"144_5 under other parameters", Params{N: 144, K: 8}, input("144_5", 0), vector144_5, ErrSolutionSize},
+		{"96_5 under 48_5 parameters", Params{N: 48, K: 5}, in96, vector96_5, ErrSolutionSize},
+		{"96_5 truncated", p96, in96, vector96_5[:len(vector96_5)-1], ErrSolutionSize},
+		{"96_5 halves swapped", p96, in96, alter(p96, vector96_5, func(indices []uint32) {
+			half := len(indices) / 2
+			swapped := append(append([]uint32{}, indices[half:]...), indices[:half]...)
+			copy(indices, swapped)
+		}), ErrOrdering},
+		{"96_5 first pair swapped", p96, in96, alter(p96, vector96_5, func(indices []uint32) {
+			indices[0], indices[1] = indices[1], indices[0]
+		}), ErrOrdering},
+		{"96_5 index repeated", p96, in96, alter(p96, vector96_5, func(indices []uint32) {
+			indices[1] = indices[0]
+		}), ErrOrdering},
+		{"96_5 index changed", p96, in96, alter(p96, vector96_5, func(indices []uint32) {
+			indices[5] ^= 1
+		}), ErrCollision},
+		{"145_5 parameters", Params{N: 145, K: 5}, in96, vector96_5, ErrParams},
+	}
+}
+
+// TestVectors checks the vectors and the minimal encoding round trip of the
+// valid ones
+func TestVectors(t *testing.T) {
+	for _, v := range vectors() {
+		err := Verify(v.Params, v.Input, v.Solution)
+		switch {
+		case v.Err == nil && err != nil:
+			t.Errorf("%s: %v", v.Name, err)
+			continue
+		case v.Err != nil && !errors.Is(err, v.Err):
+			t.Errorf("%s: got %v, want %v", v.Name, err, v.Err)
+		}
+		if v.Err != nil {
+			continue
+		}
+
+		indices, err := IndicesFromMinimal(v.Params, v.Solution)
+		if err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+			continue
+		}
+		minimal, err := MinimalFromIndices(v.Params, indices)
+		if err != nil || !bytes.Equal(minimal, v.Solution) {
+			t.Errorf("%s does not re-encode", v.Name)
+		}
+	}
+}
+
+// alter re-encodes a solution after changing its indices
+func alter(p Params, solution []byte, change func(indices []uint32)) []byte {
+	indices, err := IndicesFromMinimal(p, solution)
+	if err != nil {
+		panic(err)
+	}
+	change(indices)
+	altered, err := MinimalFromIndices(p, indices)
+	if err != nil {
+		panic(err)
+	}
+	return altered
+}
+
+func mustMinimal(p Params, indices []uint32) []byte {
+	minimal, err := MinimalFromIndices(p, indices)
+	if err != nil {
+		panic(err)
+	}
+	return minimal
+}
+
+func mustHex(s string) []byte {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
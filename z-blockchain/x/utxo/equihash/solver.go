@@ -0,0 +1,162 @@
+package equihash
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// maxSolverCollisionBits bounds the parameters Solve takes: its bucket table
+// and index list grow with 2^(N/(K+1)). 144_5 and 200_9 fit.
+const maxSolverCollisionBits = 24
+
+// maxBucketRows drops buckets with more colliding rows than that, which are
+// rare and mostly yield solutions that repeat an index
+const maxBucketRows = 16
+
+// ErrSolverParams is returned for parameters too large for the reference solver
+var ErrSolverParams = errors.New("equihash parameters beyond the reference solver")
+
+// level is one round of Wagner's algorithm: the rows left after colliding,
+// each the XOR of two rows of the round before, less the chunk they collided
+// on, and references to those two rows
+type level struct {
+	hashes   []byte // rowBytes per row
+	rowBytes int
+	left     []uint32
+	right    []uint32
+}
+
+func (l *level) rows() int {
+	return len(l.left)
+}
+
+func (l *level) row(i int) []byte {
+	return l.hashes[i*l.rowBytes : (i+1)*l.rowBytes]
+}
+
+// Solve finds up to limit solutions for input with Wagner's algorithm, in the
+// canonical order Verify accepts. It is the reference solver, plain Go on one
+// core: 144_5 takes a minute or two and about 2 GiB, 48_5 a millisecond. A
+// nonce may have no solution; the expected count is about two.
+func Solve(p Params, input []byte, limit int) ([][]uint32, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if p.CollisionBitLength() > maxSolverCollisionBits {
+		return nil, fmt.Errorf("%w: N=%d K=%d", ErrSolverParams, p.N, p.K)
+	}
+
+	cbl := p.collisionByteLength()
+	hasher := newHasher(p, input)
+	first := level{rowBytes: (p.K + 1) * cbl}
+	first.hashes = make([]byte, 0, int(p.ListLength())*first.rowBytes)
+	for i := uint64(0); i < p.ListLength(); i++ {
+		first.hashes = append(first.hashes, hasher.expandedHash(uint32(i))...)
+	}
+	// The first level's rows are the indices themselves
+	levels := []*level{&first}
+	rows := int(p.ListLength())
+
+	for round := 1; round <= p.K; round++ {
+		prev := levels[round-1]
+		next := collide(prev, rows, p.CollisionBitLength(), cbl, round == p.K)
+		// Only the references of earlier rounds are needed from here on
+		prev.hashes = nil
+		levels = append(levels, next)
+		rows = next.rows()
+	}
+
+	var solutions [][]uint32
+	for i := 0; i < rows && len(solutions) < limit; i++ {
+		indices := tree(levels, p.K, uint32(i))
+		if distinctAll(indices) {
+			solutions = append(solutions, indices)
+		}
+	}
+	return solutions, nil
+}
+
+// collide sorts the rows of prev into buckets by their first chunk and pairs
+// the rows of each bucket. On the last round only pairs whose remaining bits
+// XOR to zero are kept.
+func collide(prev *level, rows, bits, cbl int, last bool) *level {
+	key := func(i int) int {
+		v := 0
+		for _, b := range prev.row(i)[:cbl] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+
+	// Counting sort on the chunk
+	buckets := 1 << bits
+	starts := make([]uint32, buckets+1)
+	for i := 0; i < rows; i++ {
+		starts[key(i)+1]++
+	}
+	for b := 1; b <= buckets; b++ {
+		starts[b] += starts[b-1]
+	}
+	order := make([]uint32, rows)
+	for i := 0; i < rows; i++ {
+		k := key(i)
+		order[starts[k]] = uint32(i)
+		starts[k]++
+	}
+	starts = nil
+
+	next := &level{rowBytes: prev.rowBytes - cbl}
+	xor := make([]byte, next.rowBytes)
+	for s := 0; s < rows; {
+		e := s + 1
+		for e < rows && key(int(order[e])) == key(int(order[s])) {
+			e++
+		}
+		if e-s > 1 && e-s <= maxBucketRows {
+			for a := s; a < e; a++ {
+				for b := a + 1; b < e; b++ {
+					ra, rb := prev.row(int(order[a]))[cbl:], prev.row(int(order[b]))[cbl:]
+					zero := true
+					for j := range xor {
+						xor[j] = ra[j] ^ rb[j]
+						zero = zero && xor[j] == 0
+					}
+					if last != zero {
+						continue
+					}
+					next.hashes = append(next.hashes, xor...)
+					next.left = append(next.left, order[a])
+					next.right = append(next.right, order[b])
+				}
+			}
+		}
+		s = e
+	}
+	return next
+}
+
+// tree collects the indices under row i of a round, ordering each pair of
+// subtrees by their first index
+func tree(levels []*level, round int, i uint32) []uint32 {
+	if round == 0 {
+		return []uint32{i}
+	}
+	left := tree(levels, round-1, levels[round].left[i])
+	right := tree(levels, round-1, levels[round].right[i])
+	if right[0] < left[0] {
+		left, right = right, left
+	}
+	return append(left, right...)
+}
+
+func distinctAll(indices []uint32) bool {
+	sorted := append([]uint32(nil), indices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] == sorted[i-1] {
+			return false
+		}
+	}
+	return true
+}
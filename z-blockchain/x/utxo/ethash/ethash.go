@@ -0,0 +1,259 @@
+// Package ethash verifies Ethash proofs of work, the algorithm Altcoinchain
+// mines, for merged mining. A miner searches a nonce whose hashimoto over
+// the DAG of its epoch, a dataset of over 1 GiB regenerated every 30000
+// blocks, yields a result below the target. Verifying needs only the cache
+// the DAG is derived from, tens of MiB: every DAG item hashimoto reads is
+// recomputed from it, as the light clients of Ethereum do.
+package ethash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Ethash constants, as the Ethereum yellow paper specifies them
+const (
+	EpochLength        = 30000   // Blocks per epoch
+	datasetInitBytes   = 1 << 30 // Bytes in the dataset at genesis
+	datasetGrowthBytes = 1 << 23 // Dataset growth per epoch
+	cacheInitBytes     = 1 << 24 // Bytes in the cache at genesis
+	cacheGrowthBytes   = 1 << 17 // Cache growth per epoch
+	mixBytes           = 128     // Width of mix
+	hashBytes          = 64      // Hash length in bytes
+	hashWords          = 16      // Number of 32 bit ints in a hash
+	datasetParents     = 256     // Parents of each dataset item
+	cacheRounds        = 3       // Rounds in cache generation
+	loopAccesses       = 64      // Accesses in the hashimoto loop
+)
+
+// ErrMixDigest is returned for a header whose mix digest is not the one its
+// nonce yields
+var ErrMixDigest = errors.New("ethash mix digest mismatch")
+
+// Epoch returns the epoch of a block number
+func Epoch(number uint64) uint64 {
+	return number / EpochLength
+}
+
+// CacheSize returns the bytes of the verification cache of an epoch: the
+// largest size below the linear growth that is a prime number of hashes
+func CacheSize(epoch uint64) uint64 {
+	size := cacheInitBytes + cacheGrowthBytes*epoch - hashBytes
+	for !new(big.Int).SetUint64(size / hashBytes).ProbablyPrime(1) {
+		size -= 2 * hashBytes
+	}
+	return size
+}
+
+// DatasetSize returns the bytes of the DAG of an epoch: the largest size
+// below the linear growth that is a prime number of mixes
+func DatasetSize(epoch uint64) uint64 {
+	size := datasetInitBytes + datasetGrowthBytes*epoch - mixBytes
+	for !new(big.Int).SetUint64(size / mixBytes).ProbablyPrime(1) {
+		size -= 2 * mixBytes
+	}
+	return size
+}
+
+// SeedHash returns the seed the cache of an epoch is generated from:
+// Keccak-256 applied epoch times to 32 zero bytes
+func SeedHash(epoch uint64) []byte {
+	seed := make([]byte, 32)
+	h := sha3.NewLegacyKeccak256()
+	for i := uint64(0); i < epoch; i++ {
+		h.Reset()
+		h.Write(seed)
+		seed = h.Sum(seed[:0])
+	}
+	return seed
+}
+
+// Cache is the verification cache of an epoch
+type Cache struct {
+	epoch uint64
+	words []uint32
+}
+
+// NewCache generates the cache of an epoch with RandMemoHash: a chain of
+// Keccak-512 hashes of the seed, then cacheRounds passes each rehashing every
+// item with its predecessor XOR a pseudorandom item
+func NewCache(epoch uint64) *Cache {
+	size := CacheSize(epoch)
+	rows := int(size / hashBytes)
+	cache := make([]byte, size)
+
+	keccak512 := sha3.NewLegacyKeccak512()
+	sum := func(dst, data []byte) {
+		keccak512.Reset()
+		keccak512.Write(data)
+		keccak512.Sum(dst[:0])
+	}
+
+	sum(cache, SeedHash(epoch))
+	for offset := hashBytes; offset < len(cache); offset += hashBytes {
+		sum(cache[offset:], cache[offset-hashBytes:offset])
+	}
+
+	temp := make([]byte, hashBytes)
+	for i := 0; i < cacheRounds; i++ {
+		for j := 0; j < rows; j++ {
+			src := ((j - 1 + rows) % rows) * hashBytes
+			dst := j * hashBytes
+			xor := int(binary.LittleEndian.Uint32(cache[dst:])%uint32(rows)) * hashBytes
+			for k := range temp {
+				temp[k] = cache[src+k] ^ cache[xor+k]
+			}
+			sum(cache[dst:], temp)
+		}
+	}
+
+	words := make([]uint32, len(cache)/4)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(cache[i*4:])
+	}
+	return &Cache{epoch: epoch, words: words}
+}
+
+// Epoch returns the epoch of the cache
+func (c *Cache) Epoch() uint64 {
+	return c.epoch
+}
+
+// Hashimoto returns the mix digest and the result of a nonce for the seal
+// hash of a header, the Keccak-256 hash of its fields but the mix digest and
+// the nonce. The header's proof of work is valid when the mix digest is the
+// one it carries and the result does not exceed its target.
+func (c *Cache) Hashimoto(sealHash []byte, nonce uint64) (digest []byte, result []byte) {
+	keccak512 := sha3.NewLegacyKeccak512()
+	rows := uint32(DatasetSize(c.epoch) / mixBytes)
+
+	seed := make([]byte, 40)
+	copy(seed, sealHash)
+	binary.LittleEndian.PutUint64(seed[32:], nonce)
+	seed = keccak(keccak512, seed)
+	seedHead := binary.LittleEndian.Uint32(seed)
+
+	mix := make([]uint32, mixBytes/4)
+	for i := range mix {
+		mix[i] = binary.LittleEndian.Uint32(seed[i%16*4:])
+	}
+
+	temp := make([]uint32, len(mix))
+	for i := 0; i < loopAccesses; i++ {
+		parent := fnv(uint32(i)^seedHead, mix[i%len(mix)]) % rows
+		for j := uint32(0); j < mixBytes/hashBytes; j++ {
+			copy(temp[j*hashWords:], c.datasetItem(keccak512, 2*parent+j))
+		}
+		fnvHash(mix, temp)
+	}
+
+	// Compress the mix
+	for i := 0; i < len(mix); i += 4 {
+		mix[i/4] = fnv(fnv(fnv(mix[i], mix[i+1]), mix[i+2]), mix[i+3])
+	}
+	mix = mix[:len(mix)/4]
+
+	digest = make([]byte, 32)
+	for i, val := range mix {
+		binary.LittleEndian.PutUint32(digest[i*4:], val)
+	}
+	return digest, keccak(sha3.NewLegacyKeccak256(), append(seed, digest...))
+}
+
+// Verify checks the mix digest a header carries is the one its nonce yields
+// and returns the result, for the caller to check against the target
+func (c *Cache) Verify(sealHash []byte, nonce uint64, mixDigest []byte) ([]byte, error) {
+	digest, result := c.Hashimoto(sealHash, nonce)
+	if !bytes.Equal(digest, mixDigest) {
+		return nil, ErrMixDigest
+	}
+	return result, nil
+}
+
+// datasetItem recomputes the DAG item at index from the cache
+func (c *Cache) datasetItem(keccak512 hash.Hash, index uint32) []uint32 {
+	rows := uint32(len(c.words) / hashWords)
+
+	mix := make([]byte, hashBytes)
+	binary.LittleEndian.PutUint32(mix, c.words[(index%rows)*hashWords]^index)
+	for i := 1; i < hashWords; i++ {
+		binary.LittleEndian.PutUint32(mix[i*4:], c.words[(index%rows)*hashWords+uint32(i)])
+	}
+	mix = keccak(keccak512, mix)
+
+	intMix := make([]uint32, hashWords)
+	for i := range intMix {
+		intMix[i] = binary.LittleEndian.Uint32(mix[i*4:])
+	}
+	for i := uint32(0); i < datasetParents; i++ {
+		parent := fnv(index^i, intMix[i%hashWords]) % rows
+		fnvHash(intMix, c.words[parent*hashWords:])
+	}
+
+	for i, val := range intMix {
+		binary.LittleEndian.PutUint32(mix[i*4:], val)
+	}
+	mix = keccak(keccak512, mix)
+	for i := range intMix {
+		intMix[i] = binary.LittleEndian.Uint32(mix[i*4:])
+	}
+	return intMix
+}
+
+func keccak(h hash.Hash, data []byte) []byte {
+	h.Reset()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// fnv is the FNV-1 inspired mixing function of Ethash, which unlike FNV-1
+// multiplies by the full 32 bit input
+func fnv(a, b uint32) uint32 {
+	return a*0x01000193 ^ b
+}
+
+// fnvHash mixes data into mix
+func fnvHash(mix []uint32, data []uint32) {
+	for i := range mix {
+		mix[i] = mix[i]*0x01000193 ^ data[i]
+	}
+}
+
+// Caches keeps the caches of the last epochs verified in memory, as
+// generating one takes seconds
+type Caches struct {
+	mu     sync.Mutex
+	limit  int
+	caches []*Cache // Least recently used first
+}
+
+// NewCaches returns an empty set of caches holding up to limit epochs
+func NewCaches(limit int) *Caches {
+	return &Caches{limit: limit}
+}
+
+// Get returns the cache of an epoch, generating it if it is not held
+func (c *Caches) Get(epoch uint64) *Cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, cache := range c.caches {
+		if cache.epoch == epoch {
+			c.caches = append(append(c.caches[:i:i], c.caches[i+1:]...), cache)
+			return cache
+		}
+	}
+
+	cache := NewCache(epoch)
+	if len(c.caches) >= c.limit {
+		c.caches = c.caches[1:]
+	}
+	c.caches = append(c.caches, cache)
+	return cache
+}
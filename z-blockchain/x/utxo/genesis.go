@@ -0,0 +1,18 @@
+package utxo
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+)
+
+// InitGenesis initializes the module's state from a provided genesis state
+func InitGenesis(ctx sdk.Context, k keeper.Keeper, genState types.GenesisState) {
+	k.InitGenesis(ctx, genState)
+}
+
+// ExportGenesis returns the module's exported genesis
+func ExportGenesis(ctx sdk.Context, k keeper.Keeper) *types.GenesisState {
+	return k.ExportGenesis(ctx)
+}
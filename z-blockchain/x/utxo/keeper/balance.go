@@ -0,0 +1,102 @@
+package keeper
+
+import (
+	"sync"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/address"
+	"z-blockchain/x/utxo/types"
+)
+
+// MempoolView lists the transactions pending in the node's mempool, for
+// queries of unconfirmed balances. ante.UTXOMempool implements it.
+type MempoolView interface {
+	PendingTxs() []sdk.Tx
+}
+
+// mempoolHolder holds the mempool view, shared by the keeper's copies, as the
+// mempool is built after the keeper
+type mempoolHolder struct {
+	mu   sync.RWMutex
+	view MempoolView
+}
+
+// SetMempoolView sets the mempool whose pending transactions AddressBalance
+// reports. Without one nothing is pending.
+func (k Keeper) SetMempoolView(view MempoolView) {
+	k.mempool.mu.Lock()
+	defer k.mempool.mu.Unlock()
+	k.mempool.view = view
+}
+
+func (k Keeper) pendingTxs() []sdk.Tx {
+	k.mempool.mu.RLock()
+	defer k.mempool.mu.RUnlock()
+	if k.mempool.view == nil {
+		return nil
+	}
+	return k.mempool.view.PendingTxs()
+}
+
+// GetAddressBalance sums the unspent outputs of addr, a z1... address, from
+// its UTXO index: the confirmed, spendable value and the value of coinbase
+// outputs still short of the coinbase maturity
+func (k Keeper) GetAddressBalance(ctx sdk.Context, addr string) (confirmed, immature sdk.Int, count uint64) {
+	index := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOByAddressPrefix(addr))
+	utxoStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOKey)
+
+	confirmed, immature = sdk.ZeroInt(), sdk.ZeroInt()
+	iterator := index.Iterator(nil, nil)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var utxo types.UTXO
+		k.cdc.MustUnmarshal(utxoStore.Get(iterator.Key()), &utxo)
+		count++
+		if k.checkCoinbaseMaturity(ctx, utxo) != nil {
+			immature = immature.Add(intParam(utxo.Amount))
+		} else {
+			confirmed = confirmed.Add(intParam(utxo.Amount))
+		}
+	}
+	return confirmed, immature, count
+}
+
+// GetPendingBalance sums what the mempool's pending transactions pay to addr,
+// a z1... address, and the value of its unspent outputs they spend
+func (k Keeper) GetPendingBalance(ctx sdk.Context, addr string) (received, spent sdk.Int) {
+	received, spent = sdk.ZeroInt(), sdk.ZeroInt()
+	for _, tx := range k.pendingTxs() {
+		for _, msg := range tx.GetMsgs() {
+			var inputs []*types.TxInput
+			var outputs []*types.TxOutput
+			switch msg := msg.(type) {
+			case *types.MsgSendUTXO:
+				inputs, outputs = msg.Inputs, msg.Outputs
+			case *types.MsgShield:
+				inputs, outputs = msg.Inputs, msg.Outputs
+			case *types.MsgSendShielded:
+				outputs = msg.TransparentOutputs
+			case *types.MsgDeshield:
+				outputs = msg.Outputs
+			default:
+				continue
+			}
+
+			for _, input := range inputs {
+				utxo, found := k.GetUTXO(ctx, input.PrevTxHash, input.PrevOutputIndex)
+				if found && !utxo.IsSpent && utxo.Address == addr {
+					spent = spent.Add(intParam(utxo.Amount))
+				}
+			}
+			for _, output := range outputs {
+				if to, err := address.ToZChain(output.Address); err == nil && to == addr {
+					received = received.Add(intParam(output.Amount))
+				}
+			}
+		}
+	}
+	return received, spent
+}
@@ -0,0 +1,101 @@
+package keeper
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Ban score policy. An invalid mining solution costs InvalidSolutionBanScore
+// points and a point is forgiven every BanScoreDecayBlocks blocks, so a
+// miner occasionally losing a race with a retarget is never banned while
+// one sending ten bad solutions within a minute is, for BanBlocks blocks.
+const (
+	InvalidSolutionBanScore = 10
+	BanThreshold            = 100
+	BanScoreDecayBlocks     = 10
+	BanBlocks               = 1200 // 10 minutes at the 0.5s block time
+)
+
+// Ban scores guard the node's own mempool and proposals against proof
+// verification spam; like the minimum fee rate they are node policy. They
+// are kept in memory, shared by the keeper's copies: a refused submission
+// leaves no state, and invalid solutions never reach a block to be recorded
+// by every validator alike.
+type banScores struct {
+	mu     sync.Mutex
+	miners map[string]*banScore
+	pruned int64 // Height of the last pruneBanScores
+}
+
+type banScore struct {
+	score       int64
+	height      int64 // Of the last offense
+	bannedUntil int64 // First height the miner may submit again
+}
+
+func newBanScores() *banScores {
+	return &banScores{miners: make(map[string]*banScore)}
+}
+
+// AddBanScore charges a miner points for an invalid submission at the height
+// of ctx and bans it once its decayed score reaches BanThreshold. It returns
+// whether the miner is now banned.
+func (k Keeper) AddBanScore(ctx sdk.Context, miner string, points int64) bool {
+	k.banScores.mu.Lock()
+	defer k.banScores.mu.Unlock()
+
+	height := ctx.BlockHeight()
+	if height >= k.banScores.pruned+BanBlocks {
+		k.pruneBanScores(height)
+	}
+
+	s, found := k.banScores.miners[miner]
+	if !found {
+		s = &banScore{}
+		k.banScores.miners[miner] = s
+	}
+	if height < s.bannedUntil {
+		return true
+	}
+
+	s.score -= (height - s.height) / BanScoreDecayBlocks
+	if s.score < 0 {
+		s.score = 0
+	}
+	s.score += points
+	s.height = height
+	if s.score < BanThreshold {
+		return false
+	}
+
+	s.score = 0
+	s.bannedUntil = height + BanBlocks
+	k.Logger(ctx).Info("Banned miner for invalid mining submissions", "miner", miner, "until_height", s.bannedUntil)
+	return true
+}
+
+// MinerBanned reports whether a miner is banned at the height of ctx, and
+// the first height it may submit again
+func (k Keeper) MinerBanned(ctx sdk.Context, miner string) (int64, bool) {
+	k.banScores.mu.Lock()
+	defer k.banScores.mu.Unlock()
+
+	s, found := k.banScores.miners[miner]
+	if !found || ctx.BlockHeight() >= s.bannedUntil {
+		return 0, false
+	}
+	return s.bannedUntil, true
+}
+
+// pruneBanScores forgets the miners whose ban is over and whose score has
+// decayed away, so spam from throwaway addresses does not grow the map for
+// good. Called with the lock held, every BanBlocks blocks.
+func (k Keeper) pruneBanScores(height int64) {
+	k.banScores.pruned = height
+	for miner, s := range k.banScores.miners {
+		if height >= s.bannedUntil && s.score <= (height-s.height)/BanScoreDecayBlocks {
+			delete(k.banScores.miners, miner)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package keeper
+
+import (
+	"bytes"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+	"z-blockchain/x/utxo/workerpool"
+)
+
+// RecordBlockHash keeps the hash of the block ending, which the proofs of the
+// next block build on, as do its uncles later. Proposals of that block are
+// checked before it begins, when the header naming its parent is not known
+// yet.
+func (k Keeper) RecordBlockHash(ctx sdk.Context) {
+	ctx.KVStore(k.storeKey).Set(types.LastBlockHashKey, ctx.HeaderHash())
+	k.setBlockHash(ctx)
+}
+
+// lastBlockHash is the hash of the block before the one of ctx
+func (k Keeper) lastBlockHash(ctx sdk.Context) []byte {
+	if bz := ctx.KVStore(k.storeKey).Get(types.LastBlockHashKey); bz != nil {
+		return bz
+	}
+	// Not recorded yet on the first block, or the first after an upgrade
+	return ctx.BlockHeader().LastBlockId.Hash
+}
+
+// checkProofBinding refuses a mining proof made for another block: it must
+// name the block it is included in and build on that block's parent, so it
+// cannot be replayed later or on a fork
+func (k Keeper) checkProofBinding(ctx sdk.Context, proof types.MiningProof) error {
+	if proof.Height != ctx.BlockHeight() {
+		return fmt.Errorf("mining proof for height %d included at height %d", proof.Height, ctx.BlockHeight())
+	}
+	if prev := k.lastBlockHash(ctx); !bytes.Equal(proof.PrevBlockHash, prev) {
+		return fmt.Errorf("mining proof builds on block %X, not the previous block %X", proof.PrevBlockHash, prev)
+	}
+	return nil
+}
+
+// CheckMiningProof verifies a mining proof for the block of ctx without
+// recording it, and returns its solution hash
+func (k Keeper) CheckMiningProof(ctx sdk.Context, proof types.MiningProof) ([]byte, error) {
+	return k.equihashMining.verifyEquihashProof(ctx, proof)
+}
+
+// CheckMiningProofs verifies mining proofs as CheckMiningProof does, their
+// solutions concurrently on the verifier pool, and returns their solution
+// hashes and errors in the order of proofs. The checks reading the state run
+// on the caller's goroutine, as the context is not safe to share.
+func (k Keeper) CheckMiningProofs(ctx sdk.Context, proofs []types.MiningProof) ([][]byte, []error) {
+	hashes := make([][]byte, len(proofs))
+	errs := make([]error, len(proofs))
+
+	var pending []int
+	var jobs []func() error
+	for i, proof := range proofs {
+		verify, err := k.equihashMining.prepareEquihashProof(ctx, proof)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		i := i
+		pending = append(pending, i)
+		jobs = append(jobs, func() (err error) {
+			hashes[i], err = verify()
+			return err
+		})
+	}
+
+	for j, err := range k.verifiers.Run(jobs) {
+		i := pending[j]
+		if err == nil {
+			err = k.equihashMining.finishEquihashProof(ctx, proofs[i], hashes[i])
+		}
+		if err != nil {
+			hashes[i], errs[i] = nil, err
+		}
+	}
+	return hashes, errs
+}
+
+// Verifiers returns the pool verifying the proofs of proposals, shared by the
+// keeper's copies
+func (k Keeper) Verifiers() *workerpool.Pool {
+	return k.verifiers
+}
+
+// CheckMiningSolution verifies only the Equihash solution of a mining proof
+// against the difficulty target, not whether the proof is for the block of
+// ctx, so an error is the miner's fault rather than a race with the chain. It
+// is run at CheckTx, on the state of the last block committed: a proof for
+// that block or an earlier one is an uncle, solved at the difficulty of its
+// height.
+func (k Keeper) CheckMiningSolution(ctx sdk.Context, proof types.MiningProof) error {
+	difficulty := k.GetDifficulty(ctx)
+	if proof.Height <= ctx.BlockHeight() {
+		if d, found := k.GetBlockDifficulty(ctx, proof.Height); found {
+			difficulty = d
+		}
+	}
+	_, err := k.equihashMining.verifyEquihashSolution(ctx, proof, difficulty)
+	return err
+}
+
+func consumedProofKey(height int64, solutionHash []byte) []byte {
+	return append(sdk.Uint64ToBigEndian(uint64(height)), solutionHash...)
+}
+
+// IsProofConsumed reports whether a proof with the solution hash was accepted
+// at height
+func (k Keeper) IsProofConsumed(ctx sdk.Context, height int64, solutionHash []byte) bool {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ConsumedProofKey)
+	return store.Has(consumedProofKey(height, solutionHash))
+}
+
+// consumeProof records the solution hash of an accepted proof for height so
+// it is never accepted again, neither in its block nor as an uncle
+func (k Keeper) consumeProof(ctx sdk.Context, height int64, solutionHash []byte) error {
+	if k.IsProofConsumed(ctx, height, solutionHash) {
+		return fmt.Errorf("mining proof %X was already submitted", solutionHash)
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ConsumedProofKey)
+	store.Set(consumedProofKey(height, solutionHash), []byte{1})
+	return nil
+}
+
+// pruneConsumedProofs forgets the proofs of the height leaving the
+// ConsumedProofBlocks window
+func (k Keeper) pruneConsumedProofs(ctx sdk.Context) {
+	height := ctx.BlockHeight() - types.ConsumedProofBlocks
+	if height <= 0 {
+		return
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ConsumedProofKey)
+	iterator := store.Iterator(sdk.Uint64ToBigEndian(uint64(height)), sdk.Uint64ToBigEndian(uint64(height+1)))
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// GetBlockProof returns the best mining proof of the current block so far
+func (k Keeper) GetBlockProof(ctx sdk.Context) (types.BlockProof, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.BlockProofKey)
+	if bz == nil {
+		return types.BlockProof{}, false
+	}
+
+	var proof types.BlockProof
+	k.cdc.MustUnmarshal(bz, &proof)
+	return proof, true
+}
+
+// offerBlockProof makes a verified proof the block's winner if it beats the
+// best so far. A proof that does not is refused, so its transaction fails and
+// the miner knows the block went to another.
+func (k Keeper) offerBlockProof(ctx sdk.Context, proof types.BlockProof) error {
+	if best, found := k.GetBlockProof(ctx); found && !proof.Better(best) {
+		return fmt.Errorf("a better mining proof, %X by %s, holds the block", best.SolutionHash, best.MinerAddress)
+	}
+	ctx.KVStore(k.storeKey).Set(types.BlockProofKey, k.cdc.MustMarshal(&proof))
+	return nil
+}
+
+// RewardBlockProof pays the mining reward of the block to its winning proof,
+// if any; its miner also wins the block's fees. The uncles of the block are
+// paid their share, and proofs leaving the replay window are pruned.
+func (k Keeper) RewardBlockProof(ctx sdk.Context) {
+	defer k.pruneConsumedProofs(ctx)
+	defer k.rewardUncles(ctx)
+
+	proof, found := k.GetBlockProof(ctx)
+	if !found {
+		return
+	}
+	ctx.KVStore(k.storeKey).Delete(types.BlockProofKey)
+	k.setBlockWinner(ctx, proof.MinerAddress)
+
+	miner, err := sdk.AccAddressFromBech32(proof.MinerAddress)
+	if err != nil {
+		k.Logger(ctx).Error("Invalid winning miner address", "miner", proof.MinerAddress, "error", err)
+		return
+	}
+
+	// Active miners are included in the next memory audit
+	k.RecordMinedBlock(ctx, proof.MinerAddress, proof.HardwareId)
+
+	if err := k.equihashMining.distributeEquihashReward(ctx, miner, proof.HardwareId); err != nil {
+		k.Logger(ctx).Error("Failed to pay the mining reward", "miner", proof.MinerAddress, "error", err)
+	}
+}
@@ -0,0 +1,202 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/pow"
+	"z-blockchain/x/utxo/types"
+)
+
+// RecordBlockTime stores the time of the current block, with the difficulty
+// in force while it was solved, and drops the block falling out of the
+// retarget window, so the store holds the last RetargetInterval+1 blocks. A
+// block time not after the median time past is recorded just after it, so
+// the time the difficulty adjustment measures never runs backwards.
+func (k Keeper) RecordBlockTime(ctx sdk.Context) {
+	blockTime := ctx.BlockTime().UnixMilli()
+	if mtp, found := k.MedianTimePast(ctx); found && blockTime <= mtp {
+		blockTime = mtp + 1
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockTimeKey)
+	bz := make([]byte, 16)
+	binary.BigEndian.PutUint64(bz, uint64(blockTime))
+	binary.BigEndian.PutUint64(bz[8:], k.GetDifficulty(ctx))
+	store.Set(sdk.Uint64ToBigEndian(uint64(ctx.BlockHeight())), bz)
+
+	if expired := ctx.BlockHeight() - types.RetargetInterval - 1; expired >= 0 {
+		store.Delete(sdk.Uint64ToBigEndian(uint64(expired)))
+	}
+}
+
+// GetBlockTime returns the time, in Unix milliseconds, of a block within the
+// retarget window
+func (k Keeper) GetBlockTime(ctx sdk.Context, height int64) (int64, bool) {
+	if height < 0 {
+		return 0, false
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockTimeKey)
+	bz := store.Get(sdk.Uint64ToBigEndian(uint64(height)))
+	if bz == nil {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(bz)), true
+}
+
+// GetBlockDifficulty returns the difficulty a block within the retarget
+// window was solved at
+func (k Keeper) GetBlockDifficulty(ctx sdk.Context, height int64) (uint64, bool) {
+	if height < 0 {
+		return 0, false
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockTimeKey)
+	bz := store.Get(sdk.Uint64ToBigEndian(uint64(height)))
+	if len(bz) < 16 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(bz[8:]), true
+}
+
+// MedianTimePast returns the median time, in Unix milliseconds, of the last
+// MedianTimeBlocks blocks before the current one. It is not found before the
+// first block is recorded.
+func (k Keeper) MedianTimePast(ctx sdk.Context) (int64, bool) {
+	return k.medianTimePastAt(ctx, ctx.BlockHeight())
+}
+
+// medianTimePastAt returns the median time past of the blocks before height
+func (k Keeper) medianTimePastAt(ctx sdk.Context, height int64) (int64, bool) {
+	span := int64(k.GetParams(ctx).MedianTimeBlocks)
+	var times []int64
+	for h := height - 1; h >= 0 && h >= height-span; h-- {
+		t, found := k.GetBlockTime(ctx, h)
+		if !found {
+			break
+		}
+		times = append(times, t)
+	}
+	if len(times) == 0 {
+		return 0, false
+	}
+	return types.MedianTime(times), true
+}
+
+// CheckSolutionTime checks the timestamp of a mining solution, in Unix
+// milliseconds, against the median time past and the future drift allowed
+// ahead of the block time. Solutions carry the time their Equihash header
+// commits to, so a miner warping it is refused rather than skewing the time
+// the difficulty adjustment measures.
+func (k Keeper) CheckSolutionTime(ctx sdk.Context, timestamp int64) error {
+	mtp, _ := k.MedianTimePast(ctx)
+	return types.CheckSolutionTime(timestamp, mtp, ctx.BlockTime().UnixMilli(), k.GetParams(ctx).MaxFutureDriftMillis)
+}
+
+// checkUncleSolutionTime checks the timestamp of an uncle's solution as
+// CheckSolutionTime did at the height it solved, against the median time past
+// and the time of that block
+func (k Keeper) checkUncleSolutionTime(ctx sdk.Context, height, timestamp int64) error {
+	blockTime, found := k.GetBlockTime(ctx, height)
+	if !found {
+		return fmt.Errorf("time of block %d is not recorded", height)
+	}
+	mtp, _ := k.medianTimePastAt(ctx, height)
+	return types.CheckSolutionTime(timestamp, mtp, blockTime, k.GetParams(ctx).MaxFutureDriftMillis)
+}
+
+// GetBlockTimeRange returns the milliseconds elapsed from block startHeight
+// to block endHeight. When the first blocks of the range are not recorded, on
+// a young chain or after a restart from exported genesis, the time over the
+// recorded part is scaled up to the whole range; with fewer than two recorded
+// blocks the range is assumed to have kept the target block time. The result
+// is at least 1, so it can divide.
+func (k Keeper) GetBlockTimeRange(ctx sdk.Context, startHeight, endHeight int64) int64 {
+	blocks := endHeight - startHeight
+	if blocks <= 0 {
+		return 1
+	}
+
+	end, found := k.GetBlockTime(ctx, endHeight)
+	if !found {
+		return k.targetBlockTimeRange(ctx, blocks)
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockTimeKey)
+	iterator := store.Iterator(sdk.Uint64ToBigEndian(uint64(max(startHeight, 0))), sdk.Uint64ToBigEndian(uint64(endHeight)))
+	defer iterator.Close()
+	if !iterator.Valid() {
+		return k.targetBlockTimeRange(ctx, blocks)
+	}
+	first := int64(binary.BigEndian.Uint64(iterator.Key()))
+	start := int64(binary.BigEndian.Uint64(iterator.Value()[:8]))
+
+	elapsed := (end - start) * blocks / (endHeight - first)
+	if elapsed < 1 {
+		return 1
+	}
+	return elapsed
+}
+
+// targetBlockTimeRange is the time blocks take at the target block time
+func (k Keeper) targetBlockTimeRange(ctx sdk.Context, blocks int64) int64 {
+	if elapsed := k.GetParams(ctx).TargetBlockMillis * blocks; elapsed > 0 {
+		return elapsed
+	}
+	return 1
+}
+
+// AdjustDifficultyLWMA sets the difficulty of the next block from the LWMA of
+// the blocks in the window ending at the current one, within the min and max
+// difficulty params. A chain younger than the window averages the blocks it
+// has; with none solved yet the difficulty stays.
+func (k Keeper) AdjustDifficultyLWMA(ctx sdk.Context) {
+	params := k.GetParams(ctx)
+	height := ctx.BlockHeight()
+
+	// The oldest recorded block of the window opens it
+	var times []int64
+	var difficulties []uint64
+	for h := height - int64(params.LwmaWindowBlocks); h <= height; h++ {
+		t, found := k.GetBlockTime(ctx, h)
+		if !found {
+			times, difficulties = times[:0], difficulties[:0]
+			continue
+		}
+		if len(times) > 0 {
+			difficulty, found := k.GetBlockDifficulty(ctx, h)
+			if !found {
+				times, difficulties = times[:0], difficulties[:0]
+				continue
+			}
+			difficulties = append(difficulties, difficulty)
+		}
+		times = append(times, t)
+	}
+
+	next := types.LWMADifficulty(times, difficulties, params.TargetBlockMillis)
+	if next == 0 {
+		return
+	}
+	next = pow.ClampDifficulty(next, params.MinDifficulty, params.MaxDifficulty)
+
+	current := k.GetDifficulty(ctx)
+	if next == current {
+		return
+	}
+	k.SetDifficulty(ctx, next)
+	k.recordDifficultyRetarget(ctx, types.DifficultyAlgorithmLWMA, current, next,
+		times[len(times)-1]-times[0], params.TargetBlockMillis*int64(len(difficulties)))
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeDifficultyAdjust,
+			sdk.NewAttribute(types.AttributeKeyOldDifficulty, sdk.NewUint(current).String()),
+			sdk.NewAttribute(types.AttributeKeyNewDifficulty, sdk.NewUint(next).String()),
+			sdk.NewAttribute(types.AttributeKeyBlockHeight, sdk.NewInt(height).String()),
+		),
+	)
+}
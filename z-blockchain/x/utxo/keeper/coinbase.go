@@ -0,0 +1,116 @@
+package keeper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/address"
+	"z-blockchain/x/utxo/types"
+)
+
+// PayCoinbase mints a mining reward, within the hard cap, and pays it to
+// miner as a coinbase output after the treasury tax, returning the amount
+// paid. The miner rewarded in a block wins its fees, even once the cap
+// leaves no reward to mint.
+func (k Keeper) PayCoinbase(ctx sdk.Context, miner sdk.AccAddress, reward sdk.Int) (sdk.Int, error) {
+	amount, err := k.mintReward(ctx, reward)
+	if err != nil {
+		return sdk.ZeroInt(), err
+	}
+	if amount, err = k.payTreasuryTax(ctx, miner, amount); err != nil {
+		return sdk.ZeroInt(), err
+	}
+
+	if amount.IsPositive() {
+		if err := k.payCoinbase(ctx, miner, amount); err != nil {
+			return sdk.ZeroInt(), err
+		}
+	}
+	k.setBlockMiner(ctx, miner)
+	return amount, nil
+}
+
+// payCoinbase pays amount to miner as coinbase outputs: one to the miner, or
+// one per payout address if it set a payout split. The coinbase outputs of a
+// miner in a block are the outputs of one coinbase transaction, in the order
+// they are paid.
+func (k Keeper) payCoinbase(ctx sdk.Context, miner sdk.AccAddress, amount sdk.Int) error {
+	split, found := k.GetPayoutSplit(ctx, miner.String())
+	if !found {
+		return k.payCoinbaseOutput(ctx, miner, miner, amount)
+	}
+
+	for i, share := range split.Amounts(amount) {
+		if !share.IsPositive() {
+			continue
+		}
+		recipient, err := sdk.AccAddressFromBech32(split.Shares[i].Address)
+		if err != nil {
+			return err
+		}
+		if err := k.payCoinbaseOutput(ctx, miner, recipient, share); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// payCoinbaseOutput adds an output paying amount to recipient to the coinbase
+// transaction of miner
+func (k Keeper) payCoinbaseOutput(ctx sdk.Context, miner, recipient sdk.AccAddress, amount sdk.Int) error {
+	owner, err := address.FromBytes(recipient)
+	if err != nil {
+		return err
+	}
+
+	txHash := coinbaseTxHash(ctx.BlockHeight(), miner)
+	tx, found := k.GetTransaction(ctx, txHash)
+	if !found {
+		tx = types.UTXOTransaction{
+			TxHash:    txHash,
+			Timestamp: ctx.BlockTime().Unix(),
+			Fee:       "0",
+		}
+	}
+	output := types.TxOutput{Amount: amount.String(), Address: owner.ZChain()}
+	tx.Outputs = append(tx.Outputs, &output)
+	k.SetTransaction(ctx, tx)
+
+	utxo := types.UTXO{
+		TxHash:      txHash,
+		OutputIndex: uint32(len(tx.Outputs) - 1),
+		Address:     output.Address,
+		Amount:      output.Amount,
+		BlockHeight: ctx.BlockHeight(),
+		CreatedAt:   ctx.BlockTime().Unix(),
+		Coinbase:    true,
+	}
+	k.SetUTXO(ctx, utxo)
+	k.emitUTXOCreated(ctx, utxo)
+	return nil
+}
+
+// checkCoinbaseMaturity rejects spending a coinbase output younger than the
+// coinbase maturity. A reorg below the reward's block would void the reward
+// and every spend of it, so the rewards must be buried deep enough first.
+func (k Keeper) checkCoinbaseMaturity(ctx sdk.Context, utxo types.UTXO) error {
+	if !utxo.Coinbase {
+		return nil
+	}
+
+	maturity := k.GetParams(ctx).CoinbaseMaturity
+	if confirmations := ctx.BlockHeight() - utxo.BlockHeight; confirmations < maturity {
+		return fmt.Errorf("coinbase UTXO %s:%d is immature: %d of %d confirmations",
+			utxo.TxHash, utxo.OutputIndex, confirmations, maturity)
+	}
+	return nil
+}
+
+// coinbaseTxHash identifies the coinbase transaction paying miner at height
+func coinbaseTxHash(height int64, miner sdk.AccAddress) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("coinbase:%d:%s", height, miner)))
+	return hex.EncodeToString(hash[:])
+}
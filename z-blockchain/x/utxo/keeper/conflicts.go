@@ -0,0 +1,58 @@
+package keeper
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// A transaction refused for a double spend leaves no state and no events, as
+// everything it wrote is discarded with it. The conflicts of a block are kept
+// in memory instead, shared by the keeper's copies, and emitted at its end.
+// Only delivered transactions are recorded, which every validator sees the
+// same; proposals are checked before BeginBlocker clears the queue.
+type conflictQueue struct {
+	mu        sync.Mutex
+	conflicts []types.EventUTXOConflict
+}
+
+// RecordConflict queues the conflict of the transaction txHash, refused for
+// spending an output another transaction spent, for EmitConflicts. Conflicts
+// met at CheckTx are not recorded; the error tells the sender.
+func (k Keeper) RecordConflict(ctx sdk.Context, txHash string, conflict *types.ConflictError) {
+	if ctx.IsCheckTx() || ctx.IsReCheckTx() {
+		return
+	}
+
+	k.conflicts.mu.Lock()
+	defer k.conflicts.mu.Unlock()
+	k.conflicts.conflicts = append(k.conflicts.conflicts, types.EventUTXOConflict{
+		TxHash:            txHash,
+		Outpoint:          outpoint(conflict.TxHash, conflict.OutputIndex),
+		PrevTxHash:        conflict.TxHash,
+		PrevOutputIndex:   conflict.OutputIndex,
+		ConflictingTxHash: conflict.SpentBy,
+		BlockHeight:       ctx.BlockHeight(),
+	})
+}
+
+// ResetConflicts drops the queued conflicts, at the start of a block
+func (k Keeper) ResetConflicts() {
+	k.conflicts.mu.Lock()
+	defer k.conflicts.mu.Unlock()
+	k.conflicts.conflicts = nil
+}
+
+// EmitConflicts emits the typed event of every conflict queued in the block
+func (k Keeper) EmitConflicts(ctx sdk.Context) {
+	k.conflicts.mu.Lock()
+	conflicts := k.conflicts.conflicts
+	k.conflicts.conflicts = nil
+	k.conflicts.mu.Unlock()
+
+	for i := range conflicts {
+		k.emitTypedEvent(ctx, &conflicts[i])
+	}
+}
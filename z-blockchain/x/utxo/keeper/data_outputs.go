@@ -0,0 +1,44 @@
+package keeper
+
+import (
+	"encoding/binary"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/script"
+	"z-blockchain/x/utxo/types"
+)
+
+// setDataOutput indexes the data output at index of the transaction txHash if
+// scriptPubkey carries data
+func (k Keeper) setDataOutput(ctx sdk.Context, txHash string, index uint32, scriptPubkey []byte) {
+	data, ok := script.NullDataPayload(scriptPubkey)
+	if !ok {
+		return
+	}
+	k.SetDataOutput(ctx, types.DataOutput{
+		TxHash:      txHash,
+		OutputIndex: index,
+		Data:        data,
+		BlockHeight: ctx.BlockHeight(),
+	})
+}
+
+// SetDataOutput stores a data output under its data
+func (k Keeper) SetDataOutput(ctx sdk.Context, output types.DataOutput) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DataOutputKey)
+	store.Set(dataOutputKey(output), k.cdc.MustMarshal(&output))
+}
+
+// dataOutputKey is the key of a data output under DataOutputKey: its data,
+// then its outpoint. Transaction hashes are all 64 hex characters, so the
+// outpoint is of fixed size at the end of the key and no two outputs share
+// one, while the key starts with the data for prefix lookups.
+func dataOutputKey(output types.DataOutput) []byte {
+	key := make([]byte, 0, len(output.Data)+len(output.TxHash)+4)
+	key = append(key, output.Data...)
+	key = append(key, output.TxHash...)
+	return binary.BigEndian.AppendUint32(key, output.OutputIndex)
+}
@@ -0,0 +1,66 @@
+package keeper
+
+import (
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// GetDifficultyRetarget returns the retarget of a height within the
+// difficulty history
+func (k Keeper) GetDifficultyRetarget(ctx sdk.Context, height int64) (types.DifficultyRetarget, bool) {
+	if height < 0 {
+		return types.DifficultyRetarget{}, false
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyRetargetKey)
+	bz := store.Get(sdk.Uint64ToBigEndian(uint64(height)))
+	if bz == nil {
+		return types.DifficultyRetarget{}, false
+	}
+
+	var retarget types.DifficultyRetarget
+	k.cdc.MustUnmarshal(bz, &retarget)
+	return retarget, true
+}
+
+// recordDifficultyRetarget adds a change of the difficulty at the current
+// height to the history, drops the retargets older than
+// DifficultyHistoryBlocks and emits the typed event
+func (k Keeper) recordDifficultyRetarget(ctx sdk.Context, algorithm string, oldDifficulty, newDifficulty uint64, actualMillis, targetMillis int64) {
+	retarget := types.DifficultyRetarget{
+		Height:        ctx.BlockHeight(),
+		Algorithm:     algorithm,
+		OldDifficulty: oldDifficulty,
+		NewDifficulty: newDifficulty,
+		ActualMillis:  actualMillis,
+		TargetMillis:  targetMillis,
+		Time:          ctx.BlockTime().UnixMilli(),
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyRetargetKey)
+	store.Set(sdk.Uint64ToBigEndian(uint64(retarget.Height)), k.cdc.MustMarshal(&retarget))
+
+	if expired := retarget.Height - types.DifficultyHistoryBlocks; expired > 0 {
+		iterator := store.Iterator(nil, sdk.Uint64ToBigEndian(uint64(expired)))
+		var keys [][]byte
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, iterator.Key())
+		}
+		iterator.Close()
+		for _, key := range keys {
+			store.Delete(key)
+		}
+	}
+
+	k.emitTypedEvent(ctx, &types.EventDifficultyRetarget{
+		Height:             retarget.Height,
+		Algorithm:          algorithm,
+		OldDifficulty:      oldDifficulty,
+		NewDifficulty:      newDifficulty,
+		ActualMillis:       actualMillis,
+		TargetMillis:       targetMillis,
+		NextRetargetHeight: types.NextRetargetHeight(algorithm, retarget.Height),
+	})
+}
@@ -0,0 +1,58 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/pow"
+	"z-blockchain/x/utxo/types"
+)
+
+// GetMintedSupply returns the total of the mining rewards minted so far
+func (k Keeper) GetMintedSupply(ctx sdk.Context) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(types.MintedSupplyKey)
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	return intParam(string(bz))
+}
+
+func (k Keeper) setMintedSupply(ctx sdk.Context, minted sdk.Int) {
+	ctx.KVStore(k.storeKey).Set(types.MintedSupplyKey, []byte(minted.String()))
+}
+
+// CirculatingSupply returns the minted supply less the fees burned, which
+// left the UTXO set for good
+func (k Keeper) CirculatingSupply(ctx sdk.Context) sdk.Int {
+	circulating := k.GetMintedSupply(ctx).Sub(k.GetBurnedFees(ctx))
+	if circulating.IsNegative() {
+		return sdk.ZeroInt()
+	}
+	return circulating
+}
+
+// NextBlockReward returns the reward the next block pays, less once the
+// hard cap is near and zero at it
+func (k Keeper) NextBlockReward(ctx sdk.Context) sdk.Int {
+	emission := pow.DefaultEmission()
+	reward := emission.Schedule.Reward(ctx.BlockHeight() + 1)
+	return sdk.NewIntFromBigInt(emission.Mintable(k.GetMintedSupply(ctx).BigInt(), reward))
+}
+
+// mintReward mints a mining reward to the module account and returns what
+// was minted: the reward, cut down to what is left under the hard cap of
+// the emission. Every reward is minted here, so the minted supply accounts
+// for all of them.
+func (k Keeper) mintReward(ctx sdk.Context, reward sdk.Int) (sdk.Int, error) {
+	minted := k.GetMintedSupply(ctx)
+	amount := sdk.NewIntFromBigInt(pow.DefaultEmission().Mintable(minted.BigInt(), reward.BigInt()))
+	if !amount.IsPositive() {
+		return sdk.ZeroInt(), nil
+	}
+
+	coins := sdk.NewCoins(sdk.NewCoin("z", amount))
+	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
+		return sdk.ZeroInt(), err
+	}
+	k.setMintedSupply(ctx, minted.Add(amount))
+	return amount, nil
+}
@@ -1,206 +1,239 @@
 package keeper
 
 import (
-	"crypto/rand"
 	"encoding/binary"
 	"fmt"
-	"math/big"
 	"time"
 	
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"shared/pow"
+	"z-blockchain/x/utxo/equihash"
+	"z-blockchain/x/utxo/proofcache"
 	"z-blockchain/x/utxo/types"
-	
-	// Hypothetical Equihash library - replace with actual implementation
-	"github.com/zcash/librustzcash-go" // or similar Equihash library
 )
 
 // EquihashMiningKeeper handles Equihash 144_5 mining operations
 type EquihashMiningKeeper struct {
 	*Keeper
-	currentDifficulty *big.Int
-	targetBlockTime   time.Duration
-	asicResistance    bool
+	targetBlockTime time.Duration
+	asicResistance  bool
 }
 
 // NewEquihashMiningKeeper creates a new Equihash mining keeper
 func NewEquihashMiningKeeper(k *Keeper) *EquihashMiningKeeper {
 	return &EquihashMiningKeeper{
-		Keeper:            k,
-		currentDifficulty: big.NewInt(1000000), // Initial difficulty
-		targetBlockTime:   500 * time.Millisecond, // 0.5 second blocks
-		asicResistance:    true,
+		Keeper:          k,
+		targetBlockTime: 500 * time.Millisecond, // 0.5 second blocks
+		asicResistance:  true,
 	}
 }
 
-// ProcessEquihashMining processes an Equihash mining submission
+// ProcessEquihashMining processes an Equihash mining submission. A valid
+// proof is not paid at once: it competes for the block, and RewardBlockProof
+// pays the best one when the block ends. A proof for a recent height is an
+// uncle instead, paid its share alongside.
 func (k *EquihashMiningKeeper) ProcessEquihashMining(ctx sdk.Context, proof types.MiningProof) error {
-	// Verify this is Equihash mining
-	if proof.HardwareId == "" {
-		return fmt.Errorf("hardware ID required for ASIC resistance verification")
-	}
-	
-	// Create Equihash header from current block
-	header := k.createEquihashHeader(ctx, proof)
-	
-	// Parse Equihash solution from proof
-	solution, err := k.parseEquihashSolution(proof.ZkProof)
+	solutionHash, err := k.verifyEquihashProof(ctx, proof)
 	if err != nil {
-		return fmt.Errorf("invalid Equihash solution: %w", err)
+		return err
 	}
 	
-	// Verify Equihash 144_5 solution
-	if !types.VerifyEquihashSolution(header, solution) {
-		return fmt.Errorf("invalid Equihash 144_5 solution")
+	// A solution is accepted once, even when it loses the block
+	if err := k.consumeProof(ctx, proof.Height, solutionHash); err != nil {
+		return err
 	}
+	k.countProof(ctx, ProofKindMining)
 	
-	// Check difficulty target
-	if !k.checkDifficultyTarget(header, solution) {
-		return fmt.Errorf("solution does not meet difficulty target")
+	blockProof := types.BlockProof{
+		MinerAddress: proof.MinerAddress,
+		HardwareId:   proof.HardwareId,
+		SolutionHash: solutionHash,
+		Height:       proof.Height,
 	}
-	
-	// Verify ASIC resistance
-	if !k.verifyASICResistance(proof.HardwareId) {
-		return fmt.Errorf("mining setup is not ASIC resistant")
+	if proof.Height < ctx.BlockHeight() {
+		return k.addUncle(ctx, blockProof)
 	}
-	
-	// Distribute mining reward
-	miner, err := sdk.AccAddressFromBech32(proof.MinerAddress)
+	return k.offerBlockProof(ctx, blockProof)
+}
+
+// verifyEquihashProof checks a mining proof against the block of ctx and
+// returns its solution hash. It writes nothing, so proposals are checked
+// with it too.
+func (k *EquihashMiningKeeper) verifyEquihashProof(ctx sdk.Context, proof types.MiningProof) ([]byte, error) {
+	verify, err := k.prepareEquihashProof(ctx, proof)
 	if err != nil {
-		return fmt.Errorf("invalid miner address: %w", err)
+		return nil, err
 	}
-	
-	return k.distributeEquihashReward(ctx, miner, proof.HardwareId)
+	solutionHash, err := verify()
+	if err != nil {
+		return nil, err
+	}
+	if err := k.finishEquihashProof(ctx, proof, solutionHash); err != nil {
+		return nil, err
+	}
+	return solutionHash, nil
 }
 
-// createEquihashHeader creates an Equihash header from current block context
-func (k *EquihashMiningKeeper) createEquihashHeader(ctx sdk.Context, proof types.MiningProof) *types.EquihashHeader {
-	blockHeader := ctx.BlockHeader()
+// prepareEquihashProof runs the checks of a mining proof before its solution,
+// reading the state of ctx, and returns the check of the solution, which
+// reads none and so may run on another goroutine
+func (k *EquihashMiningKeeper) prepareEquihashProof(ctx sdk.Context, proof types.MiningProof) (func() ([]byte, error), error) {
+	// Verify this is Equihash mining
+	if proof.HardwareId == "" {
+		return nil, fmt.Errorf("hardware ID required for ASIC resistance verification")
+	}
 	
-	return &types.EquihashHeader{
-		Version:       1,
-		PrevBlockHash: blockHeader.LastBlockId.Hash,
-		MerkleRoot:    blockHeader.DataHash,
-		Timestamp:     uint32(ctx.BlockTime().Unix()),
-		Bits:          types.CalculateEquihashDifficulty(k.currentDifficulty),
-		Nonce:         proof.Nonce,
-		Solution:      []uint32{}, // Will be filled from proof
+	// Only a proof made for this block counts, or one for a recent block as
+	// an uncle, solved at the difficulty of its height
+	difficulty := k.GetDifficulty(ctx)
+	if proof.Height < ctx.BlockHeight() {
+		if err := k.checkUncleBinding(ctx, proof); err != nil {
+			return nil, err
+		}
+		d, err := k.uncleDifficulty(ctx, proof.Height)
+		if err != nil {
+			return nil, err
+		}
+		difficulty = d
+	} else {
+		if err := k.checkProofBinding(ctx, proof); err != nil {
+			return nil, err
+		}
+		
+		// The solution's time must follow the chain's, not the miner's clock
+		if err := k.CheckSolutionTime(ctx, proof.Timestamp); err != nil {
+			return nil, err
+		}
 	}
+	
+	return k.prepareEquihashSolution(ctx, proof, difficulty)
 }
 
-// parseEquihashSolution parses Equihash solution from zk-proof bytes
-func (k *EquihashMiningKeeper) parseEquihashSolution(zkProof []byte) (*types.EquihashSolution, error) {
-	if len(zkProof) < 8 { // At least nonce
-		return nil, fmt.Errorf("proof too short")
+// finishEquihashProof runs the checks of a mining proof after its solution
+// hashed to solutionHash
+func (k *EquihashMiningKeeper) finishEquihashProof(ctx sdk.Context, proof types.MiningProof, solutionHash []byte) error {
+	// The winner of the uncle's height, or an uncle already included, is
+	// not paid again
+	if proof.Height < ctx.BlockHeight() && k.IsProofConsumed(ctx, proof.Height, solutionHash) {
+		return fmt.Errorf("mining proof %X for height %d was already included", solutionHash, proof.Height)
 	}
 	
-	// Extract nonce (first 8 bytes)
-	nonce := binary.LittleEndian.Uint64(zkProof[:8])
-	
-	// Extract solution indices (remaining bytes)
-	solutionBytes := zkProof[8:]
-	if len(solutionBytes) != types.SolutionWidth*4 { // 32 indices * 4 bytes each
-		return nil, fmt.Errorf("invalid solution length")
+	// Only registered, eligible device classes mine; known ASICs are barred.
+	// Merged mined proofs are Altcoinchain's work, mined on its hardware.
+	if len(proof.AuxPow) == 0 && !k.HardwareEligible(ctx, proof.HardwareId) {
+		return fmt.Errorf("device class %s is not eligible for mining", proof.HardwareId)
 	}
 	
-	solution := make([]uint32, types.SolutionWidth)
-	for i := 0; i < types.SolutionWidth; i++ {
-		solution[i] = binary.LittleEndian.Uint32(solutionBytes[i*4 : (i+1)*4])
+	if _, err := sdk.AccAddressFromBech32(proof.MinerAddress); err != nil {
+		return fmt.Errorf("invalid miner address: %w", err)
 	}
 	
-	return &types.EquihashSolution{
-		Nonce:     nonce,
-		Solution:  solution,
-		Timestamp: time.Now().Unix(),
-	}, nil
+	return nil
 }
 
-// checkDifficultyTarget verifies the solution meets the difficulty target
-func (k *EquihashMiningKeeper) checkDifficultyTarget(header *types.EquihashHeader, solution *types.EquihashSolution) bool {
-	// Calculate hash of the solution
-	solutionHash := k.calculateSolutionHash(header, solution)
-	
-	// Convert to big integer
-	hashInt := new(big.Int).SetBytes(solutionHash)
-	
-	// Check if hash is less than target (lower hash = higher difficulty)
-	target := types.GetEquihashTarget(header.Bits)
-	return hashInt.Cmp(target) <= 0
+// verifyEquihashSolution checks the solution of a mining proof, with the
+// algorithm of the PowAlgorithm param or, for a merged mined proof, the
+// Altcoinchain header standing in for it, meets the target of difficulty and
+// returns its hash. Unlike the rest of the proof, which goes stale as blocks
+// pass, a solution is invalid for good, so its failures are what a miner's
+// ban score counts.
+func (k *EquihashMiningKeeper) verifyEquihashSolution(ctx sdk.Context, proof types.MiningProof, difficulty uint64) ([]byte, error) {
+	verify, err := k.prepareEquihashSolution(ctx, proof, difficulty)
+	if err != nil {
+		return nil, err
+	}
+	return verify()
 }
 
-// calculateSolutionHash calculates the hash of the Equihash solution
-func (k *EquihashMiningKeeper) calculateSolutionHash(header *types.EquihashHeader, solution *types.EquihashSolution) []byte {
-	// Combine header and solution for final hash
-	challenge := types.GenerateEquihashChallenge(header)
-	
-	// Add solution to challenge
-	solutionBytes := make([]byte, len(solution.Solution)*4)
-	for i, index := range solution.Solution {
-		binary.LittleEndian.PutUint32(solutionBytes[i*4:], index)
+// prepareEquihashSolution reads the params verifyEquihashSolution checks the
+// solution with and returns the check itself
+func (k *EquihashMiningKeeper) prepareEquihashSolution(ctx sdk.Context, proof types.MiningProof, difficulty uint64) (func() ([]byte, error), error) {
+	pow := k.ProofOfWork(ctx)
+	if len(proof.AuxPow) > 0 {
+		merged, err := k.mergedMining(ctx, pow)
+		if err != nil {
+			return nil, err
+		}
+		pow = merged
 	}
 	
-	finalData := append(challenge, solutionBytes...)
-	
-	// Use Blake2b hash (like Zcash) for final hash
-	return k.blake2bHash(finalData)
+	return func() ([]byte, error) {
+		// The challenge commits to the previous block, which miners know
+		// while solving and the binding checks have matched, and the
+		// difficulty the Difficulty query reported
+		challenge := pow.PrepareChallenge(proof, difficulty)
+		
+		// A solution checked at CheckTx is not checked again in its block
+		key := solutionKey(pow, challenge, proof)
+		if result, ok := k.solutions.Get(key); ok {
+			return result.Hash, result.Err
+		}
+		start := time.Now()
+		solutionHash, err := checkSolution(pow, challenge, proof, difficulty)
+		MeasureProofVerification(ProofKindMining, start)
+		k.solutions.Add(key, proofcache.Result{Hash: solutionHash, Err: err})
+		return solutionHash, err
+	}, nil
 }
 
-// blake2bHash computes Blake2b hash (Zcash-compatible)
-func (k *EquihashMiningKeeper) blake2bHash(data []byte) []byte {
-	// Simplified Blake2b implementation
-	// In production, use actual Blake2b library
-	hash := make([]byte, 32)
-	copy(hash, data[:min(32, len(data))])
-	return hash
+// checkSolution verifies the solution of proof solves challenge with pow and
+// meets the target of difficulty
+func checkSolution(pow types.ProofOfWork, challenge []byte, proof types.MiningProof, difficulty uint64) ([]byte, error) {
+	solutionHash, err := pow.VerifySolution(challenge, proof)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Check difficulty target
+	if !types.MeetsTarget(solutionHash, pow.Target(difficulty)) {
+		return nil, fmt.Errorf("solution does not meet difficulty target")
+	}
+	
+	return solutionHash, nil
 }
 
-// verifyASICResistance checks if the mining setup is ASIC resistant
-func (k *EquihashMiningKeeper) verifyASICResistance(hardwareId string) bool {
-	// Check against known ASIC hardware IDs
-	asicDevices := map[string]bool{
-		"antminer-z9":    false, // Known Equihash ASIC
-		"innosilicon-a9": false, // Known Equihash ASIC
+// parseEquihashSolution parses Equihash solution from zk-proof bytes, found at
+// timestamp in Unix milliseconds
+func (k *EquihashMiningKeeper) parseEquihashSolution(params equihash.Params, zkProof []byte, timestamp int64) (*types.EquihashSolution, error) {
+	if len(zkProof) < 8 { // At least nonce
+		return nil, fmt.Errorf("proof too short")
 	}
 	
-	if !asicDevices[hardwareId] && asicDevices[hardwareId] != true {
-		// Unknown device, check if it's GPU-based
-		gpuDevices := map[string]bool{
-			"nvidia-rtx-3080":  true,
-			"nvidia-rtx-3090":  true,
-			"nvidia-rtx-4080":  true,
-			"nvidia-rtx-4090":  true,
-			"amd-rx-6800-xt":   true,
-			"amd-rx-6900-xt":   true,
-			"amd-rx-7800-xt":   true,
-			"amd-rx-7900-xtx":  true,
-		}
-		
-		return gpuDevices[hardwareId]
+	// Extract nonce (first 8 bytes)
+	nonce := binary.LittleEndian.Uint64(zkProof[:8])
+	
+	// Extract solution indices (remaining bytes), minimally encoded as
+	// solvers output them: 2^K indices of N/(K+1)+1 bits
+	solution, err := equihash.IndicesFromMinimal(params, zkProof[8:])
+	if err != nil {
+		return nil, err
 	}
 	
-	return false // Known ASIC device
+	return &types.EquihashSolution{
+		Nonce:     nonce,
+		Solution:  solution,
+		Timestamp: timestamp / 1000,
+	}, nil
 }
 
 // distributeEquihashReward distributes rewards for Equihash mining
 func (k *EquihashMiningKeeper) distributeEquihashReward(ctx sdk.Context, miner sdk.AccAddress, hardwareId string) error {
 	baseReward := k.CalculateBlockReward(ctx.BlockHeight())
 	
-	// GPU bonus for ASIC resistance
-	gpuBonus := k.getGPUBonus(hardwareId)
-	totalReward := baseReward.Add(gpuBonus)
+	// Bonus of the device class, unless the miner's audits flagged it
+	totalReward := baseReward.Add(k.MinerHardwareBonus(ctx, miner.String(), hardwareId))
 	
-	// Mint Z tokens
-	coins := sdk.NewCoins(sdk.NewCoin("z", totalReward))
-	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
-		return err
-	}
+	// De-rate miners whose memory audits point to memory-constrained hardware
+	totalReward = k.MemoryFactor(ctx, miner.String()).MulInt(totalReward).TruncateInt()
 	
-	// Send to miner
-	if err := k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, miner, coins); err != nil {
+	// Pay as a coinbase output, spendable once mature
+	totalReward, err := k.PayCoinbase(ctx, miner, totalReward)
+	if err != nil {
 		return err
 	}
 	
 	// Update mining statistics
-	k.updateEquihashStats(ctx, miner, hardwareId, totalReward)
+	k.UpdateMiningStats(ctx, miner, hardwareId, totalReward)
 	
 	// Notify nuChain of Equihash mining activity
 	if err := k.notifyNuChainEquihashMining(ctx, miner, totalReward, hardwareId); err != nil {
@@ -210,38 +243,9 @@ func (k *EquihashMiningKeeper) distributeEquihashReward(ctx sdk.Context, miner s
 	return nil
 }
 
-// getGPUBonus returns bonus reward for GPU mining (ASIC resistance incentive)
-func (k *EquihashMiningKeeper) getGPUBonus(hardwareId string) sdk.Int {
-	gpuBonuses := map[string]int64{
-		"nvidia-rtx-3080":  2000000000000000,  // 0.002 Z bonus
-		"nvidia-rtx-3090":  3000000000000000,  // 0.003 Z bonus
-		"nvidia-rtx-4080":  4000000000000000,  // 0.004 Z bonus
-		"nvidia-rtx-4090":  5000000000000000,  // 0.005 Z bonus
-		"amd-rx-6800-xt":   2500000000000000,  // 0.0025 Z bonus
-		"amd-rx-6900-xt":   3500000000000000,  // 0.0035 Z bonus
-		"amd-rx-7800-xt":   4500000000000000,  // 0.0045 Z bonus
-		"amd-rx-7900-xtx":  5500000000000000,  // 0.0055 Z bonus
-	}
-	
-	if bonus, exists := gpuBonuses[hardwareId]; exists {
-		return sdk.NewInt(bonus)
-	}
-	
-	return sdk.ZeroInt()
-}
-
-// updateEquihashStats updates Equihash mining statistics
-func (k *EquihashMiningKeeper) updateEquihashStats(ctx sdk.Context, miner sdk.AccAddress, hardwareId string, reward sdk.Int) {
-	k.logger.Info("Equihash mining reward distributed",
-		"miner", miner.String(),
-		"hardware", hardwareId,
-		"reward", reward.String(),
-		"block_height", ctx.BlockHeight(),
-		"algorithm", "equihash_144_5")
-}
-
 // notifyNuChainEquihashMining sends Equihash mining notification to nuChain
 func (k *EquihashMiningKeeper) notifyNuChainEquihashMining(ctx sdk.Context, miner sdk.AccAddress, reward sdk.Int, hardwareId string) error {
+	k.countCrossChainMessage(ctx)
 	// This would use LayerZero to send cross-chain message
 	k.logger.Info("Equihash mining notification sent to nuChain",
 		"miner", miner.String(),
@@ -259,49 +263,34 @@ func (k *EquihashMiningKeeper) AdjustEquihashDifficulty(ctx sdk.Context) {
 	currentHeight := ctx.BlockHeight()
 	
 	// Adjust difficulty every 2016 blocks (like Bitcoin/Zcash)
-	if currentHeight%2016 != 0 {
+	if currentHeight%types.RetargetInterval != 0 {
 		return
 	}
 	
 	// Calculate actual time for last 2016 blocks
-	actualTime := k.getBlockTimeRange(ctx, currentHeight-2016, currentHeight)
-	targetTime := int64(k.targetBlockTime.Milliseconds()) * 2016
-	
-	// Calculate new difficulty
-	oldDifficulty := new(big.Int).Set(k.currentDifficulty)
-	
-	// newDifficulty = oldDifficulty * targetTime / actualTime
-	k.currentDifficulty.Mul(k.currentDifficulty, big.NewInt(targetTime))
-	k.currentDifficulty.Div(k.currentDifficulty, big.NewInt(actualTime))
+	actualTime := k.GetBlockTimeRange(ctx, currentHeight-types.RetargetInterval, currentHeight)
+	targetTime := int64(k.targetBlockTime.Milliseconds()) * types.RetargetInterval
 	
-	// Limit adjustment to 4x increase or 1/4 decrease (like Bitcoin)
-	maxIncrease := new(big.Int).Mul(oldDifficulty, big.NewInt(4))
-	maxDecrease := new(big.Int).Div(oldDifficulty, big.NewInt(4))
-	
-	if k.currentDifficulty.Cmp(maxIncrease) > 0 {
-		k.currentDifficulty.Set(maxIncrease)
-	} else if k.currentDifficulty.Cmp(maxDecrease) < 0 {
-		k.currentDifficulty.Set(maxDecrease)
-	}
+	// Retarget the difficulty in force, within 4x either way (like Bitcoin)
+	// and the governance bounds
+	params := k.GetParams(ctx)
+	oldDifficulty := k.GetDifficulty(ctx)
+	newDifficulty := pow.ClampDifficulty(pow.Retarget(oldDifficulty, actualTime, targetTime), params.MinDifficulty, params.MaxDifficulty)
 	
 	// Store new difficulty
-	k.SetDifficulty(ctx, k.currentDifficulty.Uint64())
+	k.SetDifficulty(ctx, newDifficulty)
+	if newDifficulty != oldDifficulty {
+		k.recordDifficultyRetarget(ctx, types.DifficultyAlgorithmRetarget, oldDifficulty, newDifficulty, actualTime, targetTime)
+	}
 	
 	k.logger.Info("Equihash difficulty adjusted",
-		"old_difficulty", oldDifficulty.String(),
-		"new_difficulty", k.currentDifficulty.String(),
+		"old_difficulty", oldDifficulty,
+		"new_difficulty", newDifficulty,
 		"block_height", currentHeight,
 		"actual_time_ms", actualTime,
 		"target_time_ms", targetTime)
 }
 
-// getBlockTimeRange calculates actual time between block heights
-func (k *EquihashMiningKeeper) getBlockTimeRange(ctx sdk.Context, startHeight, endHeight int64) int64 {
-	// In a real implementation, this would query historical block times
-	// For now, return target time as approximation
-	return int64(k.targetBlockTime.Milliseconds()) * (endHeight - startHeight)
-}
-
 // Helper function
 func min(a, b int) int {
 	if a < b {
@@ -0,0 +1,65 @@
+package keeper
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cosmos/gogoproto/proto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/htlc"
+	"z-blockchain/x/utxo/types"
+)
+
+// emitUTXOCreated emits the typed event of an output added to the UTXO set
+func (k Keeper) emitUTXOCreated(ctx sdk.Context, utxo types.UTXO) {
+	k.emitTypedEvent(ctx, &types.EventUTXOCreated{
+		Outpoint:    outpoint(utxo.TxHash, utxo.OutputIndex),
+		TxHash:      utxo.TxHash,
+		OutputIndex: utxo.OutputIndex,
+		Address:     utxo.Address,
+		Amount:      utxo.Amount,
+		BlockHeight: ctx.BlockHeight(),
+		Coinbase:    utxo.Coinbase,
+	})
+}
+
+// emitUTXOSpent emits the typed event of an output spent by spendingTxHash
+func (k Keeper) emitUTXOSpent(ctx sdk.Context, utxo types.UTXO, spendingTxHash string) {
+	k.emitTypedEvent(ctx, &types.EventUTXOSpent{
+		Outpoint:       outpoint(utxo.TxHash, utxo.OutputIndex),
+		TxHash:         utxo.TxHash,
+		OutputIndex:    utxo.OutputIndex,
+		Address:        utxo.Address,
+		Amount:         utxo.Amount,
+		BlockHeight:    ctx.BlockHeight(),
+		SpendingTxHash: spendingTxHash,
+	})
+}
+
+// emitHTLCSpent emits the typed event of a hash time locked contract spent
+// by spendingTxHash
+func (k Keeper) emitHTLCSpent(ctx sdk.Context, utxo types.UTXO, spend htlc.Spend, spendingTxHash string) {
+	k.emitTypedEvent(ctx, &types.EventHTLCSpent{
+		Outpoint:       outpoint(utxo.TxHash, utxo.OutputIndex),
+		SpendingTxHash: spendingTxHash,
+		HashLock:       hex.EncodeToString(spend.Contract.Hash[:]),
+		Preimage:       hex.EncodeToString(spend.Preimage),
+		Refund:         spend.Refund(),
+		LockTime:       spend.Contract.LockTime,
+		BlockHeight:    ctx.BlockHeight(),
+	})
+}
+
+// emitTypedEvent emits event. Typed events only fail to encode on a bug, which
+// must not fail the transaction, so the error is logged.
+func (k Keeper) emitTypedEvent(ctx sdk.Context, event proto.Message) {
+	if err := ctx.EventManager().EmitTypedEvent(event); err != nil {
+		k.Logger(ctx).Error("Failed to emit event", "event", proto.MessageName(event), "error", err)
+	}
+}
+
+func outpoint(txHash string, outputIndex uint32) string {
+	return fmt.Sprintf("%s:%d", txHash, outputIndex)
+}
@@ -0,0 +1,138 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/script"
+	"z-blockchain/x/utxo/types"
+)
+
+// MinFeeRate is the smallest fee, in base units per encoded byte of the UTXO
+// messages, a transaction may pay
+func (k Keeper) MinFeeRate(ctx sdk.Context) sdk.Int {
+	return intParam(k.GetParams(ctx).MinFeeRate)
+}
+
+// DustLimit is the smallest amount an output may carry. A smaller output costs
+// more in fees to spend than it is worth and would stay in the set for good.
+func (k Keeper) DustLimit(ctx sdk.Context) sdk.Int {
+	return intParam(k.GetParams(ctx).DustLimit)
+}
+
+// checkDust rejects outputs below the dust limit. OP_RETURN outputs never
+// enter the UTXO set and may carry any amount.
+func (k Keeper) checkDust(ctx sdk.Context, outputs []*types.TxOutput) error {
+	limit := k.DustLimit(ctx)
+	for i, output := range outputs {
+		if script.Unspendable(output.ScriptPubkey) {
+			continue
+		}
+		amount, ok := sdk.NewIntFromString(output.Amount)
+		if !ok {
+			return fmt.Errorf("invalid output amount: %s", output.Amount)
+		}
+		if amount.LT(limit) {
+			return fmt.Errorf("output %d of %s is below the dust limit of %s", i, amount, limit)
+		}
+	}
+	return nil
+}
+
+// intParam parses an integer parameter; Params.Validate has checked it
+func intParam(v string) sdk.Int {
+	i, ok := sdk.NewIntFromString(v)
+	if !ok {
+		return sdk.ZeroInt()
+	}
+	return i
+}
+
+// collectFee adds a transaction fee to the fees of the block. The fee leaves
+// the UTXO set with the transaction; SettleBlockFees routes it at the end of
+// the block.
+func (k Keeper) collectFee(ctx sdk.Context, fee sdk.Int) {
+	if !fee.IsPositive() {
+		return
+	}
+	fees := k.getBlockFees(ctx).Add(fee)
+	ctx.KVStore(k.storeKey).Set(types.BlockFeesKey, []byte(fees.String()))
+}
+
+func (k Keeper) getBlockFees(ctx sdk.Context) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(types.BlockFeesKey)
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	return intParam(string(bz))
+}
+
+// setBlockMiner records miner as the winner of the block's fees; only the
+// block's winning mining proof is rewarded, so it is set once
+func (k Keeper) setBlockMiner(ctx sdk.Context, miner sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.BlockMinerKey) {
+		store.Set(types.BlockMinerKey, miner)
+	}
+}
+
+// GetBurnedFees returns the total of the fees burned so far
+func (k Keeper) GetBurnedFees(ctx sdk.Context) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(types.BurnedFeesKey)
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	return intParam(string(bz))
+}
+
+// SettleBlockFees burns the FeeBurnFraction of the fees collected in the
+// block and pays the rest to the block's miner as a coinbase output. Without
+// a rewarded mining proof in the block all the fees are burned. Burned fees
+// never return to the UTXO set; their total is kept for supply accounting.
+func (k Keeper) SettleBlockFees(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	fees := k.getBlockFees(ctx)
+	miner := sdk.AccAddress(store.Get(types.BlockMinerKey))
+	store.Delete(types.BlockFeesKey)
+	store.Delete(types.BlockMinerKey)
+	if !fees.IsPositive() {
+		return
+	}
+
+	burnFraction, err := sdk.NewDecFromStr(k.GetParams(ctx).FeeBurnFraction)
+	if err != nil {
+		burnFraction = sdk.OneDec()
+	}
+	burn := burnFraction.MulInt(fees).TruncateInt()
+	reward := fees.Sub(burn)
+
+	if reward.IsPositive() && !miner.Empty() {
+		if err := k.payCoinbase(ctx, miner, reward); err != nil {
+			k.Logger(ctx).Error("Failed to pay block fees to miner", "miner", miner.String(), "error", err)
+			burn = fees
+		} else {
+			ctx.EventManager().EmitEvent(
+				sdk.NewEvent(
+					types.EventTypeFeeReward,
+					sdk.NewAttribute(types.AttributeKeyMiner, miner.String()),
+					sdk.NewAttribute(types.AttributeKeyAmount, reward.String()),
+					sdk.NewAttribute(types.AttributeKeyBlockHeight, sdk.NewInt(ctx.BlockHeight()).String()),
+				),
+			)
+		}
+	} else {
+		burn = fees
+	}
+
+	if burn.IsPositive() {
+		store.Set(types.BurnedFeesKey, []byte(k.GetBurnedFees(ctx).Add(burn).String()))
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeFeeBurn,
+				sdk.NewAttribute(types.AttributeKeyAmount, burn.String()),
+				sdk.NewAttribute(types.AttributeKeyBlockHeight, sdk.NewInt(ctx.BlockHeight()).String()),
+			),
+		)
+	}
+}
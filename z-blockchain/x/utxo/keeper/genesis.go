@@ -0,0 +1,189 @@
+package keeper
+
+import (
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// SetParams sets the module parameters
+func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
+	k.paramstore.SetParamSet(ctx, &params)
+}
+
+// InitGenesis rebuilds the module state from a genesis state: the UTXO set and
+// its accumulator, the transactions, the note commitment tree, nullifier set
+// and anchors, the shielded pool and the difficulty. It panics on a state that
+// cannot be imported, such as a repeated note commitment.
+func (k Keeper) InitGenesis(ctx sdk.Context, gs types.GenesisState) {
+	k.SetParams(ctx, gs.Params)
+	if gs.Difficulty > 0 {
+		k.SetDifficulty(ctx, gs.Difficulty)
+	}
+
+	for _, utxo := range gs.Utxos {
+		k.SetUTXO(ctx, utxo)
+	}
+	for _, tx := range gs.Transactions {
+		k.SetTransaction(ctx, tx)
+	}
+
+	for _, tx := range gs.ShieldedTransactions {
+		for _, nullifier := range tx.Nullifiers {
+			if k.IsNullifierUsed(ctx, nullifier) {
+				panic(fmt.Errorf("nullifier of shielded transaction %s already used: %x", tx.TxHash, nullifier))
+			}
+			k.SetNullifier(ctx, nullifier)
+		}
+		for _, commitment := range tx.Commitments {
+			position, err := k.AddCommitment(ctx, commitment)
+			if err != nil {
+				panic(fmt.Errorf("shielded transaction %s: %w", tx.TxHash, err))
+			}
+			k.appendShieldedOutput(ctx, types.ShieldedOutput{
+				Position:   position,
+				Commitment: commitment,
+				Ciphertext: tx.EncryptedMemo,
+				TxHash:     tx.TxHash,
+			})
+		}
+		k.SetShieldedTransaction(ctx, tx)
+	}
+
+	anchors := prefix.NewStore(ctx.KVStore(k.storeKey), types.AnchorKey)
+	for _, anchor := range gs.Anchors {
+		anchors.Set(anchor.Root, sdk.Uint64ToBigEndian(uint64(anchor.Height)))
+	}
+
+	for _, output := range gs.DataOutputs {
+		k.SetDataOutput(ctx, output)
+	}
+
+	for _, class := range gs.Hardware {
+		k.SetHardwareClass(ctx, class)
+	}
+	for _, stats := range gs.MinerStats {
+		k.setMinerStats(ctx, stats)
+	}
+	for _, stats := range gs.HardwareStats {
+		k.setHardwareStats(ctx, stats)
+	}
+	for _, split := range gs.PayoutSplits {
+		k.setPayoutSplit(ctx, split)
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	if gs.ShieldedPool != "" {
+		k.setShieldedPoolValue(ctx, intParam(gs.ShieldedPool))
+	}
+	if gs.BurnedFees != "" {
+		store.Set(types.BurnedFeesKey, []byte(intParam(gs.BurnedFees).String()))
+	}
+	if gs.MintedSupply != "" {
+		k.setMintedSupply(ctx, intParam(gs.MintedSupply))
+	}
+	if gs.TreasuryAccrued != "" {
+		k.setTreasuryAccrued(ctx, intParam(gs.TreasuryAccrued))
+	}
+}
+
+// ExportGenesis exports the module state so that importing it with
+// InitGenesis rebuilds the same UTXO set, note commitment tree and nullifier
+// set. Shielded transactions are exported in note commitment tree order.
+func (k Keeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
+	params := k.GetParams(ctx)
+	gs := types.DefaultGenesis()
+	gs.Params = params
+	gs.Difficulty = k.GetDifficulty(ctx)
+	gs.BlockReward = params.BlockReward
+	gs.HalvingInterval = params.HalvingInterval
+	gs.HardwareAcceleration = params.HardwareAcceleration
+	gs.LastBlockHeight = ctx.BlockHeight()
+	gs.ShieldedPool = k.GetShieldedPoolValue(ctx).String()
+	gs.BurnedFees = k.GetBurnedFees(ctx).String()
+	gs.MintedSupply = k.GetMintedSupply(ctx).String()
+	gs.TreasuryAccrued = k.GetTreasuryAccrued(ctx).String()
+
+	k.iterate(ctx, types.UTXOKey, func(_, value []byte) {
+		var utxo types.UTXO
+		k.cdc.MustUnmarshal(value, &utxo)
+		gs.Utxos = append(gs.Utxos, utxo)
+	})
+	k.iterate(ctx, types.TransactionKey, func(_, value []byte) {
+		var tx types.UTXOTransaction
+		k.cdc.MustUnmarshal(value, &tx)
+		gs.Transactions = append(gs.Transactions, tx)
+	})
+	k.iterate(ctx, types.ShieldedTxKey, func(_, value []byte) {
+		var tx types.ShieldedTransaction
+		k.cdc.MustUnmarshal(value, &tx)
+		gs.ShieldedTransactions = append(gs.ShieldedTransactions, tx)
+	})
+	k.iterate(ctx, types.DataOutputKey, func(_, value []byte) {
+		var output types.DataOutput
+		k.cdc.MustUnmarshal(value, &output)
+		gs.DataOutputs = append(gs.DataOutputs, output)
+	})
+	gs.Hardware = k.GetHardwareRegistry(ctx)
+	k.iterate(ctx, types.MiningStatsKey, func(_, value []byte) {
+		var stats types.MinerStats
+		k.cdc.MustUnmarshal(value, &stats)
+		gs.MinerStats = append(gs.MinerStats, stats)
+	})
+	k.iterate(ctx, types.HardwareStatsKey, func(_, value []byte) {
+		var stats types.HardwareStats
+		k.cdc.MustUnmarshal(value, &stats)
+		gs.HardwareStats = append(gs.HardwareStats, stats)
+	})
+	k.iterate(ctx, types.PayoutSplitKey, func(_, value []byte) {
+		var split types.PayoutSplit
+		k.cdc.MustUnmarshal(value, &split)
+		gs.PayoutSplits = append(gs.PayoutSplits, split)
+	})
+	k.iterate(ctx, types.AnchorKey, func(key, value []byte) {
+		gs.Anchors = append(gs.Anchors, types.GenesisAnchor{
+			Root:   append([]byte{}, key...),
+			Height: int64(sdk.BigEndianToUint64(value)),
+		})
+	})
+
+	// The commitments of a transaction were added to the tree together, so
+	// ordering transactions by their first commitment orders every
+	// commitment. Transactions without commitments come last, by hash.
+	first := make(map[string]uint64, len(gs.ShieldedTransactions))
+	for _, tx := range gs.ShieldedTransactions {
+		if len(tx.Commitments) == 0 {
+			continue
+		}
+		position, found := k.GetCommitmentPosition(ctx, tx.Commitments[0])
+		if !found {
+			panic(fmt.Errorf("note commitment of shielded transaction %s is not in the tree", tx.TxHash))
+		}
+		first[tx.TxHash] = position
+	}
+	sort.SliceStable(gs.ShieldedTransactions, func(i, j int) bool {
+		pi, oki := first[gs.ShieldedTransactions[i].TxHash]
+		pj, okj := first[gs.ShieldedTransactions[j].TxHash]
+		if oki != okj {
+			return oki
+		}
+		return pi < pj
+	})
+
+	return gs
+}
+
+// iterate calls fn with each key, without the prefix, and value under prefix
+func (k Keeper) iterate(ctx sdk.Context, keyPrefix []byte, fn func(key, value []byte)) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), keyPrefix)
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		fn(iterator.Key(), iterator.Value())
+	}
+}
@@ -0,0 +1,607 @@
+package keeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"shared/address"
+	"shared/pow"
+	"z-blockchain/x/utxo/types"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// BlockTimeWatchdog returns the current block time window and breach count
+func (k Keeper) BlockTimeWatchdog(goCtx context.Context, req *types.QueryBlockTimeWatchdogRequest) (*types.QueryBlockTimeWatchdogResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	watchdog, found := k.GetBlockTimeWatchdog(ctx)
+	if !found {
+		return nil, status.Error(codes.NotFound, "block time watchdog has not started")
+	}
+
+	return &types.QueryBlockTimeWatchdogResponse{Watchdog: watchdog}, nil
+}
+
+// WatchdogEvents lists the watchdog history, oldest first
+func (k Keeper) WatchdogEvents(goCtx context.Context, req *types.QueryWatchdogEventsRequest) (*types.QueryWatchdogEventsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.WatchdogEventKey)
+
+	var events []types.WatchdogEvent
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var event types.WatchdogEvent
+		if err := k.cdc.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryWatchdogEventsResponse{Events: events, Pagination: pageRes}, nil
+}
+
+// UTXO returns an output by the transaction creating it and its index
+func (k Keeper) UTXO(goCtx context.Context, req *types.QueryUTXORequest) (*types.QueryUTXOResponse, error) {
+	if req == nil || req.TxHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	utxo, found := k.GetUTXO(ctx, req.TxHash, req.OutputIndex)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "UTXO %s:%d not found", req.TxHash, req.OutputIndex)
+	}
+
+	return &types.QueryUTXOResponse{Utxo: utxo}, nil
+}
+
+// UTXOsByAddress lists the unspent outputs of an address
+func (k Keeper) UTXOsByAddress(goCtx context.Context, req *types.QueryUTXOsByAddressRequest) (*types.QueryUTXOsByAddressResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	addr, err := address.ToZChain(req.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address: %s", err)
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	index := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOByAddressPrefix(addr))
+	utxoStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOKey)
+
+	var utxos []types.UTXO
+	pageRes, err := query.Paginate(index, req.Pagination, func(key []byte, _ []byte) error {
+		var utxo types.UTXO
+		if err := k.cdc.Unmarshal(utxoStore.Get(key), &utxo); err != nil {
+			return err
+		}
+		utxos = append(utxos, utxo)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryUTXOsByAddressResponse{Utxos: utxos, Pagination: pageRes}, nil
+}
+
+// AddressBalance returns the confirmed, immature and pending balance of an
+// address
+func (k Keeper) AddressBalance(goCtx context.Context, req *types.QueryAddressBalanceRequest) (*types.QueryAddressBalanceResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	addr, err := address.ToZChain(req.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address: %s", err)
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	confirmed, immature, count := k.GetAddressBalance(ctx, addr)
+	received, spent := k.GetPendingBalance(ctx, addr)
+
+	return &types.QueryAddressBalanceResponse{
+		Address:         addr,
+		Confirmed:       confirmed.String(),
+		Immature:        immature.String(),
+		PendingReceived: received.String(),
+		PendingSpent:    spent.String(),
+		UtxoCount:       count,
+		Height:          ctx.BlockHeight(),
+	}, nil
+}
+
+// Transaction returns a transparent transaction by hash
+func (k Keeper) Transaction(goCtx context.Context, req *types.QueryTransactionRequest) (*types.QueryTransactionResponse, error) {
+	if req == nil || req.TxHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	tx, found := k.GetTransaction(ctx, req.TxHash)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "transaction %s not found", req.TxHash)
+	}
+
+	return &types.QueryTransactionResponse{Transaction: tx}, nil
+}
+
+// ShieldedTx returns a shielded transaction by hash
+func (k Keeper) ShieldedTx(goCtx context.Context, req *types.QueryShieldedTxRequest) (*types.QueryShieldedTxResponse, error) {
+	if req == nil || req.TxHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	tx, found := k.GetShieldedTransaction(ctx, req.TxHash)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "shielded transaction %s not found", req.TxHash)
+	}
+
+	return &types.QueryShieldedTxResponse{Transaction: tx}, nil
+}
+
+// Difficulty returns the current mining difficulty and its governance bounds
+func (k Keeper) Difficulty(goCtx context.Context, req *types.QueryDifficultyRequest) (*types.QueryDifficultyResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	params := k.GetParams(ctx)
+	return &types.QueryDifficultyResponse{
+		Difficulty:         k.GetDifficulty(ctx),
+		MinDifficulty:      params.MinDifficulty,
+		MaxDifficulty:      params.MaxDifficulty,
+		NextRetargetHeight: types.NextRetargetHeight(params.DifficultyAlgorithm, ctx.BlockHeight()),
+	}, nil
+}
+
+// MergedMiningCommitment returns the commitment an Altcoinchain header
+// carries in its extra data to merge mine the next block for a miner
+func (k Keeper) MergedMiningCommitment(goCtx context.Context, req *types.QueryMergedMiningCommitmentRequest) (*types.QueryMergedMiningCommitmentResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	if _, err := sdk.AccAddressFromBech32(req.Miner); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	commitment, height, prevBlockHash := k.mergedMiningCommitment(ctx, req.Miner, req.Timestamp)
+	return &types.QueryMergedMiningCommitmentResponse{
+		Commitment:         commitment,
+		Height:             height,
+		PrevBlockHash:      prevBlockHash,
+		MergedMiningFactor: k.GetParams(ctx).MergedMiningFactor,
+		AltcoinchainTip:    k.AuxPowTip(ctx),
+	}, nil
+}
+
+// DifficultyHistory lists the recent difficulty retargets, oldest first
+func (k Keeper) DifficultyHistory(goCtx context.Context, req *types.QueryDifficultyHistoryRequest) (*types.QueryDifficultyHistoryResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.DifficultyRetargetKey)
+
+	var retargets []types.DifficultyRetarget
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var retarget types.DifficultyRetarget
+		if err := k.cdc.Unmarshal(value, &retarget); err != nil {
+			return err
+		}
+		retargets = append(retargets, retarget)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryDifficultyHistoryResponse{Retargets: retargets, Pagination: pageRes}, nil
+}
+
+// NoteWitness returns the Merkle path of a note commitment to the current
+// note commitment tree root
+func (k Keeper) NoteWitness(goCtx context.Context, req *types.QueryNoteWitnessRequest) (*types.QueryNoteWitnessResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	commitment, err := hex.DecodeString(req.Commitment)
+	if err != nil || len(commitment) != 32 {
+		return nil, status.Error(codes.InvalidArgument, "commitment must be 32 bytes of hex")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	position, anchor, siblings, err := k.GetNoteWitness(ctx, commitment)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &types.QueryNoteWitnessResponse{
+		Position: position,
+		Anchor:   anchor,
+		Siblings: siblings,
+		Height:   ctx.BlockHeight(),
+	}, nil
+}
+
+// NoteTreeRoot returns the note commitment tree root and its number of leaves
+func (k Keeper) NoteTreeRoot(goCtx context.Context, req *types.QueryNoteTreeRootRequest) (*types.QueryNoteTreeRootResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	tree := k.noteTree(ctx)
+	return &types.QueryNoteTreeRootResponse{Root: tree.Root(), Leaves: tree.Size()}, nil
+}
+
+// ShieldedOutputs lists the shielded outputs of a range of heights, for
+// wallets to fetch the ciphertexts they have yet to trial decrypt
+func (k Keeper) ShieldedOutputs(goCtx context.Context, req *types.QueryShieldedOutputsRequest) (*types.QueryShieldedOutputsResponse, error) {
+	if req == nil || req.StartHeight < 0 || (req.EndHeight != 0 && req.EndHeight < req.StartHeight) {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	if req.Pagination != nil && (req.Pagination.Offset > 0 || req.Pagination.CountTotal) {
+		return nil, status.Error(codes.InvalidArgument, "shielded outputs are paginated by key only")
+	}
+
+	start := shieldedOutputHeightKey(req.StartHeight)
+	limit := query.DefaultLimit
+	if req.Pagination != nil {
+		if len(req.Pagination.Key) > 0 {
+			if bytes.Compare(req.Pagination.Key, start) < 0 {
+				return nil, status.Error(codes.InvalidArgument, "pagination key is before the start height")
+			}
+			start = req.Pagination.Key
+		}
+		if req.Pagination.Limit > 0 {
+			limit = int(req.Pagination.Limit)
+		}
+	}
+	if limit > MaxShieldedOutputsPage {
+		limit = MaxShieldedOutputsPage
+	}
+	var end []byte
+	if req.EndHeight != 0 {
+		end = shieldedOutputHeightKey(req.EndHeight + 1)
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	outputs, nextKey := k.GetShieldedOutputs(ctx, start, end, limit)
+	return &types.QueryShieldedOutputsResponse{
+		Outputs:    outputs,
+		Pagination: &query.PageResponse{NextKey: nextKey},
+	}, nil
+}
+
+// DataOutputs lists the data outputs whose data starts with a prefix
+func (k Keeper) DataOutputs(goCtx context.Context, req *types.QueryDataOutputsRequest) (*types.QueryDataOutputsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	dataPrefix, err := hex.DecodeString(req.Prefix)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "prefix must be hex")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), append(append([]byte{}, types.DataOutputKey...), dataPrefix...))
+
+	var outputs []types.DataOutput
+	pageRes, err := query.Paginate(store, req.Pagination, func(_ []byte, value []byte) error {
+		var output types.DataOutput
+		if err := k.cdc.Unmarshal(value, &output); err != nil {
+			return err
+		}
+		outputs = append(outputs, output)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryDataOutputsResponse{Outputs: outputs, Pagination: pageRes}, nil
+}
+
+// FeeParams returns the minimum fee rate and dust limit
+func (k Keeper) FeeParams(goCtx context.Context, req *types.QueryFeeParamsRequest) (*types.QueryFeeParamsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryFeeParamsResponse{
+		MinFeeRate: k.MinFeeRate(ctx).String(),
+		DustLimit:  k.DustLimit(ctx).String(),
+	}, nil
+}
+
+// Emission returns the reward of the next block, the next halving and the
+// supply minted under the hard cap
+func (k Keeper) Emission(goCtx context.Context, req *types.QueryEmissionRequest) (*types.QueryEmissionResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	emission := pow.DefaultEmission()
+	return &types.QueryEmissionResponse{
+		BlockReward:       k.NextBlockReward(ctx).String(),
+		NextHalvingHeight: emission.Schedule.NextHalving(ctx.BlockHeight() + 1),
+		MintedSupply:      k.GetMintedSupply(ctx).String(),
+		BurnedFees:        k.GetBurnedFees(ctx).String(),
+		CirculatingSupply: k.CirculatingSupply(ctx).String(),
+		MaxSupply:         emission.MaxSupply.String(),
+	}, nil
+}
+
+// Treasury returns the funds of the community treasury and the tax that
+// accrued them
+func (k Keeper) Treasury(goCtx context.Context, req *types.QueryTreasuryRequest) (*types.QueryTreasuryResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryTreasuryResponse{
+		Balance:     k.TreasuryBalance(ctx).String(),
+		Accrued:     k.GetTreasuryAccrued(ctx).String(),
+		TreasuryTax: k.GetParams(ctx).TreasuryTax,
+	}, nil
+}
+
+// ShieldedPool returns the total value held in the shielded pool
+func (k Keeper) ShieldedPool(goCtx context.Context, req *types.QueryShieldedPoolRequest) (*types.QueryShieldedPoolResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryShieldedPoolResponse{Value: k.GetShieldedPoolValue(ctx).String()}, nil
+}
+
+// Params returns the module parameters
+func (k Keeper) Params(goCtx context.Context, req *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	return &types.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
+
+// HardwareRegistry lists the device classes of the hardware registry
+func (k Keeper) HardwareRegistry(goCtx context.Context, req *types.QueryHardwareRegistryRequest) (*types.QueryHardwareRegistryResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareClassKey)
+
+	var registry []types.HardwareClass
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var class types.HardwareClass
+		if err := k.cdc.Unmarshal(value, &class); err != nil {
+			return err
+		}
+		registry = append(registry, class)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryHardwareRegistryResponse{Hardware: registry, Pagination: pageRes}, nil
+}
+
+// HardwareClass returns a device class of the hardware registry
+func (k Keeper) HardwareClass(goCtx context.Context, req *types.QueryHardwareClassRequest) (*types.QueryHardwareClassResponse, error) {
+	if req == nil || req.DeviceClass == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	class, found := k.GetHardwareClass(ctx, req.DeviceClass)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "device class %s is not registered", req.DeviceClass)
+	}
+
+	return &types.QueryHardwareClassResponse{HardwareClass: class}, nil
+}
+
+// HardwareFlags lists the miners flagged by their audit response profile
+func (k Keeper) HardwareFlags(goCtx context.Context, req *types.QueryHardwareFlagsRequest) (*types.QueryHardwareFlagsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareFlagKey)
+
+	var flags []types.HardwareFlag
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var flag types.HardwareFlag
+		if err := k.cdc.Unmarshal(value, &flag); err != nil {
+			return err
+		}
+		flags = append(flags, flag)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryHardwareFlagsResponse{Flags: flags, Pagination: pageRes}, nil
+}
+
+// PayoutSplit returns how the mining payouts of a miner are divided
+func (k Keeper) PayoutSplit(goCtx context.Context, req *types.QueryPayoutSplitRequest) (*types.QueryPayoutSplitResponse, error) {
+	if req == nil || req.Miner == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	split, found := k.GetPayoutSplit(ctx, req.Miner)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "miner %s has no payout split", req.Miner)
+	}
+
+	return &types.QueryPayoutSplitResponse{Split: split}, nil
+}
+
+// MinerStats returns the cumulative mining results of a miner
+func (k Keeper) MinerStats(goCtx context.Context, req *types.QueryMinerStatsRequest) (*types.QueryMinerStatsResponse, error) {
+	if req == nil || req.Miner == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	stats, found := k.GetMinerStats(ctx, req.Miner)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "miner %s has not won a block", req.Miner)
+	}
+
+	return &types.QueryMinerStatsResponse{Stats: stats}, nil
+}
+
+// TopMiners lists miners by total rewards, highest first
+func (k Keeper) TopMiners(goCtx context.Context, req *types.QueryTopMinersRequest) (*types.QueryTopMinersResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	rank := prefix.NewStore(ctx.KVStore(k.storeKey), types.MinerRankKey)
+	statsStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.MiningStatsKey)
+
+	var miners []types.MinerStats
+	pageRes, err := query.Paginate(rank, req.Pagination, func(key []byte, _ []byte) error {
+		var stats types.MinerStats
+		if err := k.cdc.Unmarshal(statsStore.Get([]byte(types.MinerFromRankIndexKey(key))), &stats); err != nil {
+			return err
+		}
+		miners = append(miners, stats)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryTopMinersResponse{Miners: miners, Pagination: pageRes}, nil
+}
+
+// HardwareDistribution lists the mining results of each device class
+func (k Keeper) HardwareDistribution(goCtx context.Context, req *types.QueryHardwareDistributionRequest) (*types.QueryHardwareDistributionResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareStatsKey)
+
+	var hardware []types.HardwareStats
+	pageRes, err := query.Paginate(store, req.Pagination, func(key []byte, value []byte) error {
+		var stats types.HardwareStats
+		if err := k.cdc.Unmarshal(value, &stats); err != nil {
+			return err
+		}
+		hardware = append(hardware, stats)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryHardwareDistributionResponse{Hardware: hardware, Pagination: pageRes}, nil
+}
+
+// UTXOSetRoot returns the UTXO set accumulator root committed at a height
+func (k Keeper) UTXOSetRoot(goCtx context.Context, req *types.QueryUTXOSetRootRequest) (*types.QueryUTXOSetRootResponse, error) {
+	if req == nil || req.Height < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	height := req.Height
+	if height == 0 {
+		height = ctx.BlockHeight()
+	}
+	root, found := k.GetUTXOSetRoot(ctx, height)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no UTXO set root at height %d", height)
+	}
+
+	return &types.QueryUTXOSetRootResponse{Height: height, Root: root}, nil
+}
+
+// UTXOProof proves an output is, or is not, in the UTXO set
+func (k Keeper) UTXOProof(goCtx context.Context, req *types.QueryUTXOProofRequest) (*types.QueryUTXOProofResponse, error) {
+	if req == nil || req.TxHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	proof, utxo := k.ProveUTXO(ctx, req.TxHash, req.OutputIndex)
+	return &types.QueryUTXOProofResponse{
+		Height:   ctx.BlockHeight(),
+		Root:     k.utxoSet(ctx).Root(),
+		Utxo:     utxo,
+		Bitmap:   proof.Bitmap[:],
+		Siblings: proof.Siblings,
+	}, nil
+}
+
+// TransactionProof returns where a transaction was included and accumulator
+// proofs of its outputs
+func (k Keeper) TransactionProof(goCtx context.Context, req *types.QueryTransactionProofRequest) (*types.QueryTransactionProofResponse, error) {
+	if req == nil || req.TxHash == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(goCtx)
+	tx, found := k.GetTransaction(ctx, req.TxHash)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "transaction %s not found", req.TxHash)
+	}
+	location, found := k.GetTxLocation(ctx, req.TxHash)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "transaction %s has no recorded location", req.TxHash)
+	}
+
+	outputs := make([]types.OutputProof, len(tx.Outputs))
+	for i := range tx.Outputs {
+		outputs[i] = k.proveOutput(ctx, req.TxHash, uint32(i))
+	}
+
+	return &types.QueryTransactionProofResponse{
+		Transaction: tx,
+		Location:    location,
+		Height:      ctx.BlockHeight(),
+		UtxoSetRoot: k.utxoSet(ctx).Root(),
+		Outputs:     outputs,
+	}, nil
+}
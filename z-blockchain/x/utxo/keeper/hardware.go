@@ -0,0 +1,113 @@
+package keeper
+
+import (
+	"fmt"
+	"strconv"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// GetHardwareClass returns a device class of the hardware registry
+func (k Keeper) GetHardwareClass(ctx sdk.Context, deviceClass string) (types.HardwareClass, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareClassKey)
+	bz := store.Get([]byte(deviceClass))
+	if bz == nil {
+		return types.HardwareClass{}, false
+	}
+
+	var class types.HardwareClass
+	k.cdc.MustUnmarshal(bz, &class)
+	return class, true
+}
+
+// SetHardwareClass adds a device class to the hardware registry, replacing
+// any entry of the same class
+func (k Keeper) SetHardwareClass(ctx sdk.Context, class types.HardwareClass) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareClassKey)
+	store.Set([]byte(class.DeviceClass), k.cdc.MustMarshal(&class))
+}
+
+// GetHardwareRegistry returns every device class of the registry, by class
+func (k Keeper) GetHardwareRegistry(ctx sdk.Context) []types.HardwareClass {
+	var registry []types.HardwareClass
+	k.iterate(ctx, types.HardwareClassKey, func(_, value []byte) {
+		var class types.HardwareClass
+		k.cdc.MustUnmarshal(value, &class)
+		registry = append(registry, class)
+	})
+	return registry
+}
+
+// HardwareEligible reports whether mining proofs from a device class are
+// accepted: it must be registered and not barred
+func (k Keeper) HardwareEligible(ctx sdk.Context, deviceClass string) bool {
+	class, found := k.GetHardwareClass(ctx, deviceClass)
+	return found && class.Eligible
+}
+
+// HardwareBonus returns the bonus added to the block reward of a device
+// class by the schedule governance sets, zero for classes the registry does
+// not know or bars
+func (k Keeper) HardwareBonus(ctx sdk.Context, deviceClass string) sdk.Int {
+	if !k.HardwareEligible(ctx, deviceClass) {
+		return sdk.ZeroInt()
+	}
+	return k.GetParams(ctx).HardwareBonusSchedule.Bonus(deviceClass)
+}
+
+// RegisterHardware adds or updates a device class on behalf of a registrar
+// of the params. An eligible class must attest to a benchmark: an Equihash
+// solution, at the chain's N and K, of the challenge of the class and its
+// driver fingerprint.
+func (k Keeper) RegisterHardware(ctx sdk.Context, class types.HardwareClass) error {
+	if !k.isHardwareRegistrar(ctx, class.Registrar) {
+		return fmt.Errorf("%s is not a hardware registrar", class.Registrar)
+	}
+
+	if class.Eligible {
+		if err := k.verifyHardwareBenchmark(ctx, class); err != nil {
+			return fmt.Errorf("invalid benchmark of device class %s: %w", class.DeviceClass, err)
+		}
+	}
+
+	class.RegisteredHeight = ctx.BlockHeight()
+	k.SetHardwareClass(ctx, class)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeRegisterHardware,
+			sdk.NewAttribute(types.AttributeKeyDeviceClass, class.DeviceClass),
+			sdk.NewAttribute(types.AttributeKeyEligible, strconv.FormatBool(class.Eligible)),
+			sdk.NewAttribute(types.AttributeKeyCreator, class.Registrar),
+		),
+	)
+	return nil
+}
+
+func (k Keeper) isHardwareRegistrar(ctx sdk.Context, addr string) bool {
+	for _, registrar := range k.GetParams(ctx).HardwareRegistrars {
+		if registrar == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyHardwareBenchmark checks the benchmark solution of an attestation
+func (k Keeper) verifyHardwareBenchmark(ctx sdk.Context, class types.HardwareClass) error {
+	params := k.GetParams(ctx).Equihash()
+	solution, err := k.equihashMining.parseEquihashSolution(params, class.Attestation.BenchmarkProof, 0)
+	if err != nil {
+		return err
+	}
+
+	header := types.HardwareBenchmarkHeader(class.DeviceClass, class.Attestation.DriverFingerprint, solution.Nonce)
+	if !types.VerifyEquihashSolution(params, header, solution) {
+		return fmt.Errorf("not an Equihash %d_%d solution of the benchmark challenge", params.N, params.K)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package keeper
+
+import (
+	"fmt"
+	"strconv"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// GetHardwareFlag returns the flag of a miner whose audits matched an ASIC
+func (k Keeper) GetHardwareFlag(ctx sdk.Context, miner string) (types.HardwareFlag, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareFlagKey)
+	bz := store.Get([]byte(miner))
+	if bz == nil {
+		return types.HardwareFlag{}, false
+	}
+
+	var flag types.HardwareFlag
+	k.cdc.MustUnmarshal(bz, &flag)
+	return flag, true
+}
+
+func (k Keeper) setHardwareFlag(ctx sdk.Context, flag types.HardwareFlag) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareFlagKey)
+	store.Set([]byte(flag.Miner), k.cdc.MustMarshal(&flag))
+}
+
+// MinerHardwareBonus returns the bonus of a miner's device class, zero while
+// the miner is flagged
+func (k Keeper) MinerHardwareBonus(ctx sdk.Context, miner, deviceClass string) sdk.Int {
+	if _, flagged := k.GetHardwareFlag(ctx, miner); flagged {
+		return sdk.ZeroInt()
+	}
+	return k.HardwareBonus(ctx, deviceClass)
+}
+
+// checkASICProfile flags a miner once its profile holds enough audits and
+// its time per buffer block grows with the buffer past the ratio of the
+// params. A flagged miner stays flagged until governance reviews it.
+func (k Keeper) checkASICProfile(ctx sdk.Context, profile types.MinerProfile, params types.Params) {
+	if len(profile.AuditSamples) < int(params.AsicProfileAudits) {
+		return
+	}
+	if _, flagged := k.GetHardwareFlag(ctx, profile.Miner); flagged {
+		return
+	}
+
+	ratio, ok := types.ASICLatencyRatio(profile.AuditSamples)
+	if !ok || !ratio.GT(sdk.MustNewDecFromStr(params.AsicLatencyRatio)) {
+		return
+	}
+
+	flag := types.HardwareFlag{
+		Miner:         profile.Miner,
+		HardwareId:    profile.HardwareId,
+		LatencyRatio:  ratio.String(),
+		FlaggedHeight: ctx.BlockHeight(),
+	}
+	k.setHardwareFlag(ctx, flag)
+
+	k.Logger(ctx).Info("Audit responses of miner match an ASIC, hardware bonus suspended pending governance review",
+		"miner", flag.Miner, "hardware_id", flag.HardwareId, "latency_ratio", flag.LatencyRatio)
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeHardwareFlag,
+			sdk.NewAttribute(types.AttributeKeyMiner, flag.Miner),
+			sdk.NewAttribute(types.AttributeKeyHardwareId, flag.HardwareId),
+			sdk.NewAttribute(types.AttributeKeyLatencyRatio, flag.LatencyRatio),
+		),
+	)
+}
+
+// ReviewHardwareFlag settles the flag of a miner on behalf of governance. An
+// upheld flag keeps the bonus suspended; a cleared one is deleted along with
+// the audits that raised it, so the miner's profile starts over.
+func (k Keeper) ReviewHardwareFlag(ctx sdk.Context, miner string, upheld bool) error {
+	flag, found := k.GetHardwareFlag(ctx, miner)
+	if !found {
+		return fmt.Errorf("miner %s is not flagged", miner)
+	}
+
+	if upheld {
+		flag.Upheld = true
+		k.setHardwareFlag(ctx, flag)
+	} else {
+		prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareFlagKey).Delete([]byte(miner))
+		if profile, found := k.GetMinerProfile(ctx, miner); found {
+			profile.AuditSamples = nil
+			k.setMinerProfile(ctx, profile)
+		}
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeHardwareReview,
+			sdk.NewAttribute(types.AttributeKeyMiner, miner),
+			sdk.NewAttribute(types.AttributeKeyHardwareId, flag.HardwareId),
+			sdk.NewAttribute(types.AttributeKeyUpheld, strconv.FormatBool(upheld)),
+		),
+	)
+	return nil
+}
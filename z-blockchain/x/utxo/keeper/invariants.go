@@ -0,0 +1,134 @@
+package keeper
+
+import (
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// RegisterInvariants registers the utxo module invariants with the crisis
+// module
+func RegisterInvariants(ir sdk.InvariantRegistry, k Keeper) {
+	ir.RegisterRoute(types.ModuleName, "supply", SupplyInvariant(k))
+	ir.RegisterRoute(types.ModuleName, "utxo-index", UTXOIndexInvariant(k))
+	ir.RegisterRoute(types.ModuleName, "nullifiers", NullifierInvariant(k))
+}
+
+// AllInvariants runs all the utxo module invariants, for simulations
+func AllInvariants(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		for _, invariant := range []sdk.Invariant{
+			SupplyInvariant(k),
+			UTXOIndexInvariant(k),
+			NullifierInvariant(k),
+		} {
+			if res, broken := invariant(ctx); broken {
+				return res, broken
+			}
+		}
+		return "", false
+	}
+}
+
+// SupplyInvariant checks that the bank supply of z is accounted for. The
+// module account escrows every z minted for a coinbase output, and the value
+// stays there as it moves between unspent outputs, the shielded pool and the
+// fees of the block; burned fees are never burned from the bank. So the
+// supply is the escrow plus the z held by other accounts, such as genesis
+// balances, and the escrow is what the module's own books add up to.
+func SupplyInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		unspent := sdk.ZeroInt()
+		k.iterate(ctx, types.UTXOKey, func(_, value []byte) {
+			var utxo types.UTXO
+			k.cdc.MustUnmarshal(value, &utxo)
+			if !utxo.IsSpent {
+				unspent = unspent.Add(intParam(utxo.Amount))
+			}
+		})
+		pool := k.GetShieldedPoolValue(ctx)
+		fees := k.getBlockFees(ctx)
+		burned := k.GetBurnedFees(ctx)
+
+		supply := k.bankKeeper.GetSupply(ctx, "z").Amount
+		escrow := k.bankKeeper.GetBalance(ctx, authtypes.NewModuleAddress(types.ModuleName), "z").Amount
+		held := supply.Sub(escrow)
+
+		total := unspent.Add(pool).Add(fees).Add(burned)
+		broken := !total.Equal(escrow) || held.IsNegative()
+		return sdk.FormatInvariant(types.ModuleName, "supply", fmt.Sprintf(
+			"\tunspent UTXOs: %s\n\tshielded pool: %s\n\tblock fees: %s\n\tburned fees: %s\n\tmodule escrow: %s\n\theld by other accounts: %s\n\tbank supply: %s\n",
+			unspent, pool, fees, burned, escrow, held, supply,
+		)), broken
+	}
+}
+
+// UTXOIndexInvariant checks that the address index lists exactly the unspent
+// outputs
+func UTXOIndexInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var msg string
+		count := 0
+
+		unspent := 0
+		k.iterate(ctx, types.UTXOKey, func(key, value []byte) {
+			var utxo types.UTXO
+			k.cdc.MustUnmarshal(value, &utxo)
+			if utxo.IsSpent {
+				return
+			}
+			unspent++
+			index := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOByAddressPrefix(utxo.Address))
+			if !index.Has(key) {
+				count++
+				msg += fmt.Sprintf("\tunspent UTXO %s is not indexed under %s\n", key, utxo.Address)
+			}
+		})
+
+		indexed := 0
+		k.iterate(ctx, types.UTXOByAddressKey, func(_, _ []byte) {
+			indexed++
+		})
+		if indexed != unspent {
+			count++
+			msg += fmt.Sprintf("\t%d index entries for %d unspent UTXOs\n", indexed, unspent)
+		}
+
+		return sdk.FormatInvariant(types.ModuleName, "utxo-index", fmt.Sprintf(
+			"%d inconsistencies found\n%s", count, msg,
+		)), count != 0
+	}
+}
+
+// NullifierInvariant checks that no nullifier is in the nullifier set twice,
+// under different epochs
+func NullifierInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var msg string
+		count := 0
+
+		seen := make(map[string]uint64)
+		k.iterate(ctx, types.NullifierKey, func(key, _ []byte) {
+			if len(key) < 8 {
+				return
+			}
+			epoch := sdk.BigEndianToUint64(key[:8])
+			nullifier := string(key[8:])
+			if first, ok := seen[nullifier]; ok {
+				count++
+				msg += fmt.Sprintf("\tnullifier %x revealed in epochs %d and %d\n", key[8:], first, epoch)
+				return
+			}
+			seen[nullifier] = epoch
+		})
+
+		return sdk.FormatInvariant(types.ModuleName, "nullifiers", fmt.Sprintf(
+			"%d repeated nullifiers found\n%s", count, msg,
+		)), count != 0
+	}
+}
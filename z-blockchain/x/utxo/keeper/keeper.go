@@ -1,8 +1,6 @@
 package keeper
 
 import (
-	"crypto/sha256"
-	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -16,14 +14,22 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	
+	"shared/address"
+	"shared/htlc"
+	"shared/pow"
+	"shared/sighash"
+	"z-blockchain/x/utxo/ethash"
+	"z-blockchain/x/utxo/proofcache"
+	"z-blockchain/x/utxo/script"
 	"z-blockchain/x/utxo/types"
+	"z-blockchain/x/utxo/workerpool"
+	"z-blockchain/x/utxo/zkproof"
 	
 	// Hardware acceleration for zk-proofs
 	cysic "github.com/cysic-labs/zk-sdk-go"
 	// Equihash mining support
 	"github.com/zcash/librustzcash-go"
 	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type Keeper struct {
@@ -34,13 +40,41 @@ type Keeper struct {
 	bankKeeper types.BankKeeper
 	logger     log.Logger
 	
+	// Address allowed to review hardware flags, the gov module account
+	authority string
+	
 	// Hardware mining configuration
 	hardwareAcceleration bool
-	supportedDevices     map[string]bool // GPU/FPGA device IDs
 	
 	// Equihash mining
 	equihashMining *EquihashMiningKeeper
 	asicResistant  bool
+	
+	// In-memory filter of the nullifier set, shared by the keeper's copies
+	nullifiers *nullifierFilter
+	
+	// Pending transactions for balance queries, see SetMempoolView
+	mempool *mempoolHolder
+	
+	// Double spends refused in the current block, see RecordConflict
+	conflicts *conflictQueue
+	
+	// Ban scores of miners sending invalid solutions, see AddBanScore
+	banScores *banScores
+	
+	// Ethash caches verifying merged mined proofs, shared by the keeper's
+	// copies
+	ethashCaches *ethash.Caches
+	
+	// Workers verifying the proofs of proposals, one per core
+	verifiers *workerpool.Pool
+	
+	// Results of the mining solutions last checked, shared by the mempool
+	// and block execution
+	solutions *proofcache.Cache
+	
+	// Metrics of the block being delivered, see EmitBlockMetrics
+	metrics *blockMetrics
 }
 
 func NewKeeper(
@@ -50,6 +84,7 @@ func NewKeeper(
 	ps paramtypes.Subspace,
 	bankKeeper types.BankKeeper,
 	logger log.Logger,
+	authority string,
 ) *Keeper {
 	if !ps.HasKeyTable() {
 		ps = ps.WithKeyTable(types.ParamKeyTable())
@@ -62,22 +97,17 @@ func NewKeeper(
 		paramstore: ps,
 		bankKeeper: bankKeeper,
 		logger:     logger,
+		authority:  authority,
 		hardwareAcceleration: true,
 		asicResistant: true,
-		supportedDevices: map[string]bool{
-			// GPU devices (ASIC resistant)
-			"nvidia-rtx-3080":  true,
-			"nvidia-rtx-3090":  true,
-			"nvidia-rtx-4080":  true,
-			"nvidia-rtx-4090":  true,
-			"amd-rx-6800-xt":   true,
-			"amd-rx-6900-xt":   true,
-			"amd-rx-7800-xt":   true,
-			"amd-rx-7900-xtx":  true,
-			// Professional GPUs
-			"nvidia-a100": true,
-			"nvidia-h100": true,
-		},
+		nullifiers: newNullifierFilter(),
+		mempool: &mempoolHolder{},
+		conflicts: &conflictQueue{},
+		banScores: newBanScores(),
+		ethashCaches: ethash.NewCaches(ethashCacheEpochs),
+		verifiers: workerpool.New(0),
+		solutions: proofcache.New(maxCachedSolutions),
+		metrics: newBlockMetrics(),
 	}
 	
 	// Initialize Equihash mining
@@ -86,44 +116,169 @@ func NewKeeper(
 	return keeper
 }
 
-// ProcessUTXOTransaction validates and processes a UTXO transaction
-func (k Keeper) ProcessUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction) error {
+// NewUTXOTransaction is the transaction msg creates in the current block
+func (k Keeper) NewUTXOTransaction(ctx sdk.Context, msg *types.MsgSendUTXO) types.UTXOTransaction {
+	return types.UTXOTransaction{
+		TxHash:    k.generateTxHash(msg),
+		Inputs:    msg.Inputs,
+		Outputs:   msg.Outputs,
+		LockTime:  msg.LockTime,
+		Timestamp: ctx.BlockTime().Unix(),
+		Fee:       msg.Fee,
+		ZkProof:   msg.ZkProof,
+	}
+}
+
+// NewShieldTransaction is the transparent side of the transaction msg creates
+// in the current block: the inputs pay the shielded value to the pool address
+// after the change outputs
+func (k Keeper) NewShieldTransaction(ctx sdk.Context, msg *types.MsgShield) types.UTXOTransaction {
+	outputs := append([]*types.TxOutput{}, msg.Outputs...)
+	outputs = append(outputs, &types.TxOutput{Amount: msg.Value, Address: types.ShieldedPoolAddress})
+	return types.UTXOTransaction{
+		TxHash:    msg.Hash(),
+		Inputs:    msg.Inputs,
+		Outputs:   outputs,
+		LockTime:  msg.LockTime,
+		Timestamp: ctx.BlockTime().Unix(),
+		Fee:       msg.Fee,
+		ZkProof:   msg.ZkProof,
+	}
+}
+
+// CheckUTXOTransaction validates a UTXO transaction against the current state
+// without changing it and returns its fee. The ante handler runs it at CheckTx
+// so invalid spends never reach the mempool.
+func (k Keeper) CheckUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction) (sdk.Int, error) {
+	if !script.LockTimeReached(tx.LockTime, ctx.BlockHeight(), ctx.BlockTime().Unix()) {
+		return sdk.Int{}, fmt.Errorf("transaction locked until %d", tx.LockTime)
+	}
+	
 	// Validate transaction inputs
 	totalInput := sdk.ZeroInt()
-	for _, input := range tx.Inputs {
+	spent := make(map[string]bool, len(tx.Inputs))
+	for i, input := range tx.Inputs {
+		outpoint := fmt.Sprintf("%s:%d", input.PrevTxHash, input.PrevOutputIndex)
+		if spent[outpoint] {
+			return sdk.Int{}, fmt.Errorf("UTXO spent twice: %s", outpoint)
+		}
+		spent[outpoint] = true
+		
 		utxo, found := k.GetUTXO(ctx, input.PrevTxHash, input.PrevOutputIndex)
 		if !found {
-			return fmt.Errorf("UTXO not found: %s:%d", input.PrevTxHash, input.PrevOutputIndex)
+			return sdk.Int{}, fmt.Errorf("UTXO not found: %s", outpoint)
 		}
 		
 		if utxo.IsSpent {
-			return fmt.Errorf("UTXO already spent: %s:%d", input.PrevTxHash, input.PrevOutputIndex)
+			return sdk.Int{}, &types.ConflictError{TxHash: utxo.TxHash, OutputIndex: utxo.OutputIndex, SpentBy: utxo.SpentBy}
+		}
+		
+		if err := k.checkCoinbaseMaturity(ctx, utxo); err != nil {
+			return sdk.Int{}, err
+		}
+		if !script.SequenceLockReached(input.Sequence, ctx.BlockHeight(), ctx.BlockTime().Unix(), utxo.BlockHeight, utxo.CreatedAt) {
+			return sdk.Int{}, fmt.Errorf("UTXO %s relatively locked by sequence %d", outpoint, input.Sequence)
 		}
 		
 		// Verify script signature
-		if !k.VerifyScriptSig(input.ScriptSig, utxo.ScriptPubkey, tx.TxHash) {
-			return fmt.Errorf("invalid script signature")
+		if err := k.VerifyScriptSig(ctx, tx, i, utxo); err != nil {
+			return sdk.Int{}, fmt.Errorf("input %d: invalid script signature: %w", i, err)
 		}
 		
 		amount, ok := sdk.NewIntFromString(utxo.Amount)
 		if !ok {
-			return fmt.Errorf("invalid UTXO amount: %s", utxo.Amount)
+			return sdk.Int{}, fmt.Errorf("invalid UTXO amount: %s", utxo.Amount)
 		}
 		totalInput = totalInput.Add(amount)
-		
-		// Mark UTXO as spent
-		utxo.IsSpent = true
-		k.SetUTXO(ctx, utxo)
 	}
 	
-	// Validate transaction outputs
+	// Validate transaction outputs. Only data outputs may carry no value, and
+	// nothing may carry a negative one, or the outputs could exceed the inputs.
+	if err := k.checkDust(ctx, tx.Outputs); err != nil {
+		return sdk.Int{}, err
+	}
 	totalOutput := sdk.ZeroInt()
 	for i, output := range tx.Outputs {
 		amount, ok := sdk.NewIntFromString(output.Amount)
-		if !ok {
-			return fmt.Errorf("invalid output amount: %s", output.Amount)
+		if !ok || amount.IsNegative() || (amount.IsZero() && !script.Unspendable(output.ScriptPubkey)) {
+			return sdk.Int{}, fmt.Errorf("invalid output %d amount: %s", i, output.Amount)
 		}
 		totalOutput = totalOutput.Add(amount)
+	}
+	
+	// Validate transaction fee
+	fee, ok := sdk.NewIntFromString(tx.Fee)
+	if !ok || fee.IsNegative() {
+		return sdk.Int{}, fmt.Errorf("invalid fee: %s", tx.Fee)
+	}
+	
+	if !totalInput.Equal(totalOutput.Add(fee)) {
+		return sdk.Int{}, fmt.Errorf("input/output mismatch: input=%s, output=%s, fee=%s", 
+			totalInput, totalOutput, fee)
+	}
+	
+	return fee, nil
+}
+
+// ProcessUTXOTransaction validates and processes a UTXO transaction. The
+// transaction is fully validated before anything is written, and the writes go
+// to a cache committed only once all of them are made, so a failure never
+// leaves inputs spent without their outputs.
+func (k Keeper) ProcessUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction) error {
+	fee, err := k.CheckUTXOTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+	
+	return k.commitUTXOTransaction(ctx, tx, fee)
+}
+
+// commitUTXOTransaction writes a validated transaction and collects its fee
+// through a cache context, so a write failing half way leaves no trace
+func (k Keeper) commitUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction, fee sdk.Int) error {
+	cacheCtx, write := ctx.CacheContext()
+	if err := k.applyUTXOTransaction(cacheCtx, tx); err != nil {
+		return err
+	}
+	k.collectFee(cacheCtx, fee)
+	write()
+	return nil
+}
+
+// applyUTXOTransaction writes a validated transaction
+func (k Keeper) applyUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction) error {
+	// Mark the spent UTXOs
+	for _, input := range tx.Inputs {
+		utxo, found := k.GetUTXO(ctx, input.PrevTxHash, input.PrevOutputIndex)
+		if !found || utxo.IsSpent {
+			return fmt.Errorf("UTXO %s:%d is not spendable", input.PrevTxHash, input.PrevOutputIndex)
+		}
+		utxo.IsSpent = true
+		utxo.SpentBy = tx.TxHash
+		k.SetUTXO(ctx, utxo)
+		k.emitUTXOSpent(ctx, utxo, tx.TxHash)
+		if spend, err := htlc.ParseSpend(input.ScriptSig); err == nil {
+			k.emitHTLCSpent(ctx, utxo, spend, tx.TxHash)
+		}
+	}
+	
+	for i, output := range tx.Outputs {
+		// OP_RETURN outputs can never be spent, so they stay out of the UTXO
+		// set; the data they carry is indexed instead
+		if script.Unspendable(output.ScriptPubkey) {
+			k.setDataOutput(ctx, tx.TxHash, uint32(i), output.ScriptPubkey)
+			continue
+		}
+		
+		// Value paid to the pool address enters the shielded pool instead
+		if output.Address == types.ShieldedPoolAddress {
+			amount, ok := sdk.NewIntFromString(output.Amount)
+			if !ok {
+				return fmt.Errorf("invalid output amount: %s", output.Amount)
+			}
+			k.depositToPool(ctx, amount)
+			continue
+		}
 		
 		// Create new UTXO
 		newUTXO := types.UTXO{
@@ -138,17 +293,7 @@ func (k Keeper) ProcessUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction
 		}
 		
 		k.SetUTXO(ctx, newUTXO)
-	}
-	
-	// Validate transaction fee
-	fee, ok := sdk.NewIntFromString(tx.Fee)
-	if !ok {
-		return fmt.Errorf("invalid fee: %s", tx.Fee)
-	}
-	
-	if !totalInput.Equal(totalOutput.Add(fee)) {
-		return fmt.Errorf("input/output mismatch: input=%s, output=%s, fee=%s", 
-			totalInput, totalOutput, fee)
+		k.emitUTXOCreated(ctx, newUTXO)
 	}
 	
 	// Store transaction
@@ -159,27 +304,57 @@ func (k Keeper) ProcessUTXOTransaction(ctx sdk.Context, tx types.UTXOTransaction
 
 // ProcessShieldedTransaction handles privacy-preserving transactions
 func (k Keeper) ProcessShieldedTransaction(ctx sdk.Context, tx types.ShieldedTransaction) error {
+	// The spent notes must be proved against a root the tree had
+	if len(tx.Nullifiers) > 0 && !k.IsValidAnchor(ctx, tx.Anchor) {
+		return fmt.Errorf("unknown anchor: %x", tx.Anchor)
+	}
+	
 	// Verify zk-SNARK proof for shielded transaction
-	if !k.VerifyShieldedProof(ctx, tx.ZkProof, tx.Nullifiers, tx.Commitments) {
-		return fmt.Errorf("invalid shielded transaction proof")
+	if err := k.VerifyShieldedProof(tx); err != nil {
+		return err
 	}
+	k.countProof(ctx, ProofKindShielded)
+	
+	// Nullifiers are written to a cache so one repeated within the transaction
+	// fails it without leaving the others spent
+	cacheCtx, write := ctx.CacheContext()
 	
 	// Check nullifiers to prevent double spending
 	for _, nullifier := range tx.Nullifiers {
-		if k.IsNullifierUsed(ctx, nullifier) {
+		if k.IsNullifierUsed(cacheCtx, nullifier) {
 			return fmt.Errorf("nullifier already used: %x", nullifier)
 		}
-		k.SetNullifier(ctx, nullifier)
+		k.SetNullifier(cacheCtx, nullifier)
 	}
 	
 	// Add commitments to the commitment tree
 	for _, commitment := range tx.Commitments {
-		k.AddCommitment(ctx, commitment)
+		position, err := k.AddCommitment(cacheCtx, commitment)
+		if err != nil {
+			return err
+		}
+		k.appendShieldedOutput(cacheCtx, types.ShieldedOutput{
+			Position:   position,
+			Commitment: commitment,
+			Ciphertext: tx.EncryptedMemo,
+			TxHash:     tx.TxHash,
+		})
+	}
+	
+	// Move the value balance across the turnstile
+	if err := k.applyValueBalance(cacheCtx, tx); err != nil {
+		return err
 	}
 	
 	// Store shielded transaction
-	k.SetShieldedTransaction(ctx, tx)
+	k.SetShieldedTransaction(cacheCtx, tx)
 	
+	// The fee leaves the shielded pool as part of the block's fees
+	if fee, ok := sdk.NewIntFromString(tx.Fee); ok {
+		k.collectFee(cacheCtx, fee)
+	}
+	
+	write()
 	return nil
 }
 
@@ -200,40 +375,34 @@ func (k Keeper) VerifyMiningProof(ctx sdk.Context, proof types.MiningProof) bool
 	)
 }
 
-// VerifyShieldedProof verifies zk-SNARK proof for shielded transactions
-func (k Keeper) VerifyShieldedProof(ctx sdk.Context, zkProof []byte, nullifiers [][]byte, commitments [][]byte) bool {
-	// Combine nullifiers and commitments as public inputs
-	publicInputs := make([]byte, 0)
-	for _, nullifier := range nullifiers {
-		publicInputs = append(publicInputs, nullifier...)
-	}
-	for _, commitment := range commitments {
-		publicInputs = append(publicInputs, commitment...)
+// VerifyShieldedProof verifies the Groth16 proof of a shielded transaction
+// against its anchor, nullifiers, commitments and value balance, see zkproof
+func (k Keeper) VerifyShieldedProof(tx types.ShieldedTransaction) error {
+	valueBalance, ok := sdk.NewIntFromString(tx.ValueBalance)
+	if !ok {
+		return fmt.Errorf("invalid value balance: %s", tx.ValueBalance)
 	}
 	
-	// Add block context
-	blockHash := ctx.BlockHeader().Hash()
-	publicInputs = append(publicInputs, blockHash[:]...)
-	
-	return cysic.VerifyShieldedProof(zkProof, publicInputs)
+	defer MeasureProofVerification(ProofKindShielded, time.Now())
+	return zkproof.Verify(tx.ZkProof, zkproof.PublicInputs{
+		Anchor:       tx.Anchor,
+		Nullifiers:   tx.Nullifiers,
+		Commitments:  tx.Commitments,
+		ValueBalance: valueBalance.BigInt(),
+	})
 }
 
 // DistributeMiningReward distributes Z tokens to miners
 func (k Keeper) DistributeMiningReward(ctx sdk.Context, miner sdk.AccAddress, hardwareId string) error {
 	baseReward := k.CalculateBlockReward(ctx.BlockHeight())
 	
-	// Hardware acceleration bonus
-	hardwareBonus := k.GetHardwareBonus(hardwareId)
+	// Hardware acceleration bonus, unless the miner's audits flagged it
+	hardwareBonus := k.MinerHardwareBonus(ctx, miner.String(), hardwareId)
 	totalReward := baseReward.Add(hardwareBonus)
 	
-	// Mint Z tokens
-	coins := sdk.NewCoins(sdk.NewCoin("z", totalReward))
-	if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
-		return err
-	}
-	
-	// Send to miner
-	if err := k.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, miner, coins); err != nil {
+	// Pay as a coinbase output, spendable once mature
+	totalReward, err := k.PayCoinbase(ctx, miner, totalReward)
+	if err != nil {
 		return err
 	}
 	
@@ -250,6 +419,7 @@ func (k Keeper) DistributeMiningReward(ctx sdk.Context, miner sdk.AccAddress, ha
 
 // NotifyNuChainMining sends mining activity notification to nuChain
 func (k Keeper) NotifyNuChainMining(ctx sdk.Context, miner sdk.AccAddress, reward sdk.Int, hardwareId string) error {
+	k.countCrossChainMessage(ctx)
 	// This would use LayerZero to send cross-chain message
 	// Implementation depends on LayerZero integration setup
 	k.logger.Info("Hardware mining notification sent to nuChain",
@@ -261,35 +431,10 @@ func (k Keeper) NotifyNuChainMining(ctx sdk.Context, miner sdk.AccAddress, rewar
 	return nil
 }
 
-// CalculateBlockReward implements halving mechanism for Z tokens
+// CalculateBlockReward returns the block reward of the emission schedule at
+// height, before the hard cap, see pow.Emission
 func (k Keeper) CalculateBlockReward(height int64) sdk.Int {
-	halvingInterval := int64(210000000) // 210M blocks
-	halvings := height / halvingInterval
-	
-	// Initial reward: 0.05 Z * 10^18 wei
-	initialReward := sdk.NewInt(50000000000000000)
-	
-	if halvings >= 64 {
-		return sdk.ZeroInt()
-	}
-	
-	divisor := sdk.NewInt(1 << uint(halvings))
-	return initialReward.Quo(divisor)
-}
-
-// GetHardwareBonus returns bonus reward for hardware acceleration
-func (k Keeper) GetHardwareBonus(hardwareId string) sdk.Int {
-	bonuses := map[string]int64{
-		"nvidia-a100": 5000000000000000,  // 0.005 Z bonus
-		"nvidia-h100": 10000000000000000, // 0.01 Z bonus
-		"xilinx-fpga": 15000000000000000, // 0.015 Z bonus
-	}
-	
-	if bonus, exists := bonuses[hardwareId]; exists {
-		return sdk.NewInt(bonus)
-	}
-	
-	return sdk.ZeroInt()
+	return sdk.NewIntFromBigInt(pow.DefaultEmission().Schedule.Reward(height))
 }
 
 // UTXO management functions
@@ -307,50 +452,70 @@ func (k Keeper) GetUTXO(ctx sdk.Context, txHash string, outputIndex uint32) (typ
 	return utxo, true
 }
 
+// SetUTXO stores utxo and keeps the address index to the unspent outputs
 func (k Keeper) SetUTXO(ctx sdk.Context, utxo types.UTXO) {
 	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.UTXOKey))
 	key := fmt.Sprintf("%s:%d", utxo.TxHash, utxo.OutputIndex)
 	
 	bz := k.cdc.MustMarshal(&utxo)
 	store.Set([]byte(key), bz)
-}
-
-// Script verification (simplified)
-func (k Keeper) VerifyScriptSig(scriptSig []byte, scriptPubkey []byte, txHash string) bool {
-	// Simplified script verification - implement full Bitcoin-style script engine
-	if len(scriptSig) == 0 || len(scriptPubkey) == 0 {
-		return false
-	}
 	
-	// For now, verify ECDSA signature
-	if len(scriptSig) >= 64 {
-		signature := scriptSig[:64]
-		pubkey := scriptSig[64:]
-		
-		hash := sha256.Sum256([]byte(txHash))
-		return crypto.VerifySignature(pubkey, hash[:], signature)
+	index := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOByAddressPrefix(utxo.Address))
+	wasUnspent := index.Has([]byte(key))
+	if utxo.IsSpent {
+		index.Delete([]byte(key))
+		if wasUnspent {
+			k.addCount(ctx, types.UTXOCountKey, -1)
+		}
+	} else {
+		index.Set([]byte(key), []byte{1})
+		if !wasUnspent {
+			k.addCount(ctx, types.UTXOCountKey, 1)
+		}
 	}
 	
-	return false
-}
-
-// Nullifier management for shielded transactions
-func (k Keeper) IsNullifierUsed(ctx sdk.Context, nullifier []byte) bool {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.NullifierKey))
-	return store.Has(nullifier)
+	k.updateUTXOSet(ctx, utxo, bz)
 }
 
-func (k Keeper) SetNullifier(ctx sdk.Context, nullifier []byte) {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.NullifierKey))
-	store.Set(nullifier, []byte{1})
+// VerifyScriptSig checks that input index of tx satisfies the locking script
+// of the UTXO it spends. Outputs without a script are locked to their address
+// and pay to its public key hash. Signatures commit to the sighash of the
+// input, which includes the chain ID, so they cannot be replayed elsewhere.
+func (k Keeper) VerifyScriptSig(ctx sdk.Context, tx types.UTXOTransaction, index int, utxo types.UTXO) error {
+	scriptPubkey := utxo.ScriptPubkey
+	if len(scriptPubkey) == 0 {
+		owner, err := address.ParseZChain(utxo.Address)
+		if err != nil {
+			return err
+		}
+		scriptPubkey = sighash.AddressScript(owner)
+	}
+	
+	return script.Verify(tx.Inputs[index].ScriptSig, scriptPubkey, &script.Context{
+		Tx:         scriptTx(ctx.ChainID(), tx),
+		InputIndex: index,
+		Amount:     utxo.Amount,
+		Height:     ctx.BlockHeight(),
+		Time:       ctx.BlockTime().Unix(),
+	})
 }
 
-// Commitment tree management
-func (k Keeper) AddCommitment(ctx sdk.Context, commitment []byte) {
-	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.CommitmentKey))
-	height := ctx.BlockHeight()
-	key := append(commitment, sdk.Uint64ToBigEndian(uint64(height))...)
-	store.Set(key, []byte{1})
+// scriptTx is the view of tx that signatures commit to
+func scriptTx(chainID string, tx types.UTXOTransaction) *script.Tx {
+	stx := &script.Tx{
+		ChainID:  chainID,
+		Inputs:   make([]script.Input, len(tx.Inputs)),
+		Outputs:  make([]script.Output, len(tx.Outputs)),
+		Fee:      tx.Fee,
+		LockTime: tx.LockTime,
+	}
+	for i, input := range tx.Inputs {
+		stx.Inputs[i] = script.Input{TxHash: input.PrevTxHash, Index: input.PrevOutputIndex, Sequence: input.Sequence}
+	}
+	for i, output := range tx.Outputs {
+		stx.Outputs[i] = script.Output{Amount: output.Amount, Address: output.Address, ScriptPubkey: output.ScriptPubkey}
+	}
+	return stx
 }
 
 // Transaction storage
@@ -360,6 +525,30 @@ func (k Keeper) SetTransaction(ctx sdk.Context, tx types.UTXOTransaction) {
 	store.Set([]byte(tx.TxHash), bz)
 }
 
+func (k Keeper) GetTransaction(ctx sdk.Context, txHash string) (types.UTXOTransaction, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.TransactionKey))
+	bz := store.Get([]byte(txHash))
+	if bz == nil {
+		return types.UTXOTransaction{}, false
+	}
+	
+	var tx types.UTXOTransaction
+	k.cdc.MustUnmarshal(bz, &tx)
+	return tx, true
+}
+
+func (k Keeper) GetShieldedTransaction(ctx sdk.Context, txHash string) (types.ShieldedTransaction, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.ShieldedTxKey))
+	bz := store.Get([]byte(txHash))
+	if bz == nil {
+		return types.ShieldedTransaction{}, false
+	}
+	
+	var tx types.ShieldedTransaction
+	k.cdc.MustUnmarshal(bz, &tx)
+	return tx, true
+}
+
 func (k Keeper) SetShieldedTransaction(ctx sdk.Context, tx types.ShieldedTransaction) {
 	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.ShieldedTxKey))
 	bz := k.cdc.MustMarshal(&tx)
@@ -369,31 +558,21 @@ func (k Keeper) SetShieldedTransaction(ctx sdk.Context, tx types.ShieldedTransac
 // Difficulty adjustment
 func (k Keeper) GetDifficulty(ctx sdk.Context) uint64 {
 	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.DifficultyKey))
-	bz := store.Get(types.DifficultyKey)
-	if bz == nil {
-		return 1000000 // Default difficulty
-	}
-	return binary.BigEndian.Uint64(bz)
+	return pow.GetDifficulty(store, types.DifficultyKey)
 }
 
 func (k Keeper) SetDifficulty(ctx sdk.Context, difficulty uint64) {
 	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefix(types.DifficultyKey))
-	bz := make([]byte, 8)
-	binary.BigEndian.PutUint64(bz, difficulty)
-	store.Set(types.DifficultyKey, bz)
-}
-
-// Mining statistics
-func (k Keeper) UpdateMiningStats(ctx sdk.Context, miner sdk.AccAddress, hardwareId string, reward sdk.Int) {
-	// Update miner statistics for monitoring and analytics
-	k.logger.Info("Mining reward distributed",
-		"miner", miner.String(),
-		"hardware", hardwareId,
-		"reward", reward.String(),
-		"block_height", ctx.BlockHeight())
+	pow.SetDifficulty(store, types.DifficultyKey, difficulty)
 }
 
 // Logger returns the keeper's logger
 func (k Keeper) Logger(ctx sdk.Context) log.Logger {
 	return k.logger.With("module", fmt.Sprintf("x/%s", types.ModuleName))
+}
+
+// GetAuthority returns the address allowed to review hardware flags and spend
+// the treasury
+func (k Keeper) GetAuthority() string {
+	return k.authority
 }
\ No newline at end of file
@@ -0,0 +1,153 @@
+package keeper
+
+import (
+	"testing"
+
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+
+	"z-blockchain/x/utxo/script"
+	"z-blockchain/x/utxo/types"
+)
+
+// setupKeeper returns a keeper over an in-memory store, without a bank
+// keeper, and a context at height 10
+func setupKeeper(t *testing.T) (Keeper, sdk.Context) {
+	t.Helper()
+	storeKey := storetypes.NewKVStoreKey(types.StoreKey)
+	memKey := storetypes.NewMemoryStoreKey(types.MemStoreKey)
+	tkey := storetypes.NewTransientStoreKey("transient_test")
+	ctx := testutil.DefaultContext(storeKey, tkey).WithBlockHeight(10)
+
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	ps := paramtypes.NewSubspace(cdc, codec.NewLegacyAmino(), storeKey, tkey, types.ModuleName)
+	k := NewKeeper(cdc, storeKey, memKey, ps, nil, log.NewNopLogger(), "")
+	return *k, ctx
+}
+
+func TestCommitUTXOTransactionLeavesNoTraceOnFailure(t *testing.T) {
+	funding := types.UTXO{TxHash: "funding", OutputIndex: 0, Address: "z1owner", Amount: "1000", BlockHeight: 1}
+	spend := &types.TxInput{PrevTxHash: "funding", PrevOutputIndex: 0}
+
+	cases := []struct {
+		name string
+		tx   types.UTXOTransaction
+	}{
+		// The first input is marked spent before the second finds it spent
+		{"input spent twice", types.UTXOTransaction{
+			TxHash:  "double",
+			Inputs:  []*types.TxInput{spend, spend},
+			Outputs: []*types.TxOutput{{Address: "z1payee", Amount: "990"}},
+		}},
+		// The input is spent and an output created before the deposit to
+		// the pool fails
+		{"invalid pool deposit", types.UTXOTransaction{
+			TxHash: "deposit",
+			Inputs: []*types.TxInput{spend},
+			Outputs: []*types.TxOutput{
+				{Address: "z1payee", Amount: "490"},
+				{Address: types.ShieldedPoolAddress, Amount: "five hundred"},
+			},
+		}},
+	}
+	for _, c := range cases {
+		k, ctx := setupKeeper(t)
+		k.SetUTXO(ctx, funding)
+		count := k.GetUTXOCount(ctx)
+
+		if err := k.commitUTXOTransaction(ctx, c.tx, sdk.NewInt(10)); err == nil {
+			t.Fatalf("%s: applied", c.name)
+		}
+
+		if utxo, _ := k.GetUTXO(ctx, "funding", 0); utxo.IsSpent || utxo.SpentBy != "" {
+			t.Errorf("%s: input left spent by %q", c.name, utxo.SpentBy)
+		}
+		if _, found := k.GetUTXO(ctx, c.tx.TxHash, 0); found {
+			t.Errorf("%s: output created", c.name)
+		}
+		if _, found := k.GetTransaction(ctx, c.tx.TxHash); found {
+			t.Errorf("%s: transaction stored", c.name)
+		}
+		if got := k.GetUTXOCount(ctx); got != count {
+			t.Errorf("%s: UTXO count %d, want %d", c.name, got, count)
+		}
+		if fees := k.getBlockFees(ctx); !fees.IsZero() {
+			t.Errorf("%s: collected %s in fees", c.name, fees)
+		}
+		if events := ctx.EventManager().Events(); len(events) != 0 {
+			t.Errorf("%s: emitted %d events", c.name, len(events))
+		}
+	}
+}
+
+func TestCommitUTXOTransactionWritesEverything(t *testing.T) {
+	k, ctx := setupKeeper(t)
+	k.SetUTXO(ctx, types.UTXO{TxHash: "funding", OutputIndex: 0, Address: "z1owner", Amount: "1000", BlockHeight: 1})
+
+	tx := types.UTXOTransaction{
+		TxHash:  "spend",
+		Inputs:  []*types.TxInput{{PrevTxHash: "funding", PrevOutputIndex: 0}},
+		Outputs: []*types.TxOutput{{Address: "z1payee", Amount: "990"}},
+	}
+	if err := k.commitUTXOTransaction(ctx, tx, sdk.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	if utxo, _ := k.GetUTXO(ctx, "funding", 0); !utxo.IsSpent || utxo.SpentBy != "spend" {
+		t.Errorf("input spent %t by %q", utxo.IsSpent, utxo.SpentBy)
+	}
+	if utxo, found := k.GetUTXO(ctx, "spend", 0); !found || utxo.Amount != "990" || utxo.BlockHeight != 10 {
+		t.Errorf("output %+v found %t", utxo, found)
+	}
+	if _, found := k.GetTransaction(ctx, "spend"); !found {
+		t.Error("transaction not stored")
+	}
+	if fees := k.getBlockFees(ctx); !fees.Equal(sdk.NewInt(10)) {
+		t.Errorf("collected %s in fees, want 10", fees)
+	}
+}
+
+func TestCheckUTXOTransactionRefusesMinting(t *testing.T) {
+	// Anyone can spend the funding output, so only the amounts are checked
+	funding := types.UTXO{TxHash: "funding", OutputIndex: 0, Address: "z1owner", Amount: "1000", BlockHeight: 1, ScriptPubkey: []byte{script.OP_1}}
+	inputs := []*types.TxInput{{PrevTxHash: "funding", PrevOutputIndex: 0}}
+	data, err := script.NullDataScript([]byte("z chain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		outputs []*types.TxOutput
+		fee     string
+		valid   bool
+	}{
+		{"balanced", []*types.TxOutput{{Address: "z1payee", Amount: "990"}}, "10", true},
+		{"data output without value", []*types.TxOutput{{Address: "z1payee", Amount: "990"}, {Address: "z1payee", Amount: "0", ScriptPubkey: data}}, "10", true},
+		{"negative fee", []*types.TxOutput{{Address: "z1payee", Amount: "1010"}}, "-10", false},
+		{"negative output", []*types.TxOutput{{Address: "z1payee", Amount: "1020"}, {Address: "z1payee", Amount: "-30"}}, "10", false},
+		{"zero output", []*types.TxOutput{{Address: "z1payee", Amount: "990"}, {Address: "z1payee", Amount: "0"}}, "10", false},
+	}
+	for _, c := range cases {
+		k, ctx := setupKeeper(t)
+		params := types.DefaultParams()
+		params.DustLimit = "0"
+		k.SetParams(ctx, params)
+		k.SetUTXO(ctx, funding)
+
+		tx := types.UTXOTransaction{TxHash: "spend", Inputs: inputs, Outputs: c.outputs, Fee: c.fee}
+		_, err := k.CheckUTXOTransaction(ctx, tx)
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package keeper
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// Memory audit outcomes reported in events
+const (
+	auditPassed = "passed"
+	auditSlow   = "slow"
+	auditFailed = "failed"
+	auditMissed = "missed"
+)
+
+// GetMinerProfile returns the memory audit state of a miner
+func (k Keeper) GetMinerProfile(ctx sdk.Context, miner string) (types.MinerProfile, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MinerProfileKey)
+	bz := store.Get([]byte(miner))
+	if bz == nil {
+		return types.MinerProfile{}, false
+	}
+
+	var profile types.MinerProfile
+	k.cdc.MustUnmarshal(bz, &profile)
+	return profile, true
+}
+
+func (k Keeper) setMinerProfile(ctx sdk.Context, profile types.MinerProfile) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MinerProfileKey)
+	bz := k.cdc.MustMarshal(&profile)
+	store.Set([]byte(profile.Miner), bz)
+}
+
+// GetMemoryChallenge returns the open challenge of a miner
+func (k Keeper) GetMemoryChallenge(ctx sdk.Context, miner string) (types.MemoryChallenge, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MemoryChallengeKey)
+	bz := store.Get([]byte(miner))
+	if bz == nil {
+		return types.MemoryChallenge{}, false
+	}
+
+	var challenge types.MemoryChallenge
+	k.cdc.MustUnmarshal(bz, &challenge)
+	return challenge, true
+}
+
+func (k Keeper) setMemoryChallenge(ctx sdk.Context, challenge types.MemoryChallenge) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MemoryChallengeKey)
+	bz := k.cdc.MustMarshal(&challenge)
+	store.Set([]byte(challenge.Miner), bz)
+}
+
+func (k Keeper) deleteMemoryChallenge(ctx sdk.Context, miner string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MemoryChallengeKey)
+	store.Delete([]byte(miner))
+}
+
+// RecordMinedBlock marks a miner as active so it is included in the next audit
+func (k Keeper) RecordMinedBlock(ctx sdk.Context, miner, hardwareId string) {
+	profile, found := k.GetMinerProfile(ctx, miner)
+	if !found {
+		profile = types.MinerProfile{
+			Miner:        miner,
+			MemoryFactor: sdk.OneDec().String(),
+		}
+	}
+
+	profile.HardwareId = hardwareId
+	profile.LastMinedHeight = ctx.BlockHeight()
+	k.setMinerProfile(ctx, profile)
+}
+
+// MemoryFactor returns the share of its rewards a miner keeps. Miners that
+// were never audited keep everything.
+func (k Keeper) MemoryFactor(ctx sdk.Context, miner string) sdk.Dec {
+	profile, found := k.GetMinerProfile(ctx, miner)
+	if !found {
+		return sdk.OneDec()
+	}
+
+	factor, err := sdk.NewDecFromStr(profile.MemoryFactor)
+	if err != nil {
+		return sdk.OneDec()
+	}
+	return factor
+}
+
+// IssueMemoryChallenges challenges every miner that found a block during the
+// last audit interval. The seed depends on the previous block hash, so the
+// buffer cannot be filled ahead of time.
+func (k Keeper) IssueMemoryChallenges(ctx sdk.Context) {
+	params := k.GetParams(ctx)
+	height := ctx.BlockHeight()
+	if height%params.AuditIntervalBlocks != 0 {
+		return
+	}
+
+	var active []types.MinerProfile
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MinerProfileKey)
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		var profile types.MinerProfile
+		k.cdc.MustUnmarshal(iterator.Value(), &profile)
+		if profile.LastMinedHeight > height-params.AuditIntervalBlocks {
+			active = append(active, profile)
+		}
+	}
+	iterator.Close()
+
+	for _, profile := range active {
+		if _, open := k.GetMemoryChallenge(ctx, profile.Miner); open {
+			continue
+		}
+
+		seed := types.MemoryChallengeSeed(ctx.BlockHeader().LastBlockId.Hash, profile.Miner, height)
+		challenge := types.MemoryChallenge{
+			Miner:          profile.Miner,
+			Seed:           seed,
+			MemoryBlocks:   types.ChallengeMemoryBlocks(seed, params.AuditMemoryBlocks, params.AuditMemoryScales),
+			Samples:        params.AuditSamples,
+			IssuedHeight:   height,
+			IssuedTime:     ctx.BlockTime().UnixMilli(),
+			DeadlineHeight: height + params.AuditWindowBlocks,
+		}
+		k.setMemoryChallenge(ctx, challenge)
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeMemoryChallenge,
+				sdk.NewAttribute(types.AttributeKeyMiner, challenge.Miner),
+				sdk.NewAttribute(types.AttributeKeySeed, hex.EncodeToString(challenge.Seed)),
+				sdk.NewAttribute(types.AttributeKeyMemoryBlocks, fmt.Sprintf("%d", challenge.MemoryBlocks)),
+				sdk.NewAttribute(types.AttributeKeyDeadlineHeight, fmt.Sprintf("%d", challenge.DeadlineHeight)),
+			),
+		)
+	}
+}
+
+// ExpireMemoryChallenges penalises miners that let their challenge run out
+func (k Keeper) ExpireMemoryChallenges(ctx sdk.Context) {
+	var expired []types.MemoryChallenge
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MemoryChallengeKey)
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		var challenge types.MemoryChallenge
+		k.cdc.MustUnmarshal(iterator.Value(), &challenge)
+		if ctx.BlockHeight() > challenge.DeadlineHeight {
+			expired = append(expired, challenge)
+		}
+	}
+	iterator.Close()
+
+	for _, challenge := range expired {
+		k.deleteMemoryChallenge(ctx, challenge.Miner)
+		k.settleMemoryAudit(ctx, challenge, auditMissed, 0)
+	}
+}
+
+// RespondMemoryChallenge checks a miner's response to its open challenge. A
+// wrong buffer fails the audit; a correct one that took longer than the target
+// for its buffer size de-rates the miner in proportion to how slow it was.
+func (k Keeper) RespondMemoryChallenge(ctx sdk.Context, miner string, root []byte, openings []*types.MemoryOpening) (types.MinerProfile, bool, int64, error) {
+	challenge, found := k.GetMemoryChallenge(ctx, miner)
+	if !found {
+		return types.MinerProfile{}, false, 0, fmt.Errorf("no open memory challenge for %s", miner)
+	}
+	if len(openings) != int(challenge.Samples) {
+		return types.MinerProfile{}, false, 0, fmt.Errorf("response must open %d blocks, got %d", challenge.Samples, len(openings))
+	}
+
+	elapsed := ctx.BlockTime().UnixMilli() - challenge.IssuedTime
+	k.deleteMemoryChallenge(ctx, miner)
+
+	result := auditPassed
+	indices := types.MemorySampleIndices(challenge.Seed, root, challenge.MemoryBlocks, challenge.Samples)
+	for i, index := range indices {
+		if err := types.VerifyMemoryOpening(challenge.Seed, root, challenge.MemoryBlocks, index, *openings[i]); err != nil {
+			k.Logger(ctx).Info("Memory audit failed", "miner", miner, "error", err)
+			result = auditFailed
+			break
+		}
+	}
+	if result == auditPassed && elapsed > responseTarget(k.GetParams(ctx), challenge) {
+		result = auditSlow
+	}
+
+	profile := k.settleMemoryAudit(ctx, challenge, result, elapsed)
+	return profile, result == auditPassed, elapsed, nil
+}
+
+// responseTarget is the time a challenge must be answered in: the target
+// response time, scaled by how many times the smallest buffer it is
+func responseTarget(params types.Params, challenge types.MemoryChallenge) int64 {
+	scale := int64(challenge.MemoryBlocks / params.AuditMemoryBlocks)
+	if scale < 1 {
+		scale = 1
+	}
+	return params.TargetResponseMillis * scale
+}
+
+// settleMemoryAudit updates the miner's memory factor for an audit outcome.
+// A correct response, slow or not, adds to the profile checked for ASICs.
+func (k Keeper) settleMemoryAudit(ctx sdk.Context, challenge types.MemoryChallenge, result string, elapsed int64) types.MinerProfile {
+	params := k.GetParams(ctx)
+	miner := challenge.Miner
+
+	profile, found := k.GetMinerProfile(ctx, miner)
+	if !found {
+		profile = types.MinerProfile{Miner: miner}
+	}
+	factor := k.MemoryFactor(ctx, miner)
+	floor := sdk.MustNewDecFromStr(params.MinMemoryFactor)
+
+	switch result {
+	case auditPassed:
+		factor = sdk.MinDec(sdk.OneDec(), factor.Add(sdk.MustNewDecFromStr(params.AuditRecoveryStep)))
+		profile.ConsecutiveFailures = 0
+	case auditSlow:
+		// Memory-constrained hardware trades time for space; scale by the slowdown
+		factor = sdk.MinDec(factor, sdk.NewDec(responseTarget(params, challenge)).QuoInt64(elapsed))
+		profile.ConsecutiveFailures = 0
+	default:
+		factor = factor.Mul(sdk.MustNewDecFromStr(params.AuditFailurePenalty))
+		profile.ConsecutiveFailures++
+	}
+	if factor.LT(floor) {
+		factor = floor
+	}
+
+	profile.MemoryFactor = factor.String()
+	profile.LastAuditHeight = ctx.BlockHeight()
+	if result == auditPassed || result == auditSlow {
+		profile.AuditSamples = append(profile.AuditSamples, types.AuditSample{MemoryBlocks: challenge.MemoryBlocks, ResponseMillis: elapsed})
+		if excess := len(profile.AuditSamples) - int(params.AsicProfileAudits); excess > 0 {
+			profile.AuditSamples = profile.AuditSamples[excess:]
+		}
+	}
+	k.setMinerProfile(ctx, profile)
+	k.checkASICProfile(ctx, profile, params)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeMemoryAudit,
+			sdk.NewAttribute(types.AttributeKeyMiner, miner),
+			sdk.NewAttribute(types.AttributeKeyAuditResult, result),
+			sdk.NewAttribute(types.AttributeKeyResponseTime, fmt.Sprintf("%d", elapsed)),
+			sdk.NewAttribute(types.AttributeKeyMemoryFactor, profile.MemoryFactor),
+		),
+	)
+
+	return profile
+}
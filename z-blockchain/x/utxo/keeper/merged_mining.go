@@ -0,0 +1,159 @@
+package keeper
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/ethash"
+	"z-blockchain/x/utxo/types"
+)
+
+// Generating the Ethash cache of an epoch takes seconds and memory growing
+// with the epoch, so a header is only verified if its epoch is within
+// auxPowEpochWindow epochs of the Altcoinchain tip: headers mined across an
+// epoch change, or ahead of the headers accepted so far, pass, while a header
+// claiming a far block number is refused before any cache is generated.
+const (
+	auxPowEpochWindow = 1
+
+	// ethashCacheEpochs keeps a cache for every epoch of the window, so
+	// headers within it never evict one another's
+	ethashCacheEpochs = 2*auxPowEpochWindow + 1
+
+	// auxPowTipLead is how far past the MergedMiningAnchor param the headers
+	// of accepted proofs may move the tip. Their block numbers are only the
+	// miners' claims, so without governance the window moves one epoch at
+	// most, however many proofs claim higher ones.
+	auxPowTipLead = ethash.EpochLength
+)
+
+// mergedMining returns the ProofOfWork of merged mined proofs, measured
+// against the target of native, or an error if governance disabled merged
+// mining
+func (k Keeper) mergedMining(ctx sdk.Context, native types.ProofOfWork) (types.ProofOfWork, error) {
+	params := k.GetParams(ctx)
+	if params.MergedMiningFactor == 0 {
+		return nil, fmt.Errorf("merged mining is disabled")
+	}
+
+	tip := ethash.Epoch(k.auxPowTip(ctx, params))
+	minEpoch := uint64(0)
+	if tip > auxPowEpochWindow {
+		minEpoch = tip - auxPowEpochWindow
+	}
+	return mergedPoW{
+		native:   native,
+		factor:   params.MergedMiningFactor,
+		minEpoch: minEpoch,
+		maxEpoch: tip + auxPowEpochWindow,
+		caches:   k.ethashCaches,
+	}, nil
+}
+
+// AuxPowTip returns the Altcoinchain block number merged mined headers are
+// checked around: the highest of the headers of proofs the chain accepted for
+// its blocks, at most auxPowTipLead past the MergedMiningAnchor param, or the
+// anchor if governance set it higher
+func (k Keeper) AuxPowTip(ctx sdk.Context) uint64 {
+	return k.auxPowTip(ctx, k.GetParams(ctx))
+}
+
+func (k Keeper) auxPowTip(ctx sdk.Context, params types.Params) uint64 {
+	tip := params.MergedMiningAnchor
+	if bz := ctx.KVStore(k.storeKey).Get(types.AuxPowTipKey); bz != nil {
+		if accepted := sdk.BigEndianToUint64(bz); accepted > tip {
+			tip = min(accepted, maxAuxPowTip(params))
+		}
+	}
+	return tip
+}
+
+// maxAuxPowTip returns the highest tip accepted headers may move to
+func maxAuxPowTip(params types.Params) uint64 {
+	if params.MergedMiningAnchor > math.MaxUint64-auxPowTipLead {
+		return math.MaxUint64
+	}
+	return params.MergedMiningAnchor + auxPowTipLead
+}
+
+// advanceAuxPowTip moves the Altcoinchain tip to the header of an accepted
+// merged mined proof for the block if it is higher, up to auxPowTipLead past
+// the anchor, so the window follows Altcoinchain across an epoch change
+// without governance. Uncles, mined for past blocks, never move it.
+func (k Keeper) advanceAuxPowTip(ctx sdk.Context, proof types.MiningProof) {
+	if proof.Height < ctx.BlockHeight() {
+		return
+	}
+	header, err := types.DecodeAuxPowHeader(proof.AuxPow)
+	if err != nil {
+		return
+	}
+	params := k.GetParams(ctx)
+	number := min(header.Number.Uint64(), maxAuxPowTip(params))
+	if number > k.auxPowTip(ctx, params) {
+		ctx.KVStore(k.storeKey).Set(types.AuxPowTipKey, sdk.Uint64ToBigEndian(number))
+	}
+}
+
+// mergedPoW verifies Altcoinchain headers merge mining zChain: the extra data
+// of the header commits to the header challenge of the proof, and its Ethash
+// result, scaled by the MergedMiningFactor param, is its solution hash. A
+// result MergedMiningFactor times below the native target thus meets it, and
+// merged mined proofs compete for blocks with native ones on equal terms.
+type mergedPoW struct {
+	native types.ProofOfWork
+	factor uint64
+
+	// Ethash epochs of the window around the Altcoinchain tip
+	minEpoch, maxEpoch uint64
+
+	caches *ethash.Caches
+}
+
+func (m mergedPoW) PrepareChallenge(proof types.MiningProof, difficulty uint64) []byte {
+	return types.HeaderChallenge(proof, difficulty)
+}
+
+func (m mergedPoW) VerifySolution(challenge []byte, proof types.MiningProof) ([]byte, error) {
+	header, err := types.DecodeAuxPowHeader(proof.AuxPow)
+	if err != nil {
+		return nil, err
+	}
+	// Checked before the Ethash cache of the header's epoch is generated
+	if !bytes.Equal(header.Extra, types.MergedMiningCommitment(challenge)) {
+		return nil, fmt.Errorf("Altcoinchain header does not commit to the mining proof")
+	}
+	epoch := ethash.Epoch(header.Number.Uint64())
+	if epoch < m.minEpoch || epoch > m.maxEpoch {
+		return nil, fmt.Errorf("Altcoinchain header %d is in Ethash epoch %d, outside epochs %d to %d around the tip", header.Number.Uint64(), epoch, m.minEpoch, m.maxEpoch)
+	}
+
+	cache := m.caches.Get(epoch)
+	result, err := cache.Verify(types.AuxPowSealHash(header), header.Nonce.Uint64(), header.MixDigest[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid Altcoinchain proof of work: %w", err)
+	}
+	return types.ScaleAuxPowResult(result, m.factor), nil
+}
+
+func (m mergedPoW) Target(difficulty uint64) *big.Int {
+	return m.native.Target(difficulty)
+}
+
+// mergedMiningCommitment returns the commitment an Altcoinchain header merge
+// mining the next block for miner, at timestamp, carries in its extra data,
+// with the height and parent the proof must name
+func (k Keeper) mergedMiningCommitment(ctx sdk.Context, miner string, timestamp int64) ([]byte, int64, []byte) {
+	proof := types.MiningProof{
+		MinerAddress:  miner,
+		Timestamp:     timestamp,
+		Height:        ctx.BlockHeight() + 1,
+		PrevBlockHash: k.lastBlockHash(ctx),
+	}
+	challenge := types.HeaderChallenge(proof, k.GetDifficulty(ctx))
+	return types.MergedMiningCommitment(challenge), proof.Height, proof.PrevBlockHash
+}
@@ -0,0 +1,88 @@
+package keeper
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"z-blockchain/x/utxo/ethash"
+	"z-blockchain/x/utxo/types"
+)
+
+func TestMergedPoWRefusesHeadersOutsideTheEpochWindow(t *testing.T) {
+	m := mergedPoW{
+		native:   types.EquihashPoW{},
+		factor:   types.DefaultMergedMiningFactor,
+		minEpoch: 9,
+		maxEpoch: 11,
+		caches:   ethash.NewCaches(ethashCacheEpochs),
+	}
+	challenge := []byte("challenge")
+
+	for _, number := range []uint64{0, 8*30000 + 29999, 12 * 30000, 1 << 62} {
+		header := &ethtypes.Header{
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: big.NewInt(1),
+			Extra:      types.MergedMiningCommitment(challenge),
+		}
+		auxPow, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Refused before the cache of the epoch, gigabytes for the last
+		// number, is generated
+		_, err = m.VerifySolution(challenge, types.MiningProof{AuxPow: auxPow})
+		if err == nil || !strings.Contains(err.Error(), "outside epochs 9 to 11") {
+			t.Errorf("header %d: got %v, want an epoch window error", number, err)
+		}
+	}
+}
+
+func TestPowIDNamesTheEpochWindow(t *testing.T) {
+	a := mergedPoW{native: types.EquihashPoW{}, factor: 1, minEpoch: 9, maxEpoch: 11}
+	b := mergedPoW{native: types.EquihashPoW{}, factor: 1, minEpoch: 10, maxEpoch: 12}
+
+	// A header refused as the tip lagged is checked again once it advances
+	if powID(a) == powID(b) {
+		t.Fatalf("windows share the solution cache id %s", powID(a))
+	}
+}
+
+func TestAdvanceAuxPowTipStaysWithinAnEpochOfTheAnchor(t *testing.T) {
+	k, ctx := setupKeeper(t)
+	params := types.DefaultParams()
+	params.MergedMiningAnchor = 10 * ethash.EpochLength
+	k.SetParams(ctx, params)
+
+	proof := func(height int64, number uint64) types.MiningProof {
+		auxPow, err := rlp.EncodeToBytes(&ethtypes.Header{Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.MiningProof{Height: height, AuxPow: auxPow}
+	}
+
+	// Uncles never move the tip
+	k.advanceAuxPowTip(ctx, proof(ctx.BlockHeight()-1, params.MergedMiningAnchor+100))
+	if tip := k.AuxPowTip(ctx); tip != params.MergedMiningAnchor {
+		t.Errorf("an uncle moved the tip to %d", tip)
+	}
+
+	k.advanceAuxPowTip(ctx, proof(ctx.BlockHeight(), params.MergedMiningAnchor+100))
+	if tip := k.AuxPowTip(ctx); tip != params.MergedMiningAnchor+100 {
+		t.Errorf("tip %d, want %d", tip, params.MergedMiningAnchor+100)
+	}
+
+	// However far a header claims to be, the tip stops an epoch past the
+	// anchor
+	for i := 0; i < 3; i++ {
+		k.advanceAuxPowTip(ctx, proof(ctx.BlockHeight(), k.AuxPowTip(ctx)+2*ethash.EpochLength))
+	}
+	if tip := k.AuxPowTip(ctx); tip != params.MergedMiningAnchor+ethash.EpochLength {
+		t.Errorf("tip %d, want %d", tip, params.MergedMiningAnchor+ethash.EpochLength)
+	}
+}
@@ -0,0 +1,127 @@
+package keeper
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// Metrics are exported through the SDK telemetry, which serves them to
+// Prometheus when telemetry is enabled in app.toml. They are observations of
+// the node alone and never feed back into state: proof verification latency
+// by kind, the proofs, reward minted and cross-chain messages of each block,
+// and the UTXO set size, nullifier count and difficulty after it.
+
+// Kinds of proofs whose verification is measured
+const (
+	ProofKindMining        = "mining"
+	ProofKindShielded      = "shielded"
+	ProofKindShieldedBatch = "shielded_batch"
+)
+
+// crossChainToNuChain labels the messages sent to nuChain
+const crossChainToNuChain = "nuchain"
+
+// blockMetrics accumulates the metrics of the block being delivered, shared
+// by the keeper's copies like the conflict queue
+type blockMetrics struct {
+	mu         sync.Mutex
+	minted     sdk.Int // Minted supply when the block began
+	proofs     map[string]int
+	crossChain int
+}
+
+func newBlockMetrics() *blockMetrics {
+	return &blockMetrics{minted: sdk.ZeroInt(), proofs: make(map[string]int)}
+}
+
+// GetUTXOCount returns the number of unspent outputs
+func (k Keeper) GetUTXOCount(ctx sdk.Context) uint64 {
+	return k.getCount(ctx, types.UTXOCountKey)
+}
+
+// GetNullifierCount returns the number of nullifiers revealed
+func (k Keeper) GetNullifierCount(ctx sdk.Context) uint64 {
+	return k.getCount(ctx, types.NullifierCountKey)
+}
+
+func (k Keeper) getCount(ctx sdk.Context, key []byte) uint64 {
+	bz := ctx.KVStore(k.storeKey).Get(key)
+	if bz == nil {
+		return 0
+	}
+	return sdk.BigEndianToUint64(bz)
+}
+
+func (k Keeper) addCount(ctx sdk.Context, key []byte, delta int64) {
+	count := int64(k.getCount(ctx, key)) + delta
+	if count < 0 {
+		count = 0
+	}
+	ctx.KVStore(k.storeKey).Set(key, sdk.Uint64ToBigEndian(uint64(count)))
+}
+
+// BeginBlockMetrics starts the metrics of the block of ctx
+func (k Keeper) BeginBlockMetrics(ctx sdk.Context) {
+	minted := k.GetMintedSupply(ctx)
+
+	k.metrics.mu.Lock()
+	defer k.metrics.mu.Unlock()
+	k.metrics.minted = minted
+	k.metrics.proofs = make(map[string]int)
+	k.metrics.crossChain = 0
+}
+
+// EmitBlockMetrics exports the metrics of the block of ctx as it ends
+func (k Keeper) EmitBlockMetrics(ctx sdk.Context) {
+	minted := k.GetMintedSupply(ctx)
+
+	k.metrics.mu.Lock()
+	defer k.metrics.mu.Unlock()
+
+	for _, kind := range []string{ProofKindMining, ProofKindShielded} {
+		telemetry.ModuleSetGauge(types.ModuleName, float32(k.metrics.proofs[kind]), "block_proofs", kind)
+	}
+	telemetry.ModuleSetGauge(types.ModuleName, intGauge(minted.Sub(k.metrics.minted)), "block_reward_minted")
+	telemetry.ModuleSetGauge(types.ModuleName, float32(k.metrics.crossChain), "block_cross_chain_messages", crossChainToNuChain)
+	telemetry.ModuleSetGauge(types.ModuleName, float32(k.GetUTXOCount(ctx)), "utxo_set_size")
+	telemetry.ModuleSetGauge(types.ModuleName, float32(k.GetNullifierCount(ctx)), "nullifiers")
+	telemetry.ModuleSetGauge(types.ModuleName, float32(k.GetDifficulty(ctx)), "difficulty")
+}
+
+// countProof counts a proof of kind verified in a delivered transaction
+func (k Keeper) countProof(ctx sdk.Context, kind string) {
+	if ctx.IsCheckTx() || ctx.IsReCheckTx() {
+		return
+	}
+	k.metrics.mu.Lock()
+	defer k.metrics.mu.Unlock()
+	k.metrics.proofs[kind]++
+}
+
+// countCrossChainMessage counts a message the block sends to another chain
+func (k Keeper) countCrossChainMessage(ctx sdk.Context) {
+	if ctx.IsCheckTx() || ctx.IsReCheckTx() {
+		return
+	}
+	k.metrics.mu.Lock()
+	defer k.metrics.mu.Unlock()
+	k.metrics.crossChain++
+}
+
+// MeasureProofVerification records the time verifying a proof of kind took
+// since start
+func MeasureProofVerification(kind string, start time.Time) {
+	telemetry.ModuleMeasureSince(types.ModuleName, start, "proof_verification", kind)
+}
+
+// intGauge approximates an amount as a gauge value
+func intGauge(amount sdk.Int) float32 {
+	f, _ := new(big.Float).SetInt(amount.BigInt()).Float32()
+	return f
+}
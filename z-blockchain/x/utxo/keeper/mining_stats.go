@@ -0,0 +1,103 @@
+package keeper
+
+import (
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// GetMinerStats returns the cumulative mining results of a miner
+func (k Keeper) GetMinerStats(ctx sdk.Context, miner string) (types.MinerStats, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MiningStatsKey)
+	bz := store.Get([]byte(miner))
+	if bz == nil {
+		return types.MinerStats{}, false
+	}
+
+	var stats types.MinerStats
+	k.cdc.MustUnmarshal(bz, &stats)
+	return stats, true
+}
+
+// setMinerStats stores the statistics of a miner and moves it in the rank
+// index
+func (k Keeper) setMinerStats(ctx sdk.Context, stats types.MinerStats) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.MiningStatsKey)
+	rank := prefix.NewStore(ctx.KVStore(k.storeKey), types.MinerRankKey)
+	if old, found := k.GetMinerStats(ctx, stats.Miner); found {
+		rank.Delete(types.MinerRankIndexKey(intParam(old.TotalRewards), old.Miner))
+	}
+
+	store.Set([]byte(stats.Miner), k.cdc.MustMarshal(&stats))
+	rank.Set(types.MinerRankIndexKey(intParam(stats.TotalRewards), stats.Miner), []byte{1})
+}
+
+// GetHardwareStats returns the cumulative mining results of a device class
+func (k Keeper) GetHardwareStats(ctx sdk.Context, deviceClass string) (types.HardwareStats, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareStatsKey)
+	bz := store.Get([]byte(deviceClass))
+	if bz == nil {
+		return types.HardwareStats{}, false
+	}
+
+	var stats types.HardwareStats
+	k.cdc.MustUnmarshal(bz, &stats)
+	return stats, true
+}
+
+func (k Keeper) setHardwareStats(ctx sdk.Context, stats types.HardwareStats) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.HardwareStatsKey)
+	store.Set([]byte(stats.DeviceClass), k.cdc.MustMarshal(&stats))
+}
+
+// hardwareStats returns the statistics of a device class, empty for a class
+// that never won a block
+func (k Keeper) hardwareStats(ctx sdk.Context, deviceClass string) types.HardwareStats {
+	stats, found := k.GetHardwareStats(ctx, deviceClass)
+	if !found {
+		return types.HardwareStats{DeviceClass: deviceClass, TotalRewards: "0"}
+	}
+	return stats
+}
+
+// UpdateMiningStats adds a won block and its reward to the statistics of the
+// miner and of its device class. A miner counts towards the class of its
+// latest winning proof.
+func (k Keeper) UpdateMiningStats(ctx sdk.Context, miner sdk.AccAddress, hardwareId string, reward sdk.Int) {
+	stats, found := k.GetMinerStats(ctx, miner.String())
+	if !found {
+		stats = types.MinerStats{Miner: miner.String(), TotalRewards: "0"}
+	}
+
+	switched := !found || stats.HardwareId != hardwareId
+	if switched && stats.HardwareId != "" {
+		previous := k.hardwareStats(ctx, stats.HardwareId)
+		if previous.Miners > 0 {
+			previous.Miners--
+		}
+		k.setHardwareStats(ctx, previous)
+	}
+	if hardwareId != "" {
+		class := k.hardwareStats(ctx, hardwareId)
+		if switched {
+			class.Miners++
+		}
+		class.BlocksWon++
+		class.TotalRewards = intParam(class.TotalRewards).Add(reward).String()
+		k.setHardwareStats(ctx, class)
+	}
+
+	stats.TotalRewards = intParam(stats.TotalRewards).Add(reward).String()
+	stats.BlocksWon++
+	stats.HardwareId = hardwareId
+	stats.LastProofHeight = ctx.BlockHeight()
+	k.setMinerStats(ctx, stats)
+
+	k.logger.Info("Mining reward distributed",
+		"miner", miner.String(),
+		"hardware", hardwareId,
+		"reward", reward.String(),
+		"block_height", ctx.BlockHeight())
+}
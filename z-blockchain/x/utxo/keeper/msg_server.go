@@ -2,13 +2,12 @@ package keeper
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"strconv"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	
+	"z-blockchain/x/utxo/notetree"
 	"z-blockchain/x/utxo/types"
 )
 
@@ -41,24 +40,14 @@ func (k msgServer) SendUTXO(goCtx context.Context, msg *types.MsgSendUTXO) (*typ
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "transaction must have outputs")
 	}
 
-	// Generate transaction hash
-	txHash := k.generateTxHash(msg)
-
-	// Create UTXO transaction
-	utxoTx := types.UTXOTransaction{
-		TxHash:    txHash,
-		Inputs:    msg.Inputs,
-		Outputs:   msg.Outputs,
-		LockTime:  msg.LockTime,
-		Timestamp: ctx.BlockTime().Unix(),
-		Fee:       msg.Fee,
-		ZkProof:   msg.ZkProof,
-	}
+	utxoTx := k.NewUTXOTransaction(ctx, msg)
+	txHash := utxoTx.TxHash
 
 	// Process the transaction
 	if err := k.Keeper.ProcessUTXOTransaction(ctx, utxoTx); err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
+	k.SetTxLocation(ctx, txHash)
 
 	// Emit event
 	ctx.EventManager().EmitEvent(
@@ -99,13 +88,16 @@ func (k msgServer) SendShielded(goCtx context.Context, msg *types.MsgSendShielde
 
 	// Create shielded transaction
 	shieldedTx := types.ShieldedTransaction{
-		TxHash:        txHash,
-		Nullifiers:    msg.Nullifiers,
-		Commitments:   msg.Commitments,
-		ZkProof:       msg.ZkProof,
-		EncryptedMemo: msg.EncryptedMemo,
-		Fee:           msg.Fee,
-		Timestamp:     ctx.BlockTime().Unix(),
+		TxHash:             txHash,
+		Nullifiers:         msg.Nullifiers,
+		Commitments:        msg.Commitments,
+		ZkProof:            msg.ZkProof,
+		EncryptedMemo:      msg.EncryptedMemo,
+		Fee:                msg.Fee,
+		Timestamp:          ctx.BlockTime().Unix(),
+		Anchor:             msg.Anchor,
+		ValueBalance:       msg.ValueBalance,
+		TransparentOutputs: msg.TransparentOutputs,
 	}
 
 	// Process the shielded transaction
@@ -130,6 +122,95 @@ func (k msgServer) SendShielded(goCtx context.Context, msg *types.MsgSendShielde
 	}, nil
 }
 
+// Shield spends transparent UTXOs into shielded notes. The transparent side
+// pays the value to the pool address, which deposits it for the shielded side
+// to shield; both fail together.
+func (k msgServer) Shield(goCtx context.Context, msg *types.MsgShield) (*types.MsgShieldResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	utxoTx := k.NewShieldTransaction(ctx, msg)
+	txHash := utxoTx.TxHash
+	
+	if err := k.Keeper.ProcessUTXOTransaction(ctx, utxoTx); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	k.SetTxLocation(ctx, txHash)
+	
+	value, _ := sdk.NewIntFromString(msg.Value)
+	shieldedTx := types.ShieldedTransaction{
+		TxHash:        txHash,
+		Commitments:   msg.Commitments,
+		ZkProof:       msg.ZkProof,
+		EncryptedMemo: msg.EncryptedMemo,
+		Fee:           "0",
+		Timestamp:     ctx.BlockTime().Unix(),
+		Anchor:        notetree.EmptyRoot(),
+		ValueBalance:  value.Neg().String(),
+	}
+	if err := k.Keeper.ProcessShieldedTransaction(ctx, shieldedTx); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeShield,
+			sdk.NewAttribute(types.AttributeKeyCreator, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyTxHash, txHash),
+			sdk.NewAttribute(types.AttributeKeyInputCount, strconv.Itoa(len(msg.Inputs))),
+			sdk.NewAttribute(types.AttributeKeyCommitmentCount, strconv.Itoa(len(msg.Commitments))),
+			sdk.NewAttribute(types.AttributeKeyAmount, msg.Value),
+			sdk.NewAttribute(types.AttributeKeyFee, msg.Fee),
+		),
+	)
+
+	return &types.MsgShieldResponse{
+		TxHash: txHash,
+	}, nil
+}
+
+// Deshield spends shielded notes into transparent UTXOs
+func (k msgServer) Deshield(goCtx context.Context, msg *types.MsgDeshield) (*types.MsgDeshieldResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	valueBalance, err := msg.ValueBalance()
+	if err != nil {
+		return nil, err
+	}
+	
+	txHash := msg.Hash()
+	shieldedTx := types.ShieldedTransaction{
+		TxHash:             txHash,
+		Nullifiers:         msg.Nullifiers,
+		Commitments:        msg.Commitments,
+		ZkProof:            msg.ZkProof,
+		EncryptedMemo:      msg.EncryptedMemo,
+		Fee:                msg.Fee,
+		Timestamp:          ctx.BlockTime().Unix(),
+		Anchor:             msg.Anchor,
+		ValueBalance:       valueBalance.String(),
+		TransparentOutputs: msg.Outputs,
+	}
+	if err := k.Keeper.ProcessShieldedTransaction(ctx, shieldedTx); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeDeshield,
+			sdk.NewAttribute(types.AttributeKeyCreator, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyTxHash, txHash),
+			sdk.NewAttribute(types.AttributeKeyNullifierCount, strconv.Itoa(len(msg.Nullifiers))),
+			sdk.NewAttribute(types.AttributeKeyOutputCount, strconv.Itoa(len(msg.Outputs))),
+			sdk.NewAttribute(types.AttributeKeyAmount, valueBalance.String()),
+			sdk.NewAttribute(types.AttributeKeyFee, msg.Fee),
+		),
+	)
+
+	return &types.MsgDeshieldResponse{
+		TxHash: txHash,
+	}, nil
+}
+
 // SubmitMiningProof processes a hardware-accelerated mining proof
 func (k msgServer) SubmitMiningProof(goCtx context.Context, msg *types.MsgSubmitMiningProof) (*types.MsgSubmitMiningProofResponse, error) {
 	ctx := sdk.UnwrapSDKContext(goCtx)
@@ -146,20 +227,9 @@ func (k msgServer) SubmitMiningProof(goCtx context.Context, msg *types.MsgSubmit
 	if msg.HardwareId == "" {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "hardware ID cannot be empty")
 	}
-
-	// Create mining proof
-	miningProof := types.MiningProof{
-		MinerAddress: msg.Creator,
-		ZkProof:      msg.ZkProof,
-		PublicInputs: msg.PublicInputs,
-		Nonce:        msg.Nonce,
-		Difficulty:   msg.Difficulty,
-		Timestamp:    ctx.BlockTime().Unix(),
-		HardwareId:   msg.HardwareId,
-	}
-
+	
 	// Process the mining proof
-	if err := k.Keeper.MineBlock(ctx, miningProof); err != nil {
+	if err := k.Keeper.MineBlock(ctx, msg.MiningProof()); err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
 
@@ -179,30 +249,107 @@ func (k msgServer) SubmitMiningProof(goCtx context.Context, msg *types.MsgSubmit
 	}, nil
 }
 
-// Helper functions
-func (k msgServer) generateTxHash(msg *types.MsgSendUTXO) string {
-	data := msg.Creator
-	for _, input := range msg.Inputs {
-		data += input.PrevTxHash + strconv.FormatUint(uint64(input.PrevOutputIndex), 10)
+// SubmitAuxPow processes a mining proof merge mined on Altcoinchain; it
+// competes for the block like a native one
+func (k msgServer) SubmitAuxPow(goCtx context.Context, msg *types.MsgSubmitAuxPow) (*types.MsgSubmitAuxPowResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	proof := msg.MiningProof()
+	if err := k.Keeper.MineBlock(ctx, proof); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
-	for _, output := range msg.Outputs {
-		data += output.Address + output.Amount
+	k.Keeper.advanceAuxPowTip(ctx, proof)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSubmitMiningProof,
+			sdk.NewAttribute(types.AttributeKeyCreator, msg.Creator),
+			sdk.NewAttribute(types.AttributeKeyHardwareId, types.MergedMiningDeviceClass),
+		),
+	)
+
+	return &types.MsgSubmitAuxPowResponse{}, nil
+}
+
+// RespondMemoryChallenge answers the miner's open memory audit challenge
+func (k msgServer) RespondMemoryChallenge(goCtx context.Context, msg *types.MsgRespondMemoryChallenge) (*types.MsgRespondMemoryChallengeResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	// A failed audit is still recorded; only a missing challenge is an error
+	profile, passed, elapsed, err := k.Keeper.RespondMemoryChallenge(ctx, msg.Creator, msg.Root, msg.Openings)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
-	data += msg.Fee + strconv.FormatUint(msg.LockTime, 10)
-	
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
+
+	return &types.MsgRespondMemoryChallengeResponse{
+		Passed:         passed,
+		ResponseTimeMs: elapsed,
+		MemoryFactor:   profile.MemoryFactor,
+	}, nil
 }
 
-func (k msgServer) generateShieldedTxHash(msg *types.MsgSendShielded) string {
-	data := msg.Creator + msg.Fee
-	for _, nullifier := range msg.Nullifiers {
-		data += hex.EncodeToString(nullifier)
+// RegisterHardware adds or updates a device class of the hardware registry
+func (k msgServer) RegisterHardware(goCtx context.Context, msg *types.MsgRegisterHardware) (*types.MsgRegisterHardwareResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if err := k.Keeper.RegisterHardware(ctx, msg.HardwareClass()); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	}
-	for _, commitment := range msg.Commitments {
-		data += hex.EncodeToString(commitment)
+
+	return &types.MsgRegisterHardwareResponse{}, nil
+}
+
+// ReviewHardwareFlag upholds or clears the flag of a miner for governance
+func (k msgServer) ReviewHardwareFlag(goCtx context.Context, msg *types.MsgReviewHardwareFlag) (*types.MsgReviewHardwareFlagResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if msg.Authority != k.GetAuthority() {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "expected %s to review hardware flags, got %s", k.GetAuthority(), msg.Authority)
 	}
-	
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
+	if err := k.Keeper.ReviewHardwareFlag(ctx, msg.Miner, msg.Upheld); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	return &types.MsgReviewHardwareFlagResponse{}, nil
+}
+
+// SpendTreasury pays funds of the community treasury for governance
+func (k msgServer) SpendTreasury(goCtx context.Context, msg *types.MsgSpendTreasury) (*types.MsgSpendTreasuryResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if msg.Authority != k.GetAuthority() {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "expected %s to spend the treasury, got %s", k.GetAuthority(), msg.Authority)
+	}
+	recipient, err := sdk.AccAddressFromBech32(msg.Recipient)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid recipient address (%s)", err)
+	}
+	amount, _ := sdk.NewIntFromString(msg.Amount)
+	txHash, err := k.Keeper.SpendTreasury(ctx, recipient, amount)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	return &types.MsgSpendTreasuryResponse{TxHash: txHash}, nil
+}
+
+// SetPayoutSplit divides the mining payouts of the signer between payout
+// addresses
+func (k msgServer) SetPayoutSplit(goCtx context.Context, msg *types.MsgSetPayoutSplit) (*types.MsgSetPayoutSplitResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if err := k.Keeper.SetPayoutSplit(ctx, msg.Miner, msg.Shares); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	return &types.MsgSetPayoutSplitResponse{}, nil
+}
+
+// Helper functions
+func (k Keeper) generateTxHash(msg *types.MsgSendUTXO) string {
+	return msg.Hash()
+}
+
+func (k msgServer) generateShieldedTxHash(msg *types.MsgSendShielded) string {
+	return msg.Hash()
 }
\ No newline at end of file
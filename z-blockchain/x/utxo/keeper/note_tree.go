@@ -0,0 +1,89 @@
+package keeper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/notetree"
+	"z-blockchain/x/utxo/types"
+)
+
+func (k Keeper) noteTree(ctx sdk.Context) *notetree.Tree {
+	return notetree.New(prefix.NewStore(ctx.KVStore(k.storeKey), types.NoteTreeKey))
+}
+
+// AddCommitment appends a note commitment to the note commitment tree and
+// returns its position. A commitment already in the tree is refused; notes
+// with the same commitment could not be told apart.
+func (k Keeper) AddCommitment(ctx sdk.Context, commitment []byte) (uint64, error) {
+	if _, found := k.GetCommitmentPosition(ctx, commitment); found {
+		return 0, fmt.Errorf("note commitment already exists: %x", commitment)
+	}
+
+	position, err := k.noteTree(ctx).Append(commitment)
+	if err != nil {
+		return 0, err
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.CommitmentKey)
+	store.Set(commitment, sdk.Uint64ToBigEndian(position))
+	return position, nil
+}
+
+// GetCommitmentPosition returns the position of a note commitment in the tree
+func (k Keeper) GetCommitmentPosition(ctx sdk.Context, commitment []byte) (uint64, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.CommitmentKey)
+	bz := store.Get(commitment)
+	if bz == nil {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(bz), true
+}
+
+// GetNoteTreeRoot returns the current root of the note commitment tree
+func (k Keeper) GetNoteTreeRoot(ctx sdk.Context) []byte {
+	return k.noteTree(ctx).Root()
+}
+
+// RecordAnchor makes the root the note commitment tree ends the block with a
+// valid anchor for later spends
+func (k Keeper) RecordAnchor(ctx sdk.Context) {
+	root := k.GetNoteTreeRoot(ctx)
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.AnchorKey)
+	if !store.Has(root) {
+		store.Set(root, sdk.Uint64ToBigEndian(uint64(ctx.BlockHeight())))
+	}
+}
+
+// IsValidAnchor reports whether anchor is a root the note commitment tree
+// ended a block with. Roots within a block are not anchors, so transactions in
+// a block cannot depend on each other's notes.
+func (k Keeper) IsValidAnchor(ctx sdk.Context, anchor []byte) bool {
+	if bytes.Equal(anchor, notetree.EmptyRoot()) {
+		return true
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.AnchorKey)
+	return len(anchor) > 0 && store.Has(anchor)
+}
+
+// GetNoteWitness returns the position of a note commitment and the Merkle
+// path from it to the current root, the anchor to prove a spend of the note
+// under
+func (k Keeper) GetNoteWitness(ctx sdk.Context, commitment []byte) (position uint64, anchor []byte, siblings [][]byte, err error) {
+	position, found := k.GetCommitmentPosition(ctx, commitment)
+	if !found {
+		return 0, nil, nil, fmt.Errorf("note commitment not found: %x", commitment)
+	}
+
+	tree := k.noteTree(ctx)
+	siblings, err = tree.Witness(position)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return position, tree.Root(), siblings, nil
+}
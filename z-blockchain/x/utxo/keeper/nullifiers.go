@@ -0,0 +1,202 @@
+package keeper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/poseidon"
+	"z-blockchain/x/utxo/types"
+)
+
+// The nullifier set is sharded by epoch: a nullifier is stored under the
+// epoch of the height it was revealed at, and each epoch has a Bloom filter
+// in memory, rebuilt from the store the first time the set is used after a
+// start. A lookup reads only the epochs whose filter may hold the nullifier,
+// so a fresh nullifier, the common case, costs no store read at all.
+//
+// The filters never answer for the store, they only skip reads, and a filter
+// of one node may hold more than another's (a nullifier of a reverted
+// transaction, say). So that gas stays deterministic, a lookup is charged a
+// flat NullifierLookupGas and its reads are made without gas metering.
+//
+// Nullifiers are field elements to the circuits, so the set holds them by
+// their canonical 32 byte encoding: every encoding of one element, padded or
+// above the modulus, is the same nullifier. Messages only carry canonical
+// nullifiers; the set does not rely on it.
+
+const (
+	// NullifierEpochLength is the number of blocks whose nullifiers share a
+	// shard of the nullifier set
+	NullifierEpochLength = 100000
+
+	// NullifierLookupGas is the gas charged for checking one nullifier
+	NullifierLookupGas = 1000
+
+	// Filter sizing: the bits per epoch hold 2^20 nullifiers at about a 1%
+	// false positive rate
+	nullifierFilterBits   = 1 << 23
+	nullifierFilterHashes = 7
+)
+
+// IsNullifierUsed reports whether a nullifier has been revealed
+func (k Keeper) IsNullifierUsed(ctx sdk.Context, nullifier []byte) bool {
+	ctx.GasMeter().ConsumeGas(NullifierLookupGas, "nullifier lookup")
+
+	unmetered := ctx.WithGasMeter(storetypes.NewInfiniteGasMeter())
+	k.nullifiers.load(unmetered, k.storeKey)
+	nullifier = poseidon.Reduce(nullifier)
+
+	store := prefix.NewStore(unmetered.KVStore(k.storeKey), types.NullifierKey)
+	for _, epoch := range k.nullifiers.candidates(nullifier) {
+		if store.Has(nullifierKey(epoch, nullifier)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNullifier records a nullifier as revealed at the current height
+func (k Keeper) SetNullifier(ctx sdk.Context, nullifier []byte) {
+	nullifier = poseidon.Reduce(nullifier)
+	epoch := NullifierEpoch(ctx.BlockHeight())
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.NullifierKey)
+	if !store.Has(nullifierKey(epoch, nullifier)) {
+		k.addCount(ctx, types.NullifierCountKey, 1)
+	}
+	store.Set(nullifierKey(epoch, nullifier), []byte{1})
+	k.nullifiers.add(epoch, nullifier)
+}
+
+// NullifierEpoch is the shard of the nullifiers revealed at height
+func NullifierEpoch(height int64) uint64 {
+	if height < 0 {
+		return 0
+	}
+	return uint64(height) / NullifierEpochLength
+}
+
+// nullifierKey is the key of a nullifier under NullifierKey: the big endian
+// epoch, then the nullifier
+func nullifierKey(epoch uint64, nullifier []byte) []byte {
+	key := make([]byte, 8, 8+len(nullifier))
+	binary.BigEndian.PutUint64(key, epoch)
+	return append(key, nullifier...)
+}
+
+// nullifierFilter holds a Bloom filter per epoch of the nullifier set
+type nullifierFilter struct {
+	mu     sync.RWMutex
+	loaded bool
+	salt   [32]byte // Keeps nullifiers from being picked to collide in the filters
+	epochs map[uint64][]uint64
+}
+
+func newNullifierFilter() *nullifierFilter {
+	f := &nullifierFilter{epochs: make(map[uint64][]uint64)}
+	if _, err := rand.Read(f.salt[:]); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// load fills the filters from the store the first time it is called
+func (f *nullifierFilter) load(ctx sdk.Context, storeKey storetypes.StoreKey) {
+	f.mu.RLock()
+	loaded := f.loaded
+	f.mu.RUnlock()
+	if loaded {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.loaded {
+		return
+	}
+
+	store := prefix.NewStore(ctx.KVStore(storeKey), types.NullifierKey)
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		if len(key) < 8 {
+			continue
+		}
+		f.addLocked(binary.BigEndian.Uint64(key[:8]), key[8:])
+	}
+	f.loaded = true
+}
+
+// add records a nullifier in the filter of its epoch
+func (f *nullifierFilter) add(epoch uint64, nullifier []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addLocked(epoch, nullifier)
+}
+
+// markLoaded records the filters as holding the whole nullifier set, as
+// they do once a snapshot restored it through them
+func (f *nullifierFilter) markLoaded() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loaded = true
+}
+
+func (f *nullifierFilter) addLocked(epoch uint64, nullifier []byte) {
+	bits, ok := f.epochs[epoch]
+	if !ok {
+		bits = make([]uint64, nullifierFilterBits/64)
+		f.epochs[epoch] = bits
+	}
+	for _, i := range f.positions(nullifier) {
+		bits[i/64] |= 1 << (i % 64)
+	}
+}
+
+// candidates lists the epochs whose filter may hold nullifier, newest first
+func (f *nullifierFilter) candidates(nullifier []byte) []uint64 {
+	positions := f.positions(nullifier)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var epochs []uint64
+	for epoch, bits := range f.epochs {
+		hit := true
+		for _, i := range positions {
+			if bits[i/64]&(1<<(i%64)) == 0 {
+				hit = false
+				break
+			}
+		}
+		if hit {
+			epochs = append(epochs, epoch)
+		}
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] > epochs[j] })
+	return epochs
+}
+
+// positions are the filter bits of a nullifier, by double hashing
+func (f *nullifierFilter) positions(nullifier []byte) [nullifierFilterHashes]uint64 {
+	h := sha256.New()
+	h.Write(f.salt[:])
+	h.Write(nullifier)
+	sum := h.Sum(nil)
+	a := binary.BigEndian.Uint64(sum[:8])
+	b := binary.BigEndian.Uint64(sum[8:16]) | 1
+
+	var positions [nullifierFilterHashes]uint64
+	for i := range positions {
+		positions[i] = (a + uint64(i)*b) % nullifierFilterBits
+	}
+	return positions
+}
@@ -0,0 +1,48 @@
+package keeper
+
+import (
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// GetPayoutSplit returns the payout split of a miner, if it set one
+func (k Keeper) GetPayoutSplit(ctx sdk.Context, miner string) (types.PayoutSplit, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.PayoutSplitKey)
+	bz := store.Get([]byte(miner))
+	if bz == nil {
+		return types.PayoutSplit{}, false
+	}
+
+	var split types.PayoutSplit
+	k.cdc.MustUnmarshal(bz, &split)
+	return split, true
+}
+
+func (k Keeper) setPayoutSplit(ctx sdk.Context, split types.PayoutSplit) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.PayoutSplitKey)
+	store.Set([]byte(split.Miner), k.cdc.MustMarshal(&split))
+}
+
+// SetPayoutSplit divides the later payouts of miner between the payout
+// addresses of shares. No shares clears the split, paying the miner alone.
+func (k Keeper) SetPayoutSplit(ctx sdk.Context, miner string, shares []types.PayoutShare) error {
+	if len(shares) == 0 {
+		store := prefix.NewStore(ctx.KVStore(k.storeKey), types.PayoutSplitKey)
+		store.Delete([]byte(miner))
+	} else {
+		split := types.PayoutSplit{Miner: miner, Shares: shares}
+		if err := split.Validate(); err != nil {
+			return err
+		}
+		k.setPayoutSplit(ctx, split)
+	}
+
+	k.emitTypedEvent(ctx, &types.EventPayoutSplitSet{
+		Miner:  miner,
+		Shares: shares,
+	})
+	return nil
+}
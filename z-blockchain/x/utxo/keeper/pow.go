@@ -0,0 +1,42 @@
+package keeper
+
+import (
+	"fmt"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+
+	// Hypothetical zk-SNARK library
+	cysic "github.com/cysic-labs/zk-sdk-go"
+)
+
+// ProofOfWork returns the mining algorithm the PowAlgorithm param selects
+func (k Keeper) ProofOfWork(ctx sdk.Context) types.ProofOfWork {
+	params := k.GetParams(ctx)
+	if params.PowAlgorithm == types.PowAlgorithmCysicZK {
+		return cysicPoW{}
+	}
+	return types.EquihashPoW{Params: params.Equihash()}
+}
+
+// cysicPoW mines with Cysic zk-SNARK proofs, which hardware accelerators
+// generate: the zk proof of a mining proof proves the work over the header
+// challenge, its public inputs, and its hash competes against the target
+type cysicPoW struct{}
+
+func (cysicPoW) PrepareChallenge(proof types.MiningProof, difficulty uint64) []byte {
+	return types.HeaderChallenge(proof, difficulty)
+}
+
+func (cysicPoW) VerifySolution(challenge []byte, proof types.MiningProof) ([]byte, error) {
+	if !cysic.VerifyMiningProof(proof.ZkProof, challenge, proof.Difficulty, proof.HardwareId) {
+		return nil, fmt.Errorf("invalid Cysic zk proof")
+	}
+	return types.CysicSolutionHash(challenge, proof.ZkProof), nil
+}
+
+func (cysicPoW) Target(difficulty uint64) *big.Int {
+	return types.EquihashTarget(difficulty)
+}
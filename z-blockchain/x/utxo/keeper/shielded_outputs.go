@@ -0,0 +1,84 @@
+package keeper
+
+import (
+	"encoding/binary"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// MaxShieldedOutputsPage is the most shielded outputs a query returns at once
+const MaxShieldedOutputsPage = 1000
+
+// appendShieldedOutput records an output as the next shielded output of the
+// block, setting its height and index
+func (k Keeper) appendShieldedOutput(ctx sdk.Context, output types.ShieldedOutput) {
+	output.Height = ctx.BlockHeight()
+	output.Index = k.nextShieldedOutputIndex(ctx)
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ShieldedOutputKey)
+	store.Set(shieldedOutputKey(output.Height, output.Index), k.cdc.MustMarshal(&output))
+}
+
+// nextShieldedOutputIndex is one past the index of the block's last shielded
+// output
+func (k Keeper) nextShieldedOutputIndex(ctx sdk.Context) uint32 {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ShieldedOutputKey)
+	height := ctx.BlockHeight()
+	iterator := store.ReverseIterator(shieldedOutputHeightKey(height), shieldedOutputHeightKey(height+1))
+	defer iterator.Close()
+	if !iterator.Valid() {
+		return 0
+	}
+	return binary.BigEndian.Uint32(iterator.Key()[8:]) + 1
+}
+
+// GetShieldedOutput returns the output at an index of the shielded outputs
+// created at height
+func (k Keeper) GetShieldedOutput(ctx sdk.Context, height int64, index uint32) (types.ShieldedOutput, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ShieldedOutputKey)
+	bz := store.Get(shieldedOutputKey(height, index))
+	if bz == nil {
+		return types.ShieldedOutput{}, false
+	}
+	var output types.ShieldedOutput
+	k.cdc.MustUnmarshal(bz, &output)
+	return output, true
+}
+
+// GetShieldedOutputs returns up to limit shielded outputs from the key start,
+// stopping before the key end, and the key of the next output if there are
+// more. A nil end runs to the last output.
+func (k Keeper) GetShieldedOutputs(ctx sdk.Context, start, end []byte, limit int) ([]types.ShieldedOutput, []byte) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ShieldedOutputKey)
+	iterator := store.Iterator(start, end)
+	defer iterator.Close()
+
+	var outputs []types.ShieldedOutput
+	for ; iterator.Valid(); iterator.Next() {
+		if len(outputs) == limit {
+			return outputs, append([]byte{}, iterator.Key()...)
+		}
+		var output types.ShieldedOutput
+		k.cdc.MustUnmarshal(iterator.Value(), &output)
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// shieldedOutputKey is the key of an output under ShieldedOutputKey: the big
+// endian height, then the big endian index
+func shieldedOutputKey(height int64, index uint32) []byte {
+	key := make([]byte, 12)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	binary.BigEndian.PutUint32(key[8:], index)
+	return key
+}
+
+// shieldedOutputHeightKey is the first key of the outputs created at height
+func shieldedOutputHeightKey(height int64) []byte {
+	return sdk.Uint64ToBigEndian(uint64(height))
+}
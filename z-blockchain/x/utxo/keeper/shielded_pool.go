@@ -0,0 +1,140 @@
+package keeper
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/script"
+	"z-blockchain/x/utxo/types"
+)
+
+// The shielded pool is a turnstile: value enters it through outputs paying
+// types.ShieldedPoolAddress and leaves it through the positive value balances
+// of shielded transactions. The pool total is public even though the notes
+// are not, and it can never go negative, so a flaw in the proofs that
+// counterfeits notes cannot take out more than was put in.
+
+// GetShieldedPoolValue returns the total value held in the shielded pool
+func (k Keeper) GetShieldedPoolValue(ctx sdk.Context) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(types.ShieldedPoolKey)
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	return intParam(string(bz))
+}
+
+func (k Keeper) setShieldedPoolValue(ctx sdk.Context, value sdk.Int) {
+	ctx.KVStore(k.storeKey).Set(types.ShieldedPoolKey, []byte(value.String()))
+}
+
+// depositToPool adds an output paying the pool address to the pool. The
+// value is credited to the enclosing CometBFT transaction, for a shielded
+// message of the same transaction to shield.
+func (k Keeper) depositToPool(ctx sdk.Context, amount sdk.Int) {
+	k.setShieldedPoolValue(ctx, k.GetShieldedPoolValue(ctx).Add(amount))
+
+	store := ctx.KVStore(k.storeKey)
+	key := depositKey(ctx)
+	store.Set(key, []byte(k.getDeposit(ctx, key).Add(amount).String()))
+}
+
+func (k Keeper) getDeposit(ctx sdk.Context, key []byte) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(key)
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	return intParam(string(bz))
+}
+
+// applyValueBalance moves the value balance of a shielded transaction across
+// the turnstile. A negative balance shields value deposited by the same
+// transaction; a positive one leaves the pool as the fee and new transparent
+// outputs. ValidateBasic has checked that the two sides add up.
+func (k Keeper) applyValueBalance(ctx sdk.Context, tx types.ShieldedTransaction) error {
+	valueBalance, ok := sdk.NewIntFromString(tx.ValueBalance)
+	if !ok {
+		return fmt.Errorf("invalid value balance: %s", tx.ValueBalance)
+	}
+
+	if valueBalance.IsNegative() {
+		shielded := valueBalance.Neg()
+		key := depositKey(ctx)
+		deposit := k.getDeposit(ctx, key)
+		if deposit.LT(shielded) {
+			return fmt.Errorf("transaction paid %s into the shielded pool but shields %s", deposit, shielded)
+		}
+		ctx.KVStore(k.storeKey).Set(key, []byte(deposit.Sub(shielded).String()))
+		k.emitTypedEvent(ctx, &types.EventShielded{
+			TxHash:          tx.TxHash,
+			Amount:          shielded.String(),
+			CommitmentCount: uint32(len(tx.Commitments)),
+			BlockHeight:     ctx.BlockHeight(),
+		})
+		return nil
+	}
+
+	pool := k.GetShieldedPoolValue(ctx)
+	if pool.LT(valueBalance) {
+		return fmt.Errorf("shielded pool holds %s, %s cannot leave it", pool, valueBalance)
+	}
+	k.setShieldedPoolValue(ctx, pool.Sub(valueBalance))
+
+	if err := k.checkDust(ctx, tx.TransparentOutputs); err != nil {
+		return err
+	}
+	for i, output := range tx.TransparentOutputs {
+		if script.Unspendable(output.ScriptPubkey) {
+			continue
+		}
+		utxo := types.UTXO{
+			TxHash:       tx.TxHash,
+			OutputIndex:  uint32(i),
+			Address:      output.Address,
+			Amount:       output.Amount,
+			BlockHeight:  ctx.BlockHeight(),
+			ScriptPubkey: output.ScriptPubkey,
+			CreatedAt:    ctx.BlockTime().Unix(),
+		}
+		k.SetUTXO(ctx, utxo)
+		k.emitUTXOCreated(ctx, utxo)
+	}
+	if valueBalance.IsPositive() {
+		k.emitTypedEvent(ctx, &types.EventUnshielded{
+			TxHash:      tx.TxHash,
+			Amount:      valueBalance.String(),
+			BlockHeight: ctx.BlockHeight(),
+		})
+	}
+	return nil
+}
+
+// ClearShieldedDeposits drops the deposit credits of the block's
+// transactions. Value deposited but not shielded stays in the pool for good,
+// like an unspendable output.
+func (k Keeper) ClearShieldedDeposits(ctx sdk.Context) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.ShieldedDepositKey)
+	iterator := store.Iterator(nil, nil)
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		if amount := intParam(string(iterator.Value())); amount.IsPositive() {
+			k.Logger(ctx).Info("Unshielded deposit left in the shielded pool", "tx", fmt.Sprintf("%X", iterator.Key()), "amount", amount.String())
+		}
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// depositKey is the deposit credit key of the CometBFT transaction being
+// delivered
+func depositKey(ctx sdk.Context) []byte {
+	hash := sha256.Sum256(ctx.TxBytes())
+	return append(append([]byte{}, types.ShieldedDepositKey...), hash[:]...)
+}
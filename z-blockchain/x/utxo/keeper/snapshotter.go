@@ -0,0 +1,229 @@
+package keeper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/store/prefix"
+	snapshot "cosmossdk.io/store/snapshots/types"
+	storetypes "cosmossdk.io/store/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// State sync restores the module store with the rest of the multistore, from
+// the IAVL chunks of a snapshot, so a new node starts from a recent height
+// instead of replaying every block. The utxo snapshot extension streams after
+// them the large sections of the store, the UTXO set, the nullifier set and
+// the note commitment tree, as chunks of SnapshotChunkRecords records, each
+// a key range with a count and digest. Restoring reads each chunk's range of
+// the restored store back and checks it, so a node never starts on a section
+// that arrived partly, and fills the nullifier filters from the nullifier
+// chunks as it goes, sparing the first shielded spend a scan of the whole
+// set. The app registers the Snapshotter with the snapshot manager's
+// RegisterExtensions.
+
+const (
+	// SnapshotFormat is the format of the utxo snapshot extension payloads
+	SnapshotFormat = 1
+
+	// SnapshotChunkRecords is the number of records a payload covers
+	SnapshotChunkRecords = 10000
+)
+
+// snapshotSections are the sections of the store the extension streams
+var snapshotSections = []struct {
+	name   string
+	prefix []byte
+}{
+	{"utxo", types.UTXOKey},
+	{"nullifier", types.NullifierKey},
+	{"note_tree", types.NoteTreeKey},
+}
+
+// Snapshotter is the utxo snapshot extension over the multistore of the app
+type Snapshotter struct {
+	cms    storetypes.MultiStore
+	keeper Keeper
+}
+
+// NewSnapshotter returns the snapshot extension of k over cms
+func NewSnapshotter(cms storetypes.MultiStore, k Keeper) *Snapshotter {
+	return &Snapshotter{cms: cms, keeper: k}
+}
+
+var _ snapshot.ExtensionSnapshotter = (*Snapshotter)(nil)
+
+func (s *Snapshotter) SnapshotName() string {
+	return types.ModuleName
+}
+
+func (s *Snapshotter) SnapshotFormat() uint32 {
+	return SnapshotFormat
+}
+
+func (s *Snapshotter) SupportedFormats() []uint32 {
+	return []uint32{SnapshotFormat}
+}
+
+// SnapshotExtension writes the chunks of the sections at height
+func (s *Snapshotter) SnapshotExtension(height uint64, write snapshot.ExtensionPayloadWriter) error {
+	cms, err := s.cms.CacheMultiStoreWithVersion(int64(height))
+	if err != nil {
+		return err
+	}
+	ctx := sdk.NewContext(cms, cmtproto.Header{Height: int64(height)}, false, log.NewNopLogger())
+
+	for _, section := range snapshotSections {
+		store := prefix.NewStore(ctx.KVStore(s.keeper.storeKey), section.prefix)
+		if err := s.snapshotSection(store, section.name, write); err != nil {
+			return fmt.Errorf("snapshot %s: %w", section.name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Snapshotter) snapshotSection(store prefix.Store, name string, write snapshot.ExtensionPayloadWriter) error {
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+
+	chunk := newSnapshotChunk(name)
+	for ; iterator.Valid(); iterator.Next() {
+		chunk.add(iterator.Key(), iterator.Value())
+		if chunk.Count == SnapshotChunkRecords {
+			if err := s.writeChunk(chunk, write); err != nil {
+				return err
+			}
+			chunk = newSnapshotChunk(name)
+		}
+	}
+	if chunk.Count > 0 {
+		return s.writeChunk(chunk, write)
+	}
+	return nil
+}
+
+func (s *Snapshotter) writeChunk(chunk *snapshotChunk, write snapshot.ExtensionPayloadWriter) error {
+	bz, err := s.keeper.cdc.Marshal(chunk.done())
+	if err != nil {
+		return err
+	}
+	return write(bz)
+}
+
+// RestoreExtension checks the chunks of a snapshot against the store the
+// multistore chunks restored, in order, and that no section holds records
+// past its last chunk
+func (s *Snapshotter) RestoreExtension(height uint64, format uint32, read snapshot.ExtensionPayloadReader) error {
+	if format != SnapshotFormat {
+		return fmt.Errorf("%w: %d", snapshot.ErrUnknownFormat, format)
+	}
+	ctx := sdk.NewContext(s.cms.CacheMultiStore(), cmtproto.Header{Height: int64(height)}, false, log.NewNopLogger())
+
+	prefixes := make(map[string][]byte, len(snapshotSections))
+	for _, section := range snapshotSections {
+		prefixes[section.name] = section.prefix
+	}
+	cursors := make(map[string][]byte, len(snapshotSections)) // Past the last key checked
+
+	for {
+		bz, err := read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var want types.SnapshotChunk
+		if err := s.keeper.cdc.Unmarshal(bz, &want); err != nil {
+			return fmt.Errorf("decode snapshot chunk: %w", err)
+		}
+		pfx, ok := prefixes[want.Section]
+		if !ok {
+			return fmt.Errorf("snapshot chunk of unknown section %q", want.Section)
+		}
+
+		store := prefix.NewStore(ctx.KVStore(s.keeper.storeKey), pfx)
+		if err := s.restoreChunk(store, cursors[want.Section], want); err != nil {
+			return fmt.Errorf("restore %s: %w", want.Section, err)
+		}
+		cursors[want.Section] = append(append([]byte{}, want.LastKey...), 0)
+	}
+
+	for _, section := range snapshotSections {
+		store := prefix.NewStore(ctx.KVStore(s.keeper.storeKey), section.prefix)
+		iterator := store.Iterator(cursors[section.name], nil)
+		extra := iterator.Valid()
+		iterator.Close()
+		if extra {
+			return fmt.Errorf("restore %s: records past the last snapshot chunk", section.name)
+		}
+	}
+	s.keeper.nullifiers.markLoaded()
+	return nil
+}
+
+// restoreChunk reads the records of a chunk from start in store and checks
+// they are the records it names
+func (s *Snapshotter) restoreChunk(store prefix.Store, start []byte, want types.SnapshotChunk) error {
+	if want.Count == 0 || want.Count > SnapshotChunkRecords {
+		return fmt.Errorf("chunk of %d records", want.Count)
+	}
+
+	iterator := store.Iterator(start, nil)
+	defer iterator.Close()
+
+	got := newSnapshotChunk(want.Section)
+	for ; iterator.Valid() && got.Count < want.Count; iterator.Next() {
+		key := iterator.Key()
+		got.add(key, iterator.Value())
+		if want.Section == "nullifier" && len(key) >= 8 {
+			s.keeper.nullifiers.add(binary.BigEndian.Uint64(key[:8]), key[8:])
+		}
+	}
+	got.done()
+
+	switch {
+	case got.Count != want.Count:
+		return fmt.Errorf("chunk from %X holds %d records, restored %d", want.FirstKey, want.Count, got.Count)
+	case !bytes.Equal(got.FirstKey, want.FirstKey) || !bytes.Equal(got.LastKey, want.LastKey):
+		return fmt.Errorf("chunk spans %X to %X, restored %X to %X", want.FirstKey, want.LastKey, got.FirstKey, got.LastKey)
+	case !bytes.Equal(got.Digest, want.Digest):
+		return fmt.Errorf("chunk from %X to %X does not match the restored records", want.FirstKey, want.LastKey)
+	}
+	return nil
+}
+
+// snapshotChunk accumulates a SnapshotChunk record by record
+type snapshotChunk struct {
+	types.SnapshotChunk
+	digest hash.Hash
+}
+
+func newSnapshotChunk(section string) *snapshotChunk {
+	return &snapshotChunk{SnapshotChunk: types.SnapshotChunk{Section: section}, digest: sha256.New()}
+}
+
+func (c *snapshotChunk) add(key, value []byte) {
+	if c.Count == 0 {
+		c.FirstKey = append([]byte{}, key...)
+	}
+	c.LastKey = append(c.LastKey[:0], key...)
+	c.Count++
+	writeField(c.digest, key)
+	writeField(c.digest, value)
+}
+
+func (c *snapshotChunk) done() *types.SnapshotChunk {
+	c.Digest = c.digest.Sum(nil)
+	return &c.SnapshotChunk
+}
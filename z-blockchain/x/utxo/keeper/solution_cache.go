@@ -0,0 +1,55 @@
+package keeper
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// maxCachedSolutions bounds the mining solution results kept between CheckTx
+// and the delivery of their blocks
+const maxCachedSolutions = 1 << 12
+
+// solutionKey identifies the check of the solution of proof against
+// challenge with pow: the algorithm and its params, the challenge, which
+// commits to the difficulty, and the fields of the proof the solution is
+// read from
+func solutionKey(pow types.ProofOfWork, challenge []byte, proof types.MiningProof) [32]byte {
+	h := sha256.New()
+	writeField(h, []byte(powID(pow)))
+	writeField(h, challenge)
+	writeField(h, []byte(proof.HardwareId))
+	writeField(h, sdk.Uint64ToBigEndian(proof.Difficulty))
+	writeField(h, proof.ZkProof)
+	writeField(h, proof.AuxPow)
+
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// powID names a mining algorithm with the params its checks depend on
+func powID(pow types.ProofOfWork) string {
+	switch p := pow.(type) {
+	case types.EquihashPoW:
+		return fmt.Sprintf("equihash/%d/%d", p.Params.N, p.Params.K)
+	case cysicPoW:
+		return "cysic"
+	case mergedPoW:
+		return fmt.Sprintf("merged/%d/%d-%d/%s", p.factor, p.minEpoch, p.maxEpoch, powID(p.native))
+	}
+	return fmt.Sprintf("%T", pow)
+}
+
+// writeField writes bz to h after its length, so fields cannot run together
+func writeField(h hash.Hash, bz []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(bz)))
+	h.Write(n[:])
+	h.Write(bz)
+}
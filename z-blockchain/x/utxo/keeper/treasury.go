@@ -0,0 +1,124 @@
+package keeper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"shared/address"
+	"z-blockchain/x/utxo/types"
+)
+
+// TreasuryAddress returns the module account of the community treasury
+func TreasuryAddress() sdk.AccAddress {
+	return authtypes.NewModuleAddress(types.TreasuryModuleName)
+}
+
+// TreasuryBalance returns the funds of the treasury not spent yet
+func (k Keeper) TreasuryBalance(ctx sdk.Context) sdk.Int {
+	return k.bankKeeper.GetBalance(ctx, TreasuryAddress(), "z").Amount
+}
+
+// GetTreasuryAccrued returns the total of the treasury tax paid so far
+func (k Keeper) GetTreasuryAccrued(ctx sdk.Context) sdk.Int {
+	bz := ctx.KVStore(k.storeKey).Get(types.TreasuryAccruedKey)
+	if bz == nil {
+		return sdk.ZeroInt()
+	}
+	return intParam(string(bz))
+}
+
+func (k Keeper) setTreasuryAccrued(ctx sdk.Context, accrued sdk.Int) {
+	ctx.KVStore(k.storeKey).Set(types.TreasuryAccruedKey, []byte(accrued.String()))
+}
+
+// payTreasuryTax moves the TreasuryTax share of a reward minted for miner
+// from the module account to the treasury and returns what is left for the
+// miner
+func (k Keeper) payTreasuryTax(ctx sdk.Context, miner sdk.AccAddress, reward sdk.Int) (sdk.Int, error) {
+	rate, err := sdk.NewDecFromStr(k.GetParams(ctx).TreasuryTax)
+	if err != nil {
+		return reward, nil
+	}
+	tax := rate.MulInt(reward).TruncateInt()
+	if !tax.IsPositive() {
+		return reward, nil
+	}
+
+	coins := sdk.NewCoins(sdk.NewCoin("z", tax))
+	if err := k.bankKeeper.SendCoinsFromModuleToModule(ctx, types.ModuleName, types.TreasuryModuleName, coins); err != nil {
+		return sdk.ZeroInt(), err
+	}
+	k.setTreasuryAccrued(ctx, k.GetTreasuryAccrued(ctx).Add(tax))
+
+	k.emitTypedEvent(ctx, &types.EventTreasuryTax{
+		Amount:      tax.String(),
+		Miner:       miner.String(),
+		BlockHeight: ctx.BlockHeight(),
+	})
+	return reward.Sub(tax), nil
+}
+
+// SpendTreasury pays amount of the treasury's funds to recipient as an
+// output, spendable at once, and returns the hash of its transaction. The
+// spends of a recipient in a block are the outputs of one transaction.
+func (k Keeper) SpendTreasury(ctx sdk.Context, recipient sdk.AccAddress, amount sdk.Int) (string, error) {
+	if !amount.IsPositive() {
+		return "", fmt.Errorf("treasury spend must be positive: %s", amount)
+	}
+	if balance := k.TreasuryBalance(ctx); balance.LT(amount) {
+		return "", fmt.Errorf("treasury holds %s, cannot spend %s", balance, amount)
+	}
+	owner, err := address.FromBytes(recipient)
+	if err != nil {
+		return "", err
+	}
+
+	// The value re-enters the UTXO set, backed by the module account
+	coins := sdk.NewCoins(sdk.NewCoin("z", amount))
+	if err := k.bankKeeper.SendCoinsFromModuleToModule(ctx, types.TreasuryModuleName, types.ModuleName, coins); err != nil {
+		return "", err
+	}
+
+	txHash := treasurySpendTxHash(ctx.BlockHeight(), recipient)
+	tx, found := k.GetTransaction(ctx, txHash)
+	if !found {
+		tx = types.UTXOTransaction{
+			TxHash:    txHash,
+			Timestamp: ctx.BlockTime().Unix(),
+			Fee:       "0",
+		}
+	}
+	output := types.TxOutput{Amount: amount.String(), Address: owner.ZChain()}
+	tx.Outputs = append(tx.Outputs, &output)
+	k.SetTransaction(ctx, tx)
+
+	utxo := types.UTXO{
+		TxHash:      txHash,
+		OutputIndex: uint32(len(tx.Outputs) - 1),
+		Address:     output.Address,
+		Amount:      output.Amount,
+		BlockHeight: ctx.BlockHeight(),
+		CreatedAt:   ctx.BlockTime().Unix(),
+	}
+	k.SetUTXO(ctx, utxo)
+	k.emitUTXOCreated(ctx, utxo)
+
+	k.emitTypedEvent(ctx, &types.EventTreasurySpend{
+		Recipient:   recipient.String(),
+		Amount:      amount.String(),
+		TxHash:      txHash,
+		BlockHeight: ctx.BlockHeight(),
+	})
+	return txHash, nil
+}
+
+// treasurySpendTxHash identifies the transaction paying the treasury spends
+// of recipient at height
+func treasurySpendTxHash(height int64, recipient sdk.AccAddress) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("treasury:%d:%s", height, recipient)))
+	return hex.EncodeToString(hash[:])
+}
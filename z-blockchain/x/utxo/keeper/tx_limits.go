@@ -0,0 +1,100 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// Verification gas. A transaction is charged for the work its messages ask of
+// every validator, on top of the store gas of the state it touches, so the
+// consensus max gas bounds the verification time of a block as well as its
+// writes.
+const (
+	// TxByteGas is charged per encoded byte of the transaction, for decoding
+	// and hashing it; a signature hashes the whole transaction
+	TxByteGas = 10
+
+	// SignatureVerifyGas is charged per transparent input, for the ECDSA
+	// recovery and script run of its scriptSig
+	SignatureVerifyGas = 2000
+
+	// ProofVerifyGas is charged per shielded or mining proof, for the pairing
+	// check of a Groth16 proof or the check of an Equihash solution
+	ProofVerifyGas = 400000
+
+	// MemoryOpeningGas is charged per opening of a memory challenge response,
+	// for its Merkle path
+	MemoryOpeningGas = 500
+)
+
+// TxWeight is the verification work of a transaction's utxo messages
+type TxWeight struct {
+	Inputs   uint64 // Transparent inputs, one signature each
+	Outputs  uint64 // Transparent outputs and note commitments
+	Proofs   uint64 // Shielded and mining proofs
+	Openings uint64 // Memory challenge openings
+}
+
+// Add returns the weight of both w and other
+func (w TxWeight) Add(other TxWeight) TxWeight {
+	return TxWeight{
+		Inputs:   w.Inputs + other.Inputs,
+		Outputs:  w.Outputs + other.Outputs,
+		Proofs:   w.Proofs + other.Proofs,
+		Openings: w.Openings + other.Openings,
+	}
+}
+
+// Gas is the verification gas of w in a transaction of size bytes
+func (w TxWeight) Gas(size uint64) uint64 {
+	return size*TxByteGas +
+		w.Inputs*SignatureVerifyGas +
+		w.Proofs*ProofVerifyGas +
+		w.Openings*MemoryOpeningGas
+}
+
+// MsgWeight returns the verification work of msg; messages of other modules
+// weigh nothing
+func MsgWeight(msg sdk.Msg) TxWeight {
+	switch msg := msg.(type) {
+	case *types.MsgSendUTXO:
+		return TxWeight{Inputs: uint64(len(msg.Inputs)), Outputs: uint64(len(msg.Outputs))}
+	case *types.MsgSendShielded:
+		return TxWeight{Outputs: uint64(len(msg.Commitments) + len(msg.TransparentOutputs)), Proofs: 1}
+	case *types.MsgShield:
+		return TxWeight{Inputs: uint64(len(msg.Inputs)), Outputs: uint64(len(msg.Outputs) + len(msg.Commitments)), Proofs: 1}
+	case *types.MsgDeshield:
+		return TxWeight{Outputs: uint64(len(msg.Outputs) + len(msg.Commitments)), Proofs: 1}
+	case *types.MsgSubmitMiningProof:
+		return TxWeight{Proofs: 1}
+	case *types.MsgRespondMemoryChallenge:
+		return TxWeight{Openings: uint64(len(msg.Openings))}
+	}
+	return TxWeight{}
+}
+
+// CheckTxLimits checks a transaction of size bytes and weight w against the
+// transaction limits of the params
+func (k Keeper) CheckTxLimits(ctx sdk.Context, w TxWeight, size uint64) error {
+	limits := k.GetParams(ctx).TxLimitParams
+	switch {
+	case size > limits.MaxTxBytes:
+		return fmt.Errorf("transaction of %d bytes exceeds the limit of %d", size, limits.MaxTxBytes)
+	case w.Inputs > uint64(limits.MaxTxInputs):
+		return fmt.Errorf("transaction with %d inputs exceeds the limit of %d", w.Inputs, limits.MaxTxInputs)
+	case w.Outputs > uint64(limits.MaxTxOutputs):
+		return fmt.Errorf("transaction with %d outputs exceeds the limit of %d", w.Outputs, limits.MaxTxOutputs)
+	case w.Proofs > uint64(limits.MaxTxProofs):
+		return fmt.Errorf("transaction with %d proofs exceeds the limit of %d", w.Proofs, limits.MaxTxProofs)
+	}
+	return nil
+}
+
+// ConsumeVerificationGas charges the verification gas of a transaction of
+// size bytes and weight w to the transaction's gas meter
+func (k Keeper) ConsumeVerificationGas(ctx sdk.Context, w TxWeight, size uint64) {
+	ctx.GasMeter().ConsumeGas(w.Gas(size), "utxo verification")
+}
@@ -0,0 +1,217 @@
+package keeper
+
+import (
+	"bytes"
+	"fmt"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// setBlockHash keeps the hash of the block ending for the uncles of the
+// MaxUncleDepth blocks after, which build on it, and drops the hash, winner
+// and uncle credits of the height no uncle can build on any more
+func (k Keeper) setBlockHash(ctx sdk.Context) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockHashKey)
+	store.Set(sdk.Uint64ToBigEndian(uint64(ctx.BlockHeight())), ctx.HeaderHash())
+
+	if expired := ctx.BlockHeight() - types.MaxUncleDepth - 1; expired >= 0 {
+		store.Delete(sdk.Uint64ToBigEndian(uint64(expired)))
+		prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockWinnerKey).Delete(sdk.Uint64ToBigEndian(uint64(expired)))
+		k.pruneUncleCredits(ctx, expired)
+	}
+}
+
+// setBlockWinner records the miner of the block's winning proof, whose other
+// solutions for the height are not uncles
+func (k Keeper) setBlockWinner(ctx sdk.Context, miner string) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockWinnerKey)
+	store.Set(sdk.Uint64ToBigEndian(uint64(ctx.BlockHeight())), []byte(miner))
+}
+
+// GetBlockWinner returns the miner of the winning proof of a block recent
+// enough for uncles to build on
+func (k Keeper) GetBlockWinner(ctx sdk.Context, height int64) (string, bool) {
+	if height < 0 {
+		return "", false
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockWinnerKey)
+	bz := store.Get(sdk.Uint64ToBigEndian(uint64(height)))
+	return string(bz), bz != nil
+}
+
+func uncleCreditKey(height int64, miner string) []byte {
+	return append(sdk.Uint64ToBigEndian(uint64(height)), miner...)
+}
+
+// countUncleCredits returns how many uncles all blocks so far paid for height
+func (k Keeper) countUncleCredits(ctx sdk.Context, height int64) int {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleCreditKey)
+	iterator := store.Iterator(sdk.Uint64ToBigEndian(uint64(height)), sdk.Uint64ToBigEndian(uint64(height+1)))
+	defer iterator.Close()
+
+	n := 0
+	for ; iterator.Valid(); iterator.Next() {
+		n++
+	}
+	return n
+}
+
+// pruneUncleCredits forgets the uncle credits of a height leaving the uncle
+// window
+func (k Keeper) pruneUncleCredits(ctx sdk.Context, height int64) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleCreditKey)
+	iterator := store.Iterator(sdk.Uint64ToBigEndian(uint64(height)), sdk.Uint64ToBigEndian(uint64(height+1)))
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// checkUncleCredit refuses an uncle from the miner that won its height, from
+// a miner already credited an uncle for the height, or once the height has
+// its MaxUnclesPerHeight uncles
+func (k Keeper) checkUncleCredit(ctx sdk.Context, height int64, miner string) error {
+	if winner, found := k.GetBlockWinner(ctx, height); found && winner == miner {
+		return fmt.Errorf("miner %s won height %d and earns no uncle for it", miner, height)
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleCreditKey)
+	if store.Has(uncleCreditKey(height, miner)) {
+		return fmt.Errorf("miner %s already has an uncle for height %d", miner, height)
+	}
+	if n := k.countUncleCredits(ctx, height); n >= types.MaxUnclesPerHeight {
+		return fmt.Errorf("height %d already has %d uncles", height, n)
+	}
+	return nil
+}
+
+// GetBlockHash returns the hash of a block recent enough for uncles to build
+// on
+func (k Keeper) GetBlockHash(ctx sdk.Context, height int64) ([]byte, bool) {
+	if height < 0 {
+		return nil, false
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.BlockHashKey)
+	bz := store.Get(sdk.Uint64ToBigEndian(uint64(height)))
+	return bz, bz != nil
+}
+
+// checkUncleBinding refuses an uncle that is not for one of the MaxUncleDepth
+// heights before the block of ctx, that does not build on the parent of its
+// height, that checkUncleCredit refuses, or whose solution time was out of
+// bounds at that height
+func (k Keeper) checkUncleBinding(ctx sdk.Context, proof types.MiningProof) error {
+	depth := types.UncleDepth(ctx.BlockHeight(), proof.Height)
+	if depth < 1 || depth > types.MaxUncleDepth {
+		return fmt.Errorf("mining proof for height %d is more than %d blocks before height %d", proof.Height, types.MaxUncleDepth, ctx.BlockHeight())
+	}
+	parent, found := k.GetBlockHash(ctx, proof.Height-1)
+	if !found {
+		return fmt.Errorf("hash of block %d is not recorded", proof.Height-1)
+	}
+	if !bytes.Equal(proof.PrevBlockHash, parent) {
+		return fmt.Errorf("mining proof for height %d builds on block %X, not its parent %X", proof.Height, proof.PrevBlockHash, parent)
+	}
+	if err := k.checkUncleCredit(ctx, proof.Height, proof.MinerAddress); err != nil {
+		return err
+	}
+	return k.checkUncleSolutionTime(ctx, proof.Height, proof.Timestamp)
+}
+
+// uncleDifficulty returns the difficulty in force while the block at height
+// was solved, which its uncles were solved at too
+func (k Keeper) uncleDifficulty(ctx sdk.Context, height int64) (uint64, error) {
+	difficulty, found := k.GetBlockDifficulty(ctx, height)
+	if !found {
+		return 0, fmt.Errorf("difficulty of block %d is not recorded", height)
+	}
+	return difficulty, nil
+}
+
+// GetUncles returns the uncles included in the current block so far
+func (k Keeper) GetUncles(ctx sdk.Context) []types.BlockProof {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleProofKey)
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+
+	var uncles []types.BlockProof
+	for ; iterator.Valid(); iterator.Next() {
+		var uncle types.BlockProof
+		k.cdc.MustUnmarshal(iterator.Value(), &uncle)
+		uncles = append(uncles, uncle)
+	}
+	return uncles
+}
+
+// addUncle records a verified uncle for its reward at the end of the block,
+// and credits its miner for its height, refusing it once the block holds
+// MaxUnclesPerBlock or if checkUncleCredit does
+func (k Keeper) addUncle(ctx sdk.Context, uncle types.BlockProof) error {
+	if n := len(k.GetUncles(ctx)); n >= types.MaxUnclesPerBlock {
+		return fmt.Errorf("block already holds %d uncles", n)
+	}
+	if err := k.checkUncleCredit(ctx, uncle.Height, uncle.MinerAddress); err != nil {
+		return err
+	}
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleProofKey)
+	store.Set(uncle.SolutionHash, k.cdc.MustMarshal(&uncle))
+
+	credits := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleCreditKey)
+	credits.Set(uncleCreditKey(uncle.Height, uncle.MinerAddress), []byte{1})
+	return nil
+}
+
+// rewardUncles pays the uncles of the block their UncleReward, de-rated by
+// their miner's memory audits like any reward, within the hard cap and after
+// the treasury tax, and clears them. Uncles do not win the block's fees.
+func (k Keeper) rewardUncles(ctx sdk.Context) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UncleProofKey)
+	baseReward := k.CalculateBlockReward(ctx.BlockHeight())
+
+	for _, uncle := range k.GetUncles(ctx) {
+		store.Delete(uncle.SolutionHash)
+
+		miner, err := sdk.AccAddressFromBech32(uncle.MinerAddress)
+		if err != nil {
+			k.Logger(ctx).Error("Invalid uncle miner address", "miner", uncle.MinerAddress, "error", err)
+			continue
+		}
+
+		// Active miners are included in the next memory audit
+		k.RecordMinedBlock(ctx, uncle.MinerAddress, uncle.HardwareId)
+
+		depth := types.UncleDepth(ctx.BlockHeight(), uncle.Height)
+		reward := k.MemoryFactor(ctx, uncle.MinerAddress).MulInt(types.UncleReward(baseReward, depth)).TruncateInt()
+		reward, err = k.mintReward(ctx, reward)
+		if err == nil {
+			reward, err = k.payTreasuryTax(ctx, miner, reward)
+		}
+		if err != nil {
+			k.Logger(ctx).Error("Failed to pay the uncle reward", "miner", uncle.MinerAddress, "error", err)
+			continue
+		}
+		if !reward.IsPositive() {
+			continue
+		}
+		if err := k.payCoinbase(ctx, miner, reward); err != nil {
+			k.Logger(ctx).Error("Failed to pay the uncle reward", "miner", uncle.MinerAddress, "error", err)
+			continue
+		}
+
+		k.emitTypedEvent(ctx, &types.EventUncleRewarded{
+			Miner:       uncle.MinerAddress,
+			HardwareId:  uncle.HardwareId,
+			UncleHeight: uncle.Height,
+			Depth:       depth,
+			Reward:      reward.String(),
+			BlockHeight: ctx.BlockHeight(),
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package keeper
+
+import (
+	"testing"
+
+	"z-blockchain/x/utxo/types"
+)
+
+func TestAddUncleLimitsUnclesPerMinerAndHeight(t *testing.T) {
+	k, ctx := setupKeeper(t)
+
+	// Height 8 was won by z1winner
+	k.setBlockWinner(ctx.WithBlockHeight(8), "z1winner")
+
+	uncle := func(miner string, height int64, hash string) types.BlockProof {
+		return types.BlockProof{MinerAddress: miner, HardwareId: "gpu", SolutionHash: []byte(hash), Height: height}
+	}
+
+	if err := k.addUncle(ctx, uncle("z1winner", 8, "a")); err == nil {
+		t.Error("the winner of height 8 earned an uncle for it")
+	}
+	if err := k.addUncle(ctx, uncle("z1loser", 8, "b")); err != nil {
+		t.Fatalf("first uncle for height 8 refused: %v", err)
+	}
+	if err := k.addUncle(ctx, uncle("z1loser", 8, "c")); err == nil {
+		t.Error("a second uncle by the same miner for height 8 was accepted")
+	}
+	if err := k.addUncle(ctx, uncle("z1other", 8, "d")); err == nil {
+		t.Errorf("more than %d uncles were accepted for height 8", types.MaxUnclesPerHeight)
+	}
+
+	// The credits outlive the block that paid them
+	next := ctx.WithBlockHeight(11)
+	if err := k.checkUncleCredit(next, 8, "z1other"); err == nil {
+		t.Error("height 8 paid another uncle in a later block")
+	}
+	if err := k.addUncle(next, uncle("z1other", 9, "e")); err != nil {
+		t.Errorf("uncle for height 9 refused: %v", err)
+	}
+
+	// And are dropped with the height's hash
+	k.setBlockHash(ctx.WithBlockHeight(8 + types.MaxUncleDepth + 1))
+	if n := k.countUncleCredits(ctx, 8); n != 0 {
+		t.Errorf("height 8 still has %d uncle credits past the uncle window", n)
+	}
+	if _, found := k.GetBlockWinner(ctx, 8); found {
+		t.Error("the winner of height 8 is kept past the uncle window")
+	}
+}
@@ -0,0 +1,101 @@
+package keeper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/accumulator"
+	"z-blockchain/x/utxo/types"
+)
+
+func (k Keeper) utxoSet(ctx sdk.Context) *accumulator.Tree {
+	return accumulator.New(prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOSetKey))
+}
+
+// updateUTXOSet adds an unspent output to the accumulator, or removes a spent
+// one. bz is the encoding of utxo.
+func (k Keeper) updateUTXOSet(ctx sdk.Context, utxo types.UTXO, bz []byte) {
+	key := accumulator.Key(utxo.TxHash, utxo.OutputIndex)
+	if utxo.IsSpent {
+		k.utxoSet(ctx).Update(key, nil)
+		return
+	}
+	k.utxoSet(ctx).Update(key, accumulator.ValueHash(bz))
+}
+
+// CommitUTXOSet records the accumulator root of the UTXO set at the end of
+// the block and emits it, so light clients and the bridge can prove outputs
+// against it
+func (k Keeper) CommitUTXOSet(ctx sdk.Context) {
+	root := k.utxoSet(ctx).Root()
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOSetRootKey)
+	store.Set(sdk.Uint64ToBigEndian(uint64(ctx.BlockHeight())), root)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeUTXOSetRoot,
+			sdk.NewAttribute(types.AttributeKeyRoot, hex.EncodeToString(root)),
+			sdk.NewAttribute(types.AttributeKeyBlockHeight, sdk.NewInt(ctx.BlockHeight()).String()),
+		),
+	)
+}
+
+// GetUTXOSetRoot returns the accumulator root committed at the end of height
+func (k Keeper) GetUTXOSetRoot(ctx sdk.Context, height int64) ([]byte, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.UTXOSetRootKey)
+	root := store.Get(sdk.Uint64ToBigEndian(uint64(height)))
+	return root, root != nil
+}
+
+// ProveUTXO proves whether the output is in the current UTXO set. The UTXO
+// is returned when it is unspent.
+func (k Keeper) ProveUTXO(ctx sdk.Context, txHash string, outputIndex uint32) (accumulator.Proof, *types.UTXO) {
+	proof := k.utxoSet(ctx).Prove(accumulator.Key(txHash, outputIndex))
+	utxo, found := k.GetUTXO(ctx, txHash, outputIndex)
+	if !found || utxo.IsSpent {
+		return proof, nil
+	}
+	return proof, &utxo
+}
+
+// SetTxLocation records that the UTXO transaction txHash is part of the
+// CometBFT transaction being delivered
+func (k Keeper) SetTxLocation(ctx sdk.Context, txHash string) {
+	location := types.TxLocation{Height: ctx.BlockHeight()}
+	if txBytes := ctx.TxBytes(); txBytes != nil {
+		hash := sha256.Sum256(txBytes)
+		location.BlockTxHash = hash[:]
+	}
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.TxLocationKey)
+	store.Set([]byte(txHash), k.cdc.MustMarshal(&location))
+}
+
+// GetTxLocation returns where the UTXO transaction txHash was included
+func (k Keeper) GetTxLocation(ctx sdk.Context, txHash string) (types.TxLocation, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.TxLocationKey)
+	bz := store.Get([]byte(txHash))
+	if bz == nil {
+		return types.TxLocation{}, false
+	}
+
+	var location types.TxLocation
+	k.cdc.MustUnmarshal(bz, &location)
+	return location, true
+}
+
+// proveOutput is the accumulator proof of an output in the current UTXO set
+func (k Keeper) proveOutput(ctx sdk.Context, txHash string, outputIndex uint32) types.OutputProof {
+	proof, utxo := k.ProveUTXO(ctx, txHash, outputIndex)
+	return types.OutputProof{
+		OutputIndex: outputIndex,
+		Utxo:        utxo,
+		Bitmap:      proof.Bitmap[:],
+		Siblings:    proof.Siblings,
+	}
+}
@@ -0,0 +1,188 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"cosmossdk.io/store/prefix"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/types"
+)
+
+// GetBlockTimeWatchdog returns the watchdog state; the first window starts
+// on the first call
+func (k Keeper) GetBlockTimeWatchdog(ctx sdk.Context) (types.BlockTimeWatchdog, bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.BlockTimeWatchdogKey)
+	if bz == nil {
+		return types.BlockTimeWatchdog{}, false
+	}
+
+	var watchdog types.BlockTimeWatchdog
+	k.cdc.MustUnmarshal(bz, &watchdog)
+	return watchdog, true
+}
+
+func (k Keeper) setBlockTimeWatchdog(ctx sdk.Context, watchdog types.BlockTimeWatchdog) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.BlockTimeWatchdogKey, k.cdc.MustMarshal(&watchdog))
+}
+
+func watchdogEventKey(id uint64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, id)
+	return bz
+}
+
+// GetWatchdogEvent returns an entry of the watchdog history
+func (k Keeper) GetWatchdogEvent(ctx sdk.Context, id uint64) (types.WatchdogEvent, bool) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.WatchdogEventKey)
+	bz := store.Get(watchdogEventKey(id))
+	if bz == nil {
+		return types.WatchdogEvent{}, false
+	}
+
+	var event types.WatchdogEvent
+	k.cdc.MustUnmarshal(bz, &event)
+	return event, true
+}
+
+// recordWatchdogEvent appends to the history and emits the matching event
+func (k Keeper) recordWatchdogEvent(ctx sdk.Context, watchdog *types.BlockTimeWatchdog, event types.WatchdogEvent, attrs ...sdk.Attribute) {
+	event.Id = watchdog.NextEventId
+	event.Height = ctx.BlockHeight()
+	event.Time = ctx.BlockTime().UnixMilli()
+	event.AverageBlockMillis = watchdog.LastAverageMillis
+	event.ConsecutiveBreaches = watchdog.ConsecutiveBreaches
+	watchdog.NextEventId++
+
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.WatchdogEventKey)
+	store.Set(watchdogEventKey(event.Id), k.cdc.MustMarshal(&event))
+
+	attrs = append([]sdk.Attribute{
+		sdk.NewAttribute(types.AttributeKeyWatchdogKind, event.Kind),
+		sdk.NewAttribute(types.AttributeKeyAverageBlockMs, strconv.FormatInt(event.AverageBlockMillis, 10)),
+		sdk.NewAttribute(types.AttributeKeyBreaches, strconv.FormatUint(uint64(event.ConsecutiveBreaches), 10)),
+		sdk.NewAttribute(types.AttributeKeyBlockHeight, strconv.FormatInt(event.Height, 10)),
+	}, attrs...)
+	ctx.EventManager().EmitEvent(sdk.NewEvent(types.EventTypeBlockTimeWatchdog, attrs...))
+}
+
+// WatchBlockTimes closes the current window once it spans the configured
+// number of blocks. Windows averaging above the SLA threshold are breaches;
+// after enough consecutive breaches the difficulty floor is lowered, bounded
+// per step and against the floor governance set. When auto adjustment is off,
+// or the bounds stop it short of the floor the block times call for, a
+// parameter change is proposed to governance through an event instead.
+func (k Keeper) WatchBlockTimes(ctx sdk.Context) {
+	params := k.GetParams(ctx)
+	now := ctx.BlockTime().UnixMilli()
+
+	watchdog, found := k.GetBlockTimeWatchdog(ctx)
+	if !found {
+		k.setBlockTimeWatchdog(ctx, types.BlockTimeWatchdog{
+			WindowStartHeight: ctx.BlockHeight(),
+			WindowStartTime:   now,
+			GovernanceFloor:   params.MinDifficulty,
+			AppliedFloor:      params.MinDifficulty,
+		})
+		return
+	}
+
+	blocks := ctx.BlockHeight() - watchdog.WindowStartHeight
+	if blocks < params.WatchdogWindowBlocks {
+		return
+	}
+
+	watchdog.LastAverageMillis = (now - watchdog.WindowStartTime) / blocks
+	watchdog.WindowStartHeight = ctx.BlockHeight()
+	watchdog.WindowStartTime = now
+
+	// A floor the watchdog did not set came from governance and becomes the
+	// new reference for the reduction bound
+	if params.MinDifficulty != watchdog.AppliedFloor {
+		watchdog.GovernanceFloor = params.MinDifficulty
+		watchdog.AppliedFloor = params.MinDifficulty
+	}
+
+	if watchdog.LastAverageMillis <= params.BlockTimeThresholdMillis {
+		if watchdog.ConsecutiveBreaches > 0 {
+			k.recordWatchdogEvent(ctx, &watchdog, types.WatchdogEvent{Kind: types.WatchdogRecovered})
+			watchdog.ConsecutiveBreaches = 0
+		}
+		k.setBlockTimeWatchdog(ctx, watchdog)
+		return
+	}
+
+	watchdog.ConsecutiveBreaches++
+	k.recordWatchdogEvent(ctx, &watchdog, types.WatchdogEvent{Kind: types.WatchdogBreach})
+
+	if watchdog.ConsecutiveBreaches >= params.WatchdogBreachWindows {
+		k.adjustDifficultyFloor(ctx, &watchdog, params)
+		watchdog.ConsecutiveBreaches = 0
+	}
+
+	k.setBlockTimeWatchdog(ctx, watchdog)
+}
+
+// adjustDifficultyFloor lowers the floor as far as the bounds allow and
+// proposes the rest
+func (k Keeper) adjustDifficultyFloor(ctx sdk.Context, watchdog *types.BlockTimeWatchdog, params types.Params) {
+	floor := params.MinDifficulty
+	proposed := types.DifficultyFloorFor(floor, watchdog.LastAverageMillis, params.BlockTimeWatchdogParams)
+	if proposed >= floor {
+		return
+	}
+
+	adjusted := floor
+	if params.AutoAdjustFloor {
+		adjusted = types.BoundedDifficultyFloor(floor, watchdog.GovernanceFloor, proposed, params.BlockTimeWatchdogParams)
+	}
+
+	if adjusted < floor {
+		k.paramstore.Set(ctx, types.KeyMinDifficulty, adjusted)
+		watchdog.AppliedFloor = adjusted
+
+		// A difficulty pinned at the old floor follows it down right away
+		if k.GetDifficulty(ctx) <= floor {
+			k.SetDifficulty(ctx, adjusted)
+		}
+
+		k.recordWatchdogEvent(ctx, watchdog, types.WatchdogEvent{
+			Kind:     types.WatchdogFloorAdjusted,
+			OldFloor: floor,
+			NewFloor: adjusted,
+		},
+			sdk.NewAttribute(types.AttributeKeyOldFloor, strconv.FormatUint(floor, 10)),
+			sdk.NewAttribute(types.AttributeKeyNewFloor, strconv.FormatUint(adjusted, 10)),
+		)
+
+		k.Logger(ctx).Info("Block time watchdog lowered the difficulty floor",
+			"old_floor", floor,
+			"new_floor", adjusted,
+			"average_block_ms", watchdog.LastAverageMillis)
+	}
+
+	if proposed < adjusted {
+		// Ready to submit as a legacy param change proposal
+		change := fmt.Sprintf(`{"subspace":%q,"key":%q,"value":"\"%d\""}`, types.ModuleName, string(types.KeyMinDifficulty), proposed)
+
+		k.recordWatchdogEvent(ctx, watchdog, types.WatchdogEvent{
+			Kind:     types.WatchdogFloorProposal,
+			OldFloor: adjusted,
+			NewFloor: proposed,
+		},
+			sdk.NewAttribute(types.AttributeKeyOldFloor, strconv.FormatUint(adjusted, 10)),
+			sdk.NewAttribute(types.AttributeKeyNewFloor, strconv.FormatUint(proposed, 10)),
+			sdk.NewAttribute(types.AttributeKeyParamChange, change),
+		)
+
+		k.Logger(ctx).Info("Block time watchdog proposes a lower difficulty floor to governance",
+			"floor", adjusted,
+			"proposed_floor", proposed,
+			"average_block_ms", watchdog.LastAverageMillis)
+	}
+}
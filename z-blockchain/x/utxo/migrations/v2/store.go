@@ -0,0 +1,149 @@
+// Package v2 migrates the utxo store from the layout of consensus version 1
+// to that of version 2.
+//
+// Version 1 kept UTXOs by tx_hash:output_index only, nullifiers directly
+// under the nullifier prefix, and note commitments as commitment||height
+// markers with no tree. Version 2 adds the address index and accumulator of
+// the UTXO set, shards nullifiers by epoch, and keeps commitments in the note
+// commitment tree, mapped to their positions.
+package v2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/accumulator"
+	"z-blockchain/x/utxo/notetree"
+	"z-blockchain/x/utxo/types"
+)
+
+const (
+	// v1 nullifiers and commitments are 32 bytes; a v1 commitment key is
+	// followed by the big endian height it was added at
+	v1NullifierKeyLen  = 32
+	v1CommitmentKeyLen = 32 + 8
+)
+
+// MigrateStore moves the utxo store from version 1 to version 2 in place
+func MigrateStore(ctx sdk.Context, storeKey storetypes.StoreKey, cdc codec.BinaryCodec) error {
+	store := ctx.KVStore(storeKey)
+	if err := migrateUTXOs(store, cdc); err != nil {
+		return err
+	}
+	migrateNullifiers(store)
+	return migrateCommitments(ctx, store)
+}
+
+// migrateUTXOs builds the address index and accumulator of the UTXO set
+func migrateUTXOs(store storetypes.KVStore, cdc codec.BinaryCodec) error {
+	utxoStore := prefix.NewStore(store, types.UTXOKey)
+	set := accumulator.New(prefix.NewStore(store, types.UTXOSetKey))
+
+	// Writes wait until the iteration is done
+	type unspent struct {
+		key, bz []byte
+		utxo    types.UTXO
+	}
+	var utxos []unspent
+	iterator := utxoStore.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		var utxo types.UTXO
+		if err := cdc.Unmarshal(iterator.Value(), &utxo); err != nil {
+			iterator.Close()
+			return fmt.Errorf("UTXO %s: %w", iterator.Key(), err)
+		}
+		if !utxo.IsSpent {
+			utxos = append(utxos, unspent{key: iterator.Key(), bz: iterator.Value(), utxo: utxo})
+		}
+	}
+	iterator.Close()
+
+	for _, u := range utxos {
+		index := prefix.NewStore(store, types.UTXOByAddressPrefix(u.utxo.Address))
+		index.Set(u.key, []byte{1})
+		set.Update(accumulator.Key(u.utxo.TxHash, u.utxo.OutputIndex), accumulator.ValueHash(u.bz))
+	}
+	return nil
+}
+
+// migrateNullifiers moves every nullifier into epoch 0. The height a v1
+// nullifier was revealed at is not known; a lookup checks every epoch, so
+// which one holds it only matters to the size of the shards.
+func migrateNullifiers(store storetypes.KVStore) {
+	nullifierStore := prefix.NewStore(store, types.NullifierKey)
+
+	var nullifiers [][]byte
+	iterator := nullifierStore.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		if len(iterator.Key()) == v1NullifierKeyLen {
+			nullifiers = append(nullifiers, append([]byte{}, iterator.Key()...))
+		}
+	}
+	iterator.Close()
+
+	for _, nullifier := range nullifiers {
+		nullifierStore.Delete(nullifier)
+		key := make([]byte, 8, 8+len(nullifier))
+		nullifierStore.Set(append(key, nullifier...), []byte{1})
+	}
+}
+
+// migrateCommitments appends the v1 commitments to the note commitment tree,
+// by the height they were added at and then by value, as v1 kept no order
+// within a block. The root of the tree becomes an anchor at the upgrade
+// height.
+func migrateCommitments(ctx sdk.Context, store storetypes.KVStore) error {
+	commitmentStore := prefix.NewStore(store, types.CommitmentKey)
+
+	type v1Commitment struct {
+		commitment []byte
+		height     uint64
+	}
+	var commitments []v1Commitment
+	iterator := commitmentStore.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		if len(key) == v1CommitmentKeyLen {
+			commitments = append(commitments, v1Commitment{
+				commitment: append([]byte{}, key[:32]...),
+				height:     binary.BigEndian.Uint64(key[32:]),
+			})
+		}
+	}
+	iterator.Close()
+
+	sort.Slice(commitments, func(i, j int) bool {
+		if commitments[i].height != commitments[j].height {
+			return commitments[i].height < commitments[j].height
+		}
+		return bytes.Compare(commitments[i].commitment, commitments[j].commitment) < 0
+	})
+
+	tree := notetree.New(prefix.NewStore(store, types.NoteTreeKey))
+	for _, c := range commitments {
+		commitmentStore.Delete(append(append([]byte{}, c.commitment...), sdk.Uint64ToBigEndian(c.height)...))
+		if commitmentStore.Has(c.commitment) {
+			return fmt.Errorf("note commitment added twice: %x", c.commitment)
+		}
+
+		position, err := tree.Append(c.commitment)
+		if err != nil {
+			return err
+		}
+		commitmentStore.Set(c.commitment, sdk.Uint64ToBigEndian(position))
+	}
+
+	anchors := prefix.NewStore(store, types.AnchorKey)
+	if root := tree.Root(); !anchors.Has(root) {
+		anchors.Set(root, sdk.Uint64ToBigEndian(uint64(ctx.BlockHeight())))
+	}
+	return nil
+}
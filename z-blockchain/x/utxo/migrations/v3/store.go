@@ -0,0 +1,106 @@
+// Package v3 migrates the utxo store from the layout of consensus version 2
+// to that of version 3.
+//
+// Version 2 judged mining hardware against the SupportedDevices param and
+// device maps compiled into the binary. Version 3 reads eligibility from the
+// hardware registry, managed by the registrars of the new HardwareRegistrars
+// param, and bonuses from the new HardwareBonusSchedule param governance
+// sets. Its memory audits draw the buffer size at random and flag miners whose
+// response times match an ASIC, under three new audit params. The new
+// PowAlgorithm param selects the mining algorithm, Equihash until governance
+// rotates it, the new MergedMiningFactor param weighs the Altcoinchain
+// headers merge mining the chain, the new MergedMiningAnchor param anchors
+// the Ethash epochs they may be mined in, and the new TreasuryTax param
+// routes a share of block rewards to the community treasury. The numbers of
+// unspent outputs and of nullifiers are kept as counts for the metrics.
+// Nullifiers are keyed by their canonical field element encoding.
+package v3
+
+import (
+	"bytes"
+
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+
+	"shared/poseidon"
+	"z-blockchain/x/utxo/types"
+)
+
+// MigrateStore moves the utxo store from version 2 to version 3 in place: it
+// adds the HardwareRegistrars param, with no registrars, the bonus schedule
+// version 2 compiled in, the default ASIC detection params, Equihash as the
+// PoW algorithm, the default merged mining factor, anchor and treasury tax, seeds
+// the registry with the devices the chain supported, or the default devices
+// if it listed none, re-keys nullifiers stored in another encoding of their
+// field element, and counts the unspent outputs and nullifiers
+func MigrateStore(ctx sdk.Context, storeKey storetypes.StoreKey, cdc codec.BinaryCodec, paramSpace paramtypes.Subspace) error {
+	paramSpace.Set(ctx, types.KeyHardwareRegistrars, []string{})
+	paramSpace.Set(ctx, types.KeyHardwareBonuses, types.DefaultHardwareBonusSchedule())
+	audit := types.DefaultMemoryAuditParams()
+	paramSpace.Set(ctx, types.KeyAuditMemoryScales, audit.AuditMemoryScales)
+	paramSpace.Set(ctx, types.KeyASICLatencyRatio, audit.AsicLatencyRatio)
+	paramSpace.Set(ctx, types.KeyASICProfileAudits, audit.AsicProfileAudits)
+	paramSpace.Set(ctx, types.KeyPowAlgorithm, types.PowAlgorithmEquihash)
+	paramSpace.Set(ctx, types.KeyMergedMiningFactor, uint64(types.DefaultMergedMiningFactor))
+	paramSpace.Set(ctx, types.KeyTreasuryTax, types.DefaultParams().TreasuryTax)
+	paramSpace.Set(ctx, types.KeyMergedMiningAnchor, types.DefaultParams().MergedMiningAnchor)
+
+	var devices []string
+	paramSpace.GetIfExists(ctx, types.KeySupportedDevices, &devices)
+	if len(devices) == 0 {
+		devices = types.DefaultHardwareDevices
+	}
+
+	store := prefix.NewStore(ctx.KVStore(storeKey), types.HardwareClassKey)
+	for _, class := range types.HardwareRegistry(devices) {
+		class.RegisteredHeight = ctx.BlockHeight()
+		store.Set([]byte(class.DeviceClass), cdc.MustMarshal(&class))
+	}
+
+	kv := ctx.KVStore(storeKey)
+	canonicalizeNullifiers(kv)
+	kv.Set(types.UTXOCountKey, sdk.Uint64ToBigEndian(countKeys(kv, types.UTXOByAddressKey)))
+	kv.Set(types.NullifierCountKey, sdk.Uint64ToBigEndian(countKeys(kv, types.NullifierKey)))
+	return nil
+}
+
+// canonicalizeNullifiers moves each nullifier stored under an encoding other
+// than the canonical one of its field element to the canonical key of its
+// epoch, merging those that turn out to be one nullifier
+func canonicalizeNullifiers(kv storetypes.KVStore) {
+	store := prefix.NewStore(kv, types.NullifierKey)
+
+	var moved [][]byte
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		if len(key) < 8 || bytes.Equal(key[8:], poseidon.Reduce(key[8:])) {
+			continue
+		}
+		moved = append(moved, append([]byte{}, key...))
+	}
+	iterator.Close()
+
+	for _, key := range moved {
+		canonical := append(append([]byte{}, key[:8]...), poseidon.Reduce(key[8:])...)
+
+		store.Delete(key)
+		store.Set(canonical, []byte{1})
+	}
+}
+
+// countKeys counts the keys under a prefix of store
+func countKeys(store storetypes.KVStore, pfx []byte) uint64 {
+	iterator := prefix.NewStore(store, pfx).Iterator(nil, nil)
+	defer iterator.Close()
+
+	var count uint64
+	for ; iterator.Valid(); iterator.Next() {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,152 @@
+package utxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/core/appmodule"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	sdksimulation "github.com/cosmos/cosmos-sdk/x/simulation"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/simulation"
+	"z-blockchain/x/utxo/types"
+)
+
+var (
+	_ module.AppModuleBasic      = AppModuleBasic{}
+	_ module.HasGenesis          = AppModule{}
+	_ module.HasServices         = AppModule{}
+	_ module.HasConsensusVersion = AppModule{}
+	_ module.AppModuleSimulation = AppModule{}
+	_ appmodule.AppModule        = AppModule{}
+	_ appmodule.HasBeginBlocker  = AppModule{}
+	_ appmodule.HasEndBlocker    = AppModule{}
+)
+
+// AppModuleBasic implements the stateless parts of the utxo module: its
+// codecs, default genesis and gateway routes
+type AppModuleBasic struct{}
+
+// Name returns the module's name
+func (AppModuleBasic) Name() string {
+	return types.ModuleName
+}
+
+// RegisterLegacyAminoCodec registers the amino names of the module's messages
+func (AppModuleBasic) RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
+	types.RegisterCodec(cdc)
+}
+
+// RegisterInterfaces registers the module's messages and Msg service
+func (AppModuleBasic) RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
+	types.RegisterInterfaces(registry)
+}
+
+// DefaultGenesis returns the module's default genesis state
+func (AppModuleBasic) DefaultGenesis(cdc codec.JSONCodec) json.RawMessage {
+	return cdc.MustMarshalJSON(types.DefaultGenesis())
+}
+
+// ValidateGenesis checks the module's genesis state
+func (AppModuleBasic) ValidateGenesis(cdc codec.JSONCodec, _ client.TxEncodingConfig, bz json.RawMessage) error {
+	var genState types.GenesisState
+	if err := cdc.UnmarshalJSON(bz, &genState); err != nil {
+		return fmt.Errorf("failed to unmarshal %s genesis state: %w", types.ModuleName, err)
+	}
+	return genState.Validate()
+}
+
+// RegisterGRPCGatewayRoutes serves the Query service over REST
+func (AppModuleBasic) RegisterGRPCGatewayRoutes(clientCtx client.Context, mux *runtime.ServeMux) {
+	if err := types.RegisterQueryHandlerClient(context.Background(), mux, types.NewQueryClient(clientCtx)); err != nil {
+		panic(err)
+	}
+}
+
+// AppModule is the utxo module of the module manager. The app sets its
+// ante, proposal and upgrade handlers on its own, see NewMiningBanDecorator,
+// NewPrepareProposalHandler, NewProcessProposalHandler and
+// CreateUpgradeHandler. When assembling the ante chain, MiningBanDecorator
+// goes after the SDK's SigVerificationDecorator, as it bans the miner a
+// proof names.
+type AppModule struct {
+	AppModuleBasic
+
+	keeper        keeper.Keeper
+	accountKeeper sdksimulation.AccountKeeper
+}
+
+// NewAppModule returns the utxo module of k; accountKeeper picks the
+// simulation's accounts
+func NewAppModule(k keeper.Keeper, accountKeeper sdksimulation.AccountKeeper) AppModule {
+	return AppModule{keeper: k, accountKeeper: accountKeeper}
+}
+
+// IsOnePerModuleType implements the depinject.OnePerModuleType interface
+func (AppModule) IsOnePerModuleType() {}
+
+// IsAppModule implements the appmodule.AppModule interface
+func (AppModule) IsAppModule() {}
+
+// ConsensusVersion returns the version of the module's state machine
+func (AppModule) ConsensusVersion() uint64 {
+	return ConsensusVersion
+}
+
+// RegisterServices registers the Msg and Query services and the store
+// migrations up to ConsensusVersion
+func (am AppModule) RegisterServices(cfg module.Configurator) {
+	types.RegisterMsgServer(cfg.MsgServer(), keeper.NewMsgServerImpl(am.keeper))
+	types.RegisterQueryServer(cfg.QueryServer(), am.keeper)
+
+	if err := RegisterMigrations(cfg, am.keeper); err != nil {
+		panic(fmt.Sprintf("failed to register %s migrations: %s", types.ModuleName, err))
+	}
+}
+
+// InitGenesis initializes the module's state from its genesis
+func (am AppModule) InitGenesis(ctx sdk.Context, cdc codec.JSONCodec, bz json.RawMessage) {
+	var genState types.GenesisState
+	cdc.MustUnmarshalJSON(bz, &genState)
+	InitGenesis(ctx, am.keeper, genState)
+}
+
+// ExportGenesis returns the module's state as genesis
+func (am AppModule) ExportGenesis(ctx sdk.Context, cdc codec.JSONCodec) json.RawMessage {
+	return cdc.MustMarshalJSON(ExportGenesis(ctx, am.keeper))
+}
+
+// BeginBlock runs BeginBlocker
+func (am AppModule) BeginBlock(ctx context.Context) error {
+	BeginBlocker(sdk.UnwrapSDKContext(ctx), am.keeper)
+	return nil
+}
+
+// EndBlock runs EndBlocker
+func (am AppModule) EndBlock(ctx context.Context) error {
+	EndBlocker(sdk.UnwrapSDKContext(ctx), am.keeper)
+	return nil
+}
+
+// GenerateGenesisState creates a randomized genesis for the simulation
+func (AppModule) GenerateGenesisState(simState *module.SimulationState) {
+	simulation.RandomizedGenState(simState)
+}
+
+// RegisterStoreDecoder registers no decoder; the simulation compares stores
+// by their raw bytes
+func (AppModule) RegisterStoreDecoder(simtypes.StoreDecoderRegistry) {}
+
+// WeightedOperations returns the module's simulation operations
+func (am AppModule) WeightedOperations(simState module.SimulationState) []simtypes.WeightedOperation {
+	return simulation.WeightedOperations(simState.AppParams, simState.Cdc, simState.TxConfig, am.accountKeeper, am.keeper)
+}
@@ -0,0 +1,173 @@
+// Package notetree is the incremental Merkle tree of shielded note
+// commitments. Leaves are appended left to right and never removed; a root
+// the tree had, an anchor, stays valid for proving a note was committed, as a
+// spend proof shows a path from its note to an anchor without revealing the
+// note.
+//
+// Nodes are Poseidon hashes over the BN254 scalar field so the circuits can
+// check paths cheaply. A commitment is a leaf after reducing it into the field.
+// Every node on a filled path is stored, so the witness of any leaf against the
+// current root is read off directly.
+package notetree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"shared/poseidon"
+)
+
+// Depth is the number of levels below the root; the tree holds 2^32 leaves
+const Depth = 32
+
+var (
+	ErrFull           = errors.New("note commitment tree is full")
+	ErrInvalidWitness = errors.New("invalid note commitment witness")
+)
+
+// Store persists tree nodes. A prefix store of the module satisfies it.
+type Store interface {
+	Get(key []byte) []byte
+	Set(key, value []byte)
+}
+
+// empty[l] is the root of an empty subtree whose leaves are l levels down
+var empty = func() [Depth + 1][]byte {
+	var e [Depth + 1][]byte
+	e[0] = make([]byte, poseidon.Size)
+	for l := 1; l <= Depth; l++ {
+		e[l] = hash(e[l-1], e[l-1])
+	}
+	return e
+}()
+
+// EmptyRoot is the root of the tree without leaves
+func EmptyRoot() []byte {
+	return append([]byte(nil), empty[Depth]...)
+}
+
+// Leaf is the leaf of a note commitment
+func Leaf(commitment []byte) []byte {
+	return poseidon.Reduce(commitment)
+}
+
+func hash(left, right []byte) []byte {
+	h, err := poseidon.HashBytes(left, right)
+	if err != nil {
+		// Nodes are field elements by construction
+		panic(err)
+	}
+	return h
+}
+
+var sizeKey = []byte("size")
+
+// Tree is the note commitment tree over a store
+type Tree struct {
+	store Store
+}
+
+// New opens the tree kept in store
+func New(store Store) *Tree {
+	return &Tree{store: store}
+}
+
+// Size is the number of leaves appended
+func (t *Tree) Size() uint64 {
+	bz := t.store.Get(sizeKey)
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+// Root is the current anchor
+func (t *Tree) Root() []byte {
+	return t.node(Depth, 0)
+}
+
+// Append adds the leaf of commitment and returns its position
+func (t *Tree) Append(commitment []byte) (uint64, error) {
+	position := t.Size()
+	if position >= 1<<Depth {
+		return 0, ErrFull
+	}
+
+	node := Leaf(commitment)
+	t.setNode(0, position, node)
+	index := position
+	for l := 0; l < Depth; l++ {
+		if index&1 == 0 {
+			node = hash(node, t.node(l, index+1))
+		} else {
+			node = hash(t.node(l, index-1), node)
+		}
+		index >>= 1
+		t.setNode(l+1, index, node)
+	}
+
+	t.store.Set(sizeKey, binary.BigEndian.AppendUint64(nil, position+1))
+	return position, nil
+}
+
+// Witness returns the siblings on the path from the leaf at position to the
+// current root, from the leaf up
+func (t *Tree) Witness(position uint64) ([][]byte, error) {
+	if position >= t.Size() {
+		return nil, fmt.Errorf("no leaf at position %d", position)
+	}
+
+	siblings := make([][]byte, Depth)
+	index := position
+	for l := 0; l < Depth; l++ {
+		siblings[l] = t.node(l, index^1)
+		index >>= 1
+	}
+	return siblings, nil
+}
+
+// VerifyWitness checks that commitment is the leaf at position under anchor
+func VerifyWitness(anchor, commitment []byte, position uint64, siblings [][]byte) error {
+	if len(siblings) != Depth {
+		return fmt.Errorf("%w: %d siblings", ErrInvalidWitness, len(siblings))
+	}
+
+	node := Leaf(commitment)
+	index := position
+	for _, sibling := range siblings {
+		var err error
+		if index&1 == 0 {
+			node, err = poseidon.HashBytes(node, sibling)
+		} else {
+			node, err = poseidon.HashBytes(sibling, node)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidWitness, err)
+		}
+		index >>= 1
+	}
+
+	if !bytes.Equal(node, anchor) {
+		return fmt.Errorf("%w: anchor mismatch", ErrInvalidWitness)
+	}
+	return nil
+}
+
+// node returns the node at level l and index, empty when not yet filled
+func (t *Tree) node(l int, index uint64) []byte {
+	if bz := t.store.Get(nodeKey(l, index)); bz != nil {
+		return bz
+	}
+	return empty[l]
+}
+
+func (t *Tree) setNode(l int, index uint64, hash []byte) {
+	t.store.Set(nodeKey(l, index), hash)
+}
+
+// nodeKey is the level followed by the index, big endian
+func nodeKey(l int, index uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{byte(l)}, index)
+}
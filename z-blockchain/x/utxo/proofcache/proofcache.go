@@ -0,0 +1,82 @@
+// Package proofcache remembers the results of verifying proofs, so a proof
+// checked when it enters the mempool, or in a proposal, is not verified again
+// when its block is delivered. A proof is keyed by the hash of everything its
+// verification reads, the statement it proves included, so its result holds
+// for good: a valid proof stays valid and an invalid one invalid.
+package proofcache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Result is the outcome of verifying a proof: the hash it commits to, if the
+// kind of proof has one, or the error it failed with
+type Result struct {
+	Hash []byte
+	Err  error
+}
+
+// Cache holds the results of the proofs last verified, up to its capacity,
+// evicting the least recently used. It is safe for concurrent use.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Of *entry, most recently used first
+	entries  map[[32]byte]*list.Element
+}
+
+type entry struct {
+	key    [32]byte
+	result Result
+}
+
+// New returns an empty cache of capacity results
+func New(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[[32]byte]*list.Element, capacity),
+	}
+}
+
+// Get returns the result of the proof hashing to key, if it is held
+func (c *Cache) Get(key [32]byte) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return Result{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*entry).result, true
+}
+
+// Add records the result of the proof hashing to key
+func (c *Cache) Add(key [32]byte, result Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*entry).result = result
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.capacity <= 0 {
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, result: result})
+}
+
+// Len is the number of results held
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,172 @@
+package utxo
+
+import (
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+	"z-blockchain/x/utxo/zkproof"
+)
+
+// The mining proof of a block is part of its validity: a proposal carries at
+// most one, which every validator verifies in ProcessProposal, so a block
+// never claims a reward for an invalid solution or for two. Its delivery then
+// records the proof and EndBlocker pays it. Proofs for the recent heights
+// before the block are its uncles, at most MaxUnclesPerBlock, each verified
+// alike.
+
+// NewPrepareProposalHandler keeps the best valid mining proof among the
+// transactions next selects, and the first valid uncles, and drops the
+// others, which could not be delivered with them. The app sets it with
+// SetPrepareProposal.
+func NewPrepareProposalHandler(k keeper.Keeper, txDecoder sdk.TxDecoder, next sdk.PrepareProposalHandler) sdk.PrepareProposalHandler {
+	return func(ctx sdk.Context, req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+		defer trackPhase(ctx, "prepare_proposal")()
+
+		resp := next(ctx, req)
+
+		winner := -1
+		var best types.BlockProof
+		uncles := make(map[int]bool)
+		uncleHashes := make(map[string]bool)
+		claims := make([]int, len(resp.Txs))
+		for i, bz := range resp.Txs {
+			proofs := miningProofs(txDecoder, bz)
+			claims[i] = len(proofs)
+			if len(proofs) != 1 {
+				continue
+			}
+			uncle := proofs[0].Height < req.Height
+			if uncle && len(uncles) >= types.MaxUnclesPerBlock {
+				continue
+			}
+			hash, err := k.CheckMiningProof(ctx, proofs[0])
+			if err != nil {
+				ctx.Logger().Debug("Dropped invalid mining proof from proposal", "height", req.Height, "miner", proofs[0].MinerAddress, "error", err)
+				continue
+			}
+			if uncle {
+				if !uncleHashes[string(hash)] {
+					uncles[i], uncleHashes[string(hash)] = true, true
+				}
+				continue
+			}
+			candidate := types.BlockProof{MinerAddress: proofs[0].MinerAddress, HardwareId: proofs[0].HardwareId, SolutionHash: hash, Height: proofs[0].Height}
+			if winner < 0 || candidate.Better(best) {
+				winner, best = i, candidate
+			}
+		}
+
+		txs := make([][]byte, 0, len(resp.Txs))
+		for i, bz := range resp.Txs {
+			if i == winner || uncles[i] || claims[i] == 0 {
+				txs = append(txs, bz)
+			}
+		}
+		resp.Txs = txs
+		return resp
+	}
+}
+
+// NewProcessProposalHandler rejects a proposal with more than one mining
+// proof or more than MaxUnclesPerBlock uncles, or an invalid or repeated
+// one, then batch verifies the shielded proofs of the block before next
+// handles the proposal. Both run on the keeper's verifier pool, a worker per
+// core, the cheap mining proofs first so an invalid one rejects the proposal
+// before the batch is paid for. The shielded proofs found valid pass at once
+// when their transactions are delivered; an invalid one is left for its
+// transaction to fail on, as it would without the batch. The app sets it in
+// front of its own handler with SetProcessProposal.
+func NewProcessProposalHandler(k keeper.Keeper, txDecoder sdk.TxDecoder, next sdk.ProcessProposalHandler) sdk.ProcessProposalHandler {
+	return func(ctx sdk.Context, req abci.RequestProcessProposal) abci.ResponseProcessProposal {
+		defer trackPhase(ctx, "process_proposal")()
+
+		var proofs []types.MiningProof
+		var batch zkproof.Batch
+		for _, bz := range req.Txs {
+			tx, err := txDecoder(bz)
+			if err != nil {
+				continue
+			}
+			for _, msg := range tx.GetMsgs() {
+				if proof, in, ok := types.ShieldedStatement(msg); ok {
+					batch.Add(proof, in)
+				}
+				if msg, ok := msg.(types.MiningProofMsg); ok {
+					proofs = append(proofs, msg.MiningProof())
+				}
+			}
+		}
+
+		var blockProofs, uncles int
+		for _, proof := range proofs {
+			if proof.Height < req.Height {
+				uncles++
+			} else {
+				blockProofs++
+			}
+		}
+		switch {
+		case blockProofs > 1:
+			ctx.Logger().Info("Rejected proposal with several mining proofs", "height", req.Height, "proofs", blockProofs)
+			return abci.ResponseProcessProposal{Status: abci.ResponseProcessProposal_REJECT}
+		case uncles > types.MaxUnclesPerBlock:
+			ctx.Logger().Info("Rejected proposal with too many uncles", "height", req.Height, "uncles", uncles)
+			return abci.ResponseProcessProposal{Status: abci.ResponseProcessProposal_REJECT}
+		}
+		// Solutions are verified concurrently, and their results taken in
+		// the order of the proposal: the first invalid or repeated proof
+		// rejects it, as on every validator
+		hashes, errs := k.CheckMiningProofs(ctx, proofs)
+		solutions := make(map[string]bool, len(proofs))
+		for i, proof := range proofs {
+			if errs[i] != nil {
+				ctx.Logger().Info("Rejected proposal with an invalid mining proof", "height", req.Height, "miner", proof.MinerAddress, "error", errs[i])
+				return abci.ResponseProcessProposal{Status: abci.ResponseProcessProposal_REJECT}
+			}
+			if solutions[string(hashes[i])] {
+				ctx.Logger().Info("Rejected proposal with a repeated mining proof", "height", req.Height, "miner", proof.MinerAddress)
+				return abci.ResponseProcessProposal{Status: abci.ResponseProcessProposal_REJECT}
+			}
+			solutions[string(hashes[i])] = true
+		}
+
+		if batch.Len() > 0 {
+			start := time.Now()
+			invalid := 0
+			for _, err := range batch.Verify(k.Verifiers()) {
+				if err != nil {
+					invalid++
+				}
+			}
+			ctx.Logger().Debug("Batch verified shielded proofs",
+				"height", req.Height,
+				"proofs", batch.Len(),
+				"invalid", invalid,
+				"workers", k.Verifiers().Workers(),
+				"elapsed", time.Since(start))
+			keeper.MeasureProofVerification(keeper.ProofKindShieldedBatch, start)
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// miningProofs returns the mining proofs a transaction submits; none for one
+// that does not decode, which cannot be delivered
+func miningProofs(txDecoder sdk.TxDecoder, bz []byte) []types.MiningProof {
+	tx, err := txDecoder(bz)
+	if err != nil {
+		return nil
+	}
+	var proofs []types.MiningProof
+	for _, msg := range tx.GetMsgs() {
+		if msg, ok := msg.(types.MiningProofMsg); ok {
+			proofs = append(proofs, msg.MiningProof())
+		}
+	}
+	return proofs
+}
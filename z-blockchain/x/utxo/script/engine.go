@@ -0,0 +1,737 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Lock times below LockTimeThreshold are block heights, above it Unix times
+const LockTimeThreshold = 500000000
+
+// Fields of an input sequence and an OP_CHECKSEQUENCEVERIFY operand, as in
+// BIP 68. Unlike Bitcoin a zero sequence is no lock, not a lock of 0 blocks,
+// which comes to the same.
+const (
+	SequenceLockTimeDisabled    = 1 << 31 // No relative lock; for an operand, a NOP
+	SequenceLockTimeIsSeconds   = 1 << 22 // The value counts 512 second units, not blocks
+	SequenceLockTimeMask        = 0x0000ffff
+	SequenceLockTimeGranularity = 9
+)
+
+// Context is what the scripts of an input learn about the spend
+type Context struct {
+	Tx         *Tx
+	InputIndex int
+	Amount     string // Of the output being spent
+
+	Height int64 // Of the block including the transaction
+	Time   int64 // Unix seconds, of that block
+}
+
+// LockTimeReached reports whether a transaction lock time is in effect at
+// the given block. Zero means no lock.
+func LockTimeReached(lockTime uint64, height, time int64) bool {
+	if lockTime == 0 {
+		return true
+	}
+	if lockTime < LockTimeThreshold {
+		return int64(lockTime) <= height
+	}
+	return int64(lockTime) <= time
+}
+
+// SequenceLockReached reports whether the relative lock time of an input
+// with sequence is in effect at the given block, for a spent output created
+// at outputHeight and outputTime
+func SequenceLockReached(sequence uint32, height, time, outputHeight, outputTime int64) bool {
+	if sequence&SequenceLockTimeDisabled != 0 {
+		return true
+	}
+	value := int64(sequence & SequenceLockTimeMask)
+	if sequence&SequenceLockTimeIsSeconds != 0 {
+		return time-outputTime >= value<<SequenceLockTimeGranularity
+	}
+	return height-outputHeight >= value
+}
+
+// Verify runs scriptSig and then scriptPubkey, and for pay to script hash
+// outputs the redeem script scriptSig pushed last. ctx must be set.
+func Verify(scriptSig, scriptPubkey []byte, ctx *Context) error {
+	if !IsPushOnly(scriptSig) {
+		return ErrSigPushOnly
+	}
+
+	vm := &engine{ctx: ctx}
+	if err := vm.execute(scriptSig); err != nil {
+		return fmt.Errorf("scriptSig: %w", err)
+	}
+
+	var p2shStack [][]byte
+	p2sh := isP2SH(scriptPubkey)
+	if p2sh {
+		p2shStack = append(p2shStack, vm.stack...)
+	}
+
+	if err := vm.execute(scriptPubkey); err != nil {
+		return fmt.Errorf("scriptPubkey: %w", err)
+	}
+	if err := vm.checkTrue(); err != nil {
+		return err
+	}
+
+	if p2sh {
+		redeem := p2shStack[len(p2shStack)-1]
+		vm.stack = p2shStack[:len(p2shStack)-1]
+		if err := vm.execute(redeem); err != nil {
+			return fmt.Errorf("redeem script: %w", err)
+		}
+		if err := vm.checkTrue(); err != nil {
+			return err
+		}
+	}
+
+	if len(vm.stack) != 1 {
+		return fmt.Errorf("%w: %d elements left", ErrCleanStack, len(vm.stack))
+	}
+	return nil
+}
+
+// engine evaluates scripts against one input. The main stack carries over
+// from one script to the next; everything else is reset.
+type engine struct {
+	ctx *Context
+
+	stack [][]byte
+	alt   [][]byte
+	cond  []bool // One entry per open OP_IF: whether its branch executes
+
+	script  []byte
+	codeSep int // Offset after the last OP_CODESEPARATOR
+	opCount int
+}
+
+func (vm *engine) execute(script []byte) error {
+	if len(script) > MaxScriptSize {
+		return fmt.Errorf("%w: %d bytes", ErrScriptSize, len(script))
+	}
+	ops, err := parse(script)
+	if err != nil {
+		return err
+	}
+
+	vm.script = script
+	vm.codeSep = 0
+	vm.opCount = 0
+	vm.alt = nil
+	vm.cond = nil
+
+	for _, op := range ops {
+		if err := vm.step(op); err != nil {
+			return fmt.Errorf("opcode 0x%02x at offset %d: %w", op.op, op.offset, err)
+		}
+	}
+	if len(vm.cond) != 0 {
+		return ErrUnbalancedIf
+	}
+	return nil
+}
+
+func (vm *engine) checkTrue() error {
+	if len(vm.stack) == 0 || !asBool(vm.stack[len(vm.stack)-1]) {
+		return ErrEvalFalse
+	}
+	return nil
+}
+
+func (vm *engine) executing() bool {
+	for _, branch := range vm.cond {
+		if !branch {
+			return false
+		}
+	}
+	return true
+}
+
+func (vm *engine) step(op parsedOp) error {
+	if len(op.data) > MaxElementSize {
+		return fmt.Errorf("%w: %d bytes", ErrElementSize, len(op.data))
+	}
+	if op.op > OP_16 {
+		vm.opCount++
+		if vm.opCount > MaxOpsPerScript {
+			return ErrOpCount
+		}
+	}
+	if disabled(op.op) {
+		return ErrDisabledOpcode
+	}
+	if !vm.executing() && (op.op < OP_IF || op.op > OP_ENDIF) {
+		return nil
+	}
+
+	if op.push() {
+		if !op.minimalPush() {
+			return ErrMinimalData
+		}
+		if op.op == OP_1NEGATE {
+			vm.push(scriptNum(-1).Bytes())
+		} else if n, ok := smallInt(op.op); ok {
+			vm.push(scriptNum(n).Bytes())
+		} else {
+			vm.push(append([]byte(nil), op.data...))
+		}
+	} else if err := vm.exec(op); err != nil {
+		return err
+	}
+
+	if len(vm.stack)+len(vm.alt) > MaxStackSize {
+		return ErrStackSize
+	}
+	return nil
+}
+
+func (vm *engine) exec(op parsedOp) error {
+	switch op.op {
+	// Flow control
+	case OP_NOP, OP_NOP1, OP_NOP4, OP_NOP5, OP_NOP6, OP_NOP7, OP_NOP8, OP_NOP9, OP_NOP10:
+		return nil
+	case OP_RESERVED, OP_VER, OP_RESERVED1, OP_RESERVED2:
+		return ErrReservedOpcode
+	case OP_IF, OP_NOTIF:
+		branch := false
+		if vm.executing() {
+			v, err := vm.pop()
+			if err != nil {
+				return err
+			}
+			if len(v) > 1 || (len(v) == 1 && v[0] != 1) {
+				return ErrMinimalIf
+			}
+			branch = asBool(v) == (op.op == OP_IF)
+		}
+		vm.cond = append(vm.cond, branch)
+		return nil
+	case OP_ELSE:
+		if len(vm.cond) == 0 {
+			return ErrUnbalancedIf
+		}
+		vm.cond[len(vm.cond)-1] = !vm.cond[len(vm.cond)-1]
+		return nil
+	case OP_ENDIF:
+		if len(vm.cond) == 0 {
+			return ErrUnbalancedIf
+		}
+		vm.cond = vm.cond[:len(vm.cond)-1]
+		return nil
+	case OP_VERIFY:
+		return vm.verify()
+	case OP_RETURN:
+		return ErrEarlyReturn
+
+	// Stack
+	case OP_TOALTSTACK:
+		v, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		vm.alt = append(vm.alt, v)
+	case OP_FROMALTSTACK:
+		if len(vm.alt) == 0 {
+			return ErrStackUnderflow
+		}
+		vm.push(vm.alt[len(vm.alt)-1])
+		vm.alt = vm.alt[:len(vm.alt)-1]
+	case OP_2DROP:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		vm.stack = vm.stack[:len(vm.stack)-2]
+	case OP_2DUP:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		vm.push(vm.peek(1), vm.peek(0))
+	case OP_3DUP:
+		if err := vm.need(3); err != nil {
+			return err
+		}
+		vm.push(vm.peek(2), vm.peek(1), vm.peek(0))
+	case OP_2OVER:
+		if err := vm.need(4); err != nil {
+			return err
+		}
+		vm.push(vm.peek(3), vm.peek(2))
+	case OP_2ROT:
+		if err := vm.need(6); err != nil {
+			return err
+		}
+		a, b := vm.remove(5), vm.remove(4)
+		vm.push(a, b)
+	case OP_2SWAP:
+		if err := vm.need(4); err != nil {
+			return err
+		}
+		a, b := vm.remove(3), vm.remove(2)
+		vm.push(a, b)
+	case OP_IFDUP:
+		if err := vm.need(1); err != nil {
+			return err
+		}
+		if asBool(vm.peek(0)) {
+			vm.push(vm.peek(0))
+		}
+	case OP_DEPTH:
+		vm.push(scriptNum(len(vm.stack)).Bytes())
+	case OP_DROP:
+		_, err := vm.pop()
+		return err
+	case OP_DUP:
+		if err := vm.need(1); err != nil {
+			return err
+		}
+		vm.push(vm.peek(0))
+	case OP_NIP:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		vm.remove(1)
+	case OP_OVER:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		vm.push(vm.peek(1))
+	case OP_PICK, OP_ROLL:
+		n, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		if n < 0 || int64(n) >= int64(len(vm.stack)) {
+			return ErrStackUnderflow
+		}
+		if op.op == OP_PICK {
+			vm.push(vm.peek(int(n)))
+		} else {
+			vm.push(vm.remove(int(n)))
+		}
+	case OP_ROT:
+		if err := vm.need(3); err != nil {
+			return err
+		}
+		vm.push(vm.remove(2))
+	case OP_SWAP:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		vm.push(vm.remove(1))
+	case OP_TUCK:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		a, b := vm.remove(1), vm.remove(0)
+		vm.push(b, a, b)
+
+	// Splice and bitwise logic
+	case OP_SIZE:
+		if err := vm.need(1); err != nil {
+			return err
+		}
+		vm.push(scriptNum(len(vm.peek(0))).Bytes())
+	case OP_EQUAL, OP_EQUALVERIFY:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		b, _ := vm.pop()
+		a, _ := vm.pop()
+		vm.push(fromBool(bytes.Equal(a, b)))
+		if op.op == OP_EQUALVERIFY {
+			return vm.verify()
+		}
+
+	// Arithmetic
+	case OP_1ADD, OP_1SUB, OP_NEGATE, OP_ABS, OP_NOT, OP_0NOTEQUAL:
+		n, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		switch op.op {
+		case OP_1ADD:
+			n++
+		case OP_1SUB:
+			n--
+		case OP_NEGATE:
+			n = -n
+		case OP_ABS:
+			if n < 0 {
+				n = -n
+			}
+		case OP_NOT:
+			vm.push(fromBool(n == 0))
+			return nil
+		case OP_0NOTEQUAL:
+			vm.push(fromBool(n != 0))
+			return nil
+		}
+		vm.push(n.Bytes())
+	case OP_ADD, OP_SUB, OP_BOOLAND, OP_BOOLOR, OP_NUMEQUAL, OP_NUMEQUALVERIFY, OP_NUMNOTEQUAL,
+		OP_LESSTHAN, OP_GREATERTHAN, OP_LESSTHANOREQUAL, OP_GREATERTHANOREQUAL, OP_MIN, OP_MAX:
+		b, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		a, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		var result []byte
+		switch op.op {
+		case OP_ADD:
+			result = (a + b).Bytes()
+		case OP_SUB:
+			result = (a - b).Bytes()
+		case OP_BOOLAND:
+			result = fromBool(a != 0 && b != 0)
+		case OP_BOOLOR:
+			result = fromBool(a != 0 || b != 0)
+		case OP_NUMEQUAL, OP_NUMEQUALVERIFY:
+			result = fromBool(a == b)
+		case OP_NUMNOTEQUAL:
+			result = fromBool(a != b)
+		case OP_LESSTHAN:
+			result = fromBool(a < b)
+		case OP_GREATERTHAN:
+			result = fromBool(a > b)
+		case OP_LESSTHANOREQUAL:
+			result = fromBool(a <= b)
+		case OP_GREATERTHANOREQUAL:
+			result = fromBool(a >= b)
+		case OP_MIN:
+			result = minNum(a, b).Bytes()
+		case OP_MAX:
+			result = maxNum(a, b).Bytes()
+		}
+		vm.push(result)
+		if op.op == OP_NUMEQUALVERIFY {
+			return vm.verify()
+		}
+	case OP_WITHIN:
+		upper, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		lower, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		x, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		vm.push(fromBool(lower <= x && x < upper))
+
+	// Crypto
+	case OP_RIPEMD160, OP_SHA1, OP_SHA256, OP_HASH160, OP_HASH256:
+		v, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		vm.push(hashOp(op.op, v))
+	case OP_CODESEPARATOR:
+		vm.codeSep = op.offset + 1
+	case OP_CHECKSIG, OP_CHECKSIGVERIFY:
+		if err := vm.need(2); err != nil {
+			return err
+		}
+		pubKey, _ := vm.pop()
+		sig, _ := vm.pop()
+		ok, err := vm.checkSig(sig, pubKey)
+		if err != nil {
+			return err
+		}
+		if !ok && len(sig) != 0 {
+			return ErrNullFail
+		}
+		vm.push(fromBool(ok))
+		if op.op == OP_CHECKSIGVERIFY {
+			return vm.verify()
+		}
+	case OP_CHECKMULTISIG, OP_CHECKMULTISIGVERIFY:
+		if err := vm.checkMultiSig(); err != nil {
+			return err
+		}
+		if op.op == OP_CHECKMULTISIGVERIFY {
+			return vm.verify()
+		}
+
+	// Timelocks
+	case OP_CHECKLOCKTIMEVERIFY:
+		return vm.checkLockTime()
+	case OP_CHECKSEQUENCEVERIFY:
+		return vm.checkSequence()
+
+	default:
+		return fmt.Errorf("%w: 0x%02x", ErrReservedOpcode, op.op)
+	}
+	return nil
+}
+
+func (vm *engine) push(values ...[]byte) {
+	vm.stack = append(vm.stack, values...)
+}
+
+func (vm *engine) need(n int) error {
+	if len(vm.stack) < n {
+		return ErrStackUnderflow
+	}
+	return nil
+}
+
+// peek returns the element i below the top
+func (vm *engine) peek(i int) []byte {
+	return vm.stack[len(vm.stack)-1-i]
+}
+
+// remove takes out the element i below the top
+func (vm *engine) remove(i int) []byte {
+	at := len(vm.stack) - 1 - i
+	v := vm.stack[at]
+	vm.stack = append(vm.stack[:at], vm.stack[at+1:]...)
+	return v
+}
+
+func (vm *engine) pop() ([]byte, error) {
+	if err := vm.need(1); err != nil {
+		return nil, err
+	}
+	return vm.remove(0), nil
+}
+
+func (vm *engine) popNum() (scriptNum, error) {
+	v, err := vm.pop()
+	if err != nil {
+		return 0, err
+	}
+	return makeScriptNum(v, maxNumSize)
+}
+
+func (vm *engine) verify() error {
+	v, err := vm.pop()
+	if err != nil {
+		return err
+	}
+	if !asBool(v) {
+		return ErrVerify
+	}
+	return nil
+}
+
+// checkSig verifies a 64 byte r || s signature followed by its hash type. An
+// empty signature is a failed check rather than an error.
+func (vm *engine) checkSig(sig, pubKey []byte) (bool, error) {
+	if len(sig) == 0 {
+		return false, nil
+	}
+	if len(sig) != 65 {
+		return false, fmt.Errorf("%w: %d bytes", ErrSigEncoding, len(sig))
+	}
+	hashType := SigHashType(sig[64])
+	if !hashType.Valid() {
+		return false, fmt.Errorf("%w: 0x%02x", ErrSigHashType, sig[64])
+	}
+	if !validPubKey(pubKey) {
+		return false, ErrPubKeyEncoding
+	}
+	if vm.ctx == nil || vm.ctx.Tx == nil {
+		return false, fmt.Errorf("signature check without a transaction")
+	}
+
+	hash, err := SigHash(vm.ctx.Tx, vm.ctx.InputIndex, vm.script[vm.codeSep:], vm.ctx.Amount, hashType)
+	if err != nil {
+		return false, err
+	}
+	// VerifySignature rejects high-S signatures, so they cannot be malleated
+	return crypto.VerifySignature(pubKey, hash, sig[:64]), nil
+}
+
+// checkMultiSig consumes <dummy> <sig>... <m> <pubkey>... <n>. Signatures
+// must come in the order of their keys.
+func (vm *engine) checkMultiSig() error {
+	n, err := vm.popNum()
+	if err != nil {
+		return err
+	}
+	if n < 0 || n > MaxPubKeysPerMultiSig {
+		return fmt.Errorf("%w: %d", ErrPubKeyCount, n)
+	}
+	vm.opCount += int(n)
+	if vm.opCount > MaxOpsPerScript {
+		return ErrOpCount
+	}
+	pubKeys, err := vm.popN(int(n))
+	if err != nil {
+		return err
+	}
+
+	m, err := vm.popNum()
+	if err != nil {
+		return err
+	}
+	if m < 0 || m > n {
+		return fmt.Errorf("%w: %d of %d", ErrSigCount, m, n)
+	}
+	sigs, err := vm.popN(int(m))
+	if err != nil {
+		return err
+	}
+
+	dummy, err := vm.pop()
+	if err != nil {
+		return err
+	}
+	if len(dummy) != 0 {
+		return ErrNullDummy
+	}
+
+	ok := true
+	for isig, ikey := 0, 0; isig < len(sigs); ikey++ {
+		if len(sigs)-isig > len(pubKeys)-ikey {
+			ok = false
+			break
+		}
+		valid, err := vm.checkSig(sigs[isig], pubKeys[ikey])
+		if err != nil {
+			return err
+		}
+		if valid {
+			isig++
+		}
+	}
+
+	if !ok {
+		for _, sig := range sigs {
+			if len(sig) != 0 {
+				return ErrNullFail
+			}
+		}
+	}
+	vm.push(fromBool(ok))
+	return nil
+}
+
+// popN pops n elements, returning them in the order they were pushed
+func (vm *engine) popN(n int) ([][]byte, error) {
+	if err := vm.need(n); err != nil {
+		return nil, err
+	}
+	values := append([][]byte(nil), vm.stack[len(vm.stack)-n:]...)
+	vm.stack = vm.stack[:len(vm.stack)-n]
+	return values, nil
+}
+
+// checkLockTime fails unless the transaction is locked until at least the
+// height or time on the stack. The keeper does not accept a transaction
+// before its lock time, so the output stays unspendable until then.
+func (vm *engine) checkLockTime() error {
+	if err := vm.need(1); err != nil {
+		return err
+	}
+	lockTime, err := makeScriptNum(vm.peek(0), maxLockTimeSize)
+	if err != nil {
+		return err
+	}
+	if lockTime < 0 {
+		return ErrNegativeLockTime
+	}
+
+	txLockTime := vm.ctx.Tx.LockTime
+	if (lockTime < LockTimeThreshold) != (txLockTime < LockTimeThreshold) {
+		return fmt.Errorf("%w: lock time %d and transaction lock time %d differ in kind", ErrUnsatisfiedLockTime, lockTime, txLockTime)
+	}
+	if uint64(lockTime) > txLockTime {
+		return fmt.Errorf("%w: locked until %d, transaction lock time %d", ErrUnsatisfiedLockTime, lockTime, txLockTime)
+	}
+	return nil
+}
+
+// checkSequence fails unless the input is relatively locked for at least the
+// blocks or 512 second units on the stack. The keeper does not accept an
+// input before its relative lock time, so the output stays unspendable until
+// it has aged that much.
+func (vm *engine) checkSequence() error {
+	if err := vm.need(1); err != nil {
+		return err
+	}
+	sequence, err := makeScriptNum(vm.peek(0), maxLockTimeSize)
+	if err != nil {
+		return err
+	}
+	if sequence < 0 {
+		return ErrNegativeLockTime
+	}
+	if sequence&SequenceLockTimeDisabled != 0 {
+		return nil
+	}
+
+	txSequence := int64(vm.ctx.Tx.Inputs[vm.ctx.InputIndex].Sequence)
+	if txSequence&SequenceLockTimeDisabled != 0 {
+		return fmt.Errorf("%w: input has no relative lock time", ErrUnsatisfiedLockTime)
+	}
+	if sequence&SequenceLockTimeIsSeconds != scriptNum(txSequence&SequenceLockTimeIsSeconds) {
+		return fmt.Errorf("%w: relative lock time %d and input sequence %d differ in kind", ErrUnsatisfiedLockTime, sequence, txSequence)
+	}
+	if value := int64(sequence & SequenceLockTimeMask); value > txSequence&SequenceLockTimeMask {
+		return fmt.Errorf("%w: locked for %d, input sequence %d", ErrUnsatisfiedLockTime, value, txSequence&SequenceLockTimeMask)
+	}
+	return nil
+}
+
+func hashOp(op byte, v []byte) []byte {
+	switch op {
+	case OP_RIPEMD160:
+		h := ripemd160.New()
+		h.Write(v)
+		return h.Sum(nil)
+	case OP_SHA1:
+		sum := sha1.Sum(v)
+		return sum[:]
+	case OP_SHA256:
+		sum := sha256.Sum256(v)
+		return sum[:]
+	case OP_HASH160:
+		return Hash160(v)
+	default:
+		first := sha256.Sum256(v)
+		second := sha256.Sum256(first[:])
+		return second[:]
+	}
+}
+
+// Hash160 is RIPEMD160(SHA256(v)), the hash of keys and redeem scripts
+func Hash160(v []byte) []byte {
+	sum := sha256.Sum256(v)
+	h := ripemd160.New()
+	h.Write(sum[:])
+	return h.Sum(nil)
+}
+
+func validPubKey(pubKey []byte) bool {
+	return len(pubKey) == 33 && (pubKey[0] == 0x02 || pubKey[0] == 0x03)
+}
+
+func minNum(a, b scriptNum) scriptNum {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxNum(a, b scriptNum) scriptNum {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,493 @@
+package script
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"shared/address"
+	"shared/sighash"
+)
+
+// vector is a reference evaluation. Err is nil for a spend that must verify,
+// otherwise the error it must fail with.
+type vector struct {
+	Name         string
+	ScriptSig    []byte
+	ScriptPubkey []byte
+	Err          error
+}
+
+// vectorContext is the spend every vector is evaluated against: lock time
+// 600 and an input relatively locked for 600 blocks, mined at height 700
+func vectorContext() *Context {
+	return &Context{
+		Tx: &Tx{
+			ChainID: "z-blockchain-1",
+			Inputs:  []Input{{TxHash: strings.Repeat("ab", 32), Index: 1, Sequence: 600}},
+			Outputs: []Output{{
+				Amount:       "1000",
+				Address:      "z1w508d6qejxtdg4y5r3zarvary0c5xw7keklfzv",
+				ScriptPubkey: mustHex("76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"),
+			}},
+			Fee:      "10",
+			LockTime: 600,
+		},
+		InputIndex: 0,
+		Amount:     "1010",
+		Height:     700,
+		Time:       1700000000,
+	}
+}
+
+// vectors covers every enabled opcode, the standard templates and the
+// consensus rules beyond Bitcoin's. Signatures are made with the secp256k1
+// keys 1, 2 and 3.
+func vectors() []vector {
+	key1, key2, key3 := vectorKey(1), vectorKey(2), vectorKey(3)
+	pub1, pub2, pub3 := compressed(key1), compressed(key2), compressed(key3)
+
+	p2pkh := mustScript(PayToPubKeyHash(mustHex("751e76e8199196d454941c45d1b3a323f1433bd6")))
+	multisig := mustScript(MultiSigScript(2, [][]byte{pub1, pub2, pub3}))
+	codeSep := build(OP_DUP, OP_DROP, OP_CODESEPARATOR, pub1, OP_CHECKSIG)
+	cltv := build(600, OP_CHECKLOCKTIMEVERIFY, OP_DROP, pub1, OP_CHECKSIG)
+	cltvLate := build(601, OP_CHECKLOCKTIMEVERIFY, OP_DROP, pub1, OP_CHECKSIG)
+
+	all := SigHashAll
+	badType := sign(key1, p2pkh, all)
+	badType[64] = byte(SigHashNone)
+
+	return []vector{
+		// Pay to public key hash
+		{"p2pkh", build(sign(key1, p2pkh, all), pub1), p2pkh, nil},
+		{"p2pkh sighash single anyonecanpay", build(sign(key1, p2pkh, SigHashSingle|SigHashAnyoneCanPay), pub1), p2pkh, nil},
+		{"p2pkh sighash none", build(sign(key1, p2pkh, SigHashNone), pub1), p2pkh, nil},
+		{"p2pkh replayed from another chain", build(signAltered(func(ctx *Context) { ctx.Tx.ChainID = "nuchain-1" }, key1, p2pkh, all), pub1), p2pkh, ErrNullFail},
+		{"p2pkh output amount changed", build(signAltered(func(ctx *Context) { ctx.Tx.Outputs[0].Amount = "1001" }, key1, p2pkh, all), pub1), p2pkh, ErrNullFail},
+		{"p2pkh output script changed", build(signAltered(func(ctx *Context) { ctx.Tx.Outputs[0].ScriptPubkey = nil }, key1, p2pkh, all), pub1), p2pkh, ErrNullFail},
+		{"p2pkh sequence changed", build(signAltered(func(ctx *Context) { ctx.Tx.Inputs[0].Sequence = 0 }, key1, p2pkh, all), pub1), p2pkh, ErrNullFail},
+		{"p2pkh spent amount changed", build(signAltered(func(ctx *Context) { ctx.Amount = "1" }, key1, p2pkh, all), pub1), p2pkh, ErrNullFail},
+		{"p2pkh outputs not signed", build(signAltered(func(ctx *Context) { ctx.Tx.Outputs = nil }, key1, p2pkh, SigHashNone), pub1), p2pkh, nil},
+		{"p2pkh address script", build(sign(key1, p2pkh, all), pub1), sighash.AddressScript(mustAddress("751e76e8199196d454941c45d1b3a323f1433bd6")), nil},
+		{"p2pkh other key", build(sign(key2, p2pkh, all), pub2), p2pkh, ErrVerify},
+		{"p2pkh signature for another hash type", build(badType, pub1), p2pkh, ErrNullFail},
+		{"p2pkh undefined hash type", build(append(sign(key1, p2pkh, all)[:64], 0x04), pub1), p2pkh, ErrSigHashType},
+		{"p2pkh truncated signature", build(sign(key1, p2pkh, all)[1:], pub1), p2pkh, ErrSigEncoding},
+		{"empty signature fails without error", build(OP_0, pub1), build(OP_CHECKSIG, OP_NOT), nil},
+		{"uncompressed public key", build(OP_0, append([]byte{0x04}, make([]byte, 64)...)), build(OP_CHECKSIG, OP_NOT), nil},
+		{"uncompressed public key checked", build(sign(key1, build(OP_CHECKSIG), all), append([]byte{0x04}, make([]byte, 64)...)), build(OP_CHECKSIG), ErrPubKeyEncoding},
+
+		// Pay to script hash and multisig
+		{"p2sh 2 of 3", build(OP_0, sign(key1, multisig, all), sign(key3, multisig, all), multisig), p2sh(multisig), nil},
+		{"p2sh 2 of 3 out of order", build(OP_0, sign(key3, multisig, all), sign(key1, multisig, all), multisig), p2sh(multisig), ErrNullFail},
+		{"p2sh 2 of 3 missing signature", build(OP_0, OP_0, sign(key2, multisig, all), multisig), p2sh(multisig), ErrNullFail},
+		{"p2sh 2 of 3 dummy not empty", build(OP_1, sign(key1, multisig, all), sign(key2, multisig, all), multisig), p2sh(multisig), ErrNullDummy},
+		{"p2sh wrong redeem script", build(OP_1, build(OP_1)), p2sh(build(OP_2)), ErrEvalFalse},
+		{"p2sh redeem script fails", build(OP_0, build(OP_VERIFY, OP_1)), p2sh(build(OP_VERIFY, OP_1)), ErrVerify},
+		{"bare multisig verify", build(OP_0, sign(key2, build(OP_1, pub2, OP_1, OP_CHECKMULTISIGVERIFY, OP_1), all)), build(OP_1, pub2, OP_1, OP_CHECKMULTISIGVERIFY, OP_1), nil},
+		{"multisig too many keys", build(OP_0, OP_0), build(OP_0, 21, OP_CHECKMULTISIG), ErrPubKeyCount},
+		{"code separator", build(sign(key1, build(pub1, OP_CHECKSIG), all), codeSep), p2sh(codeSep), nil},
+		{"code separator ignored", build(sign(key1, codeSep, all), codeSep), p2sh(codeSep), ErrNullFail},
+
+		// Timelocks
+		{"checklocktimeverify", build(sign(key1, cltv, all), cltv), p2sh(cltv), nil},
+		{"checklocktimeverify not reached", build(sign(key1, cltvLate, all), cltvLate), p2sh(cltvLate), ErrUnsatisfiedLockTime},
+		{"checklocktimeverify time against height", nil, build(1600000000, OP_CHECKLOCKTIMEVERIFY), ErrUnsatisfiedLockTime},
+		{"checklocktimeverify negative", nil, build(-1, OP_CHECKLOCKTIMEVERIFY), ErrNegativeLockTime},
+		{"checksequenceverify blocks", nil, build(600, OP_CHECKSEQUENCEVERIFY), nil},
+		{"checksequenceverify below input sequence", nil, build(599, OP_CHECKSEQUENCEVERIFY), nil},
+		{"checksequenceverify above input sequence", nil, build(601, OP_CHECKSEQUENCEVERIFY), ErrUnsatisfiedLockTime},
+		{"checksequenceverify ignores other bits", nil, build(1<<16|600, OP_CHECKSEQUENCEVERIFY), nil},
+		{"checksequenceverify seconds against blocks", nil, build(SequenceLockTimeIsSeconds|1, OP_CHECKSEQUENCEVERIFY), ErrUnsatisfiedLockTime},
+		{"checksequenceverify negative", nil, build(-1, OP_CHECKSEQUENCEVERIFY), ErrNegativeLockTime},
+		{"checksequenceverify disabled", nil, build(SequenceLockTimeDisabled|0xffff, OP_CHECKSEQUENCEVERIFY), nil},
+
+		// Flow control
+		{"if", build(OP_1), build(OP_IF, OP_2, OP_ELSE, OP_3, OP_ENDIF, OP_2, OP_EQUAL), nil},
+		{"else", build(OP_0), build(OP_IF, OP_2, OP_ELSE, OP_3, OP_ENDIF, OP_3, OP_EQUAL), nil},
+		{"notif", build(OP_0), build(OP_NOTIF, OP_1, OP_ELSE, OP_RETURN, OP_ENDIF), nil},
+		{"nested if", build(OP_1, OP_0), build(OP_IF, OP_RETURN, OP_ELSE, OP_IF, OP_1, OP_ELSE, OP_RETURN, OP_ENDIF, OP_ENDIF), nil},
+		{"if argument not minimal", build(OP_2), build(OP_IF, OP_1, OP_ENDIF), ErrMinimalIf},
+		{"unbalanced if", nil, build(OP_1, OP_IF, OP_1), ErrUnbalancedIf},
+		{"unbalanced endif", nil, build(OP_1, OP_ENDIF), ErrUnbalancedIf},
+		{"verify", nil, build(OP_1, OP_VERIFY, OP_1), nil},
+		{"verify false", nil, build(OP_0, OP_VERIFY, OP_1), ErrVerify},
+		{"op_return", build(OP_1), mustScript(NullDataScript([]byte("z chain"))), ErrEarlyReturn},
+		{"nops", nil, build(OP_NOP, OP_NOP1, OP_NOP4, OP_NOP5, OP_NOP6, OP_NOP7, OP_NOP8, OP_NOP9, OP_NOP10, OP_1), nil},
+		{"reserved opcode unexecuted", nil, build(OP_0, OP_IF, OP_VER, OP_RESERVED, OP_ENDIF, OP_1), nil},
+		{"reserved opcode executed", nil, build(OP_1, OP_IF, OP_VER, OP_ENDIF, OP_1), ErrReservedOpcode},
+		{"disabled opcode unexecuted", nil, build(OP_0, OP_IF, OP_CAT, OP_ENDIF, OP_1), ErrDisabledOpcode},
+		{"verif unexecuted", nil, build(OP_0, OP_IF, OP_VERIF, OP_ENDIF, OP_1), ErrDisabledOpcode},
+
+		// Stack
+		{"stack", nil, build(
+			OP_1, OP_2, OP_3, OP_ROT, OP_1, OP_EQUALVERIFY, // 2 3
+			OP_SWAP, OP_2, OP_EQUALVERIFY, // 3
+			OP_DUP, OP_2DUP, OP_3DUP, OP_DEPTH, 7, OP_NUMEQUALVERIFY, // 3 x7
+			OP_2DROP, OP_2DROP, OP_2DROP, OP_DEPTH, OP_1, OP_NUMEQUALVERIFY, // 3
+			OP_4, OP_OVER, OP_NIP, OP_3, OP_NUMEQUALVERIFY, // 3
+			OP_5, OP_TUCK, OP_DROP, OP_3, OP_NUMEQUALVERIFY, OP_5, OP_NUMEQUALVERIFY, // empty
+			OP_1, OP_2, OP_3, OP_4, OP_2OVER, OP_2, OP_NUMEQUALVERIFY, OP_1, OP_NUMEQUALVERIFY, // 1 2 3 4
+			OP_2SWAP, OP_2, OP_NUMEQUALVERIFY, OP_1, OP_NUMEQUALVERIFY, // 3 4
+			OP_5, 6, OP_1, OP_2, OP_2ROT, OP_4, OP_NUMEQUALVERIFY, OP_3, OP_NUMEQUALVERIFY, OP_2DROP, // 5 6
+			OP_1, OP_PICK, OP_5, OP_NUMEQUALVERIFY, OP_1, OP_ROLL, OP_5, OP_NUMEQUALVERIFY, // 6
+			OP_0, OP_IFDUP, OP_DEPTH, OP_2, OP_NUMEQUALVERIFY, OP_DROP, OP_IFDUP, OP_NUMEQUALVERIFY, // empty
+			7, OP_TOALTSTACK, 8, OP_FROMALTSTACK, 7, OP_NUMEQUALVERIFY, 8, OP_NUMEQUAL,
+		), nil},
+		{"stack underflow", nil, build(OP_DROP, OP_1), ErrStackUnderflow},
+		{"pick out of range", nil, build(OP_1, OP_1, OP_PICK), ErrStackUnderflow},
+		{"altstack empty", nil, build(OP_FROMALTSTACK), ErrStackUnderflow},
+		{"stack too large", nil, build(repeat(OP_1, MaxStackSize+1)...), ErrStackSize},
+
+		// Arithmetic
+		{"arithmetic", nil, build(
+			OP_2, OP_3, OP_ADD, OP_5, OP_NUMEQUALVERIFY,
+			10, OP_4, OP_SUB, 6, OP_NUMEQUALVERIFY,
+			9, OP_1ADD, 10, OP_NUMEQUALVERIFY, 9, OP_1SUB, 8, OP_NUMEQUALVERIFY,
+			OP_5, OP_NEGATE, -5, OP_NUMEQUALVERIFY, -5, OP_ABS, OP_5, OP_NUMEQUALVERIFY,
+			OP_0, OP_NOT, OP_VERIFY, OP_5, OP_0NOTEQUAL, OP_VERIFY,
+			OP_1, OP_0, OP_BOOLAND, OP_NOT, OP_VERIFY, OP_1, OP_0, OP_BOOLOR, OP_VERIFY,
+			OP_3, OP_4, OP_LESSTHAN, OP_VERIFY, OP_4, OP_3, OP_GREATERTHAN, OP_VERIFY,
+			OP_3, OP_3, OP_LESSTHANOREQUAL, OP_VERIFY, OP_3, OP_3, OP_GREATERTHANOREQUAL, OP_VERIFY,
+			OP_3, 7, OP_MIN, OP_3, OP_NUMEQUALVERIFY, OP_3, 7, OP_MAX, 7, OP_NUMEQUALVERIFY,
+			OP_3, OP_4, OP_NUMNOTEQUAL, OP_VERIFY,
+			OP_4, OP_3, 7, OP_WITHIN, OP_VERIFY, 7, OP_3, 7, OP_WITHIN, OP_NOT, OP_VERIFY,
+			0x7fffffff, OP_1ADD, 0x80000000, OP_EQUAL,
+		), nil},
+		{"operand over 4 bytes", nil, build(0x80000000, OP_1ADD), ErrNumberRange},
+		{"operand not minimal", nil, build([]byte{0x01, 0x00}, OP_1ADD), ErrMinimalData},
+		{"negative zero is false", nil, build([]byte{0x80}, OP_NOT), ErrMinimalData},
+		{"disabled arithmetic", nil, build(OP_2, OP_2, OP_MUL), ErrDisabledOpcode},
+
+		// Splice, equality and hashing
+		{"size", nil, build([]byte("abcd"), OP_SIZE, OP_4, OP_NUMEQUALVERIFY, OP_DROP, OP_1), nil},
+		{"equal", nil, build([]byte("z"), []byte("z"), OP_EQUAL), nil},
+		{"equalverify", nil, build([]byte("z"), []byte("Z"), OP_EQUALVERIFY, OP_1), ErrVerify},
+		{"hashes", nil, build(
+			[]byte("abc"), OP_RIPEMD160, mustHex("8eb208f7e05d987a9b044a8e98c6b087f15a0bfc"), OP_EQUALVERIFY,
+			[]byte("abc"), OP_SHA1, mustHex("a9993e364706816aba3e25717850c26c9cd0d89d"), OP_EQUALVERIFY,
+			[]byte("abc"), OP_SHA256, mustHex("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"), OP_EQUALVERIFY,
+			[]byte("abc"), OP_HASH256, mustHex("4f8b42c22dd3729b519ba6f68d2da7cc5b2d606d05daed5ad5128cc03e6c6358"), OP_EQUALVERIFY,
+			pub1, OP_HASH160, mustHex("751e76e8199196d454941c45d1b3a323f1433bd6"), OP_EQUAL,
+		), nil},
+
+		// Encoding and evaluation rules
+		{"scriptSig not push only", build(OP_1, OP_DUP), build(OP_EQUAL), ErrSigPushOnly},
+		{"push not minimal", []byte{OP_PUSHDATA1, 0x01, 0x07}, build(7, OP_EQUAL), ErrMinimalData},
+		{"truncated push", nil, []byte{OP_DATA_20, 0x01}, ErrMalformedPush},
+		{"element too large", nil, append([]byte{OP_PUSHDATA2, 0x09, 0x02}, make([]byte, 521)...), ErrElementSize},
+		{"too many operations", nil, build(append(repeat(OP_NOP, MaxOpsPerScript+1), OP_1)...), ErrOpCount},
+		{"evaluates to false", build(OP_0), nil, ErrEvalFalse},
+		{"empty stack", nil, nil, ErrEvalFalse},
+		{"stack not clean", build(OP_1, OP_1), build(OP_NOP), ErrCleanStack},
+	}
+}
+
+// TestVectors evaluates every vector, so a dependency bump cannot silently
+// change which spends are valid
+func TestVectors(t *testing.T) {
+	for _, v := range vectors() {
+		err := Verify(v.ScriptSig, v.ScriptPubkey, vectorContext())
+		switch {
+		case v.Err == nil && err != nil:
+			t.Errorf("%s: %v", v.Name, err)
+		case v.Err != nil && !errors.Is(err, v.Err):
+			t.Errorf("%s: got %v, want %v", v.Name, err, v.Err)
+		}
+	}
+}
+
+// TestOpcodes covers the opcodes and failure paths the vectors leave out
+func TestOpcodes(t *testing.T) {
+	key1 := vectorKey(1)
+	pub1 := compressed(key1)
+	checkSigVerify := build(pub1, OP_CHECKSIGVERIFY, OP_1)
+
+	cases := []vector{
+		// Pushes
+		{"1negate", nil, build(-1, OP_1ADD, OP_0, OP_NUMEQUAL), nil},
+		{"16", nil, build(16, OP_15, OP_SUB, OP_1, OP_NUMEQUAL), nil},
+		{"pushdata2", nil, build(make([]byte, 256), OP_SIZE, 256, OP_NUMEQUALVERIFY, OP_DROP, OP_1), nil},
+		{"pushdata4 not minimal", nil, []byte{OP_PUSHDATA4, 0x01, 0x00, 0x00, 0x00, 0x07, OP_7, OP_EQUAL}, ErrMinimalData},
+		{"script too large", nil, make([]byte, MaxScriptSize+1), ErrScriptSize},
+
+		// Flow control
+		{"op_return unexecuted", nil, build(OP_0, OP_IF, OP_RETURN, OP_ENDIF, OP_1), nil},
+		{"else without if", nil, build(OP_1, OP_ELSE), ErrUnbalancedIf},
+		{"vernotif unexecuted", nil, build(OP_0, OP_IF, OP_VERNOTIF, OP_ENDIF, OP_1), ErrDisabledOpcode},
+		{"reserved1 executed", nil, build(OP_RESERVED1, OP_1), ErrReservedOpcode},
+		{"reserved2 unexecuted", nil, build(OP_0, OP_IF, OP_RESERVED2, OP_ENDIF, OP_1), nil},
+
+		// Stack
+		{"roll moves the element", nil, build(OP_1, OP_2, OP_3, OP_2, OP_ROLL, OP_1, OP_NUMEQUALVERIFY, OP_DEPTH, OP_2, OP_NUMEQUALVERIFY, OP_2DROP, OP_1), nil},
+		{"2rot underflow", nil, build(OP_1, OP_2, OP_3, OP_4, OP_5, OP_2ROT), ErrStackUnderflow},
+		{"altstack not checked for a clean stack", nil, build(OP_1, OP_TOALTSTACK, OP_1), nil},
+
+		// Arithmetic and hashing
+		{"0notequal of zero", nil, build(OP_0, OP_0NOTEQUAL), ErrEvalFalse},
+		{"numequalverify unequal", nil, build(OP_1, OP_2, OP_NUMEQUALVERIFY, OP_1), ErrVerify},
+		{"sha256 of nothing", nil, build(OP_0, OP_SHA256, mustHex("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"), OP_EQUAL), nil},
+
+		// Signatures
+		{"checksigverify", build(sign(key1, checkSigVerify, SigHashAll)), checkSigVerify, nil},
+		{"checksigverify empty signature", build(OP_0), checkSigVerify, ErrVerify},
+		{"multisig 0 of 0", build(OP_0), build(OP_0, OP_0, OP_CHECKMULTISIG), nil},
+		{"multisig more signatures than keys", build(OP_0, OP_0, OP_0), build(OP_2, pub1, OP_1, OP_CHECKMULTISIG), ErrSigCount},
+		{"multisig without dummy", build(OP_0), build(OP_1, pub1, OP_1, OP_CHECKMULTISIG), ErrStackUnderflow},
+	}
+	for _, c := range cases {
+		err := Verify(c.ScriptSig, c.ScriptPubkey, vectorContext())
+		switch {
+		case c.Err == nil && err != nil:
+			t.Errorf("%s: %v", c.Name, err)
+		case c.Err != nil && !errors.Is(err, c.Err):
+			t.Errorf("%s: got %v, want %v", c.Name, err, c.Err)
+		}
+	}
+}
+
+func TestStandardTemplates(t *testing.T) {
+	pub1 := compressed(vectorKey(1))
+	templates := map[Class][]byte{
+		PubKeyHash: mustScript(PayToPubKeyHash(Hash160(pub1))),
+		ScriptHash: p2sh(build(OP_1)),
+		MultiSig:   mustScript(MultiSigScript(1, [][]byte{pub1})),
+		NullData:   mustScript(NullDataScript([]byte("z chain"))),
+	}
+	for class, script := range templates {
+		if got := Classify(script); got != class {
+			t.Errorf("template %s classified as %s", class, got)
+		}
+		if err := CheckStandardOutput(script); err != nil {
+			t.Errorf("template %s: %v", class, err)
+		}
+	}
+	if err := CheckStandardOutput(build(OP_1)); !errors.Is(err, ErrNonStandard) {
+		t.Errorf("non-standard output script: got %v", err)
+	}
+}
+
+// TestLockTimeRules checks the lock time rules at their boundaries, and
+// OP_CHECKSEQUENCEVERIFY against inputs the shared context does not have
+func TestLockTimeRules(t *testing.T) {
+	const height, time = 700, 1700000000
+	lockTimes := []struct {
+		lockTime uint64
+		reached  bool
+	}{
+		{0, true},
+		{699, true},
+		{700, true},
+		{701, false},
+		{LockTimeThreshold - 1, false}, // Still a height
+		{time, true},
+		{time + 1, false},
+	}
+	for _, v := range lockTimes {
+		if got := LockTimeReached(v.lockTime, height, time); got != v.reached {
+			t.Errorf("lock time %d reached %t at height %d, want %t", v.lockTime, got, height, v.reached)
+		}
+	}
+
+	// Spending an output created at height 100, 1024s before the block
+	const outputHeight, outputTime = 100, time - 1024
+	sequences := []struct {
+		sequence uint32
+		reached  bool
+	}{
+		{0, true},
+		{600, true},
+		{601, false},
+		{SequenceLockTimeDisabled | 601, true},
+		{1<<16 | 600, true}, // Bits outside the mask and flags are ignored
+		{SequenceLockTimeIsSeconds | 2, true},
+		{SequenceLockTimeIsSeconds | 3, false},
+	}
+	for _, v := range sequences {
+		if got := SequenceLockReached(v.sequence, height, time, outputHeight, outputTime); got != v.reached {
+			t.Errorf("sequence 0x%x reached %t, want %t", v.sequence, got, v.reached)
+		}
+	}
+
+	ctx := vectorContext()
+	ctx.Tx.Inputs[0].Sequence = SequenceLockTimeIsSeconds | 2
+	if err := Verify(nil, build(SequenceLockTimeIsSeconds|2, OP_CHECKSEQUENCEVERIFY), ctx); err != nil {
+		t.Errorf("checksequenceverify seconds: %v", err)
+	}
+	if err := Verify(nil, build(2, OP_CHECKSEQUENCEVERIFY), ctx); !errors.Is(err, ErrUnsatisfiedLockTime) {
+		t.Errorf("checksequenceverify blocks against seconds: got %v", err)
+	}
+	ctx.Tx.Inputs[0].Sequence = SequenceLockTimeDisabled
+	if err := Verify(nil, build(0, OP_CHECKSEQUENCEVERIFY), ctx); !errors.Is(err, ErrUnsatisfiedLockTime) {
+		t.Errorf("checksequenceverify without relative lock: got %v", err)
+	}
+}
+
+// TestLockBoundaryHeights spends lock times and relative locks on the first
+// block they allow and the one before
+func TestLockBoundaryHeights(t *testing.T) {
+	// An output created at height 1000, at time 1700000000
+	const outputHeight, outputTime = 1000, 1700000000
+	relative := []struct {
+		name     string
+		sequence uint32
+		height   int64
+		time     int64
+		reached  bool
+	}{
+		{"10 blocks, one early", 10, outputHeight + 9, outputTime, false},
+		{"10 blocks, on the height", 10, outputHeight + 10, outputTime, true},
+		{"most blocks, one early", SequenceLockTimeMask, outputHeight + SequenceLockTimeMask - 1, outputTime, false},
+		{"most blocks, on the height", SequenceLockTimeMask, outputHeight + SequenceLockTimeMask, outputTime, true},
+		{"one unit, a second early", SequenceLockTimeIsSeconds | 1, outputHeight + 1, outputTime + 511, false},
+		{"one unit, on the second", SequenceLockTimeIsSeconds | 1, outputHeight + 1, outputTime + 512, true},
+		{"same block", 1, outputHeight, outputTime, false},
+		{"no lock in the same block", 0, outputHeight, outputTime, true},
+	}
+	for _, c := range relative {
+		if got := SequenceLockReached(c.sequence, c.height, c.time, outputHeight, outputTime); got != c.reached {
+			t.Errorf("%s: reached %t, want %t", c.name, got, c.reached)
+		}
+	}
+
+	absolute := []struct {
+		name     string
+		lockTime uint64
+		height   int64
+		reached  bool
+	}{
+		{"height, one block early", 1000, 999, false},
+		{"height, on the block", 1000, 1000, true},
+		{"last height, one block early", LockTimeThreshold - 1, LockTimeThreshold - 2, false},
+		{"last height, on the block", LockTimeThreshold - 1, LockTimeThreshold - 1, true},
+		{"first time, at the same height", LockTimeThreshold, LockTimeThreshold, false},
+	}
+	for _, c := range absolute {
+		// The block time is just below the threshold, so a time lock is
+		// never reached by height alone
+		if got := LockTimeReached(c.lockTime, c.height, LockTimeThreshold-1); got != c.reached {
+			t.Errorf("%s: reached %t, want %t", c.name, got, c.reached)
+		}
+	}
+
+	// The opcodes compare against the transaction, which must commit to at
+	// least the lock of the script, of the same kind
+	ops := []struct {
+		name     string
+		lockTime uint64
+		sequence uint32
+		script   []byte
+		err      error
+	}{
+		{"cltv one block short", 999, 0, build(1000, OP_CHECKLOCKTIMEVERIFY), ErrUnsatisfiedLockTime},
+		{"cltv on the height", 1000, 0, build(1000, OP_CHECKLOCKTIMEVERIFY), nil},
+		{"cltv last height against first time", LockTimeThreshold, 0, build(LockTimeThreshold-1, OP_CHECKLOCKTIMEVERIFY), ErrUnsatisfiedLockTime},
+		{"csv one block short", 0, 9, build(10, OP_CHECKSEQUENCEVERIFY), ErrUnsatisfiedLockTime},
+		{"csv on the height", 0, 10, build(10, OP_CHECKSEQUENCEVERIFY), nil},
+		{"csv most blocks", 0, SequenceLockTimeMask, build(SequenceLockTimeMask, OP_CHECKSEQUENCEVERIFY), nil},
+		{"csv one unit short", 0, SequenceLockTimeIsSeconds, build(SequenceLockTimeIsSeconds|1, OP_CHECKSEQUENCEVERIFY), ErrUnsatisfiedLockTime},
+	}
+	for _, c := range ops {
+		ctx := vectorContext()
+		ctx.Tx.LockTime = c.lockTime
+		ctx.Tx.Inputs[0].Sequence = c.sequence
+		if err := Verify(nil, c.script, ctx); !errors.Is(err, c.err) {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.err)
+		}
+	}
+}
+
+// build assembles a script from opcodes (byte), pushes ([]byte) and numbers (int)
+func build(items ...interface{}) []byte {
+	b := NewBuilder()
+	for _, item := range items {
+		switch v := item.(type) {
+		case byte:
+			b.AddOp(v)
+		case []byte:
+			b.AddData(v)
+		case int:
+			b.AddInt64(int64(v))
+		default:
+			panic(fmt.Sprintf("script vector item %T", item))
+		}
+	}
+	return mustScript(b.Script())
+}
+
+func repeat(op byte, n int) []interface{} {
+	items := make([]interface{}, n)
+	for i := range items {
+		items[i] = op
+	}
+	return items
+}
+
+func p2sh(redeemScript []byte) []byte {
+	return mustScript(PayToScriptHash(redeemScript))
+}
+
+// sign signs the vector spend with subscript as the script being executed
+func sign(key *ecdsa.PrivateKey, subscript []byte, hashType SigHashType) []byte {
+	return signAltered(nil, key, subscript, hashType)
+}
+
+// signAltered signs the vector spend after alter changes it, so the signature
+// is for a different spend than the one verified
+func signAltered(alter func(*Context), key *ecdsa.PrivateKey, subscript []byte, hashType SigHashType) []byte {
+	ctx := vectorContext()
+	if alter != nil {
+		alter(ctx)
+	}
+	hash, err := SigHash(ctx.Tx, ctx.InputIndex, subscript, ctx.Amount, hashType)
+	if err != nil {
+		panic(err)
+	}
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		panic(err)
+	}
+	return append(sig[:64], byte(hashType))
+}
+
+func vectorKey(scalar byte) *ecdsa.PrivateKey {
+	bz := make([]byte, 32)
+	bz[31] = scalar
+	key, err := crypto.ToECDSA(bz)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func compressed(key *ecdsa.PrivateKey) []byte {
+	return crypto.CompressPubkey(&key.PublicKey)
+}
+
+func mustScript(script []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return script
+}
+
+func mustHex(s string) []byte {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func mustAddress(s string) address.Address {
+	addr, err := address.FromBytes(mustHex(s))
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
@@ -0,0 +1,37 @@
+package script
+
+import (
+	"errors"
+
+	"shared/sighash"
+)
+
+var (
+	ErrScriptSize          = errors.New("script too large")
+	ErrElementSize         = errors.New("push exceeds the element size limit")
+	ErrMalformedPush       = errors.New("malformed push")
+	ErrMinimalData         = errors.New("data not minimally encoded")
+	ErrOpCount             = errors.New("too many operations")
+	ErrStackSize           = errors.New("stack too large")
+	ErrStackUnderflow      = errors.New("stack underflow")
+	ErrNumberRange         = errors.New("number out of range")
+	ErrDisabledOpcode      = errors.New("disabled opcode")
+	ErrReservedOpcode      = errors.New("reserved opcode")
+	ErrUnbalancedIf        = errors.New("unbalanced conditional")
+	ErrMinimalIf           = errors.New("OP_IF argument must be empty or 1")
+	ErrVerify              = errors.New("verify failed")
+	ErrEarlyReturn         = errors.New("OP_RETURN executed")
+	ErrEvalFalse           = errors.New("script evaluated to false")
+	ErrCleanStack          = errors.New("stack not clean after evaluation")
+	ErrSigPushOnly         = errors.New("scriptSig must only push data")
+	ErrSigHashType         = sighash.ErrHashType
+	ErrSigEncoding         = errors.New("invalid signature encoding")
+	ErrPubKeyEncoding      = errors.New("public key must be 33 byte compressed")
+	ErrNullFail            = errors.New("failed signature check with a non-empty signature")
+	ErrNullDummy           = errors.New("OP_CHECKMULTISIG dummy element must be empty")
+	ErrPubKeyCount         = errors.New("invalid public key count")
+	ErrSigCount            = errors.New("invalid signature count")
+	ErrNegativeLockTime    = errors.New("negative lock time")
+	ErrUnsatisfiedLockTime = errors.New("lock time not reached")
+	ErrNonStandard         = errors.New("non-standard script")
+)
@@ -0,0 +1,83 @@
+package script
+
+import "fmt"
+
+// Operands of arithmetic opcodes are limited to 4 bytes; lock times to 5, so
+// they reach past 2038
+const (
+	maxNumSize      = 4
+	maxLockTimeSize = 5
+)
+
+// scriptNum is a number as scripts encode it: little endian sign-magnitude,
+// the sign in the top bit of the last byte, and no superfluous bytes
+type scriptNum int64
+
+func makeScriptNum(v []byte, maxSize int) (scriptNum, error) {
+	if len(v) > maxSize {
+		return 0, fmt.Errorf("%w: %d byte number, at most %d", ErrNumberRange, len(v), maxSize)
+	}
+	if len(v) == 0 {
+		return 0, nil
+	}
+	// The last byte may only be 0x00 or 0x80 when the byte before needs its
+	// top bit
+	if v[len(v)-1]&0x7f == 0 && (len(v) == 1 || v[len(v)-2]&0x80 == 0) {
+		return 0, fmt.Errorf("%w: number %x is not minimally encoded", ErrMinimalData, v)
+	}
+
+	var n int64
+	for i, b := range v {
+		n |= int64(b) << uint(8*i)
+	}
+	if v[len(v)-1]&0x80 != 0 {
+		n &^= int64(0x80) << uint(8*(len(v)-1))
+		return scriptNum(-n), nil
+	}
+	return scriptNum(n), nil
+}
+
+// Bytes returns the minimal encoding of n
+func (n scriptNum) Bytes() []byte {
+	if n == 0 {
+		return nil
+	}
+
+	negative := n < 0
+	abs := int64(n)
+	if negative {
+		abs = -abs
+	}
+
+	var out []byte
+	for abs > 0 {
+		out = append(out, byte(abs&0xff))
+		abs >>= 8
+	}
+	switch {
+	case out[len(out)-1]&0x80 != 0 && negative:
+		out = append(out, 0x80)
+	case out[len(out)-1]&0x80 != 0:
+		out = append(out, 0x00)
+	case negative:
+		out[len(out)-1] |= 0x80
+	}
+	return out
+}
+
+// asBool is false for any encoding of zero, including negative zero
+func asBool(v []byte) bool {
+	for i, b := range v {
+		if b != 0 {
+			return i != len(v)-1 || b != 0x80
+		}
+	}
+	return false
+}
+
+func fromBool(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package script
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// parsedOp is an opcode with the data it pushes. Offset is where the opcode
+// starts in the script, so OP_CODESEPARATOR can cut the signed subscript.
+type parsedOp struct {
+	op     byte
+	data   []byte
+	offset int
+}
+
+// push reports whether the opcode only pushes data
+func (p parsedOp) push() bool {
+	return p.op <= OP_16 && p.op != OP_RESERVED
+}
+
+// parse splits a script into opcodes, failing on truncated pushes
+func parse(script []byte) ([]parsedOp, error) {
+	var ops []parsedOp
+	for i := 0; i < len(script); {
+		op := parsedOp{op: script[i], offset: i}
+		i++
+
+		var n int
+		switch {
+		case op.op >= OP_DATA_1 && op.op <= OP_DATA_75:
+			n = int(op.op)
+		case op.op == OP_PUSHDATA1:
+			if i+1 > len(script) {
+				return nil, fmt.Errorf("%w: truncated OP_PUSHDATA1", ErrMalformedPush)
+			}
+			n = int(script[i])
+			i++
+		case op.op == OP_PUSHDATA2:
+			if i+2 > len(script) {
+				return nil, fmt.Errorf("%w: truncated OP_PUSHDATA2", ErrMalformedPush)
+			}
+			n = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		case op.op == OP_PUSHDATA4:
+			if i+4 > len(script) {
+				return nil, fmt.Errorf("%w: truncated OP_PUSHDATA4", ErrMalformedPush)
+			}
+			size := binary.LittleEndian.Uint32(script[i:])
+			if size > MaxScriptSize {
+				return nil, fmt.Errorf("%w: push of %d bytes", ErrMalformedPush, size)
+			}
+			n = int(size)
+			i += 4
+		}
+
+		if n > 0 {
+			if i+n > len(script) {
+				return nil, fmt.Errorf("%w: push of %d bytes at offset %d runs past the script", ErrMalformedPush, n, op.offset)
+			}
+			op.data = script[i : i+n]
+			i += n
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// minimalPush reports whether the data was pushed with the shortest opcode
+func (p parsedOp) minimalPush() bool {
+	if p.op < OP_DATA_1 || p.op > OP_PUSHDATA4 {
+		return true
+	}
+
+	n := len(p.data)
+	switch {
+	case n == 0:
+		return p.op == OP_0
+	case n == 1 && p.data[0] >= 1 && p.data[0] <= 16:
+		return p.op == OP_1+p.data[0]-1
+	case n == 1 && p.data[0] == 0x81:
+		return p.op == OP_1NEGATE
+	case n <= 75:
+		return int(p.op) == n
+	case n <= 255:
+		return p.op == OP_PUSHDATA1
+	case n <= 65535:
+		return p.op == OP_PUSHDATA2
+	}
+	return true
+}
+
+// IsPushOnly reports whether a script only pushes data. It is false for a
+// script that does not parse.
+func IsPushOnly(script []byte) bool {
+	ops, err := parse(script)
+	if err != nil {
+		return false
+	}
+	for _, op := range ops {
+		if !op.push() {
+			return false
+		}
+	}
+	return true
+}
+
+// Builder assembles a script with minimal pushes
+type Builder struct {
+	buf bytes.Buffer
+	err error
+}
+
+// NewBuilder starts an empty script
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// AddOp appends an opcode
+func (b *Builder) AddOp(ops ...byte) *Builder {
+	b.buf.Write(ops)
+	return b
+}
+
+// AddData appends the shortest push of data
+func (b *Builder) AddData(data []byte) *Builder {
+	n := len(data)
+	switch {
+	case n > MaxElementSize:
+		b.err = fmt.Errorf("%w: push of %d bytes", ErrElementSize, n)
+		return b
+	case n == 0:
+		b.buf.WriteByte(OP_0)
+		return b
+	case n == 1 && data[0] >= 1 && data[0] <= 16:
+		b.buf.WriteByte(OP_1 + data[0] - 1)
+		return b
+	case n == 1 && data[0] == 0x81:
+		b.buf.WriteByte(OP_1NEGATE)
+		return b
+	case n <= 75:
+		b.buf.WriteByte(byte(n))
+	case n <= 255:
+		b.buf.WriteByte(OP_PUSHDATA1)
+		b.buf.WriteByte(byte(n))
+	default:
+		b.buf.WriteByte(OP_PUSHDATA2)
+		b.buf.Write([]byte{byte(n), byte(n >> 8)})
+	}
+	b.buf.Write(data)
+	return b
+}
+
+// AddInt64 appends the push of a script number
+func (b *Builder) AddInt64(n int64) *Builder {
+	switch {
+	case n == 0:
+		b.buf.WriteByte(OP_0)
+	case n == -1:
+		b.buf.WriteByte(OP_1NEGATE)
+	case n >= 1 && n <= 16:
+		b.buf.WriteByte(OP_1 + byte(n-1))
+	default:
+		b.AddData(scriptNum(n).Bytes())
+	}
+	return b
+}
+
+// Script returns the assembled script
+func (b *Builder) Script() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.buf.Len() > MaxScriptSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrScriptSize, b.buf.Len())
+	}
+	return append([]byte(nil), b.buf.Bytes()...), nil
+}
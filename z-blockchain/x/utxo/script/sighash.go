@@ -0,0 +1,29 @@
+package script
+
+import (
+	"shared/sighash"
+)
+
+// SigHashType selects the parts of a transaction a signature commits to. It
+// is the last byte of a script signature.
+type SigHashType = sighash.HashType
+
+const (
+	SigHashAll          = sighash.All
+	SigHashNone         = sighash.None
+	SigHashSingle       = sighash.Single
+	SigHashAnyoneCanPay = sighash.AnyoneCanPay
+)
+
+// The transaction as scripts see it, shared with the wallet
+type (
+	Input  = sighash.Input
+	Output = sighash.Output
+	Tx     = sighash.Tx
+)
+
+// SigHash is the digest signed by input index, see sighash.Digest.
+// subscript is the script being executed from its last OP_CODESEPARATOR on.
+func SigHash(tx *Tx, index int, subscript []byte, amount string, hashType SigHashType) ([]byte, error) {
+	return sighash.Digest(tx, index, subscript, amount, hashType)
+}
@@ -0,0 +1,204 @@
+package script
+
+import "fmt"
+
+// Class is the template an output script follows
+type Class int
+
+const (
+	NonStandard Class = iota
+	PubKeyHash        // OP_DUP OP_HASH160 <hash> OP_EQUALVERIFY OP_CHECKSIG
+	ScriptHash        // OP_HASH160 <hash> OP_EQUAL
+	MultiSig          // <m> <pubkey>... <n> OP_CHECKMULTISIG
+	NullData          // OP_RETURN <data>...
+)
+
+// String implements the Stringer interface.
+func (c Class) String() string {
+	switch c {
+	case PubKeyHash:
+		return "pubkeyhash"
+	case ScriptHash:
+		return "scripthash"
+	case MultiSig:
+		return "multisig"
+	case NullData:
+		return "nulldata"
+	default:
+		return "nonstandard"
+	}
+}
+
+// Standardness limits. Outputs must follow one of the standard templates, so
+// arbitrary scripts, timelocks among them, are paid to through a script hash.
+const (
+	MaxStandardMultiSigKeys  = 3  // Bare multisig; larger sets go through a script hash
+	MaxNullDataSize          = 83 // Of the whole OP_RETURN script
+	MaxStandardScriptSigSize = 1650
+)
+
+// Classify returns the template script follows
+func Classify(script []byte) Class {
+	switch {
+	case isP2PKH(script):
+		return PubKeyHash
+	case isP2SH(script):
+		return ScriptHash
+	case isMultiSig(script):
+		return MultiSig
+	case isNullData(script):
+		return NullData
+	}
+	return NonStandard
+}
+
+func isP2PKH(script []byte) bool {
+	return len(script) == 25 &&
+		script[0] == OP_DUP &&
+		script[1] == OP_HASH160 &&
+		script[2] == OP_DATA_20 &&
+		script[23] == OP_EQUALVERIFY &&
+		script[24] == OP_CHECKSIG
+}
+
+func isP2SH(script []byte) bool {
+	return len(script) == 23 &&
+		script[0] == OP_HASH160 &&
+		script[1] == OP_DATA_20 &&
+		script[22] == OP_EQUAL
+}
+
+func isMultiSig(script []byte) bool {
+	_, _, ok := multiSigParams(script)
+	return ok
+}
+
+// multiSigParams returns m and n of a multisig script
+func multiSigParams(script []byte) (int, int, bool) {
+	ops, err := parse(script)
+	if err != nil || len(ops) < 4 || ops[len(ops)-1].op != OP_CHECKMULTISIG {
+		return 0, 0, false
+	}
+	m, ok := smallInt(ops[0].op)
+	if !ok || m < 1 {
+		return 0, 0, false
+	}
+	n, ok := smallInt(ops[len(ops)-2].op)
+	if !ok || n < m || n != len(ops)-3 {
+		return 0, 0, false
+	}
+	for _, op := range ops[1 : len(ops)-2] {
+		if op.op != OP_DATA_33 || !validPubKey(op.data) {
+			return 0, 0, false
+		}
+	}
+	return m, n, true
+}
+
+func isNullData(script []byte) bool {
+	return len(script) >= 1 && len(script) <= MaxNullDataSize &&
+		script[0] == OP_RETURN && IsPushOnly(script[1:])
+}
+
+// ScriptHashOf returns the redeem script hash a ScriptHash script pays to
+func ScriptHashOf(script []byte) ([]byte, bool) {
+	if !isP2SH(script) {
+		return nil, false
+	}
+	return script[2:22], true
+}
+
+// NullDataPayload returns the data a NullData script carries, its pushes
+// concatenated
+func NullDataPayload(script []byte) ([]byte, bool) {
+	if !isNullData(script) {
+		return nil, false
+	}
+	ops, err := parse(script[1:])
+	if err != nil {
+		return nil, false
+	}
+	var data []byte
+	for _, op := range ops {
+		data = append(data, op.data...)
+	}
+	return data, true
+}
+
+// Unspendable reports scripts no input can satisfy. Their outputs are never
+// added to the UTXO set.
+func Unspendable(script []byte) bool {
+	return (len(script) > 0 && script[0] == OP_RETURN) || len(script) > MaxScriptSize
+}
+
+// CheckStandardOutput rejects output scripts that follow no standard template
+func CheckStandardOutput(script []byte) error {
+	switch Classify(script) {
+	case NonStandard:
+		return fmt.Errorf("%w: output script follows no standard template", ErrNonStandard)
+	case MultiSig:
+		if _, n, _ := multiSigParams(script); n > MaxStandardMultiSigKeys {
+			return fmt.Errorf("%w: bare multisig with %d keys, at most %d", ErrNonStandard, n, MaxStandardMultiSigKeys)
+		}
+	}
+	return nil
+}
+
+// CheckStandardInput rejects oversized scriptSigs and those that do more than push
+func CheckStandardInput(scriptSig []byte) error {
+	if len(scriptSig) > MaxStandardScriptSigSize {
+		return fmt.Errorf("%w: scriptSig of %d bytes, at most %d", ErrNonStandard, len(scriptSig), MaxStandardScriptSigSize)
+	}
+	if !IsPushOnly(scriptSig) {
+		return ErrSigPushOnly
+	}
+	return nil
+}
+
+// PayToPubKeyHash locks an output to the key hashing to pubKeyHash, the
+// 20 bytes of a Z account
+func PayToPubKeyHash(pubKeyHash []byte) ([]byte, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, fmt.Errorf("public key hash must be 20 bytes, got %d", len(pubKeyHash))
+	}
+	return NewBuilder().AddOp(OP_DUP, OP_HASH160).AddData(pubKeyHash).AddOp(OP_EQUALVERIFY, OP_CHECKSIG).Script()
+}
+
+// PayToScriptHash locks an output to the redeem script hashing to its hash
+func PayToScriptHash(redeemScript []byte) ([]byte, error) {
+	if len(redeemScript) > MaxElementSize {
+		return nil, fmt.Errorf("%w: redeem script of %d bytes", ErrElementSize, len(redeemScript))
+	}
+	return NewBuilder().AddOp(OP_HASH160).AddData(Hash160(redeemScript)).AddOp(OP_EQUAL).Script()
+}
+
+// MultiSigScript requires m signatures by the given compressed keys
+func MultiSigScript(m int, pubKeys [][]byte) ([]byte, error) {
+	if len(pubKeys) < 1 || len(pubKeys) > 16 {
+		return nil, fmt.Errorf("%w: %d", ErrPubKeyCount, len(pubKeys))
+	}
+	if m < 1 || m > len(pubKeys) {
+		return nil, fmt.Errorf("%w: %d of %d", ErrSigCount, m, len(pubKeys))
+	}
+
+	b := NewBuilder().AddOp(smallIntOp(m))
+	for _, pubKey := range pubKeys {
+		if !validPubKey(pubKey) {
+			return nil, ErrPubKeyEncoding
+		}
+		b.AddData(pubKey)
+	}
+	return b.AddOp(smallIntOp(len(pubKeys)), OP_CHECKMULTISIG).Script()
+}
+
+// NullDataScript carries data in an unspendable output
+func NullDataScript(data []byte) ([]byte, error) {
+	script, err := NewBuilder().AddOp(OP_RETURN).AddData(data).Script()
+	if err != nil {
+		return nil, err
+	}
+	if len(script) > MaxNullDataSize {
+		return nil, fmt.Errorf("%w: OP_RETURN script of %d bytes, at most %d", ErrNonStandard, len(script), MaxNullDataSize)
+	}
+	return script, nil
+}
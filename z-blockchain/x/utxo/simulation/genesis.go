@@ -0,0 +1,72 @@
+package simulation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"shared/address"
+	"z-blockchain/x/utxo/types"
+)
+
+// RandomizedGenState generates a genesis in which every simulation account
+// owns one unspent output. The value of the outputs is escrowed by the module
+// account in the bank genesis, as coinbase value is, so the supply invariant
+// holds from the first block; the bank genesis must be generated first.
+func RandomizedGenState(simState *module.SimulationState) {
+	gs := types.DefaultGenesis()
+
+	total := sdk.ZeroInt()
+	for _, acc := range simState.Accounts {
+		owner, err := address.FromBytes(acc.Address)
+		if err != nil {
+			panic(err)
+		}
+
+		// Between 1 and 1000 Z
+		amount := sdk.NewIntWithDecimal(1, 18).MulRaw(1 + simState.Rand.Int63n(1000))
+		hash := sha256.Sum256(append([]byte("genesis/"), acc.Address...))
+		gs.Utxos = append(gs.Utxos, types.UTXO{
+			TxHash:      hex.EncodeToString(hash[:]),
+			OutputIndex: 0,
+			Address:     owner.ZChain(),
+			Amount:      amount.String(),
+		})
+		total = total.Add(amount)
+	}
+	gs.Params.CoinbaseMaturity = int64(simtypes.RandIntBetween(simState.Rand, 1, 100))
+
+	bz, err := simState.Cdc.MarshalJSON(gs)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Selected randomly generated utxo parameters:\n%s\n", bz)
+	simState.GenState[types.ModuleName] = simState.Cdc.MustMarshalJSON(gs)
+
+	escrowGenesis(simState, sdk.NewCoin("z", total))
+}
+
+// escrowGenesis credits the module account with escrow in the bank genesis
+func escrowGenesis(simState *module.SimulationState, escrow sdk.Coin) {
+	bankState, ok := simState.GenState[banktypes.ModuleName]
+	if !ok {
+		return
+	}
+
+	var bankGenesis banktypes.GenesisState
+	simState.Cdc.MustUnmarshalJSON(bankState, &bankGenesis)
+	bankGenesis.Balances = append(bankGenesis.Balances, banktypes.Balance{
+		Address: authtypes.NewModuleAddress(types.ModuleName).String(),
+		Coins:   sdk.NewCoins(escrow),
+	})
+	if !bankGenesis.Supply.IsZero() {
+		bankGenesis.Supply = bankGenesis.Supply.Add(escrow)
+	}
+	simState.GenState[banktypes.ModuleName] = simState.Cdc.MustMarshalJSON(&bankGenesis)
+}
@@ -0,0 +1,299 @@
+// Package simulation plugs the utxo module into the SDK's simulation
+// framework: a randomized genesis that gives every simulation account an
+// output to spend, and weighted operations run against it between the
+// module's invariants.
+//
+// Transparent spends are real, signed the way the wallet signs them. Shielded
+// bundles and mining proofs cannot be made honestly without the proving keys
+// and mining hardware, so those operations submit forged ones instead and
+// fail the simulation if one is ever accepted.
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"shared/address"
+	"shared/sighash"
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+)
+
+// Operation weights, overridable through the simulation params file
+const (
+	OpWeightMsgSendUTXO          = "op_weight_msg_send_utxo"
+	OpWeightMsgSendShielded      = "op_weight_msg_send_shielded"
+	OpWeightMsgSubmitMiningProof = "op_weight_msg_submit_mining_proof"
+
+	DefaultWeightMsgSendUTXO          = 100
+	DefaultWeightMsgSendShielded      = 20
+	DefaultWeightMsgSubmitMiningProof = 10
+)
+
+// scriptSigSize is the size of an AddressScript spend, used to size the fee
+// before signing
+const scriptSigSize = 1 + 65 + 1 + 33
+
+// WeightedOperations returns the module's operations with their weights
+func WeightedOperations(
+	appParams simtypes.AppParams,
+	cdc codec.JSONCodec,
+	txGen client.TxConfig,
+	ak simulation.AccountKeeper,
+	k keeper.Keeper,
+) simulation.WeightedOperations {
+	var weightSendUTXO, weightSendShielded, weightSubmitMiningProof int
+	appParams.GetOrGenerate(cdc, OpWeightMsgSendUTXO, &weightSendUTXO, nil, func(_ *rand.Rand) {
+		weightSendUTXO = DefaultWeightMsgSendUTXO
+	})
+	appParams.GetOrGenerate(cdc, OpWeightMsgSendShielded, &weightSendShielded, nil, func(_ *rand.Rand) {
+		weightSendShielded = DefaultWeightMsgSendShielded
+	})
+	appParams.GetOrGenerate(cdc, OpWeightMsgSubmitMiningProof, &weightSubmitMiningProof, nil, func(_ *rand.Rand) {
+		weightSubmitMiningProof = DefaultWeightMsgSubmitMiningProof
+	})
+
+	return simulation.WeightedOperations{
+		simulation.NewWeightedOperation(weightSendUTXO, SimulateMsgSendUTXO(txGen, ak, k)),
+		simulation.NewWeightedOperation(weightSendShielded, SimulateMsgSendShielded(txGen, ak, k)),
+		simulation.NewWeightedOperation(weightSubmitMiningProof, SimulateMsgSubmitMiningProof(txGen, ak)),
+	}
+}
+
+// SimulateMsgSendUTXO spends a random unspent output of a random account to
+// another account, with change back to the spender, at the minimum fee rate
+func SimulateMsgSendUTXO(txGen client.TxConfig, ak simulation.AccountKeeper, k keeper.Keeper) simtypes.Operation {
+	return func(r *rand.Rand, app *baseapp.BaseApp, ctx sdk.Context, accs []simtypes.Account, chainID string) (simtypes.OperationMsg, []simtypes.FutureOperation, error) {
+		msgType := sdk.MsgTypeURL(&types.MsgSendUTXO{})
+		from, _ := simtypes.RandomAcc(r, accs)
+		to, _ := simtypes.RandomAcc(r, accs)
+
+		owner, err := address.FromBytes(from.Address)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msgType, "invalid account address"), nil, err
+		}
+		utxo, found := randomSpendable(r, ctx, k, owner.ZChain())
+		if !found {
+			return simtypes.NoOpMsg(types.ModuleName, msgType, "no spendable output"), nil, nil
+		}
+		recipient, err := address.FromBytes(to.Address)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msgType, "invalid account address"), nil, err
+		}
+
+		msg := &types.MsgSendUTXO{
+			Creator: from.Address.String(),
+			Inputs: []*types.TxInput{{
+				PrevTxHash:      utxo.TxHash,
+				PrevOutputIndex: utxo.OutputIndex,
+				ScriptSig:       make([]byte, scriptSigSize),
+			}},
+			Outputs: []*types.TxOutput{
+				{Address: recipient.ZChain()},
+				{Address: owner.ZChain()},
+			},
+		}
+
+		// Outputs of at least the dust limit, paying the minimum fee rate for
+		// the signed size
+		amount, _ := sdk.NewIntFromString(utxo.Amount)
+		dust := k.DustLimit(ctx)
+		msg.Outputs[0].Amount, msg.Outputs[1].Amount, msg.Fee = amount.String(), "0", "0"
+		fee := k.MinFeeRate(ctx).MulRaw(int64(msg.Size() + 32))
+		spendable := amount.Sub(fee)
+		if spendable.LT(dust.MulRaw(2)) {
+			return simtypes.NoOpMsg(types.ModuleName, msgType, "output too small to split"), nil, nil
+		}
+		send, err := simtypes.RandPositiveInt(r, spendable.Sub(dust.MulRaw(2)))
+		if err != nil {
+			send = sdk.ZeroInt()
+		}
+		send = send.Add(dust)
+		msg.Fee = fee.String()
+		msg.Outputs[0].Amount = send.String()
+		msg.Outputs[1].Amount = spendable.Sub(send).String()
+
+		if err := signInput(msg, chainID, owner, utxo.Amount, from.PrivKey.Bytes()); err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msgType, "unable to sign input"), nil, err
+		}
+
+		txCtx := simulation.OperationInput{
+			R:               r,
+			App:             app,
+			TxGen:           txGen,
+			Msg:             msg,
+			Context:         ctx,
+			SimAccount:      from,
+			AccountKeeper:   ak,
+			ModuleName:      types.ModuleName,
+			CoinsSpentInMsg: sdk.NewCoins(),
+		}
+		return simulation.GenAndDeliverTx(txCtx, sdk.NewCoins())
+	}
+}
+
+// SimulateMsgSendShielded submits a shielded bundle with a forged proof
+// against the current anchor. It must be refused without leaving any of its
+// nullifiers or commitments behind.
+func SimulateMsgSendShielded(txGen client.TxConfig, ak simulation.AccountKeeper, k keeper.Keeper) simtypes.Operation {
+	return func(r *rand.Rand, app *baseapp.BaseApp, ctx sdk.Context, accs []simtypes.Account, chainID string) (simtypes.OperationMsg, []simtypes.FutureOperation, error) {
+		from, _ := simtypes.RandomAcc(r, accs)
+		msg := &types.MsgSendShielded{
+			Creator:      from.Address.String(),
+			Nullifiers:   randomHashes(r, 1+r.Intn(2)),
+			Commitments:  randomHashes(r, 1+r.Intn(2)),
+			ZkProof:      randomBytes(r, 256),
+			Anchor:       k.GetNoteTreeRoot(ctx),
+			Fee:          "0",
+			ValueBalance: "0",
+		}
+
+		opMsg, err := deliverForged(r, app, ctx, txGen, ak, from, chainID, msg)
+		if err != nil {
+			return opMsg, nil, err
+		}
+		for _, nullifier := range msg.Nullifiers {
+			if k.IsNullifierUsed(ctx, nullifier) {
+				return opMsg, nil, fmt.Errorf("refused shielded bundle left nullifier %x spent", nullifier)
+			}
+		}
+		return opMsg, nil, nil
+	}
+}
+
+// SimulateMsgSubmitMiningProof submits a random mining solution, which must
+// be refused
+func SimulateMsgSubmitMiningProof(txGen client.TxConfig, ak simulation.AccountKeeper) simtypes.Operation {
+	return func(r *rand.Rand, app *baseapp.BaseApp, ctx sdk.Context, accs []simtypes.Account, chainID string) (simtypes.OperationMsg, []simtypes.FutureOperation, error) {
+		from, _ := simtypes.RandomAcc(r, accs)
+		msg := &types.MsgSubmitMiningProof{
+			Creator:       from.Address.String(),
+			ZkProof:       randomBytes(r, 1344),
+			PublicInputs:  randomBytes(r, 140),
+			Nonce:         r.Uint64(),
+			Difficulty:    1 + uint64(r.Int63n(1<<40)),
+			HardwareId:    "nvidia-a100",
+			Timestamp:     ctx.BlockTime().UnixMilli(),
+			Height:        ctx.BlockHeight(),
+			PrevBlockHash: ctx.BlockHeader().LastBlockId.Hash,
+		}
+
+		opMsg, err := deliverForged(r, app, ctx, txGen, ak, from, chainID, msg)
+		return opMsg, nil, err
+	}
+}
+
+// randomSpendable picks a random unspent output of addr that is not an
+// immature coinbase
+func randomSpendable(r *rand.Rand, ctx sdk.Context, k keeper.Keeper, addr string) (types.UTXO, bool) {
+	res, err := k.UTXOsByAddress(ctx, &types.QueryUTXOsByAddressRequest{
+		Address:    addr,
+		Pagination: &query.PageRequest{Limit: 20},
+	})
+	if err != nil {
+		return types.UTXO{}, false
+	}
+
+	maturity := k.GetParams(ctx).CoinbaseMaturity
+	var spendable []types.UTXO
+	for _, utxo := range res.Utxos {
+		if len(utxo.ScriptPubkey) > 0 {
+			continue
+		}
+		if utxo.Coinbase && ctx.BlockHeight()-utxo.BlockHeight < maturity {
+			continue
+		}
+		spendable = append(spendable, utxo)
+	}
+	if len(spendable) == 0 {
+		return types.UTXO{}, false
+	}
+	return spendable[r.Intn(len(spendable))], true
+}
+
+// signInput signs the only input of msg, spending an output of amount locked
+// to owner, the way the wallet does
+func signInput(msg *types.MsgSendUTXO, chainID string, owner address.Address, amount string, privKey []byte) error {
+	key, err := crypto.ToECDSA(privKey)
+	if err != nil {
+		return err
+	}
+
+	tx := &sighash.Tx{ChainID: chainID, Fee: msg.Fee, LockTime: msg.LockTime}
+	for _, input := range msg.Inputs {
+		tx.Inputs = append(tx.Inputs, sighash.Input{TxHash: input.PrevTxHash, Index: input.PrevOutputIndex, Sequence: input.Sequence})
+	}
+	for _, output := range msg.Outputs {
+		tx.Outputs = append(tx.Outputs, sighash.Output{Amount: output.Amount, Address: output.Address, ScriptPubkey: output.ScriptPubkey})
+	}
+
+	hash, err := sighash.Digest(tx, 0, sighash.AddressScript(owner), amount, sighash.All)
+	if err != nil {
+		return err
+	}
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		return err
+	}
+	msg.Inputs[0].ScriptSig, err = sighash.ScriptSig(signature, sighash.All, crypto.CompressPubkey(&key.PublicKey))
+	return err
+}
+
+// deliverForged delivers msg and reports an error if it is accepted
+func deliverForged(
+	r *rand.Rand,
+	app *baseapp.BaseApp,
+	ctx sdk.Context,
+	txGen client.TxConfig,
+	ak simulation.AccountKeeper,
+	from simtypes.Account,
+	chainID string,
+	msg sdk.Msg,
+) (simtypes.OperationMsg, error) {
+	msgType := sdk.MsgTypeURL(msg)
+	account := ak.GetAccount(ctx, from.Address)
+	tx, err := simtestutil.GenSignedMockTx(
+		r,
+		txGen,
+		[]sdk.Msg{msg},
+		sdk.NewCoins(),
+		simtestutil.DefaultGenTxGas,
+		chainID,
+		[]uint64{account.GetAccountNumber()},
+		[]uint64{account.GetSequence()},
+		from.PrivKey,
+	)
+	if err != nil {
+		return simtypes.NoOpMsg(types.ModuleName, msgType, "unable to generate mock tx"), err
+	}
+
+	if _, _, err := app.SimDeliver(txGen.TxEncoder(), tx); err == nil {
+		return simtypes.NoOpMsg(types.ModuleName, msgType, "forged message accepted"), fmt.Errorf("forged %s was accepted", msgType)
+	}
+	return simtypes.NewOperationMsg(msg, false, "forged message refused"), nil
+}
+
+func randomHashes(r *rand.Rand, n int) [][]byte {
+	hashes := make([][]byte, n)
+	for i := range hashes {
+		hashes[i] = randomBytes(r, 32)
+		hashes[i][0] &= 0x0f // Below the field modulus
+	}
+	return hashes
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	bz := make([]byte, n)
+	r.Read(bz)
+	return bz
+}
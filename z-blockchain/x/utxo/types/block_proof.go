@@ -0,0 +1,55 @@
+package types
+
+import (
+	"bytes"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ConsumedProofBlocks is how many blocks the solution hashes of accepted
+// mining proofs are kept for. A proof only counts at the height it names, or
+// as an uncle within MaxUncleDepth of it, so the hashes need only outlive the
+// blocks a replay could still be included in.
+const ConsumedProofBlocks = 100
+
+// Uncle proofs. At 0.5s blocks near-simultaneous solutions are common, and
+// the miners furthest from the proposers would lose every race. A valid proof
+// for one of the MaxUncleDepth heights before a block, building on the parent
+// of its height, may still be included in the block as an uncle, up to
+// MaxUnclesPerBlock of them. An uncle earns UncleReward, a share of the base
+// reward shrinking with its depth, and neither the hardware bonus nor fees.
+//
+// A miner's other solutions are not uncles: the miner of a height's winning
+// proof earns no uncle for it, and any other miner at most one. All blocks
+// together pay at most MaxUnclesPerHeight uncles for a height, so a height
+// mints at most 1 7/8 of its base reward however its solutions are spread.
+const (
+	MaxUncleDepth          = 6 // 3 seconds at the 0.5s block time
+	MaxUnclesPerBlock      = 2
+	MaxUnclesPerHeight     = 1
+	UncleRewardDenominator = 8
+)
+
+// UncleDepth returns how many blocks before height the height a proof solved
+// is: 0 for the block's own proof, 1 to MaxUncleDepth for an uncle
+func UncleDepth(height, proofHeight int64) int64 {
+	return height - proofHeight
+}
+
+// UncleReward returns the reward of an uncle at depth, (8-depth)/8 of the
+// base reward: 7/8 at depth 1 down to 2/8 at MaxUncleDepth
+func UncleReward(baseReward sdk.Int, depth int64) sdk.Int {
+	if depth < 1 || depth > MaxUncleDepth {
+		return sdk.ZeroInt()
+	}
+	return baseReward.MulRaw(UncleRewardDenominator - depth).QuoRaw(UncleRewardDenominator)
+}
+
+// Better reports whether p beats other for the reward of the block: the lower
+// solution hash wins, then the lower miner address
+func (p BlockProof) Better(other BlockProof) bool {
+	if c := bytes.Compare(p.SolutionHash, other.SolutionHash); c != 0 {
+		return c < 0
+	}
+	return p.MinerAddress < other.MinerAddress
+}
@@ -2,22 +2,42 @@ package types
 
 import (
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
 	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/msgservice"
 )
 
+// RegisterCodec registers the amino names used for legacy JSON signing (ledger).
+// Names must match the amino.name options in tx.proto.
 func RegisterCodec(cdc *codec.LegacyAmino) {
-	cdc.RegisterConcrete(&MsgSendUTXO{}, "utxo/SendUTXO", nil)
-	cdc.RegisterConcrete(&MsgSendShielded{}, "utxo/SendShielded", nil)
-	cdc.RegisterConcrete(&MsgSubmitMiningProof{}, "utxo/SubmitMiningProof", nil)
+	legacy.RegisterAminoMsg(cdc, &MsgSendUTXO{}, "utxo/SendUTXO")
+	legacy.RegisterAminoMsg(cdc, &MsgSendShielded{}, "utxo/SendShielded")
+	legacy.RegisterAminoMsg(cdc, &MsgShield{}, "utxo/Shield")
+	legacy.RegisterAminoMsg(cdc, &MsgDeshield{}, "utxo/Deshield")
+	legacy.RegisterAminoMsg(cdc, &MsgSubmitMiningProof{}, "utxo/SubmitMiningProof")
+	legacy.RegisterAminoMsg(cdc, &MsgSubmitAuxPow{}, "utxo/SubmitAuxPow")
+	legacy.RegisterAminoMsg(cdc, &MsgRespondMemoryChallenge{}, "utxo/RespondMemoryChallenge")
+	legacy.RegisterAminoMsg(cdc, &MsgRegisterHardware{}, "utxo/RegisterHardware")
+	legacy.RegisterAminoMsg(cdc, &MsgReviewHardwareFlag{}, "utxo/ReviewHardwareFlag")
+	legacy.RegisterAminoMsg(cdc, &MsgSpendTreasury{}, "utxo/SpendTreasury")
+	legacy.RegisterAminoMsg(cdc, &MsgSetPayoutSplit{}, "utxo/SetPayoutSplit")
 }
 
+// RegisterInterfaces registers the Msg implementations and the generated Msg service
 func RegisterInterfaces(registry cdctypes.InterfaceRegistry) {
 	registry.RegisterImplementations((*sdk.Msg)(nil),
 		&MsgSendUTXO{},
 		&MsgSendShielded{},
+		&MsgShield{},
+		&MsgDeshield{},
 		&MsgSubmitMiningProof{},
+		&MsgSubmitAuxPow{},
+		&MsgRespondMemoryChallenge{},
+		&MsgRegisterHardware{},
+		&MsgReviewHardwareFlag{},
+		&MsgSpendTreasury{},
+		&MsgSetPayoutSplit{},
 	)
 
 	msgservice.RegisterMsgServiceDesc(registry, &_Msg_serviceDesc)
@@ -0,0 +1,18 @@
+package types
+
+import "fmt"
+
+// ConflictError reports an input spending an output another transaction has
+// already spent
+type ConflictError struct {
+	TxHash      string // Of the contested output
+	OutputIndex uint32
+	SpentBy     string // Transaction that spent it
+}
+
+func (e *ConflictError) Error() string {
+	if e.SpentBy == "" {
+		return fmt.Sprintf("UTXO already spent: %s:%d", e.TxHash, e.OutputIndex)
+	}
+	return fmt.Sprintf("UTXO already spent: %s:%d by %s", e.TxHash, e.OutputIndex, e.SpentBy)
+}
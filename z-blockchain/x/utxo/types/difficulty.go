@@ -0,0 +1,108 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Difficulty algorithms of DifficultyParams
+const (
+	DifficultyAlgorithmRetarget = "retarget"
+	DifficultyAlgorithmLWMA     = "lwma"
+)
+
+// RetargetInterval is the number of blocks between retarget adjustments, and
+// the window their elapsed time is measured over. Block times are kept for
+// that window, which bounds the LWMA window too.
+const (
+	RetargetInterval    = 2016
+	MaxLWMAWindowBlocks = RetargetInterval
+)
+
+// DifficultyHistoryBlocks is the number of blocks the difficulty history
+// covers, about a day at the 0.5s block time. LWMA may retarget every block,
+// so older retargets are dropped.
+const DifficultyHistoryBlocks = 100 * RetargetInterval
+
+// NextRetargetHeight returns the first height after height the difficulty
+// may change at: the next multiple of RetargetInterval, or the next block
+// with LWMA
+func NextRetargetHeight(algorithm string, height int64) int64 {
+	if algorithm == DifficultyAlgorithmLWMA {
+		return height + 1
+	}
+	return (height/RetargetInterval + 1) * RetargetInterval
+}
+
+// LWMASolveTimeFactor bounds a solve time to that many target block times,
+// so one slow block, or a timestamp pushed ahead, cannot swing the average
+const LWMASolveTimeFactor = 6
+
+// LWMADifficulty returns the next difficulty from the blocks of an LWMA
+// window: times holds the Unix millisecond times of the N+1 blocks from the
+// one before the window to the last, difficulties the difficulty each of the
+// N blocks was solved at. Solve times are weighted by their position, the
+// latest N times the first, and bounded to between 1ms and
+// LWMASolveTimeFactor target block times:
+//
+//	next = avg(difficulties) * target * N(N+1)/2 / sum(i * solvetime_i)
+//
+// Blocks solved at the target keep the difficulty; hash power arriving or
+// leaving shows in the latest, heaviest solve times first. The result is at
+// least 1; it is 0 when the window is empty.
+func LWMADifficulty(times []int64, difficulties []uint64, targetMillis int64) uint64 {
+	n := len(difficulties)
+	if n == 0 || len(times) != n+1 || targetMillis <= 0 {
+		return 0
+	}
+
+	sum := new(big.Int)
+	var weighted int64
+	for i := 1; i <= n; i++ {
+		solveTime := times[i] - times[i-1]
+		if solveTime < 1 {
+			solveTime = 1
+		}
+		if limit := LWMASolveTimeFactor * targetMillis; solveTime > limit {
+			solveTime = limit
+		}
+		weighted += int64(i) * solveTime
+		sum.Add(sum, new(big.Int).SetUint64(difficulties[i-1]))
+	}
+
+	// sum/N * target * N(N+1)/2 = sum * target * (N+1) / 2
+	next := sum.Mul(sum, big.NewInt(targetMillis*int64(n+1)))
+	next.Quo(next, big.NewInt(2*weighted))
+	switch {
+	case next.Sign() == 0:
+		return 1
+	case !next.IsUint64():
+		return ^uint64(0)
+	}
+	return next.Uint64()
+}
+
+// MedianTime returns the median of block times, the earlier of the middle two
+// for an even count; 0 when there are none
+func MedianTime(times []int64) int64 {
+	if len(times) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)-1)/2]
+}
+
+// CheckSolutionTime checks a solution timestamp, in Unix milliseconds, against
+// the median time past of the blocks before and the block time: it must be
+// after the first and at most maxDriftMillis ahead of the second
+func CheckSolutionTime(timestamp, medianTimePast, blockTime, maxDriftMillis int64) error {
+	if timestamp <= medianTimePast {
+		return fmt.Errorf("solution time %d is not after the median time past %d", timestamp, medianTimePast)
+	}
+	if timestamp > blockTime+maxDriftMillis {
+		return fmt.Errorf("solution time %d is more than %dms ahead of the block time %d", timestamp, maxDriftMillis, blockTime)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+// lwmaChain simulates blocks solved at a steady hash power: a block takes
+// target * difficulty / hashPower, the time a miner with that hash power
+// needs on average
+type lwmaChain struct {
+	window       int
+	target       int64
+	times        []int64
+	difficulties []uint64
+	difficulty   uint64
+}
+
+func newLWMAChain(window int, target int64, difficulty uint64) *lwmaChain {
+	c := &lwmaChain{window: window, target: target, times: []int64{0}, difficulty: difficulty}
+	for i := 0; i < window; i++ {
+		c.mine(difficulty)
+	}
+	return c
+}
+
+// mine solves a block at hashPower and retargets from the window ending at it
+func (c *lwmaChain) mine(hashPower uint64) {
+	solveTime := int64(float64(c.target) * float64(c.difficulty) / float64(hashPower))
+	c.times = append(c.times, c.times[len(c.times)-1]+solveTime)
+	c.difficulties = append(c.difficulties, c.difficulty)
+
+	n := len(c.difficulties)
+	if n > c.window {
+		n = c.window
+	}
+	times := c.times[len(c.times)-n-1:]
+	difficulties := c.difficulties[len(c.difficulties)-n:]
+	c.difficulty = LWMADifficulty(times, difficulties, c.target)
+}
+
+func within(got, want uint64, tolerance float64) bool {
+	return math.Abs(float64(got)-float64(want)) <= tolerance*float64(want)
+}
+
+func TestLWMAKeepsTheDifficultyOnTarget(t *testing.T) {
+	c := newLWMAChain(45, 500, 1000000)
+	for i := 0; i < 100; i++ {
+		c.mine(1000000)
+	}
+	if c.difficulty != 1000000 {
+		t.Fatalf("difficulty drifted to %d at a steady hash power", c.difficulty)
+	}
+}
+
+func TestLWMAFollowsHashPowerSwings(t *testing.T) {
+	const window, target, hashPower = 45, 500, 1000000
+	c := newLWMAChain(window, target, hashPower)
+
+	// Ten times the hash power arrives: the difficulty rises without
+	// overshooting and settles within a few windows
+	for i := 0; i < window; i++ {
+		c.mine(10 * hashPower)
+		if c.difficulty > 11*hashPower {
+			t.Fatalf("block %d after the jump: difficulty overshot to %d", i, c.difficulty)
+		}
+	}
+	if c.difficulty < 5*hashPower {
+		t.Fatalf("difficulty only rose to %d a window after a 10x jump", c.difficulty)
+	}
+	for i := 0; i < 3*window; i++ {
+		c.mine(10 * hashPower)
+	}
+	if !within(c.difficulty, 10*hashPower, 0.05) {
+		t.Fatalf("difficulty settled at %d after a 10x jump, want about %d", c.difficulty, 10*hashPower)
+	}
+
+	// It leaves again: solve times are capped, so the drop is gradual, but
+	// the difficulty follows it down and settles without undershooting
+	for i := 0; i < 4*window; i++ {
+		c.mine(hashPower)
+		if c.difficulty < hashPower*9/10 {
+			t.Fatalf("block %d after the drop: difficulty undershot to %d", i, c.difficulty)
+		}
+	}
+	if !within(c.difficulty, hashPower, 0.05) {
+		t.Fatalf("difficulty settled at %d after the hash power left, want about %d", c.difficulty, hashPower)
+	}
+}
+
+func TestLWMABoundsSolveTimes(t *testing.T) {
+	const target = 500
+	difficulties := []uint64{1000, 1000, 1000}
+
+	// A timestamp far ahead counts as LWMASolveTimeFactor target times
+	far := LWMADifficulty([]int64{0, 500, 1000, 1000 + 1e9}, difficulties, target)
+	capped := LWMADifficulty([]int64{0, 500, 1000, 1000 + LWMASolveTimeFactor*target}, difficulties, target)
+	if far != capped {
+		t.Fatalf("a timestamp pushed ahead gave %d, a capped one %d", far, capped)
+	}
+
+	// Timestamps running backwards count as 1ms solves
+	if back := LWMADifficulty([]int64{1000, 500, 0, -500}, difficulties, target); back != 1000*target {
+		t.Fatalf("backwards timestamps gave %d, want %d", back, 1000*target)
+	}
+}
+
+func TestLWMAEdgeCases(t *testing.T) {
+	if got := LWMADifficulty([]int64{0}, nil, 500); got != 0 {
+		t.Errorf("empty window gave %d", got)
+	}
+	if got := LWMADifficulty([]int64{0, 500}, []uint64{1000, 1000}, 500); got != 0 {
+		t.Errorf("mismatched window gave %d", got)
+	}
+	if got := LWMADifficulty([]int64{0, 500}, []uint64{1000}, 0); got != 0 {
+		t.Errorf("zero target gave %d", got)
+	}
+	if got := LWMADifficulty([]int64{0, 3000}, []uint64{1}, 500); got != 1 {
+		t.Errorf("difficulty fell to %d, want at least 1", got)
+	}
+	if got := LWMADifficulty([]int64{0, 0}, []uint64{math.MaxUint64}, 500); got != math.MaxUint64 {
+		t.Errorf("difficulty wrapped to %d", got)
+	}
+}
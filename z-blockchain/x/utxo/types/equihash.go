@@ -3,26 +3,15 @@ package types
 import (
 	"crypto/sha256"
 	"encoding/binary"
-	"fmt"
 	"math/big"
 	
-	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"golang.org/x/crypto/blake2b"
+	"z-blockchain/x/utxo/equihash"
 )
 
-// Equihash parameters for 144_5 (zhash)
+// Equihash N and K are module parameters, EquihashParams, with the sizes
+// derived from them; mainnet runs 144_5 (zhash)
 const (
-	EquihashN = 144  // Parameter N
-	EquihashK = 5    // Parameter K
-	
-	// Derived parameters
-	CollisionBitLength = EquihashN / (EquihashK + 1)  // 144 / 6 = 24
-	CollisionByteLength = (CollisionBitLength + 7) / 8 // 3 bytes
-	HashLength = (EquihashK + 1) * CollisionByteLength // 18 bytes
-	SolutionWidth = (1 << EquihashK)                   // 32 indices
-	
-	// Memory requirements (approximately 1GB for 144_5)
-	ListLength = 1 << (CollisionBitLength + 1) // 2^25
-	
 	// ASIC resistance parameters
 	MinMemoryGB = 1  // Minimum 1GB memory requirement
 	MaxHashRate = 1000000 // Maximum reasonable hash rate (H/s)
@@ -31,7 +20,7 @@ const (
 // EquihashSolution represents a solution to the Equihash puzzle
 type EquihashSolution struct {
 	Nonce     uint64    `json:"nonce"`
-	Solution  []uint32  `json:"solution"`  // 32 indices for 144_5
+	Solution  []uint32  `json:"solution"`  // 2^K indices, 32 for 144_5
 	MixHash   []byte    `json:"mix_hash"`  // Intermediate hash for verification
 	Timestamp int64     `json:"timestamp"`
 }
@@ -56,6 +45,25 @@ type EquihashMiner struct {
 	ASICResistant bool   `json:"asic_resistant"`
 }
 
+// NewEquihashHeader builds the header a solution miner submits is checked
+// against, when included in the block after the one hashing to prevBlockHash.
+// The header commits to the miner in place of a merkle root: miners can build
+// it before the block exists, and a solution seen in the mempool cannot be
+// resubmitted from another address.
+func NewEquihashHeader(prevBlockHash []byte, miner string, timestamp int64, difficulty uint64, nonce uint64) *EquihashHeader {
+	minerHash := sha256.Sum256([]byte(miner))
+	
+	return &EquihashHeader{
+		Version:       1,
+		PrevBlockHash: prevBlockHash,
+		MerkleRoot:    minerHash[:],
+		Timestamp:     uint32(timestamp / 1000),
+		Bits:          CalculateEquihashDifficulty(EquihashTarget(difficulty)),
+		Nonce:         nonce,
+		Solution:      []uint32{}, // Will be filled from proof
+	}
+}
+
 // GenerateEquihashChallenge creates the challenge for Equihash solving
 func GenerateEquihashChallenge(header *EquihashHeader) []byte {
 	// Serialize header without solution
@@ -90,132 +98,50 @@ func GenerateEquihashChallenge(header *EquihashHeader) []byte {
 	return data
 }
 
-// VerifyEquihashSolution verifies an Equihash 144_5 solution
-func VerifyEquihashSolution(header *EquihashHeader, solution *EquihashSolution) bool {
+// VerifyEquihashSolution verifies an Equihash solution for the parameters p
+// against the header it was found for
+func VerifyEquihashSolution(p equihash.Params, header *EquihashHeader, solution *EquihashSolution) bool {
 	// Check solution length
-	if len(solution.Solution) != SolutionWidth {
+	if len(solution.Solution) != p.SolutionIndices() {
 		return false
 	}
 	
 	// Generate challenge
 	challenge := GenerateEquihashChallenge(header)
 	
-	// Verify Equihash solution
-	return verifyEquihash144_5(challenge, solution.Solution)
-}
-
-// verifyEquihash144_5 implements Equihash 144_5 verification
-func verifyEquihash144_5(challenge []byte, solution []uint32) bool {
-	// Implementation of Equihash 144_5 verification algorithm
-	// This is a simplified version - full implementation would use
-	// the complete Equihash algorithm with Wagner's algorithm
-	
-	// Check for duplicate indices
-	seen := make(map[uint32]bool)
-	for _, index := range solution {
-		if seen[index] {
-			return false // Duplicate index
-		}
-		seen[index] = true
-	}
-	
-	// Verify indices are in valid range
-	maxIndex := uint32(1 << 20) // Adjust based on actual Equihash parameters
-	for _, index := range solution {
-		if index >= maxIndex {
-			return false
-		}
-	}
-	
-	// Simplified collision verification
-	// Full implementation would verify the complete collision tree
-	return verifyCollisionTree(challenge, solution)
-}
-
-// verifyCollisionTree verifies the Equihash collision tree
-func verifyCollisionTree(challenge []byte, solution []uint32) bool {
-	// This is a simplified implementation
-	// Full Equihash verification requires implementing Wagner's algorithm
-	
-	// Generate hash values for each index
-	hashes := make([][]byte, len(solution))
-	for i, index := range solution {
-		hashes[i] = generateIndexHash(challenge, index)
-	}
-	
-	// Verify collision tree structure
-	return verifyTreeCollisions(hashes, 0, len(hashes))
+	// Verify the collision tree over the personalized BLAKE2b hashes
+	return equihash.VerifyIndices(p, challenge, solution.Solution) == nil
 }
 
-// generateIndexHash generates hash for a specific index
-func generateIndexHash(challenge []byte, index uint32) []byte {
-	data := append(challenge, make([]byte, 4)...)
-	binary.LittleEndian.PutUint32(data[len(challenge):], index)
-	
-	hash := sha256.Sum256(data)
-	return hash[:CollisionByteLength]
-}
-
-// verifyTreeCollisions verifies collisions in the tree structure
-func verifyTreeCollisions(hashes [][]byte, start, end int) bool {
-	if end-start <= 1 {
-		return true
-	}
-	
-	mid := (start + end) / 2
-	
-	// Check collision between left and right halves
-	leftHash := combineHashes(hashes[start:mid])
-	rightHash := combineHashes(hashes[mid:end])
-	
-	// Verify collision (first CollisionBitLength bits should match)
-	if !hasCollision(leftHash, rightHash) {
-		return false
-	}
-	
-	// Recursively verify subtrees
-	return verifyTreeCollisions(hashes, start, mid) && 
-		   verifyTreeCollisions(hashes, mid, end)
+// EncodeEquihashProof encodes a solution as the zk proof of a mining proof:
+// the nonce as 8 bytes little endian, then the minimally encoded indices
+func EncodeEquihashProof(nonce uint64, minimal []byte) []byte {
+	proof := make([]byte, 8, 8+len(minimal))
+	binary.LittleEndian.PutUint64(proof, nonce)
+	return append(proof, minimal...)
 }
 
-// combineHashes combines multiple hashes
-func combineHashes(hashes [][]byte) []byte {
-	if len(hashes) == 1 {
-		return hashes[0]
-	}
-	
-	combined := make([]byte, 0)
-	for _, hash := range hashes {
-		combined = append(combined, hash...)
+// EquihashSolutionHash is the BLAKE2b-256 hash of the challenge and the
+// minimally encoded solution, which must not exceed the target
+func EquihashSolutionHash(p equihash.Params, header *EquihashHeader, indices []uint32) ([]byte, error) {
+	solutionBytes, err := equihash.MinimalFromIndices(p, indices)
+	if err != nil {
+		return nil, err
 	}
 	
-	result := sha256.Sum256(combined)
-	return result[:CollisionByteLength]
+	hash := blake2b.Sum256(append(GenerateEquihashChallenge(header), solutionBytes...))
+	return hash[:], nil
 }
 
-// hasCollision checks if two hashes have a collision in the first bits
-func hasCollision(hash1, hash2 []byte) bool {
-	if len(hash1) < CollisionByteLength || len(hash2) < CollisionByteLength {
-		return false
+// EquihashTarget is the largest solution hash meeting difficulty,
+// (2^256-1)/difficulty; difficulty 1 accepts any solution
+func EquihashTarget(difficulty uint64) *big.Int {
+	if difficulty == 0 {
+		difficulty = 1
 	}
-	
-	// Check collision in first CollisionBitLength bits
-	for i := 0; i < CollisionByteLength-1; i++ {
-		if hash1[i] != hash2[i] {
-			return false
-		}
-	}
-	
-	// Check remaining bits in the last byte
-	remainingBits := CollisionBitLength % 8
-	if remainingBits > 0 {
-		mask := byte(0xFF << (8 - remainingBits))
-		if (hash1[CollisionByteLength-1] & mask) != (hash2[CollisionByteLength-1] & mask) {
-			return false
-		}
-	}
-	
-	return true
+	target := new(big.Int).Lsh(big.NewInt(1), 256)
+	target.Sub(target, big.NewInt(1))
+	return target.Quo(target, new(big.Int).SetUint64(difficulty))
 }
 
 // CalculateEquihashDifficulty calculates difficulty target for Equihash
@@ -283,10 +209,12 @@ func IsASICResistant(miner *EquihashMiner) bool {
 }
 
 // EstimateEquihashMemoryUsage estimates memory usage for Equihash mining
-func EstimateEquihashMemoryUsage() int {
+// with the parameters p
+func EstimateEquihashMemoryUsage(p equihash.Params) int {
 	// Equihash 144_5 requires approximately 1GB of memory
 	// This makes it ASIC resistant due to memory requirements
-	baseMemory := ListLength * HashLength // Base memory for hash table
+	hashLength := (p.K + 1) * ((p.CollisionBitLength() + 7) / 8)
+	baseMemory := int(p.ListLength()) * hashLength // Base memory for hash table
 	workingMemory := baseMemory / 4       // Additional working memory
 	
 	return (baseMemory + workingMemory) / (1024 * 1024) // Convert to MB
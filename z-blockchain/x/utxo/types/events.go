@@ -1,15 +1,25 @@
 package types
 
-// UTXO module event types
+// UTXO module event types. The UTXO lifecycle is emitted as the typed events
+// of events.proto.
 const (
 	EventTypeSendUTXO           = "send_utxo"
 	EventTypeSendShielded       = "send_shielded"
+	EventTypeShield             = "shield"
+	EventTypeDeshield           = "deshield"
 	EventTypeSubmitMiningProof  = "submit_mining_proof"
 	EventTypeMiningReward       = "mining_reward"
-	EventTypeUTXOSpent          = "utxo_spent"
-	EventTypeUTXOCreated        = "utxo_created"
 	EventTypeShieldedTx         = "shielded_transaction"
 	EventTypeDifficultyAdjust   = "difficulty_adjustment"
+	EventTypeMemoryChallenge    = "memory_challenge"
+	EventTypeMemoryAudit        = "memory_audit"
+	EventTypeBlockTimeWatchdog  = "block_time_watchdog"
+	EventTypeUTXOSetRoot        = "utxo_set_root"
+	EventTypeFeeReward          = "fee_reward"
+	EventTypeFeeBurn            = "fee_burn"
+	EventTypeRegisterHardware   = "register_hardware"
+	EventTypeHardwareFlag       = "hardware_flag"
+	EventTypeHardwareReview     = "hardware_flag_review"
 )
 
 // UTXO module attribute keys
@@ -33,4 +43,21 @@ const (
 	AttributeKeyBlockHeight     = "block_height"
 	AttributeKeyOldDifficulty   = "old_difficulty"
 	AttributeKeyNewDifficulty   = "new_difficulty"
+	AttributeKeySeed            = "seed"
+	AttributeKeyDeadlineHeight  = "deadline_height"
+	AttributeKeyMemoryBlocks    = "memory_blocks"
+	AttributeKeyAuditResult     = "audit_result"
+	AttributeKeyResponseTime    = "response_time_ms"
+	AttributeKeyMemoryFactor    = "memory_factor"
+	AttributeKeyWatchdogKind    = "kind"
+	AttributeKeyAverageBlockMs  = "average_block_ms"
+	AttributeKeyBreaches        = "consecutive_breaches"
+	AttributeKeyOldFloor        = "old_floor"
+	AttributeKeyNewFloor        = "new_floor"
+	AttributeKeyParamChange     = "param_change"
+	AttributeKeyRoot            = "root"
+	AttributeKeyDeviceClass     = "device_class"
+	AttributeKeyEligible        = "eligible"
+	AttributeKeyLatencyRatio    = "latency_ratio"
+	AttributeKeyUpheld          = "upheld"
 )
\ No newline at end of file
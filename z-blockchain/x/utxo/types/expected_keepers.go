@@ -0,0 +1,14 @@
+package types
+
+import sdk "github.com/cosmos/cosmos-sdk/types"
+
+// BankKeeper defines the expected bank keeper: the module mints the coins
+// behind coinbase outputs to its account, where they stay while the value
+// moves through the UTXO set and shielded pool. The treasury tax moves to the
+// treasury account, and back when governance spends it into the UTXO set.
+type BankKeeper interface {
+	MintCoins(ctx sdk.Context, moduleName string, amt sdk.Coins) error
+	SendCoinsFromModuleToModule(ctx sdk.Context, senderModule, recipientModule string, amt sdk.Coins) error
+	GetBalance(ctx sdk.Context, addr sdk.AccAddress, denom string) sdk.Coin
+	GetSupply(ctx sdk.Context, denom string) sdk.Coin
+}
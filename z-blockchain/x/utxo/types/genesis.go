@@ -1,6 +1,12 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/pow"
+)
 
 // DefaultIndex is the default global index
 const DefaultIndex uint64 = 1
@@ -17,6 +23,13 @@ func DefaultGenesis() *GenesisState {
 		HalvingInterval:     210000000, // Halving every 210M blocks
 		LastBlockHeight:     0,
 		HardwareAcceleration: true,
+		ShieldedPool:        "0",
+		Anchors:             []GenesisAnchor{},
+		BurnedFees:          "0",
+		MintedSupply:        "0",
+		TreasuryAccrued:     "0",
+		DataOutputs:         []DataOutput{},
+		Hardware:            HardwareRegistry(DefaultHardwareDevices),
 	}
 }
 
@@ -31,6 +44,7 @@ func (gs GenesisState) Validate() error {
 	}
 	
 	// Validate UTXOs
+	utxos := make(map[string]bool, len(gs.Utxos))
 	for _, utxo := range gs.Utxos {
 		if utxo.TxHash == "" {
 			return fmt.Errorf("UTXO tx_hash cannot be empty")
@@ -41,6 +55,11 @@ func (gs GenesisState) Validate() error {
 		if utxo.Amount == "" {
 			return fmt.Errorf("UTXO amount cannot be empty")
 		}
+		key := fmt.Sprintf("%s:%d", utxo.TxHash, utxo.OutputIndex)
+		if utxos[key] {
+			return fmt.Errorf("duplicate UTXO %s", key)
+		}
+		utxos[key] = true
 	}
 	
 	// Validate transactions
@@ -53,18 +72,114 @@ func (gs GenesisState) Validate() error {
 		}
 	}
 
+	// Validate shielded transactions; nullifiers and note commitments must be
+	// unique across all of them
+	nullifiers := make(map[string]bool)
+	commitments := make(map[string]bool)
+	for _, tx := range gs.ShieldedTransactions {
+		if tx.TxHash == "" {
+			return fmt.Errorf("shielded transaction hash cannot be empty")
+		}
+		for _, nullifier := range tx.Nullifiers {
+			if nullifiers[string(nullifier)] {
+				return fmt.Errorf("duplicate nullifier %x", nullifier)
+			}
+			nullifiers[string(nullifier)] = true
+		}
+		for _, commitment := range tx.Commitments {
+			if len(commitment) != 32 {
+				return fmt.Errorf("note commitment must be 32 bytes: %x", commitment)
+			}
+			if commitments[string(commitment)] {
+				return fmt.Errorf("duplicate note commitment %x", commitment)
+			}
+			commitments[string(commitment)] = true
+		}
+	}
+	
+	for _, anchor := range gs.Anchors {
+		if len(anchor.Root) != 32 {
+			return fmt.Errorf("anchor must be 32 bytes: %x", anchor.Root)
+		}
+	}
+	
+	classes := make(map[string]bool, len(gs.Hardware))
+	for _, class := range gs.Hardware {
+		if err := class.Validate(); err != nil {
+			return err
+		}
+		if classes[class.DeviceClass] {
+			return fmt.Errorf("duplicate device class %s", class.DeviceClass)
+		}
+		classes[class.DeviceClass] = true
+	}
+	for _, bonus := range gs.Params.HardwareBonusSchedule.Bonuses {
+		if !classes[bonus.DeviceClass] {
+			return fmt.Errorf("hardware bonus of device class %s not in the hardware registry", bonus.DeviceClass)
+		}
+	}
+	
+	miners := make(map[string]bool, len(gs.MinerStats))
+	for _, stats := range gs.MinerStats {
+		if err := stats.Validate(); err != nil {
+			return err
+		}
+		if miners[stats.Miner] {
+			return fmt.Errorf("duplicate mining statistics of miner %s", stats.Miner)
+		}
+		miners[stats.Miner] = true
+	}
+	statsClasses := make(map[string]bool, len(gs.HardwareStats))
+	for _, stats := range gs.HardwareStats {
+		if err := stats.Validate(); err != nil {
+			return err
+		}
+		if statsClasses[stats.DeviceClass] {
+			return fmt.Errorf("duplicate mining statistics of device class %s", stats.DeviceClass)
+		}
+		statsClasses[stats.DeviceClass] = true
+	}
+	
+	splits := make(map[string]bool, len(gs.PayoutSplits))
+	for _, split := range gs.PayoutSplits {
+		if err := split.Validate(); err != nil {
+			return err
+		}
+		if splits[split.Miner] {
+			return fmt.Errorf("duplicate payout split of miner %s", split.Miner)
+		}
+		splits[split.Miner] = true
+	}
+	
+	if err := validateGenesisAmount("shielded pool", gs.ShieldedPool); err != nil {
+		return err
+	}
+	if err := validateGenesisAmount("burned fees", gs.BurnedFees); err != nil {
+		return err
+	}
+	if err := validateGenesisAmount("minted supply", gs.MintedSupply); err != nil {
+		return err
+	}
+	if err := validateGenesisAmount("treasury accrued", gs.TreasuryAccrued); err != nil {
+		return err
+	}
+	if gs.MintedSupply != "" {
+		minted, _ := sdk.NewIntFromString(gs.MintedSupply)
+		if maxSupply := pow.DefaultEmission().MaxSupply; minted.BigInt().Cmp(maxSupply) > 0 {
+			return fmt.Errorf("minted supply %s exceeds the max supply %s", minted, maxSupply)
+		}
+	}
+
 	return gs.Params.Validate()
 }
 
-// GenesisState defines the utxo module's genesis state
-type GenesisState struct {
-	Params               Params                `json:"params"`
-	Utxos                []UTXO               `json:"utxos"`
-	Transactions         []UTXOTransaction    `json:"transactions"`
-	ShieldedTransactions []ShieldedTransaction `json:"shielded_transactions"`
-	Difficulty           uint64               `json:"difficulty"`
-	BlockReward          string               `json:"block_reward"`
-	HalvingInterval      int64                `json:"halving_interval"`
-	LastBlockHeight      int64                `json:"last_block_height"`
-	HardwareAcceleration bool                 `json:"hardware_acceleration"`
-}
\ No newline at end of file
+// validateGenesisAmount checks an optional non-negative integer amount
+func validateGenesisAmount(name, v string) error {
+	if v == "" {
+		return nil
+	}
+	if amount, ok := sdk.NewIntFromString(v); !ok || amount.IsNegative() {
+		return fmt.Errorf("invalid %s: %s", name, v)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// knownHardwareClasses are the device classes zChain knew before the hardware
+// registry; the registry of a new chain is seeded from them
+var knownHardwareClasses = []string{
+	// Consumer GPUs, ASIC resistant
+	"nvidia-rtx-3080", "nvidia-rtx-3090", "nvidia-rtx-4080", "nvidia-rtx-4090",
+	"amd-rx-6800-xt", "amd-rx-6900-xt", "amd-rx-7800-xt", "amd-rx-7900-xtx",
+	// Professional GPUs and FPGAs
+	"nvidia-a100", "nvidia-h100", "xilinx-fpga",
+	// Known Equihash ASICs
+	"antminer-z9", "innosilicon-a9",
+}
+
+const maxDeviceClassLength = 64
+
+// DefaultHardwareDevices are the device classes eligible on a new chain
+var DefaultHardwareDevices = []string{
+	"nvidia-rtx-3080", "nvidia-rtx-3090", "nvidia-rtx-4080", "nvidia-rtx-4090",
+	"amd-rx-6800-xt", "amd-rx-6900-xt", "amd-rx-7800-xt", "amd-rx-7900-xtx",
+	"nvidia-a100", "nvidia-h100",
+}
+
+// HardwareRegistry returns the registry of a chain that accepts devices: the
+// known device classes, eligible when listed in devices and barred
+// otherwise, and the listed devices not known, eligible
+func HardwareRegistry(devices []string) []HardwareClass {
+	eligible := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		eligible[device] = true
+	}
+
+	registry := make([]HardwareClass, 0, len(knownHardwareClasses)+len(devices))
+	known := make(map[string]bool, len(knownHardwareClasses))
+	for _, class := range knownHardwareClasses {
+		registry = append(registry, HardwareClass{DeviceClass: class, Eligible: eligible[class]})
+		known[class] = true
+	}
+	for _, device := range devices {
+		if !known[device] {
+			registry = append(registry, HardwareClass{DeviceClass: device, Eligible: true})
+			known[device] = true
+		}
+	}
+	return registry
+}
+
+// DefaultHardwareBonusSchedule keeps the bonuses zChain paid before they were
+// governed, from 0.002 Z for an RTX 3080 to 0.015 Z for an FPGA, within a cap
+// of 0.05 Z, the initial block reward
+func DefaultHardwareBonusSchedule() HardwareBonusSchedule {
+	bonuses := []struct {
+		deviceClass     string
+		tenThousandthsZ int64
+	}{
+		{"nvidia-rtx-3080", 20},
+		{"nvidia-rtx-3090", 30},
+		{"nvidia-rtx-4080", 40},
+		{"nvidia-rtx-4090", 50},
+		{"amd-rx-6800-xt", 25},
+		{"amd-rx-6900-xt", 35},
+		{"amd-rx-7800-xt", 45},
+		{"amd-rx-7900-xtx", 55},
+		{"nvidia-a100", 50},
+		{"nvidia-h100", 100},
+		{"xilinx-fpga", 150},
+	}
+
+	schedule := HardwareBonusSchedule{MaxBonus: zAmount(500)}
+	for _, b := range bonuses {
+		schedule.Bonuses = append(schedule.Bonuses, HardwareBonus{DeviceClass: b.deviceClass, Bonus: zAmount(b.tenThousandthsZ)})
+	}
+	return schedule
+}
+
+// zAmount is an amount of ten-thousandths of a Z in base units, 10^-18 Z
+func zAmount(tenThousandths int64) string {
+	return sdk.NewInt(tenThousandths).Mul(sdk.NewIntWithDecimal(1, 14)).String()
+}
+
+// Bonus returns the bonus of a device class, zero for classes not listed
+func (s HardwareBonusSchedule) Bonus(deviceClass string) sdk.Int {
+	for _, b := range s.Bonuses {
+		if b.DeviceClass == deviceClass {
+			if bonus, ok := sdk.NewIntFromString(b.Bonus); ok {
+				return bonus
+			}
+		}
+	}
+	return sdk.ZeroInt()
+}
+
+// Validate checks the bonuses are well formed device classes, listed once,
+// and within the cap. Whether the classes are known is a question for the
+// registry, checked when the bonus is paid.
+func (s HardwareBonusSchedule) Validate() error {
+	maxBonus, ok := sdk.NewIntFromString(s.MaxBonus)
+	if !ok || maxBonus.IsNegative() {
+		return fmt.Errorf("invalid max hardware bonus: %s", s.MaxBonus)
+	}
+
+	listed := make(map[string]bool, len(s.Bonuses))
+	for _, b := range s.Bonuses {
+		if err := ValidateDeviceClass(b.DeviceClass); err != nil {
+			return err
+		}
+		if listed[b.DeviceClass] {
+			return fmt.Errorf("duplicate bonus of device class %s", b.DeviceClass)
+		}
+		listed[b.DeviceClass] = true
+
+		bonus, ok := sdk.NewIntFromString(b.Bonus)
+		if !ok || bonus.IsNegative() {
+			return fmt.Errorf("invalid bonus of device class %s: %s", b.DeviceClass, b.Bonus)
+		}
+		if bonus.GT(maxBonus) {
+			return fmt.Errorf("bonus of device class %s exceeds the max hardware bonus: %s > %s", b.DeviceClass, bonus, maxBonus)
+		}
+	}
+	return nil
+}
+
+// ValidateDeviceClass checks a device class is a name miners can submit: up
+// to 64 lowercase letters, digits and dashes
+func ValidateDeviceClass(deviceClass string) error {
+	if deviceClass == "" {
+		return fmt.Errorf("device class cannot be empty")
+	}
+	if len(deviceClass) > maxDeviceClassLength {
+		return fmt.Errorf("device class %s is longer than %d bytes", deviceClass, maxDeviceClassLength)
+	}
+	for _, c := range deviceClass {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return fmt.Errorf("device class %s may only hold lowercase letters, digits and dashes", deviceClass)
+		}
+	}
+	return nil
+}
+
+// HardwareBenchmarkHeader is the Equihash header the benchmark of a device
+// class is solved for: it commits to the class and the driver fingerprint,
+// so a benchmark attests to one pairing of them
+func HardwareBenchmarkHeader(deviceClass string, driverFingerprint []byte, nonce uint64) *EquihashHeader {
+	return NewEquihashHeader(driverFingerprint, "hardware/"+deviceClass, 0, 1, nonce)
+}
+
+// Validate checks the fields of a registry entry; the attestation is checked
+// against the Equihash parameters when it is registered
+func (c HardwareClass) Validate() error {
+	if err := ValidateDeviceClass(c.DeviceClass); err != nil {
+		return err
+	}
+	if fp := c.Attestation.DriverFingerprint; len(fp) != 0 && len(fp) != sha256.Size {
+		return fmt.Errorf("driver fingerprint of device class %s must be %d bytes", c.DeviceClass, sha256.Size)
+	}
+	return nil
+}
@@ -12,34 +12,176 @@ const (
 
 	// MemStoreKey defines the in-memory store key
 	MemStoreKey = "mem_utxo"
+
+	// TreasuryModuleName is the module account of the community treasury,
+	// which only governance spends from
+	TreasuryModuleName = "treasury"
 )
 
 var (
 	// UTXOKey is the key prefix for storing UTXO data
 	UTXOKey = []byte("utxo/")
 	
+	// UTXOByAddressKey is the key prefix indexing unspent outputs by address
+	UTXOByAddressKey = []byte("utxo_by_address/")
+	
+	// UTXOSetKey is the key prefix for the nodes of the UTXO set accumulator
+	UTXOSetKey = []byte("utxo_set/")
+	
+	// UTXOSetRootKey is the key prefix for the accumulator root of each height
+	UTXOSetRootKey = []byte("utxo_set_root/")
+	
 	// TransactionKey is the key prefix for storing transactions
 	TransactionKey = []byte("tx/")
 	
+	// TxLocationKey is the key prefix for the block and CometBFT transaction
+	// each UTXO transaction was included in
+	TxLocationKey = []byte("tx_location/")
+	
 	// ShieldedTxKey is the key prefix for storing shielded transactions
 	ShieldedTxKey = []byte("shielded_tx/")
 	
-	// NullifierKey is the key prefix for storing nullifiers
+	// NullifierKey is the key prefix for the nullifier set, sharded by the
+	// epoch of the height each nullifier was revealed at
 	NullifierKey = []byte("nullifier/")
 	
-	// CommitmentKey is the key prefix for storing commitments
+	// CommitmentKey is the key prefix for the position of each note commitment
+	// in the note commitment tree
 	CommitmentKey = []byte("commitment/")
 	
+	// ShieldedOutputKey is the key prefix for the shielded outputs, by big
+	// endian height and index within the block
+	ShieldedOutputKey = []byte("shielded_output/")
+	
+	// DataOutputKey is the key prefix for the data carrier outputs, by their
+	// data, then the transaction hash and big endian output index
+	DataOutputKey = []byte("data_output/")
+	
+	// NoteTreeKey is the key prefix for the nodes of the note commitment tree
+	NoteTreeKey = []byte("note_tree/")
+	
+	// AnchorKey is the key prefix for the note commitment tree roots blocks
+	// ended with, mapped to the first height each was the root at
+	AnchorKey = []byte("anchor/")
+	
+	// ShieldedPoolKey is the key for the total value held in the shielded pool
+	ShieldedPoolKey = []byte("shielded_pool")
+	
+	// ShieldedDepositKey is the key prefix for the value paid into the shielded
+	// pool by each CometBFT transaction of the block and not yet shielded
+	ShieldedDepositKey = []byte("shielded_deposit/")
+	
 	// DifficultyKey is the key for storing current mining difficulty
 	DifficultyKey = []byte("difficulty")
 	
+	// DifficultyRetargetKey is the key prefix for the difficulty history, by
+	// big endian height, over DifficultyHistoryBlocks
+	DifficultyRetargetKey = []byte("difficulty_retarget/")
+	
 	// BlockHeaderKey is the key prefix for storing block headers
 	BlockHeaderKey = []byte("block_header/")
 	
-	// MiningStatsKey is the key prefix for storing mining statistics
+	// MiningStatsKey is the key prefix for storing mining statistics, by
+	// miner
 	MiningStatsKey = []byte("mining_stats/")
+	
+	// MinerRankKey is the key prefix of the index of miners by total rewards,
+	// highest first, see MinerRankIndexKey
+	MinerRankKey = []byte("miner_rank/")
+	
+	// HardwareStatsKey is the key prefix for the mining statistics of each
+	// device class
+	HardwareStatsKey = []byte("hardware_stats/")
+	
+	// PayoutSplitKey is the key prefix for the payout splits, by miner
+	PayoutSplitKey = []byte("payout_split/")
+	
+	// MinerProfileKey is the key prefix for storing miner memory audit state
+	MinerProfileKey = []byte("miner_profile/")
+	
+	// MemoryChallengeKey is the key prefix for storing open memory challenges
+	MemoryChallengeKey = []byte("memory_challenge/")
+	
+	// BlockTimeKey is the key prefix for the time of each recent block, by big
+	// endian height, over the difficulty retarget window
+	BlockTimeKey = []byte("block_time/")
+	
+	// BlockTimeWatchdogKey is the key for storing the block time watchdog state
+	BlockTimeWatchdogKey = []byte("block_time_watchdog")
+	
+	// WatchdogEventKey is the key prefix for storing the watchdog history
+	WatchdogEventKey = []byte("watchdog_event/")
+	
+	// BlockFeesKey is the key for the fees collected in the current block
+	BlockFeesKey = []byte("block_fees")
+	
+	// BlockMinerKey is the key for the miner rewarded in the current block
+	BlockMinerKey = []byte("block_miner")
+	
+	// LastBlockHashKey is the key for the hash of the last block ended
+	LastBlockHashKey = []byte("last_block_hash")
+	
+	// BlockProofKey is the key for the best mining proof of the current block
+	BlockProofKey = []byte("block_proof")
+	
+	// ConsumedProofKey is the key prefix for the solution hashes of accepted
+	// mining proofs, by big endian height, kept for ConsumedProofBlocks
+	ConsumedProofKey = []byte("consumed_proof/")
+	
+	// UncleProofKey is the key prefix for the uncles of the current block, by
+	// solution hash
+	UncleProofKey = []byte("uncle_proof/")
+	
+	// BlockHashKey is the key prefix for the hashes of the recent blocks, by
+	// big endian height, which the uncles of later blocks build on
+	BlockHashKey = []byte("block_hash/")
+	
+	// BlockWinnerKey is the key prefix for the miners of the recent blocks'
+	// winning proofs, by big endian height, kept as long as BlockHashKey
+	BlockWinnerKey = []byte("block_winner/")
+	
+	// UncleCreditKey is the key prefix for the miners paid an uncle for a
+	// recent height, by big endian height and miner, kept as long as
+	// BlockHashKey
+	UncleCreditKey = []byte("uncle_credit/")
+	
+	// BurnedFeesKey is the key for the total of all burned fees
+	BurnedFeesKey = []byte("burned_fees")
+	
+	// MintedSupplyKey is the key for the total of all mining rewards minted
+	MintedSupplyKey = []byte("minted_supply")
+	
+	// TreasuryAccruedKey is the key for the total of the treasury tax paid
+	TreasuryAccruedKey = []byte("treasury_accrued")
+	
+	// UTXOCountKey is the key for the number of unspent outputs
+	UTXOCountKey = []byte("utxo_count")
+	
+	// NullifierCountKey is the key for the number of nullifiers revealed
+	NullifierCountKey = []byte("nullifier_count")
+	
+	// AuxPowTipKey is the key for the highest Altcoinchain block number of
+	// the merged mined headers accepted for blocks, capped one epoch past the
+	// MergedMiningAnchor param
+	AuxPowTipKey = []byte("aux_pow_tip")
+	
+	// HardwareClassKey is the key prefix for the hardware registry, by device
+	// class
+	HardwareClassKey = []byte("hardware/")
+	
+	// HardwareFlagKey is the key prefix for the miners flagged by their audit
+	// response profile, by miner
+	HardwareFlagKey = []byte("hardware_flag/")
 )
 
-func KeyPrefix(p string) []byte {
-	return []byte(p)
+func KeyPrefix(p []byte) []byte {
+	return p
+}
+
+// UTXOByAddressPrefix is the index prefix of the unspent outputs of addr. Keys
+// under it are the tx_hash:output_index keys of UTXOKey.
+func UTXOByAddressPrefix(addr string) []byte {
+	key := append([]byte{}, UTXOByAddressKey...)
+	key = append(key, addr...)
+	return append(key, '/')
 }
\ No newline at end of file
@@ -0,0 +1,230 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// MemoryBlockSize is the byte size of one block of the audit buffer
+	MemoryBlockSize = 32
+
+	// MaxMemoryOpenings bounds the size of a challenge response
+	MaxMemoryOpenings = 256
+
+	// MaxMemoryProofDepth allows buffers of up to 2^40 blocks
+	MaxMemoryProofDepth = 40
+
+	// MaxAuditMemoryScales bounds the doublings of the audit buffer
+	MaxAuditMemoryScales = 8
+
+	// MaxASICProfileAudits bounds the audits kept in a miner profile
+	MaxASICProfileAudits = 32
+)
+
+var memoryAuditDomain = []byte("z-memory-audit")
+
+// MemoryChallengeSeed derives a challenge seed nobody can know before the
+// challenge is issued
+func MemoryChallengeSeed(blockHash []byte, miner string, height int64) []byte {
+	var h [8]byte
+	binary.BigEndian.PutUint64(h[:], uint64(height))
+
+	hasher := sha256.New()
+	hasher.Write(memoryAuditDomain)
+	hasher.Write(blockHash)
+	hasher.Write([]byte(miner))
+	hasher.Write(h[:])
+	return hasher.Sum(nil)
+}
+
+// ChallengeMemoryBlocks draws the buffer size of a challenge from its seed:
+// memoryBlocks doubled 0 to scales times, so a miner cannot size its memory
+// to the challenge ahead of time
+func ChallengeMemoryBlocks(seed []byte, memoryBlocks uint64, scales uint32) uint64 {
+	hasher := sha256.New()
+	hasher.Write([]byte("size"))
+	hasher.Write(seed)
+	return memoryBlocks << (binary.LittleEndian.Uint64(hasher.Sum(nil)[:8]) % uint64(scales+1))
+}
+
+// ASICLatencyRatio compares the time per buffer block a miner took on the
+// largest buffers of its samples with the time on the smallest, averaging the
+// samples of each size. Hardware holding the whole buffer answers in time
+// proportional to its size, a ratio near 1; hardware that runs out of memory
+// recomputes evicted blocks and slows down far more. ok is false until the
+// samples cover two sizes.
+func ASICLatencyRatio(samples []AuditSample) (ratio sdk.Dec, ok bool) {
+	var smallest, largest uint64
+	for _, s := range samples {
+		if smallest == 0 || s.MemoryBlocks < smallest {
+			smallest = s.MemoryBlocks
+		}
+		if s.MemoryBlocks > largest {
+			largest = s.MemoryBlocks
+		}
+	}
+	if smallest == largest {
+		return sdk.ZeroDec(), false
+	}
+
+	small, large := meanResponseMillis(samples, smallest), meanResponseMillis(samples, largest)
+	return large.MulInt64(int64(smallest)).Quo(small.MulInt64(int64(largest))), true
+}
+
+// meanResponseMillis averages the responses to buffers of memoryBlocks,
+// counting each as at least a millisecond
+func meanResponseMillis(samples []AuditSample, memoryBlocks uint64) sdk.Dec {
+	var total, n int64
+	for _, s := range samples {
+		if s.MemoryBlocks == memoryBlocks {
+			millis := s.ResponseMillis
+			if millis < 1 {
+				millis = 1
+			}
+			total += millis
+			n++
+		}
+	}
+	return sdk.NewDec(total).QuoInt64(n)
+}
+
+// The audit buffer is filled balloon style: every block hashes its predecessor
+// and one earlier block chosen by the predecessor's content, so the whole
+// buffer has to stay in memory to be filled at full speed.
+//
+//	block[0] = H(domain || seed)
+//	block[i] = H(seed || i || block[i-1] || block[dep(i)])
+
+// MemoryBlockZero returns the first block of the audit buffer
+func MemoryBlockZero(seed []byte) []byte {
+	hasher := sha256.New()
+	hasher.Write(memoryAuditDomain)
+	hasher.Write(seed)
+	return hasher.Sum(nil)
+}
+
+// MemoryBlock computes block index from its predecessor and its dependency
+func MemoryBlock(seed []byte, index uint64, prev, dep []byte) []byte {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], index)
+
+	hasher := sha256.New()
+	hasher.Write(seed)
+	hasher.Write(idx[:])
+	hasher.Write(prev)
+	hasher.Write(dep)
+	return hasher.Sum(nil)
+}
+
+// MemoryDependency returns the earlier block block index depends on. It is only
+// known once the predecessor has been computed.
+func MemoryDependency(seed []byte, index uint64, prev []byte) uint64 {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], index)
+
+	hasher := sha256.New()
+	hasher.Write([]byte("dep"))
+	hasher.Write(seed)
+	hasher.Write(idx[:])
+	hasher.Write(prev)
+	return binary.LittleEndian.Uint64(hasher.Sum(nil)[:8]) % index
+}
+
+// MemorySampleIndices picks the blocks a response must open. They depend on the
+// committed root so a miner cannot fill only the blocks that will be checked.
+func MemorySampleIndices(seed, root []byte, memoryBlocks uint64, samples uint32) []uint64 {
+	indices := make([]uint64, samples)
+	for k := range indices {
+		var n [4]byte
+		binary.LittleEndian.PutUint32(n[:], uint32(k))
+
+		hasher := sha256.New()
+		hasher.Write([]byte("sample"))
+		hasher.Write(seed)
+		hasher.Write(root)
+		hasher.Write(n[:])
+
+		// Block 0 has no predecessor to open
+		indices[k] = 1 + binary.LittleEndian.Uint64(hasher.Sum(nil)[:8])%(memoryBlocks-1)
+	}
+	return indices
+}
+
+// MemoryProofDepth returns the Merkle proof length for a buffer size, which
+// must be a power of two
+func MemoryProofDepth(memoryBlocks uint64) (int, error) {
+	if memoryBlocks < 2 || memoryBlocks&(memoryBlocks-1) != 0 {
+		return 0, fmt.Errorf("memory blocks must be a power of two: %d", memoryBlocks)
+	}
+	depth := bits.TrailingZeros64(memoryBlocks)
+	if depth > MaxMemoryProofDepth {
+		return 0, fmt.Errorf("memory buffer exceeds %d proof levels", MaxMemoryProofDepth)
+	}
+	return depth, nil
+}
+
+// VerifyMemoryBlockProof checks that block sits at index under root. Leaves are
+// hashed with a 0x00 prefix and inner nodes with 0x01, siblings ordered from the
+// leaf up.
+func VerifyMemoryBlockProof(root, block []byte, index uint64, proof [][]byte) bool {
+	node := sha256.Sum256(append([]byte{0x00}, block...))
+	current := node[:]
+
+	for _, sibling := range proof {
+		buf := make([]byte, 0, 1+2*MemoryBlockSize)
+		buf = append(buf, 0x01)
+		if index&1 == 0 {
+			buf = append(append(buf, current...), sibling...)
+		} else {
+			buf = append(append(buf, sibling...), current...)
+		}
+		next := sha256.Sum256(buf)
+		current = next[:]
+		index >>= 1
+	}
+
+	return index == 0 && bytes.Equal(current, root)
+}
+
+// VerifyMemoryOpening checks one sampled block: all three blocks must be in the
+// committed buffer and the sampled block must follow from the other two
+func VerifyMemoryOpening(seed, root []byte, memoryBlocks uint64, index uint64, opening MemoryOpening) error {
+	depth, err := MemoryProofDepth(memoryBlocks)
+	if err != nil {
+		return err
+	}
+
+	if opening.Index != index {
+		return fmt.Errorf("opening for block %d, expected block %d", opening.Index, index)
+	}
+	if len(opening.BlockProof) != depth || len(opening.PrevProof) != depth || len(opening.DepProof) != depth {
+		return fmt.Errorf("block %d: proofs must have %d levels", index, depth)
+	}
+
+	if !VerifyMemoryBlockProof(root, opening.Block, index, opening.BlockProof) {
+		return fmt.Errorf("block %d is not in the committed buffer", index)
+	}
+	if !VerifyMemoryBlockProof(root, opening.PrevBlock, index-1, opening.PrevProof) {
+		return fmt.Errorf("predecessor of block %d is not in the committed buffer", index)
+	}
+
+	dep := MemoryDependency(seed, index, opening.PrevBlock)
+	if !VerifyMemoryBlockProof(root, opening.DepBlock, dep, opening.DepProof) {
+		return fmt.Errorf("dependency %d of block %d is not in the committed buffer", dep, index)
+	}
+
+	if index == 1 && !bytes.Equal(opening.PrevBlock, MemoryBlockZero(seed)) {
+		return fmt.Errorf("block 0 does not match the challenge seed")
+	}
+	if !bytes.Equal(MemoryBlock(seed, index, opening.PrevBlock, opening.DepBlock), opening.Block) {
+		return fmt.Errorf("block %d does not follow from its predecessor and dependency", index)
+	}
+
+	return nil
+}
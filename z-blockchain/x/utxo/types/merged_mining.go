@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Merged mining lets Altcoinchain hash power mine zChain without separate
+// work, aux-PoW style. An Altcoinchain miner puts the MergedMiningCommitment
+// of a zChain mining proof's header challenge in the extra data of the
+// Altcoinchain header it mines; a solution meeting the zChain target is then
+// submitted with MsgSubmitAuxPow, the header standing in for the native
+// solution. Whether the header ever makes it into Altcoinchain does not
+// matter: the work is what zChain pays for.
+const (
+	// MergedMiningMagic starts the commitment in the extra data
+	MergedMiningMagic = "zmm\x01"
+
+	// MergedMiningDeviceClass is the device class merged mined proofs
+	// count under, in the mining statistics and the hardware bonus schedule
+	MergedMiningDeviceClass = "altcoinchain-ethash"
+
+	// DefaultMergedMiningFactor is about the Ethash hash rate of a GPU over
+	// its Equihash 144_5 solution rate
+	DefaultMergedMiningFactor = 1000000
+)
+
+// MergedMiningCommitment returns the 32 bytes of extra data an Altcoinchain
+// header merge mining challenge carries: MergedMiningMagic, then the first 28
+// bytes of the BLAKE2b-256 hash of the challenge
+func MergedMiningCommitment(challenge []byte) []byte {
+	hash := blake2b.Sum256(challenge)
+	return append([]byte(MergedMiningMagic), hash[:28]...)
+}
+
+// DecodeAuxPowHeader decodes the RLP encoded Altcoinchain header of a merged
+// mined proof
+func DecodeAuxPowHeader(bz []byte) (*ethtypes.Header, error) {
+	var header ethtypes.Header
+	if err := rlp.DecodeBytes(bz, &header); err != nil {
+		return nil, fmt.Errorf("invalid Altcoinchain header: %w", err)
+	}
+	if header.Number == nil || !header.Number.IsUint64() {
+		return nil, fmt.Errorf("invalid Altcoinchain header number %v", header.Number)
+	}
+	return &header, nil
+}
+
+// AuxPowSealHash returns the hash Ethash seals an Altcoinchain header with:
+// the Keccak-256 hash of its RLP encoded fields but the mix digest and the
+// nonce
+func AuxPowSealHash(header *ethtypes.Header) []byte {
+	fields := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra,
+	}
+	if header.BaseFee != nil {
+		fields = append(fields, header.BaseFee)
+	}
+	bz, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		// Only fails on types RLP cannot encode
+		panic(err)
+	}
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write(bz)
+	return h.Sum(nil)
+}
+
+// ScaleAuxPowResult multiplies the Ethash result of a merged mined proof by
+// factor, the Ethash hashes one native solution is worth, so it competes
+// with native solution hashes at their target. The result is 32 bytes, big
+// endian, and saturates at the largest hash.
+func ScaleAuxPowResult(result []byte, factor uint64) []byte {
+	scaled := new(big.Int).SetBytes(result)
+	scaled.Mul(scaled, new(big.Int).SetUint64(factor))
+	if scaled.BitLen() > 256 {
+		scaled.Sub(scaled.Lsh(big.NewInt(1), 256), big.NewInt(1))
+	}
+	return scaled.FillBytes(make([]byte, 32))
+}
@@ -1,19 +1,24 @@
 package types
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
-)
 
-const (
-	TypeMsgSendUTXO           = "send_utxo"
-	TypeMsgSendShielded       = "send_shielded"
-	TypeMsgSubmitMiningProof  = "submit_mining_proof"
+	"shared/address"
+	"shared/poseidon"
+	"z-blockchain/x/utxo/script"
+	"z-blockchain/x/utxo/zkproof"
 )
 
 var _ sdk.Msg = &MsgSendUTXO{}
 
-func NewMsgSendUTXO(creator string, inputs []TxInput, outputs []TxOutput, fee string, lockTime uint64, zkProof []byte) *MsgSendUTXO {
+func NewMsgSendUTXO(creator string, inputs []*TxInput, outputs []*TxOutput, fee string, lockTime uint64, zkProof []byte) *MsgSendUTXO {
 	return &MsgSendUTXO{
 		Creator:  creator,
 		Inputs:   inputs,
@@ -24,14 +29,6 @@ func NewMsgSendUTXO(creator string, inputs []TxInput, outputs []TxOutput, fee st
 	}
 }
 
-func (msg *MsgSendUTXO) Route() string {
-	return RouterKey
-}
-
-func (msg *MsgSendUTXO) Type() string {
-	return TypeMsgSendUTXO
-}
-
 func (msg *MsgSendUTXO) GetSigners() []sdk.AccAddress {
 	creator, err := sdk.AccAddressFromBech32(msg.Creator)
 	if err != nil {
@@ -45,6 +42,22 @@ func (msg *MsgSendUTXO) GetSignBytes() []byte {
 	return sdk.MustSortJSON(bz)
 }
 
+// Hash is the hash of the transaction msg creates. It leaves out the
+// scriptSigs and the zk proof.
+func (msg *MsgSendUTXO) Hash() string {
+	data := msg.Creator
+	for _, input := range msg.Inputs {
+		data += input.PrevTxHash + strconv.FormatUint(uint64(input.PrevOutputIndex), 10)
+	}
+	for _, output := range msg.Outputs {
+		data += output.Address + output.Amount
+	}
+	data += msg.Fee + strconv.FormatUint(msg.LockTime, 10)
+	
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
 func (msg *MsgSendUTXO) ValidateBasic() error {
 	_, err := sdk.AccAddressFromBech32(msg.Creator)
 	if err != nil {
@@ -59,8 +72,47 @@ func (msg *MsgSendUTXO) ValidateBasic() error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "transaction must have outputs")
 	}
 	
-	if msg.Fee == "" {
-		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "fee cannot be empty")
+	// Whether a scriptSig must be push only depends on the output it spends, which
+	// the keeper checks; here only its size is bounded
+	for i, input := range msg.Inputs {
+		if len(input.ScriptSig) > script.MaxStandardScriptSigSize {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "input %d scriptSig of %d bytes exceeds %d", i, len(input.ScriptSig), script.MaxStandardScriptSigSize)
+		}
+	}
+	
+	// Outputs are indexed by address, so only the canonical z1... form is accepted
+	for i, output := range msg.Outputs {
+		if _, err := address.ParseZChain(output.Address); err != nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid output %d address (%s)", i, err)
+		}
+		// Value paid to the shielded pool leaves the UTXO set, so no script
+		// could ever apply to it
+		if output.Address == ShieldedPoolAddress && len(output.ScriptPubkey) > 0 {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "output %d pays the shielded pool with a script", i)
+		}
+		// Data outputs carry no value, see validateDataOutputs
+		if script.Classify(output.ScriptPubkey) != script.NullData {
+			if amount, ok := sdk.NewIntFromString(output.Amount); !ok || !amount.IsPositive() {
+				return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid output %d amount: %q", i, output.Amount)
+			}
+		}
+		if len(output.ScriptPubkey) == 0 {
+			continue
+		}
+		if err := script.CheckStandardOutput(output.ScriptPubkey); err != nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid output %d script (%s)", i, err)
+		}
+		if err := checkScriptHashAddress(output); err != nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "output %d: %s", i, err)
+		}
+	}
+	if err := validateDataOutputs(msg.Outputs); err != nil {
+		return err
+	}
+	
+	// A negative fee would let the outputs exceed the inputs
+	if fee, ok := sdk.NewIntFromString(msg.Fee); !ok || fee.IsNegative() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid fee: %q", msg.Fee)
 	}
 	
 	return nil
@@ -79,14 +131,6 @@ func NewMsgSendShielded(creator string, nullifiers [][]byte, commitments [][]byt
 	}
 }
 
-func (msg *MsgSendShielded) Route() string {
-	return RouterKey
-}
-
-func (msg *MsgSendShielded) Type() string {
-	return TypeMsgSendShielded
-}
-
 func (msg *MsgSendShielded) GetSigners() []sdk.AccAddress {
 	creator, err := sdk.AccAddressFromBech32(msg.Creator)
 	if err != nil {
@@ -114,32 +158,346 @@ func (msg *MsgSendShielded) ValidateBasic() error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "nullifiers cannot be empty")
 	}
 	
-	if msg.Fee == "" {
-		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "fee cannot be empty")
+	if err := validateNullifiers(msg.Nullifiers); err != nil {
+		return err
+	}
+	
+	if len(msg.Anchor) != 32 {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "anchor must be 32 bytes, got %d", len(msg.Anchor))
+	}
+	
+	if err := validateCommitments(msg.Commitments); err != nil {
+		return err
+	}
+	
+	fee, ok := sdk.NewIntFromString(msg.Fee)
+	if !ok || fee.IsNegative() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid fee: %q", msg.Fee)
+	}
+	
+	valueBalance, ok := sdk.NewIntFromString(msg.ValueBalance)
+	if !ok {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid value balance: %q", msg.ValueBalance)
+	}
+	
+	// Value entering the pool comes from the transparent side, so nothing is
+	// left over for outputs or a fee
+	if valueBalance.IsNegative() {
+		if len(msg.TransparentOutputs) > 0 || !fee.IsZero() {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "a negative value balance pays no transparent outputs and no fee")
+		}
+		return nil
+	}
+	
+	released, err := validateTransparentOutputs(msg.TransparentOutputs)
+	if err != nil {
+		return err
+	}
+	
+	if !valueBalance.Equal(released.Add(fee)) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "value balance %s does not match transparent outputs %s plus fee %s", valueBalance, released, fee)
 	}
 	
 	return nil
 }
 
-var _ sdk.Msg = &MsgSubmitMiningProof{}
+// Hash is the hash of the shielded transaction msg creates. It leaves out the
+// zk proof and the memo.
+func (msg *MsgSendShielded) Hash() string {
+	data := msg.Creator + msg.Fee
+	for _, nullifier := range msg.Nullifiers {
+		data += hex.EncodeToString(nullifier)
+	}
+	for _, commitment := range msg.Commitments {
+		data += hex.EncodeToString(commitment)
+	}
+	data += msg.ValueBalance
+	for _, output := range msg.TransparentOutputs {
+		data += output.Address + output.Amount
+	}
+	
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
 
-func NewMsgSubmitMiningProof(creator string, zkProof []byte, publicInputs []byte, nonce uint64, difficulty uint64, hardwareId string) *MsgSubmitMiningProof {
-	return &MsgSubmitMiningProof{
-		Creator:      creator,
-		ZkProof:      zkProof,
-		PublicInputs: publicInputs,
-		Nonce:        nonce,
-		Difficulty:   difficulty,
-		HardwareId:   hardwareId,
+// validateTransparentOutputs checks outputs a shielded message pays and
+// returns their total. Value paid back into the pool could never be spent.
+func validateTransparentOutputs(outputs []*TxOutput) (sdk.Int, error) {
+	total := sdk.ZeroInt()
+	for i, output := range outputs {
+		if _, err := address.ParseZChain(output.Address); err != nil {
+			return total, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid transparent output %d address (%s)", i, err)
+		}
+		if output.Address == ShieldedPoolAddress {
+			return total, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "transparent output %d pays the shielded pool", i)
+		}
+		if len(output.ScriptPubkey) > 0 {
+			if err := script.CheckStandardOutput(output.ScriptPubkey); err != nil {
+				return total, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid transparent output %d script (%s)", i, err)
+			}
+		}
+		if err := checkScriptHashAddress(output); err != nil {
+			return total, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "transparent output %d: %s", i, err)
+		}
+		if script.Classify(output.ScriptPubkey) == script.NullData {
+			return total, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "transparent output %d carries data; data outputs go in a MsgSendUTXO", i)
+		}
+		amount, ok := sdk.NewIntFromString(output.Amount)
+		if !ok || !amount.IsPositive() {
+			return total, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid transparent output %d amount: %q", i, output.Amount)
+		}
+		total = total.Add(amount)
+	}
+	return total, nil
+}
+
+// checkScriptHashAddress requires an output paying to a script hash to be
+// addressed to the z1... form of the hash, so the address index lists the
+// outputs of a script under one address like those of an account
+func checkScriptHashAddress(output *TxOutput) error {
+	hash, ok := script.ScriptHashOf(output.ScriptPubkey)
+	if !ok {
+		return nil
+	}
+	addr, err := address.ParseZChain(output.Address)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(addr.Bytes(), hash) {
+		return fmt.Errorf("script hash %x paid under address %s", hash, output.Address)
+	}
+	return nil
+}
+
+// MaxDataOutputs is the most data carrier outputs a transaction may have
+const MaxDataOutputs = 1
+
+// validateDataOutputs checks the data carrier outputs, OP_RETURN <data>, of a
+// transaction. They never enter the UTXO set, so any value they carried would
+// be destroyed; the data is paid for by the fee rate on the message size.
+func validateDataOutputs(outputs []*TxOutput) error {
+	count := 0
+	for i, output := range outputs {
+		if script.Classify(output.ScriptPubkey) != script.NullData {
+			continue
+		}
+		count++
+		if count > MaxDataOutputs {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "at most %d data outputs", MaxDataOutputs)
+		}
+		if amount, ok := sdk.NewIntFromString(output.Amount); !ok || !amount.IsZero() {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "data output %d must carry no value, got %q", i, output.Amount)
+		}
 	}
+	return nil
 }
 
-func (msg *MsgSubmitMiningProof) Route() string {
-	return RouterKey
+// validateNullifiers requires each nullifier to be the canonical encoding of
+// a field element. The circuits see nullifiers as field elements, so another
+// encoding of the same element, such as N plus the modulus, would verify
+// against the same proof while the nullifier set took it for a new one.
+func validateNullifiers(nullifiers [][]byte) error {
+	if len(nullifiers) > zkproof.MaxSpends {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "at most %d nullifiers", zkproof.MaxSpends)
+	}
+	seen := make(map[string]bool, len(nullifiers))
+	for i, nullifier := range nullifiers {
+		if _, err := poseidon.Element(nullifier); err != nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "nullifier %d: %s", i, err)
+		}
+		if seen[string(nullifier)] {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "nullifier %d repeated", i)
+		}
+		seen[string(nullifier)] = true
+	}
+	return nil
 }
 
-func (msg *MsgSubmitMiningProof) Type() string {
-	return TypeMsgSubmitMiningProof
+// validateCommitments checks the note commitments a shielded message creates
+func validateCommitments(commitments [][]byte) error {
+	if len(commitments) > zkproof.MaxOutputs {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "at most %d commitments", zkproof.MaxOutputs)
+	}
+	for i, commitment := range commitments {
+		if len(commitment) != 32 {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "commitment %d must be 32 bytes, got %d", i, len(commitment))
+		}
+	}
+	return nil
+}
+
+var _ sdk.Msg = &MsgShield{}
+
+func (msg *MsgShield) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgShield) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+// Hash is the hash of the transaction msg creates, both its transparent and
+// its shielded side. It leaves out the scriptSigs, the zk proof and the memo.
+func (msg *MsgShield) Hash() string {
+	data := msg.Creator
+	for _, input := range msg.Inputs {
+		data += input.PrevTxHash + strconv.FormatUint(uint64(input.PrevOutputIndex), 10)
+	}
+	for _, output := range msg.Outputs {
+		data += output.Address + output.Amount
+	}
+	data += msg.Fee + strconv.FormatUint(msg.LockTime, 10) + msg.Value
+	for _, commitment := range msg.Commitments {
+		data += hex.EncodeToString(commitment)
+	}
+	
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+func (msg *MsgShield) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	if len(msg.Inputs) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "transaction must have inputs")
+	}
+	
+	for i, input := range msg.Inputs {
+		if len(input.ScriptSig) > script.MaxStandardScriptSigSize {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "input %d scriptSig of %d bytes exceeds %d", i, len(input.ScriptSig), script.MaxStandardScriptSigSize)
+		}
+	}
+	
+	if _, err := validateTransparentOutputs(msg.Outputs); err != nil {
+		return err
+	}
+	
+	if fee, ok := sdk.NewIntFromString(msg.Fee); !ok || fee.IsNegative() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid fee: %q", msg.Fee)
+	}
+	
+	if value, ok := sdk.NewIntFromString(msg.Value); !ok || !value.IsPositive() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid value: %q", msg.Value)
+	}
+	
+	if len(msg.Commitments) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "commitments cannot be empty")
+	}
+	if err := validateCommitments(msg.Commitments); err != nil {
+		return err
+	}
+	
+	if len(msg.ZkProof) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "zk proof cannot be empty")
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgDeshield{}
+
+func (msg *MsgDeshield) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgDeshield) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+// Hash is the hash of the shielded transaction msg creates. It leaves out the
+// zk proof and the memo.
+func (msg *MsgDeshield) Hash() string {
+	data := msg.Creator + msg.Fee
+	for _, nullifier := range msg.Nullifiers {
+		data += hex.EncodeToString(nullifier)
+	}
+	for _, commitment := range msg.Commitments {
+		data += hex.EncodeToString(commitment)
+	}
+	for _, output := range msg.Outputs {
+		data += output.Address + output.Amount
+	}
+	
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+// ValueBalance is the value msg takes out of the shielded pool: its outputs
+// and its fee
+func (msg *MsgDeshield) ValueBalance() (sdk.Int, error) {
+	fee, ok := sdk.NewIntFromString(msg.Fee)
+	if !ok || fee.IsNegative() {
+		return sdk.Int{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid fee: %q", msg.Fee)
+	}
+	released, err := validateTransparentOutputs(msg.Outputs)
+	if err != nil {
+		return sdk.Int{}, err
+	}
+	return released.Add(fee), nil
+}
+
+func (msg *MsgDeshield) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	if len(msg.Nullifiers) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "nullifiers cannot be empty")
+	}
+	if err := validateNullifiers(msg.Nullifiers); err != nil {
+		return err
+	}
+	
+	if len(msg.Anchor) != 32 {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "anchor must be 32 bytes, got %d", len(msg.Anchor))
+	}
+	
+	if err := validateCommitments(msg.Commitments); err != nil {
+		return err
+	}
+	
+	if len(msg.Outputs) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "transaction must have outputs")
+	}
+	if _, err := msg.ValueBalance(); err != nil {
+		return err
+	}
+	
+	if len(msg.ZkProof) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "zk proof cannot be empty")
+	}
+	
+	return nil
+}
+
+var _ sdk.Msg = &MsgSubmitMiningProof{}
+
+func NewMsgSubmitMiningProof(creator string, zkProof []byte, publicInputs []byte, nonce uint64, difficulty uint64, hardwareId string, timestamp int64, height int64, prevBlockHash []byte) *MsgSubmitMiningProof {
+	return &MsgSubmitMiningProof{
+		Creator:       creator,
+		ZkProof:       zkProof,
+		PublicInputs:  publicInputs,
+		Nonce:         nonce,
+		Difficulty:    difficulty,
+		HardwareId:    hardwareId,
+		Timestamp:     timestamp,
+		Height:        height,
+		PrevBlockHash: prevBlockHash,
+	}
 }
 
 func (msg *MsgSubmitMiningProof) GetSigners() []sdk.AccAddress {
@@ -173,45 +531,331 @@ func (msg *MsgSubmitMiningProof) ValidateBasic() error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "difficulty must be positive")
 	}
 	
+	if msg.Timestamp <= 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "solution timestamp must be positive")
+	}
+	
+	if msg.Height <= 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "proof height must be positive")
+	}
+	
+	// The first block has no parent
+	if len(msg.PrevBlockHash) != sha256.Size && (msg.Height != 1 || len(msg.PrevBlockHash) != 0) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "previous block hash must be %d bytes", sha256.Size)
+	}
+	
+	return nil
+}
+
+// MiningProof is the proof the message submits, mined by its creator
+func (msg *MsgSubmitMiningProof) MiningProof() MiningProof {
+	return MiningProof{
+		MinerAddress:  msg.Creator,
+		ZkProof:       msg.ZkProof,
+		PublicInputs:  msg.PublicInputs,
+		Nonce:         msg.Nonce,
+		Difficulty:    msg.Difficulty,
+		Timestamp:     msg.Timestamp,
+		HardwareId:    msg.HardwareId,
+		Height:        msg.Height,
+		PrevBlockHash: msg.PrevBlockHash,
+	}
+}
+
+var _ sdk.Msg = &MsgSubmitAuxPow{}
+
+func NewMsgSubmitAuxPow(creator string, auxPow []byte, timestamp int64, height int64, prevBlockHash []byte) *MsgSubmitAuxPow {
+	return &MsgSubmitAuxPow{
+		Creator:       creator,
+		AuxPow:        auxPow,
+		Timestamp:     timestamp,
+		Height:        height,
+		PrevBlockHash: prevBlockHash,
+	}
+}
+
+func (msg *MsgSubmitAuxPow) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgSubmitAuxPow) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSubmitAuxPow) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+	
+	if _, err := DecodeAuxPowHeader(msg.AuxPow); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	
+	if msg.Timestamp <= 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "solution timestamp must be positive")
+	}
+	
+	if msg.Height <= 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "proof height must be positive")
+	}
+	
+	// The first block has no parent
+	if len(msg.PrevBlockHash) != sha256.Size && (msg.Height != 1 || len(msg.PrevBlockHash) != 0) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "previous block hash must be %d bytes", sha256.Size)
+	}
+	
+	return nil
+}
+
+// MiningProof is the proof the message submits, mined by its creator on
+// Altcoinchain hardware
+func (msg *MsgSubmitAuxPow) MiningProof() MiningProof {
+	return MiningProof{
+		MinerAddress:  msg.Creator,
+		Timestamp:     msg.Timestamp,
+		HardwareId:    MergedMiningDeviceClass,
+		Height:        msg.Height,
+		PrevBlockHash: msg.PrevBlockHash,
+		AuxPow:        msg.AuxPow,
+	}
+}
+
+// MiningProofMsg is a message submitting a mining proof, natively mined or
+// merged mined
+type MiningProofMsg interface {
+	sdk.Msg
+	MiningProof() MiningProof
+}
+
+var _ sdk.Msg = &MsgRespondMemoryChallenge{}
+
+func NewMsgRespondMemoryChallenge(creator string, root []byte, openings []*MemoryOpening) *MsgRespondMemoryChallenge {
+	return &MsgRespondMemoryChallenge{
+		Creator:  creator,
+		Root:     root,
+		Openings: openings,
+	}
+}
+
+func (msg *MsgRespondMemoryChallenge) GetSigners() []sdk.AccAddress {
+	creator, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{creator}
+}
+
+func (msg *MsgRespondMemoryChallenge) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgRespondMemoryChallenge) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Creator)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid creator address (%s)", err)
+	}
+
+	if len(msg.Root) != MemoryBlockSize {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "buffer root must be %d bytes", MemoryBlockSize)
+	}
+
+	if len(msg.Openings) == 0 || len(msg.Openings) > MaxMemoryOpenings {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "response must open between 1 and %d blocks", MaxMemoryOpenings)
+	}
+
+	// Proof contents are checked against the challenge by the keeper
+	for i, opening := range msg.Openings {
+		if opening == nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "opening %d is empty", i)
+		}
+		if len(opening.Block) != MemoryBlockSize || len(opening.PrevBlock) != MemoryBlockSize || len(opening.DepBlock) != MemoryBlockSize {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "opening %d: blocks must be %d bytes", i, MemoryBlockSize)
+		}
+		for _, proof := range [][][]byte{opening.BlockProof, opening.PrevProof, opening.DepProof} {
+			if len(proof) > MaxMemoryProofDepth {
+				return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "opening %d: proof exceeds %d levels", i, MaxMemoryProofDepth)
+			}
+			for _, sibling := range proof {
+				if len(sibling) != MemoryBlockSize {
+					return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "opening %d: proof nodes must be %d bytes", i, MemoryBlockSize)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+var _ sdk.Msg = &MsgRegisterHardware{}
+
+func NewMsgRegisterHardware(registrar, deviceClass string, eligible bool, attestation HardwareAttestation) *MsgRegisterHardware {
+	return &MsgRegisterHardware{
+		Registrar:   registrar,
+		DeviceClass: deviceClass,
+		Eligible:    eligible,
+		Attestation: attestation,
+	}
+}
+
+func (msg *MsgRegisterHardware) GetSigners() []sdk.AccAddress {
+	registrar, err := sdk.AccAddressFromBech32(msg.Registrar)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{registrar}
+}
+
+func (msg *MsgRegisterHardware) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+// HardwareClass is the registry entry the message registers
+func (msg *MsgRegisterHardware) HardwareClass() HardwareClass {
+	return HardwareClass{
+		DeviceClass: msg.DeviceClass,
+		Eligible:    msg.Eligible,
+		Attestation: msg.Attestation,
+		Registrar:   msg.Registrar,
+	}
+}
+
+func (msg *MsgRegisterHardware) ValidateBasic() error {
+	_, err := sdk.AccAddressFromBech32(msg.Registrar)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid registrar address (%s)", err)
+	}
+
+	if err := msg.HardwareClass().Validate(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	// Barring a class needs no benchmark; the keeper verifies the solution
+	if msg.Eligible {
+		if len(msg.Attestation.DriverFingerprint) == 0 || len(msg.Attestation.BenchmarkProof) == 0 {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "an eligible device class needs a driver fingerprint and benchmark proof")
+		}
+		if msg.Attestation.SolutionsPerSecond == 0 {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "benchmark solve rate must be positive")
+		}
+	}
+
+	return nil
+}
+
+var _ sdk.Msg = &MsgReviewHardwareFlag{}
+
+func NewMsgReviewHardwareFlag(authority, miner string, upheld bool) *MsgReviewHardwareFlag {
+	return &MsgReviewHardwareFlag{
+		Authority: authority,
+		Miner:     miner,
+		Upheld:    upheld,
+	}
+}
+
+func (msg *MsgReviewHardwareFlag) GetSigners() []sdk.AccAddress {
+	authority, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{authority}
+}
+
+func (msg *MsgReviewHardwareFlag) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgReviewHardwareFlag) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Authority); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid authority address (%s)", err)
+	}
+	if _, err := sdk.AccAddressFromBech32(msg.Miner); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid miner address (%s)", err)
+	}
+
 	return nil
 }
 
-// Message types for the utxo module
-type MsgSendUTXO struct {
-	Creator  string     `json:"creator"`
-	Inputs   []TxInput  `json:"inputs"`
-	Outputs  []TxOutput `json:"outputs"`
-	Fee      string     `json:"fee"`
-	LockTime uint64     `json:"lock_time"`
-	ZkProof  []byte     `json:"zk_proof"`
+var _ sdk.Msg = &MsgSpendTreasury{}
+
+func NewMsgSpendTreasury(authority, recipient, amount string) *MsgSpendTreasury {
+	return &MsgSpendTreasury{
+		Authority: authority,
+		Recipient: recipient,
+		Amount:    amount,
+	}
 }
 
-type MsgSendUTXOResponse struct {
-	TxHash string `json:"tx_hash"`
+func (msg *MsgSpendTreasury) GetSigners() []sdk.AccAddress {
+	authority, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{authority}
 }
 
-type MsgSendShielded struct {
-	Creator       string   `json:"creator"`
-	Nullifiers    [][]byte `json:"nullifiers"`
-	Commitments   [][]byte `json:"commitments"`
-	ZkProof       []byte   `json:"zk_proof"`
-	EncryptedMemo []byte   `json:"encrypted_memo"`
-	Fee           string   `json:"fee"`
+func (msg *MsgSpendTreasury) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
 }
 
-type MsgSendShieldedResponse struct {
-	TxHash string `json:"tx_hash"`
+func (msg *MsgSpendTreasury) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Authority); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid authority address (%s)", err)
+	}
+	if _, err := sdk.AccAddressFromBech32(msg.Recipient); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid recipient address (%s)", err)
+	}
+	if amount, ok := sdk.NewIntFromString(msg.Amount); !ok || !amount.IsPositive() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid amount: %q", msg.Amount)
+	}
+
+	return nil
+}
+
+var _ sdk.Msg = &MsgSetPayoutSplit{}
+
+func NewMsgSetPayoutSplit(miner string, shares []PayoutShare) *MsgSetPayoutSplit {
+	return &MsgSetPayoutSplit{
+		Miner:  miner,
+		Shares: shares,
+	}
 }
 
-type MsgSubmitMiningProof struct {
-	Creator      string `json:"creator"`
-	ZkProof      []byte `json:"zk_proof"`
-	PublicInputs []byte `json:"public_inputs"`
-	Nonce        uint64 `json:"nonce"`
-	Difficulty   uint64 `json:"difficulty"`
-	HardwareId   string `json:"hardware_id"`
+func (msg *MsgSetPayoutSplit) GetSigners() []sdk.AccAddress {
+	miner, err := sdk.AccAddressFromBech32(msg.Miner)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{miner}
 }
 
-type MsgSubmitMiningProofResponse struct {
-	Success bool `json:"success"`
-}
\ No newline at end of file
+func (msg *MsgSetPayoutSplit) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg *MsgSetPayoutSplit) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Miner); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid miner address (%s)", err)
+	}
+
+	// No shares clears the split
+	if len(msg.Shares) == 0 {
+		return nil
+	}
+	split := PayoutSplit{Miner: msg.Miner, Shares: msg.Shares}
+	if err := split.Validate(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	return nil
+}
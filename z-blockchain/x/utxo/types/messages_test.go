@@ -0,0 +1,83 @@
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/poseidon"
+	"z-blockchain/x/utxo/script"
+)
+
+func TestValidateNullifiersRequiresCanonicalElements(t *testing.T) {
+	n := poseidon.Encode(big.NewInt(42))
+	aboveModulus := new(big.Int).Add(big.NewInt(42), poseidon.Modulus).FillBytes(make([]byte, poseidon.Size))
+
+	cases := []struct {
+		name       string
+		nullifiers [][]byte
+		valid      bool
+	}{
+		{"canonical", [][]byte{n}, true},
+		{"padded", [][]byte{append([]byte{0}, n...)}, false},
+		{"short", [][]byte{n[1:]}, false},
+		{"above modulus", [][]byte{aboveModulus}, false},
+		{"repeated", [][]byte{n, n}, false},
+		{"too many", [][]byte{n, poseidon.Encode(big.NewInt(1)), poseidon.Encode(big.NewInt(2)), poseidon.Encode(big.NewInt(3)), poseidon.Encode(big.NewInt(4))}, false},
+	}
+	for _, c := range cases {
+		err := validateNullifiers(c.nullifiers)
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+
+	// Every encoding rejected above names the same element as the canonical one
+	if !bytes.Equal(poseidon.Reduce(aboveModulus), n) || !bytes.Equal(poseidon.Reduce(append([]byte{0}, n...)), n) {
+		t.Fatal("non-canonical encodings do not reduce to the canonical nullifier")
+	}
+}
+
+func TestMsgSendUTXORefusesNegativeAmounts(t *testing.T) {
+	const payee = "z1w508d6qejxtdg4y5r3zarvary0c5xw7keklfzv"
+	data, err := script.NullDataScript([]byte("z chain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		outputs []*TxOutput
+		fee     string
+		valid   bool
+	}{
+		{"positive", []*TxOutput{{Address: payee, Amount: "990"}}, "10", true},
+		{"no fee", []*TxOutput{{Address: payee, Amount: "990"}}, "0", true},
+		{"data output without value", []*TxOutput{{Address: payee, Amount: "0", ScriptPubkey: data}}, "10", true},
+		{"negative fee", []*TxOutput{{Address: payee, Amount: "1010"}}, "-10", false},
+		{"empty fee", []*TxOutput{{Address: payee, Amount: "990"}}, "", false},
+		{"negative output", []*TxOutput{{Address: payee, Amount: "-1"}}, "10", false},
+		{"zero output", []*TxOutput{{Address: payee, Amount: "0"}}, "10", false},
+		{"not an amount", []*TxOutput{{Address: payee, Amount: "ten"}}, "10", false},
+	}
+	for _, c := range cases {
+		msg := &MsgSendUTXO{
+			Creator: sdk.AccAddress(make([]byte, 20)).String(),
+			Inputs:  []*TxInput{{PrevTxHash: "funding"}},
+			Outputs: c.outputs,
+			Fee:     c.fee,
+		}
+		err := msg.ValidateBasic()
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// minerRankRewardBytes holds any total reward: sdk.Int is at most 256 bits
+const minerRankRewardBytes = 32
+
+// MinerRankIndexKey is the key of a miner under MinerRankKey: its total
+// rewards as 32 big endian bytes, inverted so the highest sort first, then
+// the miner
+func MinerRankIndexKey(totalRewards sdk.Int, miner string) []byte {
+	key := totalRewards.BigInt().FillBytes(make([]byte, minerRankRewardBytes, minerRankRewardBytes+len(miner)))
+	for i := range key {
+		key[i] = ^key[i]
+	}
+	return append(key, miner...)
+}
+
+// MinerFromRankIndexKey returns the miner of a key under MinerRankKey
+func MinerFromRankIndexKey(key []byte) string {
+	return string(key[minerRankRewardBytes:])
+}
+
+// Validate checks the statistics of a miner
+func (s MinerStats) Validate() error {
+	if _, err := sdk.AccAddressFromBech32(s.Miner); err != nil {
+		return fmt.Errorf("invalid miner address %s: %w", s.Miner, err)
+	}
+	if rewards, ok := sdk.NewIntFromString(s.TotalRewards); !ok || rewards.IsNegative() {
+		return fmt.Errorf("invalid total rewards of miner %s: %s", s.Miner, s.TotalRewards)
+	}
+	return nil
+}
+
+// Validate checks the statistics of a device class
+func (s HardwareStats) Validate() error {
+	if err := ValidateDeviceClass(s.DeviceClass); err != nil {
+		return err
+	}
+	if rewards, ok := sdk.NewIntFromString(s.TotalRewards); !ok || rewards.IsNegative() {
+		return fmt.Errorf("invalid total rewards of device class %s: %s", s.DeviceClass, s.TotalRewards)
+	}
+	return nil
+}
@@ -3,8 +3,11 @@ package types
 import (
 	"fmt"
 	
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	"gopkg.in/yaml.v2"
+	
+	"z-blockchain/x/utxo/equihash"
 )
 
 var _ paramtypes.ParamSet = (*Params)(nil)
@@ -16,6 +19,44 @@ var (
 	KeyMaxDifficulty        = []byte("MaxDifficulty")
 	KeyHardwareAcceleration = []byte("HardwareAcceleration")
 	KeySupportedDevices     = []byte("SupportedDevices")
+	KeyCoinbaseMaturity     = []byte("CoinbaseMaturity")
+	KeyMinFeeRate           = []byte("MinFeeRate")
+	KeyDustLimit            = []byte("DustLimit")
+	KeyFeeBurnFraction      = []byte("FeeBurnFraction")
+	KeyAuditIntervalBlocks  = []byte("AuditIntervalBlocks")
+	KeyAuditWindowBlocks    = []byte("AuditWindowBlocks")
+	KeyAuditMemoryBlocks    = []byte("AuditMemoryBlocks")
+	KeyAuditSamples         = []byte("AuditSamples")
+	KeyTargetResponseMillis = []byte("TargetResponseMillis")
+	KeyAuditFailurePenalty  = []byte("AuditFailurePenalty")
+	KeyAuditRecoveryStep    = []byte("AuditRecoveryStep")
+	KeyMinMemoryFactor      = []byte("MinMemoryFactor")
+	KeyAuditMemoryScales    = []byte("AuditMemoryScales")
+	KeyASICLatencyRatio     = []byte("ASICLatencyRatio")
+	KeyASICProfileAudits    = []byte("ASICProfileAudits")
+	KeyTargetBlockMillis    = []byte("TargetBlockMillis")
+	KeyWatchdogWindow       = []byte("WatchdogWindowBlocks")
+	KeyBlockTimeThreshold   = []byte("BlockTimeThresholdMillis")
+	KeyWatchdogBreaches     = []byte("WatchdogBreachWindows")
+	KeyAutoAdjustFloor      = []byte("AutoAdjustFloor")
+	KeyFloorAdjustmentStep  = []byte("FloorAdjustmentStep")
+	KeyMaxFloorReduction    = []byte("MaxFloorReduction")
+	KeyMaxTxBytes           = []byte("MaxTxBytes")
+	KeyMaxTxInputs          = []byte("MaxTxInputs")
+	KeyMaxTxOutputs         = []byte("MaxTxOutputs")
+	KeyMaxTxProofs          = []byte("MaxTxProofs")
+	KeyDifficultyAlgorithm  = []byte("DifficultyAlgorithm")
+	KeyLWMAWindowBlocks     = []byte("LWMAWindowBlocks")
+	KeyMedianTimeBlocks     = []byte("MedianTimeBlocks")
+	KeyMaxFutureDrift       = []byte("MaxFutureDriftMillis")
+	KeyEquihashN            = []byte("EquihashN")
+	KeyEquihashK            = []byte("EquihashK")
+	KeyHardwareRegistrars   = []byte("HardwareRegistrars")
+	KeyHardwareBonuses      = []byte("HardwareBonusSchedule")
+	KeyPowAlgorithm         = []byte("PowAlgorithm")
+	KeyMergedMiningFactor   = []byte("MergedMiningFactor")
+	KeyTreasuryTax          = []byte("TreasuryTax")
+	KeyMergedMiningAnchor   = []byte("MergedMiningAnchor")
 )
 
 // ParamKeyTable the param key table for utxo module
@@ -31,14 +72,44 @@ func NewParams(
 	maxDifficulty uint64,
 	hardwareAcceleration bool,
 	supportedDevices []string,
+	coinbaseMaturity int64,
+	minFeeRate string,
+	dustLimit string,
+	feeBurnFraction string,
+	audit MemoryAuditParams,
+	watchdog BlockTimeWatchdogParams,
+	txLimits TxLimitParams,
+	difficulty DifficultyParams,
+	equihashParams EquihashParams,
+	hardwareRegistrars []string,
+	hardwareBonuses HardwareBonusSchedule,
+	powAlgorithm string,
+	mergedMiningFactor uint64,
+	treasuryTax string,
+	mergedMiningAnchor uint64,
 ) Params {
 	return Params{
-		BlockReward:          blockReward,
-		HalvingInterval:      halvingInterval,
-		MinDifficulty:        minDifficulty,
-		MaxDifficulty:        maxDifficulty,
-		HardwareAcceleration: hardwareAcceleration,
-		SupportedDevices:     supportedDevices,
+		BlockReward:             blockReward,
+		HalvingInterval:         halvingInterval,
+		MinDifficulty:           minDifficulty,
+		MaxDifficulty:           maxDifficulty,
+		HardwareAcceleration:    hardwareAcceleration,
+		SupportedDevices:        supportedDevices,
+		CoinbaseMaturity:        coinbaseMaturity,
+		MinFeeRate:              minFeeRate,
+		DustLimit:               dustLimit,
+		FeeBurnFraction:         feeBurnFraction,
+		MemoryAuditParams:       audit,
+		BlockTimeWatchdogParams: watchdog,
+		TxLimitParams:           txLimits,
+		DifficultyParams:        difficulty,
+		EquihashParams:          equihashParams,
+		HardwareRegistrars:      hardwareRegistrars,
+		HardwareBonusSchedule:   hardwareBonuses,
+		PowAlgorithm:            powAlgorithm,
+		MergedMiningFactor:      mergedMiningFactor,
+		TreasuryTax:             treasuryTax,
+		MergedMiningAnchor:      mergedMiningAnchor,
 	}
 }
 
@@ -50,14 +121,95 @@ func DefaultParams() Params {
 		1000000,             // Min difficulty
 		1000000000000,       // Max difficulty
 		true,                // Hardware acceleration enabled
-		[]string{
-			"nvidia-rtx-3080", "nvidia-rtx-3090", "nvidia-rtx-4080", "nvidia-rtx-4090",
-			"amd-rx-6800-xt", "amd-rx-6900-xt", "amd-rx-7800-xt", "amd-rx-7900-xtx",
-			"nvidia-a100", "nvidia-h100",
-		},
+		nil,                 // Superseded by the hardware registry
+		100,    // Coinbase maturity in blocks
+		"10",   // Min fee rate per byte
+		"5250", // Dust limit, three times the fee to spend a 175 byte input at the min fee rate
+		"0.5",  // Half the fees are burned, the rest go to the block's miner
+		DefaultMemoryAuditParams(),
+		DefaultBlockTimeWatchdogParams(),
+		DefaultTxLimitParams(),
+		DefaultDifficultyParams(),
+		DefaultEquihashParams(),
+		nil, // Registrars are added by governance
+		DefaultHardwareBonusSchedule(),
+		PowAlgorithmEquihash, // Rotated by governance if ASICs appear
+		DefaultMergedMiningFactor,
+		"0.05", // 5% of block rewards fund the community treasury
+		0,      // Set by governance to the Altcoinchain tip when merged mining starts
 	)
 }
 
+// DefaultMemoryAuditParams audit active miners hourly with a buffer of 1 to 4
+// GiB, at least the memory Equihash 144_5 needs. A GPU fills it in a few
+// seconds; hardware that has to recompute evicted blocks misses the target by
+// orders of magnitude. Six answered audits make a profile; taking four times
+// as long per block on the largest buffers as on the smallest flags it.
+func DefaultMemoryAuditParams() MemoryAuditParams {
+	return MemoryAuditParams{
+		AuditIntervalBlocks:  7200,    // 1 hour
+		AuditWindowBlocks:    240,     // 2 minutes
+		AuditMemoryBlocks:    1 << 25, // 1 GiB of 32 byte blocks
+		AuditSamples:         48,
+		TargetResponseMillis: 30000, // Includes the time to get the response into a block
+		AuditFailurePenalty:  "0.5",
+		AuditRecoveryStep:    "0.25",
+		MinMemoryFactor:      "0.1",
+		AuditMemoryScales:    2,
+		AsicLatencyRatio:     "4",
+		AsicProfileAudits:    6,
+	}
+}
+
+// DefaultBlockTimeWatchdogParams watch the 0.5s block time in 10 minute
+// windows. Half an hour above 0.6s lowers the difficulty floor by at most 10%
+// per step and 50% below the floor governance set.
+func DefaultBlockTimeWatchdogParams() BlockTimeWatchdogParams {
+	return BlockTimeWatchdogParams{
+		TargetBlockMillis:        500,
+		WatchdogWindowBlocks:     1200, // 10 minutes
+		BlockTimeThresholdMillis: 600,
+		WatchdogBreachWindows:    3,
+		AutoAdjustFloor:          true,
+		FloorAdjustmentStep:      "0.1",
+		MaxFloorReduction:        "0.5",
+	}
+}
+
+// DefaultTxLimitParams keep the verification of one transaction to a few
+// milliseconds, well inside a 0.5s block: 100 KB, 500 signatures and four
+// proofs.
+func DefaultTxLimitParams() TxLimitParams {
+	return TxLimitParams{
+		MaxTxBytes:   100000,
+		MaxTxInputs:  500,
+		MaxTxOutputs: 500,
+		MaxTxProofs:  4,
+	}
+}
+
+// DefaultDifficultyParams keep the 2016 block retarget. Switching to lwma
+// averages over the last 90 blocks, 45 seconds at the target block time.
+// Solution timestamps follow the median of the last 11 blocks, as in Bitcoin,
+// and may run 15 seconds, 30 blocks, ahead of the block time.
+func DefaultDifficultyParams() DifficultyParams {
+	return DifficultyParams{
+		DifficultyAlgorithm:  DifficultyAlgorithmRetarget,
+		LwmaWindowBlocks:     90,
+		MedianTimeBlocks:     11,
+		MaxFutureDriftMillis: 15000,
+	}
+}
+
+// DefaultEquihashParams are zhash, Equihash 144_5: a solver needs about 1 GiB
+// of memory, which the memory audits check miners hold
+func DefaultEquihashParams() EquihashParams {
+	return EquihashParams{
+		EquihashN: uint32(equihash.Zhash.N),
+		EquihashK: uint32(equihash.Zhash.K),
+	}
+}
+
 // ParamSetPairs get the params.ParamSet
 func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
 	return paramtypes.ParamSetPairs{
@@ -67,6 +219,44 @@ func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
 		paramtypes.NewParamSetPair(KeyMaxDifficulty, &p.MaxDifficulty, validateMaxDifficulty),
 		paramtypes.NewParamSetPair(KeyHardwareAcceleration, &p.HardwareAcceleration, validateHardwareAcceleration),
 		paramtypes.NewParamSetPair(KeySupportedDevices, &p.SupportedDevices, validateSupportedDevices),
+		paramtypes.NewParamSetPair(KeyCoinbaseMaturity, &p.CoinbaseMaturity, validateCoinbaseMaturity),
+		paramtypes.NewParamSetPair(KeyMinFeeRate, &p.MinFeeRate, validateNonNegativeInt),
+		paramtypes.NewParamSetPair(KeyDustLimit, &p.DustLimit, validateNonNegativeInt),
+		paramtypes.NewParamSetPair(KeyFeeBurnFraction, &p.FeeBurnFraction, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyAuditIntervalBlocks, &p.AuditIntervalBlocks, validatePositive),
+		paramtypes.NewParamSetPair(KeyAuditWindowBlocks, &p.AuditWindowBlocks, validatePositive),
+		paramtypes.NewParamSetPair(KeyAuditMemoryBlocks, &p.AuditMemoryBlocks, validateAuditMemoryBlocks),
+		paramtypes.NewParamSetPair(KeyAuditSamples, &p.AuditSamples, validateAuditSamples),
+		paramtypes.NewParamSetPair(KeyTargetResponseMillis, &p.TargetResponseMillis, validatePositive),
+		paramtypes.NewParamSetPair(KeyAuditFailurePenalty, &p.AuditFailurePenalty, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyAuditRecoveryStep, &p.AuditRecoveryStep, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyMinMemoryFactor, &p.MinMemoryFactor, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyAuditMemoryScales, &p.AuditMemoryScales, validateAuditMemoryScales),
+		paramtypes.NewParamSetPair(KeyASICLatencyRatio, &p.AsicLatencyRatio, validateASICLatencyRatio),
+		paramtypes.NewParamSetPair(KeyASICProfileAudits, &p.AsicProfileAudits, validateASICProfileAudits),
+		paramtypes.NewParamSetPair(KeyTargetBlockMillis, &p.TargetBlockMillis, validatePositive),
+		paramtypes.NewParamSetPair(KeyWatchdogWindow, &p.WatchdogWindowBlocks, validatePositive),
+		paramtypes.NewParamSetPair(KeyBlockTimeThreshold, &p.BlockTimeThresholdMillis, validatePositive),
+		paramtypes.NewParamSetPair(KeyWatchdogBreaches, &p.WatchdogBreachWindows, validateWatchdogBreaches),
+		paramtypes.NewParamSetPair(KeyAutoAdjustFloor, &p.AutoAdjustFloor, validateBool),
+		paramtypes.NewParamSetPair(KeyFloorAdjustmentStep, &p.FloorAdjustmentStep, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyMaxFloorReduction, &p.MaxFloorReduction, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyMaxTxBytes, &p.MaxTxBytes, validateMaxTxBytes),
+		paramtypes.NewParamSetPair(KeyMaxTxInputs, &p.MaxTxInputs, validateTxCountLimit),
+		paramtypes.NewParamSetPair(KeyMaxTxOutputs, &p.MaxTxOutputs, validateTxCountLimit),
+		paramtypes.NewParamSetPair(KeyMaxTxProofs, &p.MaxTxProofs, validateTxCountLimit),
+		paramtypes.NewParamSetPair(KeyDifficultyAlgorithm, &p.DifficultyAlgorithm, validateDifficultyAlgorithm),
+		paramtypes.NewParamSetPair(KeyLWMAWindowBlocks, &p.LwmaWindowBlocks, validateLWMAWindowBlocks),
+		paramtypes.NewParamSetPair(KeyMedianTimeBlocks, &p.MedianTimeBlocks, validateMedianTimeBlocks),
+		paramtypes.NewParamSetPair(KeyMaxFutureDrift, &p.MaxFutureDriftMillis, validatePositive),
+		paramtypes.NewParamSetPair(KeyEquihashN, &p.EquihashN, validateEquihashN),
+		paramtypes.NewParamSetPair(KeyEquihashK, &p.EquihashK, validateEquihashK),
+		paramtypes.NewParamSetPair(KeyHardwareRegistrars, &p.HardwareRegistrars, validateHardwareRegistrars),
+		paramtypes.NewParamSetPair(KeyHardwareBonuses, &p.HardwareBonusSchedule, validateHardwareBonusSchedule),
+		paramtypes.NewParamSetPair(KeyPowAlgorithm, &p.PowAlgorithm, validatePowAlgorithm),
+		paramtypes.NewParamSetPair(KeyMergedMiningFactor, &p.MergedMiningFactor, validateMergedMiningFactor),
+		paramtypes.NewParamSetPair(KeyTreasuryTax, &p.TreasuryTax, validateUnitFraction),
+		paramtypes.NewParamSetPair(KeyMergedMiningAnchor, &p.MergedMiningAnchor, validateMergedMiningAnchor),
 	}
 }
 
@@ -90,6 +280,51 @@ func (p Params) Validate() error {
 	if err := validateSupportedDevices(p.SupportedDevices); err != nil {
 		return err
 	}
+	if err := validateCoinbaseMaturity(p.CoinbaseMaturity); err != nil {
+		return err
+	}
+	if err := validateNonNegativeInt(p.MinFeeRate); err != nil {
+		return err
+	}
+	if err := validateNonNegativeInt(p.DustLimit); err != nil {
+		return err
+	}
+	if err := validateUnitFraction(p.FeeBurnFraction); err != nil {
+		return err
+	}
+	if err := p.MemoryAuditParams.Validate(); err != nil {
+		return err
+	}
+	if err := p.BlockTimeWatchdogParams.Validate(); err != nil {
+		return err
+	}
+	if err := p.TxLimitParams.Validate(); err != nil {
+		return err
+	}
+	if err := p.DifficultyParams.Validate(); err != nil {
+		return err
+	}
+	if err := p.EquihashParams.Validate(); err != nil {
+		return err
+	}
+	if err := validateHardwareRegistrars(p.HardwareRegistrars); err != nil {
+		return err
+	}
+	if err := p.HardwareBonusSchedule.Validate(); err != nil {
+		return err
+	}
+	if err := validatePowAlgorithm(p.PowAlgorithm); err != nil {
+		return err
+	}
+	if err := validateMergedMiningFactor(p.MergedMiningFactor); err != nil {
+		return err
+	}
+	if err := validateUnitFraction(p.TreasuryTax); err != nil {
+		return err
+	}
+	if err := validateMergedMiningAnchor(p.MergedMiningAnchor); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -160,25 +395,415 @@ func validateHardwareAcceleration(i interface{}) error {
 	return nil
 }
 
+// validateSupportedDevices accepts any list: the hardware registry has
+// superseded it
 func validateSupportedDevices(i interface{}) error {
+	if _, ok := i.([]string); !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	return nil
+}
+
+func validateHardwareRegistrars(i interface{}) error {
 	v, ok := i.([]string)
 	if !ok {
 		return fmt.Errorf("invalid parameter type: %T", i)
 	}
 	
-	if len(v) == 0 {
-		return fmt.Errorf("supported devices cannot be empty")
+	for _, registrar := range v {
+		if _, err := sdk.AccAddressFromBech32(registrar); err != nil {
+			return fmt.Errorf("invalid hardware registrar %s: %w", registrar, err)
+		}
+	}
+	
+	return nil
+}
+
+func validateHardwareBonusSchedule(i interface{}) error {
+	v, ok := i.(HardwareBonusSchedule)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	return v.Validate()
+}
+
+func validateCoinbaseMaturity(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v < 0 {
+		return fmt.Errorf("coinbase maturity cannot be negative: %d", v)
+	}
+	
+	return nil
+}
+
+func validateNonNegativeInt(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	amount, ok := sdk.NewIntFromString(v)
+	if !ok {
+		return fmt.Errorf("invalid integer: %s", v)
+	}
+	if amount.IsNegative() {
+		return fmt.Errorf("value cannot be negative: %s", v)
+	}
+	
+	return nil
+}
+
+func validatePositive(i interface{}) error {
+	v, ok := i.(int64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v <= 0 {
+		return fmt.Errorf("value must be positive: %d", v)
+	}
+	
+	return nil
+}
+
+func validateAuditMemoryBlocks(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	_, err := MemoryProofDepth(v)
+	return err
+}
+
+func validateAuditSamples(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 || v > MaxMemoryOpenings {
+		return fmt.Errorf("audit samples must be between 1 and %d: %d", MaxMemoryOpenings, v)
+	}
+	
+	return nil
+}
+
+func validateAuditMemoryScales(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v > MaxAuditMemoryScales {
+		return fmt.Errorf("audit memory scales cannot exceed %d: %d", MaxAuditMemoryScales, v)
+	}
+	
+	return nil
+}
+
+func validateASICLatencyRatio(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	ratio, err := sdk.NewDecFromStr(v)
+	if err != nil {
+		return fmt.Errorf("invalid ASIC latency ratio: %w", err)
+	}
+	if ratio.LT(sdk.OneDec()) {
+		return fmt.Errorf("ASIC latency ratio must be at least 1: %s", v)
+	}
+	
+	return nil
+}
+
+func validateASICProfileAudits(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v < 2 || v > MaxASICProfileAudits {
+		return fmt.Errorf("ASIC profile audits must be between 2 and %d: %d", MaxASICProfileAudits, v)
+	}
+	
+	return nil
+}
+
+func validateUnitFraction(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	fraction, err := sdk.NewDecFromStr(v)
+	if err != nil {
+		return fmt.Errorf("invalid fraction: %w", err)
+	}
+	
+	if fraction.IsNegative() || fraction.GT(sdk.OneDec()) {
+		return fmt.Errorf("fraction must be between 0 and 1: %s", v)
+	}
+	
+	return nil
+}
+
+// Validate checks the audit schedule, buffer sizes, de-rating fractions and
+// ASIC profile
+func (p MemoryAuditParams) Validate() error {
+	for _, v := range []int64{p.AuditIntervalBlocks, p.AuditWindowBlocks, p.TargetResponseMillis} {
+		if err := validatePositive(v); err != nil {
+			return err
+		}
+	}
+	if p.AuditWindowBlocks >= p.AuditIntervalBlocks {
+		return fmt.Errorf("audit window %d must be shorter than the audit interval %d", p.AuditWindowBlocks, p.AuditIntervalBlocks)
+	}
+	if err := validateAuditMemoryBlocks(p.AuditMemoryBlocks); err != nil {
+		return err
+	}
+	if err := validateAuditSamples(p.AuditSamples); err != nil {
+		return err
+	}
+	for _, v := range []string{p.AuditFailurePenalty, p.AuditRecoveryStep, p.MinMemoryFactor} {
+		if err := validateUnitFraction(v); err != nil {
+			return err
+		}
+	}
+	if err := validateAuditMemoryScales(p.AuditMemoryScales); err != nil {
+		return err
+	}
+	if _, err := MemoryProofDepth(p.AuditMemoryBlocks << p.AuditMemoryScales); err != nil {
+		return fmt.Errorf("largest audit buffer: %w", err)
+	}
+	if err := validateASICLatencyRatio(p.AsicLatencyRatio); err != nil {
+		return err
+	}
+	if err := validateASICProfileAudits(p.AsicProfileAudits); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
+func validateWatchdogBreaches(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 {
+		return fmt.Errorf("watchdog breach windows must be positive: %d", v)
+	}
+	
+	return nil
+}
+
+func validateBool(i interface{}) error {
+	_, ok := i.(bool)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	return nil
+}
+
+// Validate checks the watchdog windows, SLA threshold and adjustment bounds
+func (p BlockTimeWatchdogParams) Validate() error {
+	for _, v := range []int64{p.TargetBlockMillis, p.WatchdogWindowBlocks, p.BlockTimeThresholdMillis} {
+		if err := validatePositive(v); err != nil {
+			return err
+		}
+	}
+	if p.BlockTimeThresholdMillis < p.TargetBlockMillis {
+		return fmt.Errorf("block time threshold %dms must not be below the target %dms", p.BlockTimeThresholdMillis, p.TargetBlockMillis)
+	}
+	if err := validateWatchdogBreaches(p.WatchdogBreachWindows); err != nil {
+		return err
+	}
+	for _, v := range []string{p.FloorAdjustmentStep, p.MaxFloorReduction} {
+		if err := validateUnitFraction(v); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+func validateMaxTxBytes(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 {
+		return fmt.Errorf("max tx bytes must be positive: %d", v)
+	}
+	
+	return nil
+}
+
+func validateTxCountLimit(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 {
+		return fmt.Errorf("transaction count limit must be positive: %d", v)
 	}
 	
 	return nil
 }
 
-// Params defines the parameters for the utxo module
-type Params struct {
-	BlockReward          string   `json:"block_reward" yaml:"block_reward"`
-	HalvingInterval      int64    `json:"halving_interval" yaml:"halving_interval"`
-	MinDifficulty        uint64   `json:"min_difficulty" yaml:"min_difficulty"`
-	MaxDifficulty        uint64   `json:"max_difficulty" yaml:"max_difficulty"`
-	HardwareAcceleration bool     `json:"hardware_acceleration" yaml:"hardware_acceleration"`
-	SupportedDevices     []string `json:"supported_devices" yaml:"supported_devices"`
-}
\ No newline at end of file
+// Validate checks that every transaction limit leaves room for a transaction
+func (p TxLimitParams) Validate() error {
+	if err := validateMaxTxBytes(p.MaxTxBytes); err != nil {
+		return err
+	}
+	for _, v := range []uint32{p.MaxTxInputs, p.MaxTxOutputs, p.MaxTxProofs} {
+		if err := validateTxCountLimit(v); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+func validateDifficultyAlgorithm(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	switch v {
+	case DifficultyAlgorithmRetarget, DifficultyAlgorithmLWMA:
+		return nil
+	}
+	return fmt.Errorf("unknown difficulty algorithm %q, expected %s or %s", v, DifficultyAlgorithmRetarget, DifficultyAlgorithmLWMA)
+}
+
+func validatePowAlgorithm(i interface{}) error {
+	v, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	switch v {
+	case PowAlgorithmEquihash, PowAlgorithmCysicZK:
+		return nil
+	}
+	return fmt.Errorf("unknown PoW algorithm %q, expected %s or %s", v, PowAlgorithmEquihash, PowAlgorithmCysicZK)
+}
+
+func validateMergedMiningFactor(i interface{}) error {
+	_, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	// Any factor is valid; 0 disables merged mining
+	return nil
+}
+
+func validateMergedMiningAnchor(i interface{}) error {
+	_, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	// Any block number is valid; headers are only accepted near it
+	return nil
+}
+
+func validateLWMAWindowBlocks(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v < 2 || v > MaxLWMAWindowBlocks {
+		return fmt.Errorf("lwma window must be between 2 and %d blocks: %d", MaxLWMAWindowBlocks, v)
+	}
+	
+	return nil
+}
+
+func validateMedianTimeBlocks(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 || v > RetargetInterval {
+		return fmt.Errorf("median time blocks must be between 1 and %d: %d", RetargetInterval, v)
+	}
+	
+	return nil
+}
+
+// Validate checks the algorithm name, the LWMA and median time windows and
+// the future drift
+func (p DifficultyParams) Validate() error {
+	if err := validateDifficultyAlgorithm(p.DifficultyAlgorithm); err != nil {
+		return err
+	}
+	if err := validateLWMAWindowBlocks(p.LwmaWindowBlocks); err != nil {
+		return err
+	}
+	if err := validateMedianTimeBlocks(p.MedianTimeBlocks); err != nil {
+		return err
+	}
+	return validatePositive(p.MaxFutureDriftMillis)
+}
+
+func validateEquihashN(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 || v%8 != 0 || v > 512 {
+		return fmt.Errorf("equihash n must be a positive multiple of 8 up to 512: %d", v)
+	}
+	
+	return nil
+}
+
+func validateEquihashK(i interface{}) error {
+	v, ok := i.(uint32)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	
+	if v == 0 || v > 16 {
+		return fmt.Errorf("equihash k must be between 1 and 16: %d", v)
+	}
+	
+	return nil
+}
+
+// Equihash returns the parameters solutions are verified with
+func (p EquihashParams) Equihash() equihash.Params {
+	return equihash.Params{N: int(p.EquihashN), K: int(p.EquihashK)}
+}
+
+// Validate checks N and K each and that N splits into K+1 collisions of at
+// most 31 bits, which a change of one key alone can break
+func (p EquihashParams) Validate() error {
+	if err := validateEquihashN(p.EquihashN); err != nil {
+		return err
+	}
+	if err := validateEquihashK(p.EquihashK); err != nil {
+		return err
+	}
+	return p.Equihash().Validate()
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// PayoutSplitBps is the total of the shares of a payout split
+	PayoutSplitBps = 10000
+
+	// MaxPayoutShares bounds the coinbase outputs a payout adds
+	MaxPayoutShares = 8
+)
+
+// Validate checks the miner, and that the split has at most MaxPayoutShares
+// shares, of distinct addresses, summing to PayoutSplitBps
+func (s PayoutSplit) Validate() error {
+	if _, err := sdk.AccAddressFromBech32(s.Miner); err != nil {
+		return fmt.Errorf("invalid miner address %s: %w", s.Miner, err)
+	}
+	if len(s.Shares) == 0 || len(s.Shares) > MaxPayoutShares {
+		return fmt.Errorf("payout split must have 1 to %d shares, got %d", MaxPayoutShares, len(s.Shares))
+	}
+
+	addresses := make(map[string]bool, len(s.Shares))
+	var total uint32
+	for _, share := range s.Shares {
+		if _, err := sdk.AccAddressFromBech32(share.Address); err != nil {
+			return fmt.Errorf("invalid payout address %s: %w", share.Address, err)
+		}
+		if addresses[share.Address] {
+			return fmt.Errorf("duplicate payout address %s", share.Address)
+		}
+		addresses[share.Address] = true
+		if share.Bps == 0 || share.Bps > PayoutSplitBps {
+			return fmt.Errorf("share of payout address %s must be 1 to %d bps, got %d", share.Address, PayoutSplitBps, share.Bps)
+		}
+		total += share.Bps
+	}
+	if total != PayoutSplitBps {
+		return fmt.Errorf("payout shares must sum to %d bps, got %d", PayoutSplitBps, total)
+	}
+	return nil
+}
+
+// Amounts divides amount between the shares, in their order, rounding down;
+// the first share gets what rounding leaves
+func (s PayoutSplit) Amounts(amount sdk.Int) []sdk.Int {
+	amounts := make([]sdk.Int, len(s.Shares))
+	left := amount
+	for i, share := range s.Shares {
+		amounts[i] = amount.MulRaw(int64(share.Bps)).QuoRaw(PayoutSplitBps)
+		left = left.Sub(amounts[i])
+	}
+	if len(amounts) > 0 {
+		amounts[0] = amounts[0].Add(left)
+	}
+	return amounts
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/blake2b"
+
+	"z-blockchain/x/utxo/equihash"
+)
+
+// PoW algorithms of the PowAlgorithm param. Governance can rotate from one to
+// the other if ASICs appear for the one in force; proofs are always checked
+// with the algorithm of the block including them.
+const (
+	PowAlgorithmEquihash = "equihash"
+	PowAlgorithmCysicZK  = "cysic-zk"
+)
+
+// ProofOfWork is a mining algorithm. Every algorithm solves a challenge that
+// commits to the block a proof names, its miner and the difficulty, and
+// yields a solution hash no larger than the target of the difficulty; the
+// lowest hash wins the block.
+type ProofOfWork interface {
+	// PrepareChallenge returns the challenge the solution of proof solves
+	// at difficulty
+	PrepareChallenge(proof MiningProof, difficulty uint64) []byte
+
+	// VerifySolution checks the solution of proof solves challenge and
+	// returns its hash
+	VerifySolution(challenge []byte, proof MiningProof) ([]byte, error)
+
+	// Target returns the largest solution hash meeting difficulty
+	Target(difficulty uint64) *big.Int
+}
+
+// MeetsTarget reports whether a solution hash, read as a big endian number,
+// does not exceed target
+func MeetsTarget(solutionHash []byte, target *big.Int) bool {
+	return new(big.Int).SetBytes(solutionHash).Cmp(target) <= 0
+}
+
+// EquihashPoW is Equihash at the N and K of its params, 144_5 on mainnet.
+// The zk proof of a mining proof carries the nonce, 8 bytes little endian,
+// then the minimally encoded solution indices.
+type EquihashPoW struct {
+	Params equihash.Params
+}
+
+// PrepareChallenge returns the HeaderChallenge of the proof
+func (e EquihashPoW) PrepareChallenge(proof MiningProof, difficulty uint64) []byte {
+	return HeaderChallenge(proof, difficulty)
+}
+
+// VerifySolution checks the collision tree of the solution indices and
+// returns the BLAKE2b-256 hash of the challenge and the minimal solution
+func (e EquihashPoW) VerifySolution(challenge []byte, proof MiningProof) ([]byte, error) {
+	if len(proof.ZkProof) < 8 { // At least nonce
+		return nil, fmt.Errorf("invalid Equihash solution: proof too short")
+	}
+	indices, err := equihash.IndicesFromMinimal(e.Params, proof.ZkProof[8:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid Equihash solution: %w", err)
+	}
+	if len(indices) != e.Params.SolutionIndices() || equihash.VerifyIndices(e.Params, challenge, indices) != nil {
+		return nil, fmt.Errorf("invalid Equihash %d_%d solution", e.Params.N, e.Params.K)
+	}
+
+	minimal, err := equihash.MinimalFromIndices(e.Params, indices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Equihash solution: %w", err)
+	}
+	hash := blake2b.Sum256(append(append([]byte{}, challenge...), minimal...))
+	return hash[:], nil
+}
+
+// Target returns the target the header commits to, the compact bits of
+// EquihashTarget, so it rounds the same way for miners and the chain
+func (e EquihashPoW) Target(difficulty uint64) *big.Int {
+	return GetEquihashTarget(CalculateEquihashDifficulty(EquihashTarget(difficulty)))
+}
+
+// HeaderChallenge is the challenge every algorithm solves: the serialized
+// header of the block the proof names, committing to its parent, the miner,
+// the time, the difficulty and the nonce, see NewEquihashHeader
+func HeaderChallenge(proof MiningProof, difficulty uint64) []byte {
+	header := NewEquihashHeader(proof.PrevBlockHash, proof.MinerAddress, proof.Timestamp, difficulty, proof.Nonce)
+	return GenerateEquihashChallenge(header)
+}
+
+// CysicSolutionHash is the solution hash of a Cysic zk proof, the
+// BLAKE2b-256 hash of the challenge and the proof
+func CysicSolutionHash(challenge, zkProof []byte) []byte {
+	hash := blake2b.Sum256(append(append([]byte{}, challenge...), zkProof...))
+	return hash[:]
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"crypto/sha256"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"shared/address"
+	"z-blockchain/x/utxo/notetree"
+	"z-blockchain/x/utxo/zkproof"
+)
+
+// ShieldedPoolAddress is the transparent address value enters the shielded
+// pool through. Outputs paying it never enter the UTXO set; their value is
+// added to the pool for a MsgSendShielded of the same transaction to shield.
+// No key controls it.
+var ShieldedPoolAddress = func() string {
+	hash := sha256.Sum256([]byte("utxo/shielded_pool"))
+	addr, _ := address.FromBytes(hash[:address.Length])
+	return addr.ZChain()
+}()
+
+// ShieldedStatement returns the proof a shielded message carries and the
+// statement the keeper verifies it against. ok is false for other messages
+// and for messages ValidateBasic would reject.
+func ShieldedStatement(msg sdk.Msg) (proof []byte, in zkproof.PublicInputs, ok bool) {
+	switch msg := msg.(type) {
+	case *MsgSendShielded:
+		valueBalance, ok := sdk.NewIntFromString(msg.ValueBalance)
+		if !ok {
+			return nil, in, false
+		}
+		return msg.ZkProof, zkproof.PublicInputs{
+			Anchor:       msg.Anchor,
+			Nullifiers:   msg.Nullifiers,
+			Commitments:  msg.Commitments,
+			ValueBalance: valueBalance.BigInt(),
+		}, true
+	case *MsgShield:
+		value, ok := sdk.NewIntFromString(msg.Value)
+		if !ok {
+			return nil, in, false
+		}
+		return msg.ZkProof, zkproof.PublicInputs{
+			Anchor:       notetree.EmptyRoot(),
+			Commitments:  msg.Commitments,
+			ValueBalance: value.Neg().BigInt(),
+		}, true
+	case *MsgDeshield:
+		valueBalance, err := msg.ValueBalance()
+		if err != nil {
+			return nil, in, false
+		}
+		return msg.ZkProof, zkproof.PublicInputs{
+			Anchor:       msg.Anchor,
+			Nullifiers:   msg.Nullifiers,
+			Commitments:  msg.Commitments,
+			ValueBalance: valueBalance.BigInt(),
+		}, true
+	}
+	return nil, in, false
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Kinds of watchdog history entries
+const (
+	WatchdogBreach        = "breach"
+	WatchdogRecovered     = "recovered"
+	WatchdogFloorAdjusted = "floor_adjusted"
+	WatchdogFloorProposal = "floor_proposal"
+)
+
+// DifficultyFloorFor returns the floor that would bring blocks averaging
+// averageMillis back to the target, assuming block time scales with
+// difficulty. The result is at least 1.
+func DifficultyFloorFor(floor uint64, averageMillis int64, p BlockTimeWatchdogParams) uint64 {
+	if averageMillis <= 0 {
+		return floor
+	}
+
+	proposed := new(big.Int).SetUint64(floor)
+	proposed.Mul(proposed, big.NewInt(p.TargetBlockMillis))
+	proposed.Quo(proposed, big.NewInt(averageMillis))
+	if proposed.Sign() == 0 {
+		return 1
+	}
+	if !proposed.IsUint64() {
+		return floor
+	}
+	return proposed.Uint64()
+}
+
+// BoundedDifficultyFloor limits proposed to one adjustment step below floor and
+// to the largest reduction below the floor governance set
+func BoundedDifficultyFloor(floor, governanceFloor, proposed uint64, p BlockTimeWatchdogParams) uint64 {
+	step := sdk.MustNewDecFromStr(p.FloorAdjustmentStep)
+	reduction := sdk.MustNewDecFromStr(p.MaxFloorReduction)
+
+	lowest := sdk.OneDec().Sub(step).MulInt(sdk.NewIntFromUint64(floor)).Ceil().TruncateInt().Uint64()
+	if bound := sdk.OneDec().Sub(reduction).MulInt(sdk.NewIntFromUint64(governanceFloor)).Ceil().TruncateInt().Uint64(); bound > lowest {
+		lowest = bound
+	}
+
+	if proposed < lowest {
+		proposed = lowest
+	}
+	if proposed > floor {
+		proposed = floor
+	}
+	if proposed == 0 {
+		proposed = 1
+	}
+	return proposed
+}
@@ -0,0 +1,39 @@
+package utxo
+
+import (
+	"context"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"z-blockchain/x/utxo/keeper"
+	"z-blockchain/x/utxo/types"
+)
+
+// ConsensusVersion is the version of the utxo module's state machine. It is
+// bumped whenever the store layout or state transitions change, with a
+// migration from the previous version.
+const ConsensusVersion = 3
+
+// UpgradeName is the software upgrade that moves the utxo store to version 3
+const UpgradeName = "v3-hardware-registry"
+
+// RegisterMigrations registers the store migrations of the module with the
+// configurator, for the module manager to run on upgrade
+func RegisterMigrations(cfg module.Configurator, k keeper.Keeper) error {
+	m := keeper.NewMigrator(k)
+	if err := cfg.RegisterMigration(types.ModuleName, 1, m.Migrate1to2); err != nil {
+		return err
+	}
+	return cfg.RegisterMigration(types.ModuleName, 2, m.Migrate2to3)
+}
+
+// CreateUpgradeHandler returns the handler of UpgradeName. It runs the
+// registered migrations of every module whose version changed, so the store
+// is migrated in place at the upgrade height instead of by a dump and restart.
+func CreateUpgradeHandler(mm *module.Manager, configurator module.Configurator) upgradetypes.UpgradeHandler {
+	return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		return mm.RunMigrations(ctx, configurator, fromVM)
+	}
+}
@@ -0,0 +1,51 @@
+// Package workerpool runs independent proof verifications concurrently on a
+// bounded number of goroutines. Results come back in the order the jobs were
+// given, whatever order they finish in, so a caller deciding on them, as
+// ProcessProposal does, decides alike on every validator.
+package workerpool
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Pool bounds the jobs running at once. Its workers are shared by every Run,
+// so proofs verified for a proposal never oversubscribe the cores, however
+// many kinds a block holds. A job must not Run on its own pool.
+type Pool struct {
+	slots chan struct{}
+}
+
+// New returns a pool of workers goroutines, or one per core for workers of
+// zero or less
+func New(workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &Pool{slots: make(chan struct{}, workers)}
+}
+
+// Workers is the number of jobs the pool runs at once
+func (p *Pool) Workers() int {
+	return cap(p.slots)
+}
+
+// Run runs jobs on the pool and returns their errors in the order of jobs,
+// once all of them are done
+func (p *Pool) Run(jobs []func() error) []error {
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		p.slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, job func() error) {
+			defer func() {
+				<-p.slots
+				wg.Done()
+			}()
+			errs[i] = job()
+		}(i, job)
+	}
+	wg.Wait()
+	return errs
+}
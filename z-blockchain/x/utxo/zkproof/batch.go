@@ -0,0 +1,253 @@
+package zkproof
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+
+	"z-blockchain/x/utxo/proofcache"
+	"z-blockchain/x/utxo/workerpool"
+)
+
+// Batch collects the proofs of a block to verify them together. Each proof's
+// verification equation
+//
+//	e(A, B) = e(α, β) · e(L, γ) · e(C, δ)
+//
+// is raised to a random power and the equations are multiplied, so a single
+// pairing check, with one Miller loop per proof and three per verifying key,
+// covers many proofs. The proofs are split between the workers of a pool; a
+// share whose check fails falls back to checking its proofs one at a time to
+// tell the invalid ones apart.
+type Batch struct {
+	items []item
+}
+
+type item struct {
+	proof []byte
+	in    PublicInputs
+}
+
+// Add queues a proof of the statement in
+func (b *Batch) Add(proof []byte, in PublicInputs) {
+	b.items = append(b.items, item{proof: proof, in: in})
+}
+
+// Len is the number of proofs queued
+func (b *Batch) Len() int {
+	return len(b.items)
+}
+
+// Verify checks the queued proofs on the workers of pool and returns their
+// errors in the order they were added, nil for a valid proof. The results
+// are remembered, so Verify of the same proof and statement later returns
+// them without checking it again.
+func (b *Batch) Verify(pool *workerpool.Pool) []error {
+	errs := make([]error, len(b.items))
+	shares := pool.Workers()
+	if shares > len(b.items) {
+		shares = len(b.items)
+	}
+
+	jobs := make([]func() error, shares)
+	for s := range jobs {
+		var share []int
+		for i := s; i < len(b.items); i += shares {
+			share = append(share, i)
+		}
+		jobs[s] = func() error {
+			b.verifyShare(share, errs)
+			return nil
+		}
+	}
+	pool.Run(jobs)
+	return errs
+}
+
+// prepared is a decoded proof with its key and public inputs
+type prepared struct {
+	index  int
+	key    [32]byte
+	proof  *groth16bn254.Proof
+	vk     *groth16bn254.VerifyingKey
+	public fr.Vector
+}
+
+var errNotBatchable = errors.New("proof uses commitments")
+
+func (b *Batch) verifyShare(share []int, errs []error) {
+	var batch []prepared
+	for _, i := range share {
+		p, err := prepare(b.items[i])
+		if errors.Is(err, errNotBatchable) {
+			errs[i] = Verify(b.items[i].proof, b.items[i].in)
+			continue
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if result, ok := verified.Get(p.key); ok {
+			errs[i] = result.Err
+			continue
+		}
+		p.index = i
+		batch = append(batch, p)
+	}
+
+	if len(batch) > 1 && pairingCheck(batch) == nil {
+		for _, p := range batch {
+			remember(p.key, nil)
+		}
+		return
+	}
+	for _, p := range batch {
+		errs[p.index] = pairingCheck([]prepared{p})
+		remember(p.key, errs[p.index])
+	}
+}
+
+// prepare decodes a proof and looks up its key as Verify does
+func prepare(it item) (prepared, error) {
+	spends, outputs := len(it.in.Nullifiers), len(it.in.Commitments)
+	if spends > MaxSpends || outputs > MaxOutputs || spends+outputs == 0 {
+		return prepared{}, fmt.Errorf("%w: %d spends, %d outputs", ErrShape, spends, outputs)
+	}
+
+	key, err := verifyingKey(spends, outputs)
+	if err != nil {
+		return prepared{}, err
+	}
+	elements, err := it.in.Elements()
+	if err != nil {
+		return prepared{}, fmt.Errorf("%w: %s", ErrInvalidProof, err)
+	}
+
+	vk, ok := key.(*groth16bn254.VerifyingKey)
+	if !ok || len(vk.PublicAndCommitmentCommitted) > 0 {
+		return prepared{}, errNotBatchable
+	}
+	proof := new(groth16bn254.Proof)
+	if _, err := proof.ReadFrom(bytes.NewReader(it.proof)); err != nil {
+		return prepared{}, fmt.Errorf("%w: decode: %s", ErrInvalidProof, err)
+	}
+	if len(proof.Commitments) > 0 {
+		return prepared{}, errNotBatchable
+	}
+
+	public := make(fr.Vector, len(elements))
+	for i, e := range elements {
+		public[i].SetBigInt(e)
+	}
+	return prepared{key: statementKey(it.proof, elements), proof: proof, vk: vk, public: public}, nil
+}
+
+// pairingCheck checks the random linear combination of the verification
+// equations of batch
+func pairingCheck(batch []prepared) error {
+	type sums struct {
+		r    fr.Element
+		l, c bn254.G1Jac
+	}
+	groups := make(map[*groth16bn254.VerifyingKey]*sums)
+	var keys []*groth16bn254.VerifyingKey
+
+	P := make([]bn254.G1Affine, 0, len(batch)+3)
+	Q := make([]bn254.G2Affine, 0, len(batch)+3)
+	for _, p := range batch {
+		var r fr.Element
+		if _, err := r.SetRandom(); err != nil {
+			return err
+		}
+		var rb big.Int
+		r.BigInt(&rb)
+
+		var a bn254.G1Affine
+		a.ScalarMultiplication(&p.proof.Ar, &rb)
+		P = append(P, a)
+		Q = append(Q, p.proof.Bs)
+
+		g, ok := groups[p.vk]
+		if !ok {
+			g = new(sums)
+			g.l.FromAffine(&bn254.G1Affine{})
+			g.c.FromAffine(&bn254.G1Affine{})
+			groups[p.vk] = g
+			keys = append(keys, p.vk)
+		}
+		g.r.Add(&g.r, &r)
+
+		var l bn254.G1Jac
+		if _, err := l.MultiExp(p.vk.G1.K[1:], p.public, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+		l.AddMixed(&p.vk.G1.K[0])
+		l.ScalarMultiplication(&l, &rb)
+		g.l.AddAssign(&l)
+
+		var c bn254.G1Jac
+		c.FromAffine(&p.proof.Krs)
+		c.ScalarMultiplication(&c, &rb)
+		g.c.AddAssign(&c)
+	}
+
+	for _, vk := range keys {
+		g := groups[vk]
+		var rb big.Int
+		g.r.BigInt(&rb)
+
+		var alpha, l, c bn254.G1Affine
+		alpha.ScalarMultiplication(&vk.G1.Alpha, &rb)
+		alpha.Neg(&alpha)
+		l.FromJacobian(&g.l)
+		l.Neg(&l)
+		c.FromJacobian(&g.c)
+		c.Neg(&c)
+		P = append(P, alpha, l, c)
+		Q = append(Q, vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta)
+	}
+
+	ok, err := bn254.PairingCheck(P, Q)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// maxVerified bounds the results remembered between the checks of a proof,
+// the most recently used kept
+const maxVerified = 1 << 14
+
+var verified = proofcache.New(maxVerified)
+
+// remember records the result of verifying the proof of a statement, unless
+// it failed for a reason other than the proof itself
+func remember(key [32]byte, err error) {
+	if err == nil || errors.Is(err, ErrInvalidProof) {
+		verified.Add(key, proofcache.Result{Err: err})
+	}
+}
+
+// statementKey identifies a proof together with the statement it proves
+func statementKey(proof []byte, elements []*big.Int) [32]byte {
+	h := sha256.New()
+	h.Write(proof)
+	for _, e := range elements {
+		var bz [fr.Bytes]byte
+		e.FillBytes(bz[:])
+		h.Write(bz[:])
+	}
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
@@ -0,0 +1,173 @@
+// Package zkproof verifies the Groth16 proofs of shielded transactions over
+// BN254 with gnark. A proof shows that the spent notes are in the note
+// commitment tree under the anchor, that the nullifiers are theirs, that the
+// new commitments are well formed, and that the values balance: the notes
+// spent are worth the new notes plus the value balance.
+//
+// Each transaction shape, its numbers of spends and outputs, is its own
+// circuit with its own verifying key, embedded from keys/ as
+// shielded_<spends>x<outputs>.vk in gnark's binary encoding. A shape without a
+// key is rejected, so the pool stays closed until the keys of the trusted
+// setup are shipped. Shapes without spends shield transparent value; they
+// prove nothing against the anchor, which is the empty tree root.
+package zkproof
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+
+	"shared/poseidon"
+)
+
+//go:embed keys
+var keys embed.FS
+
+// Largest transaction shape a circuit exists for
+const (
+	MaxSpends  = 4
+	MaxOutputs = 4
+)
+
+var (
+	ErrShape        = errors.New("unsupported shielded transaction shape")
+	ErrNoKey        = errors.New("no verifying key for shielded transaction shape")
+	ErrInvalidProof = errors.New("invalid shielded proof")
+)
+
+// PublicInputs are the statement a proof is verified against. In the
+// circuits they are public in this order: the anchor, the nullifiers, the
+// commitments and the value balance. Nullifiers must be canonical field
+// elements, as the nullifier set stores them by their encoding; commitments
+// are reduced into the field as the note commitment tree does.
+type PublicInputs struct {
+	Anchor      []byte
+	Nullifiers  [][]byte
+	Commitments [][]byte
+
+	// Value leaving the pool, the fee included; negative when value enters
+	// it. In the circuits it is an element of the field, a negative balance
+	// being the modulus less its magnitude.
+	ValueBalance *big.Int
+}
+
+// Elements lays the inputs out as field elements
+func (in PublicInputs) Elements() ([]*big.Int, error) {
+	anchor, err := poseidon.Element(in.Anchor)
+	if err != nil {
+		return nil, fmt.Errorf("anchor: %w", err)
+	}
+	if in.ValueBalance == nil || in.ValueBalance.CmpAbs(maxValueBalance) > 0 {
+		return nil, fmt.Errorf("value balance out of range: %v", in.ValueBalance)
+	}
+	valueBalance := new(big.Int).Mod(in.ValueBalance, poseidon.Modulus)
+
+	elements := []*big.Int{anchor}
+	for i, bz := range in.Nullifiers {
+		nullifier, err := poseidon.Element(bz)
+		if err != nil {
+			return nil, fmt.Errorf("nullifier %d: %w", i, err)
+		}
+		elements = append(elements, nullifier)
+	}
+	for _, bz := range in.Commitments {
+		elements = append(elements, new(big.Int).SetBytes(poseidon.Reduce(bz)))
+	}
+	return append(elements, valueBalance), nil
+}
+
+// maxValueBalance bounds the magnitude of a value balance so positive and
+// negative ones never meet in the field
+var maxValueBalance = new(big.Int).Rsh(poseidon.Modulus, 1)
+
+var (
+	mu        sync.Mutex
+	verifying = make(map[string]groth16.VerifyingKey)
+)
+
+// Verify checks a proof, in gnark's binary encoding, of the statement in. The
+// result is remembered, as are those of a Batch, so verifying the same proof
+// and statement again, as a transaction checked for the mempool is when its
+// block is delivered, returns it at once.
+func Verify(proof []byte, in PublicInputs) error {
+	spends, outputs := len(in.Nullifiers), len(in.Commitments)
+	if spends > MaxSpends || outputs > MaxOutputs || spends+outputs == 0 {
+		return fmt.Errorf("%w: %d spends, %d outputs", ErrShape, spends, outputs)
+	}
+
+	vk, err := verifyingKey(spends, outputs)
+	if err != nil {
+		return err
+	}
+	elements, err := in.Elements()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProof, err)
+	}
+	key := statementKey(proof, elements)
+	if result, ok := verified.Get(key); ok {
+		return result.Err
+	}
+
+	err = verifyProof(proof, vk, elements)
+	remember(key, err)
+	return err
+}
+
+// verifyProof checks a proof of the statement elements against vk
+func verifyProof(proof []byte, vk groth16.VerifyingKey, elements []*big.Int) error {
+	p := groth16.NewProof(ecc.BN254)
+	if _, err := p.ReadFrom(bytes.NewReader(proof)); err != nil {
+		return fmt.Errorf("%w: decode: %s", ErrInvalidProof, err)
+	}
+
+	public, err := witness.New(ecc.BN254.ScalarField())
+	if err != nil {
+		return err
+	}
+	values := make(chan any, len(elements))
+	for _, e := range elements {
+		values <- e
+	}
+	close(values)
+	if err := public.Fill(len(elements), 0, values); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProof, err)
+	}
+
+	if err := groth16.Verify(p, vk, public); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProof, err)
+	}
+	return nil
+}
+
+// verifyingKey loads the embedded key of a shape once
+func verifyingKey(spends, outputs int) (groth16.VerifyingKey, error) {
+	name := fmt.Sprintf("keys/shielded_%dx%d.vk", spends, outputs)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if vk, ok := verifying[name]; ok {
+		return vk, nil
+	}
+
+	bz, err := keys.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d spends, %d outputs", ErrNoKey, spends, outputs)
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	if _, err := vk.ReadFrom(bytes.NewReader(bz)); err != nil {
+		return nil, fmt.Errorf("verifying key %s: %w", name, err)
+	}
+	if got, want := vk.NbPublicWitness(), spends+outputs+2; got != want {
+		return nil, fmt.Errorf("verifying key %s has %d public inputs, want %d", name, got, want)
+	}
+
+	verifying[name] = vk
+	return vk, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"z-core-wallet/wallet"
+)
+
+// HTTP Handlers
+
+func (ws *WalletService) getAddresses(w http.ResponseWriter, r *http.Request) {
+	unified, err := ws.wallet.UnifiedAddress(0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transparent": unified.Transparent.ZChain(),
+		"shielded":    unified.Shielded.String(),
+		"unified":     unified.String(),
+		"next_index":  ws.wallet.NextDiversifier(),
+	})
+}
+
+func (ws *WalletService) newDiversifiedAddress(w http.ResponseWriter, r *http.Request) {
+	index, unified, err := ws.wallet.NewDiversifiedAddress()
+	if errors.Is(err, wallet.ErrLocked) {
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"index":    index,
+		"shielded": unified.Shielded.String(),
+		"unified":  unified.String(),
+	})
+}
+
+func (ws *WalletService) detectReceiver(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Receiver string `json:"receiver"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	match, err := ws.wallet.DetectReceiver(req.Receiver)
+	switch {
+	case errors.Is(err, wallet.ErrLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case errors.Is(err, wallet.ErrForeignReceiver):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
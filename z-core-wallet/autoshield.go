@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"z-core-wallet/wallet"
+)
+
+// minAutoShieldInterval keeps a misconfigured job from spinning on the UTXO set
+const minAutoShieldInterval = time.Minute
+
+// AutoShieldConfig controls the background job moving transparent Z into the
+// shielded pool. It is off unless enabled explicitly.
+type AutoShieldConfig struct {
+	Enabled   bool
+	Threshold int64 // Shield once confirmed transparent Z exceeds this
+	Interval  time.Duration
+	Fee       int64
+}
+
+// DefaultAutoShieldConfig returns a disabled job that checks hourly
+func DefaultAutoShieldConfig() AutoShieldConfig {
+	return AutoShieldConfig{
+		Enabled:   false,
+		Threshold: 1000000,
+		Interval:  time.Hour,
+		Fee:       wallet.DefaultShieldingFee,
+	}
+}
+
+// LoadAutoShieldConfig applies WALLET_AUTOSHIELD_* overrides to the defaults
+func LoadAutoShieldConfig() AutoShieldConfig {
+	cfg := DefaultAutoShieldConfig()
+
+	cfg.Enabled = os.Getenv("WALLET_AUTOSHIELD") == "true"
+	cfg.Threshold = int64(envInt("WALLET_AUTOSHIELD_THRESHOLD", int(cfg.Threshold)))
+	cfg.Interval = envDuration("WALLET_AUTOSHIELD_INTERVAL", cfg.Interval)
+	cfg.Fee = int64(envInt("WALLET_AUTOSHIELD_FEE", int(cfg.Fee)))
+
+	if cfg.Interval < minAutoShieldInterval {
+		cfg.Interval = minAutoShieldInterval
+	}
+
+	return cfg
+}
+
+// AutoShielder periodically shields the wallet's transparent balance
+type AutoShielder struct {
+	ws *WalletService
+
+	mu      sync.Mutex
+	cfg     AutoShieldConfig
+	lastRun time.Time
+	lastErr string
+	reset   chan struct{}
+}
+
+// NewAutoShielder creates the job; Run must be started for it to do anything
+func NewAutoShielder(ws *WalletService, cfg AutoShieldConfig) *AutoShielder {
+	return &AutoShielder{
+		ws:    ws,
+		cfg:   cfg,
+		reset: make(chan struct{}, 1),
+	}
+}
+
+// Config returns the current configuration
+func (a *AutoShielder) Config() AutoShieldConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cfg
+}
+
+// SetConfig replaces the configuration and restarts the interval timer
+func (a *AutoShielder) SetConfig(cfg AutoShieldConfig) {
+	if cfg.Interval < minAutoShieldInterval {
+		cfg.Interval = minAutoShieldInterval
+	}
+
+	a.mu.Lock()
+	a.cfg = cfg
+	a.mu.Unlock()
+
+	select {
+	case a.reset <- struct{}{}:
+	default:
+	}
+}
+
+// Run checks the transparent balance every interval until the process exits
+func (a *AutoShielder) Run() {
+	timer := time.NewTimer(a.Config().Interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			a.runOnce()
+		case <-a.reset:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+		timer.Reset(a.Config().Interval)
+	}
+}
+
+// runOnce shields all confirmed transparent Z when it exceeds the threshold.
+// A locked wallet is skipped silently; it cannot sign the inputs.
+func (a *AutoShielder) runOnce() {
+	cfg := a.Config()
+	if !cfg.Enabled || a.ws.wallet.Locked() {
+		return
+	}
+
+	available := a.ws.wallet.ConfirmedTransparent()
+	if available <= cfg.Threshold || available <= cfg.Fee {
+		return
+	}
+
+	op, err := a.ws.Shield(available-cfg.Fee, cfg.Fee, "auto-shield")
+
+	a.mu.Lock()
+	a.lastRun = time.Now()
+	a.lastErr = ""
+	if err != nil {
+		a.lastErr = err.Error()
+	}
+	a.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Auto-shield of %d Z failed: %v", available-cfg.Fee, err)
+		return
+	}
+
+	log.Printf("Auto-shielded %d Z in %s", op.Transaction.Amount, op.UTXOTxHash)
+	a.ws.publish("auto_shield", op.Transaction)
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getAutoShield(w http.ResponseWriter, r *http.Request) {
+	ws.autoShield.mu.Lock()
+	cfg, lastRun, lastErr := ws.autoShield.cfg, ws.autoShield.lastRun, ws.autoShield.lastErr
+	ws.autoShield.mu.Unlock()
+
+	state := map[string]interface{}{
+		"enabled":   cfg.Enabled,
+		"threshold": cfg.Threshold,
+		"interval":  cfg.Interval.String(),
+		"fee":       cfg.Fee,
+	}
+	if !lastRun.IsZero() {
+		state["last_run"] = lastRun
+	}
+	if lastErr != "" {
+		state["last_error"] = lastErr
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+func (ws *WalletService) setAutoShield(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled   *bool  `json:"enabled"`
+		Threshold *int64 `json:"threshold"`
+		Interval  string `json:"interval"`
+		Fee       *int64 `json:"fee"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg := ws.autoShield.Config()
+	if req.Enabled != nil {
+		cfg.Enabled = *req.Enabled
+	}
+	if req.Threshold != nil {
+		if *req.Threshold < 0 {
+			http.Error(w, "Invalid threshold", http.StatusBadRequest)
+			return
+		}
+		cfg.Threshold = *req.Threshold
+	}
+	if req.Interval != "" {
+		interval, err := time.ParseDuration(req.Interval)
+		if err != nil || interval < minAutoShieldInterval {
+			http.Error(w, "Invalid interval; minimum is "+minAutoShieldInterval.String(), http.StatusBadRequest)
+			return
+		}
+		cfg.Interval = interval
+	}
+	if req.Fee != nil {
+		if *req.Fee < 0 {
+			http.Error(w, "Invalid fee", http.StatusBadRequest)
+			return
+		}
+		cfg.Fee = *req.Fee
+	}
+
+	ws.autoShield.SetConfig(cfg)
+	ws.getAutoShield(w, r)
+}
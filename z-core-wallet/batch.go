@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"z-core-wallet/wallet"
+)
+
+var ErrBatchByPolicy = errors.New("batch breaks the spending policy")
+
+// BatchPolicyError lists the payments of a batch the spending policy rejects
+type BatchPolicyError struct {
+	Violations map[int][]string // Payment index -> violations
+}
+
+func (e *BatchPolicyError) Error() string {
+	return fmt.Sprintf("%v: %d payments need confirmation", ErrBatchByPolicy, len(e.Violations))
+}
+
+func (e *BatchPolicyError) Unwrap() error {
+	return ErrBatchByPolicy
+}
+
+// checkBatchPolicy runs every payment through the spending policy, counting
+// the earlier payments of the batch towards the daily limits
+func (ws *WalletService) checkBatchPolicy(payments []wallet.BatchPayment) error {
+	history := ws.wallet.Transactions()
+	from := ws.wallet.Address()
+	violations := make(map[int][]string)
+
+	for i, payment := range payments {
+		if v := ws.policy.Check(history, from, payment.Recipient, payment.Amount, payment.Token); len(v) > 0 {
+			violations[i] = v
+		}
+		history = append(history, wallet.Transaction{
+			From:      from,
+			To:        payment.Recipient,
+			Amount:    payment.Amount,
+			Token:     payment.Token,
+			Timestamp: time.Now(),
+		})
+	}
+
+	if len(violations) > 0 {
+		return &BatchPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// SendBatch pays every recipient from one multi-output UTXO transaction once
+// the spending policy accepts every payment
+func (ws *WalletService) SendBatch(payments []wallet.BatchPayment, fee int64) (*wallet.BatchOperation, error) {
+	payments, _, err := wallet.NormalizeBatch(payments)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.checkBatchPolicy(payments); err != nil {
+		return nil, err
+	}
+
+	op, err := ws.wallet.SendBatch(payments, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.publish("batch", op.Transaction)
+	return op, nil
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) sendBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Payments []struct {
+			Recipient string `json:"recipient"`
+			Amount    string `json:"amount"`
+			Token     string `json:"token"`
+			Memo      string `json:"memo"`
+		} `json:"payments"`
+		Fee string `json:"fee"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payments := make([]wallet.BatchPayment, len(req.Payments))
+	for i, p := range req.Payments {
+		amount, err := strconv.ParseInt(p.Amount, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid amount in payment %d", i), http.StatusBadRequest)
+			return
+		}
+		payments[i] = wallet.BatchPayment{Recipient: p.Recipient, Amount: amount, Token: p.Token, Memo: p.Memo}
+	}
+
+	fee := wallet.DefaultShieldingFee
+	if req.Fee != "" {
+		var err error
+		fee, err = strconv.ParseInt(req.Fee, 10, 64)
+		if err != nil || fee < 0 {
+			http.Error(w, "Invalid fee", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if ws.wallet.Locked() {
+		http.Error(w, wallet.ErrLocked.Error(), http.StatusLocked)
+		return
+	}
+
+	op, err := ws.SendBatch(payments, fee)
+	var policyErr *BatchPolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		// Held spends confirm a single payment; send these on their own
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      policyErr.Error(),
+			"violations": policyErr.Violations,
+		})
+		return
+	case errors.Is(err, wallet.ErrInvalidBatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		writeShieldError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(op)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	walletv1 "z-core-wallet/api/wallet/v1"
+	"z-core-wallet/netproxy"
+)
+
+// daemonTimeout bounds every call to the wallet daemon
+const daemonTimeout = 30 * time.Second
+
+// daemonCall holds a connection to the wallet daemon for a single command
+type daemonCall struct {
+	conn   *grpc.ClientConn
+	client walletv1.WalletServiceClient
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// dialDaemon connects to the daemon configured by the persistent flags
+func dialDaemon(cmd *cobra.Command) (*daemonCall, error) {
+	target, _ := cmd.Flags().GetString(flagDaemon)
+
+	creds, err := transportCredentials(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	proxyURL, _ := cmd.Flags().GetString(flagSOCKS5)
+	isolate, _ := cmd.Flags().GetBool(flagIsolate)
+	proxyCfg, err := netproxy.ParseConfig(proxyURL, isolate)
+	if err != nil {
+		return nil, err
+	}
+	if proxyCfg.Enabled() {
+		dialer, err := netproxy.NewDialer(proxyCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		// Passthrough hands the host name to the proxy instead of resolving it here
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}))
+		target = "passthrough:///" + target
+	}
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to wallet daemon at %s: %w", target, err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), daemonTimeout)
+	if apiKey, _ := cmd.Flags().GetString(flagAPIKey); apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", apiKey)
+	}
+
+	return &daemonCall{
+		conn:   conn,
+		client: walletv1.NewWalletServiceClient(conn),
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Close releases the connection
+func (d *daemonCall) Close() {
+	d.cancel()
+	d.conn.Close()
+}
+
+func transportCredentials(cmd *cobra.Command) (credentials.TransportCredentials, error) {
+	useTLS, _ := cmd.Flags().GetBool(flagTLS)
+	caFile, _ := cmd.Flags().GetString(flagTLSCA)
+	certFile, _ := cmd.Flags().GetString(flagTLSCert)
+	keyFile, _ := cmd.Flags().GetString(flagTLSKey)
+
+	if !useTLS && caFile == "" && certFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read daemon CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// daemonError turns gRPC status errors into plain messages
+func daemonError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("wallet daemon: %s", status.Convert(err).Message())
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"shared/address"
+	walletv1 "z-core-wallet/api/wallet/v1"
+	"z-core-wallet/keystore"
+	"z-core-wallet/wallet"
+)
+
+const (
+	flagForce   = "force"
+	flagFormat  = "format"
+	flagTestnet = "testnet"
+)
+
+// stdinReader is shared by every prompt so piped input is not lost to buffering
+var stdinReader *bufio.Reader
+
+func createCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Generate a new key and save it to the keystore",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			privateKey, err := btcec.NewPrivateKey()
+			if err != nil {
+				return err
+			}
+			return saveKey(cmd, privateKey)
+		},
+	}
+	cmd.Flags().Bool(flagForce, false, "Overwrite an existing keystore")
+	return cmd
+}
+
+func restoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a key from its hex encoded private key or a Zcash WIF key",
+		Long: "Restore a key from its hex encoded private key, or from a transparent key exported by " +
+			"zcashd dumpprivkey or another Zcash wallet. The key is read from the terminal without echo, or from stdin when piped.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secret, err := readSecret(cmd, "Private key: ")
+			if err != nil {
+				return err
+			}
+
+			privateKey, err := parsePrivateKey(secret)
+			if err != nil {
+				return err
+			}
+			return saveKey(cmd, privateKey)
+		},
+	}
+	cmd.Flags().Bool(flagForce, false, "Overwrite an existing keystore")
+	return cmd
+}
+
+func exportKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-key",
+		Short: "Print the private key of the local keystore",
+		Long: "Print the private key of the local keystore. --format zcash prints a WIF key that " +
+			"zcashd importprivkey and other Zcash wallets accept; they derive the t-addr of the same account.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString(flagFormat)
+			if format != "hex" && format != "zcash" {
+				return fmt.Errorf("unknown key format %q, use hex or zcash", format)
+			}
+
+			_, privateKey, err := unlockKeystore(cmd)
+			if err != nil {
+				return err
+			}
+
+			if format == "zcash" {
+				testnet, _ := cmd.Flags().GetBool(flagTestnet)
+				fmt.Fprintln(cmd.OutOrStdout(), wallet.EncodeZcashKey(privateKey, testnet))
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), hex.EncodeToString(privateKey.Serialize()))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagFormat, "hex", "Key format: hex or zcash")
+	cmd.Flags().Bool(flagTestnet, false, "Export a Zcash testnet key")
+	return cmd
+}
+
+// parsePrivateKey reads a hex private key or a Zcash transparent WIF key
+func parsePrivateKey(secret string) (*btcec.PrivateKey, error) {
+	secret = strings.TrimSpace(secret)
+
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(secret, "0x"))
+	if err == nil {
+		if len(keyBytes) != 32 {
+			return nil, fmt.Errorf("private key must be 32 hex encoded bytes")
+		}
+		privateKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+		return privateKey, nil
+	}
+	return wallet.ParseZcashKey(secret)
+}
+
+func signCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sign [message]",
+		Short: "Sign a message with the wallet key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isOffline(cmd) {
+				_, privateKey, err := unlockKeystore(cmd)
+				if err != nil {
+					return err
+				}
+
+				signature, err := signMessage(privateKey, args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), signature)
+				return nil
+			}
+
+			d, err := dialDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			res, err := d.client.SignMessage(d.ctx, &walletv1.SignMessageRequest{Message: args[0]})
+			if err != nil {
+				return daemonError(err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), res.Signature)
+			return nil
+		},
+	}
+}
+
+// saveKey encrypts privateKey into the keystore and prints the account
+func saveKey(cmd *cobra.Command, privateKey *btcec.PrivateKey) error {
+	ks := openKeystore(cmd)
+	if force, _ := cmd.Flags().GetBool(flagForce); ks.Exists() && !force {
+		return fmt.Errorf("keystore %s already exists, use --%s to overwrite it", ks.Path(), flagForce)
+	}
+
+	account, err := address.FromPubKey(privateKey.PubKey().SerializeCompressed())
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readNewPassphrase(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ks.Path()), 0700); err != nil {
+		return err
+	}
+	if err := ks.Save(account, privateKey, passphrase); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved keystore %s\n", ks.Path())
+	printAddresses(cmd, account)
+	return nil
+}
+
+func openKeystore(cmd *cobra.Command) *keystore.Keystore {
+	path, _ := cmd.Flags().GetString(flagKeystore)
+	return keystore.New(path)
+}
+
+// unlockKeystore prompts for the passphrase and decrypts the local key
+func unlockKeystore(cmd *cobra.Command) (address.Address, *btcec.PrivateKey, error) {
+	ks := openKeystore(cmd)
+	if !ks.Exists() {
+		return address.Address{}, nil, fmt.Errorf("no keystore at %s, run z-wallet create or restore first", ks.Path())
+	}
+
+	passphrase, err := readPassphrase(cmd, "Passphrase: ")
+	if err != nil {
+		return address.Address{}, nil, err
+	}
+
+	account, privateKey, err := ks.Unlock(passphrase)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return address.Address{}, nil, fmt.Errorf("invalid passphrase")
+	}
+	return account, privateKey, err
+}
+
+// signMessage signs the SHA-256 digest of message, matching the daemon's SignMessage
+func signMessage(privateKey *btcec.PrivateKey, message string) (string, error) {
+	hash := sha256.Sum256([]byte(message))
+	signature, err := crypto.Sign(hash[:], privateKey.ToECDSA())
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+func printAddresses(cmd *cobra.Command, account address.Address) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Address:  %s\n", account.ZBase58())
+	fmt.Fprintf(out, "zChain:   %s\n", account.ZChain())
+	fmt.Fprintf(out, "nuChain:  %s\n", account.NuChain())
+	fmt.Fprintf(out, "EVM:      %s\n", account.Hex())
+	fmt.Fprintf(out, "Zcash:    %s\n", account.Zcash())
+}
+
+// readPassphrase takes the passphrase from WALLET_PASSPHRASE for scripted use,
+// otherwise prompts for it
+func readPassphrase(cmd *cobra.Command, prompt string) (string, error) {
+	if passphrase := os.Getenv("WALLET_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	return readSecret(cmd, prompt)
+}
+
+func readNewPassphrase(cmd *cobra.Command) (string, error) {
+	if passphrase := os.Getenv("WALLET_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	passphrase, err := readSecret(cmd, "New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := readSecret(cmd, "Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirm {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// readSecret reads a line without echo from a terminal, or as-is from piped stdin
+func readSecret(cmd *cobra.Command, prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(cmd.ErrOrStderr(), prompt)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(cmd.ErrOrStderr())
+		return string(secret), err
+	}
+
+	if stdinReader == nil {
+		stdinReader = bufio.NewReader(cmd.InOrStdin())
+	}
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,14 @@
+// Command z-wallet manages a Z Core wallet from the terminal. By default it talks
+// to the wallet daemon over gRPC; with --offline it works directly on the local
+// keystore so keys can be created and transactions signed on an air-gapped machine.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	if err := NewRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
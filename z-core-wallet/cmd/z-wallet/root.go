@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagDaemon   = "daemon"
+	flagKeystore = "keystore"
+	flagOffline  = "offline"
+	flagAPIKey   = "api-key"
+	flagTLS      = "tls"
+	flagTLSCA    = "tls-ca"
+	flagTLSCert  = "tls-cert"
+	flagTLSKey   = "tls-key"
+	flagMemo     = "memo"
+	flagToken    = "token"
+	flagSOCKS5   = "socks5"
+	flagIsolate  = "socks5-isolate"
+)
+
+// NewRootCmd creates the z-wallet command tree
+func NewRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "z-wallet",
+		Short:        "Z Core wallet command line",
+		SilenceUsage: true,
+	}
+
+	flags := root.PersistentFlags()
+	flags.String(flagDaemon, envOr("WALLET_DAEMON", "localhost:9091"), "Wallet daemon gRPC address")
+	flags.String(flagKeystore, envOr("WALLET_KEYSTORE", defaultKeystorePath()), "Keystore file used by create, restore and --offline")
+	flags.Bool(flagOffline, false, "Work on the local keystore instead of the daemon")
+	flags.String(flagAPIKey, os.Getenv("WALLET_API_KEY"), "API key sent to the daemon")
+	flags.Bool(flagTLS, false, "Connect to the daemon over TLS")
+	flags.String(flagTLSCA, "", "CA certificate used to verify the daemon (implies --tls)")
+	flags.String(flagTLSCert, "", "Client certificate for daemons that require one (implies --tls)")
+	flags.String(flagTLSKey, "", "Client certificate key")
+	flags.String(flagSOCKS5, os.Getenv("WALLET_SOCKS5_PROXY"), "Reach the daemon through this SOCKS5 proxy, e.g. 127.0.0.1:9050 for Tor")
+	flags.Bool(flagIsolate, os.Getenv("WALLET_SOCKS5_ISOLATE") != "false", "Use separate proxy credentials per destination so Tor builds separate circuits")
+
+	root.AddCommand(
+		createCmd(),
+		restoreCmd(),
+		exportKeyCmd(),
+		balanceCmd(),
+		sendCmd(),
+		shieldCmd(),
+		unshieldCmd(),
+		historyCmd(),
+		signCmd(),
+		signerCmd(),
+	)
+
+	return root
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func defaultKeystorePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "keystore.json"
+	}
+	return filepath.Join(home, ".z-wallet", "keystore.json")
+}
+
+func isOffline(cmd *cobra.Command) bool {
+	offline, _ := cmd.Flags().GetBool(flagOffline)
+	return offline
+}
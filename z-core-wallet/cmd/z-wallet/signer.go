@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"z-core-wallet/remotesigner"
+	"z-core-wallet/wallet"
+)
+
+const (
+	flagListen     = "listen"
+	flagSessionTTL = "session-ttl"
+)
+
+func signerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signer",
+		Short: "Hold the keystore key and sign for a daemon running with WALLET_REMOTE_SIGNER",
+		Long: "Unlock the keystore and serve signing sessions to wallet daemons holding the shared secret " +
+			"in WALLET_SIGNER_SECRET or WALLET_SIGNER_SECRET_FILE, 32 or more hex encoded bytes. " +
+			"Calls are encrypted and authenticated with a per-session key, so the daemon needs no key material.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secret, err := remotesigner.LoadSecret()
+			if err != nil {
+				return err
+			}
+
+			account, privateKey, err := unlockKeystore(cmd)
+			if err != nil {
+				return err
+			}
+
+			listen, _ := cmd.Flags().GetString(flagListen)
+			ttl, _ := cmd.Flags().GetDuration(flagSessionTTL)
+			server := remotesigner.NewServer(account, wallet.NewKeySigner(privateKey), secret, ttl)
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Signing for %s on %s\n", account.ZBase58(), listen)
+			httpServer := &http.Server{
+				Addr:              listen,
+				Handler:           server,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			return httpServer.ListenAndServe()
+		},
+	}
+	cmd.Flags().String(flagListen, envOr("WALLET_SIGNER_LISTEN", "127.0.0.1:7777"), "Address to serve signing sessions on")
+	cmd.Flags().Duration(flagSessionTTL, remotesigner.DefaultSessionTTL, "Lifetime of a signing session")
+	return cmd
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"shared/address"
+	walletv1 "z-core-wallet/api/wallet/v1"
+)
+
+// offlineTransaction is printed by send --offline so it can be broadcast from an
+// online machine. The signature covers the JSON encoding with PublicKey and
+// Signature left empty.
+type offlineTransaction struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Amount    int64  `json:"amount"`
+	Token     string `json:"token"`
+	Memo      string `json:"memo"`
+	Timestamp int64  `json:"timestamp"`
+	PublicKey string `json:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+func balanceCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "balance",
+		Short: "Show the wallet addresses and balances",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isOffline(cmd) {
+				// Balances are only known to the daemon, but the account can be read while locked
+				account, err := openKeystore(cmd).Account()
+				if err != nil {
+					return err
+				}
+				printAddresses(cmd, account)
+				return nil
+			}
+
+			d, err := dialDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			info, err := d.client.GetInfo(d.ctx, &walletv1.GetInfoRequest{})
+			if err != nil {
+				return daemonError(err)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Address:  %s\n", info.Address)
+			fmt.Fprintf(out, "zChain:   %s\n", info.Addresses.GetZchain())
+			fmt.Fprintf(out, "nuChain:  %s\n", info.Addresses.GetNuchain())
+			fmt.Fprintf(out, "EVM:      %s\n", info.Addresses.GetEvm())
+			fmt.Fprintf(out, "Z:        %d\n", info.Balance.GetZ())
+			fmt.Fprintf(out, "NU:       %d\n", info.Balance.GetNu())
+			if info.Locked {
+				fmt.Fprintln(out, "Wallet is locked")
+			}
+			return nil
+		},
+	}
+}
+
+func sendCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send [recipient] [amount]",
+		Short: "Send a transparent transaction",
+		Long:  "Send a transparent transaction. With --offline the transaction is signed with the local keystore and printed instead of submitted.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, err := parseAmount(args[1])
+			if err != nil {
+				return err
+			}
+			token, _ := cmd.Flags().GetString(flagToken)
+			memo, _ := cmd.Flags().GetString(flagMemo)
+
+			if isOffline(cmd) {
+				return signOffline(cmd, args[0], amount, token, memo)
+			}
+
+			d, err := dialDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			tx, err := d.client.CreateTransaction(d.ctx, &walletv1.CreateTransactionRequest{
+				Recipient: args[0],
+				Amount:    amount,
+				Token:     token,
+				Memo:      memo,
+			})
+			if err != nil {
+				return daemonError(err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Submitted %s (%s)\n", tx.Hash, tx.Status)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagToken, "Z", "Token to send (Z or NU)")
+	cmd.Flags().String(flagMemo, "", "Transaction memo")
+	return cmd
+}
+
+func shieldCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shield [amount]",
+		Short: "Move transparent Z into the shielded balance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, err := parseAmount(args[0])
+			if err != nil {
+				return err
+			}
+			memo, _ := cmd.Flags().GetString(flagMemo)
+
+			d, err := requireDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			transfer, err := d.client.Shield(d.ctx, &walletv1.ShieldRequest{Amount: amount, Memo: memo})
+			if err != nil {
+				return daemonError(err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Shielded %d Z (nullifier %s)\n", amount, transfer.Nullifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagMemo, "", "Encrypted memo")
+	return cmd
+}
+
+func unshieldCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unshield [amount] [recipient]",
+		Short: "Move shielded Z back to a transparent address",
+		Long:  "Move shielded Z back to a transparent address. Without a recipient the funds return to the wallet's own address.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, err := parseAmount(args[0])
+			if err != nil {
+				return err
+			}
+			recipient := ""
+			if len(args) == 2 {
+				recipient = args[1]
+			}
+			memo, _ := cmd.Flags().GetString(flagMemo)
+
+			d, err := requireDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			tx, err := d.client.Unshield(d.ctx, &walletv1.UnshieldRequest{Amount: amount, Recipient: recipient, Memo: memo})
+			if err != nil {
+				return daemonError(err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Submitted %s (%s)\n", tx.Hash, tx.Status)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagMemo, "", "Transaction memo")
+	return cmd
+}
+
+func historyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history",
+		Short: "List the wallet transactions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := requireDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			res, err := d.client.ListTransactions(d.ctx, &walletv1.ListTransactionsRequest{})
+			if err != nil {
+				return daemonError(err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tHASH\tTO\tAMOUNT\tTOKEN\tSTATUS\tPRIVATE")
+			for _, tx := range res.Transactions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%t\n",
+					time.Unix(tx.Timestamp, 0).Format(time.RFC3339), tx.Hash, tx.To, tx.Amount, tx.Token, tx.Status, tx.Private)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// requireDaemon dials the daemon for commands that need its chain and note state
+func requireDaemon(cmd *cobra.Command) (*daemonCall, error) {
+	if isOffline(cmd) {
+		return nil, fmt.Errorf("%s needs the wallet daemon and cannot run with --%s", cmd.Name(), flagOffline)
+	}
+	return dialDaemon(cmd)
+}
+
+// signOffline signs a transparent transaction with the local keystore and prints it
+func signOffline(cmd *cobra.Command, recipient string, amount int64, token, memo string) error {
+	if _, _, _, err := address.Parse(recipient); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	account, privateKey, err := unlockKeystore(cmd)
+	if err != nil {
+		return err
+	}
+
+	tx := offlineTransaction{
+		From:      account.ZBase58(),
+		To:        recipient,
+		Amount:    amount,
+		Token:     token,
+		Memo:      memo,
+		Timestamp: time.Now().Unix(),
+	}
+
+	unsigned, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	tx.Signature, err = signMessage(privateKey, string(unsigned))
+	if err != nil {
+		return err
+	}
+	tx.PublicKey = hex.EncodeToString(privateKey.PubKey().SerializeCompressed())
+
+	out, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+func parseAmount(s string) (int64, error) {
+	amount, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
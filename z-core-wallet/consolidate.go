@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"z-core-wallet/wallet"
+)
+
+// HTTP Handlers
+
+func (ws *WalletService) consolidateUTXOs(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MaxInputs int    `json:"max_inputs"`
+		Below     string `json:"below"`
+		FeeRate   string `json:"fee_rate"`
+		MaxFee    string `json:"max_fee"`
+		DryRun    bool   `json:"dry_run"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	consolidation := wallet.ConsolidationRequest{MaxInputs: req.MaxInputs, DryRun: req.DryRun}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *int64
+	}{
+		{"below", req.Below, &consolidation.Below},
+		{"fee_rate", req.FeeRate, &consolidation.FeeRate},
+		{"max_fee", req.MaxFee, &consolidation.MaxFee},
+	} {
+		if field.value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(field.value, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid "+field.name, http.StatusBadRequest)
+			return
+		}
+		*field.dest = n
+	}
+
+	op, err := ws.wallet.Consolidate(consolidation)
+	switch {
+	case errors.Is(err, wallet.ErrLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case errors.Is(err, wallet.ErrNothingToConsolidate):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !op.DryRun {
+		ws.publish("consolidate", op.Transaction)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(op)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"shared/address"
+	"z-core-wallet/storage"
+)
+
+// OpenWalletDatabase opens the store named by WALLET_DB; without it wallet
+// state is kept in memory and lost on exit
+func OpenWalletDatabase() (storage.Store, error) {
+	dsn := os.Getenv("WALLET_DB")
+	if dsn == "" {
+		log.Printf("WALLET_DB is not set; wallet state is kept in memory only")
+	}
+	return storage.Open(dsn)
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getContacts(w http.ResponseWriter, r *http.Request) {
+	contacts, err := ws.store.Contacts(ws.wallet.Account().Hex())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if contacts == nil {
+		contacts = []storage.Contact{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contacts)
+}
+
+func (ws *WalletService) saveContact(w http.ResponseWriter, r *http.Request) {
+	var contact storage.Contact
+	if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contact.Name = strings.TrimSpace(contact.Name)
+	if contact.Name == "" {
+		http.Error(w, "Contact name cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if _, err := address.ParseRecipient(contact.Address); err != nil {
+		http.Error(w, "Invalid contact address: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := ws.store.SaveContact(ws.wallet.Account().Hex(), contact); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contact)
+}
+
+func (ws *WalletService) deleteContact(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	err := ws.store.DeleteContact(ws.wallet.Account().Hex(), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "contact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	walletv1 "z-core-wallet/api/wallet/v1"
+	"z-core-wallet/wallet"
+)
+
+// walletGRPCServer exposes WalletService over gRPC
+type walletGRPCServer struct {
+	walletv1.UnimplementedWalletServiceServer
+	ws *WalletService
+}
+
+// NewGRPCServer creates a gRPC server for the wallet. A nil tlsConfig serves plaintext.
+func NewGRPCServer(ws *WalletService, tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(ws.rateLimitUnary),
+		grpc.StreamInterceptor(ws.rateLimitStream),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(opts...)
+	walletv1.RegisterWalletServiceServer(server, &walletGRPCServer{ws: ws})
+	return server
+}
+
+func (s *walletGRPCServer) GetInfo(ctx context.Context, req *walletv1.GetInfoRequest) (*walletv1.GetInfoResponse, error) {
+	account := s.ws.wallet.Account()
+	balance := s.ws.wallet.Balance()
+
+	publicKey := ""
+	if pub := s.ws.wallet.PublicKey(); pub != nil {
+		publicKey = hex.EncodeToString(pub.SerializeCompressed())
+	}
+
+	return &walletv1.GetInfoResponse{
+		Address: account.ZBase58(),
+		Addresses: &walletv1.Addresses{
+			Zchain:  account.ZChain(),
+			Nuchain: account.NuChain(),
+			Evm:     evmAddress(s.ws.wallet),
+		},
+		Balance:   toProtoBalance(balance),
+		PublicKey: publicKey,
+		Locked:    s.ws.wallet.Locked(),
+	}, nil
+}
+
+func (s *walletGRPCServer) ListTransactions(ctx context.Context, req *walletv1.ListTransactionsRequest) (*walletv1.ListTransactionsResponse, error) {
+	history := s.ws.wallet.Transactions()
+	txs := make([]*walletv1.Transaction, 0, len(history))
+	for _, tx := range history {
+		txs = append(txs, toProtoTransaction(tx))
+	}
+
+	return &walletv1.ListTransactionsResponse{Transactions: txs}, nil
+}
+
+func (s *walletGRPCServer) CreateTransaction(ctx context.Context, req *walletv1.CreateTransactionRequest) (*walletv1.Transaction, error) {
+	if s.ws.wallet.Locked() {
+		return nil, status.Error(codes.FailedPrecondition, wallet.ErrLocked.Error())
+	}
+	if req.Amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if held := s.ws.holdIfViolates(req.Recipient, req.Amount, req.Token, req.Memo, false); held != nil {
+		return nil, heldError(held)
+	}
+
+	tx := s.ws.CreateTransaction(req.Recipient, req.Amount, req.Token, req.Memo)
+	return toProtoTransaction(tx), nil
+}
+
+func (s *walletGRPCServer) CreateShielded(ctx context.Context, req *walletv1.CreateShieldedRequest) (*walletv1.ShieldedTransfer, error) {
+	if s.ws.wallet.Locked() {
+		return nil, status.Error(codes.FailedPrecondition, wallet.ErrLocked.Error())
+	}
+	if req.Amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if held := s.ws.holdIfViolates(req.Recipient, req.Amount, "Z", req.Memo, true); held != nil {
+		return nil, heldError(held)
+	}
+
+	transfer, err := s.ws.wallet.CreateShieldedTransfer(req.Recipient, req.Amount, req.Memo)
+	if err != nil {
+		return nil, shieldError(err)
+	}
+
+	return &walletv1.ShieldedTransfer{
+		Memo:      transfer.Memo,
+		ZkProof:   transfer.ZkProof,
+		Nullifier: transfer.Nullifier,
+	}, nil
+}
+
+func (s *walletGRPCServer) Shield(ctx context.Context, req *walletv1.ShieldRequest) (*walletv1.ShieldedTransfer, error) {
+	if s.ws.wallet.Locked() {
+		return nil, status.Error(codes.FailedPrecondition, wallet.ErrLocked.Error())
+	}
+	if req.Amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	op, err := s.ws.Shield(req.Amount, wallet.DefaultShieldingFee, req.Memo)
+	if err != nil {
+		return nil, shieldError(err)
+	}
+
+	return &walletv1.ShieldedTransfer{
+		Memo:      op.SendShielded.EncryptedMemo,
+		ZkProof:   op.SendShielded.ZkProof,
+		Nullifier: hex.EncodeToString(op.SendShielded.Nullifiers[0]),
+	}, nil
+}
+
+func (s *walletGRPCServer) Unshield(ctx context.Context, req *walletv1.UnshieldRequest) (*walletv1.Transaction, error) {
+	if s.ws.wallet.Locked() {
+		return nil, status.Error(codes.FailedPrecondition, wallet.ErrLocked.Error())
+	}
+	if req.Amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if req.Recipient != "" {
+		if held := s.ws.holdIfViolates(req.Recipient, req.Amount, "Z", req.Memo, false); held != nil {
+			return nil, heldError(held)
+		}
+	}
+
+	op, err := s.ws.Unshield(req.Amount, wallet.DefaultShieldingFee, req.Recipient, req.Memo)
+	if err != nil {
+		return nil, shieldError(err)
+	}
+
+	return toProtoTransaction(op.Transaction), nil
+}
+
+// shieldError maps coin selection failures to FailedPrecondition
+func shieldError(err error) error {
+	if errors.Is(err, wallet.ErrInsufficientTransparent) || errors.Is(err, wallet.ErrInsufficientShielded) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (s *walletGRPCServer) SignMessage(ctx context.Context, req *walletv1.SignMessageRequest) (*walletv1.SignMessageResponse, error) {
+	if s.ws.wallet.Locked() {
+		return nil, status.Error(codes.FailedPrecondition, wallet.ErrLocked.Error())
+	}
+
+	signature, err := s.ws.wallet.SignMessage(req.Message)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &walletv1.SignMessageResponse{Signature: signature}, nil
+}
+
+func (s *walletGRPCServer) VerifyMessage(ctx context.Context, req *walletv1.VerifyMessageRequest) (*walletv1.VerifyMessageResponse, error) {
+	result, err := VerifyMessage(req.Address, req.Message, req.Signature)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &walletv1.VerifyMessageResponse{
+		Valid:     result.Valid,
+		Signer:    result.Signer,
+		PublicKey: result.PublicKey,
+		Reason:    result.Reason,
+	}, nil
+}
+
+func (s *walletGRPCServer) Subscribe(req *walletv1.SubscribeRequest, stream walletv1.WalletService_SubscribeServer) error {
+	events, cancel := s.ws.subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case message := <-events:
+			var event struct {
+				Type string          `json:"type"`
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(message, &event); err != nil {
+				continue
+			}
+
+			if err := stream.Send(&walletv1.WalletEvent{Type: event.Type, Data: event.Data}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// heldError reports a spend held by the spending policy; it is confirmed over REST
+func heldError(spend *HeldSpend) error {
+	return status.Errorf(codes.FailedPrecondition, "held by spending policy as %s: %s", spend.ID, strings.Join(spend.Violations, "; "))
+}
+
+func toProtoBalance(balance wallet.Balance) *walletv1.Balance {
+	result := &walletv1.Balance{Z: balance.Z, Nu: balance.NU}
+	for _, denom := range balance.Denoms() {
+		info, _ := wallet.LookupToken(denom)
+		result.Tokens = append(result.Tokens, &walletv1.TokenBalance{
+			Denom:       denom,
+			Decimals:    uint32(info.Decimals),
+			Transparent: balance.Amounts[denom],
+			Shielded:    balance.Shielded[denom],
+		})
+	}
+	return result
+}
+
+func toProtoTransaction(tx wallet.Transaction) *walletv1.Transaction {
+	return &walletv1.Transaction{
+		Hash:      tx.Hash,
+		From:      tx.From,
+		To:        tx.To,
+		Amount:    tx.Amount,
+		Token:     tx.Token,
+		Timestamp: tx.Timestamp.Unix(),
+		Status:    tx.Status,
+		Memo:      tx.Memo,
+		Private:   tx.Private,
+	}
+}
+
+// Rate limiting
+
+func (ws *WalletService) rateLimitUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := ws.allowGRPC(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (ws *WalletService) rateLimitStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := ws.allowGRPC(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// allowGRPC applies the REST rate limits using the peer address and x-api-key metadata
+func (ws *WalletService) allowGRPC(ctx context.Context) error {
+	ip := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	apiKey := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(APIKeyHeader)); len(values) > 0 {
+			apiKey = values[0]
+		}
+	}
+
+	if wait, ok := ws.limiter.Allow(ip, apiKey); !ok {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %s", wait)
+	}
+	return nil
+}
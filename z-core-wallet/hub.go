@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteWait bounds a single write to a WebSocket client
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is how long a client may stay silent before it is evicted
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod must be shorter than wsPongWait
+	wsPingPeriod = wsPongWait * 9 / 10
+	// wsMaxMessageSize limits what clients may send; they only send pings
+	wsMaxMessageSize = 4096
+	// clientBuffer is the number of events queued per client
+	clientBuffer = 64
+)
+
+// hubClient is a WebSocket connection or gRPC subscriber receiving events
+type hubClient struct {
+	send chan []byte
+	pong chan struct{} // Answers to application level pings, written by writePump
+	// Subscribers skip events they are too slow for; WebSocket clients that
+	// fall behind are evicted instead
+	lossy bool
+}
+
+// Hub fans events out to WebSocket clients and gRPC subscribers. Its client
+// set is only touched by the Run loop; everything else talks to it through
+// channels.
+type Hub struct {
+	register   chan *hubClient
+	unregister chan *hubClient
+	broadcast  chan []byte
+
+	clients map[*hubClient]struct{}
+}
+
+// NewHub creates a hub; Run must be started for it to deliver anything
+func NewHub() *Hub {
+	return &Hub{
+		register:   make(chan *hubClient),
+		unregister: make(chan *hubClient),
+		broadcast:  make(chan []byte, 256),
+		clients:    make(map[*hubClient]struct{}),
+	}
+}
+
+// Run is the hub's event loop
+func (h *Hub) Run() {
+	for {
+		select {
+		case client := <-h.register:
+			h.clients[client] = struct{}{}
+		case client := <-h.unregister:
+			h.remove(client)
+		case message := <-h.broadcast:
+			for client := range h.clients {
+				select {
+				case client.send <- message:
+				default:
+					if !client.lossy {
+						h.remove(client)
+					}
+				}
+			}
+		}
+	}
+}
+
+// remove drops a client and closes its queue, which ends its writer
+func (h *Hub) remove(client *hubClient) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.send)
+	}
+}
+
+// Broadcast queues message for every client
+func (h *Hub) Broadcast(message []byte) {
+	h.broadcast <- message
+}
+
+// Subscribe registers a channel receiving every broadcast message until cancelled
+func (h *Hub) Subscribe() (<-chan []byte, func()) {
+	client := &hubClient{send: make(chan []byte, clientBuffer), lossy: true}
+	h.register <- client
+
+	return client.send, func() { h.unregister <- client }
+}
+
+// ServeWS upgrades the request and serves a WebSocket client, sending greeting
+// before any broadcast event
+func (h *Hub) ServeWS(upgrader *websocket.Upgrader, w http.ResponseWriter, r *http.Request, greeting interface{}) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	client := &hubClient{send: make(chan []byte, clientBuffer), pong: make(chan struct{}, 1)}
+	if message, err := json.Marshal(greeting); err == nil {
+		client.send <- message
+	}
+	h.register <- client
+
+	go h.writePump(conn, client)
+	h.readPump(conn, client)
+}
+
+// readPump handles client messages and keeps the read deadline moving while
+// pongs arrive. It is the only reader of conn.
+func (h *Hub) readPump(conn *websocket.Conn, client *hubClient) {
+	defer func() {
+		h.unregister <- client
+	}()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		// Handle different message types
+		switch msg["type"] {
+		case "ping":
+			select {
+			case client.pong <- struct{}{}:
+			default: // A pong is already due
+			}
+		}
+	}
+}
+
+// writePump is the only writer of conn. It drains the client's queue and
+// pings on an interval; a failed write closes the connection, which ends
+// readPump and unregisters the client.
+func (h *Hub) writePump(conn *websocket.Conn, client *hubClient) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-client.send:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-client.pong:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(map[string]string{"type": "pong"}); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"z-core-wallet/storage"
+)
+
+// IdempotencyKeyHeader lets a client retry a request without repeating its effect
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayHeader marks a response replayed from an earlier request
+const IdempotentReplayHeader = "Idempotent-Replayed"
+
+const (
+	// idempotencyTTL is how long a key is remembered
+	idempotencyTTL = 24 * time.Hour
+
+	// maxIdempotencyKey bounds a key; clients usually send a UUID
+	maxIdempotencyKey = 255
+
+	// maxIdempotentBody bounds the request bodies that are fingerprinted
+	maxIdempotentBody = 1 << 20
+)
+
+// IdempotencyGuard remembers the responses of requests sent with an
+// Idempotency-Key. A retry with the same key and body gets the first response
+// back, so a client that lost the reply cannot spend twice. Only successful
+// responses are stored; a failed request changed nothing and may be retried.
+type IdempotencyGuard struct {
+	store   storage.Store
+	account func() string
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewIdempotencyGuard stores responses under the account account returns
+func NewIdempotencyGuard(store storage.Store, account func() string) *IdempotencyGuard {
+	return &IdempotencyGuard{store: store, account: account, inFlight: make(map[string]bool)}
+}
+
+// Wrap applies the guard to a handler. route names the operation and is part
+// of the fingerprint, so one key cannot be replayed against another endpoint.
+func (g *IdempotencyGuard) Wrap(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := idempotencyFingerprint(route, body)
+		account := g.account()
+
+		g.mu.Lock()
+		if g.inFlight[account+key] {
+			g.mu.Unlock()
+			http.Error(w, "a request with this Idempotency-Key is in progress", http.StatusConflict)
+			return
+		}
+		stored, err := g.store.IdempotentResponse(account, key)
+		switch {
+		case err == nil && time.Since(stored.CreatedAt) < idempotencyTTL:
+			g.mu.Unlock()
+			if stored.Fingerprint != fingerprint {
+				http.Error(w, "Idempotency-Key was used with a different request", http.StatusUnprocessableEntity)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(IdempotentReplayHeader, "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+			return
+		case err != nil && !errors.Is(err, storage.ErrNotFound):
+			g.mu.Unlock()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		g.inFlight[account+key] = true
+		g.mu.Unlock()
+
+		defer func() {
+			g.mu.Lock()
+			delete(g.inFlight, account+key)
+			g.mu.Unlock()
+		}()
+
+		capture := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+		next(capture, r)
+
+		if capture.status < 200 || capture.status >= 300 {
+			return
+		}
+		now := time.Now()
+		err = g.store.SaveIdempotentResponse(account, storage.IdempotentResponse{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      capture.status,
+			Body:        capture.body.Bytes(),
+			CreatedAt:   now,
+		})
+		if err != nil {
+			log.Printf("Failed to store response for Idempotency-Key %q: %v", key, err)
+		}
+		if err := g.store.PruneIdempotentResponses(account, now.Add(-idempotencyTTL)); err != nil {
+			log.Printf("Failed to prune idempotency keys: %v", err)
+		}
+	}
+}
+
+func idempotencyFingerprint(route string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(route))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCapture passes a response through while keeping a copy of it
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(p []byte) (int, error) {
+	c.body.Write(p)
+	return c.ResponseWriter.Write(p)
+}
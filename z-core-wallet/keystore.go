@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"z-core-wallet/keystore"
+	"z-core-wallet/wallet"
+)
+
+// HTTP Handlers
+
+func (ws *WalletService) unlockWallet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !ws.wallet.HasKeystore() {
+		http.Error(w, wallet.ErrNoKeystore.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Reserved before decrypting so a locked out caller cannot keep the scrypt
+	// work busy, nor guess in parallel
+	if wait, ok := ws.unlockGuard.Allow(); !ok {
+		writeRetryAfter(w, wait, "too many failed unlock attempts")
+		return
+	}
+
+	err := ws.wallet.Unlock(req.Passphrase)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		wait := ws.unlockGuard.Failure()
+		log.Printf("Failed wallet unlock attempt from %s, locked for %s", clientIP(r, ws.rateLimits.TrustProxy), wait)
+		http.Error(w, "invalid passphrase", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		ws.unlockGuard.Release()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ws.unlockGuard.Success()
+
+	ws.getWalletInfo(w, r)
+}
+
+func (ws *WalletService) lockWallet(w http.ResponseWriter, r *http.Request) {
+	if err := ws.wallet.Lock(); err != nil {
+		// Without a keystore the key would be lost for good
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	ws.getWalletInfo(w, r)
+}
+
+func (ws *WalletService) saveKeystore(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := ws.wallet.SaveKeystore(req.Passphrase)
+	switch {
+	case errors.Is(err, wallet.ErrNoKeystore):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, wallet.ErrLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case errors.Is(err, wallet.ErrRemoteSigner):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws.getWalletInfo(w, r)
+}
+
+// checkPassphrase authorizes an owner action with the keystore passphrase,
+// returning the decrypted key. Wrong passphrases count against the unlock
+// guard as failed unlocks do. On refusal it writes the error and returns false.
+func (ws *WalletService) checkPassphrase(w http.ResponseWriter, r *http.Request, passphrase string) (*btcec.PrivateKey, bool) {
+	if !ws.wallet.HasKeystore() {
+		http.Error(w, wallet.ErrNoKeystore.Error()+"; owner actions are authorized by its passphrase", http.StatusConflict)
+		return nil, false
+	}
+
+	if wait, ok := ws.unlockGuard.Allow(); !ok {
+		writeRetryAfter(w, wait, "too many failed unlock attempts")
+		return nil, false
+	}
+
+	privateKey, err := ws.wallet.KeystoreKey(passphrase)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		wait := ws.unlockGuard.Failure()
+		log.Printf("Failed owner authorization from %s, locked for %s", clientIP(r, ws.rateLimits.TrustProxy), wait)
+		http.Error(w, "invalid passphrase", http.StatusUnauthorized)
+		return nil, false
+	}
+	if err != nil {
+		ws.unlockGuard.Release()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	ws.unlockGuard.Success()
+	return privateKey, true
+}
+
+// writeRetryAfter rejects a request with 429 and tells the client when to retry
+func writeRetryAfter(w http.ResponseWriter, wait time.Duration, message string) {
+	seconds := int64(wait / time.Second)
+	if wait%time.Second != 0 {
+		seconds++
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	http.Error(w, message, http.StatusTooManyRequests)
+}
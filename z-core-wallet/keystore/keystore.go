@@ -0,0 +1,128 @@
+// Package keystore stores the wallet key encrypted under a passphrase. It is
+// shared by the wallet daemon and the z-wallet CLI so both read the same files.
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	ethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+
+	"shared/address"
+)
+
+// Version is written to every keystore file
+const Version = 1
+
+// ErrDecrypt is returned by Unlock for a wrong passphrase
+var ErrDecrypt = ethkeystore.ErrDecrypt
+
+// file is the on-disk format: the account stays readable so the wallet can
+// show its address while locked, the private key is scrypt + AES encrypted
+type file struct {
+	Version int                    `json:"version"`
+	Account string                 `json:"account"`
+	Crypto  ethkeystore.CryptoJSON `json:"crypto"`
+}
+
+// Keystore stores the wallet key encrypted under a passphrase
+type Keystore struct {
+	path string
+}
+
+// New returns a keystore backed by the file at path
+func New(path string) *Keystore {
+	return &Keystore{path: path}
+}
+
+// Path returns the keystore file location
+func (k *Keystore) Path() string {
+	return k.path
+}
+
+// Exists reports whether a keystore file has been written
+func (k *Keystore) Exists() bool {
+	_, err := os.Stat(k.path)
+	return err == nil
+}
+
+// Account returns the account stored in the keystore without decrypting it
+func (k *Keystore) Account() (address.Address, error) {
+	f, err := k.read()
+	if err != nil {
+		return address.Address{}, err
+	}
+
+	addr, err := address.ParseHex(f.Account)
+	if err != nil {
+		return address.Address{}, fmt.Errorf("invalid keystore account: %w", err)
+	}
+	return addr, nil
+}
+
+// Save encrypts the private key for account and writes it to disk
+func (k *Keystore) Save(account address.Address, privateKey *btcec.PrivateKey, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+
+	crypto, err := ethkeystore.EncryptDataV3(privateKey.Serialize(), []byte(passphrase), ethkeystore.StandardScryptN, ethkeystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file{
+		Version: Version,
+		Account: account.Hex(),
+		Crypto:  crypto,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated keystore
+	tmp := k.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, k.path)
+}
+
+// Unlock decrypts the private key. A wrong passphrase returns ErrDecrypt.
+func (k *Keystore) Unlock(passphrase string) (address.Address, *btcec.PrivateKey, error) {
+	f, err := k.read()
+	if err != nil {
+		return address.Address{}, nil, err
+	}
+
+	account, err := address.ParseHex(f.Account)
+	if err != nil {
+		return address.Address{}, nil, fmt.Errorf("invalid keystore account: %w", err)
+	}
+
+	keyBytes, err := ethkeystore.DecryptDataV3(f.Crypto, passphrase)
+	if err != nil {
+		return address.Address{}, nil, err
+	}
+	privateKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+
+	return account, privateKey, nil
+}
+
+func (k *Keystore) read() (*file, error) {
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid keystore file: %w", err)
+	}
+	if f.Version != Version {
+		return nil, fmt.Errorf("unsupported keystore version %d", f.Version)
+	}
+	return &f, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"shared/address"
+	"z-core-wallet/storage"
+	"z-core-wallet/wallet"
+)
+
+// maxLabelLength bounds a label so it stays a label rather than a note store
+const maxLabelLength = 256
+
+// parseUTXORef splits a UTXO reference given as tx_hash:output_index
+func parseUTXORef(ref string) (string, uint32, error) {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("expected tx_hash:output_index")
+	}
+	index, err := strconv.ParseUint(ref[i+1:], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid output index: %w", err)
+	}
+	return ref[:i], uint32(index), nil
+}
+
+// normalizeLabel validates a label and puts its reference in canonical form
+func normalizeLabel(label *storage.Label) error {
+	label.Label = strings.TrimSpace(label.Label)
+	if label.Label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+	if len(label.Label) > maxLabelLength {
+		return fmt.Errorf("label is longer than %d bytes", maxLabelLength)
+	}
+
+	switch label.Kind {
+	case storage.LabelAddress:
+		if _, err := address.ParseRecipient(label.Ref); err != nil {
+			return fmt.Errorf("invalid address: %w", err)
+		}
+	case storage.LabelUTXO:
+		hash, index, err := parseUTXORef(label.Ref)
+		if err != nil {
+			return fmt.Errorf("invalid UTXO: %w", err)
+		}
+		label.Ref = fmt.Sprintf("%s:%d", hash, index)
+	default:
+		return fmt.Errorf("kind must be %q or %q", storage.LabelAddress, storage.LabelUTXO)
+	}
+	return nil
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getLabels(w http.ResponseWriter, r *http.Request) {
+	labels, err := ws.store.Labels(ws.wallet.Account().Hex())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	filtered := make([]storage.Label, 0, len(labels))
+	for _, label := range labels {
+		if kind == "" || label.Kind == kind {
+			filtered = append(filtered, label)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filtered)
+}
+
+func (ws *WalletService) saveLabel(w http.ResponseWriter, r *http.Request) {
+	var label storage.Label
+	if err := json.NewDecoder(r.Body).Decode(&label); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := normalizeLabel(&label); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := ws.store.SaveLabel(ws.wallet.Account().Hex(), label); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(label)
+}
+
+func (ws *WalletService) deleteLabel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	err := ws.store.DeleteLabel(ws.wallet.Account().Hex(), vars["kind"], vars["ref"])
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "label not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ws *WalletService) freezeUTXO(w http.ResponseWriter, r *http.Request) {
+	ws.setFrozen(w, r, true)
+}
+
+func (ws *WalletService) unfreezeUTXO(w http.ResponseWriter, r *http.Request) {
+	ws.setFrozen(w, r, false)
+}
+
+func (ws *WalletService) setFrozen(w http.ResponseWriter, r *http.Request, frozen bool) {
+	vars := mux.Vars(r)
+	index, err := strconv.ParseUint(vars["index"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid output index", http.StatusBadRequest)
+		return
+	}
+
+	utxo, err := ws.wallet.Freeze(vars["hash"], uint32(index), frozen)
+	if errors.Is(err, wallet.ErrUnknownUTXO) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ws.publish("utxo_frozen", utxo)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(utxo)
+}
@@ -1,188 +1,144 @@
 package main
 
 import (
-	"context"
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
-	"time"
 	
-	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcutil/base58"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
-)
-
-// ShieldedTransfer represents a Zcash-style private transaction
-type ShieldedTransfer struct {
-	Sender    string `json:"sender"`    // Hidden via zk-SNARK
-	Recipient string `json:"recipient"` // Hidden
-	Amount    int64  `json:"amount"`    // Hidden
-	Memo      []byte `json:"memo"`      // Encrypted, 512 bytes max
-	ZkProof   []byte `json:"zk_proof"`  // zk-SNARK proof
-	Nullifier string `json:"nullifier"` // Prevents double spending
-}
-
-// Wallet represents the Z Core wallet
-type Wallet struct {
-	PrivateKey *btcec.PrivateKey
-	PublicKey  *btcec.PublicKey
-	Address    string
-	Balance    Balance
-	TxHistory  []Transaction
-}
-
-// Balance represents wallet balances
-type Balance struct {
-	Z  int64 `json:"z"`
-	NU int64 `json:"nu"`
-}
 
-// Transaction represents a transaction record
-type Transaction struct {
-	Hash      string    `json:"hash"`
-	From      string    `json:"from"`
-	To        string    `json:"to"`
-	Amount    int64     `json:"amount"`
-	Token     string    `json:"token"`
-	Timestamp time.Time `json:"timestamp"`
-	Status    string    `json:"status"`
-	Memo      string    `json:"memo"`
-	Private   bool      `json:"private"`
-}
+	"shared/address"
+	"z-core-wallet/keystore"
+	"z-core-wallet/storage"
+	"z-core-wallet/wallet"
+)
 
 // WalletService manages wallet operations
 type WalletService struct {
-	wallet    *Wallet
+	wallet    *wallet.Wallet
 	upgrader  websocket.Upgrader
-	clients   map[*websocket.Conn]bool
-	broadcast chan []byte
+	hub       *Hub
+	recovery  *RecoveryManager
+	policy    *PolicyManager
+	
+	autoShield  *AutoShielder
+	withholding *Withholder
+	mining      *MiningTracker
+	mempool     MempoolConfig
+	
+	store storage.Store // Persists accounts, coins, history and contacts
+
+	unlockGuard *UnlockGuard
+	rateLimits  RateLimitConfig
+	limiter     *RequestLimiter
+	idempotency *IdempotencyGuard
 }
 
-// NewWalletService creates a new wallet service
-func NewWalletService() *WalletService {
-	privateKey, _ := btcec.NewPrivateKey()
-	publicKey := privateKey.PubKey()
-	
-	// Generate address using secp256k1
-	pubKeyBytes := publicKey.SerializeCompressed()
-	hash := sha256.Sum256(pubKeyBytes)
-	address := base58.Encode(hash[:20])
-	
-	wallet := &Wallet{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
-		Address:    address,
-		Balance:    Balance{Z: 0, NU: 0},
-		TxHistory:  []Transaction{},
+// NewWalletService creates a new wallet service. When WALLET_KEYSTORE points at an
+// existing keystore the wallet starts locked until it is unlocked with its passphrase.
+func NewWalletService(store storage.Store, rateLimits RateLimitConfig, autoShield AutoShieldConfig, withholding WithholdingConfig, mempool MempoolConfig) (*WalletService, error) {
+	var ks *keystore.Keystore
+	if path := os.Getenv("WALLET_KEYSTORE"); path != "" {
+		ks = keystore.New(path)
 	}
 	
-	return &WalletService{
-		wallet: wallet,
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true // Allow all origins for development
-			},
-		},
-		clients:   make(map[*websocket.Conn]bool),
-		broadcast: make(chan []byte),
+	// Transparent input signatures commit to the chain they are made for
+	opts := wallet.Options{Store: store, Keystore: ks, ChainID: os.Getenv("WALLET_CHAIN_ID")}
+	if _, err := remoteSignerOptions(&opts); err != nil {
+		return nil, err
 	}
-}
-
-// CreateShieldedTransfer creates a private transaction
-func (ws *WalletService) CreateShieldedTransfer(recipient string, amount int64, memo string) (*ShieldedTransfer, error) {
-	// Create commitment and nullifier
-	nullifier := ws.generateNullifier()
 	
-	// Create zk-SNARK proof (hypothetical implementation)
-	zkProof, err := ws.generateZkProof(recipient, amount, memo, nullifier)
+	w, err := wallet.New(opts)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Encrypt memo (simplified - use proper encryption in production)
-	encryptedMemo := ws.encryptMemo(memo, recipient)
-	
-	transfer := &ShieldedTransfer{
-		Sender:    "", // Hidden
-		Recipient: "", // Hidden
-		Amount:    0,  // Hidden
-		Memo:      encryptedMemo,
-		ZkProof:   zkProof,
-		Nullifier: nullifier,
+	// Without its stored policy the wallet would spend unchecked
+	policy, err := NewPolicyManager(store, w.Account().Hex())
+	if err != nil {
+		return nil, err
+	}
+	recovery, err := NewRecoveryManager(store, w.Account().Hex())
+	if err != nil {
+		return nil, err
 	}
 	
-	return transfer, nil
-}
-
-// generateNullifier creates a unique nullifier to prevent double spending
-func (ws *WalletService) generateNullifier() string {
-	data := fmt.Sprintf("%s:%d", ws.wallet.Address, time.Now().UnixNano())
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
-}
-
-// generateZkProof creates a zk-SNARK proof for the transaction
-func (ws *WalletService) generateZkProof(recipient string, amount int64, memo string, nullifier string) ([]byte, error) {
-	// In a real implementation, this would use a zk-SNARK library
-	// For now, create a mock proof
-	data := fmt.Sprintf("%s:%s:%d:%s:%s", 
-		ws.wallet.Address, recipient, amount, memo, nullifier)
-	
-	// Sign with private key
-	hash := sha256.Sum256([]byte(data))
-	signature := crypto.Sign(hash[:], ws.wallet.PrivateKey.ToECDSA())
-	
-	return signature, nil
-}
-
-// encryptMemo encrypts the memo field
-func (ws *WalletService) encryptMemo(memo, recipient string) []byte {
-	// Simplified encryption - use proper encryption in production
-	data := []byte(memo)
-	key := sha256.Sum256([]byte(recipient))
-	
-	for i := range data {
-		data[i] ^= key[i%32]
+	ws := &WalletService{
+		wallet: w,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Allow all origins for development
+			},
+		},
+		hub:       NewHub(),
+		recovery:  recovery,
+		policy:    policy,
+		mempool:   mempool,
+		store:     store,
+		
+		unlockGuard: NewUnlockGuard(rateLimits),
+		rateLimits:  rateLimits,
+		limiter:     NewRequestLimiter(rateLimits),
 	}
+	ws.autoShield = NewAutoShielder(ws, autoShield)
 	
-	// Pad to 512 bytes
-	if len(data) < 512 {
-		padding := make([]byte, 512-len(data))
-		data = append(data, padding...)
+	if err := withholding.Validate(w.Address()); err != nil {
+		return nil, err
 	}
+	ws.withholding = NewWithholder(withholding)
+	ws.mining = NewMiningTracker(w.Account().NuChain())
+	ws.idempotency = NewIdempotencyGuard(store, func() string { return ws.wallet.Account().Hex() })
 	
-	return data[:512]
-}
-
-// SignMessage signs a message with the wallet's private key
-func (ws *WalletService) SignMessage(message string) (string, error) {
-	hash := sha256.Sum256([]byte(message))
-	signature := crypto.Sign(hash[:], ws.wallet.PrivateKey.ToECDSA())
-	return hex.EncodeToString(signature), nil
+	w.SetPendingHandler(ws.publishPending)
+	
+	return ws, nil
 }
 
 // HTTP Handlers
 
 func (ws *WalletService) getWalletInfo(w http.ResponseWriter, r *http.Request) {
+	addr := ws.wallet.Account()
+	
+	publicKey := ""
+	if pub := ws.wallet.PublicKey(); pub != nil {
+		publicKey = hex.EncodeToString(pub.SerializeCompressed())
+	}
+	balance := ws.wallet.Balance()
+	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"address": ws.wallet.Address,
-		"balance": ws.wallet.Balance,
-		"publicKey": hex.EncodeToString(ws.wallet.PublicKey.SerializeCompressed()),
+		"address": addr.ZBase58(),
+		"addresses": map[string]string{
+			"zchain":  addr.ZChain(),
+			"nuchain": addr.NuChain(),
+			"evm":     evmAddress(ws.wallet),
+			"zcash":   addr.Zcash(),
+		},
+		"balance": balance,
+		"tokens": tokenInfos(balance.Denoms()...),
+		"publicKey": publicKey,
+		"locked": ws.wallet.Locked(),
 	})
 }
 
+// evmAddress returns the wallet's EVM address, empty while it is locked
+func evmAddress(w *wallet.Wallet) string {
+	addr, ok := w.EVMAddress()
+	if !ok {
+		return ""
+	}
+	return addr.Hex()
+}
+
 func (ws *WalletService) getTransactionHistory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ws.wallet.TxHistory)
+	json.NewEncoder(w).Encode(ws.wallet.Transactions())
 }
 
 func (ws *WalletService) createTransaction(w http.ResponseWriter, r *http.Request) {
@@ -205,111 +161,201 @@ func (ws *WalletService) createTransaction(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	
+	req.Token, err = wallet.NormalizeDenom(req.Token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	// Unified addresses are paid through their shielded receiver when they have one
+	if recipient, err := address.ParseRecipient(req.Recipient); err == nil {
+		if recipient.Unified {
+			req.Recipient = recipient.String()
+		}
+		req.Private = req.Private || recipient.Kind == address.ReceiverShielded
+	}
+	if req.Private && req.Token != wallet.DenomZ {
+		http.Error(w, "Only Z can be sent privately", http.StatusBadRequest)
+		return
+	}
+	
+	if ws.wallet.Locked() {
+		http.Error(w, wallet.ErrLocked.Error(), http.StatusLocked)
+		return
+	}
+	
+	// Transactions breaking the spending policy wait for a second factor
+	if held := ws.holdIfViolates(req.Recipient, amount, req.Token, req.Memo, req.Private); held != nil {
+		writeHeld(w, held)
+		return
+	}
+	
 	if req.Private {
 		// Create shielded transfer
-		transfer, err := ws.CreateShieldedTransfer(req.Recipient, amount, req.Memo)
+		transfer, err := ws.wallet.CreateShieldedTransfer(req.Recipient, amount, req.Memo)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeShieldError(w, err)
 			return
 		}
 		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(transfer)
 	} else {
-		// Create regular transaction
-		tx := Transaction{
-			Hash:      ws.generateTxHash(),
-			From:      ws.wallet.Address,
-			To:        req.Recipient,
-			Amount:    amount,
-			Token:     req.Token,
-			Timestamp: time.Now(),
-			Status:    "pending",
-			Memo:      req.Memo,
-			Private:   false,
-		}
-		
-		ws.wallet.TxHistory = append(ws.wallet.TxHistory, tx)
+		tx := ws.CreateTransaction(req.Recipient, amount, req.Token, req.Memo)
 		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(tx)
 	}
 }
 
-func (ws *WalletService) generateTxHash() string {
-	data := fmt.Sprintf("%s:%d", ws.wallet.Address, time.Now().UnixNano())
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
+// CreateTransaction records a transparent transaction and notifies subscribers
+func (ws *WalletService) CreateTransaction(recipient string, amount int64, token, memo string) wallet.Transaction {
+	tx := ws.wallet.CreateTransaction(recipient, amount, token, memo)
+	ws.publish("transaction", tx)
+	
+	return tx
 }
 
 func (ws *WalletService) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := ws.upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
-		return
-	}
-	defer conn.Close()
-	
-	ws.clients[conn] = true
-	
 	// Send initial wallet state
-	walletState := map[string]interface{}{
+	ws.hub.ServeWS(&ws.upgrader, w, r, map[string]interface{}{
 		"type": "wallet_state",
 		"data": map[string]interface{}{
-			"address": ws.wallet.Address,
-			"balance": ws.wallet.Balance,
+			"address": ws.wallet.Address(),
+			"balance": ws.wallet.Balance(),
 		},
-	}
-	
-	if err := conn.WriteJSON(walletState); err != nil {
-		delete(ws.clients, conn)
+	})
+}
+
+// publish sends a typed event to WebSocket clients and gRPC subscribers
+func (ws *WalletService) publish(eventType string, data interface{}) {
+	message, err := json.Marshal(map[string]interface{}{
+		"type": eventType,
+		"data": data,
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
 		return
 	}
 	
-	// Listen for messages
-	for {
-		var msg map[string]interface{}
-		if err := conn.ReadJSON(&msg); err != nil {
-			delete(ws.clients, conn)
-			break
-		}
-		
-		// Handle different message types
-		switch msg["type"] {
-		case "ping":
-			conn.WriteJSON(map[string]string{"type": "pong"})
-		}
-	}
+	ws.hub.Broadcast(message)
 }
 
-func (ws *WalletService) broadcastToClients() {
-	for {
-		select {
-		case message := <-ws.broadcast:
-			for client := range ws.clients {
-				if err := client.WriteMessage(websocket.TextMessage, message); err != nil {
-					client.Close()
-					delete(ws.clients, client)
-				}
-			}
-		}
-	}
+// subscribe registers a channel receiving every broadcast message until cancelled
+func (ws *WalletService) subscribe() (<-chan []byte, func()) {
+	return ws.hub.Subscribe()
+}
+
+// routesV1 registers the original API the static frontend is written against
+func (ws *WalletService) routesV1(r *mux.Router) {
+	r.HandleFunc("/wallet", ws.getWalletInfo).Methods("GET")
+	r.HandleFunc("/wallet/unlock", ws.unlockWallet).Methods("POST")
+	r.HandleFunc("/wallet/lock", ws.lockWallet).Methods("POST")
+	r.HandleFunc("/wallet/keystore", ws.saveKeystore).Methods("POST")
+	r.HandleFunc("/transactions", ws.getTransactionHistory).Methods("GET")
+	r.HandleFunc("/transactions", ws.idempotency.Wrap("transactions", ws.createTransaction)).Methods("POST")
+	r.HandleFunc("/transactions/batch", ws.idempotency.Wrap("transactions/batch", ws.sendBatch)).Methods("POST")
+	r.HandleFunc("/mempool", ws.getMempool).Methods("GET")
+	r.HandleFunc("/mempool/{hash}", ws.evictPending).Methods("DELETE")
+	r.HandleFunc("/verify", ws.verifyMessage).Methods("POST")
+	r.HandleFunc("/tokens", ws.getTokens).Methods("GET")
+	r.HandleFunc("/balances", ws.setTokenBalance).Methods("POST")
+	
+	// Moving funds between the transparent and shielded pools
+	r.HandleFunc("/coins", ws.getCoins).Methods("GET")
+	r.HandleFunc("/utxos/consolidate", ws.consolidateUTXOs).Methods("POST")
+	r.HandleFunc("/shield", ws.shieldFunds).Methods("POST")
+	r.HandleFunc("/unshield", ws.unshieldFunds).Methods("POST")
+	r.HandleFunc("/autoshield", ws.getAutoShield).Methods("GET")
+	r.HandleFunc("/autoshield", ws.setAutoShield).Methods("POST")
+	
+	// Running a nuChain staking node
+	r.HandleFunc("/staking", ws.getStaking).Methods("GET")
+	r.HandleFunc("/staking/nodes", ws.createStakingNode).Methods("POST")
+	
+	// Diversified and unified receiving addresses
+	r.HandleFunc("/addresses", ws.getAddresses).Methods("GET")
+	r.HandleFunc("/addresses/diversified", ws.newDiversifiedAddress).Methods("POST")
+	r.HandleFunc("/addresses/detect", ws.detectReceiver).Methods("POST")
+	
+	// Social recovery routes
+	r.HandleFunc("/recovery", ws.getRecoveryState).Methods("GET")
+	r.HandleFunc("/recovery/guardians", ws.setGuardians).Methods("POST")
+	r.HandleFunc("/recovery/requests", ws.initiateRecovery).Methods("POST")
+	r.HandleFunc("/recovery/requests/{id}/approve", ws.approveRecovery).Methods("POST")
+	r.HandleFunc("/recovery/requests/{id}/cancel", ws.cancelRecovery).Methods("POST")
+	r.HandleFunc("/recovery/requests/{id}/execute", ws.executeRecovery).Methods("POST")
+	
+	// Spending policy routes
+	r.HandleFunc("/policy", ws.getPolicyState).Methods("GET")
+	r.HandleFunc("/policy", ws.setPolicy).Methods("POST")
+	r.HandleFunc("/policy/held/{id}/confirm", ws.confirmHeldSpend).Methods("POST")
+	r.HandleFunc("/policy/held/{id}/reject", ws.rejectHeldSpend).Methods("POST")
+	
+	// Address book
+	r.HandleFunc("/contacts", ws.getContacts).Methods("GET")
+	r.HandleFunc("/contacts", ws.saveContact).Methods("POST")
+	r.HandleFunc("/contacts/{name}", ws.deleteContact).Methods("DELETE")
+	
+	// Labels on addresses and outputs, and outputs held back from spending
+	r.HandleFunc("/labels", ws.getLabels).Methods("GET")
+	r.HandleFunc("/labels", ws.saveLabel).Methods("POST")
+	r.HandleFunc("/labels/{kind}/{ref}", ws.deleteLabel).Methods("DELETE")
+	r.HandleFunc("/utxos/{hash}/{index}/freeze", ws.freezeUTXO).Methods("POST")
+	r.HandleFunc("/utxos/{hash}/{index}/unfreeze", ws.unfreezeUTXO).Methods("POST")
+	
+	// Withholding a share of incoming mining rewards for savings or tax
+	r.HandleFunc("/rewards", ws.recordReward).Methods("POST")
+	r.HandleFunc("/withholding", ws.getWithholding).Methods("GET")
+	r.HandleFunc("/withholding", ws.setWithholding).Methods("POST")
+	r.HandleFunc("/withholding/settle", ws.settleWithholding).Methods("POST")
+	r.HandleFunc("/withholding/report", ws.getWithholdingReport).Methods("GET")
+	
+	// Mining dashboard for the rigs of linked miner addresses
+	r.HandleFunc("/mining", ws.getMining).Methods("GET")
+	r.HandleFunc("/mining", ws.applyMiningSnapshot).Methods("POST")
+	r.HandleFunc("/mining/miners", ws.linkMiner).Methods("POST")
+	r.HandleFunc("/mining/miners/{address}", ws.unlinkMiner).Methods("DELETE")
 }
 
 func main() {
-	walletService := NewWalletService()
+	if err := LoadTokens(); err != nil {
+		log.Fatalf("Failed to register tokens: %v", err)
+	}
+	
+	store, err := OpenWalletDatabase()
+	if err != nil {
+		log.Fatalf("Failed to open wallet database: %v", err)
+	}
+	defer store.Close()
+	
+	walletService, err := NewWalletService(store, LoadRateLimitConfig(), LoadAutoShieldConfig(), LoadWithholdingConfig(), LoadMempoolConfig())
+	if err != nil {
+		log.Fatalf("Failed to open wallet: %v", err)
+	}
 	
-	// Start WebSocket broadcaster
-	go walletService.broadcastToClients()
+	// Start the WebSocket and subscriber hub
+	go walletService.hub.Run()
+	
+	// Opt-in background shielding of transparent funds
+	go walletService.autoShield.Run()
+	
+	// Evict transactions that never confirmed
+	go walletService.watchMempool()
 	
 	// Setup routes
 	r := mux.NewRouter()
 	
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/wallet", walletService.getWalletInfo).Methods("GET")
-	api.HandleFunc("/transactions", walletService.getTransactionHistory).Methods("GET")
-	api.HandleFunc("/transactions", walletService.createTransaction).Methods("POST")
+	api.Use(walletService.limiter.Middleware(walletService.rateLimits.TrustProxy))
+	
+	// Every version is served under /api/{version}; plain /api negotiates one
+	v1 := mux.NewRouter()
+	walletService.routesV1(v1)
+	v2 := mux.NewRouter()
+	walletService.routesV2(v2)
+	api.PathPrefix("/").Handler(versionedAPI(map[string]http.Handler{"v1": v1, "v2": v2}))
 	
 	// WebSocket route
 	r.HandleFunc("/ws", walletService.handleWebSocket)
@@ -321,8 +367,9 @@ func main() {
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+APIKeyHeader+", "+APIVersionHeader+", "+IdempotencyKeyHeader)
+			w.Header().Set("Access-Control-Expose-Headers", APIVersionHeader+", Deprecation, Sunset, Link, "+IdempotentReplayHeader)
 			
 			if r.Method == "OPTIONS" {
 				return
@@ -337,6 +384,43 @@ func main() {
 		port = "8080"
 	}
 	
-	fmt.Printf("Z Core Wallet API server starting on port %s\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
-}
\ No newline at end of file
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: r,
+	}
+	
+	tlsSettings := LoadTLSSettings()
+	if tlsSettings.Enabled() {
+		server.TLSConfig, err = tlsSettings.Config()
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+	} else {
+		log.Printf("TLS is disabled; set WALLET_TLS_CERT/WALLET_TLS_KEY or WALLET_TLS_SELF_SIGNED=true to serve HTTPS")
+	}
+	
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9091"
+	}
+	
+	// gRPC shares the TLS configuration and rate limits with the REST API
+	go func() {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		
+		fmt.Printf("Z Core Wallet gRPC server starting on port %s\n", grpcPort)
+		log.Fatal(NewGRPCServer(walletService, server.TLSConfig).Serve(lis))
+	}()
+	
+	if server.TLSConfig == nil {
+		fmt.Printf("Z Core Wallet API server starting on port %s\n", port)
+		log.Fatal(server.ListenAndServe())
+	}
+	
+	fmt.Printf("Z Core Wallet API server starting on port %s (TLS, client certificates required: %t)\n",
+		port, tlsSettings.ClientCAFile != "")
+	log.Fatal(server.ListenAndServeTLS("", ""))
+}
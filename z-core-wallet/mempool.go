@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"z-core-wallet/wallet"
+)
+
+// MempoolConfig controls the view of transactions awaiting confirmation
+type MempoolConfig struct {
+	BlockTime time.Duration // Expected zChain block time for confirmation estimates
+	Expiry    time.Duration // Pending transactions older than this are evicted
+}
+
+// DefaultMempoolConfig matches the zChain block time target
+func DefaultMempoolConfig() MempoolConfig {
+	return MempoolConfig{
+		BlockTime: 500 * time.Millisecond,
+		Expiry:    time.Hour,
+	}
+}
+
+// LoadMempoolConfig applies WALLET_BLOCK_TIME and WALLET_MEMPOOL_EXPIRY overrides to the defaults
+func LoadMempoolConfig() MempoolConfig {
+	cfg := DefaultMempoolConfig()
+
+	cfg.BlockTime = envDuration("WALLET_BLOCK_TIME", cfg.BlockTime)
+	cfg.Expiry = envDuration("WALLET_MEMPOOL_EXPIRY", cfg.Expiry)
+
+	return cfg
+}
+
+// MempoolEntry is a pending transaction with its confirmation estimate
+type MempoolEntry struct {
+	wallet.PendingTx
+	FeeRate int64  `json:"fee_rate"` // Zero when unknown
+	Age     string `json:"age"`
+
+	// Estimates are omitted when the fee rate is unknown
+	ExpectedBlocks int64      `json:"expected_blocks,omitempty"`
+	EstimatedAt    *time.Time `json:"estimated_confirmation,omitempty"`
+	EstimatedIn    string     `json:"estimated_in,omitempty"`
+	PastEstimate   bool       `json:"past_estimate"`
+	EvictsAt       time.Time  `json:"evicts_at"`
+}
+
+func (cfg MempoolConfig) entry(p wallet.PendingTx, now time.Time) MempoolEntry {
+	entry := MempoolEntry{
+		PendingTx: p,
+		FeeRate:   p.FeeRate(),
+		Age:       now.Sub(p.Submitted).Round(time.Second).String(),
+		EvictsAt:  p.Submitted.Add(cfg.Expiry),
+	}
+
+	if blocks := p.ExpectedBlocks(); blocks > 0 {
+		at := p.Submitted.Add(time.Duration(blocks) * cfg.BlockTime)
+		entry.ExpectedBlocks = blocks
+		entry.EstimatedAt = &at
+		if remaining := at.Sub(now); remaining > 0 {
+			entry.EstimatedIn = remaining.Round(time.Millisecond).String()
+		} else {
+			entry.EstimatedIn = "0s"
+			entry.PastEstimate = true
+		}
+	}
+	return entry
+}
+
+// watchMempool evicts pending transactions once they outlive the expiry
+func (ws *WalletService) watchMempool() {
+	interval := ws.mempool.Expiry / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ws.wallet.ExpirePending(time.Now().Add(-ws.mempool.Expiry))
+	}
+}
+
+// publishPending pushes confirmations and evictions to WebSocket clients and
+// gRPC subscribers
+func (ws *WalletService) publishPending(event wallet.PendingEvent) {
+	ws.publish("mempool_"+event.Kind, ws.mempool.entry(event.Tx, time.Now()))
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getMempool(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+
+	entries := []MempoolEntry{}
+	for _, p := range ws.wallet.Pending() {
+		entries = append(entries, ws.mempool.entry(p, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (ws *WalletService) evictPending(w http.ResponseWriter, r *http.Request) {
+	err := ws.wallet.Evict(mux.Vars(r)["hash"])
+	if errors.Is(err, wallet.ErrNotPending) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
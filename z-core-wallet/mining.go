@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"shared/address"
+)
+
+// recentRewards is the number of rewards shown on the mining dashboard
+const recentRewards = 20
+
+var ErrUnknownMiner = errors.New("miner address is not linked")
+
+// MiningRig is a rig registered on nuChain, as stored by the mining module
+type MiningRig struct {
+	TokenID         uint64 `json:"token_id"`
+	Owner           string `json:"owner"`
+	ChainID         string `json:"chain_id"` // Chain the rig NFT lives on
+	ContractAddress string `json:"contract_address"`
+	HashPower       uint64 `json:"hash_power"`
+	WattConsumption uint64 `json:"watt_consumption"`
+	IsActive        bool   `json:"is_active"`
+	LastUpdated     int64  `json:"last_updated"`
+}
+
+// PoolMembership records a linked miner mining through a pool
+type PoolMembership struct {
+	Miner        string `json:"miner"`
+	Pool         string `json:"pool"`
+	ChainID      string `json:"chain_id"`
+	JoinedHeight int64  `json:"joined_height"`
+}
+
+// MiningSnapshot is the chain state of the linked miners, posted by whatever
+// follows nuChain in the same way /rewards and /balances are fed
+type MiningSnapshot struct {
+	Height int64            `json:"height"`
+	Rigs   []MiningRig      `json:"rigs"`
+	Pools  []PoolMembership `json:"pools"`
+}
+
+// MiningSummary is the dashboard view of the linked miners
+type MiningSummary struct {
+	Miners          []string           `json:"miners"`
+	Height          int64              `json:"height"`
+	Updated         time.Time          `json:"updated"`
+	Rigs            []MiningRig        `json:"rigs"`
+	Pools           []PoolMembership   `json:"pools"`
+	ActiveRigs      int                `json:"active_rigs"`
+	HashPower       uint64             `json:"hash_power"`       // Of active rigs
+	WattConsumption uint64             `json:"watt_consumption"` // Of active rigs
+	RecentRewards   []WithholdingEntry `json:"recent_rewards"`   // Newest first
+}
+
+// MiningTracker keeps the miner addresses linked to the wallet and the latest
+// chain state reported for them
+type MiningTracker struct {
+	mu      sync.Mutex
+	miners  map[string]string // Canonical hex address -> address as linked
+	height  int64
+	updated time.Time
+	rigs    []MiningRig
+	pools   []PoolMembership
+}
+
+// NewMiningTracker creates a tracker with the wallet's own account linked
+func NewMiningTracker(own string) *MiningTracker {
+	t := &MiningTracker{miners: make(map[string]string)}
+	t.Link(own)
+	return t
+}
+
+// minerKey returns the canonical form of a miner account in any encoding
+func minerKey(s string) (string, error) {
+	addr, _, _, err := address.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return addr.Hex(), nil
+}
+
+// Link adds a miner address; rigs it owns show up in the dashboard
+func (t *MiningTracker) Link(miner string) error {
+	key, err := minerKey(miner)
+	if err != nil {
+		return fmt.Errorf("invalid miner address: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.miners[key] = miner
+	return nil
+}
+
+// Unlink removes a miner address and the state reported for it
+func (t *MiningTracker) Unlink(miner string) error {
+	key, err := minerKey(miner)
+	if err != nil {
+		return fmt.Errorf("invalid miner address: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.miners[key]; !ok {
+		return ErrUnknownMiner
+	}
+	delete(t.miners, key)
+	t.rigs = t.linkedRigs(t.rigs)
+	t.pools = t.linkedPools(t.pools)
+	return nil
+}
+
+// Apply replaces the reported chain state, keeping only what concerns linked
+// miners. Snapshots older than the current one are ignored.
+func (t *MiningTracker) Apply(snapshot MiningSnapshot) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if snapshot.Height < t.height {
+		return false
+	}
+	t.height = snapshot.Height
+	t.updated = time.Now()
+	t.rigs = t.linkedRigs(snapshot.Rigs)
+	t.pools = t.linkedPools(snapshot.Pools)
+	return true
+}
+
+// linkedRigs filters rigs owned by linked miners; callers hold mu
+func (t *MiningTracker) linkedRigs(rigs []MiningRig) []MiningRig {
+	linked := make([]MiningRig, 0, len(rigs))
+	for _, rig := range rigs {
+		if key, err := minerKey(rig.Owner); err == nil && t.miners[key] != "" {
+			linked = append(linked, rig)
+		}
+	}
+	sort.Slice(linked, func(i, j int) bool {
+		if linked[i].ChainID != linked[j].ChainID {
+			return linked[i].ChainID < linked[j].ChainID
+		}
+		return linked[i].TokenID < linked[j].TokenID
+	})
+	return linked
+}
+
+// linkedPools filters memberships of linked miners; callers hold mu
+func (t *MiningTracker) linkedPools(pools []PoolMembership) []PoolMembership {
+	linked := make([]PoolMembership, 0, len(pools))
+	for _, pool := range pools {
+		if key, err := minerKey(pool.Miner); err == nil && t.miners[key] != "" {
+			linked = append(linked, pool)
+		}
+	}
+	return linked
+}
+
+// Summary totals the reported state of the linked miners
+func (t *MiningTracker) Summary(rewards []WithholdingEntry) MiningSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := MiningSummary{
+		Miners:        make([]string, 0, len(t.miners)),
+		Height:        t.height,
+		Updated:       t.updated,
+		Rigs:          append([]MiningRig{}, t.rigs...),
+		Pools:         append([]PoolMembership{}, t.pools...),
+		RecentRewards: []WithholdingEntry{},
+	}
+	for _, miner := range t.miners {
+		summary.Miners = append(summary.Miners, miner)
+	}
+	sort.Strings(summary.Miners)
+
+	for _, rig := range t.rigs {
+		if !rig.IsActive {
+			continue
+		}
+		summary.ActiveRigs++
+		summary.HashPower += rig.HashPower
+		summary.WattConsumption += rig.WattConsumption
+	}
+
+	for i := len(rewards) - 1; i >= 0 && len(summary.RecentRewards) < recentRewards; i-- {
+		summary.RecentRewards = append(summary.RecentRewards, rewards[i])
+	}
+	return summary
+}
+
+// miningSummary returns the dashboard with the rewards recorded through /rewards
+func (ws *WalletService) miningSummary() MiningSummary {
+	return ws.mining.Summary(ws.withholding.Entries())
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getMining(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ws.miningSummary())
+}
+
+func (ws *WalletService) linkMiner(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Address string `json:"address"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ws.mining.Link(req.Address); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary := ws.miningSummary()
+	ws.publish("mining", summary)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (ws *WalletService) unlinkMiner(w http.ResponseWriter, r *http.Request) {
+	err := ws.mining.Unlink(mux.Vars(r)["address"])
+	if errors.Is(err, ErrUnknownMiner) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws.publish("mining", ws.miningSummary())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ws *WalletService) applyMiningSnapshot(w http.ResponseWriter, r *http.Request) {
+	var snapshot MiningSnapshot
+	if err := json.NewDecoder(r.Body).Decode(&snapshot); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !ws.mining.Apply(snapshot) {
+		http.Error(w, "snapshot is older than the current one", http.StatusConflict)
+		return
+	}
+
+	summary := ws.miningSummary()
+	ws.publish("mining", summary)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"shared/address"
+	"z-core-wallet/storage"
+	"z-core-wallet/wallet"
+)
+
+// PolicyDomain separates spending policy confirmations from any other signed message
+const PolicyDomain = "z-wallet-policy-v1"
+
+// HeldSpendTTL is how long a held transaction waits for confirmation
+const HeldSpendTTL = 24 * time.Hour
+
+// spendWindow is the rolling period the daily limit applies to
+const spendWindow = 24 * time.Hour
+
+// Held spend states
+const (
+	SpendHeld      = "held"
+	SpendConfirmed = "confirmed"
+	SpendRejected  = "rejected"
+	SpendExpired   = "expired"
+)
+
+var (
+	ErrSpendNotFound = errors.New("held transaction not found")
+	ErrSpendNotHeld  = errors.New("transaction is no longer held")
+	ErrPolicyAuth    = errors.New("valid confirmation token or cosigner signature required")
+)
+
+// SpendLimit caps spending of one token. Zero means unlimited.
+type SpendLimit struct {
+	PerTransaction int64 `json:"per_transaction"`
+	Daily          int64 `json:"daily"` // Rolling 24 hours
+}
+
+// SpendingPolicy restricts what the wallet may send without a second factor.
+// Transactions that break it are held until confirmed with the confirmation
+// token or a signature from the cosigner key.
+type SpendingPolicy struct {
+	Limits    map[string]SpendLimit `json:"limits"`             // Keyed by token, e.g. Z or NU
+	Whitelist []string              `json:"whitelist"`          // When set, only these recipients are allowed
+	Cosigner  string                `json:"cosigner,omitempty"` // Compressed secp256k1 public key (hex)
+}
+
+// Digest returns the hash a cosigner signs to authorize replacing the policy with p
+func (p *SpendingPolicy) Digest() []byte {
+	// Map keys are marshalled in sorted order so the encoding is stable
+	data, _ := json.Marshal(p)
+	hash := sha256.Sum256(append([]byte(PolicyDomain+":update:"), data...))
+	return hash[:]
+}
+
+// PolicyAuth is the second factor for held transactions and policy changes
+type PolicyAuth struct {
+	ConfirmationToken string `json:"confirmation_token"`
+	Signature         string `json:"signature"` // Cosigner signature over the digest, hex
+}
+
+// HeldSpend is a transaction that broke the spending policy
+type HeldSpend struct {
+	ID         string    `json:"id"`
+	Recipient  string    `json:"recipient"`
+	Amount     int64     `json:"amount"`
+	Token      string    `json:"token"`
+	Memo       string    `json:"memo"`
+	Private    bool      `json:"private"`
+	Violations []string  `json:"violations"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Digest returns the hash a cosigner signs to confirm this transaction
+func (s *HeldSpend) Digest() []byte {
+	data := fmt.Sprintf("%s:%s:%s:%d:%s:%t", PolicyDomain, s.ID, s.Recipient, s.Amount, s.Token, s.Private)
+	hash := sha256.Sum256([]byte(data))
+	return hash[:]
+}
+
+// PolicyManager enforces the spending policy and tracks held transactions.
+// Its state is kept in the wallet store, so a restart neither lifts the
+// policy nor releases or forgets held transactions.
+type PolicyManager struct {
+	mu        sync.Mutex
+	store     storage.Store
+	account   string // Hex account the state is stored under
+	policy    *SpendingPolicy
+	tokenHash []byte // SHA-256 of the confirmation token
+	held      map[string]*HeldSpend
+}
+
+// NewPolicyManager loads the account's policy state from store; without one
+// there is no policy and everything is allowed. A state that cannot be loaded
+// is an error rather than no policy, so the wallet never runs unprotected.
+func NewPolicyManager(store storage.Store, account string) (*PolicyManager, error) {
+	m := &PolicyManager{
+		store:   store,
+		account: account,
+		held:    make(map[string]*HeldSpend),
+	}
+
+	state, err := store.PolicyState(account)
+	if errors.Is(err, storage.ErrNotFound) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spending policy: %w", err)
+	}
+
+	var held []*HeldSpend
+	if err := json.Unmarshal(state.Policy, &m.policy); err != nil {
+		return nil, fmt.Errorf("failed to load spending policy: %w", err)
+	}
+	if err := json.Unmarshal(state.Held, &held); err != nil {
+		return nil, fmt.Errorf("failed to load held transactions: %w", err)
+	}
+	m.tokenHash = state.TokenHash
+	for _, spend := range held {
+		m.held[spend.ID] = spend
+	}
+	return m, nil
+}
+
+// commit stores the state the manager would have with policy, tokenHash and
+// held and only then adopts it, so a failed write changes nothing; callers
+// hold mu
+func (m *PolicyManager) commit(policy *SpendingPolicy, tokenHash []byte, held map[string]*HeldSpend) error {
+	spends := make([]*HeldSpend, 0, len(held))
+	for _, spend := range held {
+		spends = append(spends, spend)
+	}
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	heldJSON, err := json.Marshal(spends)
+	if err != nil {
+		return err
+	}
+
+	state := storage.PolicyState{Policy: policyJSON, TokenHash: tokenHash, Held: heldJSON}
+	if err := m.store.SavePolicyState(m.account, state); err != nil {
+		return fmt.Errorf("failed to save spending policy: %w", err)
+	}
+	m.policy, m.tokenHash, m.held = policy, tokenHash, held
+	return nil
+}
+
+// withStatus returns the held transactions with the one of id, if any, copied
+// in status; the others are shared
+func (m *PolicyManager) withStatus(id, status string) (map[string]*HeldSpend, *HeldSpend) {
+	held := make(map[string]*HeldSpend, len(m.held))
+	var changed *HeldSpend
+	for key, spend := range m.held {
+		if key == id {
+			copied := *spend
+			copied.Status = status
+			spend, changed = &copied, &copied
+		}
+		held[key] = spend
+	}
+	return held, changed
+}
+
+// SetPolicy installs a policy. Replacing an existing one needs its second factor
+// so a stolen API session cannot simply lift the limits. At least one of the
+// confirmation token or a cosigner must be set so held transactions can be
+// confirmed. Transactions held under the old policy are rejected.
+func (m *PolicyManager) SetPolicy(policy SpendingPolicy, confirmationToken string, auth PolicyAuth) error {
+	limits := make(map[string]SpendLimit, len(policy.Limits))
+	for token, limit := range policy.Limits {
+		if limit.PerTransaction < 0 || limit.Daily < 0 {
+			return fmt.Errorf("limits for %s cannot be negative", token)
+		}
+		limits[strings.ToUpper(token)] = limit
+	}
+	policy.Limits = limits
+	for i, recipient := range policy.Whitelist {
+		if _, _, _, err := address.Parse(recipient); err != nil {
+			return fmt.Errorf("invalid whitelist entry %d: %w", i, err)
+		}
+	}
+	if policy.Cosigner != "" {
+		pubKey, err := parseCompressedPubKey(policy.Cosigner)
+		if err != nil {
+			return fmt.Errorf("invalid cosigner: %w", err)
+		}
+		policy.Cosigner = hex.EncodeToString(pubKey.SerializeCompressed())
+	}
+	if confirmationToken == "" && policy.Cosigner == "" {
+		return fmt.Errorf("a confirmation token or cosigner is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.policy != nil && !m.authorized(auth, policy.Digest()) {
+		return ErrPolicyAuth
+	}
+
+	var tokenHash []byte
+	if confirmationToken != "" {
+		hash := sha256.Sum256([]byte(confirmationToken))
+		tokenHash = hash[:]
+	}
+
+	held := make(map[string]*HeldSpend, len(m.held))
+	for id, spend := range m.held {
+		if spend.Status == SpendHeld {
+			rejected := *spend
+			rejected.Status = SpendRejected
+			spend = &rejected
+		}
+		held[id] = spend
+	}
+	return m.commit(&policy, tokenHash, held)
+}
+
+// Check returns the policy rules a transaction would break. history is the
+// wallet's transaction history, used for the daily limit.
+func (m *PolicyManager) Check(history []wallet.Transaction, from, recipient string, amount int64, token string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.policy == nil {
+		return nil
+	}
+
+	var violations []string
+
+	if limit, ok := m.policy.Limits[strings.ToUpper(token)]; ok {
+		if limit.PerTransaction > 0 && amount > limit.PerTransaction {
+			violations = append(violations, fmt.Sprintf("amount %d exceeds per transaction limit of %d %s", amount, limit.PerTransaction, token))
+		}
+		if limit.Daily > 0 {
+			spent := spentSince(history, from, token, time.Now().Add(-spendWindow))
+			if spent+amount > limit.Daily {
+				violations = append(violations, fmt.Sprintf("amount %d with %d already spent exceeds daily limit of %d %s", amount, spent, limit.Daily, token))
+			}
+		}
+	}
+
+	if len(m.policy.Whitelist) > 0 && !sameAccount(recipient, from) && !m.whitelisted(recipient) {
+		violations = append(violations, fmt.Sprintf("recipient %s is not whitelisted", recipient))
+	}
+
+	return violations
+}
+
+// Hold records a transaction that broke the policy until it is confirmed. If
+// it cannot be stored it is still held, until the wallet restarts.
+func (m *PolicyManager) Hold(recipient string, amount int64, token, memo string, private bool, violations []string) *HeldSpend {
+	idBytes := make([]byte, 16)
+	rand.Read(idBytes)
+
+	now := time.Now()
+	spend := &HeldSpend{
+		ID:         hex.EncodeToString(idBytes),
+		Recipient:  recipient,
+		Amount:     amount,
+		Token:      token,
+		Memo:       memo,
+		Private:    private,
+		Violations: violations,
+		Status:     SpendHeld,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(HeldSpendTTL),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	held := make(map[string]*HeldSpend, len(m.held)+1)
+	for id, existing := range m.held {
+		held[id] = existing
+	}
+	held[spend.ID] = spend
+	if err := m.commit(m.policy, m.tokenHash, held); err != nil {
+		log.Printf("Holding transaction %s in memory only: %v", spend.ID, err)
+		m.held[spend.ID] = spend
+	}
+	return spend
+}
+
+// Confirm releases a held transaction given the confirmation token or a cosigner
+// signature over its digest. The caller submits the returned transaction.
+func (m *PolicyManager) Confirm(id string, auth PolicyAuth) (*HeldSpend, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	spend, err := m.activeSpend(id)
+	if err != nil {
+		return nil, err
+	}
+	if !m.authorized(auth, spend.Digest()) {
+		return nil, ErrPolicyAuth
+	}
+
+	// Stored as confirmed before it is released, so a restart cannot send it
+	// twice
+	held, confirmed := m.withStatus(id, SpendConfirmed)
+	if err := m.commit(m.policy, m.tokenHash, held); err != nil {
+		return nil, err
+	}
+	return confirmed, nil
+}
+
+// Reject drops a held transaction
+func (m *PolicyManager) Reject(id string) (*HeldSpend, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.activeSpend(id); err != nil {
+		return nil, err
+	}
+
+	held, rejected := m.withStatus(id, SpendRejected)
+	if err := m.commit(m.policy, m.tokenHash, held); err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+// State returns the policy and all held transactions
+func (m *PolicyManager) State() (*SpendingPolicy, []*HeldSpend) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	spends := make([]*HeldSpend, 0, len(m.held))
+	for _, spend := range m.held {
+		m.expire(spend)
+		spends = append(spends, spend)
+	}
+	return m.policy, spends
+}
+
+func (m *PolicyManager) activeSpend(id string) (*HeldSpend, error) {
+	spend, ok := m.held[id]
+	if !ok {
+		return nil, ErrSpendNotFound
+	}
+	m.expire(spend)
+	if spend.Status != SpendHeld {
+		return nil, ErrSpendNotHeld
+	}
+	return spend, nil
+}
+
+func (m *PolicyManager) expire(spend *HeldSpend) {
+	if spend.Status == SpendHeld && time.Now().After(spend.ExpiresAt) {
+		spend.Status = SpendExpired
+	}
+}
+
+// authorized checks the confirmation token or a cosigner signature over digest
+func (m *PolicyManager) authorized(auth PolicyAuth, digest []byte) bool {
+	if auth.ConfirmationToken != "" && m.tokenHash != nil {
+		hash := sha256.Sum256([]byte(auth.ConfirmationToken))
+		if subtle.ConstantTimeCompare(hash[:], m.tokenHash) == 1 {
+			return true
+		}
+	}
+
+	if auth.Signature != "" && m.policy.Cosigner != "" {
+		signer, err := recoverSigner(digest, auth.Signature)
+		if err == nil && signer == m.policy.Cosigner {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *PolicyManager) whitelisted(recipient string) bool {
+	for _, allowed := range m.policy.Whitelist {
+		if sameAccount(recipient, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAccount compares two addresses in any supported encoding
+func sameAccount(a, b string) bool {
+	addrA, _, _, errA := address.Parse(a)
+	addrB, _, _, errB := address.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return addrA.Equal(addrB)
+}
+
+// spentSince sums the outgoing amount of token sent by from after since
+func spentSince(history []wallet.Transaction, from, token string, since time.Time) int64 {
+	var spent int64
+	for _, tx := range history {
+		if tx.From != from || tx.To == from || !strings.EqualFold(tx.Token, token) {
+			continue
+		}
+		if tx.Status == "failed" || tx.Timestamp.Before(since) {
+			continue
+		}
+		spent += tx.Amount
+	}
+	return spent
+}
+
+// holdIfViolates checks a spend against the policy and holds it if it breaks any rule
+func (ws *WalletService) holdIfViolates(recipient string, amount int64, token, memo string, private bool) *HeldSpend {
+	if token == "" {
+		token = "Z" // Shielded transfers carry no token and always move Z
+	}
+
+	violations := ws.policy.Check(ws.wallet.Transactions(), ws.wallet.Address(), recipient, amount, token)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	spend := ws.policy.Hold(recipient, amount, token, memo, private, violations)
+	ws.publish("spend_held", spend)
+	return spend
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getPolicyState(w http.ResponseWriter, r *http.Request) {
+	policy, held := ws.policy.State()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"policy": policy,
+		"held":   held,
+	})
+}
+
+func (ws *WalletService) setPolicy(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Policy            SpendingPolicy `json:"policy"`
+		ConfirmationToken string         `json:"confirmation_token"` // New token for the policy being set
+		Auth              PolicyAuth     `json:"auth"`               // Second factor of the current policy
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := ws.policy.SetPolicy(req.Policy, req.ConfirmationToken, req.Auth); err != nil {
+		writePolicyError(w, err)
+		return
+	}
+
+	ws.getPolicyState(w, r)
+}
+
+func (ws *WalletService) confirmHeldSpend(w http.ResponseWriter, r *http.Request) {
+	var auth PolicyAuth
+
+	if err := json.NewDecoder(r.Body).Decode(&auth); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if ws.wallet.Locked() {
+		http.Error(w, wallet.ErrLocked.Error(), http.StatusLocked)
+		return
+	}
+
+	spend, err := ws.policy.Confirm(mux.Vars(r)["id"], auth)
+	if err != nil {
+		writePolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if spend.Private {
+		transfer, err := ws.wallet.CreateShieldedTransfer(spend.Recipient, spend.Amount, spend.Memo)
+		if err != nil {
+			writeShieldError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(transfer)
+	} else {
+		json.NewEncoder(w).Encode(ws.CreateTransaction(spend.Recipient, spend.Amount, spend.Token, spend.Memo))
+	}
+}
+
+func (ws *WalletService) rejectHeldSpend(w http.ResponseWriter, r *http.Request) {
+	spend, err := ws.policy.Reject(mux.Vars(r)["id"])
+	if err != nil {
+		writePolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(spend)
+}
+
+// writeHeld answers a spend that was held by the policy with 202 Accepted
+func writeHeld(w http.ResponseWriter, spend *HeldSpend) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"held":   spend,
+		"digest": hex.EncodeToString(spend.Digest()),
+	})
+}
+
+func writePolicyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrSpendNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrPolicyAuth):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrSpendNotHeld):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyHeader identifies API clients for per-key rate limiting
+const APIKeyHeader = "X-API-Key"
+
+// idleBucketTTL is how long an unused bucket is kept before it is pruned
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimitConfig controls request throttling and keystore unlock lockout.
+// A zero per-minute limit disables that limiter.
+type RateLimitConfig struct {
+	PerIPPerMinute     int
+	PerAPIKeyPerMinute int
+	Burst              int
+	TrustProxy         bool // Take the client IP from X-Forwarded-For
+
+	UnlockFreeAttempts int
+	UnlockBaseDelay    time.Duration
+	UnlockMaxDelay     time.Duration
+}
+
+// DefaultRateLimitConfig returns limits suitable for a wallet serving a single user
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		PerIPPerMinute:     120,
+		PerAPIKeyPerMinute: 600,
+		Burst:              20,
+		TrustProxy:         false,
+		UnlockFreeAttempts: 3,
+		UnlockBaseDelay:    time.Second,
+		UnlockMaxDelay:     time.Hour,
+	}
+}
+
+// LoadRateLimitConfig applies WALLET_RATE_LIMIT_* and WALLET_UNLOCK_* overrides to the defaults
+func LoadRateLimitConfig() RateLimitConfig {
+	cfg := DefaultRateLimitConfig()
+
+	cfg.PerIPPerMinute = envInt("WALLET_RATE_LIMIT_IP", cfg.PerIPPerMinute)
+	cfg.PerAPIKeyPerMinute = envInt("WALLET_RATE_LIMIT_API_KEY", cfg.PerAPIKeyPerMinute)
+	cfg.Burst = envInt("WALLET_RATE_LIMIT_BURST", cfg.Burst)
+	cfg.TrustProxy = os.Getenv("WALLET_TRUST_PROXY") == "true"
+	cfg.UnlockFreeAttempts = envInt("WALLET_UNLOCK_FREE_ATTEMPTS", cfg.UnlockFreeAttempts)
+	cfg.UnlockBaseDelay = envDuration("WALLET_UNLOCK_BASE_DELAY", cfg.UnlockBaseDelay)
+	cfg.UnlockMaxDelay = envDuration("WALLET_UNLOCK_MAX_DELAY", cfg.UnlockMaxDelay)
+
+	return cfg
+}
+
+func envInt(name string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(name)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
+// tokenBucket refills continuously up to burst tokens
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter keeps one token bucket per client key
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter allows perMinute requests per key with bursts up to burst
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes a token for key, or returns how long until one is available
+func (l *RateLimiter) Allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return wait, false
+	}
+
+	bucket.tokens--
+	return 0, true
+}
+
+// prune drops buckets that have been idle long enough to be full again
+func (l *RateLimiter) prune() {
+	for range time.Tick(idleBucketTTL) {
+		l.mu.Lock()
+		cutoff := time.Now().Add(-idleBucketTTL)
+		for key, bucket := range l.buckets {
+			if bucket.last.Before(cutoff) {
+				delete(l.buckets, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// RequestLimiter throttles every request by client IP and, when an API key is
+// presented, additionally by key. Both limits apply so rotating keys does not
+// lift the per-IP limit. REST and gRPC share one limiter.
+type RequestLimiter struct {
+	byIP  *RateLimiter
+	byKey *RateLimiter
+}
+
+// NewRequestLimiter creates the limiters enabled in cfg
+func NewRequestLimiter(cfg RateLimitConfig) *RequestLimiter {
+	l := &RequestLimiter{}
+	if cfg.PerIPPerMinute > 0 {
+		l.byIP = NewRateLimiter(cfg.PerIPPerMinute, cfg.Burst)
+		go l.byIP.prune()
+	}
+	if cfg.PerAPIKeyPerMinute > 0 {
+		l.byKey = NewRateLimiter(cfg.PerAPIKeyPerMinute, cfg.Burst)
+		go l.byKey.prune()
+	}
+	return l
+}
+
+// Allow takes a token for the client IP and API key, or returns how long to wait
+func (l *RequestLimiter) Allow(ip, apiKey string) (time.Duration, bool) {
+	if l.byIP != nil {
+		if wait, ok := l.byIP.Allow(ip); !ok {
+			return wait, false
+		}
+	}
+
+	if apiKey != "" && l.byKey != nil {
+		// Keys are hashed so the limiter never holds client secrets
+		hash := sha256.Sum256([]byte(apiKey))
+		if wait, ok := l.byKey.Allow(hex.EncodeToString(hash[:])); !ok {
+			return wait, false
+		}
+	}
+
+	return 0, true
+}
+
+// Middleware applies the limiter to HTTP requests
+func (l *RequestLimiter) Middleware(trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait, ok := l.Allow(clientIP(r, trustProxy), r.Header.Get(APIKeyHeader)); !ok {
+				writeRetryAfter(w, wait, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the caller's address, honouring X-Forwarded-For only behind a trusted proxy
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// unlockBusyWait is how long a caller is told to wait while another unlock
+// attempt is being decrypted
+const unlockBusyWait = time.Second
+
+// UnlockGuard applies an exponential lockout after repeated failed keystore unlocks.
+// The lockout is global rather than per client so spreading guesses over many
+// addresses does not speed up a brute-force attack. Attempts run one at a
+// time: decrypting takes long enough that parallel guesses admitted before the
+// first failure is recorded would otherwise all escape the lockout.
+type UnlockGuard struct {
+	mu           sync.Mutex
+	failures     int
+	lockedUntil  time.Time
+	inFlight     bool
+	freeAttempts int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+}
+
+// NewUnlockGuard creates a guard from the unlock settings in cfg
+func NewUnlockGuard(cfg RateLimitConfig) *UnlockGuard {
+	return &UnlockGuard{
+		freeAttempts: cfg.UnlockFreeAttempts,
+		baseDelay:    cfg.UnlockBaseDelay,
+		maxDelay:     cfg.UnlockMaxDelay,
+	}
+}
+
+// Allow reserves the attempt at an unlock if one may be made now, or reports
+// how long to wait. A reserved attempt ends with Success, Failure or Release.
+func (g *UnlockGuard) Allow() (time.Duration, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if wait := time.Until(g.lockedUntil); wait > 0 {
+		return wait, false
+	}
+	if g.inFlight {
+		return unlockBusyWait, false
+	}
+	g.inFlight = true
+	return 0, true
+}
+
+// Release ends a reserved attempt that failed for another reason than the
+// passphrase
+func (g *UnlockGuard) Release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.inFlight = false
+}
+
+// Failure records a wrong passphrase and returns the resulting lockout.
+// After the free attempts each failure doubles the delay up to maxDelay.
+func (g *UnlockGuard) Failure() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.inFlight = false
+	g.failures++
+	if g.failures <= g.freeAttempts {
+		return 0
+	}
+
+	delay := g.baseDelay
+	for i := g.freeAttempts + 1; i < g.failures && delay < g.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.maxDelay {
+		delay = g.maxDelay
+	}
+
+	g.lockedUntil = time.Now().Add(delay)
+	return delay
+}
+
+// Success clears the failure count after a correct passphrase
+func (g *UnlockGuard) Success() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.inFlight = false
+	g.failures = 0
+	g.lockedUntil = time.Time{}
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+
+	"shared/address"
+	"z-core-wallet/storage"
+	"z-core-wallet/wallet"
+)
+
+// RecoveryDomain separates guardian approvals from any other signed message
+const RecoveryDomain = "z-wallet-recovery-v1"
+
+// DefaultRecoveryDelay is the time an approved recovery waits before it can execute
+const DefaultRecoveryDelay = 48 * time.Hour
+
+// Recovery request states
+const (
+	RecoveryPending   = "pending"
+	RecoveryApproved  = "approved"
+	RecoveryCancelled = "cancelled"
+	RecoveryExecuted  = "executed"
+)
+
+var (
+	ErrNoGuardians       = errors.New("no guardians configured")
+	ErrRecoveryNotFound  = errors.New("recovery request not found")
+	ErrRecoveryNotActive = errors.New("recovery request is no longer active")
+	ErrNotGuardian       = errors.New("signature does not belong to a guardian")
+	ErrRecoveryTimelock  = errors.New("recovery delay has not elapsed")
+)
+
+// GuardianSet designates the keys allowed to rotate the account owner
+type GuardianSet struct {
+	Guardians []string      `json:"guardians"` // Compressed secp256k1 public keys (hex)
+	Threshold int           `json:"threshold"`
+	Delay     time.Duration `json:"delay"`
+}
+
+// RecoveryRequest is an M-of-N guardian proposal to move the account to a new key
+type RecoveryRequest struct {
+	ID           string            `json:"id"`
+	Account      string            `json:"account"`
+	NewPublicKey string            `json:"new_public_key"`
+	Approvals    map[string]string `json:"approvals"` // guardian pubkey -> signature
+	Status       string            `json:"status"`
+	InitiatedAt  time.Time         `json:"initiated_at"`
+	ApprovedAt   time.Time         `json:"approved_at,omitempty"`
+	ExecutableAt time.Time         `json:"executable_at,omitempty"`
+}
+
+// Digest returns the hash every guardian signs to approve this request
+func (r *RecoveryRequest) Digest() []byte {
+	data := fmt.Sprintf("%s:%s:%s:%s", RecoveryDomain, r.Account, r.ID, r.NewPublicKey)
+	hash := sha256.Sum256([]byte(data))
+	return hash[:]
+}
+
+// clone returns a copy of the request that shares none of its approvals
+func (r *RecoveryRequest) clone() *RecoveryRequest {
+	copied := *r
+	copied.Approvals = make(map[string]string, len(r.Approvals))
+	for guardian, signature := range r.Approvals {
+		copied.Approvals[guardian] = signature
+	}
+	return &copied
+}
+
+// RecoveryManager tracks guardians and in-flight recovery requests. Both are
+// kept in the wallet store, so a restart neither drops the guardians nor
+// loses approvals or the delay already served.
+type RecoveryManager struct {
+	mu        sync.Mutex
+	store     storage.Store
+	account   string // Hex account the state is stored under
+	guardians *GuardianSet
+	requests  map[string]*RecoveryRequest
+}
+
+// NewRecoveryManager loads the account's guardians and recovery requests
+// from store; without them there are none
+func NewRecoveryManager(store storage.Store, account string) (*RecoveryManager, error) {
+	m := &RecoveryManager{
+		store:    store,
+		account:  account,
+		requests: make(map[string]*RecoveryRequest),
+	}
+
+	state, err := store.RecoveryState(account)
+	if errors.Is(err, storage.ErrNotFound) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recovery state: %w", err)
+	}
+
+	var requests []*RecoveryRequest
+	if err := json.Unmarshal(state.Guardians, &m.guardians); err != nil {
+		return nil, fmt.Errorf("failed to load guardians: %w", err)
+	}
+	if err := json.Unmarshal(state.Requests, &requests); err != nil {
+		return nil, fmt.Errorf("failed to load recovery requests: %w", err)
+	}
+	for _, req := range requests {
+		m.requests[req.ID] = req
+	}
+	return m, nil
+}
+
+// commit stores the state the manager would have with guardians and
+// requests and only then adopts it, so a failed write changes nothing;
+// callers hold mu
+func (m *RecoveryManager) commit(guardians *GuardianSet, requests map[string]*RecoveryRequest) error {
+	list := make([]*RecoveryRequest, 0, len(requests))
+	for _, req := range requests {
+		list = append(list, req)
+	}
+	guardiansJSON, err := json.Marshal(guardians)
+	if err != nil {
+		return err
+	}
+	requestsJSON, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	state := storage.RecoveryState{Guardians: guardiansJSON, Requests: requestsJSON}
+	if err := m.store.SaveRecoveryState(m.account, state); err != nil {
+		return fmt.Errorf("failed to save recovery state: %w", err)
+	}
+	m.guardians, m.requests = guardians, requests
+	return nil
+}
+
+// withRequest returns the requests with req in place of the one of its ID;
+// the others are shared
+func (m *RecoveryManager) withRequest(req *RecoveryRequest) map[string]*RecoveryRequest {
+	requests := make(map[string]*RecoveryRequest, len(m.requests)+1)
+	for id, existing := range m.requests {
+		requests[id] = existing
+	}
+	requests[req.ID] = req
+	return requests
+}
+
+// SetGuardians replaces the guardian set. Pending requests are cancelled because
+// their approvals were collected under the old set.
+func (m *RecoveryManager) SetGuardians(set GuardianSet) error {
+	if len(set.Guardians) == 0 {
+		return ErrNoGuardians
+	}
+	if set.Threshold <= 0 || set.Threshold > len(set.Guardians) {
+		return fmt.Errorf("threshold must be between 1 and %d", len(set.Guardians))
+	}
+	if set.Delay <= 0 {
+		set.Delay = DefaultRecoveryDelay
+	}
+
+	seen := make(map[string]bool)
+	for i, guardian := range set.Guardians {
+		pubKey, err := parseCompressedPubKey(guardian)
+		if err != nil {
+			return fmt.Errorf("invalid guardian %d: %w", i, err)
+		}
+		normalized := hex.EncodeToString(pubKey.SerializeCompressed())
+		if seen[normalized] {
+			return fmt.Errorf("duplicate guardian %s", normalized)
+		}
+		seen[normalized] = true
+		set.Guardians[i] = normalized
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests := make(map[string]*RecoveryRequest, len(m.requests))
+	for id, req := range m.requests {
+		if req.Status == RecoveryPending || req.Status == RecoveryApproved {
+			req = req.clone()
+			req.Status = RecoveryCancelled
+		}
+		requests[id] = req
+	}
+	return m.commit(&set, requests)
+}
+
+// Initiate opens a recovery request that moves the account to newPublicKey
+func (m *RecoveryManager) Initiate(account address.Address, newPublicKey string) (*RecoveryRequest, error) {
+	pubKey, err := parseCompressedPubKey(newPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid new public key: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.guardians == nil {
+		return nil, ErrNoGuardians
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+
+	req := &RecoveryRequest{
+		ID:           hex.EncodeToString(idBytes),
+		Account:      account.ZChain(),
+		NewPublicKey: hex.EncodeToString(pubKey.SerializeCompressed()),
+		Approvals:    make(map[string]string),
+		Status:       RecoveryPending,
+		InitiatedAt:  time.Now(),
+	}
+	if err := m.commit(m.guardians, m.withRequest(req)); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Approve records a guardian signature over the request digest. Once the threshold
+// is reached the request becomes executable after the configured delay.
+func (m *RecoveryManager) Approve(id string, signature string) (*RecoveryRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, ErrRecoveryNotFound
+	}
+	if req.Status != RecoveryPending && req.Status != RecoveryApproved {
+		return nil, ErrRecoveryNotActive
+	}
+
+	guardian, err := recoverSigner(req.Digest(), signature)
+	if err != nil {
+		return nil, err
+	}
+	if !m.isGuardian(guardian) {
+		return nil, ErrNotGuardian
+	}
+
+	req = req.clone()
+	req.Approvals[guardian] = signature
+	if req.Status == RecoveryPending && len(req.Approvals) >= m.guardians.Threshold {
+		req.Status = RecoveryApproved
+		req.ApprovedAt = time.Now()
+		req.ExecutableAt = req.ApprovedAt.Add(m.guardians.Delay)
+	}
+	if err := m.commit(m.guardians, m.withRequest(req)); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Cancel aborts a request; only the current owner may call it
+func (m *RecoveryManager) Cancel(id string) (*RecoveryRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, ErrRecoveryNotFound
+	}
+	if req.Status != RecoveryPending && req.Status != RecoveryApproved {
+		return nil, ErrRecoveryNotActive
+	}
+
+	req = req.clone()
+	req.Status = RecoveryCancelled
+	if err := m.commit(m.guardians, m.withRequest(req)); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Execute finalizes an approved request whose delay has elapsed. The caller must
+// prove possession of the new key, decrypted from the keystore.
+func (m *RecoveryManager) Execute(id string, privateKey *btcec.PrivateKey) (*RecoveryRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, ErrRecoveryNotFound
+	}
+	if req.Status != RecoveryApproved {
+		return nil, ErrRecoveryNotActive
+	}
+	if time.Now().Before(req.ExecutableAt) {
+		return nil, fmt.Errorf("%w: executable at %s", ErrRecoveryTimelock, req.ExecutableAt.Format(time.RFC3339))
+	}
+
+	expected, _ := hex.DecodeString(req.NewPublicKey)
+	if !bytes.Equal(privateKey.PubKey().SerializeCompressed(), expected) {
+		return nil, fmt.Errorf("private key does not match the recovery public key")
+	}
+
+	req = req.clone()
+	req.Status = RecoveryExecuted
+	if err := m.commit(m.guardians, m.withRequest(req)); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// State returns the guardian set and all requests
+func (m *RecoveryManager) State() (*GuardianSet, []*RecoveryRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests := make([]*RecoveryRequest, 0, len(m.requests))
+	for _, req := range m.requests {
+		requests = append(requests, req)
+	}
+	return m.guardians, requests
+}
+
+func (m *RecoveryManager) isGuardian(pubKey string) bool {
+	for _, guardian := range m.guardians.Guardians {
+		if guardian == pubKey {
+			return true
+		}
+	}
+	return false
+}
+
+// recoverSigner returns the compressed public key that produced a 65 byte recoverable signature
+func recoverSigner(digest []byte, signature string) (string, error) {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if len(sig) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes, got %d", len(sig))
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	return hex.EncodeToString(crypto.CompressPubkey(pubKey)), nil
+}
+
+func parseCompressedPubKey(s string) (*btcec.PublicKey, error) {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) != 33 {
+		return nil, fmt.Errorf("public key must be 33 bytes compressed")
+	}
+	return btcec.ParsePubKey(bz)
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getRecoveryState(w http.ResponseWriter, r *http.Request) {
+	guardians, requests := ws.recovery.State()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"guardians": guardians,
+		"requests":  requests,
+	})
+}
+
+// setGuardians replaces the guardian set; the owner authorizes it with the
+// keystore passphrase
+func (ws *WalletService) setGuardians(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Guardians    []string `json:"guardians"`
+		Threshold    int      `json:"threshold"`
+		DelaySeconds int64    `json:"delay_seconds"`
+		Passphrase   string   `json:"passphrase"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := ws.checkPassphrase(w, r, req.Passphrase); !ok {
+		return
+	}
+
+	set := GuardianSet{
+		Guardians: req.Guardians,
+		Threshold: req.Threshold,
+		Delay:     time.Duration(req.DelaySeconds) * time.Second,
+	}
+	if err := ws.recovery.SetGuardians(set); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws.getRecoveryState(w, r)
+}
+
+func (ws *WalletService) initiateRecovery(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		NewPublicKey string `json:"new_public_key"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recovery, err := ws.recovery.Initiate(ws.wallet.Account(), req.NewPublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"request": recovery,
+		"digest":  hex.EncodeToString(recovery.Digest()),
+	})
+}
+
+func (ws *WalletService) approveRecovery(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Signature string `json:"signature"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recovery, err := ws.recovery.Approve(mux.Vars(r)["id"], req.Signature)
+	if err != nil {
+		writeRecoveryError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recovery)
+}
+
+// cancelRecovery aborts a request; the owner authorizes it with the keystore
+// passphrase
+func (ws *WalletService) cancelRecovery(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := ws.checkPassphrase(w, r, req.Passphrase); !ok {
+		return
+	}
+
+	recovery, err := ws.recovery.Cancel(mux.Vars(r)["id"])
+	if err != nil {
+		writeRecoveryError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recovery)
+}
+
+// executeRecovery hands the account to the new key once the delay elapsed.
+// The key never crosses the API: it is restored into the local keystore with
+// z-wallet restore --force, and the request carries the passphrase it was
+// saved under.
+func (ws *WalletService) executeRecovery(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The recovered key belongs in the signer process, not the daemon
+	if ws.wallet.RemoteSigner() {
+		http.Error(w, wallet.ErrRemoteSigner.Error()+"; rekey the signer instead", http.StatusConflict)
+		return
+	}
+
+	privateKey, ok := ws.checkPassphrase(w, r, req.Passphrase)
+	if !ok {
+		return
+	}
+
+	recovery, err := ws.recovery.Execute(mux.Vars(r)["id"], privateKey)
+	if err != nil {
+		writeRecoveryError(w, err)
+		return
+	}
+
+	// The account keeps its address; only the controlling key changes, and
+	// the keystore is saved again under the account rather than the one
+	// restore derived from the new key
+	ws.wallet.Rekey(privateKey)
+	if err := ws.wallet.SaveKeystore(req.Passphrase); err != nil {
+		log.Printf("Failed to save the recovered keystore: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recovery)
+}
+
+func writeRecoveryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRecoveryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotGuardian):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrRecoveryNotActive), errors.Is(err, ErrRecoveryTimelock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,244 @@
+package remotesigner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"shared/address"
+)
+
+// renewBefore opens a new session this long before the current one expires
+const renewBefore = time.Minute
+
+// Client is a wallet.Signer backed by a signer process. Calls are serialized
+// so sequence numbers arrive in order.
+type Client struct {
+	url    string
+	secret []byte
+	http   *http.Client
+
+	mu        sync.Mutex
+	account   address.Address
+	publicKey *btcec.PublicKey
+	sessionID string
+	sealer    *sealer
+	seq       uint64
+	expires   time.Time
+}
+
+// Dial opens a session with the signer at url, e.g. http://signer:7777, and
+// learns the account it controls
+func Dial(url string, secret []byte, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	c := &Client{url: strings.TrimSuffix(url, "/"), secret: secret, http: httpClient}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.openSession(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Account returns the account the signer controls
+func (c *Client) Account() address.Address {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.account
+}
+
+// PublicKey returns the signer's key as of the latest session
+func (c *Client) PublicKey() *btcec.PublicKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.publicKey
+}
+
+// Sign asks the signer to sign a 32 byte hash
+func (c *Client) Sign(hash []byte) ([]byte, error) {
+	resp, err := c.call(request{Op: OpSign, Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// Derive asks the signer for sha256(prefix || key || suffix)
+func (c *Client) Derive(prefix, suffix []byte) ([32]byte, error) {
+	var secret [32]byte
+	resp, err := c.call(request{Op: OpDerive, Prefix: prefix, Suffix: suffix})
+	if err != nil {
+		return secret, err
+	}
+	if len(resp.Secret) != len(secret) {
+		return secret, fmt.Errorf("remote signer: derived %d bytes", len(resp.Secret))
+	}
+	copy(secret[:], resp.Secret)
+	return secret, nil
+}
+
+// openSession performs the handshake; callers hold mu
+func (c *Client) openSession() error {
+	clientNonce := make([]byte, 32)
+	if _, err := rand.Read(clientNonce); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	req := hello{
+		ClientNonce: hex.EncodeToString(clientNonce),
+		Time:        now,
+		Proof:       hex.EncodeToString(helloProof(c.secret, clientNonce, now)),
+	}
+
+	var resp welcome
+	if err := c.post(sessionPath, "", req, &resp); err != nil {
+		return err
+	}
+
+	serverNonce, err := hex.DecodeString(resp.ServerNonce)
+	if err != nil {
+		return fmt.Errorf("remote signer: invalid server nonce: %w", err)
+	}
+	key := sessionKey(c.secret, clientNonce, serverNonce)
+	proof, _ := hex.DecodeString(resp.Proof)
+	if !hmac.Equal(proof, welcomeProof(key, resp)) {
+		return fmt.Errorf("%w: signer failed to prove the shared secret", ErrUnauthorized)
+	}
+
+	accountBytes, err := hex.DecodeString(strings.TrimPrefix(resp.Account, "0x"))
+	if err != nil {
+		return fmt.Errorf("remote signer: invalid account: %w", err)
+	}
+	account, err := address.FromBytes(accountBytes)
+	if err != nil {
+		return fmt.Errorf("remote signer: invalid account: %w", err)
+	}
+	pubBytes, err := hex.DecodeString(resp.PublicKey)
+	if err != nil {
+		return fmt.Errorf("remote signer: invalid public key: %w", err)
+	}
+	publicKey, err := btcec.ParsePubKey(pubBytes)
+	if err != nil {
+		return fmt.Errorf("remote signer: invalid public key: %w", err)
+	}
+	if c.sessionID != "" && account != c.account {
+		return fmt.Errorf("remote signer: account changed from %s to %s", c.account.Hex(), account.Hex())
+	}
+
+	sealer, err := newSealer(key, resp.SessionID)
+	if err != nil {
+		return err
+	}
+
+	c.account = account
+	c.publicKey = publicKey
+	c.sessionID = resp.SessionID
+	c.sealer = sealer
+	c.seq = 0
+	c.expires = time.Unix(resp.Expires, 0)
+	return nil
+}
+
+// call sends one sealed request, renewing the session when it has expired
+func (c *Client) call(req request) (response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Until(c.expires) < renewBefore {
+		if err := c.openSession(); err != nil {
+			return response{}, err
+		}
+	}
+
+	resp, err := c.send(req)
+	if errors.Is(err, ErrSession) {
+		if err := c.openSession(); err != nil {
+			return response{}, err
+		}
+		resp, err = c.send(req)
+	}
+	if err != nil {
+		return response{}, err
+	}
+	if resp.Error != "" {
+		return response{}, fmt.Errorf("remote signer: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// send seals and posts req on the current session; callers hold mu
+func (c *Client) send(req request) (response, error) {
+	plaintext, err := json.Marshal(req)
+	if err != nil {
+		return response{}, err
+	}
+
+	c.seq++
+	seq := c.seq
+	var env envelope
+	if err := c.post(callPath, c.sessionID, envelope{Seq: seq, Box: c.sealer.seal(dirRequest, seq, plaintext)}, &env); err != nil {
+		return response{}, err
+	}
+	if env.Seq != seq {
+		return response{}, fmt.Errorf("%w: reply out of sequence", ErrUnauthorized)
+	}
+
+	opened, err := c.sealer.open(dirResponse, seq, env.Box)
+	if err != nil {
+		return response{}, fmt.Errorf("%w: reply failed authentication", ErrUnauthorized)
+	}
+	var resp response
+	if err := json.Unmarshal(opened, &resp); err != nil {
+		return response{}, err
+	}
+	return resp, nil
+}
+
+func (c *Client) post(path, sessionID string, body, out interface{}) error {
+	bz, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.url+path, bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		httpReq.Header.Set(SessionHeader, sessionID)
+	}
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote signer: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		text := strings.TrimSpace(string(msg))
+		switch {
+		case httpResp.StatusCode == http.StatusUnauthorized && text == ErrSession.Error():
+			return ErrSession
+		case httpResp.StatusCode == http.StatusUnauthorized:
+			return ErrUnauthorized
+		}
+		return fmt.Errorf("remote signer: %s: %s", httpResp.Status, text)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(out)
+}
@@ -0,0 +1,177 @@
+// Package remotesigner keeps the wallet key in a separate signer process. The
+// daemon holds no key material; it opens a session with the signer and sends
+// it the hashes to sign.
+//
+// Both sides share a secret. A session starts with a hello authenticated by
+// that secret, and both sides derive a session key from it and their nonces.
+// The signer proves it knows the secret too. Every call after that is sealed
+// with AES-GCM under the session key, with a sequence number that must
+// increase, so calls cannot be read, altered or replayed on the wire.
+// Sessions expire, and the client opens a new one when they do.
+package remotesigner
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	sessionPath = "/v1/session"
+	callPath    = "/v1/call"
+
+	// SessionHeader names the session a call belongs to
+	SessionHeader = "Z-Signer-Session"
+
+	// helloWindow bounds the clock skew accepted in a hello
+	helloWindow = time.Minute
+
+	// MinSecretLength is the shortest shared secret accepted, in bytes
+	MinSecretLength = 32
+)
+
+// Directions, the first byte of a GCM nonce, so a request can never be
+// replayed as a response
+const (
+	dirRequest  byte = 1
+	dirResponse byte = 2
+)
+
+// Call operations
+const (
+	OpSign   = "sign"
+	OpDerive = "derive"
+)
+
+var (
+	ErrUnauthorized = errors.New("remote signer: unauthorized")
+	ErrSession      = errors.New("remote signer: session expired")
+)
+
+// hello opens a session. Proof is HMAC(secret, client nonce || time).
+type hello struct {
+	ClientNonce string `json:"client_nonce"`
+	Time        int64  `json:"time"` // Unix seconds
+	Proof       string `json:"proof"`
+}
+
+// welcome accepts a session. Proof is HMAC(session key, id || account ||
+// public key), binding the key the signer reports to the shared secret.
+type welcome struct {
+	SessionID   string `json:"session_id"`
+	ServerNonce string `json:"server_nonce"`
+	Account     string `json:"account"`    // Hex
+	PublicKey   string `json:"public_key"` // Compressed, hex
+	Expires     int64  `json:"expires"`    // Unix seconds
+	Proof       string `json:"proof"`
+}
+
+// envelope carries a sealed request or response
+type envelope struct {
+	Seq uint64 `json:"seq"`
+	Box []byte `json:"box"`
+}
+
+// request is the plaintext of a call
+type request struct {
+	Op     string `json:"op"`
+	Hash   []byte `json:"hash,omitempty"`
+	Prefix []byte `json:"prefix,omitempty"`
+	Suffix []byte `json:"suffix,omitempty"`
+}
+
+// response is the plaintext of a reply
+type response struct {
+	Signature []byte `json:"signature,omitempty"`
+	Secret    []byte `json:"secret,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func mac(key []byte, parts ...[]byte) []byte {
+	m := hmac.New(sha256.New, key)
+	for _, part := range parts {
+		m.Write(part)
+	}
+	return m.Sum(nil)
+}
+
+func helloProof(secret, clientNonce []byte, t int64) []byte {
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(t))
+	return mac(secret, []byte("zcore-signer/hello"), clientNonce, ts[:])
+}
+
+func sessionKey(secret, clientNonce, serverNonce []byte) []byte {
+	return mac(secret, []byte("zcore-signer/session"), clientNonce, serverNonce)
+}
+
+func welcomeProof(key []byte, w welcome) []byte {
+	return mac(key, []byte("zcore-signer/welcome"), []byte(w.SessionID), []byte(w.Account), []byte(w.PublicKey))
+}
+
+// sealer encrypts the calls of one session
+type sealer struct {
+	aead cipher.AEAD
+	id   []byte
+}
+
+func newSealer(key []byte, id string) (*sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead, id: []byte(id)}, nil
+}
+
+func (s *sealer) nonce(dir byte, seq uint64) []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	nonce[0] = dir
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+func (s *sealer) seal(dir byte, seq uint64, plaintext []byte) []byte {
+	return s.aead.Seal(nil, s.nonce(dir, seq), plaintext, s.id)
+}
+
+func (s *sealer) open(dir byte, seq uint64, box []byte) ([]byte, error) {
+	return s.aead.Open(nil, s.nonce(dir, seq), box, s.id)
+}
+
+// LoadSecret reads the shared secret, hex encoded, from WALLET_SIGNER_SECRET
+// or the file named by WALLET_SIGNER_SECRET_FILE
+func LoadSecret() ([]byte, error) {
+	encoded := os.Getenv("WALLET_SIGNER_SECRET")
+	if path := os.Getenv("WALLET_SIGNER_SECRET_FILE"); path != "" {
+		bz, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(bz)
+	}
+	return ParseSecret(encoded)
+}
+
+// ParseSecret decodes a hex encoded shared secret
+func ParseSecret(encoded string) ([]byte, error) {
+	secret, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer secret: %w", err)
+	}
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("invalid signer secret: need at least %d bytes, got %d", MinSecretLength, len(secret))
+	}
+	return secret, nil
+}
@@ -0,0 +1,211 @@
+package remotesigner
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"shared/address"
+	"z-core-wallet/wallet"
+)
+
+// DefaultSessionTTL is how long a session stays usable
+const DefaultSessionTTL = time.Hour
+
+// maxSessions bounds the sessions a signer keeps open
+const maxSessions = 64
+
+type session struct {
+	sealer  *sealer
+	lastSeq uint64
+	expires time.Time
+}
+
+// Server is the signer process. It serves sessions to daemons holding the
+// shared secret and signs on their behalf.
+type Server struct {
+	account address.Address
+	signer  wallet.Signer
+	secret  []byte
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewServer creates a signer for the account controlled by signer
+func NewServer(account address.Address, signer wallet.Signer, secret []byte, ttl time.Duration) *Server {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Server{
+		account:  account,
+		signer:   signer,
+		secret:   secret,
+		ttl:      ttl,
+		sessions: make(map[string]*session),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Path {
+	case sessionPath:
+		s.openSession(w, r)
+	case callPath:
+		s.call(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) openSession(w http.ResponseWriter, r *http.Request) {
+	var req hello
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientNonce, err := hex.DecodeString(req.ClientNonce)
+	if err != nil || len(clientNonce) != 32 {
+		http.Error(w, "invalid client nonce", http.StatusBadRequest)
+		return
+	}
+	skew := time.Since(time.Unix(req.Time, 0))
+	proof, _ := hex.DecodeString(req.Proof)
+	if skew > helloWindow || skew < -helloWindow || !hmac.Equal(proof, helloProof(s.secret, clientNonce, req.Time)) {
+		log.Printf("Remote signer: rejected session from %s", r.RemoteAddr)
+		http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	serverNonce := make([]byte, 32)
+	id := make([]byte, 16)
+	if _, err := rand.Read(serverNonce); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := rand.Read(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	key := sessionKey(s.secret, clientNonce, serverNonce)
+	resp := welcome{
+		SessionID:   hex.EncodeToString(id),
+		ServerNonce: hex.EncodeToString(serverNonce),
+		Account:     s.account.Hex(),
+		PublicKey:   hex.EncodeToString(s.signer.PublicKey().SerializeCompressed()),
+		Expires:     time.Now().Add(s.ttl).Unix(),
+	}
+	resp.Proof = hex.EncodeToString(welcomeProof(key, resp))
+
+	sealer, err := newSealer(key, resp.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.prune()
+	if len(s.sessions) >= maxSessions {
+		s.mu.Unlock()
+		http.Error(w, "too many sessions", http.StatusServiceUnavailable)
+		return
+	}
+	s.sessions[resp.SessionID] = &session{sealer: sealer, expires: time.Unix(resp.Expires, 0)}
+	s.mu.Unlock()
+
+	log.Printf("Remote signer: session %s opened from %s", resp.SessionID[:8], r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// prune drops expired sessions; callers hold mu
+func (s *Server) prune() {
+	now := time.Now()
+	for id, sess := range s.sessions {
+		if now.After(sess.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func (s *Server) call(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(SessionHeader)
+
+	var env envelope
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 65536)).Decode(&env); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	if !ok || time.Now().After(sess.expires) {
+		delete(s.sessions, id)
+		s.mu.Unlock()
+		http.Error(w, ErrSession.Error(), http.StatusUnauthorized)
+		return
+	}
+	plaintext, err := sess.sealer.open(dirRequest, env.Seq, env.Box)
+	if err != nil || env.Seq <= sess.lastSeq {
+		s.mu.Unlock()
+		log.Printf("Remote signer: rejected call on session %.8s", id)
+		http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+	sess.lastSeq = env.Seq
+	s.mu.Unlock()
+
+	var req request
+	var resp response
+	if err := json.Unmarshal(plaintext, &req); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp = s.handle(req)
+	}
+
+	bz, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(envelope{Seq: env.Seq, Box: sess.sealer.seal(dirResponse, env.Seq, bz)})
+}
+
+func (s *Server) handle(req request) response {
+	switch req.Op {
+	case OpSign:
+		if len(req.Hash) != 32 {
+			return response{Error: "hash must be 32 bytes"}
+		}
+		signature, err := s.signer.Sign(req.Hash)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		log.Printf("Remote signer: signed %x", req.Hash)
+		return response{Signature: signature}
+	case OpDerive:
+		secret, err := s.signer.Derive(req.Prefix, req.Suffix)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Secret: secret[:]}
+	default:
+		return response{Error: "unknown operation " + req.Op}
+	}
+}
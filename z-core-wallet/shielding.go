@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"shared/address"
+	"z-core-wallet/wallet"
+)
+
+// Shield moves transparent Z into a shielded note and notifies subscribers
+func (ws *WalletService) Shield(amount, fee int64, memo string) (*wallet.ShieldOperation, error) {
+	op, err := ws.wallet.Shield(amount, fee, memo)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.publish("shield", op.Transaction)
+	return op, nil
+}
+
+// Unshield releases shielded Z to a transparent address and notifies subscribers
+func (ws *WalletService) Unshield(amount, fee int64, recipient, memo string) (*wallet.ShieldOperation, error) {
+	op, err := ws.wallet.Unshield(amount, fee, recipient, memo)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.publish("unshield", op.Transaction)
+	return op, nil
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getCoins(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"utxos":  ws.wallet.UTXOs(),
+		"notes":  ws.wallet.Notes(),
+		"frozen": ws.wallet.Frozen(),
+	})
+}
+
+func (ws *WalletService) shieldFunds(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount string `json:"amount"`
+		Fee    string `json:"fee"`
+		Memo   string `json:"memo"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	amount, fee, err := parseShieldAmounts(req.Amount, req.Fee)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if ws.wallet.Locked() {
+		http.Error(w, wallet.ErrLocked.Error(), http.StatusLocked)
+		return
+	}
+
+	op, err := ws.Shield(amount, fee, req.Memo)
+	if err != nil {
+		writeShieldError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(op)
+}
+
+func (ws *WalletService) unshieldFunds(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount    string `json:"amount"`
+		Fee       string `json:"fee"`
+		Recipient string `json:"recipient"`
+		Memo      string `json:"memo"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	amount, fee, err := parseShieldAmounts(req.Amount, req.Fee)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Recipient != "" {
+		if _, _, _, err := address.Parse(req.Recipient); err != nil {
+			http.Error(w, "Invalid recipient: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if ws.wallet.Locked() {
+		http.Error(w, wallet.ErrLocked.Error(), http.StatusLocked)
+		return
+	}
+
+	// Paying someone else from the shielded pool is a spend like any other
+	if req.Recipient != "" {
+		if held := ws.holdIfViolates(req.Recipient, amount, "Z", req.Memo, false); held != nil {
+			writeHeld(w, held)
+			return
+		}
+	}
+
+	op, err := ws.Unshield(amount, fee, req.Recipient, req.Memo)
+	if err != nil {
+		writeShieldError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(op)
+}
+
+// parseShieldAmounts parses the amount and the optional fee of a shield request
+func parseShieldAmounts(amountStr, feeStr string) (int64, int64, error) {
+	amount, err := strconv.ParseInt(amountStr, 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, 0, errors.New("Invalid amount")
+	}
+
+	fee := wallet.DefaultShieldingFee
+	if feeStr != "" {
+		fee, err = strconv.ParseInt(feeStr, 10, 64)
+		if err != nil || fee < 0 {
+			return 0, 0, errors.New("Invalid fee")
+		}
+	}
+
+	return amount, fee, nil
+}
+
+func writeShieldError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, wallet.ErrInsufficientTransparent), errors.Is(err, wallet.ErrInsufficientShielded):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"z-core-wallet/remotesigner"
+	"z-core-wallet/wallet"
+)
+
+// remoteSignerOptions connects to the signer process named by
+// WALLET_REMOTE_SIGNER. The daemon then holds no key and has no keystore;
+// the wallet is locked only while the signer is unreachable.
+func remoteSignerOptions(opts *wallet.Options) (bool, error) {
+	url := os.Getenv("WALLET_REMOTE_SIGNER")
+	if url == "" {
+		return false, nil
+	}
+
+	secret, err := remotesigner.LoadSecret()
+	if err != nil {
+		return false, err
+	}
+	client, err := remotesigner.Dial(url, secret, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach remote signer %s: %w", url, err)
+	}
+
+	log.Printf("Signing remotely with %s for %s", url, client.Account().ZBase58())
+	opts.Signer = client
+	opts.Account = client.Account()
+	opts.Keystore = nil
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"z-core-wallet/wallet"
+)
+
+// CreateStakingNode builds a staking node registration and notifies subscribers
+func (ws *WalletService) CreateStakingNode(req wallet.StakingNodeRequest) (*wallet.StakingOperation, error) {
+	op, err := ws.wallet.CreateStakingNode(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.publish("staking_node", op)
+	return op, nil
+}
+
+// HTTP Handlers
+
+// getStaking describes what running a staking node takes and pays. WATT
+// rewards are sent to the supported chains every block rather than accrued
+// on nuChain, so there is no pending balance to query or withdraw.
+func (ws *WalletService) getStaking(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"operator":    ws.wallet.Account().NuChain(),
+		"stake":       wallet.StakingNodeStake,
+		"stake_denom": wallet.DenomNU,
+		// Paid on each supported chain
+		"watt_per_block": strconv.FormatInt(wallet.StakingWattPerBlock, 10),
+	})
+}
+
+func (ws *WalletService) createStakingNode(w http.ResponseWriter, r *http.Request) {
+	var req wallet.StakingNodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	op, err := ws.CreateStakingNode(req)
+	switch {
+	case errors.Is(err, wallet.ErrInvalidStakingNode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, wallet.ErrLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(op)
+}
@@ -0,0 +1,420 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Top level buckets. Per-account records live in a nested bucket named after
+// the account inside each of them.
+var (
+	bucketAccounts = []byte("accounts")
+	bucketUTXOs    = []byte("utxos")
+	bucketNotes    = []byte("notes")
+	bucketTxs      = []byte("txs")      // Sequence -> transaction, in insertion order
+	bucketTxIndex  = []byte("tx_index") // Hash -> sequence
+	bucketContacts = []byte("contacts")
+	bucketLabels   = []byte("labels") // kind/ref -> label
+	bucketIdem     = []byte("idempotency")
+	bucketPolicy   = []byte("policy")   // Account -> policy state
+	bucketRecovery = []byte("recovery") // Account -> recovery state
+)
+
+// BoltStore keeps wallet state in an embedded BoltDB file
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates the database file at path. The file is locked
+// while open, so a second wallet daemon on the same file fails fast.
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wallet database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketAccounts, bucketUTXOs, bucketNotes, bucketTxs, bucketTxIndex, bucketContacts, bucketLabels, bucketIdem, bucketPolicy, bucketRecovery} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// accountBucket returns the account's bucket inside a top level bucket,
+// creating it when writable
+func accountBucket(tx *bolt.Tx, top []byte, account string) (*bolt.Bucket, error) {
+	parent := tx.Bucket(top)
+	if !tx.Writable() {
+		return parent.Bucket([]byte(account)), nil
+	}
+	return parent.CreateBucketIfNotExists([]byte(account))
+}
+
+func (s *BoltStore) SaveAccount(account Account) error {
+	bz, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketAccounts).Put([]byte(account.Address), bz)
+	})
+}
+
+func (s *BoltStore) GetAccount(address string) (Account, error) {
+	var account Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bz := tx.Bucket(bucketAccounts).Get([]byte(address))
+		if bz == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(bz, &account)
+	})
+	return account, err
+}
+
+func (s *BoltStore) Accounts() ([]Account, error) {
+	var accounts []Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketAccounts).ForEach(func(_, v []byte) error {
+			var account Account
+			if err := json.Unmarshal(v, &account); err != nil {
+				return err
+			}
+			accounts = append(accounts, account)
+			return nil
+		})
+	})
+	return accounts, err
+}
+
+// replaceAll empties the account's bucket and writes records under their keys
+func (s *BoltStore) replaceAll(top []byte, account string, keys [][]byte, records []interface{}) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		parent := tx.Bucket(top)
+		if parent.Bucket([]byte(account)) != nil {
+			if err := parent.DeleteBucket([]byte(account)); err != nil {
+				return err
+			}
+		}
+		b, err := parent.CreateBucket([]byte(account))
+		if err != nil {
+			return err
+		}
+		for i, record := range records {
+			bz, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(keys[i], bz); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// loadAll decodes every record of the account's bucket in key order
+func (s *BoltStore) loadAll(top []byte, account string, decode func(v []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b, _ := accountBucket(tx, top, account)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			return decode(v)
+		})
+	})
+}
+
+func (s *BoltStore) ReplaceUTXOs(account string, utxos []UTXO) error {
+	keys := make([][]byte, len(utxos))
+	records := make([]interface{}, len(utxos))
+	for i, utxo := range utxos {
+		keys[i] = []byte(fmt.Sprintf("%s:%010d", utxo.TxHash, utxo.OutputIndex))
+		records[i] = utxo
+	}
+	return s.replaceAll(bucketUTXOs, account, keys, records)
+}
+
+func (s *BoltStore) UTXOs(account string) ([]UTXO, error) {
+	var utxos []UTXO
+	err := s.loadAll(bucketUTXOs, account, func(v []byte) error {
+		var utxo UTXO
+		if err := json.Unmarshal(v, &utxo); err != nil {
+			return err
+		}
+		utxos = append(utxos, utxo)
+		return nil
+	})
+	return utxos, err
+}
+
+func (s *BoltStore) ReplaceNotes(account string, notes []Note) error {
+	keys := make([][]byte, len(notes))
+	records := make([]interface{}, len(notes))
+	for i, note := range notes {
+		keys[i] = []byte(note.Nullifier)
+		records[i] = note
+	}
+	return s.replaceAll(bucketNotes, account, keys, records)
+}
+
+func (s *BoltStore) Notes(account string) ([]Note, error) {
+	var notes []Note
+	err := s.loadAll(bucketNotes, account, func(v []byte) error {
+		var note Note
+		if err := json.Unmarshal(v, &note); err != nil {
+			return err
+		}
+		notes = append(notes, note)
+		return nil
+	})
+	return notes, err
+}
+
+func (s *BoltStore) SaveTransaction(account string, record Transaction) error {
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		txs, err := accountBucket(tx, bucketTxs, account)
+		if err != nil {
+			return err
+		}
+		index, err := accountBucket(tx, bucketTxIndex, account)
+		if err != nil {
+			return err
+		}
+
+		// Updates keep the original position in the history
+		key := index.Get([]byte(record.Hash))
+		if key == nil {
+			seq, err := txs.NextSequence()
+			if err != nil {
+				return err
+			}
+			key = make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			if err := index.Put([]byte(record.Hash), key); err != nil {
+				return err
+			}
+		}
+		return txs.Put(key, bz)
+	})
+}
+
+func (s *BoltStore) Transactions(account string) ([]Transaction, error) {
+	var txs []Transaction
+	err := s.loadAll(bucketTxs, account, func(v []byte) error {
+		var record Transaction
+		if err := json.Unmarshal(v, &record); err != nil {
+			return err
+		}
+		txs = append(txs, record)
+		return nil
+	})
+	return txs, err
+}
+
+func (s *BoltStore) SaveContact(account string, contact Contact) error {
+	bz, err := json.Marshal(contact)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := accountBucket(tx, bucketContacts, account)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(contact.Name), bz)
+	})
+}
+
+func (s *BoltStore) DeleteContact(account string, name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := accountBucket(tx, bucketContacts, account)
+		if err != nil {
+			return err
+		}
+		if b.Get([]byte(name)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(name))
+	})
+}
+
+func (s *BoltStore) Contacts(account string) ([]Contact, error) {
+	var contacts []Contact
+	err := s.loadAll(bucketContacts, account, func(v []byte) error {
+		var contact Contact
+		if err := json.Unmarshal(v, &contact); err != nil {
+			return err
+		}
+		contacts = append(contacts, contact)
+		return nil
+	})
+	return contacts, err
+}
+
+func (s *BoltStore) SaveLabel(account string, label Label) error {
+	bz, err := json.Marshal(label)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := accountBucket(tx, bucketLabels, account)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(labelKey(label.Kind, label.Ref)), bz)
+	})
+}
+
+func (s *BoltStore) DeleteLabel(account string, kind, ref string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := accountBucket(tx, bucketLabels, account)
+		if err != nil {
+			return err
+		}
+		key := []byte(labelKey(kind, ref))
+		if b.Get(key) == nil {
+			return ErrNotFound
+		}
+		return b.Delete(key)
+	})
+}
+
+func (s *BoltStore) Labels(account string) ([]Label, error) {
+	var labels []Label
+	err := s.loadAll(bucketLabels, account, func(v []byte) error {
+		var label Label
+		if err := json.Unmarshal(v, &label); err != nil {
+			return err
+		}
+		labels = append(labels, label)
+		return nil
+	})
+	return labels, err
+}
+
+func (s *BoltStore) SaveIdempotentResponse(account string, resp IdempotentResponse) error {
+	bz, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := accountBucket(tx, bucketIdem, account)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(resp.Key), bz)
+	})
+}
+
+func (s *BoltStore) IdempotentResponse(account string, key string) (IdempotentResponse, error) {
+	var resp IdempotentResponse
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b, _ := accountBucket(tx, bucketIdem, account)
+		if b == nil {
+			return ErrNotFound
+		}
+		bz := b.Get([]byte(key))
+		if bz == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(bz, &resp)
+	})
+	return resp, err
+}
+
+func (s *BoltStore) PruneIdempotentResponses(account string, before time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := accountBucket(tx, bucketIdem, account)
+		if err != nil {
+			return err
+		}
+		var expired [][]byte
+		err = b.ForEach(func(k, v []byte) error {
+			var resp IdempotentResponse
+			if err := json.Unmarshal(v, &resp); err != nil {
+				return err
+			}
+			if resp.CreatedAt.Before(before) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) SavePolicyState(account string, state PolicyState) error {
+	bz, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPolicy).Put([]byte(account), bz)
+	})
+}
+
+func (s *BoltStore) PolicyState(account string) (PolicyState, error) {
+	var state PolicyState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bz := tx.Bucket(bucketPolicy).Get([]byte(account))
+		if bz == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(bz, &state)
+	})
+	return state, err
+}
+
+func (s *BoltStore) SaveRecoveryState(account string, state RecoveryState) error {
+	bz, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRecovery).Put([]byte(account), bz)
+	})
+}
+
+func (s *BoltStore) RecoveryState(account string) (RecoveryState, error) {
+	var state RecoveryState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bz := tx.Bucket(bucketRecovery).Get([]byte(account))
+		if bz == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(bz, &state)
+	})
+	return state, err
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
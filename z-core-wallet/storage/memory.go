@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps wallet state in process memory, as the wallet did before
+// it had a database. Useful for development and throwaway wallets.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	accounts map[string]Account
+	utxos    map[string][]UTXO
+	notes    map[string][]Note
+	txs      map[string][]Transaction
+	contacts map[string]map[string]Contact
+	labels   map[string]map[string]Label
+	idem     map[string]map[string]IdempotentResponse
+	policies map[string]PolicyState
+	recovery map[string]RecoveryState
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		accounts: make(map[string]Account),
+		utxos:    make(map[string][]UTXO),
+		notes:    make(map[string][]Note),
+		txs:      make(map[string][]Transaction),
+		contacts: make(map[string]map[string]Contact),
+		labels:   make(map[string]map[string]Label),
+		idem:     make(map[string]map[string]IdempotentResponse),
+		policies: make(map[string]PolicyState),
+		recovery: make(map[string]RecoveryState),
+	}
+}
+
+func (s *MemoryStore) SaveAccount(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[account.Address] = account
+	return nil
+}
+
+func (s *MemoryStore) GetAccount(address string) (Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	account, ok := s.accounts[address]
+	if !ok {
+		return Account{}, ErrNotFound
+	}
+	return account, nil
+}
+
+func (s *MemoryStore) Accounts() ([]Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	accounts := make([]Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Address < accounts[j].Address })
+	return accounts, nil
+}
+
+func (s *MemoryStore) ReplaceUTXOs(account string, utxos []UTXO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.utxos[account] = append([]UTXO(nil), utxos...)
+	return nil
+}
+
+func (s *MemoryStore) UTXOs(account string) ([]UTXO, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]UTXO(nil), s.utxos[account]...), nil
+}
+
+func (s *MemoryStore) ReplaceNotes(account string, notes []Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notes[account] = append([]Note(nil), notes...)
+	return nil
+}
+
+func (s *MemoryStore) Notes(account string) ([]Note, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Note(nil), s.notes[account]...), nil
+}
+
+func (s *MemoryStore) SaveTransaction(account string, tx Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.txs[account] {
+		if existing.Hash == tx.Hash {
+			s.txs[account][i] = tx
+			return nil
+		}
+	}
+	s.txs[account] = append(s.txs[account], tx)
+	return nil
+}
+
+func (s *MemoryStore) Transactions(account string) ([]Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Transaction(nil), s.txs[account]...), nil
+}
+
+func (s *MemoryStore) SaveContact(account string, contact Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.contacts[account] == nil {
+		s.contacts[account] = make(map[string]Contact)
+	}
+	s.contacts[account][contact.Name] = contact
+	return nil
+}
+
+func (s *MemoryStore) DeleteContact(account string, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.contacts[account][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.contacts[account], name)
+	return nil
+}
+
+func (s *MemoryStore) Contacts(account string) ([]Contact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	contacts := make([]Contact, 0, len(s.contacts[account]))
+	for _, contact := range s.contacts[account] {
+		contacts = append(contacts, contact)
+	}
+	sort.Slice(contacts, func(i, j int) bool { return contacts[i].Name < contacts[j].Name })
+	return contacts, nil
+}
+
+func (s *MemoryStore) SaveLabel(account string, label Label) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.labels[account] == nil {
+		s.labels[account] = make(map[string]Label)
+	}
+	s.labels[account][labelKey(label.Kind, label.Ref)] = label
+	return nil
+}
+
+func (s *MemoryStore) DeleteLabel(account string, kind, ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := labelKey(kind, ref)
+	if _, ok := s.labels[account][key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.labels[account], key)
+	return nil
+}
+
+func (s *MemoryStore) Labels(account string) ([]Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	labels := make([]Label, 0, len(s.labels[account]))
+	for _, label := range s.labels[account] {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labelKey(labels[i].Kind, labels[i].Ref) < labelKey(labels[j].Kind, labels[j].Ref)
+	})
+	return labels, nil
+}
+
+func (s *MemoryStore) SaveIdempotentResponse(account string, resp IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idem[account] == nil {
+		s.idem[account] = make(map[string]IdempotentResponse)
+	}
+	resp.Body = append([]byte(nil), resp.Body...)
+	s.idem[account][resp.Key] = resp
+	return nil
+}
+
+func (s *MemoryStore) IdempotentResponse(account string, key string) (IdempotentResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resp, ok := s.idem[account][key]
+	if !ok {
+		return IdempotentResponse{}, ErrNotFound
+	}
+	resp.Body = append([]byte(nil), resp.Body...)
+	return resp, nil
+}
+
+func (s *MemoryStore) PruneIdempotentResponses(account string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, resp := range s.idem[account] {
+		if resp.CreatedAt.Before(before) {
+			delete(s.idem[account], key)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) SavePolicyState(account string, state PolicyState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies[account] = state
+	return nil
+}
+
+func (s *MemoryStore) PolicyState(account string) (PolicyState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.policies[account]
+	if !ok {
+		return PolicyState{}, ErrNotFound
+	}
+	return state, nil
+}
+
+func (s *MemoryStore) SaveRecoveryState(account string, state RecoveryState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recovery[account] = state
+	return nil
+}
+
+func (s *MemoryStore) RecoveryState(account string) (RecoveryState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.recovery[account]
+	if !ok {
+		return RecoveryState{}, ErrNotFound
+	}
+	return state, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
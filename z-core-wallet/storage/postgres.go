@@ -0,0 +1,412 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresSchema is applied on open; every statement is idempotent
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS wallet_accounts (
+	address    TEXT PRIMARY KEY,
+	z_address  TEXT NOT NULL,
+	public_key TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS wallet_utxos (
+	account      TEXT NOT NULL,
+	tx_hash      TEXT NOT NULL,
+	output_index BIGINT NOT NULL,
+	amount       BIGINT NOT NULL,
+	pending      BOOLEAN NOT NULL,
+	frozen       BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (account, tx_hash, output_index)
+);
+ALTER TABLE wallet_utxos ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS wallet_notes (
+	account    TEXT NOT NULL,
+	nullifier  TEXT NOT NULL,
+	commitment TEXT NOT NULL,
+	amount     BIGINT NOT NULL,
+	pending    BOOLEAN NOT NULL,
+	spent      BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (account, nullifier)
+);
+ALTER TABLE wallet_notes ADD COLUMN IF NOT EXISTS spent BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+	seq       BIGSERIAL,
+	account   TEXT NOT NULL,
+	hash      TEXT NOT NULL,
+	sender    TEXT NOT NULL,
+	recipient TEXT NOT NULL,
+	amount    BIGINT NOT NULL,
+	token     TEXT NOT NULL,
+	timestamp TIMESTAMPTZ NOT NULL,
+	status    TEXT NOT NULL,
+	memo      TEXT NOT NULL,
+	private   BOOLEAN NOT NULL,
+	PRIMARY KEY (account, hash)
+);
+CREATE TABLE IF NOT EXISTS wallet_contacts (
+	account TEXT NOT NULL,
+	name    TEXT NOT NULL,
+	address TEXT NOT NULL,
+	note    TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (account, name)
+);
+CREATE TABLE IF NOT EXISTS wallet_labels (
+	account TEXT NOT NULL,
+	kind    TEXT NOT NULL,
+	ref     TEXT NOT NULL,
+	label   TEXT NOT NULL,
+	PRIMARY KEY (account, kind, ref)
+);
+CREATE TABLE IF NOT EXISTS wallet_idempotency (
+	account     TEXT NOT NULL,
+	key         TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	status      INTEGER NOT NULL,
+	body        BYTEA NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (account, key)
+);
+CREATE TABLE IF NOT EXISTS wallet_policy (
+	account    TEXT PRIMARY KEY,
+	policy     BYTEA NOT NULL,
+	token_hash BYTEA,
+	held       BYTEA NOT NULL
+);
+CREATE TABLE IF NOT EXISTS wallet_recovery (
+	account   TEXT PRIMARY KEY,
+	guardians BYTEA NOT NULL,
+	requests  BYTEA NOT NULL
+);`
+
+// PostgresStore keeps wallet state in PostgreSQL, for custodial deployments
+// that already run a database and want it backed up with the rest
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// OpenPostgres connects to dsn and creates the wallet tables if needed
+func OpenPostgres(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to wallet database: %w", err)
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create wallet tables: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) SaveAccount(account Account) error {
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_accounts (address, z_address, public_key, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (address) DO UPDATE SET z_address = $2, public_key = $3`,
+		account.Address, account.ZAddress, account.PublicKey, account.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetAccount(address string) (Account, error) {
+	var account Account
+	err := s.db.QueryRow(`
+		SELECT address, z_address, public_key, created_at FROM wallet_accounts WHERE address = $1`,
+		address).Scan(&account.Address, &account.ZAddress, &account.PublicKey, &account.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{}, ErrNotFound
+	}
+	return account, err
+}
+
+func (s *PostgresStore) Accounts() ([]Account, error) {
+	rows, err := s.db.Query(`SELECT address, z_address, public_key, created_at FROM wallet_accounts ORDER BY address`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var account Account
+		if err := rows.Scan(&account.Address, &account.ZAddress, &account.PublicKey, &account.CreatedAt); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+// inTx runs fn in a transaction, rolling back on error
+func (s *PostgresStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) ReplaceUTXOs(account string, utxos []UTXO) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM wallet_utxos WHERE account = $1`, account); err != nil {
+			return err
+		}
+		for _, utxo := range utxos {
+			if _, err := tx.Exec(`
+				INSERT INTO wallet_utxos (account, tx_hash, output_index, amount, pending, frozen)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				account, utxo.TxHash, utxo.OutputIndex, utxo.Amount, utxo.Pending, utxo.Frozen); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStore) UTXOs(account string) ([]UTXO, error) {
+	rows, err := s.db.Query(`
+		SELECT tx_hash, output_index, amount, pending, frozen FROM wallet_utxos
+		WHERE account = $1 ORDER BY tx_hash, output_index`, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var utxos []UTXO
+	for rows.Next() {
+		var utxo UTXO
+		if err := rows.Scan(&utxo.TxHash, &utxo.OutputIndex, &utxo.Amount, &utxo.Pending, &utxo.Frozen); err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, rows.Err()
+}
+
+func (s *PostgresStore) ReplaceNotes(account string, notes []Note) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM wallet_notes WHERE account = $1`, account); err != nil {
+			return err
+		}
+		for _, note := range notes {
+			if _, err := tx.Exec(`
+				INSERT INTO wallet_notes (account, nullifier, commitment, amount, pending, spent)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				account, note.Nullifier, note.Commitment, note.Amount, note.Pending, note.Spent); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStore) Notes(account string) ([]Note, error) {
+	rows, err := s.db.Query(`
+		SELECT commitment, nullifier, amount, pending, spent FROM wallet_notes
+		WHERE account = $1 ORDER BY nullifier`, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var note Note
+		if err := rows.Scan(&note.Commitment, &note.Nullifier, &note.Amount, &note.Pending, &note.Spent); err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+func (s *PostgresStore) SaveTransaction(account string, tx Transaction) error {
+	// Updates keep seq, and with it the position in the history
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_transactions (account, hash, sender, recipient, amount, token, timestamp, status, memo, private)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (account, hash) DO UPDATE SET
+			sender = $3, recipient = $4, amount = $5, token = $6, timestamp = $7, status = $8, memo = $9, private = $10`,
+		account, tx.Hash, tx.From, tx.To, tx.Amount, tx.Token, tx.Timestamp, tx.Status, tx.Memo, tx.Private)
+	return err
+}
+
+func (s *PostgresStore) Transactions(account string) ([]Transaction, error) {
+	rows, err := s.db.Query(`
+		SELECT hash, sender, recipient, amount, token, timestamp, status, memo, private
+		FROM wallet_transactions WHERE account = $1 ORDER BY seq`, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []Transaction
+	for rows.Next() {
+		var tx Transaction
+		if err := rows.Scan(&tx.Hash, &tx.From, &tx.To, &tx.Amount, &tx.Token, &tx.Timestamp, &tx.Status, &tx.Memo, &tx.Private); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, rows.Err()
+}
+
+func (s *PostgresStore) SaveContact(account string, contact Contact) error {
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_contacts (account, name, address, note) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account, name) DO UPDATE SET address = $3, note = $4`,
+		account, contact.Name, contact.Address, contact.Note)
+	return err
+}
+
+func (s *PostgresStore) DeleteContact(account string, name string) error {
+	res, err := s.db.Exec(`DELETE FROM wallet_contacts WHERE account = $1 AND name = $2`, account, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) Contacts(account string) ([]Contact, error) {
+	rows, err := s.db.Query(`
+		SELECT name, address, note FROM wallet_contacts WHERE account = $1 ORDER BY name`, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []Contact
+	for rows.Next() {
+		var contact Contact
+		if err := rows.Scan(&contact.Name, &contact.Address, &contact.Note); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, rows.Err()
+}
+
+func (s *PostgresStore) SaveLabel(account string, label Label) error {
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_labels (account, kind, ref, label) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account, kind, ref) DO UPDATE SET label = $4`,
+		account, label.Kind, label.Ref, label.Label)
+	return err
+}
+
+func (s *PostgresStore) DeleteLabel(account string, kind, ref string) error {
+	res, err := s.db.Exec(`DELETE FROM wallet_labels WHERE account = $1 AND kind = $2 AND ref = $3`, account, kind, ref)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) Labels(account string) ([]Label, error) {
+	rows, err := s.db.Query(`
+		SELECT kind, ref, label FROM wallet_labels WHERE account = $1 ORDER BY kind, ref`, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var labels []Label
+	for rows.Next() {
+		var label Label
+		if err := rows.Scan(&label.Kind, &label.Ref, &label.Label); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+func (s *PostgresStore) SaveIdempotentResponse(account string, resp IdempotentResponse) error {
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_idempotency (account, key, fingerprint, status, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account, key) DO UPDATE SET fingerprint = $3, status = $4, body = $5, created_at = $6`,
+		account, resp.Key, resp.Fingerprint, resp.Status, resp.Body, resp.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) IdempotentResponse(account string, key string) (IdempotentResponse, error) {
+	resp := IdempotentResponse{Key: key}
+	err := s.db.QueryRow(`
+		SELECT fingerprint, status, body, created_at FROM wallet_idempotency
+		WHERE account = $1 AND key = $2`, account, key).
+		Scan(&resp.Fingerprint, &resp.Status, &resp.Body, &resp.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return IdempotentResponse{}, ErrNotFound
+	}
+	return resp, err
+}
+
+func (s *PostgresStore) PruneIdempotentResponses(account string, before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM wallet_idempotency WHERE account = $1 AND created_at < $2`, account, before)
+	return err
+}
+
+func (s *PostgresStore) SavePolicyState(account string, state PolicyState) error {
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_policy (account, policy, token_hash, held) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account) DO UPDATE SET policy = $2, token_hash = $3, held = $4`,
+		account, []byte(state.Policy), state.TokenHash, []byte(state.Held))
+	return err
+}
+
+func (s *PostgresStore) PolicyState(account string) (PolicyState, error) {
+	var state PolicyState
+	var policy, held []byte
+	err := s.db.QueryRow(`
+		SELECT policy, token_hash, held FROM wallet_policy WHERE account = $1`,
+		account).Scan(&policy, &state.TokenHash, &held)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PolicyState{}, ErrNotFound
+	}
+	state.Policy, state.Held = policy, held
+	return state, err
+}
+
+func (s *PostgresStore) SaveRecoveryState(account string, state RecoveryState) error {
+	_, err := s.db.Exec(`
+		INSERT INTO wallet_recovery (account, guardians, requests) VALUES ($1, $2, $3)
+		ON CONFLICT (account) DO UPDATE SET guardians = $2, requests = $3`,
+		account, []byte(state.Guardians), []byte(state.Requests))
+	return err
+}
+
+func (s *PostgresStore) RecoveryState(account string) (RecoveryState, error) {
+	var guardians, requests []byte
+	err := s.db.QueryRow(`
+		SELECT guardians, requests FROM wallet_recovery WHERE account = $1`,
+		account).Scan(&guardians, &requests)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RecoveryState{}, ErrNotFound
+	}
+	return RecoveryState{Guardians: guardians, Requests: requests}, err
+}
+
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
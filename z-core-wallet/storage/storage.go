@@ -0,0 +1,175 @@
+// Package storage persists wallet state: accounts, their unspent outputs and
+// notes, transaction history, contacts, spending policy and social recovery. Keys never pass through it; the
+// private key stays in the encrypted keystore.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Account is a wallet account the store holds state for
+type Account struct {
+	Address   string    `json:"address"` // Hex account address shared by all chains
+	ZAddress  string    `json:"z_address"`
+	PublicKey string    `json:"public_key,omitempty"` // Compressed, hex; empty until first unlocked
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UTXO is an unspent transparent output of an account
+type UTXO struct {
+	TxHash      string `json:"tx_hash"`
+	OutputIndex uint32 `json:"output_index"`
+	Amount      int64  `json:"amount"`
+	Pending     bool   `json:"pending"`
+	Frozen      bool   `json:"frozen"` // Never picked by coin selection
+}
+
+// Note is an unspent shielded note of an account
+type Note struct {
+	Commitment string `json:"commitment"`
+	Nullifier  string `json:"nullifier"`
+	Amount     int64  `json:"amount"`
+	Pending    bool   `json:"pending"`
+	Spent      bool   `json:"spent"` // By a transaction not yet seen in a block
+}
+
+// Transaction is an entry of an account's history
+type Transaction struct {
+	Hash      string    `json:"hash"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    int64     `json:"amount"`
+	Token     string    `json:"token"`
+	Timestamp time.Time `json:"timestamp"`
+	Status    string    `json:"status"`
+	Memo      string    `json:"memo"`
+	Private   bool      `json:"private"`
+}
+
+// Contact is a named address in an account's address book
+type Contact struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Note    string `json:"note,omitempty"`
+}
+
+// Label kinds
+const (
+	LabelAddress = "address"
+	LabelUTXO    = "utxo" // Ref is tx_hash:output_index
+)
+
+// Label is a note the user attached to an address or output
+type Label struct {
+	Kind  string `json:"kind"`
+	Ref   string `json:"ref"`
+	Label string `json:"label"`
+}
+
+// IdempotentResponse is the stored outcome of a request sent with an
+// Idempotency-Key. A retry with the same key and fingerprint gets Body back
+// instead of being executed again.
+type IdempotentResponse struct {
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"` // Hash of the route and request body
+	Status      int       `json:"status"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PolicyState is the spending policy of an account, the hash of its
+// confirmation token and the transactions held under it. Only the wallet
+// interprets the policy and held spends, so they are kept as its JSON.
+type PolicyState struct {
+	Policy    json.RawMessage `json:"policy"`               // null while no policy is set
+	TokenHash []byte          `json:"token_hash,omitempty"` // SHA-256 of the confirmation token
+	Held      json.RawMessage `json:"held"`
+}
+
+// RecoveryState is the guardian set of an account and its recovery
+// requests, kept as the wallet's JSON like PolicyState
+type RecoveryState struct {
+	Guardians json.RawMessage `json:"guardians"` // null while no guardians are set
+	Requests  json.RawMessage `json:"requests"`
+}
+
+// Store persists wallet state. Everything but accounts is scoped by the hex
+// account address. Implementations are safe for concurrent use.
+type Store interface {
+	SaveAccount(account Account) error
+	GetAccount(address string) (Account, error)
+	Accounts() ([]Account, error)
+
+	// ReplaceUTXOs and ReplaceNotes swap the whole set atomically, coin
+	// selection rewrites it at once
+	ReplaceUTXOs(account string, utxos []UTXO) error
+	UTXOs(account string) ([]UTXO, error)
+	ReplaceNotes(account string, notes []Note) error
+	Notes(account string) ([]Note, error)
+
+	// SaveTransaction inserts or updates a transaction by hash; Transactions
+	// returns the history oldest first
+	SaveTransaction(account string, tx Transaction) error
+	Transactions(account string) ([]Transaction, error)
+
+	SaveContact(account string, contact Contact) error
+	DeleteContact(account string, name string) error
+	Contacts(account string) ([]Contact, error)
+
+	// Labels outlive the outputs they name, so history stays readable
+	SaveLabel(account string, label Label) error
+	DeleteLabel(account string, kind, ref string) error
+	Labels(account string) ([]Label, error)
+
+	// IdempotentResponse returns ErrNotFound for an unknown key;
+	// PruneIdempotentResponses drops those created before the cutoff
+	SaveIdempotentResponse(account string, resp IdempotentResponse) error
+	IdempotentResponse(account string, key string) (IdempotentResponse, error)
+	PruneIdempotentResponses(account string, before time.Time) error
+
+	// SavePolicyState replaces the account's spending policy state;
+	// PolicyState returns ErrNotFound until one is saved
+	SavePolicyState(account string, state PolicyState) error
+	PolicyState(account string) (PolicyState, error)
+
+	// SaveRecoveryState replaces the account's guardians and recovery
+	// requests; RecoveryState returns ErrNotFound until they are saved
+	SaveRecoveryState(account string, state RecoveryState) error
+	RecoveryState(account string) (RecoveryState, error)
+
+	Close() error
+}
+
+// Open returns the store described by dsn:
+//
+//	""                         in memory, lost on exit
+//	bolt:///var/lib/wallet.db  embedded BoltDB file
+//	postgres://user@host/db    PostgreSQL
+//
+// A bare path is treated as a BoltDB file.
+func Open(dsn string) (Store, error) {
+	switch {
+	case dsn == "" || dsn == "memory":
+		return NewMemoryStore(), nil
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return OpenPostgres(dsn)
+	case strings.HasPrefix(dsn, "bolt://"):
+		return OpenBolt(strings.TrimPrefix(dsn, "bolt://"))
+	case strings.Contains(dsn, "://"):
+		return nil, fmt.Errorf("unsupported wallet database: %s", dsn)
+	default:
+		return OpenBolt(dsn)
+	}
+}
+
+// labelKey orders labels by kind, then reference
+func labelKey(kind, ref string) string {
+	return kind + "/" + ref
+}
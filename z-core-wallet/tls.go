@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// selfSignedValidity is how long a generated development certificate is valid
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// TLSSettings configures HTTPS termination for the wallet server
+type TLSSettings struct {
+	CertFile     string // PEM certificate chain
+	KeyFile      string // PEM private key
+	ClientCAFile string // When set, clients must present a certificate signed by this CA
+	SelfSigned   bool   // Generate a development certificate when none exists
+}
+
+// LoadTLSSettings reads WALLET_TLS_* from the environment
+func LoadTLSSettings() TLSSettings {
+	return TLSSettings{
+		CertFile:     os.Getenv("WALLET_TLS_CERT"),
+		KeyFile:      os.Getenv("WALLET_TLS_KEY"),
+		ClientCAFile: os.Getenv("WALLET_TLS_CLIENT_CA"),
+		SelfSigned:   os.Getenv("WALLET_TLS_SELF_SIGNED") == "true",
+	}
+}
+
+// Enabled reports whether the server should listen with TLS
+func (s TLSSettings) Enabled() bool {
+	return s.SelfSigned || (s.CertFile != "" && s.KeyFile != "")
+}
+
+// Config builds the server TLS configuration. With SelfSigned, a certificate is
+// generated for localhost; it is written to CertFile/KeyFile when those are set
+// so browsers only need to trust it once.
+func (s TLSSettings) Config() (*tls.Config, error) {
+	cert, err := s.loadCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if s.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(s.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", s.ClientCAFile)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+func (s TLSSettings) loadCertificate() (tls.Certificate, error) {
+	haveFiles := s.CertFile != "" && s.KeyFile != ""
+
+	if haveFiles && fileExists(s.CertFile) && fileExists(s.KeyFile) {
+		return tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	}
+	if !s.SelfSigned {
+		return tls.Certificate{}, fmt.Errorf("certificate %s or key %s not found", s.CertFile, s.KeyFile)
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	if haveFiles {
+		if err := os.WriteFile(s.CertFile, certPEM, 0644); err != nil {
+			return tls.Certificate{}, err
+		}
+		if err := os.WriteFile(s.KeyFile, keyPEM, 0600); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// generateSelfSigned creates a P-256 certificate for localhost. It is meant for
+// development only; production deployments should supply a CA issued certificate.
+func generateSelfSigned() (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Z Core Wallet"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
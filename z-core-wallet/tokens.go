@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"z-core-wallet/wallet"
+)
+
+// LoadTokens registers the bridged tokens listed in WALLET_TOKENS, given as
+// comma separated DENOM:decimals[:chain[:name]] entries
+func LoadTokens() error {
+	spec := os.Getenv("WALLET_TOKENS")
+	if spec == "" {
+		return nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ":")
+		if len(fields) < 2 || len(fields) > 4 {
+			return fmt.Errorf("invalid WALLET_TOKENS entry %q", entry)
+		}
+		decimals, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid WALLET_TOKENS entry %q: %w", entry, err)
+		}
+
+		info := wallet.TokenInfo{Denom: fields[0], Decimals: decimals, Chain: "bridged"}
+		if len(fields) > 2 {
+			info.Chain = fields[2]
+		}
+		if len(fields) > 3 {
+			info.Name = fields[3]
+		}
+		if err := wallet.RegisterToken(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenInfos returns the metadata of the given denominations, by denom
+func tokenInfos(denoms ...string) map[string]wallet.TokenInfo {
+	infos := make(map[string]wallet.TokenInfo, len(denoms))
+	for _, denom := range denoms {
+		if info, ok := wallet.LookupToken(denom); ok {
+			infos[info.Denom] = info
+		}
+	}
+	return infos
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wallet.Tokens())
+}
+
+// setTokenBalance takes the chain balance of an account based denomination
+// from whatever follows the chain, as /rewards does for mining rewards
+func (ws *WalletService) setTokenBalance(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token  string `json:"token"`
+		Amount string `json:"amount"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	amount, err := strconv.ParseInt(req.Amount, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid amount", http.StatusBadRequest)
+		return
+	}
+
+	if err := ws.wallet.SetTokenBalance(req.Token, amount); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, wallet.ErrUnknownToken) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	balance := ws.wallet.Balance()
+	ws.publish("balance", balance)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"shared/address"
+)
+
+var ErrMalformedSignature = errors.New("malformed signature")
+
+// MessageVerification is the outcome of checking a signed message
+type MessageVerification struct {
+	Valid     bool   `json:"valid"`
+	Address   string `json:"address"`              // The address as given
+	Format    string `json:"format"`               // Encoding the address was given in
+	Signer    string `json:"signer,omitempty"`     // zChain address of the recovered key
+	PublicKey string `json:"public_key,omitempty"` // Recovered compressed key, hex
+	Reason    string `json:"reason,omitempty"`
+}
+
+// VerifyMessage checks a signature produced by SignMessage: the signer key is
+// recovered from the 65 byte signature and must derive the claimed account.
+// Z base58 and zChain or nuChain bech32 addresses name the same account.
+func VerifyMessage(claimed, message, signature string) (MessageVerification, error) {
+	addr, format, hrp, err := address.Parse(claimed)
+	if err != nil {
+		return MessageVerification{}, err
+	}
+	if format == address.FormatBech32 && hrp != address.ZChainHRP && hrp != address.NuChainHRP {
+		return MessageVerification{}, fmt.Errorf("%w: %s", address.ErrWrongHRP, hrp)
+	}
+
+	result := MessageVerification{Address: claimed, Format: format.String()}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != 65 {
+		return MessageVerification{}, fmt.Errorf("%w: expected 65 hex encoded bytes", ErrMalformedSignature)
+	}
+
+	hash := sha256.Sum256([]byte(message))
+	pubKey, err := recoverSigner(hash[:], signature)
+	if err != nil {
+		result.Reason = err.Error()
+		return result, nil
+	}
+
+	// recoverSigner returns a valid compressed key, so derivation cannot fail
+	pubKeyBytes, _ := hex.DecodeString(pubKey)
+	signer, _ := address.FromPubKey(pubKeyBytes)
+
+	result.Signer = signer.ZChain()
+	result.PublicKey = pubKey
+	result.Valid = signer.Equal(addr)
+	if !result.Valid {
+		result.Reason = "signature was made by a different key"
+	}
+
+	return result, nil
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) verifyMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Address   string `json:"address"`
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := VerifyMessage(req.Address, req.Message, req.Signature)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"z-core-wallet/wallet"
+)
+
+// APIVersionHeader selects the API version of an unversioned /api request and
+// names the version that answered
+const APIVersionHeader = "API-Version"
+
+// DefaultAPIVersion answers unversioned requests that do not ask for a
+// version, so existing clients keep the shape they were written against
+const DefaultAPIVersion = "v1"
+
+// Transaction history page sizes of v2
+const (
+	defaultHistoryPage = 50
+	maxHistoryPage     = 500
+)
+
+// APIVersion is a served version of the REST API and its lifecycle. Zero
+// dates mean the version is not deprecated or has no removal date yet.
+type APIVersion struct {
+	Name       string
+	Deprecated time.Time
+	Sunset     time.Time // Requests are answered 410 Gone from this time on
+	Successor  string
+}
+
+// apiVersions lists the served versions, oldest first. v1 is the original
+// shape the static frontend uses; it stays served until its sunset.
+var apiVersions = []APIVersion{
+	{
+		Name:       "v1",
+		Deprecated: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:     time.Date(2027, time.November, 1, 0, 0, 0, 0, time.UTC),
+		Successor:  "v2",
+	},
+	{Name: "v2"},
+}
+
+// vendorMediaType matches Accept values like application/vnd.zcore.v2+json
+var vendorMediaType = regexp.MustCompile(`application/vnd\.zcore\.(v\d+)\+json`)
+
+func lookupAPIVersion(name string) (APIVersion, bool) {
+	if !strings.HasPrefix(name, "v") {
+		name = "v" + name
+	}
+	for _, v := range apiVersions {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return APIVersion{}, false
+}
+
+// negotiateAPIVersion picks the version of an unversioned request from the
+// API-Version header or a vendor media type in Accept
+func negotiateAPIVersion(r *http.Request) string {
+	if requested := strings.TrimSpace(r.Header.Get(APIVersionHeader)); requested != "" {
+		return requested
+	}
+	if m := vendorMediaType.FindStringSubmatch(r.Header.Get("Accept")); m != nil {
+		return m[1]
+	}
+	return DefaultAPIVersion
+}
+
+// serveVersion answers with the handler of version v, announcing deprecation
+// and sunset as described by RFC 9745 and RFC 8594
+func serveVersion(v APIVersion, handler http.Handler, w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	if !v.Sunset.IsZero() && !now.Before(v.Sunset) {
+		http.Error(w, "API "+v.Name+" was retired on "+v.Sunset.Format(time.DateOnly)+", use /api/"+v.Successor, http.StatusGone)
+		return
+	}
+
+	w.Header().Set(APIVersionHeader, v.Name)
+	if !v.Deprecated.IsZero() && !now.Before(v.Deprecated) {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+		if v.Successor != "" {
+			w.Header().Set("Link", `</api/`+v.Successor+`>; rel="successor-version"`)
+		}
+	}
+	if !v.Sunset.IsZero() {
+		w.Header().Set("Sunset", v.Sunset.Format(http.TimeFormat))
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// versionedAPI serves /api/{version}/... from the matching router and
+// negotiates the version of unversioned /api/... requests
+func versionedAPI(routers map[string]http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api")
+
+		name := ""
+		if segment := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]; routers[segment] != nil {
+			name = segment
+			path = strings.TrimPrefix(path, "/"+segment)
+		} else {
+			name = negotiateAPIVersion(r)
+			w.Header().Add("Vary", APIVersionHeader+", Accept")
+		}
+
+		v, ok := lookupAPIVersion(name)
+		if !ok {
+			http.Error(w, "unsupported API version "+name, http.StatusNotAcceptable)
+			return
+		}
+
+		r.URL.Path = path
+		r.URL.RawPath = ""
+		serveVersion(v, routers[v.Name], w, r)
+	})
+}
+
+// routesV2 registers the v2 API. It changes the shape of the wallet info and
+// transaction history and adds accounts; every other route matches v1.
+func (ws *WalletService) routesV2(r *mux.Router) {
+	r.HandleFunc("/wallet", ws.getWalletInfoV2).Methods("GET")
+	r.HandleFunc("/transactions", ws.getTransactionPage).Methods("GET")
+	r.HandleFunc("/accounts", ws.getAccounts).Methods("GET")
+
+	ws.routesV1(r)
+}
+
+// BalanceV2 is a token balance of the v2 schema. Amounts are decimal strings
+// in base units so clients do not lose precision above 2^53.
+type BalanceV2 struct {
+	Token       string `json:"token"`
+	Decimals    int    `json:"decimals"`
+	Transparent string `json:"transparent"`
+	Shielded    string `json:"shielded"`
+	Total       string `json:"total"`
+}
+
+func balancesV2(balance wallet.Balance) []BalanceV2 {
+	format := func(amount int64) string { return strconv.FormatInt(amount, 10) }
+	// NU stays listed even when empty, as it was before other tokens
+	denoms := balance.Denoms()
+	if balance.Amounts[wallet.DenomNU] == 0 {
+		denoms = append(denoms[:1], append([]string{wallet.DenomNU}, denoms[1:]...)...)
+	}
+
+	balances := make([]BalanceV2, 0, len(denoms))
+	for _, denom := range denoms {
+		info, _ := wallet.LookupToken(denom)
+		transparent, shielded := balance.Amounts[denom], balance.Shielded[denom]
+		balances = append(balances, BalanceV2{
+			Token:       denom,
+			Decimals:    info.Decimals,
+			Transparent: format(transparent),
+			Shielded:    format(shielded),
+			Total:       format(transparent + shielded),
+		})
+	}
+	return balances
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getWalletInfoV2(w http.ResponseWriter, r *http.Request) {
+	addr := ws.wallet.Account()
+
+	publicKey := ""
+	if pub := ws.wallet.PublicKey(); pub != nil {
+		publicKey = hex.EncodeToString(pub.SerializeCompressed())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account": map[string]interface{}{
+			"address": addr.ZBase58(),
+			"addresses": map[string]string{
+				"zchain":  addr.ZChain(),
+				"nuchain": addr.NuChain(),
+				"evm":     evmAddress(ws.wallet),
+				"zcash":   addr.Zcash(),
+			},
+			"public_key": publicKey,
+			"locked":     ws.wallet.Locked(),
+		},
+		"balances": balancesV2(ws.wallet.Balance()),
+	})
+}
+
+// getTransactionPage returns the history newest first. The cursor is the
+// position of the oldest entry returned; history is append only, so a cursor
+// stays valid while new transactions arrive.
+func (ws *WalletService) getTransactionPage(w http.ResponseWriter, r *http.Request) {
+	history := ws.wallet.Transactions()
+
+	limit := defaultHistoryPage
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxHistoryPage {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxHistoryPage), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	end := len(history)
+	if s := r.URL.Query().Get("cursor"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > len(history) {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		end = n
+	}
+
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+
+	page := make([]wallet.Transaction, 0, end-start)
+	var denoms []string
+	for i := end - 1; i >= start; i-- {
+		page = append(page, history[i])
+		denoms = append(denoms, history[i].Token)
+	}
+
+	response := map[string]interface{}{
+		"transactions": page,
+		"tokens":       tokenInfos(denoms...),
+		"total":        len(history),
+	}
+	if start > 0 {
+		response["next_cursor"] = strconv.Itoa(start)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (ws *WalletService) getAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := ws.store.Accounts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	active := ws.wallet.Account().Hex()
+	result := make([]map[string]interface{}, 0, len(accounts))
+	for _, account := range accounts {
+		result = append(result, map[string]interface{}{
+			"address":    account.Address,
+			"z_address":  account.ZAddress,
+			"public_key": account.PublicKey,
+			"created_at": account.CreatedAt,
+			"active":     account.Address == active,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"accounts": result})
+}
@@ -0,0 +1,202 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"shared/address"
+)
+
+// diversifierTagLength is the part of a diversifier authenticating its index
+const diversifierTagLength = address.DiversifierLength - 8
+
+var ErrForeignReceiver = errors.New("receiver does not belong to this wallet")
+
+// shieldedKeys are derived from the wallet key. The diversifier key turns an
+// index into a diversifier and back; the incoming viewing key derives the
+// transmission key every receiver of the wallet is checked against; the
+// nullifier key turns a note commitment into the nullifier spending it.
+type shieldedKeys struct {
+	diversifierKey     []byte
+	incomingViewingKey []byte
+	nullifierKey       []byte
+}
+
+// shieldedKeys derives the keys of the current private key; callers hold mu
+func (w *Wallet) shieldedKeys() (shieldedKeys, error) {
+	if w.signer == nil {
+		return shieldedKeys{}, ErrLocked
+	}
+
+	spend, err := w.signer.Derive([]byte("z-spend:"), nil)
+	if err != nil {
+		return shieldedKeys{}, err
+	}
+	dk := sha256.Sum256(append([]byte("z-diversifier:"), spend[:]...))
+	ivk := sha256.Sum256(append([]byte("z-ivk:"), spend[:]...))
+	nk := sha256.Sum256(append([]byte("z-nk:"), spend[:]...))
+
+	return shieldedKeys{diversifierKey: dk[:], incomingViewingKey: ivk[:], nullifierKey: nk[:]}, nil
+}
+
+// nullifier is PRF_nk(commitment). Only the holder of the spending key can
+// compute it, it is the same every time a note is spent, so the pool rejects
+// a second spend, and no two notes share one.
+func (k shieldedKeys) nullifier(commitment []byte) []byte {
+	mac := hmac.New(sha256.New, k.nullifierKey)
+	mac.Write([]byte("z-nullifier:"))
+	mac.Write(commitment)
+	return mac.Sum(nil)
+}
+
+func (k shieldedKeys) prf(data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, k.diversifierKey)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// diversifier masks the index so receivers cannot be linked, followed by a tag
+// that lets the wallet recognise its own diversifiers
+func (k shieldedKeys) diversifier(index uint64) []byte {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], index)
+
+	mask := k.prf([]byte("mask"))
+	d := make([]byte, 0, address.DiversifierLength)
+	for i := range idx {
+		d = append(d, idx[i]^mask[i])
+	}
+	return append(d, k.prf([]byte("tag"), idx[:])[:diversifierTagLength]...)
+}
+
+// index recovers the index of one of the wallet's diversifiers
+func (k shieldedKeys) index(d []byte) (uint64, bool) {
+	mask := k.prf([]byte("mask"))
+
+	var idx [8]byte
+	for i := range idx {
+		idx[i] = d[i] ^ mask[i]
+	}
+
+	tag := k.prf([]byte("tag"), idx[:])[:diversifierTagLength]
+	if !hmac.Equal(tag, d[8:]) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(idx[:]), true
+}
+
+func (k shieldedKeys) receiver(index uint64) address.ShieldedReceiver {
+	d := k.diversifier(index)
+	pkd := sha256.Sum256(append(append([]byte(nil), k.incomingViewingKey...), d...))
+
+	r, _ := address.ShieldedReceiverFromBytes(append(d, pkd[:]...))
+	return r
+}
+
+// DiversifiedAddress returns the shielded address at index. All diversified
+// addresses pay the same spend key but cannot be linked to each other.
+func (w *Wallet) DiversifiedAddress(index uint64) (address.ShieldedReceiver, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keys, err := w.shieldedKeys()
+	if err != nil {
+		return address.ShieldedReceiver{}, err
+	}
+	return keys.receiver(index), nil
+}
+
+// UnifiedAddress bundles the wallet's transparent account with the shielded
+// address at index
+func (w *Wallet) UnifiedAddress(index uint64) (address.Unified, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.unifiedAddress(index)
+}
+
+func (w *Wallet) unifiedAddress(index uint64) (address.Unified, error) {
+	keys, err := w.shieldedKeys()
+	if err != nil {
+		return address.Unified{}, err
+	}
+
+	account := w.account
+	shielded := keys.receiver(index)
+	return address.Unified{Transparent: &account, Shielded: &shielded}, nil
+}
+
+// NextDiversifier returns the last diversified address index handed out
+func (w *Wallet) NextDiversifier() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nextDiversifier
+}
+
+// NewDiversifiedAddress hands out the unified address at the next unused
+// index. Index 0 is the default address and is never handed out here.
+func (w *Wallet) NewDiversifiedAddress() (uint64, address.Unified, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return 0, address.Unified{}, ErrLocked
+	}
+
+	w.nextDiversifier++
+	unified, err := w.unifiedAddress(w.nextDiversifier)
+	return w.nextDiversifier, unified, err
+}
+
+// ReceiverMatch reports which of the wallet's receivers a payment was sent to
+type ReceiverMatch struct {
+	Kind    string  `json:"kind"`
+	Index   *uint64 `json:"index,omitempty"` // Diversifier index of a shielded receiver
+	Address string  `json:"address"`
+	Unified string  `json:"unified,omitempty"`
+}
+
+// DetectReceiver tells which receiver of the wallet an incoming payment used.
+// For a unified address the receiver a sender would pick is checked.
+func (w *Wallet) DetectReceiver(receiver string) (ReceiverMatch, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keys, err := w.shieldedKeys()
+	if err != nil {
+		return ReceiverMatch{}, err
+	}
+
+	recipient, err := address.ParseRecipient(receiver)
+	if err != nil {
+		return ReceiverMatch{}, err
+	}
+
+	// The wallet keeps no redeem scripts, so no script hash is its own
+	if recipient.Kind == address.ReceiverScriptHash {
+		return ReceiverMatch{}, ErrForeignReceiver
+	}
+	if recipient.Kind == address.ReceiverTransparent {
+		if !recipient.Transparent.Equal(w.account) {
+			return ReceiverMatch{}, ErrForeignReceiver
+		}
+		return ReceiverMatch{Kind: recipient.Kind.String(), Address: recipient.Transparent.ZChain()}, nil
+	}
+
+	index, ok := keys.index(recipient.Shielded.Diversifier())
+	if !ok || keys.receiver(index) != recipient.Shielded {
+		return ReceiverMatch{}, ErrForeignReceiver
+	}
+
+	unified, _ := w.unifiedAddress(index)
+	return ReceiverMatch{
+		Kind:    recipient.Kind.String(),
+		Index:   &index,
+		Address: recipient.Shielded.String(),
+		Unified: unified.String(),
+	}, nil
+}
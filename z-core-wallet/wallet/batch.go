@@ -0,0 +1,159 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"shared/address"
+	"shared/sighash"
+)
+
+// MaxBatchPayments bounds the outputs of one batch transaction; the change
+// output comes on top
+const MaxBatchPayments = 500
+
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// NormalizeBatch checks every payment and rewrites recipients to their zChain
+// transparent address, or the zsh1... form of a script hash. It returns the
+// total paid out.
+func NormalizeBatch(payments []BatchPayment) ([]BatchPayment, int64, error) {
+	if len(payments) == 0 {
+		return nil, 0, fmt.Errorf("%w: no payments", ErrInvalidBatch)
+	}
+	if len(payments) > MaxBatchPayments {
+		return nil, 0, fmt.Errorf("%w: %d payments exceed the limit of %d", ErrInvalidBatch, len(payments), MaxBatchPayments)
+	}
+
+	normalized := make([]BatchPayment, len(payments))
+	var total int64
+	for i, payment := range payments {
+		// Transparent outputs only move Z
+		if payment.Token == "" {
+			payment.Token = DenomZ
+		}
+		if !strings.EqualFold(payment.Token, DenomZ) {
+			return nil, 0, fmt.Errorf("%w: payment %d: only Z can be batched, got %s", ErrInvalidBatch, i, payment.Token)
+		}
+		payment.Token = DenomZ
+
+		if payment.Amount <= 0 {
+			return nil, 0, fmt.Errorf("%w: payment %d: invalid amount %d", ErrInvalidBatch, i, payment.Amount)
+		}
+		if total > total+payment.Amount {
+			return nil, 0, fmt.Errorf("%w: total overflows", ErrInvalidBatch)
+		}
+		total += payment.Amount
+
+		// Unified addresses are paid through their transparent receiver here
+		recipient, err := address.ParseRecipient(payment.Recipient)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: payment %d: %v", ErrInvalidBatch, i, err)
+		}
+		if recipient.Kind == address.ReceiverShielded && (!recipient.Unified || recipient.Transparent == (address.Address{})) {
+			return nil, 0, fmt.Errorf("%w: payment %d: shielded recipients cannot be paid from a transparent batch", ErrInvalidBatch, i)
+		}
+		if recipient.Kind == address.ReceiverScriptHash {
+			payment.Recipient = recipient.String()
+		} else {
+			payment.Recipient = recipient.Transparent.ZChain()
+		}
+
+		normalized[i] = payment
+	}
+
+	return normalized, total, nil
+}
+
+// paymentOutput is the output paying a normalized payment. A script hash is
+// paid with a script locking the output to it, under the z1... form of the
+// hash so the chain indexes the output by the script.
+func paymentOutput(payment BatchPayment) TxOutput {
+	output := TxOutput{Amount: strconv.FormatInt(payment.Amount, 10), Address: payment.Recipient}
+	if hash, err := address.ParseScriptHash(payment.Recipient); err == nil {
+		output.Address = hash.ZChain()
+		output.ScriptPubkey = sighash.ScriptHashScript(hash)
+	}
+	return output
+}
+
+// SendBatch pays every recipient from one multi-output UTXO transaction.
+// Payments to the wallet itself are allowed and simply come back as outputs.
+func (w *Wallet) SendBatch(payments []BatchPayment, fee int64) (*BatchOperation, error) {
+	payments, total, err := NormalizeBatch(payments)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+
+	inputs, available, err := w.selectUTXOs(total + fee)
+	if err != nil {
+		return nil, err
+	}
+	change := available - total - fee
+
+	creator := w.account.ZChain()
+	send := &MsgSendUTXO{
+		Creator: creator,
+		Fee:     strconv.FormatInt(fee, 10),
+	}
+	for _, payment := range payments {
+		send.Outputs = append(send.Outputs, paymentOutput(payment))
+	}
+	if change > 0 {
+		send.Outputs = append(send.Outputs, TxOutput{Amount: strconv.FormatInt(change, 10), Address: creator})
+	}
+	for _, utxo := range inputs {
+		send.Inputs = append(send.Inputs, TxInput{PrevTxHash: utxo.TxHash, PrevOutputIndex: utxo.OutputIndex})
+	}
+
+	txHash := send.Hash()
+	if err := w.signInputs(send, inputs); err != nil {
+		return nil, err
+	}
+
+	w.spendUTXOs(inputs)
+	for i, output := range send.Outputs {
+		if output.Address != creator {
+			continue
+		}
+		amount, _ := strconv.ParseInt(output.Amount, 10, 64)
+		w.utxos = append(w.utxos, UTXO{TxHash: txHash, OutputIndex: uint32(i), Amount: amount, Pending: true})
+	}
+	w.refreshBalance()
+	w.saveCoins()
+
+	// The history holds the batch as one spend so daily limits see its total
+	tx := Transaction{
+		Hash:      txHash,
+		From:      w.account.ZBase58(),
+		To:        fmt.Sprintf("batch of %d payments", len(payments)),
+		Amount:    total,
+		Token:     DenomZ,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      fmt.Sprintf("batch payment to %d recipients", len(payments)),
+		Private:   false,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, fee, EstimateTxSize(len(send.Inputs), len(send.Outputs)), inputs, nil)
+
+	return &BatchOperation{
+		SendUTXO:    send,
+		TxHash:      txHash,
+		Payments:    payments,
+		Total:       total,
+		Fee:         fee,
+		Change:      change,
+		Transaction: tx,
+	}, nil
+}
@@ -0,0 +1,165 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Approximate encoded sizes in bytes of a MsgSendUTXO: a signed input carries
+// the previous hash, index and 100 byte script signature, an output its amount
+// and address
+const (
+	txBaseSize   = 72
+	txInputSize  = 175
+	txOutputSize = 66
+)
+
+// DefaultFeeRate is the fee in base units per estimated byte
+const DefaultFeeRate int64 = 10
+
+// MaxConsolidationInputs bounds the inputs swept by one consolidation
+const MaxConsolidationInputs = 200
+
+var ErrNothingToConsolidate = errors.New("nothing to consolidate")
+
+// EstimateTxSize estimates the encoded size of a signed MsgSendUTXO
+func EstimateTxSize(inputs, outputs int) int64 {
+	return txBaseSize + int64(inputs)*txInputSize + int64(outputs)*txOutputSize
+}
+
+// estimateShieldedSize estimates the encoded size of a MsgSendShielded
+func estimateShieldedSize(msg *MsgSendShielded) int64 {
+	size := int64(len(msg.Creator)+len(msg.Fee)+len(msg.ValueBalance)+len(msg.ZkProof)+len(msg.EncryptedMemo)) + 16
+	for _, nullifier := range msg.Nullifiers {
+		size += int64(len(nullifier)) + 2
+	}
+	for _, output := range msg.TransparentOutputs {
+		size += int64(len(output.Amount)+len(output.Address)+len(output.ScriptPubkey)) + 8
+	}
+	for _, commitment := range msg.Commitments {
+		size += int64(len(commitment)) + 2
+	}
+	return size
+}
+
+// ConsolidationRequest selects the outputs to sweep
+type ConsolidationRequest struct {
+	MaxInputs int   // At most MaxConsolidationInputs; zero means the maximum
+	Below     int64 // Only outputs smaller than this are swept; zero sweeps any
+	FeeRate   int64 // Per estimated byte; zero means DefaultFeeRate
+	MaxFee    int64 // The sweep is refused when its fee is higher; zero disables
+	DryRun    bool  // Report the sweep without signing it
+}
+
+// ConsolidationOperation is a sweep of small UTXOs into one output paying the
+// wallet. A dry run has no message and leaves the coins untouched.
+type ConsolidationOperation struct {
+	SendUTXO    *MsgSendUTXO `json:"send_utxo,omitempty"`
+	TxHash      string       `json:"tx_hash,omitempty"`
+	Inputs      []UTXO       `json:"inputs"`
+	InputTotal  int64        `json:"input_total"`
+	SizeBytes   int64        `json:"size_bytes"`
+	FeeRate     int64        `json:"fee_rate"`
+	Fee         int64        `json:"fee"`
+	Output      int64        `json:"output"`
+	DryRun      bool         `json:"dry_run"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
+
+// Consolidate sweeps the smallest confirmed UTXOs into a single output of the
+// wallet, so later spends need fewer inputs. Outputs worth less than the fee
+// they add are left alone.
+func (w *Wallet) Consolidate(req ConsolidationRequest) (*ConsolidationOperation, error) {
+	if req.MaxInputs <= 0 || req.MaxInputs > MaxConsolidationInputs {
+		req.MaxInputs = MaxConsolidationInputs
+	}
+	if req.FeeRate <= 0 {
+		req.FeeRate = DefaultFeeRate
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil && !req.DryRun {
+		return nil, ErrLocked
+	}
+
+	candidates := make([]UTXO, 0, len(w.utxos))
+	for _, utxo := range w.utxos {
+		if utxo.Pending || utxo.Frozen || (req.Below > 0 && utxo.Amount >= req.Below) {
+			continue
+		}
+		if utxo.Amount <= txInputSize*req.FeeRate {
+			continue
+		}
+		candidates = append(candidates, utxo)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Amount < candidates[j].Amount })
+	if len(candidates) > req.MaxInputs {
+		candidates = candidates[:req.MaxInputs]
+	}
+	if len(candidates) < 2 {
+		return nil, fmt.Errorf("%w: %d spendable outputs match", ErrNothingToConsolidate, len(candidates))
+	}
+
+	op := &ConsolidationOperation{
+		Inputs:    candidates,
+		SizeBytes: EstimateTxSize(len(candidates), 1),
+		FeeRate:   req.FeeRate,
+		DryRun:    req.DryRun,
+	}
+	for _, utxo := range candidates {
+		op.InputTotal += utxo.Amount
+	}
+	op.Fee = op.SizeBytes * req.FeeRate
+	op.Output = op.InputTotal - op.Fee
+
+	if req.MaxFee > 0 && op.Fee > req.MaxFee {
+		return nil, fmt.Errorf("%w: fee %d exceeds the limit of %d", ErrNothingToConsolidate, op.Fee, req.MaxFee)
+	}
+	if req.DryRun {
+		return op, nil
+	}
+
+	creator := w.account.ZChain()
+	send := &MsgSendUTXO{
+		Creator: creator,
+		Outputs: []TxOutput{{Amount: strconv.FormatInt(op.Output, 10), Address: creator}},
+		Fee:     strconv.FormatInt(op.Fee, 10),
+	}
+	for _, utxo := range candidates {
+		send.Inputs = append(send.Inputs, TxInput{PrevTxHash: utxo.TxHash, PrevOutputIndex: utxo.OutputIndex})
+	}
+
+	txHash := send.Hash()
+	if err := w.signInputs(send, candidates); err != nil {
+		return nil, err
+	}
+
+	w.spendUTXOs(candidates)
+	w.utxos = append(w.utxos, UTXO{TxHash: txHash, OutputIndex: 0, Amount: op.Output, Pending: true})
+	w.refreshBalance()
+	w.saveCoins()
+
+	tx := Transaction{
+		Hash:      txHash,
+		From:      w.account.ZBase58(),
+		To:        w.account.ZBase58(),
+		Amount:    op.Output,
+		Token:     DenomZ,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      fmt.Sprintf("consolidated %d outputs", len(candidates)),
+		Private:   false,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, op.Fee, op.SizeBytes, candidates, nil)
+
+	op.SendUTXO = send
+	op.TxHash = txHash
+	op.Transaction = &tx
+	return op, nil
+}
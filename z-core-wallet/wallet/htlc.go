@@ -0,0 +1,215 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"shared/address"
+	"shared/htlc"
+	"shared/sighash"
+)
+
+var ErrInvalidHTLC = errors.New("invalid hash time locked contract")
+
+// NewSwapSecret draws the secret of an atomic swap and its SHA256 hash lock.
+// The initiator keeps the secret until it redeems the counterparty's leg,
+// which reveals it.
+func NewSwapSecret() ([]byte, [32]byte, error) {
+	secret := make([]byte, htlc.PreimageSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, [32]byte{}, err
+	}
+	return secret, sha256.Sum256(secret), nil
+}
+
+// InitiateHTLC locks amount in a contract paying recipient against the
+// preimage of hash, refundable to the wallet from lockTime on. lockTime is a
+// block height or Unix time; the party initiating a swap should lock for
+// longer than its counterparty does, so it cannot redeem the other leg and
+// refund its own.
+func (w *Wallet) InitiateHTLC(recipient string, hash [32]byte, amount int64, lockTime uint64, fee int64) (*HTLCOperation, error) {
+	parsed, err := address.ParseRecipient(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHTLC, err)
+	}
+	if parsed.Transparent == (address.Address{}) || parsed.Kind == address.ReceiverScriptHash {
+		return nil, fmt.Errorf("%w: the recipient needs a transparent address to redeem", ErrInvalidHTLC)
+	}
+	contract, err := htlc.New(hash, parsed.Transparent, w.Account(), lockTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHTLC, err)
+	}
+
+	// The contract is paid like any script hash, as the first output
+	payment := BatchPayment{
+		Recipient: contract.Address().ScriptHash(),
+		Amount:    amount,
+		Token:     DenomZ,
+		Memo:      fmt.Sprintf("HTLC %x", hash[:4]),
+	}
+	op, err := w.SendBatch([]BatchPayment{payment}, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTLCOperation{
+		SendUTXO:            op.SendUTXO,
+		TxHash:              op.TxHash,
+		Contract:            contract.Script(),
+		ContractAddress:     payment.Recipient,
+		HashLock:            hex.EncodeToString(hash[:]),
+		LockTime:            lockTime,
+		ContractTxHash:      op.TxHash,
+		ContractOutputIndex: 0,
+		Amount:              amount,
+		Fee:                 fee,
+		Transaction:         op.Transaction,
+	}, nil
+}
+
+// AuditHTLC checks a contract the counterparty of a swap funded before the
+// wallet locks its own leg: it must follow the template, pay the wallet
+// against hash and stay locked until at least minLockTime
+func (w *Wallet) AuditHTLC(script []byte, hash [32]byte, minLockTime uint64) (htlc.Contract, error) {
+	contract, err := htlc.Parse(script)
+	if err != nil {
+		return htlc.Contract{}, fmt.Errorf("%w: %v", ErrInvalidHTLC, err)
+	}
+
+	switch {
+	case contract.Recipient != w.Account():
+		return htlc.Contract{}, fmt.Errorf("%w: pays %s, not the wallet", ErrInvalidHTLC, contract.Recipient.ZChain())
+	case contract.Hash != hash:
+		return htlc.Contract{}, fmt.Errorf("%w: locked to hash %x", ErrInvalidHTLC, contract.Hash)
+	case (contract.LockTime < htlc.LockTimeThreshold) != (minLockTime < htlc.LockTimeThreshold):
+		return htlc.Contract{}, fmt.Errorf("%w: lock time %d and %d differ in kind", ErrInvalidHTLC, contract.LockTime, minLockTime)
+	case contract.LockTime < minLockTime:
+		return htlc.Contract{}, fmt.Errorf("%w: refundable from %d, before %d", ErrInvalidHTLC, contract.LockTime, minLockTime)
+	}
+	return contract, nil
+}
+
+// RedeemHTLC claims the contract output txHash:outputIndex of amount to the
+// wallet with preimage, revealing it to the counterparty
+func (w *Wallet) RedeemHTLC(script []byte, txHash string, outputIndex uint32, amount int64, preimage []byte, fee int64) (*HTLCOperation, error) {
+	contract, err := htlc.Parse(script)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHTLC, err)
+	}
+	if contract.Recipient != w.Account() {
+		return nil, fmt.Errorf("%w: only %s can redeem it", ErrInvalidHTLC, contract.Recipient.ZChain())
+	}
+	if err := contract.Verify(preimage); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHTLC, err)
+	}
+
+	scriptSig := func(signature, pubKey []byte) ([]byte, error) {
+		return htlc.RedeemScriptSig(contract, signature, byte(sighash.All), pubKey, preimage)
+	}
+	return w.spendHTLC(contract, txHash, outputIndex, amount, fee, 0, "redeem", scriptSig)
+}
+
+// RefundHTLC takes the contract output txHash:outputIndex of amount back to
+// the wallet after its lock time. The transaction carries the contract's lock
+// time, so the chain only accepts it once that has passed.
+func (w *Wallet) RefundHTLC(script []byte, txHash string, outputIndex uint32, amount int64, fee int64) (*HTLCOperation, error) {
+	contract, err := htlc.Parse(script)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHTLC, err)
+	}
+	if contract.Refund != w.Account() {
+		return nil, fmt.Errorf("%w: only %s can refund it", ErrInvalidHTLC, contract.Refund.ZChain())
+	}
+
+	scriptSig := func(signature, pubKey []byte) ([]byte, error) {
+		return htlc.RefundScriptSig(contract, signature, byte(sighash.All), pubKey)
+	}
+	return w.spendHTLC(contract, txHash, outputIndex, amount, fee, contract.LockTime, "refund", scriptSig)
+}
+
+// spendHTLC pays the contract output, less fee, to the wallet, signing over
+// the redeem script. The output is not one of the wallet's UTXOs until then.
+func (w *Wallet) spendHTLC(contract htlc.Contract, txHash string, outputIndex uint32, amount, fee int64, lockTime uint64, kind string, scriptSig func(signature, pubKey []byte) ([]byte, error)) (*HTLCOperation, error) {
+	if amount-fee <= 0 {
+		return nil, fmt.Errorf("%w: fee %d leaves nothing of %d", ErrInvalidHTLC, fee, amount)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+
+	creator := w.account.ZChain()
+	send := &MsgSendUTXO{
+		Creator:  creator,
+		Inputs:   []TxInput{{PrevTxHash: txHash, PrevOutputIndex: outputIndex}},
+		Outputs:  []TxOutput{{Amount: strconv.FormatInt(amount-fee, 10), Address: creator}},
+		Fee:      strconv.FormatInt(fee, 10),
+		LockTime: lockTime,
+	}
+
+	hash, err := sighash.Digest(send.sighashTx(w.chainID), 0, contract.Script(), strconv.FormatInt(amount, 10), sighash.All)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := w.signer.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	if send.Inputs[0].ScriptSig, err = scriptSig(signature, w.signer.PublicKey().SerializeCompressed()); err != nil {
+		return nil, err
+	}
+
+	spendHash := send.Hash()
+	w.utxos = append(w.utxos, UTXO{TxHash: spendHash, OutputIndex: 0, Amount: amount - fee, Pending: true})
+	w.refreshBalance()
+	w.saveCoins()
+
+	tx := Transaction{
+		Hash:      spendHash,
+		From:      contract.Address().ScriptHash(),
+		To:        creator,
+		Amount:    amount - fee,
+		Token:     DenomZ,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      fmt.Sprintf("HTLC %s of %s:%d", kind, txHash, outputIndex),
+		Private:   false,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, fee, EstimateTxSize(1, 1)+int64(len(send.Inputs[0].ScriptSig)), nil, nil)
+
+	return &HTLCOperation{
+		SendUTXO:            send,
+		TxHash:              spendHash,
+		Contract:            contract.Script(),
+		ContractAddress:     contract.Address().ScriptHash(),
+		HashLock:            hex.EncodeToString(contract.Hash[:]),
+		LockTime:            contract.LockTime,
+		ContractTxHash:      txHash,
+		ContractOutputIndex: outputIndex,
+		Amount:              amount,
+		Fee:                 fee,
+		Transaction:         tx,
+	}, nil
+}
+
+// HTLCPreimage returns the secret revealed by an included MsgSendUTXO that
+// redeems a contract locked to hash. Callers scanning blocks for the
+// counterparty's redeem use it to claim the other leg of the swap; the
+// EventHTLCSpent events of the utxo module carry the same preimage.
+func HTLCPreimage(msg *MsgSendUTXO, hash [32]byte) ([]byte, bool) {
+	for _, input := range msg.Inputs {
+		if preimage, ok := htlc.ExtractPreimage(input.ScriptSig, hash); ok {
+			return preimage, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,238 @@
+package wallet
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"z-core-wallet/storage"
+)
+
+// Kinds of PendingEvent
+const (
+	PendingConfirmed = "confirmed"
+	PendingEvicted   = "evicted"
+)
+
+var ErrNotPending = errors.New("transaction is not awaiting confirmation")
+
+// PendingTx is a transaction the wallet built that has not been seen in a
+// block. Transactions restored from history after a restart have no fee or
+// size, so their fee rate is unknown.
+type PendingTx struct {
+	Hash      string    `json:"hash"`
+	To        string    `json:"to"`
+	Amount    int64     `json:"amount"`
+	Token     string    `json:"token"`
+	Fee       int64     `json:"fee"`
+	SizeBytes int64     `json:"size_bytes"`
+	Submitted time.Time `json:"submitted"`
+
+	spent   []UTXO         // Restored when the transaction is evicted
+	notes   []ShieldedNote // Released when the transaction is evicted
+	created []string       // Commitments of notes the transaction created
+}
+
+// FeeRate is the fee per estimated byte, zero when unknown
+func (p PendingTx) FeeRate() int64 {
+	if p.SizeBytes == 0 {
+		return 0
+	}
+	return p.Fee / p.SizeBytes
+}
+
+// ExpectedBlocks estimates the blocks until inclusion: one at DefaultFeeRate
+// or above, proportionally more below it. Zero means unknown.
+func (p PendingTx) ExpectedBlocks() int64 {
+	rate := p.FeeRate()
+	if rate <= 0 {
+		return 0
+	}
+	if rate >= DefaultFeeRate {
+		return 1
+	}
+	return (DefaultFeeRate + rate - 1) / rate
+}
+
+// PendingEvent reports a pending transaction leaving the mempool view
+type PendingEvent struct {
+	Kind string    `json:"kind"`
+	Tx   PendingTx `json:"tx"`
+}
+
+// SetPendingHandler registers fn to be called, without wallet locks held, when
+// a pending transaction confirms or is evicted
+func (w *Wallet) SetPendingHandler(fn func(PendingEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onPending = fn
+}
+
+func (w *Wallet) notifyPending(events []PendingEvent) {
+	w.mu.Lock()
+	fn := w.onPending
+	w.mu.Unlock()
+
+	if fn == nil {
+		return
+	}
+	for _, event := range events {
+		fn(event)
+	}
+}
+
+// trackPending records a transaction built from tx; callers hold mu
+func (w *Wallet) trackPending(tx Transaction, fee, size int64, spent []UTXO, notes []ShieldedNote, created ...ShieldedNote) {
+	if w.pending == nil {
+		w.pending = make(map[string]*PendingTx)
+	}
+
+	p := &PendingTx{
+		Hash:      tx.Hash,
+		To:        tx.To,
+		Amount:    tx.Amount,
+		Token:     tx.Token,
+		Fee:       fee,
+		SizeBytes: size,
+		Submitted: tx.Timestamp,
+		spent:     append([]UTXO(nil), spent...),
+		notes:     append([]ShieldedNote(nil), notes...),
+	}
+	for _, note := range created {
+		p.created = append(p.created, note.Commitment)
+	}
+	w.pending[tx.Hash] = p
+}
+
+// Pending lists the transactions awaiting confirmation, oldest first
+func (w *Wallet) Pending() []PendingTx {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var result []PendingTx
+	for _, tx := range w.history {
+		if tx.Status != "pending" {
+			continue
+		}
+		if p, ok := w.pending[tx.Hash]; ok {
+			result = append(result, *p)
+			continue
+		}
+		result = append(result, PendingTx{Hash: tx.Hash, To: tx.To, Amount: tx.Amount, Token: tx.Token, Submitted: tx.Timestamp})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Submitted.Before(result[j].Submitted) })
+	return result
+}
+
+// Evict gives up on a pending transaction: it is marked failed, the coins it
+// spent become spendable again and the outputs it created are dropped
+func (w *Wallet) Evict(txHash string) error {
+	w.mu.Lock()
+	event, ok := w.evict(txHash)
+	w.mu.Unlock()
+
+	if !ok {
+		return ErrNotPending
+	}
+	w.notifyPending([]PendingEvent{event})
+	return nil
+}
+
+// ExpirePending evicts every pending transaction submitted before cutoff
+func (w *Wallet) ExpirePending(cutoff time.Time) []PendingEvent {
+	w.mu.Lock()
+	var events []PendingEvent
+	for _, tx := range w.history {
+		if tx.Status != "pending" || !tx.Timestamp.Before(cutoff) {
+			continue
+		}
+		if event, ok := w.evict(tx.Hash); ok {
+			events = append(events, event)
+		}
+	}
+	w.mu.Unlock()
+
+	w.notifyPending(events)
+	return events
+}
+
+// evict undoes a pending transaction; callers hold mu
+func (w *Wallet) evict(txHash string) (PendingEvent, bool) {
+	index := -1
+	for i := range w.history {
+		if w.history[i].Hash == txHash && w.history[i].Status == "pending" {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return PendingEvent{}, false
+	}
+
+	tx := &w.history[index]
+	p, tracked := w.pending[txHash]
+	if !tracked {
+		p = &PendingTx{Hash: tx.Hash, To: tx.To, Amount: tx.Amount, Token: tx.Token, Submitted: tx.Timestamp}
+	}
+	delete(w.pending, txHash)
+
+	remaining := w.utxos[:0]
+	for _, utxo := range w.utxos {
+		if utxo.TxHash != txHash {
+			remaining = append(remaining, utxo)
+		}
+	}
+	w.utxos = append(remaining, p.spent...)
+
+	notes := w.notes[:0]
+	for _, note := range w.notes {
+		created := false
+		for _, commitment := range p.created {
+			if note.Commitment == commitment {
+				created = true
+				break
+			}
+		}
+		if !created {
+			notes = append(notes, note)
+		}
+	}
+	w.notes = notes
+	w.markNotes(p.notes, false)
+
+	w.refreshBalance()
+	w.saveCoins()
+
+	tx.Status = "failed"
+	if err := w.store.SaveTransaction(w.account.Hex(), storage.Transaction(*tx)); err != nil {
+		log.Printf("Failed to persist transaction %s: %v", txHash, err)
+	}
+
+	return PendingEvent{Kind: PendingEvicted, Tx: *p}, true
+}
+
+// confirmPending drops a transaction seen in a block from the mempool view;
+// callers hold mu
+func (w *Wallet) confirmPending(txHash string) (PendingEvent, bool) {
+	for i := range w.history {
+		if w.history[i].Hash != txHash || w.history[i].Status != "pending" {
+			continue
+		}
+
+		w.history[i].Status = "confirmed"
+		if err := w.store.SaveTransaction(w.account.Hex(), storage.Transaction(w.history[i])); err != nil {
+			log.Printf("Failed to persist transaction %s: %v", txHash, err)
+		}
+
+		p, ok := w.pending[txHash]
+		if !ok {
+			tx := w.history[i]
+			p = &PendingTx{Hash: tx.Hash, To: tx.To, Amount: tx.Amount, Token: tx.Token, Submitted: tx.Timestamp}
+		}
+		delete(w.pending, txHash)
+		return PendingEvent{Kind: PendingConfirmed, Tx: *p}, true
+	}
+	return PendingEvent{}, false
+}
@@ -0,0 +1,128 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"strconv"
+)
+
+// Scan applies a MsgSendUTXO included in a block. Outputs paying the wallet
+// are tracked as confirmed, spent inputs are dropped and the matching history
+// entry is confirmed. Callers feed every included transaction in block order;
+// transactions not touching the wallet are ignored.
+func (w *Wallet) Scan(msg *MsgSendUTXO) {
+	w.mu.Lock()
+	event, confirmed := w.scan(msg)
+	w.mu.Unlock()
+
+	if confirmed {
+		w.notifyPending([]PendingEvent{event})
+	}
+}
+
+func (w *Wallet) scan(msg *MsgSendUTXO) (PendingEvent, bool) {
+	txHash := msg.Hash()
+	changed := false
+
+	for _, input := range msg.Inputs {
+		for _, utxo := range w.utxos {
+			if utxo.TxHash == input.PrevTxHash && utxo.OutputIndex == input.PrevOutputIndex {
+				w.spendUTXOs([]UTXO{utxo})
+				changed = true
+				break
+			}
+		}
+	}
+
+	if w.scanOutputs(txHash, msg.Outputs) {
+		changed = true
+	}
+
+	if changed {
+		w.refreshBalance()
+		w.saveCoins()
+	}
+	return w.confirmPending(txHash)
+}
+
+// ScanShielded applies a MsgSendShielded included in a block, dropping the
+// wallet's notes whose nullifiers it reveals, confirming the wallet's notes
+// among its commitments and tracking its transparent outputs paying the
+// wallet. Notes spent elsewhere with the same key are dropped too.
+func (w *Wallet) ScanShielded(msg *MsgSendShielded) {
+	w.mu.Lock()
+	event, confirmed := w.scanShielded(msg)
+	w.mu.Unlock()
+
+	if confirmed {
+		w.notifyPending([]PendingEvent{event})
+	}
+}
+
+func (w *Wallet) scanShielded(msg *MsgSendShielded) (PendingEvent, bool) {
+	txHash := msg.Hash()
+	changed := false
+	revealed := make(map[string]bool, len(msg.Nullifiers))
+	for _, nullifier := range msg.Nullifiers {
+		revealed[hex.EncodeToString(nullifier)] = true
+	}
+	remaining := w.notes[:0]
+	for _, note := range w.notes {
+		if revealed[note.Nullifier] {
+			changed = true
+			continue
+		}
+		remaining = append(remaining, note)
+	}
+	w.notes = remaining
+
+	// Unshielded value pays transparent outputs of the shielded transaction
+	if w.scanOutputs(txHash, msg.TransparentOutputs) {
+		changed = true
+	}
+
+	for _, commitment := range msg.Commitments {
+		encoded := hex.EncodeToString(commitment)
+		for i := range w.notes {
+			if w.notes[i].Commitment == encoded && w.notes[i].Pending {
+				w.notes[i].Pending = false
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		w.refreshBalance()
+		w.saveCoins()
+	}
+	return w.confirmPending(txHash)
+}
+
+// scanOutputs tracks the outputs of txHash paying the wallet as confirmed and
+// reports whether any did; callers hold mu
+func (w *Wallet) scanOutputs(txHash string, outputs []TxOutput) bool {
+	owner := w.account.ZChain()
+	changed := false
+	for i, output := range outputs {
+		if output.Address != owner {
+			continue
+		}
+		amount, err := strconv.ParseInt(output.Amount, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		tracked := false
+		for j := range w.utxos {
+			if w.utxos[j].TxHash == txHash && w.utxos[j].OutputIndex == uint32(i) {
+				w.utxos[j].Pending = false
+				tracked = true
+				break
+			}
+		}
+		if !tracked {
+			w.utxos = append(w.utxos, UTXO{TxHash: txHash, OutputIndex: uint32(i), Amount: amount})
+		}
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,537 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"shared/address"
+	"shared/sighash"
+)
+
+// DefaultShieldingFee is the fee in base units used when a request does not set one
+const DefaultShieldingFee int64 = 10000
+
+var (
+	ErrInsufficientTransparent = errors.New("insufficient transparent funds")
+	ErrInsufficientShielded    = errors.New("insufficient shielded funds")
+)
+
+// ShieldedPoolAddress is the transparent zChain address value enters the
+// shielded pool through. Shielding pays into it; unshielding releases value
+// through the transparent outputs of the shielded message.
+var ShieldedPoolAddress = func() string {
+	hash := sha256.Sum256([]byte("utxo/shielded_pool"))
+	addr, _ := address.FromBytes(hash[:address.Length])
+	return addr.ZChain()
+}()
+
+// CreateShieldedTransfer creates a private transaction spending the wallet's
+// notes to a new note of recipient. Excess value returns as a change note.
+func (w *Wallet) CreateShieldedTransfer(recipient string, amount int64, memo string) (*ShieldedTransfer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid amount %d", amount)
+	}
+
+	notes, total, err := w.selectNotes(amount)
+	if err != nil {
+		return nil, err
+	}
+	nullifiers, err := w.nullifiers(notes)
+	if err != nil {
+		return nil, err
+	}
+
+	rseed := make([]byte, 32)
+	if _, err := rand.Read(rseed); err != nil {
+		return nil, err
+	}
+	output := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%x", recipient, amount, rseed)))
+
+	// Create zk-SNARK proof (hypothetical implementation)
+	zkProof, err := w.generateZkProof(recipient, amount, memo, hex.EncodeToString(nullifiers[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt memo (simplified - use proper encryption in production)
+	shielded := &MsgSendShielded{
+		Creator:       w.account.ZChain(),
+		Nullifiers:    nullifiers,
+		Commitments:   [][]byte{output[:]},
+		ZkProof:       zkProof,
+		EncryptedMemo: encryptMemo(memo, recipient),
+		Fee:           "0",
+		ValueBalance:  "0",
+	}
+
+	var created []ShieldedNote
+	if change := total - amount; change > 0 {
+		changeNote, err := w.newNote(change)
+		if err != nil {
+			return nil, err
+		}
+		commitment, _ := hex.DecodeString(changeNote.Commitment)
+		shielded.Commitments = append(shielded.Commitments, commitment)
+		created = append(created, changeNote)
+	}
+	txHash := shielded.Hash()
+
+	w.spendNotes(notes)
+	w.notes = append(w.notes, created...)
+	w.refreshBalance()
+	w.saveCoins()
+
+	tx := Transaction{
+		Hash:      txHash,
+		From:      w.account.ZBase58(),
+		To:        recipient,
+		Amount:    amount,
+		Token:     DenomZ,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      memo,
+		Private:   true,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, 0, estimateShieldedSize(shielded), nil, notes, created...)
+
+	transfer := &ShieldedTransfer{
+		Sender:       "", // Hidden
+		Recipient:    "", // Hidden
+		Amount:       0,  // Hidden
+		Memo:         shielded.EncryptedMemo,
+		ZkProof:      zkProof,
+		Nullifier:    hex.EncodeToString(nullifiers[0]),
+		SendShielded: shielded,
+		TxHash:       txHash,
+	}
+	for _, nullifier := range nullifiers {
+		transfer.Nullifiers = append(transfer.Nullifiers, hex.EncodeToString(nullifier))
+	}
+	return transfer, nil
+}
+
+// nullifiers derives the nullifiers spending notes; callers hold mu. Notes
+// stored by older versions may carry a nullifier of another derivation that
+// was never published, so the stored value is replaced.
+func (w *Wallet) nullifiers(notes []ShieldedNote) ([][]byte, error) {
+	keys, err := w.shieldedKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	nullifiers := make([][]byte, 0, len(notes))
+	for i, note := range notes {
+		commitment, err := hex.DecodeString(note.Commitment)
+		if err != nil {
+			return nil, fmt.Errorf("note %s: invalid commitment: %w", note.Commitment, err)
+		}
+		nullifier := keys.nullifier(commitment)
+		notes[i].Nullifier = hex.EncodeToString(nullifier)
+		for j := range w.notes {
+			if w.notes[j].Commitment == note.Commitment {
+				w.notes[j].Nullifier = notes[i].Nullifier
+			}
+		}
+		nullifiers = append(nullifiers, nullifier)
+	}
+	return nullifiers, nil
+}
+
+// generateZkProof creates a zk-SNARK proof for the transaction; callers hold mu
+func (w *Wallet) generateZkProof(recipient string, amount int64, memo string, nullifier string) ([]byte, error) {
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+
+	// In a real implementation, this would use a zk-SNARK library
+	// For now, create a mock proof
+	data := fmt.Sprintf("%s:%s:%d:%s:%s",
+		w.account.ZBase58(), recipient, amount, memo, nullifier)
+
+	// Sign with private key
+	hash := sha256.Sum256([]byte(data))
+	return w.signer.Sign(hash[:])
+}
+
+// encryptMemo encrypts the memo field
+func encryptMemo(memo, recipient string) []byte {
+	// Simplified encryption - use proper encryption in production
+	data := []byte(memo)
+	key := sha256.Sum256([]byte(recipient))
+
+	for i := range data {
+		data[i] ^= key[i%32]
+	}
+
+	// Pad to 512 bytes
+	if len(data) < 512 {
+		padding := make([]byte, 512-len(data))
+		data = append(data, padding...)
+	}
+
+	return data[:512]
+}
+
+// newNote creates a note of the wallet's own with a fresh commitment and the
+// nullifier that will later spend it
+func (w *Wallet) newNote(amount int64) (ShieldedNote, error) {
+	rseed := make([]byte, 32)
+	if _, err := rand.Read(rseed); err != nil {
+		return ShieldedNote{}, err
+	}
+
+	keys, err := w.shieldedKeys()
+	if err != nil {
+		return ShieldedNote{}, err
+	}
+	owner := keys.receiver(0)
+
+	commitment := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%x", owner.String(), amount, rseed)))
+	nullifier := keys.nullifier(commitment[:])
+
+	return ShieldedNote{
+		Commitment: hex.EncodeToString(commitment[:]),
+		Nullifier:  hex.EncodeToString(nullifier[:]),
+		Amount:     amount,
+		Pending:    true,
+	}, nil
+}
+
+// signInputs signs every input of send the way the utxo module verifies outputs
+// locked to the account: over the sighash of the input, which commits to the
+// chain ID, all outputs and the amount of the UTXO spent. utxos are the
+// outputs the inputs spend, in order.
+func (w *Wallet) signInputs(send *MsgSendUTXO, utxos []UTXO) error {
+	if w.signer == nil {
+		return ErrLocked
+	}
+
+	tx := send.sighashTx(w.chainID)
+	subscript := sighash.AddressScript(w.account)
+	pubKey := w.signer.PublicKey().SerializeCompressed()
+	for i := range send.Inputs {
+		hash, err := sighash.Digest(tx, i, subscript, strconv.FormatInt(utxos[i].Amount, 10), sighash.All)
+		if err != nil {
+			return err
+		}
+		signature, err := w.signer.Sign(hash)
+		if err != nil {
+			return err
+		}
+		if send.Inputs[i].ScriptSig, err = sighash.ScriptSig(signature, sighash.All, pubKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectUTXOs picks confirmed transparent outputs, largest first, covering target
+func (w *Wallet) selectUTXOs(target int64) ([]UTXO, int64, error) {
+	candidates := make([]UTXO, 0, len(w.utxos))
+	for _, utxo := range w.utxos {
+		if !utxo.Pending && !utxo.Frozen {
+			candidates = append(candidates, utxo)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Amount > candidates[j].Amount })
+
+	var selected []UTXO
+	var total int64
+	for _, utxo := range candidates {
+		if total >= target {
+			break
+		}
+		selected = append(selected, utxo)
+		total += utxo.Amount
+	}
+
+	if total < target {
+		return nil, 0, fmt.Errorf("%w: need %d, have %d", ErrInsufficientTransparent, target, total)
+	}
+	return selected, total, nil
+}
+
+// selectNotes picks confirmed unspent shielded notes, largest first, covering target
+func (w *Wallet) selectNotes(target int64) ([]ShieldedNote, int64, error) {
+	candidates := make([]ShieldedNote, 0, len(w.notes))
+	for _, note := range w.notes {
+		if !note.Pending && !note.Spent {
+			candidates = append(candidates, note)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Amount > candidates[j].Amount })
+
+	var selected []ShieldedNote
+	var total int64
+	for _, note := range candidates {
+		if total >= target {
+			break
+		}
+		selected = append(selected, note)
+		total += note.Amount
+	}
+
+	if total < target {
+		return nil, 0, fmt.Errorf("%w: need %d, have %d", ErrInsufficientShielded, target, total)
+	}
+	return selected, total, nil
+}
+
+// refreshBalance recomputes Z from the tracked outputs and notes and merges
+// in the mirrored balances of account based denoms
+func (w *Wallet) refreshBalance() {
+	var transparent, shielded int64
+	for _, utxo := range w.utxos {
+		transparent += utxo.Amount
+	}
+	for _, note := range w.notes {
+		if !note.Spent {
+			shielded += note.Amount
+		}
+	}
+
+	amounts := map[string]int64{DenomZ: transparent}
+	for denom, amount := range w.tokenBalances {
+		amounts[denom] = amount
+	}
+
+	w.balance = Balance{
+		Z:         transparent,
+		ShieldedZ: shielded,
+		NU:        amounts[DenomNU],
+		Amounts:   amounts,
+		Shielded:  map[string]int64{DenomZ: shielded},
+	}
+}
+
+// Shield moves transparent Z of the wallet into a new shielded note. The
+// selected UTXOs pay amount into the shielded pool and fee to the chain, any
+// excess returns to the wallet as a change output.
+func (w *Wallet) Shield(amount, fee int64, memo string) (*ShieldOperation, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+
+	inputs, total, err := w.selectUTXOs(amount + fee)
+	if err != nil {
+		return nil, err
+	}
+	change := total - amount - fee
+
+	note, err := w.newNote(amount)
+	if err != nil {
+		return nil, err
+	}
+	commitment, _ := hex.DecodeString(note.Commitment)
+
+	// Shielding spends no note; a random nullifier satisfies the pool's input rule
+	dummy := make([]byte, 32)
+	if _, err := rand.Read(dummy); err != nil {
+		return nil, err
+	}
+
+	creator := w.account.ZChain()
+	proof, err := w.generateZkProof(creator, amount, memo, note.Commitment)
+	if err != nil {
+		return nil, err
+	}
+
+	shielded := &MsgSendShielded{
+		Creator:       creator,
+		Nullifiers:    [][]byte{dummy},
+		Commitments:   [][]byte{commitment},
+		ZkProof:       proof,
+		EncryptedMemo: encryptMemo(memo, creator),
+		Fee:           "0",
+		ValueBalance:  strconv.FormatInt(-amount, 10),
+	}
+
+	send := &MsgSendUTXO{
+		Creator: creator,
+		Outputs: []TxOutput{{Amount: strconv.FormatInt(amount, 10), Address: ShieldedPoolAddress}},
+		Fee:     strconv.FormatInt(fee, 10),
+		ZkProof: proof,
+	}
+	if change > 0 {
+		send.Outputs = append(send.Outputs, TxOutput{Amount: strconv.FormatInt(change, 10), Address: creator})
+	}
+	for _, utxo := range inputs {
+		send.Inputs = append(send.Inputs, TxInput{PrevTxHash: utxo.TxHash, PrevOutputIndex: utxo.OutputIndex})
+	}
+
+	utxoHash := send.Hash()
+	if err := w.signInputs(send, inputs); err != nil {
+		return nil, err
+	}
+
+	w.spendUTXOs(inputs)
+	if change > 0 {
+		w.utxos = append(w.utxos, UTXO{TxHash: utxoHash, OutputIndex: 1, Amount: change, Pending: true})
+	}
+	w.notes = append(w.notes, note)
+	w.refreshBalance()
+	w.saveCoins()
+
+	tx := Transaction{
+		Hash:      utxoHash,
+		From:      w.account.ZBase58(),
+		To:        w.account.ZBase58(),
+		Amount:    amount,
+		Token:     DenomZ,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      memo,
+		Private:   true,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, fee, EstimateTxSize(len(send.Inputs), len(send.Outputs))+estimateShieldedSize(shielded), inputs, nil, note)
+
+	return &ShieldOperation{
+		SendUTXO:     send,
+		SendShielded: shielded,
+		UTXOTxHash:   utxoHash,
+		ShieldedHash: shielded.Hash(),
+		Fee:          fee,
+		Change:       change,
+		Transaction:  tx,
+	}, nil
+}
+
+// Unshield spends shielded notes to a transparent address, the wallet's own by
+// default. Excess note value returns to the wallet as a new shielded change note,
+// so the change never becomes visible on the transparent side.
+func (w *Wallet) Unshield(amount, fee int64, recipient, memo string) (*ShieldOperation, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+
+	creator := w.account.ZChain()
+	if recipient == "" {
+		recipient = creator
+	}
+
+	notes, total, err := w.selectNotes(amount + fee)
+	if err != nil {
+		return nil, err
+	}
+	change := total - amount - fee
+
+	// The value leaving the pool pays the recipient and the fee
+	shielded := &MsgSendShielded{
+		Creator:            creator,
+		EncryptedMemo:      encryptMemo(memo, recipient),
+		Fee:                strconv.FormatInt(fee, 10),
+		ValueBalance:       strconv.FormatInt(amount+fee, 10),
+		TransparentOutputs: []TxOutput{{Amount: strconv.FormatInt(amount, 10), Address: recipient}},
+	}
+	shielded.Nullifiers, err = w.nullifiers(notes)
+	if err != nil {
+		return nil, err
+	}
+
+	var changeNote ShieldedNote
+	if change > 0 {
+		changeNote, err = w.newNote(change)
+		if err != nil {
+			return nil, err
+		}
+		commitment, _ := hex.DecodeString(changeNote.Commitment)
+		shielded.Commitments = append(shielded.Commitments, commitment)
+	}
+
+	proof, err := w.generateZkProof(recipient, amount, memo, notes[0].Nullifier)
+	if err != nil {
+		return nil, err
+	}
+	shielded.ZkProof = proof
+	shieldedHash := shielded.Hash()
+
+	w.spendNotes(notes)
+	if change > 0 {
+		w.notes = append(w.notes, changeNote)
+	}
+	if recipient == creator {
+		w.utxos = append(w.utxos, UTXO{TxHash: shieldedHash, OutputIndex: 0, Amount: amount, Pending: true})
+	}
+	w.refreshBalance()
+	w.saveCoins()
+
+	tx := Transaction{
+		Hash:      shieldedHash,
+		From:      w.account.ZBase58(),
+		To:        recipient,
+		Amount:    amount,
+		Token:     DenomZ,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      memo,
+		Private:   false,
+	}
+	var created []ShieldedNote
+	if change > 0 {
+		created = append(created, changeNote)
+	}
+	w.trackPending(tx, fee, estimateShieldedSize(shielded), nil, notes, created...)
+
+	return &ShieldOperation{
+		SendShielded: shielded,
+		UTXOTxHash:   shieldedHash,
+		ShieldedHash: shieldedHash,
+		Fee:          fee,
+		Change:       change,
+		Transaction:  tx,
+	}, nil
+}
+
+func (w *Wallet) spendUTXOs(spent []UTXO) {
+	remaining := w.utxos[:0]
+	for _, utxo := range w.utxos {
+		keep := true
+		for _, s := range spent {
+			if utxo.TxHash == s.TxHash && utxo.OutputIndex == s.OutputIndex {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			remaining = append(remaining, utxo)
+		}
+	}
+	w.utxos = remaining
+}
+
+// spendNotes marks notes as spent by a pending transaction. They are dropped
+// once their nullifiers are seen on chain and released if it is evicted.
+func (w *Wallet) spendNotes(spent []ShieldedNote) {
+	w.markNotes(spent, true)
+}
+
+func (w *Wallet) markNotes(notes []ShieldedNote, spent bool) {
+	for i := range w.notes {
+		for _, s := range notes {
+			if w.notes[i].Commitment == s.Commitment {
+				w.notes[i].Spent = spent
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrRemoteSigner = errors.New("key is held by a remote signer")
+
+// Signer holds the key controlling the account. The wallet never needs the
+// key itself, so it can live in another process.
+type Signer interface {
+	// PublicKey returns the controlling public key
+	PublicKey() *btcec.PublicKey
+	// Sign returns the 65 byte recoverable signature of a 32 byte hash
+	Sign(hash []byte) ([]byte, error)
+	// Derive returns sha256(prefix || private key || suffix), from which the
+	// shielded keys and nullifiers are derived
+	Derive(prefix, suffix []byte) ([32]byte, error)
+}
+
+// KeySigner signs with a private key held in process
+type KeySigner struct {
+	key *btcec.PrivateKey
+}
+
+// NewKeySigner creates a signer for key
+func NewKeySigner(key *btcec.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+func (s *KeySigner) PublicKey() *btcec.PublicKey {
+	return s.key.PubKey()
+}
+
+func (s *KeySigner) Sign(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key.ToECDSA())
+}
+
+func (s *KeySigner) Derive(prefix, suffix []byte) ([32]byte, error) {
+	data := append(append(append([]byte{}, prefix...), s.key.Serialize()...), suffix...)
+	return sha256.Sum256(data), nil
+}
+
+// RemoteSigner reports whether the key is held outside this process
+func (w *Wallet) RemoteSigner() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, local := w.signer.(*KeySigner)
+	return w.signer != nil && !local
+}
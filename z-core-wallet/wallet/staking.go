@@ -0,0 +1,122 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"shared/address"
+)
+
+// Staking parameters of the nuChain mining module
+const (
+	// StakingNodeStake is escrowed by MsgCreateStakingNode, in base units of
+	// NU. It does not fit an int64, so it is kept as a decimal string.
+	StakingNodeStake = "21000000000000000000"
+	// StakingWattPerBlock is paid to an online node per supported chain and
+	// block, in base units of WATT, over LayerZero to that chain
+	StakingWattPerBlock int64 = 1000000000000000
+
+	// MsgCreateStakingNodeType is the type URL of the message on nuChain
+	MsgCreateStakingNodeType = "/nuchain.mining.v1.MsgCreateStakingNode"
+)
+
+var ErrInvalidStakingNode = errors.New("invalid staking node")
+
+// MsgCreateStakingNode registers a staking node on nuChain, escrowing
+// StakingNodeStake from the creator
+type MsgCreateStakingNode struct {
+	Type             string   `json:"@type"`
+	Creator          string   `json:"creator"`
+	Moniker          string   `json:"moniker"`
+	SupportedChains  []string `json:"supported_chains"`
+	ConsensusAddress string   `json:"consensus_address"`
+}
+
+// Hash identifies the message until the chain assigns a transaction hash
+func (m *MsgCreateStakingNode) Hash() string {
+	bz, _ := json.Marshal(m)
+	hash := sha256.Sum256(bz)
+	return hex.EncodeToString(hash[:])
+}
+
+// StakingNodeRequest describes the node to register
+type StakingNodeRequest struct {
+	Moniker          string   `json:"moniker"`
+	SupportedChains  []string `json:"supported_chains"` // Chains the node's WATT rewards are paid on
+	ConsensusAddress string   `json:"consensus_address"`
+}
+
+// StakingOperation is a staking node registration built by the wallet. nuChain
+// transactions are signed against the account number and sequence of the
+// creator, so the caller wraps Msg in a transaction, signs and broadcasts it.
+type StakingOperation struct {
+	Msg         *MsgCreateStakingNode `json:"msg"`
+	Stake       string                `json:"stake"`
+	StakeDenom  string                `json:"stake_denom"`
+	Transaction Transaction           `json:"transaction"`
+}
+
+// CreateStakingNode builds the MsgCreateStakingNode registering a staking node
+// operated by the wallet's nuChain account, and records it as pending
+func (w *Wallet) CreateStakingNode(req StakingNodeRequest) (*StakingOperation, error) {
+	moniker := strings.TrimSpace(req.Moniker)
+	if moniker == "" {
+		return nil, fmt.Errorf("%w: moniker cannot be empty", ErrInvalidStakingNode)
+	}
+
+	seen := make(map[string]bool)
+	var chains []string
+	for _, chain := range req.SupportedChains {
+		chain = strings.TrimSpace(chain)
+		if chain != "" && !seen[chain] {
+			seen[chain] = true
+			chains = append(chains, chain)
+		}
+	}
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("%w: supported chains cannot be empty", ErrInvalidStakingNode)
+	}
+	sort.Strings(chains)
+
+	if _, err := address.ParseBech32(req.ConsensusAddress, address.NuChainValconsHRP); err != nil {
+		return nil, fmt.Errorf("%w: consensus address: %v", ErrInvalidStakingNode, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return nil, ErrLocked
+	}
+
+	msg := &MsgCreateStakingNode{
+		Type:             MsgCreateStakingNodeType,
+		Creator:          w.account.NuChain(),
+		Moniker:          moniker,
+		SupportedChains:  chains,
+		ConsensusAddress: req.ConsensusAddress,
+	}
+
+	// The stake leaves the account but is escrowed, not paid to anyone
+	tx := Transaction{
+		Hash:      msg.Hash(),
+		From:      w.account.NuChain(),
+		To:        "mining",
+		Amount:    0,
+		Token:     DenomNU,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      fmt.Sprintf("staking node %s, 21 NU escrowed", moniker),
+		Private:   false,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, 0, 0, nil, nil)
+
+	return &StakingOperation{Msg: msg, Stake: StakingNodeStake, StakeDenom: DenomNU, Transaction: tx}, nil
+}
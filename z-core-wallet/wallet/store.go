@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"z-core-wallet/storage"
+)
+
+// load restores the account's coins and history from the store and records
+// the account, so a reopened wallet picks up where it left off
+func (w *Wallet) load() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	account := w.account.Hex()
+
+	utxos, err := w.store.UTXOs(account)
+	if err != nil {
+		return err
+	}
+	notes, err := w.store.Notes(account)
+	if err != nil {
+		return err
+	}
+	txs, err := w.store.Transactions(account)
+	if err != nil {
+		return err
+	}
+
+	for _, utxo := range utxos {
+		w.utxos = append(w.utxos, UTXO(utxo))
+	}
+	for _, note := range notes {
+		w.notes = append(w.notes, ShieldedNote(note))
+	}
+	for _, tx := range txs {
+		w.history = append(w.history, Transaction(tx))
+	}
+	w.refreshBalance()
+
+	return w.saveAccount()
+}
+
+// saveAccount records the account, keeping its creation time; callers hold mu
+func (w *Wallet) saveAccount() error {
+	record, err := w.store.GetAccount(w.account.Hex())
+	if errors.Is(err, storage.ErrNotFound) {
+		record = storage.Account{Address: w.account.Hex(), CreatedAt: time.Now()}
+	} else if err != nil {
+		return err
+	}
+
+	record.ZAddress = w.account.ZBase58()
+	if w.publicKey != nil {
+		record.PublicKey = hex.EncodeToString(w.publicKey.SerializeCompressed())
+	}
+	return w.store.SaveAccount(record)
+}
+
+// recordTransaction appends tx to the history and persists it; callers hold mu
+func (w *Wallet) recordTransaction(tx Transaction) {
+	w.history = append(w.history, tx)
+	if err := w.store.SaveTransaction(w.account.Hex(), storage.Transaction(tx)); err != nil {
+		log.Printf("Failed to persist transaction %s: %v", tx.Hash, err)
+	}
+}
+
+// saveCoins persists the wallet's UTXOs and notes; callers hold mu
+func (w *Wallet) saveCoins() {
+	account := w.account.Hex()
+
+	utxos := make([]storage.UTXO, 0, len(w.utxos))
+	for _, utxo := range w.utxos {
+		utxos = append(utxos, storage.UTXO(utxo))
+	}
+	if err := w.store.ReplaceUTXOs(account, utxos); err != nil {
+		log.Printf("Failed to persist UTXOs: %v", err)
+	}
+
+	notes := make([]storage.Note, 0, len(w.notes))
+	for _, note := range w.notes {
+		notes = append(notes, storage.Note(note))
+	}
+	if err := w.store.ReplaceNotes(account, notes); err != nil {
+		log.Printf("Failed to persist notes: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Denominations known to every wallet
+const (
+	DenomZ    = "Z"    // Native coin of the Z chain, held as UTXOs and notes
+	DenomNU   = "NU"   // Native coin of nuChain
+	DenomWATT = "WATT" // Mining stake and reward token on nuChain
+)
+
+var ErrUnknownToken = errors.New("unknown token")
+
+// TokenInfo describes a denomination. Amounts are always kept in base units;
+// Decimals tells clients where to put the decimal point.
+type TokenInfo struct {
+	Denom    string `json:"denom"`
+	Name     string `json:"name"`
+	Decimals int    `json:"decimals"`
+	Chain    string `json:"chain"` // zchain, nuchain, or the origin of a bridged token
+}
+
+var (
+	tokensMu sync.RWMutex
+	tokens   = map[string]TokenInfo{
+		DenomZ:    {Denom: DenomZ, Name: "Z", Decimals: 18, Chain: "zchain"},
+		DenomNU:   {Denom: DenomNU, Name: "nuChain", Decimals: 18, Chain: "nuchain"},
+		DenomWATT: {Denom: DenomWATT, Name: "WATT", Decimals: 18, Chain: "nuchain"},
+	}
+)
+
+// RegisterToken makes a denomination, such as a bridged token, known to the
+// wallet. Built in denominations cannot be redefined.
+func RegisterToken(info TokenInfo) error {
+	info.Denom = strings.ToUpper(strings.TrimSpace(info.Denom))
+	if info.Denom == "" {
+		return fmt.Errorf("token denom cannot be empty")
+	}
+	if info.Decimals < 0 || info.Decimals > 36 {
+		return fmt.Errorf("token %s: invalid decimals %d", info.Denom, info.Decimals)
+	}
+	if info.Name == "" {
+		info.Name = info.Denom
+	}
+
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+
+	switch info.Denom {
+	case DenomZ, DenomNU, DenomWATT:
+		return fmt.Errorf("token %s is built in", info.Denom)
+	}
+	tokens[info.Denom] = info
+	return nil
+}
+
+// LookupToken returns the metadata of a denomination, ignoring case
+func LookupToken(denom string) (TokenInfo, bool) {
+	tokensMu.RLock()
+	defer tokensMu.RUnlock()
+	info, ok := tokens[strings.ToUpper(denom)]
+	return info, ok
+}
+
+// Tokens lists the known denominations, Z first and the rest by denom
+func Tokens() []TokenInfo {
+	tokensMu.RLock()
+	defer tokensMu.RUnlock()
+
+	list := make([]TokenInfo, 0, len(tokens))
+	for _, info := range tokens {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Denom == DenomZ) != (list[j].Denom == DenomZ) {
+			return list[i].Denom == DenomZ
+		}
+		return list[i].Denom < list[j].Denom
+	})
+	return list
+}
+
+// NormalizeDenom returns the canonical form of a known denomination; an
+// empty token means Z
+func NormalizeDenom(token string) (string, error) {
+	if token == "" {
+		return DenomZ, nil
+	}
+	info, ok := LookupToken(token)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownToken, token)
+	}
+	return info.Denom, nil
+}
+
+// SetTokenBalance records the balance of an account based denomination such
+// as NU or WATT. The wallet does not query chains itself; the caller mirrors
+// the chain's bank balance here whenever it changes. Z is derived from the
+// wallet's coins and cannot be set.
+func (w *Wallet) SetTokenBalance(denom string, amount int64) error {
+	denom, err := NormalizeDenom(denom)
+	if err != nil {
+		return err
+	}
+	if denom == DenomZ {
+		return fmt.Errorf("the Z balance is derived from the wallet's coins")
+	}
+	if amount < 0 {
+		return fmt.Errorf("invalid amount %d", amount)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.tokenBalances == nil {
+		w.tokenBalances = make(map[string]int64)
+	}
+	w.tokenBalances[denom] = amount
+	w.refreshBalance()
+	return nil
+}
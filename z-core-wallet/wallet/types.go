@@ -0,0 +1,226 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"shared/sighash"
+)
+
+// Balance represents wallet balances. Amounts and Shielded hold every
+// denomination in base units; Z, ShieldedZ and NU repeat their entries for
+// clients written before other tokens were tracked.
+type Balance struct {
+	Z         int64            `json:"z"`
+	ShieldedZ int64            `json:"shielded_z"`
+	NU        int64            `json:"nu"`
+	Amounts   map[string]int64 `json:"amounts"`  // Transparent, by denom
+	Shielded  map[string]int64 `json:"shielded"` // By denom; only Z has a shielded pool
+}
+
+// Denoms lists the denominations with a balance, Z first
+func (b Balance) Denoms() []string {
+	denoms := []string{DenomZ}
+	for _, info := range Tokens() {
+		if info.Denom == DenomZ {
+			continue
+		}
+		if b.Amounts[info.Denom] != 0 || b.Shielded[info.Denom] != 0 {
+			denoms = append(denoms, info.Denom)
+		}
+	}
+	return denoms
+}
+
+// Transaction represents a transaction record
+type Transaction struct {
+	Hash      string    `json:"hash"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    int64     `json:"amount"`
+	Token     string    `json:"token"`
+	Timestamp time.Time `json:"timestamp"`
+	Status    string    `json:"status"`
+	Memo      string    `json:"memo"`
+	Private   bool      `json:"private"`
+}
+
+// UTXO is a transparent output owned by the wallet
+type UTXO struct {
+	TxHash      string `json:"tx_hash"`
+	OutputIndex uint32 `json:"output_index"`
+	Amount      int64  `json:"amount"`
+	Pending     bool   `json:"pending"`
+	Frozen      bool   `json:"frozen"` // Never picked by coin selection
+}
+
+// ShieldedNote is a note the wallet can spend from the shielded pool
+type ShieldedNote struct {
+	Commitment string `json:"commitment"`
+	Nullifier  string `json:"nullifier"`
+	Amount     int64  `json:"amount"`
+	Pending    bool   `json:"pending"`
+	Spent      bool   `json:"spent"` // By a transaction not yet seen in a block
+}
+
+// ShieldedTransfer represents a Zcash-style private transaction
+type ShieldedTransfer struct {
+	Sender    string `json:"sender"`    // Hidden via zk-SNARK
+	Recipient string `json:"recipient"` // Hidden
+	Amount    int64  `json:"amount"`    // Hidden
+	Memo      []byte `json:"memo"`      // Encrypted, 512 bytes max
+	ZkProof   []byte `json:"zk_proof"`  // zk-SNARK proof
+	Nullifier string `json:"nullifier"` // First of Nullifiers, kept for older clients
+
+	Nullifiers   []string         `json:"nullifiers"` // Of the notes spent
+	SendShielded *MsgSendShielded `json:"send_shielded"`
+	TxHash       string           `json:"tx_hash"`
+}
+
+// TxInput mirrors zblockchain.utxo.v1.TxInput
+type TxInput struct {
+	PrevTxHash      string `json:"prev_tx_hash"`
+	PrevOutputIndex uint32 `json:"prev_output_index"`
+	ScriptSig       []byte `json:"script_sig,omitempty"`
+	Witness         []byte `json:"witness,omitempty"`
+	Sequence        uint32 `json:"sequence,omitempty"`
+}
+
+// TxOutput mirrors zblockchain.utxo.v1.TxOutput
+type TxOutput struct {
+	Amount       string `json:"amount"`
+	ScriptPubkey []byte `json:"script_pubkey,omitempty"`
+	Address      string `json:"address"`
+}
+
+// MsgSendUTXO mirrors zblockchain.utxo.v1.MsgSendUTXO
+type MsgSendUTXO struct {
+	Creator  string     `json:"creator"`
+	Inputs   []TxInput  `json:"inputs"`
+	Outputs  []TxOutput `json:"outputs"`
+	Fee      string     `json:"fee"`
+	LockTime uint64     `json:"lock_time"`
+	ZkProof  []byte     `json:"zk_proof,omitempty"`
+}
+
+// MsgSendShielded mirrors zblockchain.utxo.v1.MsgSendShielded
+type MsgSendShielded struct {
+	Creator       string   `json:"creator"`
+	Nullifiers    [][]byte `json:"nullifiers"`
+	Commitments   [][]byte `json:"commitments"`
+	ZkProof       []byte   `json:"zk_proof"`
+	EncryptedMemo []byte   `json:"encrypted_memo"`
+	Fee           string   `json:"fee"`
+
+	// Value leaving the shielded pool, the fee included; negative when shielding
+	ValueBalance       string     `json:"value_balance"`
+	TransparentOutputs []TxOutput `json:"transparent_outputs,omitempty"`
+}
+
+// ShieldOperation moves value between the transparent and shielded pools.
+// Shielding pays the pool address with SendUTXO and shields the value with
+// SendShielded; both go into one zChain transaction so neither side can land
+// without the other. Unshielding is SendShielded alone, paying its transparent
+// outputs, and SendUTXO is nil.
+type ShieldOperation struct {
+	SendUTXO     *MsgSendUTXO     `json:"send_utxo"`
+	SendShielded *MsgSendShielded `json:"send_shielded"`
+	UTXOTxHash   string           `json:"utxo_tx_hash"`
+	ShieldedHash string           `json:"shielded_tx_hash"`
+	Fee          int64            `json:"fee"`
+	Change       int64            `json:"change"`
+	Transaction  Transaction      `json:"transaction"`
+}
+
+// BatchPayment is one recipient of a batch transaction. Memos stay in the
+// wallet; transparent outputs carry none on chain.
+type BatchPayment struct {
+	Recipient string `json:"recipient"`
+	Amount    int64  `json:"amount"`
+	Token     string `json:"token"`
+	Memo      string `json:"memo,omitempty"`
+}
+
+// BatchOperation is a signed MsgSendUTXO paying every recipient at once, so
+// the batch pays a single fee instead of one per payment
+type BatchOperation struct {
+	SendUTXO    *MsgSendUTXO   `json:"send_utxo"`
+	TxHash      string         `json:"tx_hash"`
+	Payments    []BatchPayment `json:"payments"`
+	Total       int64          `json:"total"`
+	Fee         int64          `json:"fee"`
+	Change      int64          `json:"change"`
+	Transaction Transaction    `json:"transaction"`
+}
+
+// HTLCOperation is a signed MsgSendUTXO funding, redeeming or refunding a hash
+// time locked contract. Contract is the redeem script; both parties keep it,
+// as the chain only learns it when the contract is spent.
+type HTLCOperation struct {
+	SendUTXO        *MsgSendUTXO `json:"send_utxo"`
+	TxHash          string       `json:"tx_hash"`
+	Contract        []byte       `json:"contract"`
+	ContractAddress string       `json:"contract_address"` // zsh1...
+	HashLock        string       `json:"hash_lock"`
+	LockTime        uint64       `json:"lock_time"`
+	// Outpoint of the contract output: created by a funding transaction,
+	// spent by a redeem or refund
+	ContractTxHash      string      `json:"contract_tx_hash"`
+	ContractOutputIndex uint32      `json:"contract_output_index"`
+	Amount              int64       `json:"amount"`
+	Fee                 int64       `json:"fee"`
+	Transaction         Transaction `json:"transaction"`
+}
+
+// Hash matches the transaction hash the utxo module derives for MsgSendUTXO
+func (msg *MsgSendUTXO) Hash() string {
+	data := msg.Creator
+	for _, input := range msg.Inputs {
+		data += input.PrevTxHash + strconv.FormatUint(uint64(input.PrevOutputIndex), 10)
+	}
+	for _, output := range msg.Outputs {
+		data += output.Address + output.Amount
+	}
+	data += msg.Fee + strconv.FormatUint(msg.LockTime, 10)
+
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+// sighashTx is the view of the message its input signatures commit to
+func (msg *MsgSendUTXO) sighashTx(chainID string) *sighash.Tx {
+	tx := &sighash.Tx{
+		ChainID:  chainID,
+		Inputs:   make([]sighash.Input, len(msg.Inputs)),
+		Outputs:  make([]sighash.Output, len(msg.Outputs)),
+		Fee:      msg.Fee,
+		LockTime: msg.LockTime,
+	}
+	for i, input := range msg.Inputs {
+		tx.Inputs[i] = sighash.Input{TxHash: input.PrevTxHash, Index: input.PrevOutputIndex, Sequence: input.Sequence}
+	}
+	for i, output := range msg.Outputs {
+		tx.Outputs[i] = sighash.Output{Amount: output.Amount, Address: output.Address, ScriptPubkey: output.ScriptPubkey}
+	}
+	return tx
+}
+
+// Hash matches the transaction hash the utxo module derives for MsgSendShielded
+func (msg *MsgSendShielded) Hash() string {
+	data := msg.Creator + msg.Fee
+	for _, nullifier := range msg.Nullifiers {
+		data += hex.EncodeToString(nullifier)
+	}
+	for _, commitment := range msg.Commitments {
+		data += hex.EncodeToString(commitment)
+	}
+	data += msg.ValueBalance
+	for _, output := range msg.TransparentOutputs {
+		data += output.Address + output.Amount
+	}
+
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
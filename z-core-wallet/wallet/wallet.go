@@ -0,0 +1,360 @@
+// Package wallet is the core of the Z Core wallet: key management, address
+// derivation, coin tracking and building and signing zChain transactions.
+// It holds no network code. Messages it builds are returned for the caller to
+// broadcast, and outputs seen on chain are fed back through Scan, so the
+// wallet can be embedded by services that run their own node connection.
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"shared/address"
+	"z-core-wallet/keystore"
+	"z-core-wallet/storage"
+)
+
+var (
+	ErrLocked     = errors.New("wallet is locked")
+	ErrNoKeystore = errors.New("no keystore configured")
+)
+
+// Options configures a wallet opened with New
+type Options struct {
+	// Store persists coins and history; state is kept in memory when nil
+	Store storage.Store
+	// Keystore holds the encrypted key. A wallet with an existing keystore
+	// opens locked until Unlock is called with its passphrase.
+	Keystore *keystore.Keystore
+	// PrivateKey is used when there is no keystore to open; a new key is
+	// generated when it is nil
+	PrivateKey *btcec.PrivateKey
+	// Signer, when set, holds the key in place of a keystore or PrivateKey,
+	// for example in a separate signer process
+	Signer Signer
+	// Account is the address controlled by Signer; it is derived from the
+	// signer's public key when zero
+	Account address.Address
+	// ChainID is the zChain chain ID transparent input signatures commit to;
+	// DefaultChainID when empty
+	ChainID string
+}
+
+// DefaultChainID is the zChain mainnet chain ID
+const DefaultChainID = "z-blockchain-1"
+
+// Wallet is a single account. All methods are safe for concurrent use.
+type Wallet struct {
+	mu sync.Mutex // Guards everything below, coin selection included
+
+	signer    Signer // Nil while locked
+	publicKey *btcec.PublicKey
+	account   address.Address // Stays fixed when the controlling key is rotated
+
+	balance         Balance
+	tokenBalances   map[string]int64 // Account based denoms, mirrored by the caller
+	history         []Transaction
+	utxos           []UTXO         // Unspent transparent outputs
+	notes           []ShieldedNote // Unspent shielded notes
+	nextDiversifier uint64         // Last diversified address index handed out
+
+	pending   map[string]*PendingTx // Built transactions not yet seen in a block
+	onPending func(PendingEvent)
+
+	store    storage.Store
+	keystore *keystore.Keystore
+	chainID  string
+}
+
+// New opens the wallet and restores its coins and history from the store
+func New(opts Options) (*Wallet, error) {
+	w := &Wallet{
+		store:    opts.Store,
+		keystore: opts.Keystore,
+		history:  []Transaction{},
+		chainID:  opts.ChainID,
+	}
+	if w.store == nil {
+		w.store = storage.NewMemoryStore()
+	}
+	if w.chainID == "" {
+		w.chainID = DefaultChainID
+	}
+
+	if opts.Signer != nil {
+		w.keystore = nil
+		w.signer = opts.Signer
+		w.publicKey = opts.Signer.PublicKey()
+		w.account = opts.Account
+		if w.account == (address.Address{}) {
+			account, err := address.FromPubKey(w.publicKey.SerializeCompressed())
+			if err != nil {
+				return nil, err
+			}
+			w.account = account
+		}
+	} else if w.keystore != nil && w.keystore.Exists() {
+		account, err := w.keystore.Account()
+		if err != nil {
+			return nil, err
+		}
+		w.account = account
+	} else {
+		privateKey := opts.PrivateKey
+		if privateKey == nil {
+			var err error
+			if privateKey, err = btcec.NewPrivateKey(); err != nil {
+				return nil, err
+			}
+		}
+
+		// Derive the account address shared with zChain and nuChain
+		account, err := address.FromPubKey(privateKey.PubKey().SerializeCompressed())
+		if err != nil {
+			return nil, err
+		}
+		w.account = account
+		w.signer = NewKeySigner(privateKey)
+		w.publicKey = privateKey.PubKey()
+	}
+
+	if err := w.load(); err != nil {
+		return nil, fmt.Errorf("failed to load wallet state: %w", err)
+	}
+	return w, nil
+}
+
+// Account returns the account address shared by zChain and nuChain
+func (w *Wallet) Account() address.Address {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.account
+}
+
+// Address returns the account in Z base58 form
+func (w *Wallet) Address() string {
+	return w.Account().ZBase58()
+}
+
+// PublicKey returns the controlling key, nil while a keystore wallet has not
+// been unlocked yet
+func (w *Wallet) PublicKey() *btcec.PublicKey {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.publicKey
+}
+
+// EVMAddress returns the address of the controlling key on Altcoinchain and
+// Polygon. It is derived from the key, not the account, so it is unknown
+// while a keystore wallet has not been unlocked yet.
+func (w *Wallet) EVMAddress() (address.EVMAddress, bool) {
+	pub := w.PublicKey()
+	if pub == nil {
+		return address.EVMAddress{}, false
+	}
+	addr, err := address.EVMFromPubKey(pub.SerializeCompressed())
+	return addr, err == nil
+}
+
+// Locked reports whether the wallet lacks its private key
+func (w *Wallet) Locked() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.signer == nil
+}
+
+// HasKeystore reports whether the key is saved in a keystore the wallet can
+// be unlocked from again
+func (w *Wallet) HasKeystore() bool {
+	return w.keystore != nil && w.keystore.Exists()
+}
+
+// Unlock decrypts the keystore. A wrong passphrase fails with
+// keystore.ErrDecrypt.
+func (w *Wallet) Unlock(passphrase string) error {
+	if !w.HasKeystore() {
+		return ErrNoKeystore
+	}
+
+	account, privateKey, err := w.keystore.Unlock(passphrase)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.account = account
+	w.signer = NewKeySigner(privateKey)
+	w.publicKey = privateKey.PubKey()
+	if err := w.saveAccount(); err != nil {
+		log.Printf("Failed to persist wallet account: %v", err)
+	}
+	return nil
+}
+
+// KeystoreKey decrypts the key of the keystore without unlocking the wallet
+// with it, to authorize an owner action. A wrong passphrase fails with
+// keystore.ErrDecrypt.
+func (w *Wallet) KeystoreKey(passphrase string) (*btcec.PrivateKey, error) {
+	if !w.HasKeystore() {
+		return nil, ErrNoKeystore
+	}
+
+	_, privateKey, err := w.keystore.Unlock(passphrase)
+	return privateKey, err
+}
+
+// Lock drops the private key. It refuses without a keystore, where the key
+// would be lost for good.
+func (w *Wallet) Lock() error {
+	if !w.HasKeystore() {
+		return ErrNoKeystore
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.signer = nil
+	return nil
+}
+
+// SaveKeystore encrypts the key into the keystore with passphrase
+func (w *Wallet) SaveKeystore(passphrase string) error {
+	if w.keystore == nil {
+		return ErrNoKeystore
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return ErrLocked
+	}
+	signer, ok := w.signer.(*KeySigner)
+	if !ok {
+		return ErrRemoteSigner
+	}
+	return w.keystore.Save(w.account, signer.key, passphrase)
+}
+
+// Rekey hands control of the account to privateKey, as social recovery does.
+// The account keeps its address.
+func (w *Wallet) Rekey(privateKey *btcec.PrivateKey) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.signer = NewKeySigner(privateKey)
+	w.publicKey = privateKey.PubKey()
+	if err := w.saveAccount(); err != nil {
+		log.Printf("Failed to persist wallet account: %v", err)
+	}
+}
+
+// SignMessage signs sha256(message) with the wallet key, returning the 65
+// byte recoverable signature hex encoded
+func (w *Wallet) SignMessage(message string) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.signer == nil {
+		return "", ErrLocked
+	}
+
+	hash := sha256.Sum256([]byte(message))
+	signature, err := w.signer.Sign(hash[:])
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// Balance returns the wallet balances
+func (w *Wallet) Balance() Balance {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	balance := w.balance
+	balance.Amounts = copyAmounts(w.balance.Amounts)
+	balance.Shielded = copyAmounts(w.balance.Shielded)
+	return balance
+}
+
+func copyAmounts(amounts map[string]int64) map[string]int64 {
+	c := make(map[string]int64, len(amounts))
+	for denom, amount := range amounts {
+		c[denom] = amount
+	}
+	return c
+}
+
+// Transactions returns the history, oldest first
+func (w *Wallet) Transactions() []Transaction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Transaction{}, w.history...)
+}
+
+// UTXOs returns the tracked transparent outputs
+func (w *Wallet) UTXOs() []UTXO {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]UTXO{}, w.utxos...)
+}
+
+// Notes returns the tracked shielded notes
+func (w *Wallet) Notes() []ShieldedNote {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]ShieldedNote{}, w.notes...)
+}
+
+// ConfirmedTransparent sums the transparent outputs coin selection may spend
+func (w *Wallet) ConfirmedTransparent() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var total int64
+	for _, utxo := range w.utxos {
+		if !utxo.Pending && !utxo.Frozen {
+			total += utxo.Amount
+		}
+	}
+	return total
+}
+
+// CreateTransaction records a pending transparent transaction in the history
+func (w *Wallet) CreateTransaction(recipient string, amount int64, token, memo string) Transaction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if denom, err := NormalizeDenom(token); err == nil {
+		token = denom
+	}
+
+	from := w.account.ZBase58()
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", from, time.Now().UnixNano())))
+
+	tx := Transaction{
+		Hash:      hex.EncodeToString(hash[:]),
+		From:      from,
+		To:        recipient,
+		Amount:    amount,
+		Token:     token,
+		Timestamp: time.Now(),
+		Status:    "pending",
+		Memo:      memo,
+		Private:   false,
+	}
+	w.recordTransaction(tx)
+	w.trackPending(tx, 0, 0, nil, nil)
+
+	return tx
+}
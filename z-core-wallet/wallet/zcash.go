@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/base58"
+)
+
+// WIF version bytes of Zcash transparent keys, shared with Bitcoin
+const (
+	zcashWIFVersion        byte = 0x80
+	zcashWIFTestnetVersion byte = 0xef
+
+	// wifCompressed follows the key when its address hashes the compressed
+	// public key, the only form Z accounts use
+	wifCompressed byte = 0x01
+)
+
+var (
+	ErrInvalidZcashKey     = errors.New("invalid zcash key")
+	ErrUnsupportedZcashKey = errors.New("unsupported zcash key")
+)
+
+// Bech32 prefixes of Zcash shielded keys, recognized only to reject them with
+// a useful error
+var zcashShieldedKeyPrefixes = []string{
+	"secret-extended-key-", "zxviews", "zxviewtestsapling", "uview", "usk",
+}
+
+// EncodeZcashKey returns key as a Zcash transparent WIF key, the format of
+// zcashd's dumpprivkey. Zcash derives the same t-addr from it as the Z
+// account of key.
+func EncodeZcashKey(key *btcec.PrivateKey, testnet bool) string {
+	version := zcashWIFVersion
+	if testnet {
+		version = zcashWIFTestnetVersion
+	}
+	return base58.CheckEncode(append(key.Serialize(), wifCompressed), version)
+}
+
+// ParseZcashKey decodes a transparent WIF key exported from Zcash tooling.
+// Keys of uncompressed public keys are rejected; their t-addr is not the
+// account the wallet would derive. Sapling and Sprout spending keys belong to
+// a different curve and key tree than Z's shielded keys, so funds held by them
+// must be sent to a Z address from Zcash first.
+func ParseZcashKey(s string) (*btcec.PrivateKey, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	for _, prefix := range zcashShieldedKeyPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return nil, fmt.Errorf("%w: shielded keys cannot be imported, send the funds to a Z address instead", ErrUnsupportedZcashKey)
+		}
+	}
+	if strings.HasPrefix(s, "SK") || strings.HasPrefix(s, "ST") {
+		return nil, fmt.Errorf("%w: Sprout spending keys cannot be imported, send the funds to a Z address instead", ErrUnsupportedZcashKey)
+	}
+
+	payload, version, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidZcashKey, err)
+	}
+	if version != zcashWIFVersion && version != zcashWIFTestnetVersion {
+		return nil, fmt.Errorf("%w: unexpected version byte 0x%02x", ErrInvalidZcashKey, version)
+	}
+
+	switch {
+	case len(payload) == 33 && payload[32] == wifCompressed:
+		key, _ := btcec.PrivKeyFromBytes(payload[:32])
+		return key, nil
+	case len(payload) == 32:
+		return nil, fmt.Errorf("%w: keys of uncompressed public keys are not supported", ErrUnsupportedZcashKey)
+	default:
+		return nil, fmt.Errorf("%w: unexpected length %d", ErrInvalidZcashKey, len(payload))
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"shared/address"
+	"z-core-wallet/wallet"
+)
+
+// maxWithholdingBps lets a custodial account withhold up to all of a reward
+const maxWithholdingBps = 10000
+
+// Withholding entry states
+const (
+	WithholdingSent = "sent" // Transfer to the destination created
+	WithholdingOwed = "owed" // Wallet was locked; settle once unlocked
+	WithholdingHeld = "held" // Transfer waits for the spending policy's second factor
+	WithholdingNone = "none" // Withholding disabled or the share rounded to zero
+)
+
+var ErrInvalidWithholding = errors.New("invalid withholding configuration")
+
+// WithholdingConfig routes a share of every incoming mining reward to a savings
+// or tax account. This is the custodial, wallet-level counterpart of nuChain's
+// on-chain reward withholding.
+type WithholdingConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Bps         uint32 `json:"bps"`         // Share withheld in basis points
+	Destination string `json:"destination"` // Savings or tax account
+}
+
+// LoadWithholdingConfig reads WALLET_WITHHOLDING_* settings; withholding is
+// off unless enabled explicitly
+func LoadWithholdingConfig() WithholdingConfig {
+	return WithholdingConfig{
+		Enabled:     os.Getenv("WALLET_WITHHOLDING") == "true",
+		Bps:         uint32(envInt("WALLET_WITHHOLDING_BPS", 0)),
+		Destination: os.Getenv("WALLET_WITHHOLDING_DESTINATION"),
+	}
+}
+
+// Validate checks an enabled configuration can route rewards
+func (c WithholdingConfig) Validate(own string) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Bps == 0 || c.Bps > maxWithholdingBps {
+		return fmt.Errorf("%w: bps must be between 1 and %d", ErrInvalidWithholding, maxWithholdingBps)
+	}
+	if _, _, _, err := address.Parse(c.Destination); err != nil {
+		return fmt.Errorf("%w: destination: %v", ErrInvalidWithholding, err)
+	}
+	if sameAccount(c.Destination, own) {
+		return fmt.Errorf("%w: destination must differ from the wallet address", ErrInvalidWithholding)
+	}
+	return nil
+}
+
+// WithholdingEntry records one incoming reward and what was withheld from it
+type WithholdingEntry struct {
+	ID          uint64    `json:"id"`
+	Time        time.Time `json:"time"`
+	Reference   string    `json:"reference,omitempty"` // Block height or payout ID of the reward
+	Token       string    `json:"token"`
+	Gross       int64     `json:"gross"`
+	Withheld    int64     `json:"withheld"`
+	Destination string    `json:"destination,omitempty"`
+	Status      string    `json:"status"`
+	TxHash      string    `json:"tx_hash,omitempty"`
+	HeldID      string    `json:"held_id,omitempty"`
+}
+
+// WithholdingPeriodTotals sums the entries of one reporting period and token
+type WithholdingPeriodTotals struct {
+	Period   string `json:"period"`
+	Token    string `json:"token"`
+	Rewards  int    `json:"rewards"`
+	Gross    int64  `json:"gross"`
+	Withheld int64  `json:"withheld"`
+	Owed     int64  `json:"owed"` // Withheld but not yet transferred
+}
+
+// Withholder keeps the withholding configuration and ledger
+type Withholder struct {
+	mu      sync.Mutex
+	cfg     WithholdingConfig
+	entries []WithholdingEntry
+	nextID  uint64
+}
+
+// NewWithholder creates a withholder with an empty ledger
+func NewWithholder(cfg WithholdingConfig) *Withholder {
+	return &Withholder{cfg: cfg}
+}
+
+// Config returns the current configuration
+func (h *Withholder) Config() WithholdingConfig {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.cfg
+}
+
+// SetConfig replaces the configuration; past entries are kept as recorded
+func (h *Withholder) SetConfig(cfg WithholdingConfig) {
+	h.mu.Lock()
+	h.cfg = cfg
+	h.mu.Unlock()
+}
+
+// Entries returns a copy of the ledger, oldest first
+func (h *Withholder) Entries() []WithholdingEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]WithholdingEntry(nil), h.entries...)
+}
+
+func (h *Withholder) record(entry WithholdingEntry) WithholdingEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	entry.ID = h.nextID
+	h.entries = append(h.entries, entry)
+	return entry
+}
+
+func (h *Withholder) update(entry WithholdingEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.entries {
+		if h.entries[i].ID == entry.ID {
+			h.entries[i] = entry
+			return
+		}
+	}
+}
+
+// Report totals the ledger per day, month or year
+func (h *Withholder) Report(granularity string) ([]WithholdingPeriodTotals, error) {
+	var layout string
+	switch granularity {
+	case "", "day":
+		layout = "2006-01-02"
+	case "month":
+		layout = "2006-01"
+	case "year":
+		layout = "2006"
+	default:
+		return nil, fmt.Errorf("unknown period %q; use day, month or year", granularity)
+	}
+
+	totals := make(map[[2]string]*WithholdingPeriodTotals)
+	for _, entry := range h.Entries() {
+		key := [2]string{entry.Time.UTC().Format(layout), entry.Token}
+		t, ok := totals[key]
+		if !ok {
+			t = &WithholdingPeriodTotals{Period: key[0], Token: key[1]}
+			totals[key] = t
+		}
+		t.Rewards++
+		t.Gross += entry.Gross
+		t.Withheld += entry.Withheld
+		if entry.Status == WithholdingOwed || entry.Status == WithholdingHeld {
+			t.Owed += entry.Withheld
+		}
+	}
+
+	report := make([]WithholdingPeriodTotals, 0, len(totals))
+	for _, t := range totals {
+		report = append(report, *t)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Period != report[j].Period {
+			return report[i].Period < report[j].Period
+		}
+		return report[i].Token < report[j].Token
+	})
+	return report, nil
+}
+
+// RecordReward books an incoming reward and transfers the withheld share to
+// the destination. A locked wallet cannot sign, so the share is marked owed
+// and moved by SettleOwed after unlocking.
+func (ws *WalletService) RecordReward(amount int64, token, reference string) WithholdingEntry {
+	cfg := ws.withholding.Config()
+
+	entry := WithholdingEntry{
+		Time:      time.Now(),
+		Reference: reference,
+		Token:     token,
+		Gross:     amount,
+		Status:    WithholdingNone,
+	}
+	if cfg.Enabled {
+		entry.Withheld = amount * int64(cfg.Bps) / maxWithholdingBps
+	}
+	if entry.Withheld > 0 {
+		entry.Destination = cfg.Destination
+		entry.Status = WithholdingOwed
+	}
+
+	entry = ws.withholding.record(entry)
+	if entry.Status == WithholdingOwed && !ws.wallet.Locked() {
+		entry = ws.transferWithheld(entry)
+	}
+
+	ws.publish("reward_withheld", entry)
+	return entry
+}
+
+// SettleOwed transfers every share withheld while the wallet was locked
+func (ws *WalletService) SettleOwed() ([]WithholdingEntry, error) {
+	if ws.wallet.Locked() {
+		return nil, wallet.ErrLocked
+	}
+
+	var settled []WithholdingEntry
+	for _, entry := range ws.withholding.Entries() {
+		if entry.Status != WithholdingOwed {
+			continue
+		}
+		settled = append(settled, ws.transferWithheld(entry))
+	}
+	return settled, nil
+}
+
+// transferWithheld sends a withheld share, subject to the spending policy
+func (ws *WalletService) transferWithheld(entry WithholdingEntry) WithholdingEntry {
+	memo := "reward withholding"
+	if entry.Reference != "" {
+		memo += " for " + entry.Reference
+	}
+
+	if held := ws.holdIfViolates(entry.Destination, entry.Withheld, entry.Token, memo, false); held != nil {
+		entry.Status = WithholdingHeld
+		entry.HeldID = held.ID
+	} else {
+		tx := ws.CreateTransaction(entry.Destination, entry.Withheld, entry.Token, memo)
+		entry.Status = WithholdingSent
+		entry.TxHash = tx.Hash
+	}
+
+	ws.withholding.update(entry)
+	return entry
+}
+
+// HTTP Handlers
+
+func (ws *WalletService) getWithholding(w http.ResponseWriter, r *http.Request) {
+	var owed int64
+	entries := ws.withholding.Entries()
+	for _, entry := range entries {
+		if entry.Status == WithholdingOwed {
+			owed += entry.Withheld
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config":  ws.withholding.Config(),
+		"owed":    owed,
+		"entries": entries,
+	})
+}
+
+func (ws *WalletService) setWithholding(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled     *bool   `json:"enabled"`
+		Bps         *uint32 `json:"bps"`
+		Destination *string `json:"destination"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg := ws.withholding.Config()
+	if req.Enabled != nil {
+		cfg.Enabled = *req.Enabled
+	}
+	if req.Bps != nil {
+		cfg.Bps = *req.Bps
+	}
+	if req.Destination != nil {
+		cfg.Destination = *req.Destination
+	}
+	if err := cfg.Validate(ws.wallet.Address()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws.withholding.SetConfig(cfg)
+	ws.getWithholding(w, r)
+}
+
+func (ws *WalletService) recordReward(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount    int64  `json:"amount"`
+		Token     string `json:"token"`
+		Reference string `json:"reference"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Amount <= 0 {
+		http.Error(w, "Invalid amount", http.StatusBadRequest)
+		return
+	}
+	token, err := wallet.NormalizeDenom(req.Token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Token = token
+
+	entry := ws.RecordReward(req.Amount, req.Token, req.Reference)
+	ws.publish("mining", ws.miningSummary())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (ws *WalletService) settleWithholding(w http.ResponseWriter, r *http.Request) {
+	settled, err := ws.SettleOwed()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"settled": settled})
+}
+
+func (ws *WalletService) getWithholdingReport(w http.ResponseWriter, r *http.Request) {
+	granularity := r.URL.Query().Get("period")
+	report, err := ws.withholding.Report(granularity)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if granularity == "" {
+		granularity = "day"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"period": granularity,
+		"totals": report,
+	})
+}